@@ -1,6 +1,8 @@
 package util
 
 import (
+	"time"
+
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/router"
 )
@@ -30,3 +32,11 @@ type apiRouter struct {
 func (r *apiRouter) String() string {
 	return "api"
 }
+
+func (r *apiRouter) Feedback(route router.Route, rtt time.Duration, err error) error {
+	return nil
+}
+
+func (r *apiRouter) Status() router.Status {
+	return router.Status{Code: router.Running, Routes: len(r.routes)}
+}
@@ -7,13 +7,20 @@ import (
 	"net/http"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/micro/go-micro/v2/api/server"
 	"github.com/micro/go-micro/v2/api/server/cors"
+	"github.com/micro/go-micro/v2/auth/jwt"
 	"github.com/micro/go-micro/v2/logger"
 )
 
+var (
+	// DefaultReadHeaderTimeout is how long a client has to send the request headers
+	DefaultReadHeaderTimeout = 10 * time.Second
+)
+
 type httpServer struct {
 	mux  *http.ServeMux
 	opts server.Options
@@ -29,9 +36,14 @@ func NewServer(address string, opts ...server.Option) server.Server {
 		o(&options)
 	}
 
+	mux := http.NewServeMux()
+	if options.JWKS != nil {
+		mux.Handle(jwt.JWKSPath, jwt.JWKSHandler(options.JWKS))
+	}
+
 	return &httpServer{
 		opts:    options,
-		mux:     http.NewServeMux(),
+		mux:     mux,
 		address: address,
 		exit:    make(chan chan error),
 	}
@@ -58,6 +70,9 @@ func (s *httpServer) Handle(path string, handler http.Handler) {
 		handler = wrapper(handler)
 	}
 
+	// limit the requests to the route
+	handler = server.LimitHandler(handler, s.opts.Limits.Merge(s.opts.RouteLimits[path]))
+
 	// wrap with cors
 	if s.opts.EnableCORS {
 		handler = cors.CombinedCORSHandler(handler)
@@ -94,8 +109,17 @@ func (s *httpServer) Start() error {
 	s.address = l.Addr().String()
 	s.mtx.Unlock()
 
+	timeout := s.opts.ReadHeaderTimeout
+	if timeout == 0 {
+		timeout = DefaultReadHeaderTimeout
+	}
+	srv := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: timeout,
+	}
+
 	go func() {
-		if err := http.Serve(l, s.mux); err != nil {
+		if err := srv.Serve(l); err != nil {
 			// temporary fix
 			//logger.Fatal(err)
 		}
@@ -2,9 +2,16 @@ package http
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/api/server"
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/auth/jwt"
 )
 
 func TestHTTPServer(t *testing.T) {
@@ -39,3 +46,88 @@ func TestHTTPServer(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestHTTPServerLimits(t *testing.T) {
+	s := NewServer("localhost:0",
+		server.WithLimits(server.Limits{MaxBodySize: 8}),
+		server.WithRouteLimits("/slow", server.Limits{RequestTimeout: time.Millisecond * 50}),
+	)
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(b)
+	})
+	s.Handle("/echo", echo)
+	s.Handle("/slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte("late"))
+	}))
+
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	url := fmt.Sprintf("http://%s", s.Address())
+
+	testData := []struct {
+		path string
+		body io.Reader
+		code int
+	}{
+		{"/echo", strings.NewReader("small"), http.StatusOK},
+		// the content length is checked before the handler is called
+		{"/echo", strings.NewReader("much too large"), http.StatusRequestEntityTooLarge},
+		// the body is checked while it's read when the length isn't known
+		{"/echo", ioutil.NopCloser(strings.NewReader("much too large")), http.StatusRequestEntityTooLarge},
+		{"/slow", strings.NewReader(""), http.StatusRequestTimeout},
+	}
+
+	for _, d := range testData {
+		rsp, err := http.Post(url+d.path, "text/plain", d.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode != d.code {
+			t.Fatalf("Expected status %d for %s, got %d", d.code, d.path, rsp.StatusCode)
+		}
+	}
+}
+
+func TestHTTPServerJWKS(t *testing.T) {
+	pub, err := ioutil.ReadFile("../../../util/token/jwt/test/sample_key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the key set is served outside of the wrappers e.g. auth
+	deny := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	s := NewServer("localhost:0", server.JWKS(jwt.NewAuth(auth.PublicKey(string(pub)))), server.WrapHandler(deny))
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	rsp, err := http.Get(fmt.Sprintf("http://%s%s", s.Address(), jwt.JWKSPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+
+	b, _ := ioutil.ReadAll(rsp.Body)
+	if rsp.StatusCode != http.StatusOK || !strings.Contains(string(b), `"keys"`) {
+		t.Fatalf("Expected the key set, got %d %s", rsp.StatusCode, b)
+	}
+}
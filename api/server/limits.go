@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrBodyTooLarge is returned reading a request body over the max size
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+// Limits protect the server from slow or abusive clients
+type Limits struct {
+	// MaxBodySize is the max size of a request body in bytes, larger requests
+	// get a 413. Zero is no limit.
+	MaxBodySize int64
+	// RequestTimeout is the max duration of a request, requests taking longer
+	// get a 408. Zero is no limit. The responses of the requests are buffered
+	// so don't set it on routes streaming responses.
+	RequestTimeout time.Duration
+}
+
+// Merge returns the limits with those which aren't set taken from l
+func (l Limits) Merge(o Limits) Limits {
+	if o.MaxBodySize == 0 {
+		o.MaxBodySize = l.MaxBodySize
+	}
+	if o.RequestTimeout == 0 {
+		o.RequestTimeout = l.RequestTimeout
+	}
+	return o
+}
+
+// LimitHandler returns a handler enforcing the limits on the requests
+func LimitHandler(h http.Handler, l Limits) http.Handler {
+	if l.MaxBodySize > 0 {
+		h = maxBodySize(h, l.MaxBodySize)
+	}
+	if l.RequestTimeout > 0 {
+		h = requestTimeout(h, l.RequestTimeout)
+	}
+	return h
+}
+
+func maxBodySize(h http.Handler, max int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > max {
+			http.Error(w, ErrBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		body := &limitReader{ReadCloser: r.Body, left: max}
+		r.Body = body
+		h.ServeHTTP(&limitWriter{ResponseWriter: w, body: body}, r)
+	})
+}
+
+// limitReader errors reading over the max size of the body
+type limitReader struct {
+	io.ReadCloser
+	left     int64
+	exceeded int32
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.tooLarge() {
+		return 0, ErrBodyTooLarge
+	}
+	// read one more byte than left to tell if the body is too large
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	if int64(n) > l.left {
+		atomic.StoreInt32(&l.exceeded, 1)
+		n = int(l.left)
+		l.left = 0
+		return n, ErrBodyTooLarge
+	}
+	l.left -= int64(n)
+	return n, err
+}
+
+func (l *limitReader) tooLarge() bool {
+	return atomic.LoadInt32(&l.exceeded) == 1
+}
+
+// limitWriter responds with a 413 if the handler read too much of the body
+type limitWriter struct {
+	http.ResponseWriter
+	body        *limitReader
+	wroteHeader bool
+}
+
+func (l *limitWriter) WriteHeader(code int) {
+	if l.wroteHeader {
+		return
+	}
+	l.wroteHeader = true
+	if l.body.tooLarge() {
+		code = http.StatusRequestEntityTooLarge
+	}
+	l.ResponseWriter.WriteHeader(code)
+}
+
+func (l *limitWriter) Write(b []byte) (int, error) {
+	l.WriteHeader(http.StatusOK)
+	return l.ResponseWriter.Write(b)
+}
+
+func (l *limitWriter) Flush() {
+	if f, ok := l.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (l *limitWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := l.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer is not a hijacker")
+	}
+	return h.Hijack()
+}
+
+func requestTimeout(h http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			h.ServeHTTP(tw, r.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case <-done:
+			tw.Lock()
+			defer tw.Unlock()
+			for k, v := range tw.header {
+				w.Header()[k] = v
+			}
+			if tw.code == 0 {
+				tw.code = http.StatusOK
+			}
+			w.WriteHeader(tw.code)
+			w.Write(tw.buf.Bytes())
+		case <-ctx.Done():
+			tw.Lock()
+			defer tw.Unlock()
+			tw.timedOut = true
+			http.Error(w, http.StatusText(http.StatusRequestTimeout), http.StatusRequestTimeout)
+		}
+	})
+}
+
+// timeoutWriter buffers the response until the handler returns
+type timeoutWriter struct {
+	sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	code     int
+	timedOut bool
+}
+
+func (t *timeoutWriter) Header() http.Header {
+	return t.header
+}
+
+func (t *timeoutWriter) Write(b []byte) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+	if t.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if t.code == 0 {
+		t.code = http.StatusOK
+	}
+	return t.buf.Write(b)
+}
+
+func (t *timeoutWriter) WriteHeader(code int) {
+	t.Lock()
+	defer t.Unlock()
+	if t.timedOut || t.code != 0 {
+		return
+	}
+	t.code = code
+}
@@ -3,9 +3,11 @@ package server
 import (
 	"crypto/tls"
 	"net/http"
+	"time"
 
 	"github.com/micro/go-micro/v2/api/resolver"
 	"github.com/micro/go-micro/v2/api/server/acme"
+	"github.com/micro/go-micro/v2/auth"
 )
 
 type Option func(o *Options)
@@ -19,6 +21,14 @@ type Options struct {
 	TLSConfig    *tls.Config
 	Resolver     resolver.Resolver
 	Wrappers     []Wrapper
+	// ReadHeaderTimeout is how long a client has to send the request headers
+	ReadHeaderTimeout time.Duration
+	// Limits of the requests to every route
+	Limits Limits
+	// RouteLimits override the limits of the requests to a route by path
+	RouteLimits map[string]Limits
+	// JWKS is the auth the public keys of which are served
+	JWKS auth.Auth
 }
 
 type Wrapper func(h http.Handler) http.Handler
@@ -70,3 +80,37 @@ func Resolver(r resolver.Resolver) Option {
 		o.Resolver = r
 	}
 }
+
+// ReadHeaderTimeout sets how long a client has to send the request headers
+func ReadHeaderTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.ReadHeaderTimeout = d
+	}
+}
+
+// WithLimits sets the limits of the requests to every route
+func WithLimits(l Limits) Option {
+	return func(o *Options) {
+		o.Limits = l
+	}
+}
+
+// WithRouteLimits sets the limits of the requests to the route handled at the
+// path, the limits which aren't set default to the limits of every route
+func WithRouteLimits(path string, l Limits) Option {
+	return func(o *Options) {
+		if o.RouteLimits == nil {
+			o.RouteLimits = make(map[string]Limits)
+		}
+		o.RouteLimits[path] = l
+	}
+}
+
+// JWKS serves the public keys of the auth as a JSON web key set on the well
+// known path, outside of the wrappers, so external systems can verify the
+// tokens it issues
+func JWKS(a auth.Auth) Option {
+	return func(o *Options) {
+		o.JWKS = a
+	}
+}
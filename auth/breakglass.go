@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// BreakGlassType is the type of the accounts issued for break-glass credentials
+	BreakGlassType = "break-glass"
+)
+
+var (
+	// DefaultBreakGlassExpiry is how long a token issued for break-glass credentials lasts
+	DefaultBreakGlassExpiry = 15 * time.Minute
+	// ErrInvalidBreakGlass is returned when break-glass credentials can't be parsed
+	ErrInvalidBreakGlass = errors.New("invalid break-glass credentials")
+)
+
+// BreakGlassAccount is an emergency account verified locally against a bcrypt
+// hash of its secret, used by operators when the auth service is unreachable
+type BreakGlassAccount struct {
+	// ID of the account
+	ID string
+	// Hash is the bcrypt hash of the account secret
+	Hash string
+}
+
+// ParseBreakGlass parses break-glass credentials in the form id:hash
+func ParseBreakGlass(creds ...string) ([]BreakGlassAccount, error) {
+	accounts := make([]BreakGlassAccount, 0, len(creds))
+
+	for _, c := range creds {
+		parts := strings.SplitN(c, ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, ErrInvalidBreakGlass
+		}
+		accounts = append(accounts, BreakGlassAccount{ID: parts[0], Hash: parts[1]})
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,210 @@
+// Package credentials provides the credentials used by plugins to connect to
+// their backends, e.g. a store database, so they can be issued dynamically and
+// renewed rather than set as static passwords.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/util/backoff"
+)
+
+var (
+	// ErrNoCredentials is returned when the provider has no credentials
+	ErrNoCredentials = errors.New("no credentials")
+)
+
+// Credentials to connect to a backend
+type Credentials struct {
+	// Username to authenticate with
+	Username string `json:"username"`
+	// Password of the user
+	Password string `json:"password"`
+	// Token to authenticate with, for backends using tokens
+	Token string `json:"token"`
+	// Expiry of the credentials, zero if they don't expire
+	Expiry time.Time `json:"expiry"`
+}
+
+// Expired returns true if the credentials have expired
+func (c *Credentials) Expired() bool {
+	return !c.Expiry.IsZero() && time.Now().After(c.Expiry)
+}
+
+// Provider issues credentials
+type Provider interface {
+	// Credentials returns valid credentials
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// ProviderFunc is a func used as a Provider
+type ProviderFunc func(ctx context.Context) (*Credentials, error)
+
+// Credentials calls the func
+func (fn ProviderFunc) Credentials(ctx context.Context) (*Credentials, error) {
+	return fn(ctx)
+}
+
+// Static returns a provider of credentials which never change
+func Static(username, password string) Provider {
+	return ProviderFunc(func(context.Context) (*Credentials, error) {
+		return &Credentials{Username: username, Password: password}, nil
+	})
+}
+
+// Renewer caches the credentials of a provider and renews them in the
+// background before they expire
+type Renewer struct {
+	provider Provider
+	opts     RenewOptions
+
+	sync.RWMutex
+	creds  *Credentials
+	notify []func(*Credentials)
+	exit   chan bool
+	once   sync.Once
+}
+
+// NewRenewer returns a renewer of the credentials of the provider. The first
+// credentials are fetched before it returns.
+func NewRenewer(p Provider, opts ...RenewOption) (*Renewer, error) {
+	options := RenewOptions{
+		Fraction: DefaultRenewFraction,
+		Timeout:  DefaultRenewTimeout,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	r := &Renewer{
+		provider: p,
+		opts:     options,
+		exit:     make(chan bool),
+	}
+
+	creds, err := r.fetch()
+	if err != nil {
+		return nil, err
+	}
+	r.creds = creds
+
+	go r.run()
+
+	return r, nil
+}
+
+// Credentials returns the current credentials, fetching new ones if they expired
+func (r *Renewer) Credentials(ctx context.Context) (*Credentials, error) {
+	r.RLock()
+	creds := r.creds
+	r.RUnlock()
+
+	if !creds.Expired() {
+		return creds, nil
+	}
+
+	// the background renewal failed so try again
+	creds, err := r.provider.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		return nil, ErrNoCredentials
+	}
+	r.update(creds)
+	return creds, nil
+}
+
+// Notify calls fn with the new credentials every time they're renewed
+func (r *Renewer) Notify(fn func(*Credentials)) {
+	r.Lock()
+	r.notify = append(r.notify, fn)
+	r.Unlock()
+}
+
+// Stop renewing the credentials
+func (r *Renewer) Stop() {
+	r.once.Do(func() {
+		close(r.exit)
+	})
+}
+
+// fetch fetches credentials from the provider
+func (r *Renewer) fetch() (*Credentials, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+	defer cancel()
+
+	creds, err := r.provider.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		return nil, ErrNoCredentials
+	}
+	return creds, nil
+}
+
+func (r *Renewer) update(creds *Credentials) {
+	r.Lock()
+	r.creds = creds
+	notify := r.notify
+	r.Unlock()
+
+	for _, fn := range notify {
+		fn(creds)
+	}
+}
+
+// next returns how long to wait before renewing the credentials, false if
+// they're never renewed
+func (r *Renewer) next() (time.Duration, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	expiry := r.creds.Expiry
+	if expiry.IsZero() {
+		return r.opts.Interval, r.opts.Interval > 0
+	}
+
+	// don't spin on credentials issued already expired
+	wait := time.Duration(float64(time.Until(expiry)) * r.opts.Fraction)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait, true
+}
+
+// run renews the credentials before they expire until stopped
+func (r *Renewer) run() {
+	var attempts int
+
+	for {
+		// a nil channel blocks until stopped
+		var renew <-chan time.Time
+		if attempts > 0 {
+			renew = time.After(backoff.Do(attempts))
+		} else if wait, ok := r.next(); ok {
+			renew = time.After(wait)
+		}
+
+		select {
+		case <-r.exit:
+			return
+		case <-renew:
+		}
+
+		creds, err := r.fetch()
+		if err != nil {
+			attempts++
+			logger.Errorf("Error renewing credentials, attempt %d: %v", attempts, err)
+			continue
+		}
+
+		attempts = 0
+		r.update(creds)
+	}
+}
@@ -0,0 +1,98 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRenewer(t *testing.T) {
+	var mtx sync.Mutex
+	var issued int
+
+	p := ProviderFunc(func(ctx context.Context) (*Credentials, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		issued++
+		return &Credentials{Username: "user", Password: fmt.Sprintf("pass-%d", issued)}, nil
+	})
+
+	r, err := NewRenewer(p, RenewInterval(time.Millisecond*20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	creds, err := r.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.Password != "pass-1" {
+		t.Fatalf("Expected the first credentials, got %s", creds.Password)
+	}
+
+	renewed := make(chan *Credentials, 1)
+	r.Notify(func(c *Credentials) {
+		select {
+		case renewed <- c:
+		default:
+		}
+	})
+
+	select {
+	case c := <-renewed:
+		if c.Password == "pass-1" {
+			t.Fatal("Expected renewed credentials")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the credentials to be renewed")
+	}
+}
+
+func TestRenewerExpired(t *testing.T) {
+	var fail bool
+	var mtx sync.Mutex
+
+	p := ProviderFunc(func(ctx context.Context) (*Credentials, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if fail {
+			return nil, errors.New("unavailable")
+		}
+		return &Credentials{Token: "token", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+
+	r, err := NewRenewer(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	// expire the cached credentials with the provider down
+	r.Lock()
+	r.creds = &Credentials{Token: "old", Expiry: time.Now().Add(-time.Second)}
+	r.Unlock()
+
+	mtx.Lock()
+	fail = true
+	mtx.Unlock()
+
+	if _, err := r.Credentials(context.Background()); err == nil {
+		t.Fatal("Expected an error when the credentials expired and can't be renewed")
+	}
+
+	mtx.Lock()
+	fail = false
+	mtx.Unlock()
+
+	creds, err := r.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.Token != "token" || creds.Expired() {
+		t.Fatalf("Expected new credentials, got %+v", creds)
+	}
+}
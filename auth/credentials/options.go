@@ -0,0 +1,47 @@
+package credentials
+
+import (
+	"time"
+)
+
+var (
+	// DefaultRenewFraction is the fraction of the lifetime of the credentials
+	// after which they're renewed
+	DefaultRenewFraction = 0.66
+	// DefaultRenewTimeout is the timeout of fetching credentials
+	DefaultRenewTimeout = 10 * time.Second
+)
+
+// RenewOptions configure a Renewer
+type RenewOptions struct {
+	// Fraction of the lifetime of the credentials after which they're renewed
+	Fraction float64
+	// Interval at which credentials without an expiry are renewed, zero to never renew them
+	Interval time.Duration
+	// Timeout of fetching credentials
+	Timeout time.Duration
+}
+
+// RenewOption sets values in RenewOptions
+type RenewOption func(o *RenewOptions)
+
+// RenewFraction sets the fraction of the lifetime of the credentials after which they're renewed
+func RenewFraction(f float64) RenewOption {
+	return func(o *RenewOptions) {
+		o.Fraction = f
+	}
+}
+
+// RenewInterval sets the interval at which credentials without an expiry are renewed
+func RenewInterval(d time.Duration) RenewOption {
+	return func(o *RenewOptions) {
+		o.Interval = d
+	}
+}
+
+// RenewTimeout sets the timeout of fetching credentials
+func RenewTimeout(d time.Duration) RenewOption {
+	return func(o *RenewOptions) {
+		o.Timeout = d
+	}
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/util/token/jwt"
+)
+
+// JWKSPath is the well known path the key set is served on
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWKSHandler serves the public keys of the auth as a JSON web key set so
+// external systems can verify the tokens it issues. The current public key
+// and any verification keys are served, so rotating the signing key using
+// auth.VerificationKeys keeps previously issued tokens verifiable.
+func JWKSHandler(a auth.Auth) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		opts := a.Options()
+		keys := append([]string{opts.PublicKey}, opts.VerificationKeys...)
+
+		set, err := jwt.NewJWKS(keys...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(set)
+	})
+}
@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/util/token/jwt"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	pub, err := ioutil.ReadFile("../../util/token/jwt/test/sample_key.pub")
+	if err != nil {
+		t.Fatalf("Unable to read public key: %v", err)
+	}
+
+	h := JWKSHandler(NewAuth(auth.PublicKey(string(pub))))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Expected json, got %s", ct)
+	}
+
+	var set jwt.JWKS
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kty != "RSA" || len(set.Keys[0].Kid) == 0 {
+		t.Fatalf("Unexpected key set %+v", set)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, JWKSPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status 405, got %d", w.Code)
+	}
+}
@@ -33,6 +33,9 @@ type Options struct {
 	PublicKey string
 	// PrivateKey for encoding JWTs
 	PrivateKey string
+	// VerificationKeys are previous public keys which are still accepted
+	// when decoding JWTs, allowing signing keys to be rotated with overlap
+	VerificationKeys []string
 	// LoginURL is the relative url path where a user can login
 	LoginURL string
 	// Store to back auth
@@ -41,6 +44,8 @@ type Options struct {
 	Client client.Client
 	// Addrs sets the addresses of auth
 	Addrs []string
+	// BreakGlass accounts which can login when the auth service is unreachable
+	BreakGlass []BreakGlassAccount
 }
 
 type Option func(o *Options)
@@ -80,6 +85,13 @@ func PrivateKey(key string) Option {
 	}
 }
 
+// VerificationKeys are JWT public keys which remain valid after a key rotation
+func VerificationKeys(keys ...string) Option {
+	return func(o *Options) {
+		o.VerificationKeys = keys
+	}
+}
+
 // Credentials sets the auth credentials
 func Credentials(id, secret string) Option {
 	return func(o *Options) {
@@ -88,6 +100,14 @@ func Credentials(id, secret string) Option {
 	}
 }
 
+// BreakGlass sets the emergency accounts which are verified locally and can
+// login when the auth service is unreachable
+func BreakGlass(accounts ...BreakGlassAccount) Option {
+	return func(o *Options) {
+		o.BreakGlass = accounts
+	}
+}
+
 // ClientToken sets the auth token to use when making requests
 func ClientToken(token *Token) Option {
 	return func(o *Options) {
@@ -16,6 +16,15 @@ func VerifyAccess(rules []*Rule, acc *Account, res *Resource) error {
 	// the rule is only to be applied if the name matches the resource or is catch-all (*)
 	validNames := []string{"*", res.Name}
 
+	// topics can have wildcard excludes on the name, e.g. go.micro.events.* would
+	// include go.micro.events.foo and go.micro.events.foo.bar
+	if comps := strings.Split(res.Name, "."); res.Type == TopicResourceType && len(comps) > 1 {
+		for i := 1; i < len(comps); i++ {
+			wildcard := fmt.Sprintf("%v.*", strings.Join(comps[0:i], "."))
+			validNames = append(validNames, wildcard)
+		}
+	}
+
 	// rules can have wildcard excludes on endpoints since this can also be a path for web services,
 	// e.g. /foo/* would include /foo/bar. We also want to check for wildcards and the exact endpoint
 	validEndpoints := []string{"*", res.Endpoint}
@@ -276,6 +276,53 @@ func TestVerify(t *testing.T) {
 			},
 			Error: ErrForbidden,
 		},
+		{
+			Name:     "TopicWildcardNameValid",
+			Resource: TopicResource("go.micro.events.foo", TopicPublish),
+			Account:  &Account{},
+			Rules: []*Rule{
+				&Rule{
+					Scope: "*",
+					Resource: &Resource{
+						Type:     TopicResourceType,
+						Name:     "go.micro.events.*",
+						Endpoint: TopicPublish,
+					},
+				},
+			},
+		},
+		{
+			Name:     "TopicWildcardNameInvalid",
+			Resource: TopicResource("go.micro.orders.foo", TopicPublish),
+			Account:  &Account{},
+			Rules: []*Rule{
+				&Rule{
+					Scope: "*",
+					Resource: &Resource{
+						Type:     TopicResourceType,
+						Name:     "go.micro.events.*",
+						Endpoint: TopicPublish,
+					},
+				},
+			},
+			Error: ErrForbidden,
+		},
+		{
+			Name:     "TopicSubscribeOnly",
+			Resource: TopicResource("go.micro.events.foo", TopicPublish),
+			Account:  &Account{},
+			Rules: []*Rule{
+				&Rule{
+					Scope: "*",
+					Resource: &Resource{
+						Type:     TopicResourceType,
+						Name:     "go.micro.events.foo",
+						Endpoint: TopicSubscribe,
+					},
+				},
+			},
+			Error: ErrForbidden,
+		},
 	}
 
 	for _, tc := range tt {
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBreakGlass(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := auth.ParseBreakGlass("operator:" + string(hash))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := newBreakGlass()
+	b.setAccounts(accounts)
+
+	cause := errors.InternalServerError("go.micro.client", "service go.micro.auth: not found")
+	if !unreachable(cause) {
+		t.Fatalf("Expected %v to mean the auth service is unreachable", cause)
+	}
+	if unreachable(errors.Unauthorized("go.micro.auth", "invalid secret")) {
+		t.Fatal("Expected unauthorized not to mean the auth service is unreachable")
+	}
+
+	if _, err := b.login("operator", "wrong", "micro", cause); err != auth.ErrInvalidToken {
+		t.Fatalf("Expected invalid token, got %v", err)
+	}
+	if _, err := b.login("unknown", "secret", "micro", cause); err != cause {
+		t.Fatalf("Expected the original error for an unknown account, got %v", err)
+	}
+
+	tok, err := b.login("operator", "secret", "micro", cause)
+	if err != nil {
+		t.Fatalf("Expected login to succeed, got %v", err)
+	}
+
+	acc, ok := b.inspect(tok.AccessToken)
+	if !ok {
+		t.Fatal("Expected the token to be valid")
+	}
+	if acc.ID != "operator" || acc.Type != auth.BreakGlassType {
+		t.Fatalf("Unexpected account %+v", acc)
+	}
+	if !b.verify(acc, &auth.Resource{Type: "service", Name: "go.micro.service.foo", Endpoint: "Foo.Bar"}) {
+		t.Fatal("Expected the break-glass account to be granted access")
+	}
+
+	// another replica with the account verifies the token
+	replica := newBreakGlass()
+	replica.setAccounts(accounts)
+	if _, ok := replica.inspect(tok.AccessToken); !ok {
+		t.Fatal("Expected the token to be valid on another replica")
+	}
+
+	// tampered and expired tokens are rejected
+	parts := strings.Split(tok.AccessToken, ".")
+	forged, _ := json.Marshal(&breakGlassClaims{ID: "operator", Issuer: "micro", Expiry: time.Now().Add(time.Hour * 24).Unix()})
+	if _, ok := b.inspect(breakGlassPrefix + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]); ok {
+		t.Fatal("Expected a tampered token to be invalid")
+	}
+	expired, _ := json.Marshal(&breakGlassClaims{ID: "operator", Issuer: "micro", Expiry: time.Now().Add(-time.Minute).Unix()})
+	enc := base64.RawURLEncoding
+	if _, ok := b.inspect(breakGlassPrefix + enc.EncodeToString(expired) + "." + enc.EncodeToString(sign(string(hash), expired))); ok {
+		t.Fatal("Expected an expired token to be invalid")
+	}
+
+	// removing the account revokes its tokens
+	b.setAccounts(nil)
+	if _, ok := b.inspect(tok.AccessToken); ok {
+		t.Fatal("Expected the token to be revoked")
+	}
+	if b.verify(acc, &auth.Resource{Type: "service", Name: "go.micro.service.foo"}) {
+		t.Fatal("Expected access to be denied once the account is removed")
+	}
+}
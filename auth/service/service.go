@@ -19,6 +19,9 @@ type svcAuth struct {
 	auth    pb.AuthService
 	rules   pb.RulesService
 	token   token.Provider
+
+	// breakGlass accounts used when the auth service is unreachable
+	breakGlass *breakGlass
 }
 
 func (s *svcAuth) String() string {
@@ -34,6 +37,7 @@ func (s *svcAuth) Init(opts ...auth.Option) {
 	s.rules = pb.NewRulesService("go.micro.auth", s.options.Client)
 
 	s.setupJWT()
+	s.breakGlass.setAccounts(s.options.BreakGlass)
 }
 
 func (s *svcAuth) Options() auth.Options {
@@ -161,6 +165,11 @@ func (s *svcAuth) Verify(acc *auth.Account, res *auth.Resource, opts ...auth.Ver
 		o(&options)
 	}
 
+	// break-glass accounts don't depend on the rules held by the auth service
+	if s.breakGlass.verify(acc, res) {
+		return nil
+	}
+
 	rs, err := s.Rules(
 		auth.RulesContext(options.Context),
 		auth.RulesNamespace(options.Namespace),
@@ -174,6 +183,11 @@ func (s *svcAuth) Verify(acc *auth.Account, res *auth.Resource, opts ...auth.Ver
 
 // Inspect a token
 func (s *svcAuth) Inspect(token string) (*auth.Account, error) {
+	// check if the token was issued locally for break-glass credentials
+	if acc, ok := s.breakGlass.inspect(token); ok {
+		return acc, nil
+	}
+
 	// try to decode JWT locally and fall back to srv if an error occurs
 	if len(strings.Split(token, ".")) == 3 && len(s.options.PublicKey) > 0 {
 		return s.token.Inspect(token)
@@ -230,7 +244,10 @@ func (s *svcAuth) Token(opts ...auth.TokenOption) (*auth.Token, error) {
 			Namespace: options.Issuer,
 		},
 	}, s.callOpts()...)
-	if err != nil {
+	if err != nil && unreachable(err) && len(options.ID) > 0 {
+		// fall back to the break-glass accounts so operators aren't locked out
+		return s.breakGlass.login(options.ID, options.Secret, options.Issuer, err)
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -294,11 +311,13 @@ func NewAuth(opts ...auth.Option) auth.Auth {
 	}
 
 	service := &svcAuth{
-		auth:    pb.NewAuthService("go.micro.auth", options.Client),
-		rules:   pb.NewRulesService("go.micro.auth", options.Client),
-		options: options,
+		auth:       pb.NewAuthService("go.micro.auth", options.Client),
+		rules:      pb.NewRulesService("go.micro.auth", options.Client),
+		options:    options,
+		breakGlass: newBreakGlass(),
 	}
 	service.setupJWT()
+	service.breakGlass.setAccounts(options.BreakGlass)
 
 	return service
 }
@@ -313,6 +332,12 @@ func (s *svcAuth) setupJWT() {
 		tokenOpts = append(tokenOpts, token.WithPublicKey(key))
 	}
 
+	// keys which have been rotated out are still accepted until
+	// the tokens signed by them have expired
+	if keys := s.options.VerificationKeys; len(keys) > 0 {
+		tokenOpts = append(tokenOpts, token.WithVerificationKeys(keys...))
+	}
+
 	// if we have a JWT private key passed as an option,
 	// we can generate accounts locally and not have to make
 	// an RPC call, this is used for micro clients such as
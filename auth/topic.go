@@ -0,0 +1,34 @@
+package auth
+
+const (
+	// TopicResourceType is the resource type used for broker topics
+	TopicResourceType = "topic"
+	// TopicPublish is the endpoint verified before publishing to a topic
+	TopicPublish = "Publish"
+	// TopicSubscribe is the endpoint verified before subscribing to a topic
+	TopicSubscribe = "Subscribe"
+)
+
+// TopicResource returns the resource used to verify access to a broker topic. Rules can
+// match the topic exactly or a pattern such as go.micro.events.* which matches every
+// topic under that prefix.
+func TopicResource(topic, endpoint string) *Resource {
+	return &Resource{
+		Type:     TopicResourceType,
+		Name:     topic,
+		Endpoint: endpoint,
+	}
+}
+
+// VerifyTopic verifies the account the auth is authenticated as has access to
+// the topic, returning ErrInvalidToken if its token can't be inspected
+func VerifyTopic(a Auth, topic, endpoint string) error {
+	var acc *Account
+	if t := a.Options().Token; t != nil {
+		var err error
+		if acc, err = a.Inspect(t.AccessToken); err != nil {
+			return ErrInvalidToken
+		}
+	}
+	return a.Verify(acc, TopicResource(topic, endpoint))
+}
@@ -0,0 +1,231 @@
+package broker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+)
+
+var (
+	// DefaultBatchSize is the max size of the batches delivered
+	DefaultBatchSize = 100
+	// DefaultBatchWait is the max time the first message of a batch waits
+	// for the batch to fill up
+	DefaultBatchWait = time.Second
+
+	// log of the broker module
+	log = logger.NewModule("broker")
+)
+
+// BatchHandler is used to process the batches of messages of a subscription
+type BatchHandler func([]Event) error
+
+// BatchPublisher is implemented by the brokers which can publish a batch of
+// messages natively rather than one message at a time
+type BatchPublisher interface {
+	PublishBatch(topic string, msgs []*Message, opts ...PublishOption) error
+}
+
+// BatchSubscriber is implemented by the brokers which can deliver batches
+// of messages natively
+type BatchSubscriber interface {
+	SubscribeBatch(topic string, h BatchHandler, opts ...SubscribeOption) (Subscriber, error)
+}
+
+// BatchBroker is a broker publishing and subscribing to batches of messages
+type BatchBroker interface {
+	Broker
+	BatchPublisher
+	BatchSubscriber
+}
+
+type batchBroker struct {
+	Broker
+}
+
+// PublishBatch publishes the messages in one batch if the broker is a
+// BatchPublisher, one at a time otherwise
+func (b *batchBroker) PublishBatch(topic string, msgs []*Message, opts ...PublishOption) error {
+	if p, ok := b.Broker.(BatchPublisher); ok {
+		return p.PublishBatch(topic, msgs, opts...)
+	}
+
+	for _, msg := range msgs {
+		if err := b.Broker.Publish(topic, msg, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubscribeBatch delivers the batches natively if the broker is a
+// BatchSubscriber. Otherwise the messages are acked, or requeued, once the
+// batch they're in is handled. The brokers which can't requeue a message
+// once its handler returned drop the messages of the batches which fail.
+func (b *batchBroker) SubscribeBatch(topic string, h BatchHandler, opts ...SubscribeOption) (Subscriber, error) {
+	if s, ok := b.Broker.(BatchSubscriber); ok {
+		return s.SubscribeBatch(topic, h, opts...)
+	}
+
+	options := NewSubscribeOptions(opts...)
+
+	bt := &batcher{
+		handler: h,
+		autoAck: options.AutoAck,
+		size:    options.BatchSize,
+		wait:    options.BatchWait,
+	}
+	if bt.size <= 0 {
+		bt.size = DefaultBatchSize
+	}
+	if bt.wait <= 0 {
+		bt.wait = DefaultBatchWait
+	}
+
+	// the messages are acked once their batch is handled, the broker is
+	// told the size of the batches so it can deliver enough messages at once
+	sub, err := b.Broker.Subscribe(topic, bt.add, append(opts[:len(opts):len(opts)], BatchSize(bt.size), DisableAutoAck())...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batchSubscriber{Subscriber: sub, b: bt}, nil
+}
+
+type batcher struct {
+	handler BatchHandler
+	autoAck bool
+	size    int
+	wait    time.Duration
+
+	sync.Mutex
+	events []Event
+	timer  *time.Timer
+	// gen is incremented for each batch so the timer of a batch handled
+	// already doesn't flush the next one
+	gen int
+
+	// the batches are handled one at a time
+	hmtx sync.Mutex
+}
+
+// add adds the event to the batch, which is handled once full
+func (b *batcher) add(e Event) error {
+	b.Lock()
+	b.events = append(b.events, e)
+	if len(b.events) == 1 {
+		gen := b.gen
+		b.timer = time.AfterFunc(b.wait, func() {
+			b.expire(gen)
+		})
+	}
+	var batch []Event
+	if len(b.events) >= b.size {
+		batch = b.take()
+	}
+	b.Unlock()
+
+	b.handle(batch)
+	return nil
+}
+
+// take returns the batch and starts the next one, it's called with the lock
+func (b *batcher) take() []Event {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	batch := b.events
+	b.events = nil
+	b.gen++
+	return batch
+}
+
+// expire handles the batch once it waited long enough
+func (b *batcher) expire(gen int) {
+	b.Lock()
+	if gen != b.gen {
+		b.Unlock()
+		return
+	}
+	batch := b.take()
+	b.Unlock()
+
+	b.handle(batch)
+}
+
+// flush handles the batch whatever its size
+func (b *batcher) flush() {
+	b.Lock()
+	batch := b.take()
+	b.Unlock()
+
+	b.handle(batch)
+}
+
+// handle calls the handler with the batch, then acks its messages if it
+// succeeded or requeues them otherwise
+func (b *batcher) handle(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	b.hmtx.Lock()
+	defer b.hmtx.Unlock()
+
+	if err := b.handler(batch); err != nil {
+		if logger.V(logger.ErrorLevel, log) {
+			log.Errorf("[batch]: handling %d messages of %s failed: %v", len(batch), batch[0].Topic(), err)
+		}
+		for _, e := range batch {
+			if err := e.Nack(true); err != nil {
+				if logger.V(logger.WarnLevel, log) {
+					log.Warnf("[batch]: requeuing a message of %s failed: %v", e.Topic(), err)
+				}
+			}
+		}
+		return
+	}
+
+	if !b.autoAck {
+		return
+	}
+	for _, e := range batch {
+		e.Ack()
+	}
+}
+
+type batchSubscriber struct {
+	Subscriber
+	b *batcher
+}
+
+// Unsubscribe handles the messages of the last batch before unsubscribing
+func (s *batchSubscriber) Unsubscribe() error {
+	s.b.flush()
+	err := s.Subscriber.Unsubscribe()
+	// handles the messages delivered meanwhile
+	s.b.flush()
+	return err
+}
+
+// Batch returns a BatchBroker publishing and subscribing to the batches of
+// messages through b, natively if it's a BatchPublisher or BatchSubscriber
+func Batch(b Broker) BatchBroker {
+	if bb, ok := b.(BatchBroker); ok {
+		return bb
+	}
+	return &batchBroker{b}
+}
+
+// PublishBatch publishes the messages to the topic with the default broker
+func PublishBatch(topic string, msgs []*Message, opts ...PublishOption) error {
+	return Batch(DefaultBroker).PublishBatch(topic, msgs, opts...)
+}
+
+// SubscribeBatch subscribes to the batches of messages of the topic with
+// the default broker
+func SubscribeBatch(topic string, h BatchHandler, opts ...SubscribeOption) (Subscriber, error) {
+	return Batch(DefaultBroker).SubscribeBatch(topic, h, opts...)
+}
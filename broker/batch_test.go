@@ -0,0 +1,53 @@
+package broker_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+)
+
+func TestBatch(t *testing.T) {
+	b := broker.Batch(bmemory.NewBroker())
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	batches := make(chan []string, 10)
+	sub, err := b.SubscribeBatch("test", func(events []broker.Event) error {
+		var batch []string
+		for _, e := range events {
+			batch = append(batch, string(e.Message().Body))
+		}
+		batches <- batch
+		return nil
+	}, broker.BatchSize(2), broker.BatchWait(time.Millisecond*100))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	var msgs []*broker.Message
+	for i := 0; i < 5; i++ {
+		msgs = append(msgs, &broker.Message{Body: []byte(fmt.Sprint(i))})
+	}
+	if err := b.PublishBatch("test", msgs); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	// the last batch is delivered once it waited for the others
+	expected := [][]string{{"0", "1"}, {"2", "3"}, {"4"}}
+	for _, e := range expected {
+		select {
+		case batch := <-batches:
+			if fmt.Sprint(batch) != fmt.Sprint(e) {
+				t.Fatalf("Expected batch %v, got %v", e, batch)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for batch %v", e)
+		}
+	}
+}
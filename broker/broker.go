@@ -28,6 +28,11 @@ type Event interface {
 	Topic() string
 	Message() *Message
 	Ack() error
+	// Nack rejects the message, which is redelivered if requeued unless it
+	// was redelivered the max redeliveries of the subscription already.
+	// The brokers which can't redeliver messages fail to requeue them with
+	// ErrUnsupportedDelivery.
+	Nack(requeue bool) error
 	Error() error
 }
 
@@ -0,0 +1,176 @@
+// Package dedup provides a broker dropping the duplicates of the messages
+// published with an idempotency key, e.g. redelivered after a subscriber
+// crashed before acking them. The keys of the messages handled are kept in
+// a store for the dedup window, the store must be shared by the consumers.
+//
+// A consumer claims a message in the store for a short lease before handling
+// it, so a duplicate delivered to another consumer meanwhile is requeued
+// rather than handled too. The store has no conditional writes, so claims
+// made at the same instant can still both succeed.
+package dedup
+
+import (
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/store"
+)
+
+// Header is the header of the messages the idempotency key is sent in
+const Header = "Micro-Idempotency-Key"
+
+// handled is the value of the records of the messages handled, the records
+// of the messages being handled hold the id of the claim
+var handled = []byte("handled")
+
+var (
+	// log of the broker module
+	log = logger.NewModule("broker")
+)
+
+type dedupBroker struct {
+	broker.Broker
+	opts Options
+}
+
+// event records if the message was requeued, it's not handled then
+type event struct {
+	broker.Event
+	requeued bool
+}
+
+func (e *event) Nack(requeue bool) error {
+	if err := e.Event.Nack(requeue); err != nil {
+		return err
+	}
+	e.requeued = requeue
+	return nil
+}
+
+// Publish sends the idempotency key of the message in its header
+func (d *dedupBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(options.IdempotencyKey) > 0 {
+		header := make(map[string]string, len(msg.Header)+1)
+		for k, v := range msg.Header {
+			header[k] = v
+		}
+		header[Header] = options.IdempotencyKey
+		msg = &broker.Message{Header: header, Body: msg.Body}
+	}
+
+	return d.Broker.Publish(topic, msg, opts...)
+}
+
+// Subscribe drops the messages handled by the queue already, or by the
+// subscriber if it has no queue
+func (d *dedupBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	options := broker.NewSubscribeOptions(opts...)
+
+	group := options.Queue
+	if len(group) == 0 {
+		group = uuid.New().String()
+	}
+
+	return d.Broker.Subscribe(topic, d.handler(d.opts.Prefix+topic+"/"+group+"/", h), opts...)
+}
+
+// store returns the store of the options, or the default store
+func (d *dedupBroker) store() store.Store {
+	if d.opts.Store != nil {
+		return d.opts.Store
+	}
+	return store.DefaultStore
+}
+
+// handler calls h with the messages whose key isn't in the store. The message
+// is claimed for the lease while h handles it, and the key written for the
+// window once it's handled, so the message isn't dropped when it's
+// redelivered after h failed or requeued it.
+func (d *dedupBroker) handler(prefix string, h broker.Handler) broker.Handler {
+	return func(e broker.Event) error {
+		id := e.Message().Header[Header]
+		if len(id) == 0 {
+			return h(e)
+		}
+		key := prefix + id
+		s := d.store()
+
+		recs, err := s.Read(key)
+		switch {
+		case err == nil && len(recs) > 0:
+			if string(recs[0].Value) == string(handled) {
+				if logger.V(logger.DebugLevel, log) {
+					log.Debugf("[dedup]: dropping duplicate %s of %s", id, e.Topic())
+				}
+				return e.Ack()
+			}
+			// requeued in case the consumer handling it fails
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("[dedup]: requeuing %s of %s being handled", id, e.Topic())
+			}
+			return e.Nack(true)
+		case err != nil && err != store.ErrNotFound:
+			// handled rather than dropped if the store is unavailable
+			if logger.V(logger.WarnLevel, log) {
+				log.Warnf("[dedup]: error reading %s: %v", key, err)
+			}
+		}
+
+		// claim the message, backing off if another consumer claimed it since
+		claim := uuid.New().String()
+		if err := s.Write(&store.Record{Key: key, Value: []byte(claim), Expiry: d.opts.Lease}); err != nil {
+			if logger.V(logger.WarnLevel, log) {
+				log.Warnf("[dedup]: error claiming %s: %v", key, err)
+			}
+		} else if recs, err := s.Read(key); err == nil && len(recs) > 0 && string(recs[0].Value) != claim {
+			return e.Nack(true)
+		}
+
+		ev := &event{Event: e}
+		if err := h(ev); err != nil || ev.requeued {
+			// release the claim so the redelivery is handled
+			if derr := s.Delete(key); derr != nil && derr != store.ErrNotFound {
+				if logger.V(logger.WarnLevel, log) {
+					log.Warnf("[dedup]: error releasing %s: %v", key, derr)
+				}
+			}
+			return err
+		}
+
+		if err := s.Write(&store.Record{Key: key, Value: handled, Expiry: d.opts.Window}); err != nil {
+			if logger.V(logger.WarnLevel, log) {
+				log.Warnf("[dedup]: error writing %s: %v", key, err)
+			}
+		}
+		return nil
+	}
+}
+
+func (d *dedupBroker) String() string {
+	return "dedup"
+}
+
+// NewBroker returns a broker publishing and subscribing through b which
+// drops the duplicates of the messages published with an idempotency key.
+// The publishers and the subscribers must both use it.
+func NewBroker(b broker.Broker, opts ...Option) broker.Broker {
+	options := Options{
+		Window: DefaultWindow,
+		Lease:  DefaultLease,
+		Prefix: DefaultPrefix,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &dedupBroker{
+		Broker: b,
+		opts:   options,
+	}
+}
@@ -0,0 +1,143 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/store"
+	smemory "github.com/micro/go-micro/v2/store/memory"
+)
+
+func TestDedup(t *testing.T) {
+	b := NewBroker(memory.NewBroker(), Store(smemory.NewStore()), Window(time.Millisecond*100))
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	var calls []string
+	sub, err := b.Subscribe("payments", func(e broker.Event) error {
+		calls = append(calls, string(e.Message().Body))
+		return nil
+	}, broker.Queue("billing"))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	publish := func(body, key string) {
+		var opts []broker.PublishOption
+		if len(key) > 0 {
+			opts = append(opts, broker.IdempotencyKey(key))
+		}
+		if err := b.Publish("payments", &broker.Message{Body: []byte(body)}, opts...); err != nil {
+			t.Fatalf("Unexpected publish error %v", err)
+		}
+	}
+
+	publish("1", "payment-1")
+	publish("1 again", "payment-1")
+	publish("2", "payment-2")
+	// the messages without a key are never dropped
+	publish("3", "")
+	publish("3", "")
+
+	expected := []string{"1", "2", "3", "3"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, calls)
+		}
+	}
+
+	// handled again past the window
+	time.Sleep(time.Millisecond * 200)
+	publish("1 later", "payment-1")
+	if last := calls[len(calls)-1]; last != "1 later" {
+		t.Fatalf("Expected the message to be handled past the window, got %v", calls)
+	}
+}
+
+func TestDedupFailure(t *testing.T) {
+	b := NewBroker(memory.NewBroker(), Store(smemory.NewStore()))
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	// the message which failed isn't dropped when redelivered
+	calls := make(chan int, 10)
+	var n int
+	sub, err := b.Subscribe("payments", func(e broker.Event) error {
+		n++
+		calls <- n
+		if n == 1 {
+			return e.Nack(true)
+		}
+		return nil
+	}, broker.RedeliveryDelay(time.Millisecond*10))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if err := b.Publish("payments", &broker.Message{Body: []byte("1")}, broker.IdempotencyKey("payment-1")); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected delivery %d of the message", i)
+		}
+	}
+}
+
+func TestDedupInProgress(t *testing.T) {
+	st := smemory.NewStore()
+	b := NewBroker(memory.NewBroker(), Store(st))
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	calls := make(chan string, 10)
+	sub, err := b.Subscribe("payments", func(e broker.Event) error {
+		calls <- string(e.Message().Body)
+		return nil
+	}, broker.Queue("billing"), broker.RedeliveryDelay(time.Millisecond*20))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// another consumer claimed the message, it's requeued rather than handled
+	key := DefaultPrefix + "payments/billing/payment-1"
+	if err := st.Write(&store.Record{Key: key, Value: []byte("claim"), Expiry: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Publish("payments", &broker.Message{Body: []byte("1")}, broker.IdempotencyKey("payment-1")); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	select {
+	case body := <-calls:
+		t.Fatalf("Expected the message being handled not to be handled again, got %s", body)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	// handled once the claim is released
+	if err := st.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the message to be handled once the claim was released")
+	}
+}
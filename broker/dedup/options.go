@@ -0,0 +1,63 @@
+package dedup
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v2/store"
+)
+
+var (
+	// DefaultWindow the keys of the messages handled are kept for
+	DefaultWindow = time.Hour * 24
+	// DefaultLease a message is claimed for while it's handled, a claim left
+	// by a consumer which crashed expires after it
+	DefaultLease = time.Minute
+	// DefaultPrefix of the keys in the store
+	DefaultPrefix = "dedup/"
+)
+
+// Options of the dedup broker
+type Options struct {
+	// Store the keys of the messages handled are kept in, the store must
+	// be shared by the instances of a service to drop the duplicates
+	// delivered to another one. Defaults to the store.DefaultStore.
+	Store store.Store
+	// Window the keys are kept for, the duplicates published after are
+	// handled again
+	Window time.Duration
+	// Lease a message is claimed for while it's handled
+	Lease time.Duration
+	// Prefix of the keys in the store
+	Prefix string
+}
+
+type Option func(o *Options)
+
+// Store sets the store the keys of the messages handled are kept in
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Window sets how long the keys of the messages handled are kept for
+func Window(d time.Duration) Option {
+	return func(o *Options) {
+		o.Window = d
+	}
+}
+
+// Lease sets how long a message is claimed for while it's handled, it should
+// be longer than the handler takes
+func Lease(d time.Duration) Option {
+	return func(o *Options) {
+		o.Lease = d
+	}
+}
+
+// Prefix sets the prefix of the keys in the store
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
@@ -0,0 +1,43 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedDelivery is returned by Subscribe when the broker can't
+// honour the delivery mode declared by the subscription
+var ErrUnsupportedDelivery = errors.New("delivery mode not supported")
+
+// Delivery is the delivery guarantee of a subscription
+type Delivery int
+
+const (
+	// DeliverDefault leaves the delivery semantics to the broker
+	DeliverDefault Delivery = iota
+	// AtMostOnce delivers a message once or not at all. Messages
+	// which fail to be handled are never redelivered.
+	AtMostOnce
+	// AtLeastOnce redelivers a message until it's handled, so the
+	// handler may see the same message more than once.
+	AtLeastOnce
+)
+
+func (d Delivery) String() string {
+	switch d {
+	case DeliverDefault:
+		return "default"
+	case AtMostOnce:
+		return "at-most-once"
+	case AtLeastOnce:
+		return "at-least-once"
+	default:
+		return "unknown"
+	}
+}
+
+// UnsupportedDelivery returns the error a broker fails to subscribe with
+// when it doesn't support the given delivery mode
+func UnsupportedDelivery(broker string, d Delivery) error {
+	return fmt.Errorf("%w: %s broker does not support %s delivery", ErrUnsupportedDelivery, broker, d)
+}
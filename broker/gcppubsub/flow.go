@@ -0,0 +1,65 @@
+package gcppubsub
+
+import (
+	"sync"
+)
+
+// flowController limits the messages of a subscription being handled at
+// once, in number and size
+type flowController struct {
+	maxCount int
+	maxBytes int
+
+	sync.Mutex
+	cond   *sync.Cond
+	count  int
+	bytes  int
+	closed bool
+}
+
+func newFlowController(maxCount, maxBytes int) *flowController {
+	f := &flowController{maxCount: maxCount, maxBytes: maxBytes}
+	f.cond = sync.NewCond(&f.Mutex)
+	return f
+}
+
+// wait blocks until messages can be handled, returning how many, or 0 once
+// closed
+func (f *flowController) wait() int {
+	f.Lock()
+	defer f.Unlock()
+	for !f.closed && (f.count >= f.maxCount || f.bytes >= f.maxBytes) {
+		f.cond.Wait()
+	}
+	if f.closed {
+		return 0
+	}
+	return f.maxCount - f.count
+}
+
+// acquire counts a message being handled. The messages pulled are leased
+// already so they're never held back, the size limit may be exceeded by
+// the last messages pulled.
+func (f *flowController) acquire(size int) {
+	f.Lock()
+	f.count++
+	f.bytes += size
+	f.Unlock()
+}
+
+// release counts a message as handled
+func (f *flowController) release(size int) {
+	f.Lock()
+	f.count--
+	f.bytes -= size
+	f.Unlock()
+	f.cond.Broadcast()
+}
+
+// close wakes up the waits
+func (f *flowController) close() {
+	f.Lock()
+	f.closed = true
+	f.Unlock()
+	f.cond.Broadcast()
+}
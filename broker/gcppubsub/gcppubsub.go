@@ -0,0 +1,831 @@
+// Package gcppubsub provides a broker on google cloud pub/sub. The topics and
+// subscriptions are created as they're needed. The queues are subscriptions
+// shared by their subscribers, named after the topic and the queue, while the
+// subscribers without a queue get a subscription of their own.
+package gcppubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	vkit "cloud.google.com/go/pubsub/apiv1"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/auth/credentials"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/codec/json"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/util/backoff"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	gcreds "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// log of the broker module
+	log = logger.NewModule("broker")
+
+	// DefaultAddress of google cloud pub/sub
+	DefaultAddress = "pubsub.googleapis.com:443"
+	// DefaultAckDeadline of the subscriptions created
+	DefaultAckDeadline = time.Minute
+	// DefaultMaxOutstandingMessages of a subscription handled at once
+	DefaultMaxOutstandingMessages = 1000
+	// DefaultMaxOutstandingBytes of a subscription handled at once
+	DefaultMaxOutstandingBytes = int(1e9)
+	// DefaultNumGoroutines pulling the messages of a subscription
+	DefaultNumGoroutines = 1
+	// DefaultMaxExtension of the ack deadline of a message being handled
+	DefaultMaxExtension = time.Hour
+
+	// the max number of messages of a pull
+	maxPull = 1000
+	// the max number of messages of a publish request
+	maxPublishMessages = 1000
+	// the bounds of the delivery attempts of a dead letter policy
+	minDeliveryAttempts      = 5
+	maxDeliveryAttemptsLimit = 100
+	// the subscriptions without a queue are deleted once unsubscribed, they
+	// expire once idle for a day in case they're not, e.g. after a crash
+	subscriptionExpiry = 24 * time.Hour
+)
+
+type pubsubBroker struct {
+	sync.RWMutex
+
+	opts    broker.Options
+	addr    string
+	project string
+	copts   []option.ClientOption
+
+	autoCreate    bool
+	ackDeadline   time.Duration
+	maxExtension  time.Duration
+	maxMessages   int
+	maxBytes      int
+	numGoroutines int
+
+	pub *vkit.PublisherClient
+	sub *vkit.SubscriberClient
+
+	subscribers map[*subscriber]bool
+}
+
+type subscriber struct {
+	b       *pubsubBroker
+	client  *vkit.SubscriberClient
+	topic   string
+	name    string
+	ordered bool
+	opts    broker.SubscribeOptions
+	handler broker.Handler
+	flow    *flowController
+
+	// the ack deadline is extended up to the max extension while handling
+	ackDeadline  time.Duration
+	maxExtension time.Duration
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	pullers  sync.WaitGroup
+	handlers sync.WaitGroup
+	once     sync.Once
+
+	// the messages pending by ordering key, while the key's being handled
+	sync.Mutex
+	keys map[string][]*pb.ReceivedMessage
+}
+
+type publication struct {
+	t   string
+	id  string
+	err error
+	m   *broker.Message
+	s   *subscriber
+	// acked or nacked
+	done bool
+	// lease of the message while it's handled, nil if not extended
+	lease *lease
+}
+
+// lease extends the ack deadline of a message while it's handled, so it isn't
+// redelivered when the handler runs longer than the deadline
+type lease struct {
+	sync.Mutex
+	stopped bool
+	done    chan struct{}
+}
+
+// stop stops extending the deadline, once an extension being sent was
+func (l *lease) stop() {
+	if l == nil {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	if !l.stopped {
+		l.stopped = true
+		close(l.done)
+	}
+}
+
+// extend extends the ack deadline of the message every half deadline, until
+// the lease is stopped or it was extended for the max extension
+func (s *subscriber) extend(rm *pb.ReceivedMessage) *lease {
+	l := &lease{done: make(chan struct{})}
+	if s.ackDeadline <= 0 || s.maxExtension <= 0 {
+		return l
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.ackDeadline / 2)
+		defer ticker.Stop()
+		expiry := time.Now().Add(s.maxExtension)
+
+		for {
+			select {
+			case <-l.done:
+				return
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				if now.After(expiry) {
+					return
+				}
+			}
+
+			l.Lock()
+			if l.stopped {
+				l.Unlock()
+				return
+			}
+			err := s.client.ModifyAckDeadline(context.Background(), &pb.ModifyAckDeadlineRequest{
+				Subscription:       s.name,
+				AckIds:             []string{rm.AckId},
+				AckDeadlineSeconds: int32(s.ackDeadline / time.Second),
+			})
+			l.Unlock()
+
+			if err != nil && logger.V(logger.WarnLevel, log) {
+				log.Warnf("Error extending the deadline of message %s of subscription %s: %v", rm.GetMessage().GetMessageId(), s.name, err)
+			}
+		}
+	}()
+
+	return l
+}
+
+func (p *publication) Topic() string {
+	return p.t
+}
+
+func (p *publication) Message() *broker.Message {
+	return p.m
+}
+
+func (p *publication) Ack() error {
+	if p.done {
+		return nil
+	}
+	p.lease.stop()
+	err := p.s.client.Acknowledge(context.Background(), &pb.AcknowledgeRequest{
+		Subscription: p.s.name,
+		AckIds:       []string{p.id},
+	})
+	if err == nil {
+		p.done = true
+	}
+	return err
+}
+
+// Nack redelivers the message after the redelivery delay if requeued, it's
+// acked otherwise so it's dropped
+func (p *publication) Nack(requeue bool) error {
+	if p.done {
+		return nil
+	}
+	if !requeue {
+		return p.Ack()
+	}
+	p.lease.stop()
+	err := p.s.client.ModifyAckDeadline(context.Background(), &pb.ModifyAckDeadlineRequest{
+		Subscription:       p.s.name,
+		AckIds:             []string{p.id},
+		AckDeadlineSeconds: int32(p.s.opts.RedeliveryDelay / time.Second),
+	})
+	if err == nil {
+		p.done = true
+	}
+	return err
+}
+
+func (p *publication) Error() error {
+	return p.err
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+func (s *subscriber) Unsubscribe() error {
+	var err error
+	s.once.Do(func() {
+		s.cancel()
+		s.flow.close()
+		s.pullers.Wait()
+		s.handlers.Wait()
+
+		s.b.Lock()
+		delete(s.b.subscribers, s)
+		s.b.Unlock()
+
+		// the subscriptions of the queues are kept for their messages
+		if len(s.opts.Queue) == 0 {
+			err = s.client.DeleteSubscription(context.Background(), &pb.DeleteSubscriptionRequest{
+				Subscription: s.name,
+			})
+		}
+	})
+	return err
+}
+
+// pull pulls the messages of the subscription until unsubscribed
+func (s *subscriber) pull() {
+	defer s.pullers.Done()
+
+	failures := 0
+
+	for {
+		n := s.flow.wait()
+		if n == 0 {
+			return
+		}
+		if n > maxPull {
+			n = maxPull
+		}
+
+		rsp, err := s.client.Pull(s.ctx, &pb.PullRequest{
+			Subscription: s.name,
+			MaxMessages:  int32(n),
+		})
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			// no messages before the deadline of the pull
+			if status.Code(err) == codes.DeadlineExceeded {
+				continue
+			}
+			failures++
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Error pulling subscription %s: %v", s.name, err)
+			}
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(backoff.Do(failures)):
+			}
+			continue
+		}
+		failures = 0
+
+		for _, rm := range rsp.ReceivedMessages {
+			s.dispatch(rm)
+		}
+	}
+}
+
+// dispatch handles the message, after the messages with the same ordering
+// key if the subscription is ordered
+func (s *subscriber) dispatch(rm *pb.ReceivedMessage) {
+	s.flow.acquire(len(rm.GetMessage().GetData()))
+
+	key := rm.GetMessage().GetOrderingKey()
+	if !s.ordered || len(key) == 0 {
+		s.handlers.Add(1)
+		go func() {
+			defer s.handlers.Done()
+			s.handle(rm)
+		}()
+		return
+	}
+
+	s.Lock()
+	pending, busy := s.keys[key]
+	s.keys[key] = append(pending, rm)
+	s.Unlock()
+
+	if !busy {
+		s.handlers.Add(1)
+		go s.handleOrdered(key)
+	}
+}
+
+// handleOrdered handles the messages of the ordering key one after another
+// until there are none pending. Once one fails the others are nacked, so
+// they're redelivered after it.
+func (s *subscriber) handleOrdered(key string) {
+	defer s.handlers.Done()
+
+	for {
+		s.Lock()
+		pending := s.keys[key]
+		if len(pending) == 0 {
+			delete(s.keys, key)
+			s.Unlock()
+			return
+		}
+		rm := pending[0]
+		s.keys[key] = pending[1:]
+		s.Unlock()
+
+		if s.ctx.Err() != nil {
+			s.nack(rm, 0)
+			s.flow.release(len(rm.GetMessage().GetData()))
+		} else if s.handle(rm) {
+			continue
+		}
+
+		s.Lock()
+		pending = s.keys[key]
+		delete(s.keys, key)
+		s.Unlock()
+
+		for _, rm := range pending {
+			s.nack(rm, 0)
+			s.flow.release(len(rm.GetMessage().GetData()))
+		}
+		return
+	}
+}
+
+// handle calls the handler with the message, returning false if it failed.
+// The messages which fail are nacked so they're redelivered after the
+// redelivery delay.
+func (s *subscriber) handle(rm *pb.ReceivedMessage) bool {
+	msg := rm.GetMessage()
+	defer s.flow.release(len(msg.GetData()))
+
+	header := msg.GetAttributes()
+	if header == nil {
+		header = make(map[string]string)
+	}
+	pub := &publication{
+		t:  s.topic,
+		id: rm.AckId,
+		m:  &broker.Message{Header: header, Body: msg.GetData()},
+		s:  s,
+	}
+	eh := s.b.opts.ErrorHandler
+
+	// the message is acked before it's handled so it's never redelivered
+	if s.opts.Delivery == broker.AtMostOnce {
+		if err := pub.Ack(); err != nil {
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Error acking message %s of subscription %s: %v", msg.GetMessageId(), s.name, err)
+			}
+			return false
+		}
+	}
+
+	// the deadline is extended while handling a message which isn't acked yet
+	if !pub.done {
+		pub.lease = s.extend(rm)
+	}
+	err := s.handler(pub)
+	pub.lease.stop()
+
+	if err != nil {
+		pub.err = err
+		if logger.V(logger.ErrorLevel, log) {
+			log.Error(err)
+		}
+		if eh != nil {
+			eh(pub)
+		}
+		if !pub.done {
+			s.nack(rm, s.opts.RedeliveryDelay)
+		}
+		return false
+	}
+
+	if s.opts.AutoAck {
+		if err := pub.Ack(); err != nil && logger.V(logger.ErrorLevel, log) {
+			log.Errorf("Error acking message %s of subscription %s: %v", msg.GetMessageId(), s.name, err)
+		}
+	}
+	return true
+}
+
+// nack makes the message available for redelivery after the delay
+func (s *subscriber) nack(rm *pb.ReceivedMessage, delay time.Duration) {
+	err := s.client.ModifyAckDeadline(context.Background(), &pb.ModifyAckDeadlineRequest{
+		Subscription:       s.name,
+		AckIds:             []string{rm.AckId},
+		AckDeadlineSeconds: int32(delay / time.Second),
+	})
+	if err != nil && logger.V(logger.ErrorLevel, log) {
+		log.Errorf("Error nacking message %s of subscription %s: %v", rm.GetMessage().GetMessageId(), s.name, err)
+	}
+}
+
+// tokenSource issues the oauth2 tokens of the credentials of a provider
+type tokenSource struct {
+	p credentials.Provider
+}
+
+func (t *tokenSource) Token() (*oauth2.Token, error) {
+	c, err := t.p.Credentials(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: c.Token, TokenType: "Bearer", Expiry: c.Expiry}, nil
+}
+
+func (r *pubsubBroker) Address() string {
+	r.RLock()
+	defer r.RUnlock()
+	return r.addr
+}
+
+func (r *pubsubBroker) Connect() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.pub != nil {
+		return nil
+	}
+
+	if len(r.project) == 0 {
+		return errors.New("project id not set")
+	}
+
+	ctx := context.Background()
+
+	pub, err := vkit.NewPublisherClient(ctx, r.copts...)
+	if err != nil {
+		if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Error connecting to broker: %v", err)
+		}
+		return err
+	}
+
+	sub, err := vkit.NewSubscriberClient(ctx, r.copts...)
+	if err != nil {
+		pub.Close()
+		if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Error connecting to broker: %v", err)
+		}
+		return err
+	}
+
+	r.pub = pub
+	r.sub = sub
+	return nil
+}
+
+func (r *pubsubBroker) Disconnect() error {
+	r.RLock()
+	subs := make([]*subscriber, 0, len(r.subscribers))
+	for s := range r.subscribers {
+		subs = append(subs, s)
+	}
+	r.RUnlock()
+
+	// stop the subscribers before closing the client they pull with
+	for _, s := range subs {
+		s.Unsubscribe()
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.pub == nil {
+		return nil
+	}
+	err := r.pub.Close()
+	if serr := r.sub.Close(); err == nil {
+		err = serr
+	}
+	r.pub = nil
+	r.sub = nil
+	return err
+}
+
+func (r *pubsubBroker) Init(opts ...broker.Option) error {
+	r.Lock()
+	defer r.Unlock()
+	r.setOption(opts...)
+	return nil
+}
+
+func (r *pubsubBroker) Options() broker.Options {
+	return r.opts
+}
+
+func (r *pubsubBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	return r.PublishBatch(topic, []*broker.Message{msg}, opts...)
+}
+
+// PublishBatch publishes the messages in as few requests as pub/sub accepts,
+// the messages of a request are published or not all together
+func (r *pubsubBroker) PublishBatch(topic string, msgs []*broker.Message, opts ...broker.PublishOption) error {
+	r.RLock()
+	client := r.pub
+	r.RUnlock()
+
+	if client == nil {
+		return errors.New("not connected")
+	}
+
+	options := broker.PublishOptions{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	key, _ := options.Context.Value(orderingKeyKey{}).(string)
+	ctx := options.Context
+
+	for len(msgs) > 0 {
+		n := len(msgs)
+		if n > maxPublishMessages {
+			n = maxPublishMessages
+		}
+
+		req := &pb.PublishRequest{
+			Topic:    r.topicName(topic),
+			Messages: make([]*pb.PubsubMessage, 0, n),
+		}
+		for _, msg := range msgs[:n] {
+			req.Messages = append(req.Messages, &pb.PubsubMessage{
+				Data:        msg.Body,
+				Attributes:  msg.Header,
+				OrderingKey: key,
+			})
+		}
+
+		_, err := client.Publish(ctx, req)
+		if status.Code(err) == codes.NotFound && r.autoCreate {
+			if err = r.createTopic(ctx, client, topic); err == nil {
+				_, err = client.Publish(ctx, req)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		msgs = msgs[n:]
+	}
+
+	return nil
+}
+
+func (r *pubsubBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	r.RLock()
+	pub, client := r.pub, r.sub
+	r.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("not connected")
+	}
+
+	opt := broker.SubscribeOptions{
+		AutoAck: true,
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	if exactly, _ := opt.Context.Value(exactlyOnceKey{}).(bool); exactly {
+		return nil, fmt.Errorf("%w: %s broker does not support exactly-once delivery", broker.ErrUnsupportedDelivery, r.String())
+	}
+
+	ordered, _ := opt.Context.Value(messageOrderingKey{}).(bool)
+
+	id := topic + "-" + opt.Queue
+	if len(opt.Queue) == 0 {
+		id = topic + "-" + uuid.New().String()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &subscriber{
+		b:       r,
+		client:  client,
+		topic:   topic,
+		name:    fmt.Sprintf("projects/%s/subscriptions/%s", r.project, id),
+		ordered: ordered,
+		opts:    opt,
+		handler: handler,
+		flow:    newFlowController(r.maxMessages, r.maxBytes),
+		ctx:     ctx,
+		cancel:  cancel,
+		keys:    make(map[string][]*pb.ReceivedMessage),
+
+		ackDeadline:  r.ackDeadline,
+		maxExtension: r.maxExtension,
+	}
+
+	// the subscribers without a queue always get a subscription of their own
+	if len(opt.Queue) == 0 || r.autoCreate {
+		sub := &pb.Subscription{
+			Name:                  s.name,
+			Topic:                 r.topicName(topic),
+			AckDeadlineSeconds:    int32(r.ackDeadline / time.Second),
+			EnableMessageOrdering: ordered,
+		}
+		if len(opt.Queue) == 0 {
+			sub.ExpirationPolicy = &pb.ExpirationPolicy{Ttl: ptypes.DurationProto(subscriptionExpiry)}
+		}
+		// the messages are sent to the dead letter topic of the
+		// subscription once redelivered the max redeliveries, it gets a
+		// subscription so they're kept until read
+		if n := opt.MaxRedeliveries; n > 0 {
+			deadLetter := id + "-dead-letter"
+			if r.autoCreate {
+				if err := r.createSubscription(opt.Context, pub, client, deadLetter, &pb.Subscription{
+					Name:               fmt.Sprintf("projects/%s/subscriptions/%s", r.project, deadLetter),
+					Topic:              r.topicName(deadLetter),
+					AckDeadlineSeconds: int32(r.ackDeadline / time.Second),
+				}); err != nil {
+					cancel()
+					return nil, err
+				}
+			}
+			sub.DeadLetterPolicy = &pb.DeadLetterPolicy{
+				DeadLetterTopic:     r.topicName(deadLetter),
+				MaxDeliveryAttempts: maxDeliveryAttempts(n),
+			}
+		}
+		if err := r.createSubscription(opt.Context, pub, client, topic, sub); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	r.Lock()
+	r.subscribers[s] = true
+	r.Unlock()
+
+	// the messages are pulled in order by a single goroutine
+	n := r.numGoroutines
+	if ordered {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		s.pullers.Add(1)
+		go s.pull()
+	}
+
+	return s, nil
+}
+
+func (r *pubsubBroker) String() string {
+	return "gcppubsub"
+}
+
+// maxDeliveryAttempts returns the delivery attempts of the max redeliveries,
+// clamped to the 5 to 100 attempts pub/sub accepts
+func maxDeliveryAttempts(redeliveries int) int32 {
+	n := redeliveries + 1
+	if n < minDeliveryAttempts {
+		n = minDeliveryAttempts
+	}
+	if n > maxDeliveryAttemptsLimit {
+		n = maxDeliveryAttemptsLimit
+	}
+	return int32(n)
+}
+
+func (r *pubsubBroker) topicName(topic string) string {
+	return fmt.Sprintf("projects/%s/topics/%s", r.project, topic)
+}
+
+// createTopic creates the topic unless it exists
+func (r *pubsubBroker) createTopic(ctx context.Context, client *vkit.PublisherClient, topic string) error {
+	_, err := client.CreateTopic(ctx, &pb.Topic{Name: r.topicName(topic)})
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+// createSubscription creates the subscription unless it exists, and its
+// topic if auto created
+func (r *pubsubBroker) createSubscription(ctx context.Context, pub *vkit.PublisherClient, client *vkit.SubscriberClient, topic string, sub *pb.Subscription) error {
+	_, err := client.CreateSubscription(ctx, sub)
+	if status.Code(err) == codes.NotFound && r.autoCreate {
+		if err = r.createTopic(ctx, pub, topic); err == nil {
+			_, err = client.CreateSubscription(ctx, sub)
+		}
+	}
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+func (r *pubsubBroker) setOption(opts ...broker.Option) {
+	for _, o := range opts {
+		o(&r.opts)
+	}
+
+	if id, ok := r.opts.Context.Value(projectIDKey{}).(string); ok {
+		r.project = id
+	}
+
+	var copts []option.ClientOption
+
+	// the emulator takes precedence, as it does for the google clients
+	if host := os.Getenv("PUBSUB_EMULATOR_HOST"); len(host) > 0 {
+		r.addr = host
+		copts = append(copts,
+			option.WithEndpoint(host),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithInsecure()),
+		)
+	} else {
+		// only the first address is used
+		r.addr = DefaultAddress
+		if len(r.opts.Addrs) > 0 && len(r.opts.Addrs[0]) > 0 {
+			r.addr = r.opts.Addrs[0]
+			copts = append(copts, option.WithEndpoint(r.addr))
+		}
+		if r.opts.TLSConfig != nil {
+			copts = append(copts, option.WithGRPCDialOption(grpc.WithTransportCredentials(gcreds.NewTLS(r.opts.TLSConfig))))
+		}
+		// the tokens of the provider are used instead of the default
+		// credentials of google cloud
+		if creds := r.opts.Credentials; creds != nil {
+			copts = append(copts, option.WithTokenSource(oauth2.ReuseTokenSource(nil, &tokenSource{creds})))
+		}
+	}
+
+	if o, ok := r.opts.Context.Value(clientOptionsKey{}).([]option.ClientOption); ok {
+		copts = append(copts, o...)
+	}
+	r.copts = copts
+
+	if _, ok := r.opts.Context.Value(disableAutoCreateKey{}).(bool); ok {
+		r.autoCreate = false
+	}
+	if d, ok := r.opts.Context.Value(ackDeadlineKey{}).(time.Duration); ok && d > 0 {
+		r.ackDeadline = d
+	}
+	if d, ok := r.opts.Context.Value(maxExtensionKey{}).(time.Duration); ok {
+		r.maxExtension = d
+	}
+	if n, ok := r.opts.Context.Value(maxOutstandingMessagesKey{}).(int); ok && n > 0 {
+		r.maxMessages = n
+	}
+	if n, ok := r.opts.Context.Value(maxOutstandingBytesKey{}).(int); ok && n > 0 {
+		r.maxBytes = n
+	}
+	if n, ok := r.opts.Context.Value(numGoroutinesKey{}).(int); ok && n > 0 {
+		r.numGoroutines = n
+	}
+}
+
+// NewBroker returns a broker on google cloud pub/sub
+func NewBroker(opts ...broker.Option) broker.Broker {
+	options := broker.Options{
+		// Default codec, the messages are published with their header
+		// as attributes so it's not used
+		Codec:    json.Marshaler{},
+		Context:  context.Background(),
+		Registry: registry.DefaultRegistry,
+	}
+
+	r := &pubsubBroker{
+		opts:          options,
+		project:       os.Getenv("GOOGLE_CLOUD_PROJECT"),
+		autoCreate:    true,
+		ackDeadline:   DefaultAckDeadline,
+		maxExtension:  DefaultMaxExtension,
+		maxMessages:   DefaultMaxOutstandingMessages,
+		maxBytes:      DefaultMaxOutstandingBytes,
+		numGoroutines: DefaultNumGoroutines,
+		subscribers:   make(map[*subscriber]bool),
+	}
+	r.setOption(opts...)
+
+	return r
+}
@@ -0,0 +1,327 @@
+package gcppubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/micro/go-micro/v2/broker"
+	"google.golang.org/api/option"
+	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/grpc"
+)
+
+func newTestBroker(t *testing.T, opts ...broker.Option) (broker.Broker, func()) {
+	srv := pstest.NewServer()
+
+	opts = append(opts,
+		ProjectID("test"),
+		ClientOptions(
+			option.WithEndpoint(srv.Addr),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithInsecure()),
+		),
+	)
+
+	b := NewBroker(opts...)
+	if err := b.Connect(); err != nil {
+		srv.Close()
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	return b, func() {
+		b.Disconnect()
+		srv.Close()
+	}
+}
+
+func TestOptions(t *testing.T) {
+	b := NewBroker(ProjectID("test"), AckDeadline(time.Second*30), MaxOutstandingMessages(10), DisableAutoCreate()).(*pubsubBroker)
+	if b.project != "test" || b.ackDeadline != 30*time.Second || b.maxMessages != 10 || b.autoCreate {
+		t.Fatalf("Unexpected options %+v", b)
+	}
+	if b.maxBytes != DefaultMaxOutstandingBytes || b.numGoroutines != DefaultNumGoroutines {
+		t.Fatalf("Unexpected default options %+v", b)
+	}
+	if err := NewBroker(ProjectID("")).Connect(); err == nil {
+		t.Fatal("Expected an error without a project")
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b, stop := newTestBroker(t)
+	defer stop()
+
+	// the topic is created by the subscription of the queue, which gets
+	// the message again once nacked
+	var mtx sync.Mutex
+	var calls int
+	done := make(chan *broker.Message, 1)
+	sub, err := b.Subscribe("test", func(e broker.Event) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		calls++
+		if calls == 1 {
+			return errors.New("failed")
+		}
+		done <- e.Message()
+		return nil
+	}, broker.Queue("queue"))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// the subscriber without a queue gets the message too
+	broadcast := make(chan *broker.Message, 1)
+	bsub, err := b.Subscribe("test", func(e broker.Event) error {
+		broadcast <- e.Message()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer bsub.Unsubscribe()
+
+	msg := &broker.Message{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   []byte(`{"message":"hello"}`),
+	}
+	if err := b.Publish("test", msg); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	for _, ch := range []chan *broker.Message{done, broadcast} {
+		select {
+		case m := <-ch:
+			if string(m.Body) != string(msg.Body) || m.Header["Content-Type"] != "application/json" {
+				t.Fatalf("Unexpected message %+v", m)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the message")
+		}
+	}
+
+	// the topic is created by the publish
+	if err := b.Publish("other", msg); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+}
+
+func TestNack(t *testing.T) {
+	b, stop := newTestBroker(t)
+	defer stop()
+
+	// the message requeued first is redelivered, then dropped
+	calls := make(chan int, 10)
+	var mtx sync.Mutex
+	var n int
+	sub, err := b.Subscribe("test", func(e broker.Event) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		n++
+		calls <- n
+		return e.Nack(n == 1)
+	}, broker.Queue("queue"))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if err := b.Publish("test", &broker.Message{Body: []byte("hello")}); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case c := <-calls:
+			if c != i {
+				t.Fatalf("Expected delivery %d, got %d", i, c)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for delivery %d", i)
+		}
+	}
+
+	select {
+	case c := <-calls:
+		t.Fatalf("Unexpected delivery %d of a dropped message", c)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestBatch(t *testing.T) {
+	b, stop := newTestBroker(t)
+	defer stop()
+
+	if _, ok := b.(broker.BatchPublisher); !ok {
+		t.Fatal("Expected the broker to publish batches natively")
+	}
+	bb := broker.Batch(b)
+
+	received := make(chan int, 100)
+	sub, err := bb.SubscribeBatch("test", func(events []broker.Event) error {
+		if len(events) > 500 {
+			return fmt.Errorf("unexpected batch of %d messages", len(events))
+		}
+		received <- len(events)
+		return nil
+	}, broker.Queue("queue"), broker.BatchSize(500), broker.BatchWait(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// more messages than a publish request takes
+	msgs := make([]*broker.Message, 1500)
+	for i := range msgs {
+		msgs[i] = &broker.Message{Body: []byte(fmt.Sprint(i))}
+	}
+	if err := bb.PublishBatch("test", msgs); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	total := 0
+	for total < len(msgs) {
+		select {
+		case n := <-received:
+			total += n
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out with %d messages received", total)
+		}
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	b, stop := newTestBroker(t)
+	defer stop()
+
+	var mtx sync.Mutex
+	var got []string
+	done := make(chan bool)
+	sub, err := b.Subscribe("ordered", func(e broker.Event) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		got = append(got, string(e.Message().Body))
+		if len(got) == 10 {
+			close(done)
+		}
+		return nil
+	}, broker.Queue("queue"), MessageOrdering())
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// the fake server drops the ordering keys, the messages are dispatched
+	// as pulled
+	s := sub.(*subscriber)
+	for i := 0; i < 10; i++ {
+		s.dispatch(&pb.ReceivedMessage{
+			AckId: fmt.Sprint(i),
+			Message: &pb.PubsubMessage{
+				Data:        []byte(fmt.Sprint(i)),
+				OrderingKey: "key",
+			},
+		})
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the messages")
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	for i, m := range got {
+		if m != fmt.Sprint(i) {
+			t.Fatalf("Expected the messages in order, got %v", got)
+		}
+	}
+}
+
+func TestExactlyOnce(t *testing.T) {
+	b, stop := newTestBroker(t)
+	defer stop()
+
+	_, err := b.Subscribe("test", func(broker.Event) error { return nil }, ExactlyOnce())
+	if !errors.Is(err, broker.ErrUnsupportedDelivery) {
+		t.Fatalf("Expected unsupported delivery error, got %v", err)
+	}
+}
+
+func TestExtension(t *testing.T) {
+	pstest.SetMinAckDeadline(time.Second)
+	defer pstest.ResetMinAckDeadline()
+
+	b, stop := newTestBroker(t, AckDeadline(time.Second*2))
+	defer stop()
+
+	// the handler runs past the deadline without the message being redelivered
+	calls := make(chan struct{}, 10)
+	sub, err := b.Subscribe("test", func(e broker.Event) error {
+		calls <- struct{}{}
+		time.Sleep(time.Second * 3)
+		return nil
+	}, broker.Queue("queue"))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if err := b.Publish("test", &broker.Message{Body: []byte("hello")}); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	select {
+	case <-calls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the message")
+	}
+
+	select {
+	case <-calls:
+		t.Fatal("Unexpected redelivery of a message being handled")
+	case <-time.After(4 * time.Second):
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	for n, attempts := range map[int]int32{1: 5, 10: 11, 200: 100} {
+		if a := maxDeliveryAttempts(n); a != attempts {
+			t.Fatalf("Expected %d delivery attempts for %d redeliveries, got %d", attempts, n, a)
+		}
+	}
+
+	b, stop := newTestBroker(t)
+	defer stop()
+
+	sub, err := b.Subscribe("test", func(broker.Event) error { return nil }, broker.Queue("queue"), broker.MaxRedeliveries(2))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	client := b.(*pubsubBroker).sub
+	s, err := client.GetSubscription(context.Background(), &pb.GetSubscriptionRequest{
+		Subscription: "projects/test/subscriptions/test-queue",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error getting the subscription %v", err)
+	}
+	if p := s.DeadLetterPolicy; p == nil || p.MaxDeliveryAttempts != 5 || p.DeadLetterTopic != "projects/test/topics/test-queue-dead-letter" {
+		t.Fatalf("Unexpected dead letter policy %+v", p)
+	}
+
+	// the dead letters are kept by a subscription of their own
+	if _, err := client.GetSubscription(context.Background(), &pb.GetSubscriptionRequest{
+		Subscription: "projects/test/subscriptions/test-queue-dead-letter",
+	}); err != nil {
+		t.Fatalf("Expected the dead letter subscription to be created, got %v", err)
+	}
+}
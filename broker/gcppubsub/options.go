@@ -0,0 +1,93 @@
+package gcppubsub
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	"google.golang.org/api/option"
+)
+
+type projectIDKey struct{}
+type clientOptionsKey struct{}
+type disableAutoCreateKey struct{}
+type ackDeadlineKey struct{}
+type maxExtensionKey struct{}
+type maxOutstandingMessagesKey struct{}
+type maxOutstandingBytesKey struct{}
+type numGoroutinesKey struct{}
+type orderingKeyKey struct{}
+type messageOrderingKey struct{}
+type exactlyOnceKey struct{}
+
+// ProjectID sets the google cloud project of the topics and subscriptions,
+// it defaults to the GOOGLE_CLOUD_PROJECT environment variable
+func ProjectID(id string) broker.Option {
+	return setBrokerOption(projectIDKey{}, id)
+}
+
+// ClientOptions accepts the options of the pubsub clients, e.g. their
+// credentials. They take precedence over the broker options.
+func ClientOptions(opts ...option.ClientOption) broker.Option {
+	return setBrokerOption(clientOptionsKey{}, opts)
+}
+
+// DisableAutoCreate doesn't create the topics and subscriptions which don't
+// exist, e.g. when they're managed by terraform
+func DisableAutoCreate() broker.Option {
+	return setBrokerOption(disableAutoCreateKey{}, true)
+}
+
+// AckDeadline is the time the handlers have to ack a message before it's
+// redelivered, set on the subscriptions created. Defaults to a minute.
+func AckDeadline(d time.Duration) broker.Option {
+	return setBrokerOption(ackDeadlineKey{}, d)
+}
+
+// MaxExtension is the max time the ack deadline of a message is extended
+// for while it's handled, the deadline is extended every half deadline so a
+// slow handler doesn't get the message redelivered. Zero disables the
+// extension. Defaults to an hour.
+func MaxExtension(d time.Duration) broker.Option {
+	return setBrokerOption(maxExtensionKey{}, d)
+}
+
+// MaxOutstandingMessages is the max number of messages of a subscription
+// being handled at once. Defaults to 1000.
+func MaxOutstandingMessages(n int) broker.Option {
+	return setBrokerOption(maxOutstandingMessagesKey{}, n)
+}
+
+// MaxOutstandingBytes is the max size of the messages of a subscription
+// being handled at once. Defaults to 1GB.
+func MaxOutstandingBytes(n int) broker.Option {
+	return setBrokerOption(maxOutstandingBytesKey{}, n)
+}
+
+// NumGoroutines is the number of goroutines pulling the messages of each
+// subscription. Defaults to 1, the subscriptions with message ordering
+// always pull with one.
+func NumGoroutines(n int) broker.Option {
+	return setBrokerOption(numGoroutinesKey{}, n)
+}
+
+// OrderingKey publishes the message with the ordering key, the messages with
+// the same key are delivered in order to the subscriptions with message
+// ordering
+func OrderingKey(key string) broker.PublishOption {
+	return setPublishOption(orderingKeyKey{}, key)
+}
+
+// MessageOrdering creates the subscription with message ordering, so the
+// messages with the same ordering key are handled one after another in the
+// order they were published
+func MessageOrdering() broker.SubscribeOption {
+	return setSubscribeOption(messageOrderingKey{}, true)
+}
+
+// ExactlyOnce requires a subscription with exactly-once delivery. It's not
+// available in the version of the pubsub api the broker is built with, so
+// the subscriptions fail with broker.ErrUnsupportedDelivery rather than
+// silently delivering messages more than once.
+func ExactlyOnce() broker.SubscribeOption {
+	return setSubscribeOption(exactlyOnceKey{}, true)
+}
@@ -157,6 +157,15 @@ func (h *httpEvent) Ack() error {
 	return nil
 }
 
+// Nack can't requeue the message, as it's posted to the subscribers without
+// being redelivered
+func (h *httpEvent) Nack(requeue bool) error {
+	if requeue {
+		return UnsupportedDelivery("http", AtLeastOnce)
+	}
+	return nil
+}
+
 func (h *httpEvent) Error() error {
 	return h.err
 }
@@ -642,6 +651,11 @@ func (h *httpBroker) Subscribe(topic string, handler Handler, opts ...SubscribeO
 	var host, port string
 	options := NewSubscribeOptions(opts...)
 
+	// messages are posted to subscribers without being redelivered
+	if options.Delivery == AtLeastOnce {
+		return nil, UnsupportedDelivery(h.String(), options.Delivery)
+	}
+
 	// parse address for host, port
 	host, port, err = net.SplitHostPort(h.Address())
 	if err != nil {
@@ -1,6 +1,7 @@
 package broker_test
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -234,6 +235,38 @@ func TestBroker(t *testing.T) {
 	}
 }
 
+func TestBrokerDelivery(t *testing.T) {
+	m := newTestRegistry()
+	b := broker.NewBroker(broker.Registry(m))
+
+	if err := b.Init(); err != nil {
+		t.Fatalf("Unexpected init error: %v", err)
+	}
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+
+	fn := func(p broker.Event) error {
+		return nil
+	}
+
+	_, err := b.Subscribe("test", fn, broker.DeliveryMode(broker.AtLeastOnce))
+	if !errors.Is(err, broker.ErrUnsupportedDelivery) {
+		t.Fatalf("Expected unsupported delivery error, got: %v", err)
+	}
+
+	sub, err := b.Subscribe("test", fn, broker.DeliveryMode(broker.AtMostOnce))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+	sub.Unsubscribe()
+
+	if err := b.Disconnect(); err != nil {
+		t.Fatalf("Unexpected disconnect error: %v", err)
+	}
+}
+
 func TestConcurrentSubBroker(t *testing.T) {
 	m := newTestRegistry()
 	b := broker.NewBroker(broker.Registry(m))
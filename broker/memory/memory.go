@@ -12,9 +12,15 @@ import (
 	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/logger"
 	maddr "github.com/micro/go-micro/v2/util/addr"
+	"github.com/micro/go-micro/v2/util/backoff"
 	mnet "github.com/micro/go-micro/v2/util/net"
 )
 
+var (
+	// log of the broker module
+	log = logger.NewModule("broker")
+)
+
 type memoryBroker struct {
 	opts broker.Options
 
@@ -29,12 +35,19 @@ type memoryEvent struct {
 	topic   string
 	err     error
 	message interface{}
+
+	// acked or nacked by the handler, or after it returned
+	sync.Mutex
+	acked   bool
+	nacked  bool
+	requeue bool
 }
 
 type memorySubscriber struct {
 	id      string
 	topic   string
 	exit    chan bool
+	done    chan bool
 	handler broker.Handler
 	opts    broker.SubscribeOptions
 }
@@ -114,14 +127,19 @@ func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker
 		v = msg
 	}
 
-	p := &memoryEvent{
-		topic:   topic,
-		message: v,
-		opts:    m.opts,
-	}
-
 	for _, sub := range subs {
-		if err := sub.handler(p); err != nil {
+		p := &memoryEvent{
+			topic:   topic,
+			message: v,
+			opts:    m.opts,
+		}
+
+		err := sub.handler(p)
+		if sub.requeue(p, err) {
+			go sub.redeliver(topic, v, m.opts)
+		}
+
+		if err != nil {
 			p.err = err
 			if eh := m.opts.ErrorHandler; eh != nil {
 				eh(p)
@@ -149,6 +167,7 @@ func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...b
 
 	sub := &memorySubscriber{
 		exit:    make(chan bool, 1),
+		done:    make(chan bool),
 		id:      uuid.New().String(),
 		topic:   topic,
 		handler: handler,
@@ -171,6 +190,7 @@ func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...b
 		}
 		m.Subscribers[topic] = newSubscribers
 		m.Unlock()
+		close(sub.done)
 	}()
 
 	return sub, nil
@@ -191,8 +211,8 @@ func (m *memoryEvent) Message() *broker.Message {
 	case []byte:
 		msg := &broker.Message{}
 		if err := m.opts.Codec.Unmarshal(v, msg); err != nil {
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Errorf("[memory]: failed to unmarshal: %v\n", err)
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("[memory]: failed to unmarshal: %v\n", err)
 			}
 			return nil
 		}
@@ -203,6 +223,17 @@ func (m *memoryEvent) Message() *broker.Message {
 }
 
 func (m *memoryEvent) Ack() error {
+	m.Lock()
+	defer m.Unlock()
+	m.acked = true
+	return nil
+}
+
+func (m *memoryEvent) Nack(requeue bool) error {
+	m.Lock()
+	defer m.Unlock()
+	m.nacked = true
+	m.requeue = requeue
 	return nil
 }
 
@@ -218,6 +249,59 @@ func (m *memorySubscriber) Topic() string {
 	return m.topic
 }
 
+// requeue returns true if the event is redelivered once handled, if it was
+// nacked to be requeued or failed to be handled at least once
+func (m *memorySubscriber) requeue(p *memoryEvent, err error) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	switch {
+	case p.nacked:
+		return p.requeue
+	case p.acked:
+		return false
+	default:
+		return err != nil && m.opts.Delivery == broker.AtLeastOnce
+	}
+}
+
+// redeliver retries handling the message after the redelivery delay, or with
+// backoff, until it's handled, the max redeliveries are reached or the
+// subscriber unsubscribes
+func (m *memorySubscriber) redeliver(topic string, v interface{}, opts broker.Options) {
+	for i := 1; m.opts.MaxRedeliveries == 0 || i <= m.opts.MaxRedeliveries; i++ {
+		delay := m.opts.RedeliveryDelay
+		if delay == 0 {
+			delay = backoff.Do(i)
+		}
+
+		select {
+		case <-m.done:
+			return
+		case <-time.After(delay):
+		}
+
+		p := &memoryEvent{
+			topic:   topic,
+			message: v,
+			opts:    opts,
+		}
+
+		err := m.handler(p)
+		if !m.requeue(p, err) {
+			return
+		}
+
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("[memory]: redelivery %d to %s failed: %v", i, m.topic, err)
+		}
+	}
+
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("[memory]: message to %s dropped after %d redeliveries", m.topic, m.opts.MaxRedeliveries)
+	}
+}
+
 func (m *memorySubscriber) Unsubscribe() error {
 	m.exit <- true
 	return nil
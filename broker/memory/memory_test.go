@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/micro/go-micro/v2/broker"
 )
@@ -48,3 +50,132 @@ func TestMemoryBroker(t *testing.T) {
 		t.Fatalf("Unexpected connect error %v", err)
 	}
 }
+
+func TestMemoryBrokerAtLeastOnce(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	topic := "test"
+	calls := make(chan int, 10)
+	attempts := 0
+
+	// fail the first delivery so the message is redelivered
+	fn := func(p broker.Event) error {
+		attempts++
+		calls <- attempts
+		if attempts < 2 {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+
+	sub, err := b.Subscribe(topic, fn, broker.DeliveryMode(broker.AtLeastOnce))
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	message := &broker.Message{Body: []byte(`hello world`)}
+	if err := b.Publish(topic, message); err == nil {
+		t.Fatal("Expected the handler error to be returned")
+	}
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case n := <-calls:
+			if n != i {
+				t.Fatalf("Expected delivery %d, got %d", i, n)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("Expected delivery %d of the message", i)
+		}
+	}
+
+	select {
+	case n := <-calls:
+		t.Fatalf("Unexpected delivery %d of a handled message", n)
+	case <-time.After(time.Millisecond * 200):
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unexpected error unsubscribing from %s: %v", topic, err)
+	}
+}
+
+func TestMemoryBrokerNack(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+
+	topic := "test"
+	calls := make(chan int, 10)
+	attempts := 0
+
+	// requeue the message every time until it's dropped
+	fn := func(p broker.Event) error {
+		attempts++
+		calls <- attempts
+		return p.Nack(true)
+	}
+
+	sub, err := b.Subscribe(topic, fn,
+		broker.MaxRedeliveries(2),
+		broker.RedeliveryDelay(time.Millisecond*10),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	message := &broker.Message{Body: []byte(`hello world`)}
+	if err := b.Publish(topic, message); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+
+	// delivered once then redelivered twice
+	for i := 1; i <= 3; i++ {
+		select {
+		case n := <-calls:
+			if n != i {
+				t.Fatalf("Expected delivery %d, got %d", i, n)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected delivery %d of the message", i)
+		}
+	}
+
+	select {
+	case n := <-calls:
+		t.Fatalf("Unexpected delivery %d past the max redeliveries", n)
+	case <-time.After(time.Millisecond * 200):
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unexpected error unsubscribing from %s: %v", topic, err)
+	}
+
+	// a failed message nacked without requeue isn't redelivered
+	drop := func(p broker.Event) error {
+		calls <- 0
+		p.Nack(false)
+		return errors.New("handler failed")
+	}
+
+	sub, err = b.Subscribe("drop", drop, broker.DeliveryMode(broker.AtLeastOnce))
+	if err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	b.Publish("drop", message)
+	<-calls
+
+	select {
+	case <-calls:
+		t.Fatal("Unexpected redelivery of a dropped message")
+	case <-time.After(time.Millisecond * 200):
+	}
+}
@@ -14,6 +14,11 @@ import (
 	nats "github.com/nats-io/nats.go"
 )
 
+var (
+	// log of the broker module
+	log = logger.NewModule("broker")
+)
+
 type natsBroker struct {
 	sync.Once
 	sync.RWMutex
@@ -55,6 +60,14 @@ func (p *publication) Ack() error {
 	return nil
 }
 
+func (p *publication) Nack(requeue bool) error {
+	// core nats has no message redelivery
+	if requeue {
+		return broker.UnsupportedDelivery("nats", broker.AtLeastOnce)
+	}
+	return nil
+}
+
 func (p *publication) Error() error {
 	return p.err
 }
@@ -131,8 +144,8 @@ func (n *natsBroker) Connect() error {
 
 		c, err := opts.Connect()
 		if err != nil {
-			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
-				logger.Warnf("Error connecting to broker: %v", err)
+			if logger.V(logger.WarnLevel, log) {
+				log.Warnf("Error connecting to broker: %v", err)
 			}
 
 			return err
@@ -203,6 +216,11 @@ func (n *natsBroker) Subscribe(topic string, handler broker.Handler, opts ...bro
 		o(&opt)
 	}
 
+	// core nats has no message redelivery
+	if opt.Delivery == broker.AtLeastOnce {
+		return nil, broker.UnsupportedDelivery(n.String(), opt.Delivery)
+	}
+
 	fn := func(msg *nats.Msg) {
 		var m broker.Message
 		pub := &publication{t: msg.Subject}
@@ -212,8 +230,8 @@ func (n *natsBroker) Subscribe(topic string, handler broker.Handler, opts ...bro
 		pub.m = &m
 		if err != nil {
 			m.Body = msg.Data
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Error(err)
+			if logger.V(logger.ErrorLevel, log) {
+				log.Error(err)
 			}
 			if eh != nil {
 				eh(pub)
@@ -222,8 +240,8 @@ func (n *natsBroker) Subscribe(topic string, handler broker.Handler, opts ...bro
 		}
 		if err := handler(pub); err != nil {
 			pub.err = err
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Error(err)
+			if logger.V(logger.ErrorLevel, log) {
+				log.Error(err)
 			}
 			if eh != nil {
 				eh(pub)
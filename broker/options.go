@@ -3,7 +3,9 @@ package broker
 import (
 	"context"
 	"crypto/tls"
+	"time"
 
+	"github.com/micro/go-micro/v2/auth/credentials"
 	"github.com/micro/go-micro/v2/codec"
 	"github.com/micro/go-micro/v2/registry"
 )
@@ -20,12 +22,18 @@ type Options struct {
 	TLSConfig *tls.Config
 	// Registry used for clustering
 	Registry registry.Registry
+	// Credentials used to connect to the backend
+	Credentials credentials.Provider
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
 }
 
 type PublishOptions struct {
+	// IdempotencyKey identifies the message, its duplicates published
+	// with the same key are dropped by the subscribers deduplicating them
+	IdempotencyKey string
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -39,6 +47,22 @@ type SubscribeOptions struct {
 	// will create a shared subscription where each
 	// receives a subset of messages.
 	Queue string
+	// Delivery is the delivery guarantee of the subscription.
+	// Brokers which can't honour it fail to subscribe.
+	Delivery Delivery
+	// MaxRedeliveries is the number of times a message is redelivered
+	// before it's dropped, or dead lettered by the brokers which can.
+	// Zero redelivers it until it's handled.
+	MaxRedeliveries int
+	// RedeliveryDelay is the delay a message is redelivered after, the
+	// default of the broker if zero
+	RedeliveryDelay time.Duration
+	// BatchSize is the max size of the batches delivered to the batch
+	// handlers, DefaultBatchSize if zero
+	BatchSize int
+	// BatchWait is the max time a message waits for its batch to fill
+	// up, DefaultBatchWait if zero
+	BatchWait time.Duration
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -56,6 +80,14 @@ func PublishContext(ctx context.Context) PublishOption {
 	}
 }
 
+// IdempotencyKey sets the key identifying the message, e.g. the id of a
+// payment, so its duplicates are dropped by the subscribers
+func IdempotencyKey(key string) PublishOption {
+	return func(o *PublishOptions) {
+		o.IdempotencyKey = key
+	}
+}
+
 type SubscribeOption func(*SubscribeOptions)
 
 func NewSubscribeOptions(opts ...SubscribeOption) SubscribeOptions {
@@ -77,6 +109,13 @@ func Addrs(addrs ...string) Option {
 	}
 }
 
+// Credentials sets the provider of the credentials used to connect to the backend
+func Credentials(p credentials.Provider) Option {
+	return func(o *Options) {
+		o.Credentials = p
+	}
+}
+
 // Codec sets the codec used for encoding/decoding used where
 // a broker does not support headers
 func Codec(c codec.Marshaler) Option {
@@ -85,6 +124,28 @@ func Codec(c codec.Marshaler) Option {
 	}
 }
 
+// DeliveryMode declares the delivery semantics of the subscription
+func DeliveryMode(d Delivery) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Delivery = d
+	}
+}
+
+// BatchSize sets the max size of the batches delivered to a batch handler
+func BatchSize(n int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.BatchSize = n
+	}
+}
+
+// BatchWait sets the max time a message waits for its batch to fill up
+// before the batch is delivered to a batch handler
+func BatchWait(d time.Duration) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.BatchWait = d
+	}
+}
+
 // DisableAutoAck will disable auto acking of messages
 // after they have been handled.
 func DisableAutoAck() SubscribeOption {
@@ -101,6 +162,14 @@ func ErrorHandler(h Handler) Option {
 	}
 }
 
+// MaxRedeliveries sets the number of times a message is redelivered before
+// it's dropped, or dead lettered
+func MaxRedeliveries(n int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.MaxRedeliveries = n
+	}
+}
+
 // Queue sets the name of the queue to share messages on
 func Queue(name string) SubscribeOption {
 	return func(o *SubscribeOptions) {
@@ -108,6 +177,13 @@ func Queue(name string) SubscribeOption {
 	}
 }
 
+// RedeliveryDelay sets the delay a message is redelivered after
+func RedeliveryDelay(d time.Duration) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.RedeliveryDelay = d
+	}
+}
+
 func Registry(r registry.Registry) Option {
 	return func(o *Options) {
 		o.Registry = r
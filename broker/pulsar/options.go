@@ -0,0 +1,78 @@
+package pulsar
+
+import (
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/micro/go-micro/v2/broker"
+)
+
+type clientOptionsKey struct{}
+type tenantKey struct{}
+type namespaceKey struct{}
+type subscriptionTypeKey struct{}
+type batchingKey struct{}
+type nackBackoffKey struct{}
+type keyKey struct{}
+type deliverAfterKey struct{}
+
+// ClientOptions accepts pulsar.ClientOptions, the broker addresses take
+// precedence over its url
+func ClientOptions(opts pulsar.ClientOptions) broker.Option {
+	return setBrokerOption(clientOptionsKey{}, opts)
+}
+
+// Tenant is the tenant of the topics. Defaults to public.
+func Tenant(name string) broker.Option {
+	return setBrokerOption(tenantKey{}, name)
+}
+
+// Namespace is the namespace of the topics in the tenant. Defaults to
+// default.
+func Namespace(name string) broker.Option {
+	return setBrokerOption(namespaceKey{}, name)
+}
+
+type batching struct {
+	maxMessages uint
+	maxDelay    time.Duration
+}
+
+// Batching sets the max number of messages the producers batch, and the max
+// delay of the messages sent waiting for their batch. Defaults to the ones
+// of the pulsar client, 1000 messages and 10ms.
+func Batching(maxMessages uint, maxDelay time.Duration) broker.Option {
+	return setBrokerOption(batchingKey{}, batching{maxMessages, maxDelay})
+}
+
+// SubscriptionType is the type of the subscription of a queue, e.g.
+// pulsar.Failover so only one subscriber gets the messages at a time or
+// pulsar.KeyShared so the messages with the same key go to the same one.
+// Defaults to pulsar.Shared, the subscribers without a queue are exclusive.
+func SubscriptionType(t pulsar.SubscriptionType) broker.SubscribeOption {
+	return setSubscribeOption(subscriptionTypeKey{}, t)
+}
+
+type nackBackoff struct {
+	min, max time.Duration
+}
+
+// NackBackoff redelivers the messages which fail after a delay doubled each
+// time they fail, from min up to max, rather than after the redelivery
+// delay. The messages are redelivered through the retry topic of the
+// subscription.
+func NackBackoff(min, max time.Duration) broker.SubscribeOption {
+	return setSubscribeOption(nackBackoffKey{}, nackBackoff{min, max})
+}
+
+// Key publishes the message with the key, the messages with the same key go
+// to the same subscriber of a key shared subscription
+func Key(key string) broker.PublishOption {
+	return setPublishOption(keyKey{}, key)
+}
+
+// DeliverAfter delays the delivery of the message to the shared
+// subscriptions
+func DeliverAfter(d time.Duration) broker.PublishOption {
+	return setPublishOption(deliverAfterKey{}, d)
+}
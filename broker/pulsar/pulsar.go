@@ -0,0 +1,558 @@
+// Package pulsar provides a broker on apache pulsar. The topics are in the
+// namespace of a tenant. The queues are shared subscriptions, or failover or
+// key shared ones, while the subscribers without a queue get an exclusive
+// subscription of their own.
+package pulsar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/codec/json"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+var (
+	// log of the broker module
+	log = logger.NewModule("broker")
+
+	// DefaultAddress of pulsar
+	DefaultAddress = "pulsar://127.0.0.1:6650"
+	// DefaultTenant of the topics
+	DefaultTenant = "public"
+	// DefaultNamespace of the topics
+	DefaultNamespace = "default"
+)
+
+type pulsarBroker struct {
+	sync.RWMutex
+
+	opts      broker.Options
+	copts     pulsar.ClientOptions
+	tenant    string
+	namespace string
+
+	client    pulsar.Client
+	producers map[string]pulsar.Producer
+	batching  batching
+
+	subscribers map[*subscriber]bool
+}
+
+type subscriber struct {
+	b        *pulsarBroker
+	topic    string
+	opts     broker.SubscribeOptions
+	handler  broker.Handler
+	consumer pulsar.Consumer
+	backoff  *nackBackoff
+
+	exit chan bool
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+type publication struct {
+	t   string
+	err error
+	m   *broker.Message
+	msg pulsar.Message
+	s   *subscriber
+
+	// acked or nacked
+	done bool
+}
+
+func (p *publication) Topic() string {
+	return p.t
+}
+
+func (p *publication) Message() *broker.Message {
+	return p.m
+}
+
+func (p *publication) Ack() error {
+	if !p.done {
+		p.s.consumer.Ack(p.msg)
+		p.done = true
+	}
+	return nil
+}
+
+// Nack redelivers the message after the redelivery delay, or the backoff, if
+// requeued, it's acked otherwise so it's dropped. The messages delivered at
+// most once were acked already so they can't be requeued.
+func (p *publication) Nack(requeue bool) error {
+	if p.s.opts.Delivery == broker.AtMostOnce {
+		if requeue {
+			return broker.UnsupportedDelivery(p.s.b.String(), broker.AtLeastOnce)
+		}
+		return nil
+	}
+	if !requeue {
+		return p.Ack()
+	}
+	if !p.done {
+		p.s.nack(p.msg)
+		p.done = true
+	}
+	return nil
+}
+
+func (p *publication) Error() error {
+	return p.err
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+func (s *subscriber) Unsubscribe() error {
+	var err error
+	s.once.Do(func() {
+		close(s.exit)
+		s.wg.Wait()
+
+		s.b.Lock()
+		delete(s.b.subscribers, s)
+		s.b.Unlock()
+
+		// the subscriptions of the queues are kept for their messages
+		if len(s.opts.Queue) == 0 {
+			err = s.consumer.Unsubscribe()
+		}
+		s.consumer.Close()
+	})
+	return err
+}
+
+// run handles the messages of the subscription until unsubscribed
+func (s *subscriber) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.exit:
+			return
+		case cm, ok := <-s.consumer.Chan():
+			if !ok {
+				return
+			}
+			s.handle(cm.Message)
+		}
+	}
+}
+
+// handle calls the handler with the message, acking it if handled. The
+// messages which fail are nacked so they're redelivered after a delay.
+func (s *subscriber) handle(msg pulsar.Message) {
+	header := make(map[string]string, len(msg.Properties()))
+	for k, v := range msg.Properties() {
+		header[k] = v
+	}
+
+	pub := &publication{
+		t:   s.topic,
+		m:   &broker.Message{Header: header, Body: msg.Payload()},
+		msg: msg,
+		s:   s,
+	}
+	eh := s.b.opts.ErrorHandler
+
+	// the message is acked before it's handled so it's never redelivered
+	if s.opts.Delivery == broker.AtMostOnce {
+		pub.Ack()
+	}
+
+	if err := s.handler(pub); err != nil {
+		pub.err = err
+		if logger.V(logger.ErrorLevel, log) {
+			log.Error(err)
+		}
+		if eh != nil {
+			eh(pub)
+		}
+		if !pub.done {
+			pub.Nack(true)
+		}
+		return
+	}
+
+	if s.opts.AutoAck {
+		pub.Ack()
+	}
+}
+
+// nack redelivers the message after the redelivery delay, or the backoff of the
+// times it was redelivered so far
+func (s *subscriber) nack(msg pulsar.Message) {
+	if s.backoff == nil {
+		s.consumer.Nack(msg)
+		return
+	}
+
+	times, _ := strconv.Atoi(msg.Properties()[pulsar.SysPropertyReconsumeTimes])
+	delay := s.backoff.min
+	for i := 0; i < times && delay < s.backoff.max; i++ {
+		delay *= 2
+	}
+	if delay > s.backoff.max {
+		delay = s.backoff.max
+	}
+	s.consumer.ReconsumeLater(msg, delay)
+}
+
+func (p *pulsarBroker) Address() string {
+	p.RLock()
+	defer p.RUnlock()
+	return p.copts.URL
+}
+
+func (p *pulsarBroker) Connect() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.client != nil {
+		return nil
+	}
+
+	client, err := pulsar.NewClient(p.copts)
+	if err != nil {
+		if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Error connecting to broker: %v", err)
+		}
+		return err
+	}
+
+	// the client connects lazily, the lookup of a topic checks pulsar is
+	// reachable
+	if _, err := client.TopicPartitions(p.topicName("micro")); err != nil {
+		client.Close()
+		if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Error connecting to broker: %v", err)
+		}
+		return err
+	}
+
+	p.client = client
+	return nil
+}
+
+func (p *pulsarBroker) Disconnect() error {
+	p.RLock()
+	subs := make([]*subscriber, 0, len(p.subscribers))
+	for s := range p.subscribers {
+		subs = append(subs, s)
+	}
+	p.RUnlock()
+
+	for _, s := range subs {
+		s.Unsubscribe()
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.client == nil {
+		return nil
+	}
+
+	for topic, producer := range p.producers {
+		producer.Close()
+		delete(p.producers, topic)
+	}
+
+	p.client.Close()
+	p.client = nil
+	return nil
+}
+
+func (p *pulsarBroker) Init(opts ...broker.Option) error {
+	p.Lock()
+	defer p.Unlock()
+	p.setOption(opts...)
+	return nil
+}
+
+func (p *pulsarBroker) Options() broker.Options {
+	return p.opts
+}
+
+// producer returns the producer of the topic, created on first use
+func (p *pulsarBroker) producer(topic string) (pulsar.Producer, error) {
+	p.RLock()
+	producer, ok := p.producers[topic]
+	client := p.client
+	p.RUnlock()
+
+	if ok {
+		return producer, nil
+	}
+	if client == nil {
+		return nil, errors.New("not connected")
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	// created while waiting for the lock
+	if producer, ok := p.producers[topic]; ok {
+		return producer, nil
+	}
+
+	producer, err := client.CreateProducer(pulsar.ProducerOptions{
+		Topic:                   p.topicName(topic),
+		BatchingMaxMessages:     p.batching.maxMessages,
+		BatchingMaxPublishDelay: p.batching.maxDelay,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.producers[topic] = producer
+	return producer, nil
+}
+
+func (p *pulsarBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	options := broker.PublishOptions{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	producer, err := p.producer(topic)
+	if err != nil {
+		return err
+	}
+
+	_, err = producer.Send(options.Context, producerMessage(msg, options))
+	return err
+}
+
+// PublishBatch sends the messages asynchronously so the producer batches
+// them, then flushes the batch and waits for the messages to be persisted
+func (p *pulsarBroker) PublishBatch(topic string, msgs []*broker.Message, opts ...broker.PublishOption) error {
+	options := broker.PublishOptions{
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	producer, err := p.producer(topic)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var sendErr error
+
+	wg.Add(len(msgs))
+	for _, msg := range msgs {
+		producer.SendAsync(options.Context, producerMessage(msg, options), func(_ pulsar.MessageID, _ *pulsar.ProducerMessage, err error) {
+			if err != nil {
+				once.Do(func() { sendErr = err })
+			}
+			wg.Done()
+		})
+	}
+
+	if err := producer.Flush(); err != nil {
+		return err
+	}
+	wg.Wait()
+	return sendErr
+}
+
+// producerMessage returns the pulsar message of msg
+func producerMessage(msg *broker.Message, options broker.PublishOptions) *pulsar.ProducerMessage {
+	m := &pulsar.ProducerMessage{
+		Payload:    msg.Body,
+		Properties: msg.Header,
+	}
+	if key, ok := options.Context.Value(keyKey{}).(string); ok {
+		m.Key = key
+	}
+	if d, ok := options.Context.Value(deliverAfterKey{}).(time.Duration); ok {
+		m.DeliverAfter = d
+	}
+	return m
+}
+
+func (p *pulsarBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	p.RLock()
+	client := p.client
+	p.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("not connected")
+	}
+
+	opt := broker.SubscribeOptions{
+		AutoAck: true,
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	copts := pulsar.ConsumerOptions{
+		Topic:            p.topicName(topic),
+		SubscriptionName: opt.Queue,
+		Type:             pulsar.Shared,
+	}
+
+	// the subscribers without a queue get a subscription of their own
+	if len(opt.Queue) == 0 {
+		copts.SubscriptionName = "micro-" + uuid.New().String()
+		copts.Type = pulsar.Exclusive
+	} else if t, ok := opt.Context.Value(subscriptionTypeKey{}).(pulsar.SubscriptionType); ok {
+		copts.Type = t
+	}
+
+	copts.NackRedeliveryDelay = opt.RedeliveryDelay
+
+	s := &subscriber{
+		b:       p,
+		topic:   topic,
+		opts:    opt,
+		handler: handler,
+		exit:    make(chan bool),
+	}
+
+	// the messages backed off are sent to the retry topic, the client
+	// names it and the dead letter topic after the subscription
+	if b, ok := opt.Context.Value(nackBackoffKey{}).(nackBackoff); ok {
+		s.backoff = &b
+		copts.RetryEnable = true
+	}
+
+	// the messages are sent to the dead letter topic of the subscription
+	// once redelivered the max redeliveries
+	if n := opt.MaxRedeliveries; n > 0 {
+		copts.DLQ = &pulsar.DLQPolicy{MaxDeliveries: uint32(n)}
+		if !copts.RetryEnable {
+			copts.DLQ.DeadLetterTopic = p.topicName(copts.SubscriptionName + pulsar.DlqTopicSuffix)
+		}
+	}
+
+	consumer, err := client.Subscribe(copts)
+	if err != nil {
+		return nil, err
+	}
+	s.consumer = consumer
+
+	p.Lock()
+	p.subscribers[s] = true
+	p.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+func (p *pulsarBroker) String() string {
+	return "pulsar"
+}
+
+// topicName returns the name of the topic in the namespace of the tenant,
+// the topics named in full are kept as is
+func (p *pulsarBroker) topicName(topic string) string {
+	if strings.Contains(topic, "://") {
+		return topic
+	}
+	return fmt.Sprintf("persistent://%s/%s/%s", p.tenant, p.namespace, topic)
+}
+
+func (p *pulsarBroker) setOption(opts ...broker.Option) {
+	for _, o := range opts {
+		o(&p.opts)
+	}
+
+	if copts, ok := p.opts.Context.Value(clientOptionsKey{}).(pulsar.ClientOptions); ok {
+		p.copts = copts
+	}
+
+	// broker.Options have higher priority than pulsar.ClientOptions, only
+	// the first address is used
+	if len(p.opts.Addrs) > 0 && len(p.opts.Addrs[0]) > 0 {
+		p.copts.URL = p.opts.Addrs[0]
+		if !strings.Contains(p.copts.URL, "://") {
+			p.copts.URL = "pulsar://" + p.copts.URL
+		}
+	}
+	if len(p.copts.URL) == 0 {
+		p.copts.URL = DefaultAddress
+	}
+
+	if p.opts.Secure && strings.HasPrefix(p.copts.URL, "pulsar://") {
+		p.copts.URL = "pulsar+ssl://" + strings.TrimPrefix(p.copts.URL, "pulsar://")
+		// best effort, as the certificate isn't verified
+		if len(p.copts.TLSTrustCertsFilePath) == 0 {
+			p.copts.TLSAllowInsecureConnection = true
+		}
+	}
+
+	// the tokens of the provider are fetched as they're needed, so they
+	// can be renewed
+	if creds := p.opts.Credentials; creds != nil {
+		p.copts.Authentication = pulsar.NewAuthenticationTokenFromSupplier(func() (string, error) {
+			c, err := creds.Credentials(context.Background())
+			if err != nil {
+				return "", err
+			}
+			return c.Token, nil
+		})
+	}
+
+	if name, ok := p.opts.Context.Value(tenantKey{}).(string); ok && len(name) > 0 {
+		p.tenant = name
+	}
+	if name, ok := p.opts.Context.Value(namespaceKey{}).(string); ok && len(name) > 0 {
+		p.namespace = name
+	}
+	if b, ok := p.opts.Context.Value(batchingKey{}).(batching); ok {
+		p.batching = b
+	}
+}
+
+// NewBroker returns a broker on apache pulsar
+func NewBroker(opts ...broker.Option) broker.Broker {
+	options := broker.Options{
+		// Default codec, the messages are published with their header
+		// as properties so it's not used
+		Codec:    json.Marshaler{},
+		Context:  context.Background(),
+		Registry: registry.DefaultRegistry,
+	}
+
+	p := &pulsarBroker{
+		opts:        options,
+		tenant:      DefaultTenant,
+		namespace:   DefaultNamespace,
+		producers:   make(map[string]pulsar.Producer),
+		subscribers: make(map[*subscriber]bool),
+	}
+	p.setOption(opts...)
+
+	return p
+}
@@ -0,0 +1,105 @@
+package pulsar
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/micro/go-micro/v2/broker"
+)
+
+func TestInitAddrs(t *testing.T) {
+	testData := []struct {
+		addrs []string
+		addr  string
+	}{
+		{nil, DefaultAddress},
+		{[]string{"10.0.0.1:6651"}, "pulsar://10.0.0.1:6651"},
+		{[]string{"pulsar+ssl://10.0.0.1:6651"}, "pulsar+ssl://10.0.0.1:6651"},
+	}
+
+	for _, d := range testData {
+		b := NewBroker()
+		b.Init(broker.Addrs(d.addrs...))
+
+		if b.Address() != d.addr {
+			t.Fatalf("Expected %s for %v, got %s", d.addr, d.addrs, b.Address())
+		}
+	}
+}
+
+func TestTopicName(t *testing.T) {
+	b := NewBroker(Tenant("acme"), Namespace("billing")).(*pulsarBroker)
+
+	if name := b.topicName("go.micro.invoices"); name != "persistent://acme/billing/go.micro.invoices" {
+		t.Fatalf("Unexpected topic name %s", name)
+	}
+	if name := b.topicName("non-persistent://acme/billing/events"); name != "non-persistent://acme/billing/events" {
+		t.Fatalf("Unexpected topic name %s", name)
+	}
+}
+
+func TestBroker(t *testing.T) {
+	// fails fast without pulsar
+	b := NewBroker(ClientOptions(pulsar.ClientOptions{
+		ConnectionTimeout: time.Second,
+		OperationTimeout:  time.Second,
+	}))
+	if err := b.Connect(); err != nil {
+		t.Skip("broker/pulsar: can't connect to pulsar")
+	}
+	defer b.Disconnect()
+
+	topic := "go.micro.test.pulsar." + time.Now().Format("150405.000000")
+
+	// the queue subscriber failing first gets the message again
+	var mtx sync.Mutex
+	var calls int
+	done := make(chan *broker.Message, 1)
+	sub, err := b.Subscribe(topic, func(e broker.Event) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		calls++
+		if calls == 1 {
+			return errors.New("failed")
+		}
+		done <- e.Message()
+		return nil
+	}, broker.Queue("test"), NackBackoff(100*time.Millisecond, time.Second))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// the subscriber without a queue gets the message too
+	broadcast := make(chan *broker.Message, 1)
+	bsub, err := b.Subscribe(topic, func(e broker.Event) error {
+		broadcast <- e.Message()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error %v", err)
+	}
+	defer bsub.Unsubscribe()
+
+	msg := &broker.Message{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   []byte(`{"message":"hello"}`),
+	}
+	if err := b.Publish(topic, msg, Key("key")); err != nil {
+		t.Fatalf("Unexpected publish error %v", err)
+	}
+
+	for _, ch := range []chan *broker.Message{done, broadcast} {
+		select {
+		case m := <-ch:
+			if string(m.Body) != string(msg.Body) || m.Header["Content-Type"] != "application/json" {
+				t.Fatalf("Unexpected message %+v", m)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timed out waiting for the message")
+		}
+	}
+}
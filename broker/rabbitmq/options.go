@@ -0,0 +1,109 @@
+package rabbitmq
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/streadway/amqp"
+)
+
+type exchangeNameKey struct{}
+type exchangeKindKey struct{}
+type durableExchangeKey struct{}
+type prefetchCountKey struct{}
+type publisherConfirmsKey struct{}
+type confirmTimeoutKey struct{}
+type durableQueueKey struct{}
+type quorumQueueKey struct{}
+type queueArgumentsKey struct{}
+type requeueOnErrorKey struct{}
+type deduplicationKey struct{}
+type persistentKey struct{}
+
+// ExchangeName is the exchange the messages are published to, with their
+// topic as routing key. Defaults to micro.
+func ExchangeName(name string) broker.Option {
+	return setBrokerOption(exchangeNameKey{}, name)
+}
+
+// ExchangeKind is the kind of the exchange declared, e.g. topic, direct or
+// fanout. Defaults to topic.
+func ExchangeKind(kind string) broker.Option {
+	return setBrokerOption(exchangeKindKey{}, kind)
+}
+
+// DurableExchange declares a durable exchange, which survives the restarts
+// of the server
+func DurableExchange() broker.Option {
+	return setBrokerOption(durableExchangeKey{}, true)
+}
+
+// PrefetchCount is the number of messages delivered to a consumer before
+// they're acked. Defaults to 10, raised to the batch size for the batch
+// subscribers.
+func PrefetchCount(n int) broker.Option {
+	return setBrokerOption(prefetchCountKey{}, n)
+}
+
+// PublisherConfirms waits for the server to confirm each message published,
+// so Publish fails if the message wasn't taken in charge
+func PublisherConfirms() broker.Option {
+	return setBrokerOption(publisherConfirmsKey{}, true)
+}
+
+// ConfirmTimeout is the time Publish waits for the confirm of a message,
+// unless the publish context has a deadline. Defaults to 5 seconds.
+func ConfirmTimeout(d time.Duration) broker.Option {
+	return setBrokerOption(confirmTimeoutKey{}, d)
+}
+
+// DurableQueue declares a durable queue, which survives the restarts of the
+// server
+func DurableQueue() broker.SubscribeOption {
+	return setSubscribeOption(durableQueueKey{}, true)
+}
+
+// QuorumQueue declares a replicated quorum queue, which is durable. Only
+// the subscriptions to a named queue can be quorum queues.
+func QuorumQueue() broker.SubscribeOption {
+	return setSubscribeOption(quorumQueueKey{}, true)
+}
+
+// QueueArguments are the arguments the queue is declared with, e.g. its
+// dead letter exchange or max length
+func QueueArguments(args amqp.Table) broker.SubscribeOption {
+	return setSubscribeOption(queueArgumentsKey{}, args)
+}
+
+type deduplication struct {
+	size int
+	ttl  time.Duration
+}
+
+// Deduplication declares a queue deduplicating the messages published with
+// an idempotency key, the keys of the last size messages are cached for the
+// ttl, or until evicted if zero. It needs the message deduplication plugin of
+// rabbitmq.
+func Deduplication(size int, ttl time.Duration) broker.SubscribeOption {
+	return setSubscribeOption(deduplicationKey{}, deduplication{size, ttl})
+}
+
+// RequeueOnError requeues the messages the handler fails with, rather than
+// dropping them or dead lettering them. The messages of an at least once
+// subscription are always requeued.
+func RequeueOnError() broker.SubscribeOption {
+	return setSubscribeOption(requeueOnErrorKey{}, true)
+}
+
+// Persistent publishes the message to disk, so it survives the restarts of
+// the server when in a durable queue. It's the default of the brokers with a
+// durable exchange.
+func Persistent() broker.PublishOption {
+	return setPublishOption(persistentKey{}, true)
+}
+
+// Transient publishes the message in memory only, even to a durable exchange,
+// so it's lost on restart
+func Transient() broker.PublishOption {
+	return setPublishOption(persistentKey{}, false)
+}
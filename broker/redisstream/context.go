@@ -0,0 +1,17 @@
+package redisstream
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/broker"
+)
+
+// setBrokerOption returns a function to setup a context with given value
+func setBrokerOption(k, v interface{}) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
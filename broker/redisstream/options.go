@@ -0,0 +1,51 @@
+package redisstream
+
+import (
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/micro/go-micro/v2/broker"
+)
+
+type optionsKey struct{}
+type maxLenKey struct{}
+type exactTrimKey struct{}
+type minIdleKey struct{}
+type claimIntervalKey struct{}
+type batchSizeKey struct{}
+
+// Options accepts redis.Options, the broker addresses and tls config take
+// precedence
+func Options(opts redis.Options) broker.Option {
+	return setBrokerOption(optionsKey{}, opts)
+}
+
+// MaxLen trims the streams to about n messages as they're published, the
+// streams aren't trimmed by default
+func MaxLen(n int64) broker.Option {
+	return setBrokerOption(maxLenKey{}, n)
+}
+
+// ExactTrim trims the streams to exactly the max len, rather than about it
+// which is cheaper for redis
+func ExactTrim() broker.Option {
+	return setBrokerOption(exactTrimKey{}, true)
+}
+
+// MinIdle is the time a message is pending, unacked by a consumer of a
+// queue, before it's claimed by another, e.g. once the consumer crashed.
+// Defaults to a minute.
+func MinIdle(d time.Duration) broker.Option {
+	return setBrokerOption(minIdleKey{}, d)
+}
+
+// ClaimInterval is the interval the consumers of the queues claim the pending
+// messages idle for the min idle time. Defaults to 30 seconds.
+func ClaimInterval(d time.Duration) broker.Option {
+	return setBrokerOption(claimIntervalKey{}, d)
+}
+
+// BatchSize is the max number of messages read at once. Defaults to 10.
+func BatchSize(n int64) broker.Option {
+	return setBrokerOption(batchSizeKey{}, n)
+}
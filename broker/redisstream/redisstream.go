@@ -0,0 +1,786 @@
+// Package redisstream provides a broker on redis streams. The queues are
+// consumer groups, so the messages published while their consumers are down
+// are delivered once they're back, and the messages of a crashed consumer are
+// claimed by the others.
+package redisstream
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/codec/json"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/util/backoff"
+)
+
+var (
+	// log of the broker module
+	log = logger.NewModule("broker")
+
+	// DefaultAddress of redis
+	DefaultAddress = "127.0.0.1:6379"
+	// DefaultMinIdle of the pending messages before they're claimed
+	DefaultMinIdle = time.Minute
+	// DefaultClaimInterval of the consumers of the queues
+	DefaultClaimInterval = 30 * time.Second
+	// DefaultBatchSize of the messages read at once
+	DefaultBatchSize int64 = 10
+
+	// the time a read blocks waiting for messages, it's the time an
+	// unsubscribe may wait for
+	readBlock = time.Second
+)
+
+// messageField is the field of the stream entries holding the message
+const messageField = "message"
+
+type redisBroker struct {
+	sync.RWMutex
+
+	opts   broker.Options
+	ropts  redis.Options
+	client *redis.Client
+
+	// trimming of the streams
+	maxLen    int64
+	exactTrim bool
+
+	// claiming of the pending messages
+	minIdle       time.Duration
+	claimInterval time.Duration
+	batchSize     int64
+
+	subscribers map[*subscriber]bool
+}
+
+type subscriber struct {
+	b        *redisBroker
+	client   *redis.Client
+	topic    string
+	consumer string
+	// last is the id of the last message read without a queue
+	last    string
+	opts    broker.SubscribeOptions
+	handler broker.Handler
+	// batch handles the messages of a read together, nil if not batched
+	batch broker.BatchHandler
+
+	exit chan bool
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+type publication struct {
+	t   string
+	id  string
+	err error
+	m   *broker.Message
+	s   *subscriber
+	// acked or nacked
+	done bool
+}
+
+func (p *publication) Topic() string {
+	return p.t
+}
+
+func (p *publication) Message() *broker.Message {
+	return p.m
+}
+
+// Ack acknowledges the message to the consumer group of the queue, the
+// messages of the subscribers without a queue aren't acked
+func (p *publication) Ack() error {
+	if !p.s.acks() || p.done {
+		return nil
+	}
+	if err := p.s.client.XAck(p.t, p.s.opts.Queue, p.id).Err(); err != nil {
+		return err
+	}
+	p.done = true
+	return nil
+}
+
+// Nack leaves the message pending if requeued, so it's claimed again once
+// idle for the redelivery delay, and acks it otherwise so it's dropped. The
+// messages of the subscribers without a queue can't be requeued.
+func (p *publication) Nack(requeue bool) error {
+	if !p.s.acks() {
+		if requeue {
+			return broker.UnsupportedDelivery(p.s.b.String(), broker.AtLeastOnce)
+		}
+		return nil
+	}
+	if requeue {
+		p.done = true
+		return nil
+	}
+	return p.Ack()
+}
+
+func (p *publication) Error() error {
+	return p.err
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+func (s *subscriber) Unsubscribe() error {
+	s.once.Do(func() {
+		close(s.exit)
+		s.wg.Wait()
+
+		s.b.Lock()
+		delete(s.b.subscribers, s)
+		s.b.Unlock()
+
+		// deleting a consumer drops its pending messages, so it's only
+		// deleted once it has none left, otherwise the others claim them
+		if len(s.opts.Queue) > 0 {
+			pending, err := s.client.XPendingExt(&redis.XPendingExtArgs{
+				Stream:   s.topic,
+				Group:    s.opts.Queue,
+				Start:    "-",
+				End:      "+",
+				Count:    1,
+				Consumer: s.consumer,
+			}).Result()
+			if err == nil && len(pending) == 0 {
+				s.client.XGroupDelConsumer(s.topic, s.opts.Queue, s.consumer)
+			}
+		}
+	})
+	return nil
+}
+
+// acks returns true if the messages of the subscriber are acked
+func (s *subscriber) acks() bool {
+	return len(s.opts.Queue) > 0 && s.opts.Delivery != broker.AtMostOnce
+}
+
+// count returns the max number of messages read at once, the batch size of
+// the batch subscribers
+func (s *subscriber) count() int64 {
+	if s.batch == nil {
+		return s.b.batchSize
+	}
+	if n := s.opts.BatchSize; n > 0 {
+		return int64(n)
+	}
+	return int64(broker.DefaultBatchSize)
+}
+
+// run reads the messages of the stream until unsubscribed
+func (s *subscriber) run() {
+	defer s.wg.Done()
+
+	last := s.last
+	failures := 0
+
+	for {
+		select {
+		case <-s.exit:
+			return
+		default:
+		}
+
+		var streams []redis.XStream
+		var err error
+
+		if len(s.opts.Queue) > 0 {
+			streams, err = s.client.XReadGroup(&redis.XReadGroupArgs{
+				Group:    s.opts.Queue,
+				Consumer: s.consumer,
+				Streams:  []string{s.topic, ">"},
+				Count:    s.count(),
+				Block:    readBlock,
+				NoAck:    !s.acks(),
+			}).Result()
+		} else {
+			streams, err = s.client.XRead(&redis.XReadArgs{
+				Streams: []string{s.topic, last},
+				Count:   s.count(),
+				Block:   readBlock,
+			}).Result()
+		}
+
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if err == redis.ErrClosed {
+				return
+			}
+			failures++
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Error reading stream %s: %v", s.topic, err)
+			}
+			select {
+			case <-s.exit:
+				return
+			case <-time.After(backoff.Do(failures)):
+			}
+			continue
+		}
+		failures = 0
+
+		for _, stream := range streams {
+			if len(stream.Messages) == 0 {
+				continue
+			}
+			s.deliver(stream.Messages)
+			last = stream.Messages[len(stream.Messages)-1].ID
+		}
+	}
+}
+
+// minIdle returns the time the messages are pending before they're claimed,
+// the redelivery delay of the subscriber if set
+func (s *subscriber) minIdle() time.Duration {
+	if d := s.opts.RedeliveryDelay; d > 0 {
+		return d
+	}
+	return s.b.minIdle
+}
+
+// claim claims the messages of the queue pending for the min idle time, e.g.
+// of a crashed consumer or which failed to be handled, until unsubscribed.
+// The messages redelivered the max redeliveries are acked so they're dropped.
+func (s *subscriber) claim() {
+	defer s.wg.Done()
+
+	t := time.NewTicker(s.b.claimInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.exit:
+			return
+		case <-t.C:
+		}
+
+		// page through the pending messages, a batch at a time
+		start := "-"
+		for {
+			next, ok := s.claimBatch(start)
+			if !ok {
+				break
+			}
+			select {
+			case <-s.exit:
+				return
+			default:
+			}
+			start = next
+		}
+	}
+}
+
+// claimBatch claims the batch of pending messages from the start id,
+// returning the id the next batch starts from and false once the last
+// batch was claimed
+func (s *subscriber) claimBatch(start string) (string, bool) {
+	pending, err := s.client.XPendingExt(&redis.XPendingExtArgs{
+		Stream: s.topic,
+		Group:  s.opts.Queue,
+		Start:  start,
+		End:    "+",
+		Count:  s.b.batchSize,
+	}).Result()
+	if err != nil {
+		if logger.V(logger.ErrorLevel, log) {
+			log.Errorf("Error listing the pending messages of stream %s: %v", s.topic, err)
+		}
+		return "", false
+	}
+	if len(pending) == 0 {
+		return "", false
+	}
+
+	minIdle := s.minIdle()
+	max := int64(s.opts.MaxRedeliveries)
+
+	var ids, dropped []string
+	for _, p := range pending {
+		switch {
+		case p.Idle < minIdle:
+		case max > 0 && p.RetryCount > max:
+			dropped = append(dropped, p.ID)
+		default:
+			ids = append(ids, p.ID)
+		}
+	}
+
+	if len(dropped) > 0 {
+		if err := s.client.XAck(s.topic, s.opts.Queue, dropped...).Err(); err != nil {
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Error dropping the messages of stream %s: %v", s.topic, err)
+			}
+		} else if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Dropped messages %v of stream %s after %d redeliveries", dropped, s.topic, max)
+		}
+	}
+
+	if len(ids) > 0 {
+		// the messages claimed by another consumer first are skipped
+		msgs, err := s.client.XClaim(&redis.XClaimArgs{
+			Stream:   s.topic,
+			Group:    s.opts.Queue,
+			Consumer: s.consumer,
+			MinIdle:  minIdle,
+			Messages: ids,
+		}).Result()
+		if err != nil {
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Error claiming the pending messages of stream %s: %v", s.topic, err)
+			}
+			return "", false
+		}
+
+		if len(msgs) > 0 {
+			s.deliver(msgs)
+		}
+	}
+
+	if int64(len(pending)) < s.b.batchSize {
+		return "", false
+	}
+	return nextID(pending[len(pending)-1].ID), true
+}
+
+// nextID returns the id following the id of a stream entry, the ranges of
+// the older versions of redis can't exclude their start
+func nextID(id string) string {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return id
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return id
+	}
+	return parts[0] + "-" + strconv.FormatUint(seq+1, 10)
+}
+
+// deliver handles the messages read, together if the subscriber is batched
+func (s *subscriber) deliver(msgs []redis.XMessage) {
+	if s.batch == nil {
+		for _, msg := range msgs {
+			s.handle(msg)
+		}
+		return
+	}
+	s.handleBatch(msgs)
+}
+
+// decode decodes the message, the messages which can't be decoded are acked
+// as they never will be
+func (s *subscriber) decode(msg redis.XMessage) (*publication, bool) {
+	var m broker.Message
+	pub := &publication{t: s.topic, id: msg.ID, m: &m, s: s}
+
+	data, _ := msg.Values[messageField].(string)
+	if err := s.b.opts.Codec.Unmarshal([]byte(data), &m); err != nil {
+		pub.err = err
+		m.Body = []byte(data)
+		if logger.V(logger.ErrorLevel, log) {
+			log.Error(err)
+		}
+		if eh := s.b.opts.ErrorHandler; eh != nil {
+			eh(pub)
+		}
+		pub.Ack()
+		return nil, false
+	}
+	return pub, true
+}
+
+// handleBatch calls the batch handler with the messages, acking them all at
+// once if handled. The messages of a batch which fails are left pending, so
+// they're claimed again.
+func (s *subscriber) handleBatch(msgs []redis.XMessage) {
+	events := make([]broker.Event, 0, len(msgs))
+	pubs := make([]*publication, 0, len(msgs))
+	for _, msg := range msgs {
+		if pub, ok := s.decode(msg); ok {
+			events = append(events, pub)
+			pubs = append(pubs, pub)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	if err := s.batch(events); err != nil {
+		if logger.V(logger.ErrorLevel, log) {
+			log.Error(err)
+		}
+		if eh := s.b.opts.ErrorHandler; eh != nil {
+			for _, pub := range pubs {
+				pub.err = err
+				eh(pub)
+			}
+		}
+		return
+	}
+
+	if !s.opts.AutoAck || !s.acks() {
+		return
+	}
+
+	ids := make([]string, 0, len(pubs))
+	for _, pub := range pubs {
+		if !pub.done {
+			ids = append(ids, pub.id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	if err := s.client.XAck(s.topic, s.opts.Queue, ids...).Err(); err != nil {
+		if logger.V(logger.ErrorLevel, log) {
+			log.Errorf("Error acking messages of stream %s: %v", s.topic, err)
+		}
+		return
+	}
+	for _, pub := range pubs {
+		pub.done = true
+	}
+}
+
+// handle decodes the message and calls the handler, acking it if handled
+func (s *subscriber) handle(msg redis.XMessage) {
+	pub, ok := s.decode(msg)
+	if !ok {
+		return
+	}
+	eh := s.b.opts.ErrorHandler
+
+	if err := s.handler(pub); err != nil {
+		pub.err = err
+		if logger.V(logger.ErrorLevel, log) {
+			log.Error(err)
+		}
+		if eh != nil {
+			eh(pub)
+		}
+		return
+	}
+
+	if s.opts.AutoAck {
+		if err := pub.Ack(); err != nil && logger.V(logger.ErrorLevel, log) {
+			log.Errorf("Error acking message %s of stream %s: %v", pub.id, s.topic, err)
+		}
+	}
+}
+
+func (r *redisBroker) Address() string {
+	r.RLock()
+	defer r.RUnlock()
+	return r.ropts.Addr
+}
+
+func (r *redisBroker) Connect() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.client != nil {
+		return nil
+	}
+
+	client := redis.NewClient(&r.ropts)
+	if err := client.Ping().Err(); err != nil {
+		client.Close()
+		if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Error connecting to broker: %v", err)
+		}
+		return err
+	}
+
+	r.client = client
+	return nil
+}
+
+func (r *redisBroker) Disconnect() error {
+	r.RLock()
+	subs := make([]*subscriber, 0, len(r.subscribers))
+	for s := range r.subscribers {
+		subs = append(subs, s)
+	}
+	r.RUnlock()
+
+	// stop the subscribers before closing the client they read with
+	for _, s := range subs {
+		s.Unsubscribe()
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.client == nil {
+		return nil
+	}
+	err := r.client.Close()
+	r.client = nil
+	return err
+}
+
+func (r *redisBroker) Init(opts ...broker.Option) error {
+	r.Lock()
+	defer r.Unlock()
+	r.setOption(opts...)
+	return nil
+}
+
+func (r *redisBroker) Options() broker.Options {
+	return r.opts
+}
+
+func (r *redisBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	r.RLock()
+	client := r.client
+	r.RUnlock()
+
+	if client == nil {
+		return errors.New("not connected")
+	}
+
+	args, err := r.xaddArgs(topic, msg)
+	if err != nil {
+		return err
+	}
+
+	return client.XAdd(args).Err()
+}
+
+// PublishBatch adds the messages to the stream in a single round trip
+func (r *redisBroker) PublishBatch(topic string, msgs []*broker.Message, opts ...broker.PublishOption) error {
+	r.RLock()
+	client := r.client
+	r.RUnlock()
+
+	if client == nil {
+		return errors.New("not connected")
+	}
+
+	pipe := client.Pipeline()
+	defer pipe.Close()
+
+	for _, msg := range msgs {
+		args, err := r.xaddArgs(topic, msg)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(args)
+	}
+
+	_, err := pipe.Exec()
+	return err
+}
+
+// xaddArgs returns the arguments adding the message to the stream
+func (r *redisBroker) xaddArgs(topic string, msg *broker.Message) (*redis.XAddArgs, error) {
+	b, err := r.opts.Codec.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	args := &redis.XAddArgs{
+		Stream: topic,
+		Values: map[string]interface{}{messageField: b},
+	}
+	if r.exactTrim {
+		args.MaxLen = r.maxLen
+	} else {
+		args.MaxLenApprox = r.maxLen
+	}
+	return args, nil
+}
+
+func (r *redisBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return r.subscribe(topic, handler, nil, opts...)
+}
+
+// SubscribeBatch delivers the messages of each read of the stream as a batch,
+// reading up to the batch size at once
+func (r *redisBroker) SubscribeBatch(topic string, h broker.BatchHandler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return r.subscribe(topic, nil, h, opts...)
+}
+
+func (r *redisBroker) subscribe(topic string, handler broker.Handler, batch broker.BatchHandler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	r.RLock()
+	client := r.client
+	r.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("not connected")
+	}
+
+	opt := broker.SubscribeOptions{
+		AutoAck: true,
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	// the messages are only pending redelivery in a consumer group
+	if opt.Delivery == broker.AtLeastOnce && len(opt.Queue) == 0 {
+		return nil, broker.UnsupportedDelivery(r.String(), opt.Delivery)
+	}
+
+	s := &subscriber{
+		b:        r,
+		client:   client,
+		topic:    topic,
+		consumer: uuid.New().String(),
+		opts:     opt,
+		handler:  handler,
+		batch:    batch,
+		exit:     make(chan bool),
+	}
+
+	if len(opt.Queue) > 0 {
+		// the group gets the messages published from now on, unless it
+		// already exists
+		err := client.XGroupCreateMkStream(topic, opt.Queue, "$").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return nil, err
+		}
+	} else {
+		// the subscribers without a queue get the messages published from
+		// now on, which are after the last message so far
+		msgs, err := client.XRevRangeN(topic, "+", "-", 1).Result()
+		if err != nil {
+			return nil, err
+		}
+		s.last = "0-0"
+		if len(msgs) > 0 {
+			s.last = msgs[0].ID
+		}
+	}
+
+	r.Lock()
+	r.subscribers[s] = true
+	r.Unlock()
+
+	s.wg.Add(1)
+	go s.run()
+
+	if s.acks() {
+		s.wg.Add(1)
+		go s.claim()
+	}
+
+	return s, nil
+}
+
+func (r *redisBroker) String() string {
+	return "redisstream"
+}
+
+func (r *redisBroker) setOption(opts ...broker.Option) {
+	for _, o := range opts {
+		o(&r.opts)
+	}
+
+	if ropts, ok := r.opts.Context.Value(optionsKey{}).(redis.Options); ok {
+		r.ropts = ropts
+	}
+
+	// broker.Options have higher priority than redis.Options, only the
+	// first address is used
+	if len(r.opts.Addrs) > 0 && len(r.opts.Addrs[0]) > 0 {
+		addr := r.opts.Addrs[0]
+		if strings.HasPrefix(addr, "redis://") || strings.HasPrefix(addr, "rediss://") {
+			if ropts, err := redis.ParseURL(addr); err == nil {
+				r.ropts.Addr = ropts.Addr
+				r.ropts.DB = ropts.DB
+				r.ropts.Username = ropts.Username
+				r.ropts.Password = ropts.Password
+				r.ropts.TLSConfig = ropts.TLSConfig
+			} else if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Error parsing broker address %s: %v", addr, err)
+			}
+		} else {
+			r.ropts.Addr = addr
+		}
+	}
+	if len(r.ropts.Addr) == 0 {
+		r.ropts.Addr = DefaultAddress
+	}
+
+	if r.opts.TLSConfig != nil {
+		r.ropts.TLSConfig = r.opts.TLSConfig
+	} else if r.opts.Secure && r.ropts.TLSConfig == nil {
+		// best effort, as the certificate isn't verified
+		r.ropts.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	// authenticate each connection with the credentials of the provider,
+	// so they can be renewed
+	if creds := r.opts.Credentials; creds != nil {
+		r.ropts.OnConnect = func(cn *redis.Conn) error {
+			c, err := creds.Credentials(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(c.Username) > 0 {
+				return cn.AuthACL(c.Username, c.Password).Err()
+			}
+			return cn.Auth(c.Password).Err()
+		}
+	}
+
+	if n, ok := r.opts.Context.Value(maxLenKey{}).(int64); ok {
+		r.maxLen = n
+	}
+	if _, ok := r.opts.Context.Value(exactTrimKey{}).(bool); ok {
+		r.exactTrim = true
+	}
+	if d, ok := r.opts.Context.Value(minIdleKey{}).(time.Duration); ok && d > 0 {
+		r.minIdle = d
+	}
+	if d, ok := r.opts.Context.Value(claimIntervalKey{}).(time.Duration); ok && d > 0 {
+		r.claimInterval = d
+	}
+	if n, ok := r.opts.Context.Value(batchSizeKey{}).(int64); ok && n > 0 {
+		r.batchSize = n
+	}
+}
+
+// NewBroker returns a broker on redis streams
+func NewBroker(opts ...broker.Option) broker.Broker {
+	options := broker.Options{
+		// Default codec
+		Codec:    json.Marshaler{},
+		Context:  context.Background(),
+		Registry: registry.DefaultRegistry,
+	}
+
+	r := &redisBroker{
+		opts:          options,
+		minIdle:       DefaultMinIdle,
+		claimInterval: DefaultClaimInterval,
+		batchSize:     DefaultBatchSize,
+		subscribers:   make(map[*subscriber]bool),
+	}
+	r.setOption(opts...)
+
+	return r
+}
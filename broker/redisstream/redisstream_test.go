@@ -0,0 +1,139 @@
+package redisstream
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+)
+
+func TestInitAddrs(t *testing.T) {
+	testData := []struct {
+		addrs []string
+		addr  string
+		db    int
+	}{
+		{nil, DefaultAddress, 0},
+		{[]string{"10.0.0.1:6380"}, "10.0.0.1:6380", 0},
+		{[]string{"redis://:secret@10.0.0.1:6380/2"}, "10.0.0.1:6380", 2},
+	}
+
+	for _, d := range testData {
+		b := NewBroker()
+		b.Init(broker.Addrs(d.addrs...))
+
+		r := b.(*redisBroker)
+		if r.ropts.Addr != d.addr || r.ropts.DB != d.db {
+			t.Fatalf("Expected %s db %d for %v, got %s db %d", d.addr, d.db, d.addrs, r.ropts.Addr, r.ropts.DB)
+		}
+	}
+
+	b := NewBroker(MaxLen(100), MinIdle(time.Second), BatchSize(5)).(*redisBroker)
+	if b.maxLen != 100 || b.exactTrim || b.minIdle != time.Second || b.batchSize != 5 || b.claimInterval != DefaultClaimInterval {
+		t.Fatalf("Unexpected options %+v", b)
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker(MinIdle(100*time.Millisecond), ClaimInterval(100*time.Millisecond))
+	if err := b.Connect(); err != nil {
+		t.Skip("broker/redisstream: can't connect to redis")
+	}
+	defer b.Disconnect()
+
+	topic := "go.micro.test.redisstream." + time.Now().Format("150405.000000")
+
+	// the queue subscriber failing first gets the message again once claimed
+	var mtx sync.Mutex
+	var calls int
+	done := make(chan *broker.Message, 1)
+	sub, err := b.Subscribe(topic, func(e broker.Event) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		calls++
+		if calls == 1 {
+			return broker.ErrUnsupportedDelivery
+		}
+		done <- e.Message()
+		return nil
+	}, broker.Queue("test"), broker.DeliveryMode(broker.AtLeastOnce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// the subscribers without a queue can't redeliver
+	if _, err := b.Subscribe(topic, nil, broker.DeliveryMode(broker.AtLeastOnce)); err == nil {
+		t.Fatal("Expected at least once delivery to be unsupported without a queue")
+	}
+
+	msg := &broker.Message{Header: map[string]string{"Foo": "bar"}, Body: []byte("hello")}
+	if err := b.Publish(topic, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-done:
+		if m.Header["Foo"] != "bar" || string(m.Body) != "hello" {
+			t.Fatalf("Unexpected message %+v", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the message to be redelivered")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	b := NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Skip("broker/redisstream: can't connect to redis")
+	}
+	defer b.Disconnect()
+
+	topic := "go.micro.test.redisstream.batch." + time.Now().Format("150405.000000")
+
+	batches := make(chan int, 10)
+	sub, err := b.(broker.BatchSubscriber).SubscribeBatch(topic, func(events []broker.Event) error {
+		batches <- len(events)
+		return nil
+	}, broker.Queue("test"), broker.BatchSize(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	var msgs []*broker.Message
+	for i := 0; i < 8; i++ {
+		msgs = append(msgs, &broker.Message{Body: []byte("hello")})
+	}
+	if err := b.(broker.BatchPublisher).PublishBatch(topic, msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	// no batch is larger than the batch size
+	var n int
+	for n < len(msgs) {
+		select {
+		case size := <-batches:
+			if size > 5 {
+				t.Fatalf("Expected batches of up to 5 messages, got %d", size)
+			}
+			n += size
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %d messages, got %d", len(msgs), n)
+		}
+	}
+}
+
+func TestNextID(t *testing.T) {
+	testData := map[string]string{
+		"1526919030474-55": "1526919030474-56",
+		"0-0":              "0-1",
+		"invalid":          "invalid",
+	}
+	for id, next := range testData {
+		if got := nextID(id); got != next {
+			t.Fatalf("Expected the id after %s to be %s, got %s", id, next, got)
+		}
+	}
+}
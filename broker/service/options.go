@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 
+	"github.com/micro/go-micro/v2/auth"
 	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/client"
 )
 
 type clientKey struct{}
+type authKey struct{}
 
 // Client to call broker service
 func Client(c client.Client) broker.Option {
@@ -20,3 +22,13 @@ func Client(c client.Client) broker.Option {
 		o.Context = context.WithValue(o.Context, clientKey{}, c)
 	}
 }
+
+// Auth used to verify access to topics before calling the broker service
+func Auth(a auth.Auth) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, authKey{}, a)
+	}
+}
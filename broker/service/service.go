@@ -5,9 +5,11 @@ import (
 	"context"
 	"time"
 
+	"github.com/micro/go-micro/v2/auth"
 	"github.com/micro/go-micro/v2/broker"
 	pb "github.com/micro/go-micro/v2/broker/service/proto"
 	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/logger"
 )
 
@@ -17,8 +19,31 @@ type serviceBroker struct {
 	options broker.Options
 }
 
+// verify the account has access to the topic if an auth was provided
+func (b *serviceBroker) verify(topic, endpoint string) error {
+	if b.options.Context == nil {
+		return nil
+	}
+	a, ok := b.options.Context.Value(authKey{}).(auth.Auth)
+	if !ok {
+		return nil
+	}
+
+	if err := auth.VerifyTopic(a, topic, endpoint); err == auth.ErrForbidden {
+		return errors.Forbidden(DefaultName, "Forbidden %v to topic %v", endpoint, topic)
+	} else if err == auth.ErrInvalidToken {
+		return errors.Unauthorized(DefaultName, "Unauthorized %v to topic %v: %v", endpoint, topic, err)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
 var (
 	DefaultName = "go.micro.broker"
+
+	// log of the broker module
+	log = logger.NewModule("broker")
 )
 
 func (b *serviceBroker) Address() string {
@@ -45,8 +70,11 @@ func (b *serviceBroker) Options() broker.Options {
 }
 
 func (b *serviceBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
-	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-		logger.Debugf("Publishing to topic %s broker %v", topic, b.Addrs)
+	if err := b.verify(topic, auth.TopicPublish); err != nil {
+		return err
+	}
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("Publishing to topic %s broker %v", topic, b.Addrs)
 	}
 	_, err := b.Client.Publish(context.TODO(), &pb.PublishRequest{
 		Topic: topic,
@@ -63,8 +91,15 @@ func (b *serviceBroker) Subscribe(topic string, handler broker.Handler, opts ...
 	for _, o := range opts {
 		o(&options)
 	}
-	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-		logger.Debugf("Subscribing to topic %s queue %s broker %v", topic, options.Queue, b.Addrs)
+	// the subscribe stream doesn't redeliver messages
+	if options.Delivery == broker.AtLeastOnce {
+		return nil, broker.UnsupportedDelivery(b.String(), options.Delivery)
+	}
+	if err := b.verify(topic, auth.TopicSubscribe); err != nil {
+		return nil, err
+	}
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("Subscribing to topic %s queue %s broker %v", topic, options.Queue, b.Addrs)
 	}
 	stream, err := b.Client.Subscribe(context.TODO(), &pb.SubscribeRequest{
 		Topic: topic,
@@ -87,26 +122,26 @@ func (b *serviceBroker) Subscribe(topic string, handler broker.Handler, opts ...
 		for {
 			select {
 			case <-sub.closed:
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debugf("Unsubscribed from topic %s", topic)
+				if logger.V(logger.DebugLevel, log) {
+					log.Debugf("Unsubscribed from topic %s", topic)
 				}
 				return
 			default:
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				if logger.V(logger.DebugLevel, log) {
 					// run the subscriber
-					logger.Debugf("Streaming from broker %v to topic [%s] queue [%s]", b.Addrs, topic, options.Queue)
+					log.Debugf("Streaming from broker %v to topic [%s] queue [%s]", b.Addrs, topic, options.Queue)
 				}
 				if err := sub.run(); err != nil {
-					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-						logger.Debugf("Resubscribing to topic %s broker %v", topic, b.Addrs)
+					if logger.V(logger.DebugLevel, log) {
+						log.Debugf("Resubscribing to topic %s broker %v", topic, b.Addrs)
 					}
 					stream, err := b.Client.Subscribe(context.TODO(), &pb.SubscribeRequest{
 						Topic: topic,
 						Queue: options.Queue,
 					}, client.WithAddress(b.Addrs...), client.WithRequestTimeout(time.Hour))
 					if err != nil {
-						if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-							logger.Debugf("Failed to resubscribe to topic %s: %v", topic, err)
+						if logger.V(logger.DebugLevel, log) {
+							log.Debugf("Failed to resubscribe to topic %s: %v", topic, err)
 						}
 						time.Sleep(time.Second)
 						continue
@@ -33,6 +33,15 @@ func (s *serviceEvent) Ack() error {
 	return nil
 }
 
+// Nack can't requeue the message, as the subscribe stream doesn't redeliver
+// messages
+func (s *serviceEvent) Nack(requeue bool) error {
+	if requeue {
+		return broker.UnsupportedDelivery("service", broker.AtLeastOnce)
+	}
+	return nil
+}
+
 func (s *serviceEvent) Error() error {
 	return s.err
 }
@@ -62,8 +71,8 @@ func (s *serviceSub) run() error {
 		// TODO: do not fail silently
 		msg, err := s.stream.Recv()
 		if err != nil {
-			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-				logger.Debugf("Streaming error for subcription to topic %s: %v", s.Topic(), err)
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Streaming error for subcription to topic %s: %v", s.Topic(), err)
 			}
 
 			// close the exit channel
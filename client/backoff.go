@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"math/rand"
 	"time"
 
 	"github.com/micro/go-micro/v2/util/backoff"
@@ -12,3 +13,35 @@ type BackoffFunc func(ctx context.Context, req Request, attempts int) (time.Dura
 func exponentialBackoff(ctx context.Context, req Request, attempts int) (time.Duration, error) {
 	return backoff.Do(attempts), nil
 }
+
+// ExponentialBackoff returns a backoff doubling from base with every retry up
+// to max. The jitter is the fraction of the backoff randomly taken off so the
+// retries of many clients don't happen at once, 1 is full jitter.
+func ExponentialBackoff(base, max time.Duration, jitter float64) BackoffFunc {
+	if jitter < 0 {
+		jitter = 0
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+
+	return func(ctx context.Context, req Request, attempts int) (time.Duration, error) {
+		// the first attempt isn't a retry
+		if attempts <= 0 || base <= 0 {
+			return 0, nil
+		}
+
+		d := base
+		for i := 1; i < attempts && (max <= 0 || d < max); i++ {
+			d *= 2
+		}
+		if max > 0 && d > max {
+			d = max
+		}
+
+		if jitter > 0 {
+			d -= time.Duration(rand.Float64() * jitter * float64(d))
+		}
+		return d, nil
+	}
+}
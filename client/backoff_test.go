@@ -29,3 +29,36 @@ func TestBackoff(t *testing.T) {
 		}
 	}
 }
+
+func TestExponentialBackoff(t *testing.T) {
+	req := NewClient().NewRequest("test", "test", nil)
+
+	fn := ExponentialBackoff(100*time.Millisecond, time.Second, 0)
+	results := []time.Duration{
+		0,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, r := range results {
+		d, err := fn(context.TODO(), req, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != r {
+			t.Fatalf("Expected %v for attempt %d, got %v", r, i, d)
+		}
+	}
+
+	// the jitter takes off up to the fraction of the backoff
+	fn = ExponentialBackoff(100*time.Millisecond, time.Second, 0.5)
+	for i := 0; i < 100; i++ {
+		d, _ := fn(context.TODO(), req, 3)
+		if d < 200*time.Millisecond || d > 400*time.Millisecond {
+			t.Fatalf("Expected a backoff between 200ms and 400ms, got %v", d)
+		}
+	}
+}
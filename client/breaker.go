@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+var (
+	// DefaultBreakerWindow is the rolling window the errors are counted over
+	DefaultBreakerWindow = 10 * time.Second
+	// DefaultBreakerBuckets is the number of buckets the window is divided in
+	DefaultBreakerBuckets = 10
+	// DefaultBreakerMinRequests is the number of requests in the window
+	// needed before the circuit can open
+	DefaultBreakerMinRequests = 20
+	// DefaultBreakerErrorRate is the ratio of failed requests opening the circuit
+	DefaultBreakerErrorRate = 0.5
+	// DefaultBreakerCooldown is how long the circuit stays open before probing
+	DefaultBreakerCooldown = 5 * time.Second
+	// DefaultBreakerProbes is the number of successful probes closing the circuit
+	DefaultBreakerProbes = 1
+)
+
+// Breaker is a circuit breaker, it rejects the calls to an endpoint which is
+// failing so it can recover rather than be overwhelmed by calls which fail
+type Breaker interface {
+	// Allow returns an error if the request is rejected, otherwise done must
+	// be called with the result of the request
+	Allow(req Request) (done func(error), err error)
+}
+
+// BreakerOptions configure the default breaker
+type BreakerOptions struct {
+	// Window is the rolling window the errors are counted over
+	Window time.Duration
+	// Buckets is the number of buckets the window is divided in
+	Buckets int
+	// MinRequests is the number of requests in the window needed before
+	// the circuit can open
+	MinRequests int
+	// ErrorRate is the ratio of failed requests opening the circuit
+	ErrorRate float64
+	// Cooldown is how long the circuit stays open before half opening to
+	// let probe requests through
+	Cooldown time.Duration
+	// Probes is the number of successful probes closing the circuit
+	Probes int
+	// Failure classifies the errors of requests, true counts as a failure
+	Failure func(err error) bool
+	// Scope returns the name of the circuit of the request
+	Scope func(req Request) string
+}
+
+// BreakerOption sets an option of the default breaker
+type BreakerOption func(o *BreakerOptions)
+
+// BreakerWindow sets the rolling window the errors are counted over
+func BreakerWindow(d time.Duration, buckets int) BreakerOption {
+	return func(o *BreakerOptions) {
+		o.Window = d
+		o.Buckets = buckets
+	}
+}
+
+// BreakerThreshold sets the ratio of failed requests opening the circuit once
+// there are at least min requests in the window
+func BreakerThreshold(rate float64, min int) BreakerOption {
+	return func(o *BreakerOptions) {
+		o.ErrorRate = rate
+		o.MinRequests = min
+	}
+}
+
+// BreakerCooldown sets how long the circuit stays open, and the number of
+// successful probes closing it after
+func BreakerCooldown(d time.Duration, probes int) BreakerOption {
+	return func(o *BreakerOptions) {
+		o.Cooldown = d
+		o.Probes = probes
+	}
+}
+
+// BreakerFailure sets the func classifying the errors counting as failures
+func BreakerFailure(fn func(err error) bool) BreakerOption {
+	return func(o *BreakerOptions) {
+		o.Failure = fn
+	}
+}
+
+// BreakerScope sets the func returning the circuit of a request, by default
+// each endpoint of a service has its own circuit
+func BreakerScope(fn func(req Request) string) BreakerOption {
+	return func(o *BreakerOptions) {
+		o.Scope = fn
+	}
+}
+
+// IsFailure is the default classification of errors, timeouts, server and
+// network errors are failures while errors of the caller e.g. a bad request
+// aren't
+func IsFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Code == 0 || e.Code == 408 || e.Code >= 500
+}
+
+// ScopeEndpoint scopes a circuit to the service and endpoint of the request
+func ScopeEndpoint(req Request) string {
+	return req.Service() + "." + req.Endpoint()
+}
+
+// NewBreaker returns the default breaker counting the failures over a
+// rolling window, the circuit is probed once it cooled down
+func NewBreaker(opts ...BreakerOption) Breaker {
+	options := BreakerOptions{
+		Window:      DefaultBreakerWindow,
+		Buckets:     DefaultBreakerBuckets,
+		MinRequests: DefaultBreakerMinRequests,
+		ErrorRate:   DefaultBreakerErrorRate,
+		Cooldown:    DefaultBreakerCooldown,
+		Probes:      DefaultBreakerProbes,
+		Failure:     IsFailure,
+		Scope:       ScopeEndpoint,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.Buckets <= 0 {
+		options.Buckets = 1
+	}
+	if options.Probes <= 0 {
+		options.Probes = 1
+	}
+
+	return &breaker{
+		opts:     options,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+type breaker struct {
+	opts BreakerOptions
+
+	sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuit struct {
+	state circuitState
+	// opened is when the circuit opened
+	opened time.Time
+	// probes in flight and succeeded while half open
+	probing, probed int
+	// buckets of the rolling window
+	buckets []bucket
+}
+
+type bucket struct {
+	start              time.Time
+	requests, failures int
+}
+
+func (b *breaker) Allow(req Request) (func(error), error) {
+	scope := b.opts.Scope(req)
+	now := time.Now()
+
+	b.Lock()
+	defer b.Unlock()
+
+	c, ok := b.circuits[scope]
+	if !ok {
+		c = &circuit{buckets: make([]bucket, b.opts.Buckets)}
+		b.circuits[scope] = c
+	}
+
+	probe := false
+
+	switch c.state {
+	case circuitOpen:
+		if now.Sub(c.opened) < b.opts.Cooldown {
+			return nil, errors.ServiceUnavailable("go.micro.client", "circuit breaker open for %s", scope)
+		}
+		c.state = circuitHalfOpen
+		c.probing, c.probed = 0, 0
+		fallthrough
+	case circuitHalfOpen:
+		// only let as many probes through as are needed to close
+		if c.probing >= b.opts.Probes-c.probed {
+			return nil, errors.ServiceUnavailable("go.micro.client", "circuit breaker half open for %s", scope)
+		}
+		c.probing++
+		probe = true
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			b.done(c, probe, b.opts.Failure(err))
+		})
+	}, nil
+}
+
+// done records the result of a request
+func (b *breaker) done(c *circuit, probe, failed bool) {
+	now := time.Now()
+
+	b.Lock()
+	defer b.Unlock()
+
+	if probe {
+		// the circuit may have changed state since
+		if c.state != circuitHalfOpen {
+			return
+		}
+		c.probing--
+		if failed {
+			c.state = circuitOpen
+			c.opened = now
+			return
+		}
+		c.probed++
+		if c.probed >= b.opts.Probes {
+			c.state = circuitClosed
+			c.buckets = make([]bucket, b.opts.Buckets)
+		}
+		return
+	}
+
+	if c.state != circuitClosed {
+		return
+	}
+
+	// count the request in the current bucket
+	width := b.opts.Window / time.Duration(b.opts.Buckets)
+	start := now.Truncate(width)
+	bk := &c.buckets[int(start.UnixNano()/int64(width))%len(c.buckets)]
+	if !bk.start.Equal(start) {
+		*bk = bucket{start: start}
+	}
+	bk.requests++
+	if failed {
+		bk.failures++
+	}
+
+	// sum the buckets in the window
+	var requests, failures int
+	for _, bk := range c.buckets {
+		if now.Sub(bk.start) < b.opts.Window {
+			requests += bk.requests
+			failures += bk.failures
+		}
+	}
+
+	if requests >= b.opts.MinRequests && float64(failures)/float64(requests) >= b.opts.ErrorRate {
+		c.state = circuitOpen
+		c.opened = now
+	}
+}
+
+// AllowCall asks the breaker of the call whether the attempt can be made,
+// reporting the rejections to the retry hooks
+func AllowCall(ctx context.Context, req Request, opts CallOptions, attempt int) (func(error), error) {
+	if opts.Breaker == nil {
+		return func(error) {}, nil
+	}
+
+	done, err := opts.Breaker.Allow(req)
+	if err != nil {
+		ReportRetry(ctx, opts, NewRetryEvent(req, RetryReject, attempt, err.Error()))
+		return nil, err
+	}
+	return done, nil
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+func TestBreaker(t *testing.T) {
+	b := NewBreaker(
+		BreakerThreshold(0.5, 4),
+		BreakerCooldown(50*time.Millisecond, 2),
+	)
+
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+	other := newRequest("foo", "Foo.Baz", nil, "application/json")
+
+	call := func(r Request, err error) error {
+		done, aerr := b.Allow(r)
+		if aerr != nil {
+			return aerr
+		}
+		done(err)
+		return nil
+	}
+
+	// errors of the caller don't open the circuit
+	for i := 0; i < 10; i++ {
+		if err := call(other, errors.BadRequest("foo", "bad request")); err != nil {
+			t.Fatalf("Unexpected rejection: %v", err)
+		}
+	}
+
+	// the circuit needs the min requests to open
+	for i := 0; i < 4; i++ {
+		if err := call(req, errors.InternalServerError("foo", "failed")); err != nil {
+			t.Fatalf("Unexpected rejection after %d failures: %v", i, err)
+		}
+	}
+
+	err := call(req, nil)
+	if err == nil {
+		t.Fatal("Expected the circuit to be open")
+	}
+	if e := errors.FromError(err); e.Code != 503 {
+		t.Fatalf("Expected a 503 rejection, got %v", err)
+	}
+
+	// circuits are scoped to the endpoint
+	if err := call(other, nil); err != nil {
+		t.Fatalf("Unexpected rejection of another endpoint: %v", err)
+	}
+
+	// once cooled down only the probes are let through
+	time.Sleep(60 * time.Millisecond)
+	var probes []func(error)
+	for i := 0; i < 2; i++ {
+		done, err := b.Allow(req)
+		if err != nil {
+			t.Fatalf("Unexpected rejection of probe %d: %v", i, err)
+		}
+		probes = append(probes, done)
+	}
+	if _, err := b.Allow(req); err == nil {
+		t.Fatal("Expected the half open circuit to reject more than the probes")
+	}
+
+	// a failed probe opens the circuit again
+	probes[0](fmt.Errorf("failed"))
+	probes[1](nil)
+	if err := call(req, nil); err == nil {
+		t.Fatal("Expected the circuit to open after a failed probe")
+	}
+
+	// successful probes close it
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := call(req, nil); err != nil {
+			t.Fatalf("Unexpected rejection of probe %d: %v", i, err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if err := call(req, nil); err != nil {
+			t.Fatalf("Unexpected rejection once closed: %v", err)
+		}
+	}
+}
+
+func TestBreakerWindow(t *testing.T) {
+	b := NewBreaker(
+		BreakerWindow(50*time.Millisecond, 5),
+		BreakerThreshold(0.5, 2),
+	)
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	done, _ := b.Allow(req)
+	done(fmt.Errorf("failed"))
+
+	// the failure is out of the window
+	time.Sleep(60 * time.Millisecond)
+
+	done, _ = b.Allow(req)
+	done(fmt.Errorf("failed"))
+
+	if _, err := b.Allow(req); err != nil {
+		t.Fatalf("Unexpected rejection of failures out of the window: %v", err)
+	}
+}
+
+func TestAllowCall(t *testing.T) {
+	var events []RetryEvent
+	opts := CallOptions{
+		Breaker: NewBreaker(BreakerThreshold(0.5, 1)),
+		RetryHooks: []RetryHook{func(ctx context.Context, e RetryEvent) {
+			events = append(events, e)
+		}},
+	}
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	done, err := AllowCall(context.TODO(), req, opts, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(fmt.Errorf("failed"))
+
+	if _, err := AllowCall(context.TODO(), req, opts, 1); err == nil {
+		t.Fatal("Expected the call to be rejected")
+	}
+	if len(events) != 1 || events[0].Type != RetryReject || events[0].Attempt != 1 {
+		t.Fatalf("Expected a reject event, got %+v", events)
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultBudgetWindow is the window the requests and retries of a budget
+	// are counted over
+	DefaultBudgetWindow = 10 * time.Second
+)
+
+// Budget limits the retries to a ratio of the requests, so retries don't pile
+// on a service which is partially down. A budget is shared by the calls it's
+// set on, set one per service to budget them separately.
+type Budget struct {
+	ratio  float64
+	min    int
+	window time.Duration
+
+	sync.Mutex
+	// counts of the requests and retries per second of the window
+	buckets []budgetBucket
+}
+
+type budgetBucket struct {
+	second            int64
+	requests, retries int
+}
+
+// NewBudget returns a budget allowing retries of up to the ratio of the
+// requests e.g. 0.1 is retrying one in ten requests, and at least min retries
+// per second so services with little traffic can retry at all
+func NewBudget(ratio float64, min int) *Budget {
+	return NewBudgetWindow(ratio, min, DefaultBudgetWindow)
+}
+
+// NewBudgetWindow returns a budget counting the requests and retries over
+// the window rather than the default one
+func NewBudgetWindow(ratio float64, min int, window time.Duration) *Budget {
+	seconds := int(window / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &Budget{
+		ratio:   ratio,
+		min:     min,
+		window:  time.Duration(seconds) * time.Second,
+		buckets: make([]budgetBucket, seconds),
+	}
+}
+
+// bucket returns the bucket of the current second. Should be called under lock.
+func (b *Budget) bucket(now time.Time) *budgetBucket {
+	sec := now.Unix()
+	bk := &b.buckets[int(sec%int64(len(b.buckets)))]
+	if bk.second != sec {
+		*bk = budgetBucket{second: sec}
+	}
+	return bk
+}
+
+// Deposit counts a request, the budget is nil safe so calls without one
+// don't have to check
+func (b *Budget) Deposit() {
+	if b == nil {
+		return
+	}
+
+	b.Lock()
+	b.bucket(time.Now()).requests++
+	b.Unlock()
+}
+
+// Withdraw returns true and counts the retry if the budget allows it
+func (b *Budget) Withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	now := time.Now()
+
+	b.Lock()
+	defer b.Unlock()
+
+	current := b.bucket(now)
+
+	var requests, retries int
+	oldest := now.Unix() - int64(len(b.buckets))
+	for _, bk := range b.buckets {
+		if bk.second > oldest {
+			requests += bk.requests
+			retries += bk.retries
+		}
+	}
+
+	allowed := float64(b.min)*b.window.Seconds() + b.ratio*float64(requests)
+	if float64(retries) >= allowed {
+		return false
+	}
+
+	current.retries++
+	return true
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router/static"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudgetWindow(0.1, 0, time.Second)
+
+	// no requests no retries
+	if b.Withdraw() {
+		t.Fatal("Expected no retries without requests")
+	}
+
+	for i := 0; i < 20; i++ {
+		b.Deposit()
+	}
+	for i := 0; i < 2; i++ {
+		if !b.Withdraw() {
+			t.Fatalf("Expected retry %d to be allowed", i)
+		}
+	}
+	if b.Withdraw() {
+		t.Fatal("Expected retries over 10% of the requests to be rejected")
+	}
+
+	// the min retries are allowed regardless of the requests
+	b = NewBudgetWindow(0.1, 2, time.Second)
+	for i := 0; i < 2; i++ {
+		if !b.Withdraw() {
+			t.Fatalf("Expected min retry %d to be allowed", i)
+		}
+	}
+	if b.Withdraw() {
+		t.Fatal("Expected retries over the min to be rejected")
+	}
+
+	// a nil budget allows any retry
+	var nb *Budget
+	nb.Deposit()
+	if !nb.Withdraw() {
+		t.Fatal("Expected a nil budget to allow retries")
+	}
+}
+
+func TestBudgetCall(t *testing.T) {
+	var attempts int
+	c := NewClient(
+		Router(static.NewRouter()),
+		Retries(5),
+		Retry(RetryAlways),
+		Backoff(ExponentialBackoff(0, 0, 0)),
+		RetryBudget(NewBudgetWindow(0, 1, time.Second)),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				attempts++
+				return errors.InternalServerError("test", "failed")
+			}
+		}),
+	)
+
+	req := c.NewRequest("test", "Test.Call", nil)
+	if err := c.Call(context.TODO(), req, nil); err == nil {
+		t.Fatal("Expected the call to fail")
+	}
+	// the first attempt and the one retry the budget allows
+	if attempts != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", attempts)
+	}
+}
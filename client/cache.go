@@ -1,36 +1,222 @@
 package client
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"sync"
 	"time"
 
+	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/metadata"
 	cache "github.com/patrickmn/go-cache"
 )
 
+var (
+	// DefaultCacheTopic is the topic of the cache invalidation events
+	DefaultCacheTopic = "go.micro.client.cache"
+)
+
 // NewCache returns an initialised cache.
 func NewCache() *Cache {
-	return &Cache{
-		cache: cache.New(cache.NoExpiration, 30*time.Second),
+	c := &Cache{
+		cache:     cache.New(cache.NoExpiration, 30*time.Second),
+		policies:  make(map[string]CachePolicy),
+		entries:   make(map[string]*cacheEntry),
+		endpoints: make(map[string]*list.List),
 	}
+	c.cache.OnEvicted(func(k string, v interface{}) {
+		c.Lock()
+		c.remove(k)
+		c.Unlock()
+	})
+	return c
 }
 
 // Cache for responses
 type Cache struct {
 	cache *cache.Cache
+
+	sync.RWMutex
+	// policies by service and endpoint
+	policies map[string]CachePolicy
+	// entries by key
+	entries map[string]*cacheEntry
+	// keys of the entries of each endpoint, oldest first
+	endpoints map[string]*list.List
+}
+
+// CachePolicy is how the responses of an endpoint are cached
+type CachePolicy struct {
+	// TTL of the responses, calls without a cache expiry use it
+	TTL time.Duration
+	// MaxSize is the max number of responses cached, the oldest ones are
+	// evicted. Zero is no limit.
+	MaxSize int
+	// Vary are the metadata keys whose values are part of the cache key
+	// e.g. Authorization so every caller gets their own responses
+	Vary []string
+}
+
+// CacheInvalidation is the event invalidating cached responses
+type CacheInvalidation struct {
+	// Service of the responses
+	Service string `json:"service"`
+	// Endpoint of the responses, all the endpoints of the service if blank
+	Endpoint string `json:"endpoint,omitempty"`
+	// Request the response is for, all the requests if blank
+	Request json.RawMessage `json:"request,omitempty"`
+}
+
+type cacheEntry struct {
+	service  string
+	endpoint string
+	body     string
+	elem     *list.Element
+}
+
+// SetPolicy sets the cache policy of an endpoint
+func (c *Cache) SetPolicy(service, endpoint string, p CachePolicy) {
+	c.Lock()
+	c.policies[service+"/"+endpoint] = p
+	c.Unlock()
+}
+
+// Policy returns the cache policy of an endpoint
+func (c *Cache) Policy(service, endpoint string) (CachePolicy, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	p, ok := c.policies[service+"/"+endpoint]
+	return p, ok
 }
 
 // Get a response from the cache
 func (c *Cache) Get(ctx context.Context, req *Request) (interface{}, bool) {
-	return c.cache.Get(key(ctx, req))
+	p, _ := c.Policy((*req).Service(), (*req).Endpoint())
+	return c.cache.Get(key(ctx, req, p.Vary...))
 }
 
 // Set a response in the cache
 func (c *Cache) Set(ctx context.Context, req *Request, rsp interface{}, expiry time.Duration) {
-	c.cache.Set(key(ctx, req), rsp, expiry)
+	endpoint := (*req).Service() + "/" + (*req).Endpoint()
+
+	c.Lock()
+	p := c.policies[endpoint]
+	k := key(ctx, req, p.Vary...)
+
+	// track the entry so it can be invalidated
+	c.remove(k)
+	keys, ok := c.endpoints[endpoint]
+	if !ok {
+		keys = list.New()
+		c.endpoints[endpoint] = keys
+	}
+	c.entries[k] = &cacheEntry{
+		service:  (*req).Service(),
+		endpoint: endpoint,
+		body:     bodyHash((*req).Body()),
+		elem:     keys.PushBack(k),
+	}
+
+	// evict the oldest entries over the max size
+	var evict []string
+	for p.MaxSize > 0 && keys.Len() > p.MaxSize {
+		old := keys.Front().Value.(string)
+		c.remove(old)
+		evict = append(evict, old)
+	}
+
+	// set under the lock so an invalidation can't come in between
+	c.cache.Set(k, rsp, expiry)
+	c.Unlock()
+
+	// deleted outside of the lock as the cache calls back on eviction
+	for _, old := range evict {
+		c.cache.Delete(old)
+	}
+}
+
+// Invalidate the cached responses to the request of the endpoint of the
+// service. A nil request invalidates every response of the endpoint, and a
+// blank endpoint every response of the service.
+func (c *Cache) Invalidate(service, endpoint string, req interface{}) {
+	var body string
+	if req != nil {
+		body = bodyHash(req)
+	}
+
+	c.Lock()
+	var keys []string
+	for k, e := range c.entries {
+		if e.service != service || (len(endpoint) > 0 && e.endpoint != service+"/"+endpoint) {
+			continue
+		}
+		if len(body) > 0 && e.body != body {
+			continue
+		}
+		c.remove(k)
+		keys = append(keys, k)
+	}
+	c.Unlock()
+
+	for _, k := range keys {
+		c.cache.Delete(k)
+	}
+}
+
+// Subscribe invalidates the cached responses on the invalidation events
+// published to the topic, DefaultCacheTopic if blank, with PublishInvalidation
+func (c *Cache) Subscribe(b broker.Broker, topic string) (broker.Subscriber, error) {
+	if len(topic) == 0 {
+		topic = DefaultCacheTopic
+	}
+
+	return b.Subscribe(topic, func(e broker.Event) error {
+		var inv CacheInvalidation
+		if err := json.Unmarshal(e.Message().Body, &inv); err != nil {
+			return err
+		}
+
+		var req interface{}
+		if len(inv.Request) > 0 {
+			req = inv.Request
+		}
+		c.Invalidate(inv.Service, inv.Endpoint, req)
+		return nil
+	})
+}
+
+// PublishInvalidation publishes an event invalidating the cached responses to
+// the request of the endpoint of the service in the clients subscribed to the
+// topic, DefaultCacheTopic if blank. See Invalidate.
+func PublishInvalidation(b broker.Broker, topic, service, endpoint string, req interface{}) error {
+	if len(topic) == 0 {
+		topic = DefaultCacheTopic
+	}
+
+	inv := CacheInvalidation{
+		Service:  service,
+		Endpoint: endpoint,
+	}
+	if req != nil {
+		body, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		inv.Request = body
+	}
+
+	body, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	return b.Publish(topic, &broker.Message{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   body,
+	})
 }
 
 // List the key value pairs in the cache
@@ -46,11 +232,35 @@ func (c *Cache) List() map[string]string {
 	return rsp
 }
 
-// key returns a hash for the context and request
-func key(ctx context.Context, req *Request) string {
+// remove the entry from the index. Should be called under lock.
+func (c *Cache) remove(k string) {
+	e, ok := c.entries[k]
+	if !ok {
+		return
+	}
+	delete(c.entries, k)
+
+	keys := c.endpoints[e.endpoint]
+	keys.Remove(e.elem)
+	if keys.Len() == 0 {
+		delete(c.endpoints, e.endpoint)
+	}
+}
+
+// bodyHash returns a hash of the request body
+func bodyHash(body interface{}) string {
+	bytes, _ := json.Marshal(body)
+	h := fnv.New64()
+	h.Write(bytes)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// key returns a hash for the context and request, the values of the vary
+// metadata keys are part of it
+func key(ctx context.Context, req *Request, vary ...string) string {
 	ns, _ := metadata.Get(ctx, "Micro-Namespace")
 
-	bytes, _ := json.Marshal(map[string]interface{}{
+	values := map[string]interface{}{
 		"namespace": ns,
 		"request": map[string]interface{}{
 			"service":  (*req).Service(),
@@ -58,7 +268,17 @@ func key(ctx context.Context, req *Request) string {
 			"method":   (*req).Method(),
 			"body":     (*req).Body(),
 		},
-	})
+	}
+
+	if len(vary) > 0 {
+		md := make(map[string]string, len(vary))
+		for _, k := range vary {
+			md[k], _ = metadata.Get(ctx, k)
+		}
+		values["vary"] = md
+	}
+
+	bytes, _ := json.Marshal(values)
 
 	h := fnv.New64()
 	h.Write(bytes)
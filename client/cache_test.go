@@ -2,9 +2,11 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/micro/go-micro/v2/broker/memory"
 	"github.com/micro/go-micro/v2/metadata"
 )
 
@@ -74,3 +76,109 @@ func TestCacheKey(t *testing.T) {
 		}
 	})
 }
+
+func TestCachePolicy(t *testing.T) {
+	c := NewCache()
+	c.SetPolicy("foo", "Foo.Bar", CachePolicy{TTL: time.Minute, MaxSize: 2, Vary: []string{"Authorization"}})
+
+	req1 := newRequest("foo", "Foo.Bar", "1", "application/json")
+	req2 := newRequest("foo", "Foo.Bar", "2", "application/json")
+	req3 := newRequest("foo", "Foo.Bar", "3", "application/json")
+
+	// the vary metadata is part of the key
+	alice := metadata.Set(context.TODO(), "Authorization", "alice")
+	bob := metadata.Set(context.TODO(), "Authorization", "bob")
+
+	c.Set(alice, &req1, "alice", time.Minute)
+	if _, ok := c.Get(bob, &req1); ok {
+		t.Fatal("Expected no response for another caller")
+	}
+	if rsp, ok := c.Get(alice, &req1); !ok || rsp != "alice" {
+		t.Fatalf("Expected the response of the caller, got %v", rsp)
+	}
+
+	// the oldest responses are evicted over the max size
+	c.Set(alice, &req2, "2", time.Minute)
+	c.Set(alice, &req3, "3", time.Minute)
+	if _, ok := c.Get(alice, &req1); ok {
+		t.Fatal("Expected the oldest response to be evicted")
+	}
+	for _, req := range []Request{req2, req3} {
+		if _, ok := c.Get(alice, &req); !ok {
+			t.Fatalf("Expected the response to %v to be cached", req.Body())
+		}
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	ctx := context.TODO()
+	c := NewCache()
+
+	req1 := newRequest("foo", "Foo.Bar", "1", "application/json")
+	req2 := newRequest("foo", "Foo.Bar", "2", "application/json")
+	req3 := newRequest("foo", "Foo.Baz", "1", "application/json")
+	req4 := newRequest("bar", "Bar.Baz", "1", "application/json")
+
+	set := func() {
+		for _, req := range []Request{req1, req2, req3, req4} {
+			c.Set(ctx, &req, "rsp", time.Minute)
+		}
+	}
+	cached := func(reqs ...Request) []bool {
+		var res []bool
+		for _, req := range reqs {
+			_, ok := c.Get(ctx, &req)
+			res = append(res, ok)
+		}
+		return res
+	}
+
+	testData := []struct {
+		service  string
+		endpoint string
+		req      interface{}
+		cached   []bool
+	}{
+		{"foo", "Foo.Bar", "1", []bool{false, true, true, true}},
+		{"foo", "Foo.Bar", nil, []bool{false, false, true, true}},
+		{"foo", "", nil, []bool{false, false, false, true}},
+	}
+
+	for _, d := range testData {
+		set()
+		c.Invalidate(d.service, d.endpoint, d.req)
+		if got := cached(req1, req2, req3, req4); fmt.Sprint(got) != fmt.Sprint(d.cached) {
+			t.Fatalf("Expected cached %v invalidating %s %s %v, got %v", d.cached, d.service, d.endpoint, d.req, got)
+		}
+	}
+}
+
+func TestCacheSubscribe(t *testing.T) {
+	ctx := context.TODO()
+	b := memory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	c := NewCache()
+	if _, err := c.Subscribe(b, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	req1 := newRequest("foo", "Foo.Bar", map[string]string{"id": "1"}, "application/json")
+	req2 := newRequest("foo", "Foo.Bar", map[string]string{"id": "2"}, "application/json")
+	c.Set(ctx, &req1, "rsp", time.Minute)
+	c.Set(ctx, &req2, "rsp", time.Minute)
+
+	if err := PublishInvalidation(b, "", "foo", "Foo.Bar", map[string]string{"id": "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Get(ctx, &req1); ok {
+		t.Fatal("Expected the response to be invalidated")
+	}
+	if _, ok := c.Get(ctx, &req2); !ok {
+		t.Fatal("Expected the response to another request to be cached")
+	}
+}
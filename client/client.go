@@ -109,6 +109,10 @@ var (
 	DefaultPoolSize = 100
 	// DefaultPoolTTL sets the connection pool ttl
 	DefaultPoolTTL = time.Minute
+	// DefaultPoolCheckInterval is the interval the idle connections of the
+	// pool are checked at, so the ones expired are closed without waiting
+	// for a call to the node
+	DefaultPoolCheckInterval = 30 * time.Second
 
 	// NewClient returns a new client
 	NewClient func(...Option) Client = newRpcClient
@@ -125,6 +129,11 @@ func Publish(ctx context.Context, msg Message, opts ...PublishOption) error {
 	return DefaultClient.Publish(ctx, msg, opts...)
 }
 
+// Publishes a batch of publications using the default client, see PublishAll
+func PublishBatch(ctx context.Context, msgs []Message, opts ...PublishOption) error {
+	return PublishAll(ctx, DefaultClient, msgs, opts...)
+}
+
 // Creates a new message using the default client
 func NewMessage(topic string, payload interface{}, opts ...MessageOption) Message {
 	return DefaultClient.NewMessage(topic, payload, opts...)
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+// FallbackFunc returns the response of a call which failed with the error
+// once out of retries, or rejected by the circuit breaker, e.g. a cached or
+// default response. Returning an error fails the call with it instead.
+type FallbackFunc func(ctx context.Context, req Request, err error) (interface{}, error)
+
+// CallFallback makes the call with fn and, if it fails, calls the fallback of
+// the call options with its error, setting the response to the one of the
+// fallback. The response of the fallback is either a pointer to a value of the
+// type of the response or a value of the type.
+//
+// Only failures, as classified by IsFailure, and calls rejected by the circuit
+// breaker are answered by the fallback, not the errors of the caller nor the
+// calls it cancelled. With a fallback the call decodes into a response of its
+// own, copied into rsp on success, so an attempt still decoding once the call
+// timed out doesn't race with the response of the fallback.
+func CallFallback(ctx context.Context, req Request, rsp interface{}, opts CallOptions, fn func(rsp interface{}) error) error {
+	if opts.Fallback == nil {
+		return fn(rsp)
+	}
+
+	r := rsp
+	if hedgeable(rsp) {
+		r = reflect.New(reflect.TypeOf(rsp).Elem()).Interface()
+	}
+
+	err := fn(r)
+	if err == nil {
+		if r != rsp {
+			reflect.ValueOf(rsp).Elem().Set(reflect.ValueOf(r).Elem())
+		}
+		return nil
+	}
+
+	// the caller gave up on the call, or it failed with an error of the caller
+	if ctx.Err() != nil || !IsFailure(err) {
+		return err
+	}
+
+	frsp, ferr := opts.Fallback(ctx, req, err)
+	if ferr != nil {
+		return ferr
+	}
+
+	if err := setResponse(rsp, frsp); err != nil {
+		return err
+	}
+
+	ReportRetry(ctx, opts, NewRetryEvent(req, RetryFallback, opts.Retries, err.Error()))
+	return nil
+}
+
+// setResponse sets the response of the call to the one of the fallback
+func setResponse(rsp, frsp interface{}) error {
+	if rsp == nil || frsp == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(rsp)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+
+	f := reflect.ValueOf(frsp)
+	if f.Kind() == reflect.Ptr && f.Type() == v.Type() {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+	if f.Type() != v.Elem().Type() {
+		return errors.InternalServerError("go.micro.client", "fallback response of type %T can't be set to %T", frsp, rsp)
+	}
+
+	v.Elem().Set(f)
+	return nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router/static"
+)
+
+type fallbackResponse struct {
+	Value string
+}
+
+func TestCallFallback(t *testing.T) {
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+	failed := errors.InternalServerError("foo", "failed")
+	fail := func(err error) func(interface{}) error {
+		return func(interface{}) error {
+			return err
+		}
+	}
+
+	// the fallback response can be a pointer or a value
+	for _, v := range []interface{}{&fallbackResponse{"default"}, fallbackResponse{"default"}} {
+		opts := CallOptions{
+			Fallback: func(ctx context.Context, req Request, err error) (interface{}, error) {
+				return v, nil
+			},
+		}
+
+		rsp := new(fallbackResponse)
+		if err := CallFallback(context.TODO(), req, rsp, opts, fail(failed)); err != nil {
+			t.Fatal(err)
+		}
+		if rsp.Value != "default" {
+			t.Fatalf("Expected the fallback response, got %q", rsp.Value)
+		}
+	}
+
+	// the fallback can fail the call with its own error
+	opts := CallOptions{
+		Fallback: func(ctx context.Context, req Request, err error) (interface{}, error) {
+			return nil, err
+		},
+	}
+	if err := CallFallback(context.TODO(), req, new(fallbackResponse), opts, fail(failed)); err != failed {
+		t.Fatalf("Expected the error of the call, got %v", err)
+	}
+
+	// a response of another type is an error
+	opts.Fallback = func(ctx context.Context, req Request, err error) (interface{}, error) {
+		return "default", nil
+	}
+	if err := CallFallback(context.TODO(), req, new(fallbackResponse), opts, fail(failed)); err == nil {
+		t.Fatal("Expected an error setting a response of another type")
+	}
+
+	// without a fallback the error is returned
+	if err := CallFallback(context.TODO(), req, new(fallbackResponse), CallOptions{}, fail(failed)); err != failed {
+		t.Fatalf("Expected the error of the call, got %v", err)
+	}
+
+	// errors of the caller and cancelled calls aren't answered by the fallback
+	opts.Fallback = func(ctx context.Context, req Request, err error) (interface{}, error) {
+		return &fallbackResponse{"default"}, nil
+	}
+	bad := errors.BadRequest("foo", "bad request")
+	if err := CallFallback(context.TODO(), req, new(fallbackResponse), opts, fail(bad)); err != bad {
+		t.Fatalf("Expected the error of the caller, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := CallFallback(ctx, req, new(fallbackResponse), opts, fail(failed)); err != failed {
+		t.Fatalf("Expected the error of the cancelled call, got %v", err)
+	}
+
+	// the call decodes into a response of its own, copied on success
+	rsp := new(fallbackResponse)
+	err := CallFallback(context.TODO(), req, rsp, opts, func(r interface{}) error {
+		if r == rsp {
+			t.Fatal("Expected the call to decode into a response of its own")
+		}
+		r.(*fallbackResponse).Value = "answered"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Value != "answered" {
+		t.Fatalf("Expected the response of the call, got %q", rsp.Value)
+	}
+}
+
+func TestFallbackCall(t *testing.T) {
+	var attempts int
+	c := NewClient(
+		Router(static.NewRouter()),
+		Retries(2),
+		Retry(RetryAlways),
+		Backoff(ExponentialBackoff(0, 0, 0)),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				attempts++
+				return errors.InternalServerError("test", "failed")
+			}
+		}),
+	)
+
+	var fallbackErr error
+	req := c.NewRequest("test", "Test.Call", nil)
+	rsp := new(fallbackResponse)
+	err := c.Call(context.TODO(), req, rsp, Fallback(func(ctx context.Context, req Request, err error) (interface{}, error) {
+		fallbackErr = err
+		return &fallbackResponse{"cached"}, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Value != "cached" {
+		t.Fatalf("Expected the fallback response, got %q", rsp.Value)
+	}
+	// the fallback is called once out of retries
+	if attempts != 3 || fallbackErr == nil {
+		t.Fatalf("Expected the fallback after 3 attempts, got %d attempts and error %v", attempts, fallbackErr)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -17,10 +19,15 @@ import (
 	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/selector"
+	"github.com/micro/go-micro/v2/util/compress"
+	mnet "github.com/micro/go-micro/v2/util/net"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 	gmetadata "google.golang.org/grpc/metadata"
 )
 
@@ -28,6 +35,10 @@ type grpcClient struct {
 	opts client.Options
 	pool *pool
 	once atomic.Value
+
+	// pools of the services with their own pool size
+	sync.Mutex
+	pools map[string]*pool
 }
 
 func init() {
@@ -114,13 +125,21 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 		grpcDialOptions = append(grpcDialOptions, opts...)
 	}
 
-	cc, err := g.pool.getConn(node.Address, grpcDialOptions...)
+	// dial unix domain sockets directly
+	if network, _ := mnet.Network(node.Address); network == "unix" {
+		grpcDialOptions = append(grpcDialOptions, grpc.WithContextDialer(mnet.Dial))
+	}
+
+	p := g.poolFor(req.Service())
+	cc, err := p.getConn(node.Address, grpcDialOptions...)
 	if err != nil {
 		return errors.InternalServerError("go.micro.client", fmt.Sprintf("Error sending request: %v", err))
 	}
+	release := g.opts.Metrics.Conn(req.Service(), node.Address)
 	defer func() {
 		// defer execution of release
-		g.pool.release(node.Address, cc, grr)
+		p.release(node.Address, cc, grr)
+		release()
 	}()
 
 	ch := make(chan error, 1)
@@ -133,6 +152,9 @@ func (g *grpcClient) call(ctx context.Context, node *registry.Node, req client.R
 		if opts := getGrpcCallOptions(opts); opts != nil {
 			grpcCallOptions = append(grpcCallOptions, opts...)
 		}
+		if c, ok := compressor(node, opts); ok {
+			grpcCallOptions = append(grpcCallOptions, c)
+		}
 		if opts := g.getGrpcCallOptions(); opts != nil {
 			grpcCallOptions = append(grpcCallOptions, opts...)
 		}
@@ -198,6 +220,11 @@ func (g *grpcClient) stream(ctx context.Context, node *registry.Node, req client
 		grpcDialOptions = append(grpcDialOptions, opts...)
 	}
 
+	// dial unix domain sockets directly
+	if network, _ := mnet.Network(node.Address); network == "unix" {
+		grpcDialOptions = append(grpcDialOptions, grpc.WithContextDialer(mnet.Dial))
+	}
+
 	cc, err := grpc.DialContext(dialCtx, node.Address, grpcDialOptions...)
 	if err != nil {
 		return errors.InternalServerError("go.micro.client", fmt.Sprintf("Error sending request: %v", err))
@@ -216,6 +243,9 @@ func (g *grpcClient) stream(ctx context.Context, node *registry.Node, req client
 	if opts := g.getGrpcCallOptions(); opts != nil {
 		grpcCallOptions = append(grpcCallOptions, opts...)
 	}
+	if c, ok := compressor(node, opts); ok {
+		grpcCallOptions = append(grpcCallOptions, c)
+	}
 
 	// create a new cancelling context
 	newCtx, cancel := context.WithCancel(ctx)
@@ -322,22 +352,61 @@ func (g *grpcClient) newGRPCCodec(contentType string) (encoding.Codec, error) {
 	return nil, fmt.Errorf("Unsupported Content-Type: %s", contentType)
 }
 
-func (g *grpcClient) Init(opts ...client.Option) error {
-	size := g.opts.PoolSize
-	ttl := g.opts.PoolTTL
+// poolFor returns the connection pool of the service, which is the pool of
+// the client unless the pool size of the service is set
+func (g *grpcClient) poolFor(service string) *pool {
+	o, _ := g.opts.Services.Get(service)
+	if o.PoolSize <= 0 {
+		return g.pool
+	}
+
+	g.Lock()
+	defer g.Unlock()
 
+	p, ok := g.pools[service]
+	if !ok {
+		p = newPool(o.PoolSize, g.poolTTL(), g.poolMaxIdle(), g.poolMaxStreams())
+		g.pools[service] = p
+		return p
+	}
+
+	p.Lock()
+	p.size = o.PoolSize
+	p.Unlock()
+
+	return p
+}
+
+func (g *grpcClient) Init(opts ...client.Option) error {
 	for _, o := range opts {
 		o(&g.opts)
 	}
 
-	// update pool configuration if the options changed
-	if size != g.opts.PoolSize || ttl != g.opts.PoolTTL {
-		g.pool.Lock()
-		g.pool.size = g.opts.PoolSize
-		g.pool.ttl = int64(g.opts.PoolTTL.Seconds())
-		g.pool.Unlock()
+	// update the pool configuration in case the options changed
+	ttl := int64(g.poolTTL().Seconds())
+	idle := g.poolMaxIdle()
+	streams := g.poolMaxStreams()
+	if streams <= 0 {
+		streams = 1
 	}
 
+	g.pool.Lock()
+	g.pool.size = g.opts.PoolSize
+	g.pool.ttl = ttl
+	g.pool.maxIdle = idle
+	g.pool.maxStreams = streams
+	g.pool.Unlock()
+
+	g.Lock()
+	for _, p := range g.pools {
+		p.Lock()
+		p.ttl = ttl
+		p.maxIdle = idle
+		p.maxStreams = streams
+		p.Unlock()
+	}
+	g.Unlock()
+
 	return nil
 }
 
@@ -350,20 +419,33 @@ func (g *grpcClient) NewMessage(topic string, msg interface{}, opts ...client.Me
 }
 
 func (g *grpcClient) NewRequest(service, method string, req interface{}, reqOpts ...client.RequestOption) client.Request {
-	return newGRPCRequest(service, method, req, g.opts.ContentType, reqOpts...)
+	contentType := g.opts.ContentType
+	if o, ok := g.opts.Services.Get(service); ok && len(o.ContentType) > 0 {
+		contentType = o.ContentType
+	}
+	return newGRPCRequest(service, method, req, contentType, reqOpts...)
 }
 
 func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	// answer the failed call with its fallback, if any
+	callOpts := g.opts.Services.CallOptions(req.Service(), g.opts.CallOptions, opts...)
+	return client.CallFallback(ctx, req, rsp, callOpts, func(rsp interface{}) error {
+		done := g.opts.Metrics.Start(req)
+		err := client.ChainUnary(g.invoke, g.opts.UnaryInterceptors...)(ctx, req, rsp, opts...)
+		done(err)
+		return err
+	})
+}
+
+// invoke makes the call at the end of the interceptors
+func (g *grpcClient) invoke(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
 	if req == nil {
 		return errors.InternalServerError("go.micro.client", "req is nil")
 	} else if rsp == nil {
 		return errors.InternalServerError("go.micro.client", "rsp is nil")
 	}
 	// make a copy of call opts
-	callOpts := g.opts.CallOptions
-	for _, opt := range opts {
-		opt(&callOpts)
-	}
+	callOpts := g.opts.Services.CallOptions(req.Service(), g.opts.CallOptions, opts...)
 
 	// check if we already have a deadline
 	d, ok := ctx.Deadline()
@@ -386,6 +468,14 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 	default:
 	}
 
+	// fail fast over the rate limit of the service
+	if err := g.opts.RateLimits.Allow(req); err != nil {
+		return err
+	}
+
+	// mirror the call to the services testing with it
+	client.MirrorCall(ctx, g, req, rsp, callOpts, g.opts.Mirrors)
+
 	// make copy of call method
 	gcall := g.call
 
@@ -394,6 +484,9 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 		gcall = callOpts.CallWrappers[i-1](gcall)
 	}
 
+	// track the routes attempted so retries go elsewhere
+	ctx = selector.NewAttemptsContext(ctx)
+
 	// return errors.New("go.micro.client", "request timeout", 408)
 	call := func(i int) error {
 		// call backoff first. Someone may want an initial start delay
@@ -404,6 +497,9 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 
 		// only sleep if greater than 0
 		if t.Seconds() > 0 {
+			e := client.NewRetryEvent(req, client.RetryBackoff, i, "")
+			e.Backoff = t
+			client.ReportRetry(ctx, callOpts, e)
 			time.Sleep(t)
 		}
 
@@ -416,22 +512,43 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 			callOpts.Selector = g.opts.Selector
 		}
 
-		// lookup the route to send the reques to
-		route, err := client.LookupRoute(req, callOpts)
+		// check the circuit breaker before making the attempt
+		done, err := client.AllowCall(ctx, req, callOpts, i)
 		if err != nil {
 			return err
 		}
 
-		// pass a node to enable backwards compatability as changing the
-		// call func would be a breaking change.
-		// todo v3: change the call func to accept a route
-		node := &registry.Node{Address: route.Address}
+		// make the call, hedging it if the call options ask for it
+		err = client.Hedge(ctx, req, callOpts, i, rsp, func(ctx context.Context, rsp interface{}) error {
+			// lookup the route to send the reques to
+			route, err := client.LookupRouteContext(ctx, req, callOpts)
+			if err != nil {
+				return err
+			}
 
-		// make the call
-		err = gcall(ctx, node, req, rsp, callOpts)
+			// pass a node to enable backwards compatability as changing the
+			// call func would be a breaking change.
+			// todo v3: change the call func to accept a route
+			node := &registry.Node{Address: route.Address, Metadata: route.Metadata}
 
-		// record the result of the call to inform future routing decisions
-		g.opts.Selector.Record(*route, err)
+			// make the call
+			start := time.Now()
+			err = gcall(ctx, node, req, rsp, callOpts)
+
+			// a hedge cancelled once another answered says nothing of the route
+			if err != nil && ctx.Err() == context.Canceled {
+				return err
+			}
+
+			// record the result of the call to inform future routing decisions
+			latency := time.Since(start)
+			g.opts.Selector.Record(*route, err)
+			selector.Observe(g.opts.Selector, *route, latency, err)
+			callOpts.Router.Feedback(*route, latency, err)
+
+			return err
+		})
+		done(err)
 
 		// try and transform the error to a go-micro error
 		return err
@@ -440,6 +557,9 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 	ch := make(chan error, callOpts.Retries+1)
 	var gerr error
 
+	// count the request against the retry budget
+	callOpts.RetryBudget.Deposit()
+
 	for i := 0; i <= callOpts.Retries; i++ {
 		go func(i int) {
 			ch <- call(i)
@@ -456,11 +576,22 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 
 			retry, rerr := callOpts.Retry(ctx, req, i, err)
 			if rerr != nil {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, rerr.Error()))
 				return rerr
 			}
 
 			if !retry {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, err.Error()))
+				return err
+			}
+
+			if i == callOpts.Retries {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, "retries exhausted: "+err.Error()))
+			} else if !callOpts.RetryBudget.Withdraw() {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, "retry budget exhausted: "+err.Error()))
 				return err
+			} else {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryAttempt, i, err.Error()))
 			}
 
 			gerr = err
@@ -471,12 +602,34 @@ func (g *grpcClient) Call(ctx context.Context, req client.Request, rsp interface
 }
 
 func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
-	// make a copy of call opts
-	callOpts := g.opts.CallOptions
-	for _, opt := range opts {
-		opt(&callOpts)
+	done := g.opts.Metrics.Start(req)
+	streamer := client.ChainStream(g.newStream, g.opts.StreamInterceptors...)
+	stream, err := streamer(ctx, req, opts...)
+	if err != nil {
+		done(err)
+		return nil, err
 	}
 
+	// reopen the stream through the interceptors if it breaks
+	callOpts := g.opts.Services.CallOptions(req.Service(), g.opts.CallOptions, opts...)
+	if callOpts.Resume != nil {
+		stream = client.ResumeStream(ctx, req, &transportStream{stream}, callOpts, func(ctx context.Context) (client.Stream, error) {
+			s, err := streamer(ctx, req, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return &transportStream{s}, nil
+		})
+	}
+
+	return client.StreamDone(stream, done), nil
+}
+
+// newStream opens the stream at the end of the interceptors
+func (g *grpcClient) newStream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	// make a copy of call opts
+	callOpts := g.opts.Services.CallOptions(req.Service(), g.opts.CallOptions, opts...)
+
 	// #200 - streams shouldn't have a request timeout set on the context
 
 	// should we noop right here?
@@ -486,6 +639,11 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 	default:
 	}
 
+	// fail fast over the rate limit of the service
+	if err := g.opts.RateLimits.Allow(req); err != nil {
+		return nil, err
+	}
+
 	// make a copy of stream
 	gstream := g.stream
 
@@ -494,6 +652,9 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 		gstream = callOpts.CallWrappers[i-1](gstream)
 	}
 
+	// track the routes attempted so retries go elsewhere
+	ctx = selector.NewAttemptsContext(ctx)
+
 	call := func(i int) (client.Stream, error) {
 		// call backoff first. Someone may want an initial start delay
 		t, err := callOpts.Backoff(ctx, req, i)
@@ -503,6 +664,9 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 
 		// only sleep if greater than 0
 		if t.Seconds() > 0 {
+			e := client.NewRetryEvent(req, client.RetryBackoff, i, "")
+			e.Backoff = t
+			client.ReportRetry(ctx, callOpts, e)
 			time.Sleep(t)
 		}
 
@@ -515,23 +679,35 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 			callOpts.Selector = g.opts.Selector
 		}
 
+		// check the circuit breaker before making the attempt
+		done, err := client.AllowCall(ctx, req, callOpts, i)
+		if err != nil {
+			return nil, err
+		}
+
 		// lookup the route to send the reques to
-		route, err := client.LookupRoute(req, callOpts)
+		route, err := client.LookupRouteContext(ctx, req, callOpts)
 		if err != nil {
+			done(err)
 			return nil, err
 		}
 
 		// pass a node to enable backwards compatability as changing the
 		// call func would be a breaking change.
 		// todo v3: change the call func to accept a route
-		node := &registry.Node{Address: route.Address}
+		node := &registry.Node{Address: route.Address, Metadata: route.Metadata}
 
 		// make the call
 		stream := &grpcStream{}
+		start := time.Now()
 		err = g.stream(ctx, node, req, stream, callOpts)
 
 		// record the result of the call to inform future routing decisions
+		latency := time.Since(start)
 		g.opts.Selector.Record(*route, err)
+		selector.Observe(g.opts.Selector, *route, latency, err)
+		callOpts.Router.Feedback(*route, latency, err)
+		done(err)
 
 		// try and transform the error to a go-micro error
 		if verr, ok := err.(*errors.Error); ok {
@@ -550,6 +726,9 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 	ch := make(chan response, callOpts.Retries+1)
 	var grr error
 
+	// count the request against the retry budget
+	callOpts.RetryBudget.Deposit()
+
 	for i := 0; i <= callOpts.Retries; i++ {
 		go func(i int) {
 			s, err := call(i)
@@ -567,13 +746,24 @@ func (g *grpcClient) Stream(ctx context.Context, req client.Request, opts ...cli
 
 			retry, rerr := callOpts.Retry(ctx, req, i, grr)
 			if rerr != nil {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, rerr.Error()))
 				return nil, rerr
 			}
 
 			if !retry {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, rsp.err.Error()))
 				return nil, rsp.err
 			}
 
+			if i == callOpts.Retries {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, "retries exhausted: "+rsp.err.Error()))
+			} else if !callOpts.RetryBudget.Withdraw() {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryGiveUp, i, "retry budget exhausted: "+rsp.err.Error()))
+				return nil, rsp.err
+			} else {
+				client.ReportRetry(ctx, callOpts, client.NewRetryEvent(req, client.RetryAttempt, i, rsp.err.Error()))
+			}
+
 			grr = rsp.err
 		}
 	}
@@ -585,6 +775,15 @@ func (g *grpcClient) Publish(ctx context.Context, p client.Message, opts ...clie
 	var options client.PublishOptions
 	var body []byte
 
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// queue the message to be published in the background
+	if options.Async {
+		return g.opts.PublishQueue.Enqueue(ctx, p, g.Publish, opts...)
+	}
+
 	// fail early on connect error
 	if !g.once.Load().(bool) {
 		if err := g.opts.Broker.Connect(); err != nil {
@@ -593,16 +792,16 @@ func (g *grpcClient) Publish(ctx context.Context, p client.Message, opts ...clie
 		g.once.Store(true)
 	}
 
-	for _, o := range opts {
-		o(&options)
-	}
-
 	md, ok := metadata.FromContext(ctx)
 	if !ok {
 		md = make(map[string]string)
 	}
 	md["Content-Type"] = p.ContentType()
 	md["Micro-Topic"] = p.Topic()
+	// the time of publishing, for subscribers to report their lag
+	if g.opts.Metrics != nil {
+		md["Micro-Timestamp"] = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
 
 	// passed in raw data
 	if d, ok := p.Payload().(*raw.Frame); ok {
@@ -628,7 +827,7 @@ func (g *grpcClient) Publish(ctx context.Context, p client.Message, opts ...clie
 		topic = options.Exchange
 	}
 
-	return g.opts.Broker.Publish(topic, &broker.Message{
+	return client.PublishBroker(options, g.opts.Broker).Publish(topic, &broker.Message{
 		Header: md,
 		Body:   body,
 	}, broker.PublishContext(options.Context))
@@ -639,23 +838,85 @@ func (g *grpcClient) String() string {
 }
 
 func (g *grpcClient) getGrpcDialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+
+	if ka, ok := g.keepaliveParams(); ok {
+		opts = append(opts, grpc.WithKeepaliveParams(ka))
+	}
+
+	if g.opts.Context != nil {
+		if n, ok := g.opts.Context.Value(windowSizeKey{}).(int32); ok {
+			opts = append(opts, grpc.WithInitialWindowSize(n))
+		}
+		if n, ok := g.opts.Context.Value(connWindowSizeKey{}).(int32); ok {
+			opts = append(opts, grpc.WithInitialConnWindowSize(n))
+		}
+	}
+
 	if g.opts.CallOptions.Context == nil {
-		return nil
+		return opts
 	}
 
 	v := g.opts.CallOptions.Context.Value(grpcDialOptions{})
 
 	if v == nil {
-		return nil
+		return opts
 	}
 
-	opts, ok := v.([]grpc.DialOption)
+	dopts, ok := v.([]grpc.DialOption)
 
 	if !ok {
-		return nil
+		return opts
 	}
 
-	return opts
+	return append(opts, dopts...)
+}
+
+// compressor returns the call option compressing the messages with the
+// encoding of the call options, if the node accepts it
+func compressor(node *registry.Node, opts client.CallOptions) (grpc.CallOption, bool) {
+	if len(opts.Compression) == 0 || encoding.GetCompressor(opts.Compression) == nil {
+		return nil, false
+	}
+	if !compress.Accepts(node.Metadata[compress.MetadataKey], opts.Compression) {
+		return nil, false
+	}
+	return grpc.UseCompressor(opts.Compression), true
+}
+
+// keepaliveParams returns the keepalive parameters, if the keepalive time is set
+func (g *grpcClient) keepaliveParams() (keepalive.ClientParameters, bool) {
+	if g.opts.Context == nil {
+		return keepalive.ClientParameters{}, false
+	}
+
+	t, ok := g.opts.Context.Value(keepaliveTimeKey{}).(time.Duration)
+	if !ok || t <= 0 {
+		return keepalive.ClientParameters{}, false
+	}
+
+	ka := keepalive.ClientParameters{
+		Time:    t,
+		Timeout: DefaultKeepaliveTimeout,
+	}
+	if d, ok := g.opts.Context.Value(keepaliveTimeoutKey{}).(time.Duration); ok && d > 0 {
+		ka.Timeout = d
+	}
+	if b, ok := g.opts.Context.Value(keepalivePermitKey{}).(bool); ok {
+		ka.PermitWithoutStream = b
+	}
+
+	return ka, true
+}
+
+// poolTTL returns the max age of the pooled connections
+func (g *grpcClient) poolTTL() time.Duration {
+	if g.opts.Context != nil {
+		if d, ok := g.opts.Context.Value(maxConnAgeKey{}).(time.Duration); ok && d > 0 {
+			return d
+		}
+	}
+	return g.opts.PoolTTL
 }
 
 func (g *grpcClient) getGrpcCallOptions() []grpc.CallOption {
@@ -688,11 +949,12 @@ func newClient(opts ...client.Option) client.Client {
 	}
 
 	rc := &grpcClient{
-		opts: options,
+		opts:  options,
+		pools: make(map[string]*pool),
 	}
 	rc.once.Store(false)
 
-	rc.pool = newPool(options.PoolSize, options.PoolTTL, rc.poolMaxIdle(), rc.poolMaxStreams())
+	rc.pool = newPool(options.PoolSize, rc.poolTTL(), rc.poolMaxIdle(), rc.poolMaxStreams())
 
 	c := client.Client(rc)
 
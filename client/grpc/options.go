@@ -4,6 +4,7 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
+	"time"
 
 	"github.com/micro/go-micro/v2/client"
 	"google.golang.org/grpc"
@@ -26,6 +27,10 @@ var (
 	// DefaultMaxSendMsgSize maximum message that client can send
 	// (4 MB).
 	DefaultMaxSendMsgSize = 1024 * 1024 * 4
+
+	// DefaultKeepaliveTimeout is how long to wait for a keepalive ping ack
+	// (20s).
+	DefaultKeepaliveTimeout = 20 * time.Second
 )
 
 type poolMaxStreams struct{}
@@ -36,6 +41,12 @@ type maxRecvMsgSizeKey struct{}
 type maxSendMsgSizeKey struct{}
 type grpcDialOptions struct{}
 type grpcCallOptions struct{}
+type keepaliveTimeKey struct{}
+type keepaliveTimeoutKey struct{}
+type keepalivePermitKey struct{}
+type maxConnAgeKey struct{}
+type windowSizeKey struct{}
+type connWindowSizeKey struct{}
 
 // maximum streams on a connectioin
 func PoolMaxStreams(n int) client.Option {
@@ -57,6 +68,66 @@ func PoolMaxIdle(d int) client.Option {
 	}
 }
 
+//
+// KeepaliveTime sets how long a connection is idle before the client pings
+// the server to check it's alive. Connections through L4 load balancers are
+// dropped silently when idle, so set it below their idle timeout.
+//
+func KeepaliveTime(d time.Duration) client.Option {
+	return setOption(keepaliveTimeKey{}, d)
+}
+
+//
+// KeepaliveTimeout sets how long the client waits for the ack of a keepalive
+// ping before closing the connection. Default: 20s
+//
+func KeepaliveTimeout(d time.Duration) client.Option {
+	return setOption(keepaliveTimeoutKey{}, d)
+}
+
+//
+// KeepalivePermitWithoutStream pings the connections without active streams
+// e.g. those idle in the pool. The server must permit it, or it closes the
+// connections pinged without streams.
+//
+func KeepalivePermitWithoutStream(b bool) client.Option {
+	return setOption(keepalivePermitKey{}, b)
+}
+
+//
+// MaxConnectionAge sets the max age of the pooled connections, older ones are
+// closed once they've no streams. It overrides the pool ttl of the client.
+//
+func MaxConnectionAge(d time.Duration) client.Option {
+	return setOption(maxConnAgeKey{}, d)
+}
+
+//
+// InitialWindowSize sets the receive window of each stream, how much the
+// server can send before the client reads. Long lived streams of large
+// messages need a larger window. Below 64KB it's ignored.
+//
+func InitialWindowSize(n int32) client.Option {
+	return setOption(windowSizeKey{}, n)
+}
+
+//
+// InitialConnWindowSize sets the receive window of each connection, shared
+// by its streams. Below 64KB it's ignored.
+//
+func InitialConnWindowSize(n int32) client.Option {
+	return setOption(connWindowSizeKey{}, n)
+}
+
+func setOption(k, v interface{}) client.Option {
+	return func(o *client.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
 // gRPC Codec to be used to encode/decode requests for a given content type
 func Codec(contentType string, c encoding.Codec) client.Option {
 	return func(o *client.Options) {
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeepaliveOptions(t *testing.T) {
+	g := newClient().(*grpcClient)
+
+	if _, ok := g.keepaliveParams(); ok {
+		t.Fatal("Expected no keepalive by default")
+	}
+
+	g.Init(
+		KeepaliveTime(30*time.Second),
+		KeepalivePermitWithoutStream(true),
+	)
+
+	ka, ok := g.keepaliveParams()
+	if !ok {
+		t.Fatal("Expected keepalive to be set")
+	}
+	if ka.Time != 30*time.Second || ka.Timeout != DefaultKeepaliveTimeout || !ka.PermitWithoutStream {
+		t.Fatalf("Unexpected keepalive parameters %+v", ka)
+	}
+	if len(g.getGrpcDialOptions()) != 1 {
+		t.Fatal("Expected the keepalive dial option")
+	}
+}
+
+func TestConnectionOptions(t *testing.T) {
+	g := newClient().(*grpcClient)
+
+	g.Init(
+		PoolMaxIdle(5),
+		PoolMaxStreams(10),
+		MaxConnectionAge(time.Hour),
+	)
+
+	g.pool.Lock()
+	defer g.pool.Unlock()
+
+	if g.pool.maxIdle != 5 || g.pool.maxStreams != 10 {
+		t.Fatalf("Expected the pool limits to be updated, got %d idle and %d streams", g.pool.maxIdle, g.pool.maxStreams)
+	}
+	if g.pool.ttl != int64(time.Hour.Seconds()) {
+		t.Fatalf("Expected the max connection age to be the pool ttl, got %ds", g.pool.ttl)
+	}
+}
+
+func TestWindowOptions(t *testing.T) {
+	g := newClient().(*grpcClient)
+
+	if len(g.getGrpcDialOptions()) != 0 {
+		t.Fatal("Expected no dial options by default")
+	}
+
+	g.Init(
+		InitialWindowSize(1<<20),
+		InitialConnWindowSize(1<<22),
+	)
+
+	if len(g.getGrpcDialOptions()) != 2 {
+		t.Fatal("Expected the window dial options")
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"sync"
 
 	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Implements the streamer interface
@@ -86,3 +89,24 @@ func (g *grpcStream) Close() error {
 	g.stream.CloseSend()
 	return g.conn.Close()
 }
+
+// transportStream returns the errors of a broken connection, which grpc
+// reports as unavailable, as errors of the transport so the stream is resumed
+type transportStream struct {
+	client.Stream
+}
+
+func (t *transportStream) Send(msg interface{}) error {
+	return transportError(t.Stream.Send(msg))
+}
+
+func (t *transportStream) Recv(msg interface{}) error {
+	return transportError(t.Stream.Recv(msg))
+}
+
+func transportError(err error) error {
+	if status.Code(err) == codes.Unavailable {
+		return errors.InternalServerError(client.TransportErrorId, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Hedge makes the call with fn and, if it hasn't answered within the hedge
+// delay of the call options, sends up to the max hedges of duplicate calls,
+// each after another delay. The first success is used and the other calls
+// are cancelled. Retries exclude the routes attempted so every hedge goes
+// to another node. Only hedge idempotent requests.
+//
+// Every call decodes into a response of its own. The response of the call
+// which won is copied into rsp once the others were cancelled and returned,
+// so a nil response or one which isn't a pointer isn't hedged.
+func Hedge(ctx context.Context, req Request, opts CallOptions, attempt int, rsp interface{}, fn func(ctx context.Context, rsp interface{}) error) error {
+	if opts.HedgeDelay <= 0 || opts.MaxHedges <= 0 || !hedgeable(rsp) {
+		return fn(ctx, rsp)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		rsp interface{}
+		err error
+	}
+
+	ch := make(chan result, opts.MaxHedges+1)
+	send := func() {
+		r := reflect.New(reflect.TypeOf(rsp).Elem()).Interface()
+		go func() {
+			ch <- result{r, fn(ctx, r)}
+		}()
+	}
+
+	send()
+	sent, received := 1, 0
+
+	timer := time.NewTimer(opts.HedgeDelay)
+	defer timer.Stop()
+
+	var winner interface{}
+	var err error
+
+	for received < sent {
+		select {
+		case <-timer.C:
+			// no more hedges once a call won
+			if winner != nil {
+				continue
+			}
+			ReportRetry(ctx, opts, NewRetryEvent(req, RetryHedge, attempt, ""))
+			send()
+			sent++
+			if sent <= opts.MaxHedges {
+				timer.Reset(opts.HedgeDelay)
+			}
+		case res := <-ch:
+			received++
+			if res.err == nil && winner == nil {
+				winner = res.rsp
+				// the others are cancelled, and waited for so none still
+				// decodes when the response is copied
+				cancel()
+				timer.Stop()
+				continue
+			}
+			if winner == nil {
+				err = res.err
+			}
+		}
+	}
+
+	if winner == nil {
+		return err
+	}
+
+	reflect.ValueOf(rsp).Elem().Set(reflect.ValueOf(winner).Elem())
+	return nil
+}
+
+// hedgeable returns true if a response of the same type can be made
+func hedgeable(rsp interface{}) bool {
+	v := reflect.ValueOf(rsp)
+	return v.Kind() == reflect.Ptr && !v.IsNil()
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type hedgeResponse struct {
+	Value string
+}
+
+func TestHedge(t *testing.T) {
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	var hedges int
+	opts := CallOptions{
+		HedgeDelay: 10 * time.Millisecond,
+		MaxHedges:  2,
+		RetryHooks: []RetryHook{func(ctx context.Context, e RetryEvent) {
+			if e.Type == RetryHedge {
+				hedges++
+			}
+		}},
+	}
+
+	// the first call is slow so the hedge answers
+	var calls int32
+	rsp := new(hedgeResponse)
+	err := Hedge(context.TODO(), req, opts, 0, rsp, func(ctx context.Context, r interface{}) error {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+		r.(*hedgeResponse).Value = fmt.Sprintf("call %d", n)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Value != "call 2" {
+		t.Fatalf("Expected the response of the hedge, got %q", rsp.Value)
+	}
+	if hedges != 1 {
+		t.Fatalf("Expected 1 hedge, got %d", hedges)
+	}
+
+	// every call fails, the max hedges are sent and the error returned
+	atomic.StoreInt32(&calls, 0)
+	hedges = 0
+	err = Hedge(context.TODO(), req, opts, 0, rsp, func(ctx context.Context, r interface{}) error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return fmt.Errorf("failed")
+	})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("Expected 3 calls, got %d", n)
+	}
+
+	// a fast call isn't hedged
+	atomic.StoreInt32(&calls, 0)
+	hedges = 0
+	err = Hedge(context.TODO(), req, opts, 0, rsp, func(ctx context.Context, r interface{}) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 || hedges != 0 {
+		t.Fatalf("Expected 1 call and no hedges, got %d calls and %d hedges", n, hedges)
+	}
+
+	// a nil response isn't hedged
+	atomic.StoreInt32(&calls, 0)
+	err = Hedge(context.TODO(), req, opts, 0, nil, func(ctx context.Context, r interface{}) error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("Expected 1 call, got %d", n)
+	}
+
+	// the call which lost and ignores the cancellation doesn't overwrite the
+	// response of the hedge which won
+	atomic.StoreInt32(&calls, 0)
+	rsp = new(hedgeResponse)
+	err = Hedge(context.TODO(), req, opts, 0, rsp, func(ctx context.Context, r interface{}) error {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		r.(*hedgeResponse).Value = fmt.Sprintf("call %d", n)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Value != "call 2" {
+		t.Fatalf("Expected the response of the hedge, got %q", rsp.Value)
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+)
+
+// UnaryInvoker makes a call, it's the rest of the chain for an interceptor
+type UnaryInvoker func(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error
+
+// UnaryInterceptor intercepts the calls of a client. It calls the invoker to
+// continue the call, or returns without to short circuit it e.g. with a
+// cached response.
+type UnaryInterceptor func(ctx context.Context, req Request, rsp interface{}, invoker UnaryInvoker, opts ...CallOption) error
+
+// Streamer opens a stream, it's the rest of the chain for an interceptor
+type Streamer func(ctx context.Context, req Request, opts ...CallOption) (Stream, error)
+
+// StreamInterceptor intercepts the streams opened by a client. It calls the
+// streamer to open the stream, which it may wrap.
+type StreamInterceptor func(ctx context.Context, req Request, streamer Streamer, opts ...CallOption) (Stream, error)
+
+// ChainUnary returns an invoker calling the interceptors in order, the first
+// being the outermost, and then the invoker
+func ChainUnary(invoker UnaryInvoker, interceptors ...UnaryInterceptor) UnaryInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, req Request, rsp interface{}, opts ...CallOption) error {
+			return interceptor(ctx, req, rsp, next, opts...)
+		}
+	}
+	return invoker
+}
+
+// ChainStream returns a streamer calling the interceptors in order, the first
+// being the outermost, and then the streamer
+func ChainStream(streamer Streamer, interceptors ...StreamInterceptor) Streamer {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], streamer
+		streamer = func(ctx context.Context, req Request, opts ...CallOption) (Stream, error) {
+			return interceptor(ctx, req, next, opts...)
+		}
+	}
+	return streamer
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router/static"
+)
+
+func TestInterceptors(t *testing.T) {
+	var calls []string
+
+	intercept := func(name string) UnaryInterceptor {
+		return func(ctx context.Context, req Request, rsp interface{}, invoker UnaryInvoker, opts ...CallOption) error {
+			calls = append(calls, name)
+			return invoker(metadata.Set(ctx, "Interceptor", name), req, rsp, opts...)
+		}
+	}
+
+	c := NewClient(
+		Router(static.NewRouter()),
+		Intercept(intercept("first"), intercept("second")),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				md, _ := metadata.FromContext(ctx)
+				calls = append(calls, "call "+md["Interceptor"])
+				return nil
+			}
+		}),
+	)
+
+	if err := c.Call(context.TODO(), c.NewRequest("foo", "Foo.Bar", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the interceptors are called in order before the call
+	if got := strings.Join(calls, ", "); got != "first, second, call second" {
+		t.Fatalf("Unexpected calls: %s", got)
+	}
+
+	// an interceptor can short circuit the call
+	calls = nil
+	c.Init(Intercept(func(ctx context.Context, req Request, rsp interface{}, invoker UnaryInvoker, opts ...CallOption) error {
+		return fmt.Errorf("rejected")
+	}))
+	if err := c.Call(context.TODO(), c.NewRequest("foo", "Foo.Bar", nil), nil); err == nil || err.Error() != "rejected" {
+		t.Fatalf("Expected the call to be rejected, got %v", err)
+	}
+	if got := strings.Join(calls, ", "); got != "first, second" {
+		t.Fatalf("Unexpected calls: %s", got)
+	}
+}
+
+func TestChainStream(t *testing.T) {
+	var calls []string
+
+	intercept := func(name string) StreamInterceptor {
+		return func(ctx context.Context, req Request, streamer Streamer, opts ...CallOption) (Stream, error) {
+			calls = append(calls, name)
+			return streamer(ctx, req, opts...)
+		}
+	}
+
+	streamer := ChainStream(func(ctx context.Context, req Request, opts ...CallOption) (Stream, error) {
+		calls = append(calls, "stream")
+		return nil, nil
+	}, intercept("first"), intercept("second"))
+
+	if _, err := streamer(context.TODO(), newRequest("foo", "Foo.Bar", nil, "application/json")); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ", "); got != "first, second, stream" {
+		t.Fatalf("Unexpected calls: %s", got)
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/metrics"
+	"github.com/micro/go-micro/v2/errors"
+)
+
+const (
+	// MetricRequests counts the calls and streams by service and endpoint
+	MetricRequests = "micro.client.requests"
+	// MetricErrors counts the failed calls and streams by service, endpoint
+	// and error code
+	MetricErrors = "micro.client.errors"
+	// MetricLatency is the latency of the calls, and the duration of the
+	// streams, by service and endpoint
+	MetricLatency = "micro.client.latency"
+	// MetricInFlight is the number of calls and open streams by service and
+	// endpoint
+	MetricInFlight = "micro.client.in_flight"
+	// MetricPoolInUse is the number of pooled connections in use by service
+	// and address
+	MetricPoolInUse = "micro.client.pool.in_use"
+)
+
+// CallMetrics reports the metrics of the calls of a client to a reporter
+type CallMetrics struct {
+	reporter metrics.Reporter
+	gauges   *metrics.Gauges
+}
+
+// NewCallMetrics returns the metrics of a client reported to the reporter
+func NewCallMetrics(r metrics.Reporter) *CallMetrics {
+	return &CallMetrics{
+		reporter: r,
+		gauges:   metrics.NewGauges(r),
+	}
+}
+
+// Start reports the start of a call, or the opening of a stream, and
+// returns the func reporting its end with its error
+func (m *CallMetrics) Start(req Request) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+
+	tags := metrics.Tags{
+		"service":  req.Service(),
+		"endpoint": req.Endpoint(),
+	}
+	start := time.Now()
+
+	m.reporter.Count(MetricRequests, 1, tags)
+	m.gauges.Add(MetricInFlight, tags, 1)
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			m.reporter.Timing(MetricLatency, time.Since(start), tags)
+			m.gauges.Add(MetricInFlight, tags, -1)
+
+			if err == nil {
+				return
+			}
+			m.reporter.Count(MetricErrors, 1, metrics.Tags{
+				"service":  req.Service(),
+				"endpoint": req.Endpoint(),
+				"code":     strconv.Itoa(int(errors.FromError(err).Code)),
+			})
+		})
+	}
+}
+
+// Conn reports a pooled connection to the address of the service in use,
+// and returns the func reporting its release
+func (m *CallMetrics) Conn(service, address string) func() {
+	if m == nil {
+		return func() {}
+	}
+
+	tags := metrics.Tags{
+		"service": service,
+		"address": address,
+	}
+	m.gauges.Add(MetricPoolInUse, tags, 1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.gauges.Add(MetricPoolInUse, tags, -1)
+		})
+	}
+}
+
+// StreamDone returns the stream calling done with its error once closed e.g.
+// to report the end of the stream to the metrics
+func StreamDone(stream Stream, done func(error)) Stream {
+	return &doneStream{
+		Stream: stream,
+		done:   done,
+	}
+}
+
+type doneStream struct {
+	Stream
+	done func(error)
+}
+
+func (d *doneStream) Close() error {
+	// the error which ended the stream, the end of the stream isn't one
+	serr := d.Stream.Error()
+	if serr == io.EOF {
+		serr = nil
+	}
+
+	err := d.Stream.Close()
+	if serr == nil {
+		serr = err
+	}
+	d.done(serr)
+
+	return err
+}
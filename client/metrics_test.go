@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/micro/go-micro/v2/debug/metrics"
+	"github.com/micro/go-micro/v2/debug/metrics/memory"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router/static"
+)
+
+func TestCallMetrics(t *testing.T) {
+	r := memory.NewReporter()
+
+	var fail bool
+	c := NewClient(
+		Router(static.NewRouter()),
+		Retries(0),
+		Metrics(r),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				list, _ := r.Read()
+				for _, m := range list {
+					if m.Name == MetricInFlight && m.Value != 1 {
+						t.Fatalf("Expected 1 call in flight, got %v", m.Value)
+					}
+				}
+				if fail {
+					return errors.NotFound("foo", "not found")
+				}
+				return nil
+			}
+		}),
+	)
+
+	req := c.NewRequest("foo", "Foo.Bar", nil)
+	if err := c.Call(context.TODO(), req, nil); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	if err := c.Call(context.TODO(), req, nil); err == nil {
+		t.Fatal("Expected the call to fail")
+	}
+
+	list, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]*metrics.Metric)
+	for _, m := range list {
+		if m.Tags["service"] != "foo" || m.Tags["endpoint"] != "Foo.Bar" {
+			t.Fatalf("Unexpected tags %v", m.Tags)
+		}
+		got[m.Name] = m
+	}
+
+	if m := got[MetricRequests]; m == nil || m.Value != 2 {
+		t.Fatalf("Expected 2 requests, got %+v", m)
+	}
+	if m := got[MetricErrors]; m == nil || m.Value != 1 || m.Tags["code"] != "404" {
+		t.Fatalf("Expected 1 not found error, got %+v", m)
+	}
+	if m := got[MetricLatency]; m == nil || m.Count != 2 {
+		t.Fatalf("Expected 2 timings, got %+v", m)
+	}
+	if m := got[MetricInFlight]; m == nil || m.Value != 0 {
+		t.Fatalf("Expected no calls in flight, got %+v", m)
+	}
+}
+
+func TestConnMetrics(t *testing.T) {
+	r := memory.NewReporter()
+	m := NewCallMetrics(r)
+
+	release := m.Conn("foo", "10.0.0.1:8080")
+	m.Conn("foo", "10.0.0.1:8080")
+
+	list, _ := r.Read()
+	if len(list) != 1 || list[0].Value != 2 {
+		t.Fatalf("Expected 2 connections in use, got %+v", list)
+	}
+
+	// releasing twice counts once
+	release()
+	release()
+
+	list, _ = r.Read()
+	if list[0].Value != 1 {
+		t.Fatalf("Expected 1 connection in use, got %v", list[0].Value)
+	}
+
+	// nil metrics report nothing
+	var none *CallMetrics
+	none.Start(newRequest("foo", "Foo.Bar", nil, "application/json"))(nil)
+	none.Conn("foo", "10.0.0.1:8080")()
+}
+
+// endedStream is a stream which ended with the error
+type endedStream struct {
+	Stream
+	err error
+}
+
+func (e *endedStream) Error() error { return e.err }
+func (e *endedStream) Close() error { return nil }
+
+func TestStreamMetrics(t *testing.T) {
+	r := memory.NewReporter()
+	m := NewCallMetrics(r)
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	stream := StreamDone(&endedStream{err: errors.InternalServerError("foo", "broken")}, m.Start(req))
+
+	list, _ := r.Read()
+	for _, m := range list {
+		if m.Name == MetricInFlight && m.Value != 1 {
+			t.Fatalf("Expected 1 stream in flight, got %v", m.Value)
+		}
+	}
+
+	stream.Close()
+
+	got := make(map[string]*metrics.Metric)
+	list, _ = r.Read()
+	for _, m := range list {
+		got[m.Name] = m
+	}
+	if m := got[MetricInFlight]; m == nil || m.Value != 0 {
+		t.Fatalf("Expected no streams in flight once closed, got %+v", m)
+	}
+	if m := got[MetricErrors]; m == nil || m.Value != 1 || m.Tags["code"] != "500" {
+		t.Fatalf("Expected the error of the stream to be reported, got %+v", m)
+	}
+
+	// the end of a stream isn't an error
+	stream = StreamDone(&endedStream{err: io.EOF}, m.Start(req))
+	stream.Close()
+
+	list, _ = r.Read()
+	for _, m := range list {
+		if m.Name == MetricErrors && m.Value != 1 {
+			t.Fatalf("Expected the end of the stream not to be an error, got %v errors", m.Value)
+		}
+	}
+}
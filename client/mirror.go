@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+var (
+	log = logger.NewModule("client")
+
+	// MaxMirroredCalls is the max number of mirrored calls in flight, the
+	// calls mirrored beyond are dropped so a slow target doesn't pile up
+	// goroutines
+	MaxMirroredCalls = 100
+
+	// the mirrored calls in flight
+	mirroring int64
+)
+
+// Mirror copies a percentage of the calls to a service to another service or
+// version, so it can be tested with production traffic. The responses of the
+// mirrored calls are discarded and their errors logged.
+type Mirror struct {
+	// Service whose calls are mirrored
+	Service string
+	// Target service the calls are mirrored to, defaults to the service
+	Target string
+	// Version of the target the calls are mirrored to, any if blank
+	Version string
+	// Percent of the calls mirrored from 0 to 100
+	Percent float64
+}
+
+type mirroredKey struct{}
+
+// MirrorCall mirrors the call to the targets of the mirrors matching the
+// service of the request. The mirrored calls are made in the background with
+// the client so they don't delay the call, and are never mirrored again.
+func MirrorCall(ctx context.Context, c Client, req Request, rsp interface{}, opts CallOptions, mirrors []Mirror) {
+	if len(mirrors) == 0 || req.Stream() {
+		return
+	}
+	if ok, _ := ctx.Value(mirroredKey{}).(bool); ok {
+		return
+	}
+
+	for _, m := range mirrors {
+		if m.Service != req.Service() || rand.Float64()*100 >= m.Percent {
+			continue
+		}
+		if atomic.AddInt64(&mirroring, 1) > int64(MaxMirroredCalls) {
+			atomic.AddInt64(&mirroring, -1)
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Dropping the mirror of %s.%s, %d calls mirrored already", req.Service(), req.Endpoint(), MaxMirroredCalls)
+			}
+			continue
+		}
+		go func(m Mirror) {
+			defer atomic.AddInt64(&mirroring, -1)
+			mirrorCall(ctx, c, req, rsp, opts, m)
+		}(m)
+	}
+}
+
+func mirrorCall(ctx context.Context, c Client, req Request, rsp interface{}, opts CallOptions, m Mirror) {
+	target := m.Target
+	if len(target) == 0 {
+		target = m.Service
+	}
+
+	// keep the metadata of the call but not its cancellation, the call
+	// answering first mustn't cancel the mirrored one
+	mctx := context.WithValue(context.Background(), mirroredKey{}, true)
+	if md, ok := metadata.FromContext(ctx); ok {
+		mctx = metadata.NewContext(mctx, metadata.Copy(md))
+	}
+
+	// decode into a response of the same type which is discarded
+	var mrsp interface{}
+	if v := reflect.ValueOf(rsp); v.Kind() == reflect.Ptr && !v.IsNil() {
+		mrsp = reflect.New(v.Elem().Type()).Interface()
+	}
+
+	callOpts := []CallOption{
+		WithRetries(0),
+		WithRequestTimeout(opts.RequestTimeout),
+	}
+	if len(m.Version) > 0 {
+		callOpts = append(callOpts, WithAddedSelectOptions(selector.WithFilter(selector.FilterVersion(m.Version))))
+	}
+
+	mreq := c.NewRequest(target, req.Endpoint(), req.Body(), WithContentType(req.ContentType()))
+	if err := c.Call(mctx, mreq, mrsp, callOpts...); err != nil {
+		log.Errorf("Error mirroring %s.%s to %s: %v", req.Service(), req.Endpoint(), target, err)
+	}
+}
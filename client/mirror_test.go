@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/router/static"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+func TestMirror(t *testing.T) {
+	calls := make(chan string, 10)
+
+	c := NewClient(
+		Router(static.NewRouter()),
+		Retries(0),
+		MirrorTraffic(Mirror{Service: "foo", Target: "foo.canary", Percent: 100}),
+		MirrorTraffic(Mirror{Service: "bar", Target: "bar.canary", Percent: 0}),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				md, _ := metadata.FromContext(ctx)
+				calls <- req.Service() + " " + md["Trace"]
+				return nil
+			}
+		}),
+	)
+
+	ctx := metadata.NewContext(context.TODO(), metadata.Metadata{"Trace": "1"})
+
+	// the calls to foo are mirrored with the metadata of the call
+	if err := c.Call(ctx, c.NewRequest("foo", "Foo.Bar", nil), new(hedgeResponse)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case call := <-calls:
+			got[call] = true
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the call to be mirrored, got %v", got)
+		}
+	}
+	if !got["foo 1"] || !got["foo.canary 1"] {
+		t.Fatalf("Expected calls to foo and foo.canary, got %v", got)
+	}
+
+	// the calls to bar are never mirrored
+	if err := c.Call(ctx, c.NewRequest("bar", "Bar.Baz", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	<-calls
+	select {
+	case call := <-calls:
+		t.Fatalf("Unexpected mirrored call %s", call)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// firstSelector selects the first route, ignoring the filters
+type firstSelector struct {
+	selector.Selector
+}
+
+func (firstSelector) Select(routes []router.Route, opts ...selector.SelectOption) (*router.Route, error) {
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+	return &routes[0], nil
+}
+
+func TestMirrorLimit(t *testing.T) {
+	defer func(n int) { MaxMirroredCalls = n }(MaxMirroredCalls)
+	MaxMirroredCalls = 1
+
+	mirrored := make(chan CallOptions, 10)
+	release := make(chan bool)
+	c := NewClient(
+		Router(static.NewRouter()),
+		Selector(firstSelector{selector.NewSelector()}),
+		Retries(0),
+		PreferLocality(selector.Locality{Zone: "a"}),
+		MirrorTraffic(Mirror{Service: "foo", Target: "foo.canary", Version: "2", Percent: 100}),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				if req.Service() == "foo.canary" {
+					mirrored <- opts
+					<-release
+				}
+				return nil
+			}
+		}),
+	)
+
+	// the calls mirrored while one is in flight are dropped
+	for i := 0; i < 3; i++ {
+		if err := c.Call(context.TODO(), c.NewRequest("foo", "Foo.Bar", nil), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var opts CallOptions
+	select {
+	case opts = <-mirrored:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the call to be mirrored")
+	}
+	close(release)
+	select {
+	case <-mirrored:
+		t.Fatal("Expected the calls over the limit to be dropped")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the version is filtered along with the locality preference
+	if n := len(opts.SelectOptions); n != 2 {
+		t.Fatalf("Expected the version and locality filters, got %d select options", n)
+	}
+}
@@ -6,10 +6,12 @@ import (
 
 	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/codec"
+	"github.com/micro/go-micro/v2/debug/metrics"
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/router"
 	"github.com/micro/go-micro/v2/selector"
 	"github.com/micro/go-micro/v2/transport"
+	"github.com/micro/go-micro/v2/util/pool"
 )
 
 type Options struct {
@@ -26,6 +28,15 @@ type Options struct {
 	// Connection Pool
 	PoolSize int
 	PoolTTL  time.Duration
+	// PoolIdleTimeout is the time after which an idle connection is evicted
+	PoolIdleTimeout time.Duration
+	// PoolCheckInterval is the interval of the health checks of the idle
+	// connections of the pool, they aren't checked if zero. Defaults to
+	// DefaultPoolCheckInterval.
+	PoolCheckInterval time.Duration
+	// PoolCheck is the health check of the idle connections of the pool,
+	// the pools created already use it once changed
+	PoolCheck pool.CheckFunc
 
 	// Response cache
 	Cache *Cache
@@ -33,6 +44,25 @@ type Options struct {
 	// Middleware for client
 	Wrappers []Wrapper
 
+	// Interceptors of the calls and streams, the first is the outermost
+	UnaryInterceptors  []UnaryInterceptor
+	StreamInterceptors []StreamInterceptor
+
+	// Calls mirrored to other services
+	Mirrors []Mirror
+
+	// Options of the services called
+	Services *Services
+
+	// Rate limits of the calls to services
+	RateLimits *RateLimits
+
+	// Queue of the messages published asynchronously
+	PublishQueue *PublishQueue
+
+	// Metrics of the calls, none are reported if nil
+	Metrics *CallMetrics
+
 	// Default Call Options
 	CallOptions CallOptions
 
@@ -44,6 +74,8 @@ type Options struct {
 type CallOptions struct {
 	// Address of remote hosts
 	Address []string
+	// Prober of the addresses, the dead ones aren't called
+	Prober *Prober
 	// Backoff func
 	Backoff BackoffFunc
 	// Duration to cache the response for
@@ -54,6 +86,18 @@ type CallOptions struct {
 	Retries int
 	// Check if retriable func
 	Retry RetryFunc
+	// Hooks called with every retry decision
+	RetryHooks []RetryHook
+	// Budget limiting the ratio of retries
+	RetryBudget *Budget
+	// Circuit breaker checked before every attempt
+	Breaker Breaker
+	// Fallback answering the calls which failed
+	Fallback FallbackFunc
+	// Delay after which a hedged request is sent
+	HedgeDelay time.Duration
+	// Max number of hedged requests per attempt
+	MaxHedges int
 	// Request/Response timeout
 	RequestTimeout time.Duration
 	// Router to use for this call
@@ -64,10 +108,16 @@ type CallOptions struct {
 	SelectOptions []selector.SelectOption
 	// Stream timeout for the stream
 	StreamTimeout time.Duration
+	// Resume returns the token a broken stream is resumed from
+	Resume ResumeFunc
+	// Max number of attempts to reopen a broken stream
+	ResumeAttempts int
 	// Use the services own auth token
 	ServiceToken bool
 	// Network to lookup the route within
 	Network string
+	// Compression is the encoding of the bodies e.g. gzip, none if blank
+	Compression string
 
 	// Middleware for low level call func
 	CallWrappers []CallWrapper
@@ -80,6 +130,8 @@ type CallOptions struct {
 type PublishOptions struct {
 	// Exchange is the routing exchange for the message
 	Exchange string
+	// Async queues the message to be published in the background
+	Async bool
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -111,12 +163,17 @@ func NewOptions(options ...Option) Options {
 			RequestTimeout: DefaultRequestTimeout,
 			DialTimeout:    transport.DefaultDialTimeout,
 		},
-		PoolSize:  DefaultPoolSize,
-		PoolTTL:   DefaultPoolTTL,
-		Broker:    broker.DefaultBroker,
-		Router:    router.DefaultRouter,
-		Selector:  selector.DefaultSelector,
-		Transport: transport.DefaultTransport,
+		PoolSize:     DefaultPoolSize,
+		PoolTTL:      DefaultPoolTTL,
+		Services:     NewServices(),
+		PublishQueue: NewPublishQueue(DefaultPublishQueueSize, DefaultPublishWorkers, nil),
+		Broker:       broker.DefaultBroker,
+		Router:       router.DefaultRouter,
+		Selector:     selector.DefaultSelector,
+		Transport:    transport.DefaultTransport,
+
+		// the idle connections are checked by default
+		PoolCheckInterval: DefaultPoolCheckInterval,
 	}
 
 	for _, o := range options {
@@ -161,6 +218,23 @@ func PoolTTL(d time.Duration) Option {
 	}
 }
 
+// PoolIdleTimeout sets the time after which an idle connection of the pool
+// is evicted
+func PoolIdleTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.PoolIdleTimeout = d
+	}
+}
+
+// PoolHealthCheck checks the idle connections of the pool at the interval,
+// evicting the ones too old, idle for too long or failing the check func
+func PoolHealthCheck(interval time.Duration, fn pool.CheckFunc) Option {
+	return func(o *Options) {
+		o.PoolCheckInterval = interval
+		o.PoolCheck = fn
+	}
+}
+
 // Transport to use for communication e.g http, rabbitmq, etc
 func Transport(t transport.Transport) Option {
 	return func(o *Options) {
@@ -189,6 +263,22 @@ func Wrap(w Wrapper) Option {
 	}
 }
 
+// Intercept adds interceptors to the calls of the client, they're called in
+// the order they're added, within the client wrappers
+func Intercept(i ...UnaryInterceptor) Option {
+	return func(o *Options) {
+		o.UnaryInterceptors = append(o.UnaryInterceptors, i...)
+	}
+}
+
+// InterceptStream adds interceptors to the streams of the client, they're
+// called in the order they're added, within the client wrappers
+func InterceptStream(i ...StreamInterceptor) Option {
+	return func(o *Options) {
+		o.StreamInterceptors = append(o.StreamInterceptors, i...)
+	}
+}
+
 // Adds a Wrapper to the list of CallFunc wrappers
 func WrapCall(cw ...CallWrapper) Option {
 	return func(o *Options) {
@@ -219,6 +309,142 @@ func Retry(fn RetryFunc) Option {
 	}
 }
 
+// RetryHooks adds hooks called with every retry, backoff and give up decision
+func RetryHooks(h ...RetryHook) Option {
+	return func(o *Options) {
+		o.CallOptions.RetryHooks = append(o.CallOptions.RetryHooks, h...)
+	}
+}
+
+// CacheEndpoint sets the cache policy of an endpoint of a service, its
+// responses are cached for the ttl of the policy unless the call sets WithCache
+func CacheEndpoint(service, endpoint string, p CachePolicy) Option {
+	return func(o *Options) {
+		if o.Cache == nil {
+			o.Cache = NewCache()
+		}
+		o.Cache.SetPolicy(service, endpoint, p)
+	}
+}
+
+// ServiceConfig sets the options of the calls to a service, overriding those
+// of the client e.g. ServiceConfig("go.micro.srv.foo", ServiceCallOptions(WithRetries(3))).
+// Update Options.Services to change them while the client is used.
+func ServiceConfig(service string, opts ...ServiceOption) Option {
+	return func(o *Options) {
+		if o.Services == nil {
+			o.Services = NewServices()
+		}
+		o.Services.Set(service, opts...)
+	}
+}
+
+// RateLimit limits the calls to the service to rps per second with bursts of
+// up to burst calls. Calls over the limit fail with ErrRateLimited.
+func RateLimit(service string, rps float64, burst int) Option {
+	return func(o *Options) {
+		if o.RateLimits == nil {
+			o.RateLimits = NewRateLimits()
+		}
+		o.RateLimits.Set(service, "", rps, burst)
+	}
+}
+
+// RateLimitEndpoint limits the calls to the endpoint of the service, see RateLimit
+func RateLimitEndpoint(service, endpoint string, rps float64, burst int) Option {
+	return func(o *Options) {
+		if o.RateLimits == nil {
+			o.RateLimits = NewRateLimits()
+		}
+		o.RateLimits.Set(service, endpoint, rps, burst)
+	}
+}
+
+// StaticAddress calls the service at the addresses rather than using service
+// discovery. The addresses are probed, see HealthProbe, and the dead ones
+// left out until they are up again.
+func StaticAddress(service string, addrs ...string) Option {
+	return func(o *Options) {
+		if o.Services == nil {
+			o.Services = NewServices()
+		}
+		o.Services.Update(service, ServiceCallOptions(WithAddress(addrs...)))
+
+		if o.CallOptions.Prober == nil {
+			o.CallOptions.Prober = NewProber()
+		}
+		o.CallOptions.Prober.Add(addrs...)
+	}
+}
+
+// HealthProbe sets the options of the prober of the static addresses
+func HealthProbe(opts ...ProbeOption) Option {
+	return func(o *Options) {
+		if o.CallOptions.Prober == nil {
+			o.CallOptions.Prober = NewProber(opts...)
+			return
+		}
+		o.CallOptions.Prober.Init(opts...)
+	}
+}
+
+// Metrics reports the requests, errors, latency and in flight calls by
+// service and endpoint, and the pooled connections in use, to the reporter
+func Metrics(r metrics.Reporter) Option {
+	return func(o *Options) {
+		o.Metrics = NewCallMetrics(r)
+	}
+}
+
+// AsyncPublish sets the size of the queue of the messages published with
+// PublishAsync, the number of workers publishing them, and the func called
+// with those failing to publish
+func AsyncPublish(size, workers int, fn PublishErrorFunc) Option {
+	return func(o *Options) {
+		o.PublishQueue = NewPublishQueue(size, workers, fn)
+	}
+}
+
+// MirrorTraffic mirrors the percent of the calls to the service to the target
+// service and version, see Mirror
+func MirrorTraffic(m Mirror) Option {
+	return func(o *Options) {
+		o.Mirrors = append(o.Mirrors, m)
+	}
+}
+
+// Compression compresses the bodies of the calls with the encoding e.g.
+// gzip, if the services accept it. See WithCompression.
+func Compression(encoding string) Option {
+	return func(o *Options) {
+		o.CallOptions.Compression = encoding
+	}
+}
+
+// RetryBudget sets the budget limiting the retries to a ratio of the requests
+func RetryBudget(b *Budget) Option {
+	return func(o *Options) {
+		o.CallOptions.RetryBudget = b
+	}
+}
+
+// CircuitBreaker sets the circuit breaker checked before every call attempt,
+// see NewBreaker for the default implementation
+func CircuitBreaker(b Breaker) Option {
+	return func(o *Options) {
+		o.CallOptions.Breaker = b
+	}
+}
+
+// PreferLocality selects routes in the local zone or region before the others,
+// see selector.PreferLocality. Use selector.LocalityFromEnv to read the locality
+// from the environment.
+func PreferLocality(l selector.Locality) Option {
+	return func(o *Options) {
+		o.CallOptions.SelectOptions = append(o.CallOptions.SelectOptions, selector.WithFilter(selector.PreferLocality(l)))
+	}
+}
+
 // Registry sets the routers registry
 func Registry(r registry.Registry) Option {
 	return func(o *Options) {
@@ -257,6 +483,14 @@ func WithExchange(e string) PublishOption {
 	}
 }
 
+// PublishAsync queues the message to be published in the background rather
+// than waiting for the broker, see AsyncPublish
+func PublishAsync() PublishOption {
+	return func(o *PublishOptions) {
+		o.Async = true
+	}
+}
+
 // PublishContext sets the context in publish options
 func PublishContext(ctx context.Context) PublishOption {
 	return func(o *PublishOptions) {
@@ -264,13 +498,48 @@ func PublishContext(ctx context.Context) PublishOption {
 	}
 }
 
-// WithAddress sets the remote addresses to use rather than using service discovery
+// WithAddress sets the remote addresses to use rather than using service discovery.
+// A route is selected among the addresses which aren't dead, see WithProber.
 func WithAddress(a ...string) CallOption {
 	return func(o *CallOptions) {
 		o.Address = a
 	}
 }
 
+// WithProber sets the prober of the addresses of the call, the addresses
+// called are probed from then on
+func WithProber(p *Prober) CallOption {
+	return func(o *CallOptions) {
+		o.Prober = p
+	}
+}
+
+// WithResume reopens the stream up to attempts times when it breaks, resuming
+// it from the token returned by fn. See ResumeStream.
+func WithResume(fn ResumeFunc, attempts int) CallOption {
+	return func(o *CallOptions) {
+		o.Resume = fn
+		o.ResumeAttempts = attempts
+	}
+}
+
+// Fallback answers the call with the response of fn when it fails, once
+// out of retries or rejected by the circuit breaker, rather than returning
+// the error. See FallbackFunc and CallFallback.
+func Fallback(fn FallbackFunc) CallOption {
+	return func(o *CallOptions) {
+		o.Fallback = fn
+	}
+}
+
+// WithCompression compresses the bodies of the call with the encoding e.g.
+// gzip, if the service accepts it. A blank encoding turns compression off.
+func WithCompression(encoding string) CallOption {
+	return func(o *CallOptions) {
+		o.Compression = encoding
+	}
+}
+
 // WithCallWrapper is a CallOption which adds to the existing CallFunc wrappers
 func WithCallWrapper(cw ...CallWrapper) CallOption {
 	return func(o *CallOptions) {
@@ -294,6 +563,39 @@ func WithRetry(fn RetryFunc) CallOption {
 	}
 }
 
+// WithRetryHooks is a CallOption which adds to the existing retry hooks
+func WithRetryHooks(h ...RetryHook) CallOption {
+	return func(o *CallOptions) {
+		o.RetryHooks = append(o.RetryHooks, h...)
+	}
+}
+
+// WithRetryBudget is a CallOption which overrides the retry budget
+// set in Options.CallOptions, nil disables it
+func WithRetryBudget(b *Budget) CallOption {
+	return func(o *CallOptions) {
+		o.RetryBudget = b
+	}
+}
+
+// Hedging is a CallOption which sends a duplicate request to another node if
+// the call hasn't answered within the delay, up to max hedges, using the first
+// answer. Only use it for idempotent requests.
+func Hedging(delay time.Duration, maxHedges int) CallOption {
+	return func(o *CallOptions) {
+		o.HedgeDelay = delay
+		o.MaxHedges = maxHedges
+	}
+}
+
+// WithBreaker is a CallOption which overrides the circuit breaker
+// set in Options.CallOptions, nil disables it
+func WithBreaker(b Breaker) CallOption {
+	return func(o *CallOptions) {
+		o.Breaker = b
+	}
+}
+
 // WithRetries is a CallOption which overrides that which
 // set in Options.CallOptions
 func WithRetries(i int) CallOption {
@@ -362,13 +664,24 @@ func WithSelector(s selector.Selector) CallOption {
 	}
 }
 
-// WithSelectOptions sets the options to pass to the selector for this call
+// WithSelectOptions sets the options to pass to the selector for this call,
+// replacing the select options of the client
 func WithSelectOptions(sops ...selector.SelectOption) CallOption {
 	return func(o *CallOptions) {
 		o.SelectOptions = sops
 	}
 }
 
+// WithAddedSelectOptions adds the options to pass to the selector for this
+// call. They're applied before the select options of the client, so its
+// filters preferring routes, e.g. PreferLocality, only prefer among the routes
+// the call keeps.
+func WithAddedSelectOptions(sops ...selector.SelectOption) CallOption {
+	return func(o *CallOptions) {
+		o.SelectOptions = append(sops[:len(sops):len(sops)], o.SelectOptions...)
+	}
+}
+
 func WithMessageContentType(ct string) MessageOption {
 	return func(o *MessageOptions) {
 		o.ContentType = ct
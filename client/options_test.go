@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
 	"github.com/micro/go-micro/v2/transport"
 )
 
@@ -83,3 +85,45 @@ func TestCallOptions(t *testing.T) {
 
 	}
 }
+
+func TestWithSelectOptions(t *testing.T) {
+	local := router.Route{Address: "local", Version: "1", Metadata: map[string]string{selector.ZoneKey: "a"}}
+	remote := router.Route{Address: "remote", Version: "2", Metadata: map[string]string{selector.ZoneKey: "b"}}
+
+	opts := NewOptions(PreferLocality(selector.Locality{Zone: "a"}))
+
+	// the select options of the call replace the ones of the client
+	callOpts := opts.CallOptions
+	WithSelectOptions(selector.WithFilter(selector.FilterVersion("1")))(&callOpts)
+	if n := len(callOpts.SelectOptions); n != 1 {
+		t.Fatalf("Expected the select options replaced, got %d", n)
+	}
+	route, err := selector.NewSelector().Select([]router.Route{local, remote}, callOpts.SelectOptions...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route.Address != "local" {
+		t.Fatalf("Expected the route of the version, got %s", route.Address)
+	}
+
+	// the added select options are merged with the ones of the client
+	callOpts = opts.CallOptions
+	WithAddedSelectOptions(selector.WithFilter(selector.FilterVersion("2")))(&callOpts)
+	if n := len(callOpts.SelectOptions); n != 2 {
+		t.Fatalf("Expected the select options merged, got %d", n)
+	}
+
+	// the version is filtered before the locality is preferred
+	route, err = selector.NewSelector().Select([]router.Route{local, remote}, callOpts.SelectOptions...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route.Address != "remote" {
+		t.Fatalf("Expected the route of the version, got %s", route.Address)
+	}
+
+	// the select options of the client are left as is
+	if n := len(opts.CallOptions.SelectOptions); n != 1 {
+		t.Fatalf("Expected the locality preference of the client only, got %d select options", n)
+	}
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultProbeInterval is the interval between the probes of an address
+	DefaultProbeInterval = time.Second * 10
+	// DefaultProbeTimeout is the max duration of a probe
+	DefaultProbeTimeout = time.Second * 2
+	// DefaultProbeFailures is the number of failed probes in a row after
+	// which an address is dead
+	DefaultProbeFailures = 2
+)
+
+// ProbeFunc checks the health of an address, returning an error if it's down
+type ProbeFunc func(ctx context.Context, address string) error
+
+// ProbeOptions are the options of a prober
+type ProbeOptions struct {
+	// Interval between the probes of an address
+	Interval time.Duration
+	// Timeout of a probe
+	Timeout time.Duration
+	// Failures is the number of failed probes in a row after which an
+	// address is dead. A successful probe brings it back.
+	Failures int
+	// Probe checks an address, DialProbe by default
+	Probe ProbeFunc
+}
+
+// ProbeOption sets an option of a prober
+type ProbeOption func(o *ProbeOptions)
+
+// ProbeInterval sets the interval between the probes of an address
+func ProbeInterval(d time.Duration) ProbeOption {
+	return func(o *ProbeOptions) {
+		o.Interval = d
+	}
+}
+
+// ProbeTimeout sets the max duration of a probe
+func ProbeTimeout(d time.Duration) ProbeOption {
+	return func(o *ProbeOptions) {
+		o.Timeout = d
+	}
+}
+
+// ProbeFailures sets the number of failed probes in a row after which an
+// address is dead
+func ProbeFailures(n int) ProbeOption {
+	return func(o *ProbeOptions) {
+		o.Failures = n
+	}
+}
+
+// ProbeWith sets the func checking an address e.g. to call a health endpoint
+func ProbeWith(fn ProbeFunc) ProbeOption {
+	return func(o *ProbeOptions) {
+		o.Probe = fn
+	}
+}
+
+// DialProbe checks an address is up by opening a tcp connection to it
+func DialProbe(ctx context.Context, address string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Prober periodically probes the static addresses of services, those failing
+// their probes are dead and left out of the calls until they are up again
+type Prober struct {
+	sync.RWMutex
+	opts ProbeOptions
+	// failed probes in a row by address
+	addrs map[string]int
+
+	// probing starts once addresses are added, and ends once stopped
+	start sync.Once
+	once  sync.Once
+	exit  chan bool
+}
+
+// NewProber returns a prober, which probes the addresses added until stopped.
+// The prober of a client is stopped with the service.
+func NewProber(opts ...ProbeOption) *Prober {
+	options := ProbeOptions{
+		Interval: DefaultProbeInterval,
+		Timeout:  DefaultProbeTimeout,
+		Failures: DefaultProbeFailures,
+		Probe:    DialProbe,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	p := &Prober{
+		opts:  options,
+		addrs: make(map[string]int),
+		exit:  make(chan bool),
+	}
+	return p
+}
+
+// Init updates the options of the prober
+func (p *Prober) Init(opts ...ProbeOption) {
+	p.Lock()
+	for _, o := range opts {
+		o(&p.opts)
+	}
+	p.Unlock()
+}
+
+// Add addresses to probe, they are healthy until they fail their probes
+func (p *Prober) Add(addrs ...string) {
+	if len(addrs) == 0 {
+		return
+	}
+
+	p.Lock()
+	for _, a := range addrs {
+		if _, ok := p.addrs[a]; !ok {
+			p.addrs[a] = 0
+		}
+	}
+	p.Unlock()
+
+	p.start.Do(func() {
+		go p.run()
+	})
+}
+
+// Remove addresses from the prober
+func (p *Prober) Remove(addrs ...string) {
+	p.Lock()
+	for _, a := range addrs {
+		delete(p.addrs, a)
+	}
+	p.Unlock()
+}
+
+// Healthy returns the addresses which aren't dead. Addresses which aren't
+// probed are healthy.
+func (p *Prober) Healthy(addrs []string) []string {
+	if p == nil {
+		return addrs
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+
+	healthy := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		if p.addrs[a] < p.opts.Failures {
+			healthy = append(healthy, a)
+		}
+	}
+	return healthy
+}
+
+// Stop probing
+func (p *Prober) Stop() {
+	p.once.Do(func() {
+		close(p.exit)
+	})
+}
+
+func (p *Prober) run() {
+	for {
+		p.RLock()
+		interval := p.opts.Interval
+		p.RUnlock()
+
+		select {
+		case <-p.exit:
+			return
+		case <-time.After(interval):
+			p.probe()
+		}
+	}
+}
+
+// probe every address at once and record the results
+func (p *Prober) probe() {
+	p.RLock()
+	opts := p.opts
+	addrs := make([]string, 0, len(p.addrs))
+	for a := range p.addrs {
+		addrs = append(addrs, a)
+	}
+	p.RUnlock()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(addrs))
+	for i, a := range addrs {
+		wg.Add(1)
+		go func(i int, a string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+			defer cancel()
+			errs[i] = opts.Probe(ctx, a)
+		}(i, a)
+	}
+	wg.Wait()
+
+	p.Lock()
+	defer p.Unlock()
+
+	for i, a := range addrs {
+		failures, ok := p.addrs[a]
+		if !ok {
+			// removed while being probed
+			continue
+		}
+
+		if errs[i] == nil {
+			if failures >= opts.Failures {
+				log.Infof("Address %s is up again", a)
+			}
+			p.addrs[a] = 0
+			continue
+		}
+
+		p.addrs[a] = failures + 1
+		if failures+1 == opts.Failures {
+			log.Warnf("Address %s is dead after %d failed probes: %v", a, failures+1, errs[i])
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router/static"
+)
+
+func TestProber(t *testing.T) {
+	var mu sync.Mutex
+	down := map[string]bool{"b:1": true}
+
+	p := NewProber(
+		ProbeInterval(10*time.Millisecond),
+		ProbeFailures(2),
+		ProbeWith(func(ctx context.Context, address string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if down[address] {
+				return fmt.Errorf("%s is down", address)
+			}
+			return nil
+		}),
+	)
+	defer p.Stop()
+
+	addrs := []string{"a:1", "b:1"}
+	p.Add(addrs...)
+
+	// addresses are healthy until they fail their probes
+	if healthy := p.Healthy(addrs); len(healthy) != 2 {
+		t.Fatalf("Expected 2 healthy addresses, got %v", healthy)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if healthy := p.Healthy(addrs); len(healthy) != 1 || healthy[0] != "a:1" {
+		t.Fatalf("Expected only a:1 to be healthy, got %v", healthy)
+	}
+
+	// addresses which aren't probed are healthy
+	if healthy := p.Healthy([]string{"c:1"}); len(healthy) != 1 {
+		t.Fatalf("Expected an address not probed to be healthy, got %v", healthy)
+	}
+
+	// a dead address comes back once up
+	mu.Lock()
+	down["b:1"] = false
+	mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	if healthy := p.Healthy(addrs); len(healthy) != 2 {
+		t.Fatalf("Expected 2 healthy addresses, got %v", healthy)
+	}
+}
+
+func TestDialProbe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+
+	if err := DialProbe(context.TODO(), addr); err != nil {
+		t.Fatalf("Unexpected error probing a listener: %v", err)
+	}
+
+	l.Close()
+	if err := DialProbe(context.TODO(), addr); err == nil {
+		t.Fatal("Expected an error probing a closed listener")
+	}
+}
+
+func TestStaticAddress(t *testing.T) {
+	var mu sync.Mutex
+	var called []string
+
+	c := NewClient(
+		Router(static.NewRouter()),
+		StaticAddress("foo", "a:1", "b:1"),
+		HealthProbe(
+			ProbeInterval(10*time.Millisecond),
+			ProbeFailures(1),
+			ProbeWith(func(ctx context.Context, address string) error {
+				if address == "b:1" {
+					return fmt.Errorf("down")
+				}
+				return nil
+			}),
+		),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				mu.Lock()
+				called = append(called, node.Address)
+				mu.Unlock()
+				return nil
+			}
+		}),
+	)
+	defer c.Options().CallOptions.Prober.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+
+	req := c.NewRequest("foo", "Foo.Bar", nil)
+	for i := 0; i < 10; i++ {
+		if err := c.Call(context.TODO(), req, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, a := range called {
+		if a != "a:1" {
+			t.Fatalf("Expected only a:1 to be called, got %v", called)
+		}
+	}
+}
+
+func TestStaticAddressOptions(t *testing.T) {
+	c := NewClient(
+		ServiceConfig("foo", ServicePoolSize(5)),
+		StaticAddress("foo", "a:1"),
+	)
+	defer c.Options().CallOptions.Prober.Stop()
+
+	// the static addresses are added to the options of the service
+	opts, ok := c.Options().Services.Get("foo")
+	if !ok || opts.PoolSize != 5 {
+		t.Fatalf("Expected the options of the service to be kept, got %+v", opts)
+	}
+	var callOpts CallOptions
+	for _, o := range opts.CallOptions {
+		o(&callOpts)
+	}
+	if len(callOpts.Address) != 1 || callOpts.Address[0] != "a:1" {
+		t.Fatalf("Expected the static address, got %v", callOpts.Address)
+	}
+
+	// only the static addresses are probed
+	p := c.Options().CallOptions.Prober
+	if _, err := selectAddress(c.NewRequest("bar", "Bar.Baz", nil), "bar", []string{"b:1"}, c.Options().CallOptions); err != nil {
+		t.Fatal(err)
+	}
+	p.RLock()
+	defer p.RUnlock()
+	if _, ok := p.addrs["b:1"]; ok || len(p.addrs) != 1 {
+		t.Fatalf("Expected only the static address to be probed, got %v", p.addrs)
+	}
+}
@@ -0,0 +1,306 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+var (
+	// DefaultPublishQueueSize is the number of messages the async publish
+	// queue holds before publishing fails with ErrPublishQueueFull
+	DefaultPublishQueueSize = 1024
+	// DefaultPublishWorkers is the number of messages published at once by
+	// the async publish queue and PublishAll
+	DefaultPublishWorkers = 8
+	// DefaultPublishDrainTimeout is the max time a service waits for the
+	// messages queued to be published as it stops
+	DefaultPublishDrainTimeout = 10 * time.Second
+
+	// ErrPublishQueueFull is returned by an async publish when the queue is full
+	ErrPublishQueueFull = errors.New("go.micro.client", "publish queue full", 503)
+	// ErrPublishQueueClosed is returned by an async publish once the queue is closed
+	ErrPublishQueueClosed = errors.New("go.micro.client", "publish queue closed", 503)
+)
+
+// PublishFunc publishes a message
+type PublishFunc func(ctx context.Context, msg Message, opts ...PublishOption) error
+
+// PublishErrorFunc is called with the messages published asynchronously
+// which failed to publish
+type PublishErrorFunc func(ctx context.Context, msg Message, err error)
+
+// BatchError is the error of the messages of a batch which failed to publish,
+// indexed as the messages of the batch. Those published have a nil error.
+type BatchError []error
+
+func (b BatchError) Error() string {
+	var failed int
+	var first error
+	for _, err := range b {
+		if err == nil {
+			continue
+		}
+		if first == nil {
+			first = err
+		}
+		failed++
+	}
+	return fmt.Sprintf("failed to publish %d of %d messages: %v", failed, len(b), first)
+}
+
+// PublishAll publishes the messages with the client, in batches if its broker
+// is a broker.BatchPublisher, otherwise a few at once. Messages which fail to
+// publish don't stop the others, the error is a BatchError.
+func PublishAll(ctx context.Context, c Client, msgs []Message, opts ...PublishOption) error {
+	var errs BatchError
+	if bp, ok := c.Options().Broker.(broker.BatchPublisher); ok && !publishAsync(opts) {
+		errs = publishBatches(ctx, c, bp, msgs, opts...)
+	} else {
+		errs = publishEach(ctx, c, msgs, opts...)
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return errs
+		}
+	}
+	return nil
+}
+
+// publishAsync returns true if the options queue the messages
+func publishAsync(opts []PublishOption) bool {
+	var options PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	return options.Async
+}
+
+// publishEach publishes the messages one at a time, a few at once
+func publishEach(ctx context.Context, c Client, msgs []Message, opts ...PublishOption) BatchError {
+	errs := make(BatchError, len(msgs))
+	sem := make(chan bool, DefaultPublishWorkers)
+
+	var wg sync.WaitGroup
+	for i, msg := range msgs {
+		sem <- true
+		wg.Add(1)
+		go func(i int, msg Message) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = c.Publish(ctx, msg, opts...)
+		}(i, msg)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// collector is a broker collecting the message published rather than
+// publishing it, so the messages encoded by a client are published in batches
+type collector struct {
+	broker.Broker
+	topic string
+	msg   *broker.Message
+	opts  []broker.PublishOption
+}
+
+func (c *collector) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	c.topic, c.msg, c.opts = topic, msg, opts
+	return nil
+}
+
+type publishBrokerKey struct{}
+
+// PublishBroker returns the broker a message is published to, the broker of
+// the client unless the options override it
+func PublishBroker(o PublishOptions, b broker.Broker) broker.Broker {
+	if o.Context == nil {
+		return b
+	}
+	if pb, ok := o.Context.Value(publishBrokerKey{}).(broker.Broker); ok {
+		return pb
+	}
+	return b
+}
+
+// publishBatches encodes the messages with the client, so its wrappers apply,
+// then publishes those of each topic in a batch
+func publishBatches(ctx context.Context, c Client, bp broker.BatchPublisher, msgs []Message, opts ...PublishOption) BatchError {
+	errs := make(BatchError, len(msgs))
+
+	type batch struct {
+		index []int
+		msgs  []*broker.Message
+		opts  []broker.PublishOption
+	}
+	var topics []string
+	batches := make(map[string]*batch)
+
+	n := len(opts)
+	for i, msg := range msgs {
+		col := &collector{Broker: c.Options().Broker}
+		collect := func(o *PublishOptions) {
+			if o.Context == nil {
+				o.Context = context.Background()
+			}
+			o.Context = context.WithValue(o.Context, publishBrokerKey{}, col)
+		}
+		if errs[i] = c.Publish(ctx, msg, append(opts[:n:n], collect)...); errs[i] != nil {
+			continue
+		}
+		// a wrapper may have published it already
+		if col.msg == nil {
+			continue
+		}
+
+		b, ok := batches[col.topic]
+		if !ok {
+			b = &batch{opts: col.opts}
+			batches[col.topic] = b
+			topics = append(topics, col.topic)
+		}
+		b.index = append(b.index, i)
+		b.msgs = append(b.msgs, col.msg)
+	}
+
+	for _, topic := range topics {
+		b := batches[topic]
+		if err := bp.PublishBatch(topic, b.msgs, b.opts...); err != nil {
+			for _, i := range b.index {
+				errs[i] = err
+			}
+		}
+	}
+
+	return errs
+}
+
+// PublishQueue publishes messages in the background, so the publisher doesn't
+// wait for the broker. Messages which fail to publish are passed to the error
+// func, or logged if there's none.
+type PublishQueue struct {
+	queue   chan publication
+	workers int
+	onError PublishErrorFunc
+	once    sync.Once
+
+	// closed is set once the queue is closed, wg waits for the workers
+	sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+type publication struct {
+	ctx     context.Context
+	msg     Message
+	opts    []PublishOption
+	publish PublishFunc
+}
+
+// NewPublishQueue returns a queue of the size published by the workers, which
+// are started with the first message
+func NewPublishQueue(size, workers int, fn PublishErrorFunc) *PublishQueue {
+	if size < 1 {
+		size = DefaultPublishQueueSize
+	}
+	if workers < 1 {
+		workers = DefaultPublishWorkers
+	}
+
+	return &PublishQueue{
+		queue:   make(chan publication, size),
+		workers: workers,
+		onError: fn,
+	}
+}
+
+// Enqueue the message to be published with the publish func and options,
+// PublishAsync unset, returning ErrPublishQueueFull rather than waiting if
+// the queue is full. The message is published with the metadata of the
+// context, not its deadline.
+func (q *PublishQueue) Enqueue(ctx context.Context, msg Message, publish PublishFunc, opts ...PublishOption) error {
+	q.RLock()
+	defer q.RUnlock()
+
+	if q.closed {
+		return ErrPublishQueueClosed
+	}
+
+	q.once.Do(q.start)
+
+	// the context of the caller is likely done before the message is published
+	pctx := context.Background()
+	if md, ok := metadata.FromContext(ctx); ok {
+		pctx = metadata.NewContext(pctx, metadata.Copy(md))
+	}
+
+	select {
+	case q.queue <- publication{pctx, msg, opts, publish}:
+		return nil
+	default:
+		return ErrPublishQueueFull
+	}
+}
+
+// start starts the workers
+func (q *PublishQueue) start() {
+	q.wg.Add(q.workers)
+	for i := 0; i < q.workers; i++ {
+		go q.run()
+	}
+}
+
+// Close stops queueing messages and waits for those queued to be published,
+// or the context to be done. The workers stop once the queue is drained.
+func (q *PublishQueue) Close(ctx context.Context) error {
+	q.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// publishSync unsets PublishAsync so the queued messages are published
+func publishSync(o *PublishOptions) {
+	o.Async = false
+}
+
+func (q *PublishQueue) run() {
+	defer q.wg.Done()
+
+	for p := range q.queue {
+		n := len(p.opts)
+		err := p.publish(p.ctx, p.msg, append(p.opts[:n:n], publishSync)...)
+		if err == nil {
+			continue
+		}
+		if q.onError != nil {
+			q.onError(p.ctx, p.msg, err)
+			continue
+		}
+		log.Errorf("Failed to publish to %s: %v", p.msg.Topic(), err)
+	}
+}
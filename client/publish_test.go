@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+func TestPublishAll(t *testing.T) {
+	b := memory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	var mu sync.Mutex
+	var got int
+	if _, err := b.Subscribe("foo", func(broker.Event) error {
+		mu.Lock()
+		got++
+		mu.Unlock()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(Broker(b), ContentType("application/json"))
+
+	var msgs []Message
+	for i := 0; i < 20; i++ {
+		msgs = append(msgs, c.NewMessage("foo", map[string]int{"id": i}))
+	}
+	if err := PublishAll(context.TODO(), c, msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got != 20 {
+		t.Fatalf("Expected 20 messages, got %d", got)
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	errs := BatchError{nil, errors.InternalServerError("test", "failed"), nil}
+	if errs.Error() != "failed to publish 1 of 3 messages: "+errs[1].Error() {
+		t.Fatalf("Unexpected error %q", errs.Error())
+	}
+}
+
+func TestPublishQueue(t *testing.T) {
+	var mu sync.Mutex
+	var failed []Message
+
+	done := make(chan bool)
+	q := NewPublishQueue(1, 1, func(ctx context.Context, msg Message, err error) {
+		mu.Lock()
+		failed = append(failed, msg)
+		mu.Unlock()
+	})
+
+	// the first message blocks the worker so the queue fills up
+	publish := func(ctx context.Context, msg Message, opts ...PublishOption) error {
+		var options PublishOptions
+		for _, o := range opts {
+			o(&options)
+		}
+		if options.Async {
+			t.Fatal("Expected the queued message to be published synchronously")
+		}
+		if v, _ := metadata.Get(ctx, "Foo"); v != "bar" {
+			t.Fatalf("Expected the metadata of the publisher, got %q", v)
+		}
+		if msg.Topic() == "block" {
+			<-done
+		}
+		return errors.InternalServerError("test", "failed")
+	}
+
+	ctx, cancel := context.WithCancel(metadata.Set(context.TODO(), "Foo", "bar"))
+	if err := q.Enqueue(ctx, newMessage("block", nil, "application/json"), publish, PublishAsync()); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for the worker to take the first message
+	time.Sleep(10 * time.Millisecond)
+	if err := q.Enqueue(ctx, newMessage("foo", nil, "application/json"), publish, PublishAsync()); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(ctx, newMessage("foo", nil, "application/json"), publish); err != ErrPublishQueueFull {
+		t.Fatalf("Expected ErrPublishQueueFull, got %v", err)
+	}
+
+	// the messages are published once the publisher is gone
+	cancel()
+	close(done)
+	time.Sleep(10 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failed) != 2 {
+		t.Fatalf("Expected the error func to be called with 2 messages, got %d", len(failed))
+	}
+}
+
+func TestPublishAsync(t *testing.T) {
+	b := memory.NewBroker()
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	got := make(chan bool, 1)
+	if _, err := b.Subscribe("foo", func(broker.Event) error {
+		got <- true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(Broker(b), ContentType("application/json"), AsyncPublish(10, 1, nil))
+	if err := c.Publish(context.TODO(), c.NewMessage("foo", "bar"), PublishAsync()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the message to be published")
+	}
+}
+
+// batchBroker records the batches published
+type batchBroker struct {
+	broker.Broker
+
+	sync.Mutex
+	batches map[string]int
+}
+
+func (b *batchBroker) PublishBatch(topic string, msgs []*broker.Message, opts ...broker.PublishOption) error {
+	b.Lock()
+	defer b.Unlock()
+	b.batches[topic] += len(msgs)
+	return nil
+}
+
+func TestPublishAllBatch(t *testing.T) {
+	b := &batchBroker{Broker: memory.NewBroker(), batches: make(map[string]int)}
+	c := NewClient(Broker(b), ContentType("application/json"))
+
+	var msgs []Message
+	for i := 0; i < 10; i++ {
+		topic := "foo"
+		if i%2 == 0 {
+			topic = "bar"
+		}
+		msgs = append(msgs, c.NewMessage(topic, map[string]int{"id": i}))
+	}
+	if err := PublishAll(context.TODO(), c, msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	if b.batches["foo"] != 5 || b.batches["bar"] != 5 {
+		t.Fatalf("Expected a batch of 5 messages per topic, got %v", b.batches)
+	}
+}
+
+func TestPublishQueueClose(t *testing.T) {
+	var mu sync.Mutex
+	var published int
+
+	q := NewPublishQueue(10, 2, nil)
+	publish := func(ctx context.Context, msg Message, opts ...PublishOption) error {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		published++
+		mu.Unlock()
+		return nil
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := q.Enqueue(context.TODO(), newMessage("foo", nil, "application/json"), publish); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	if err := q.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if published != 10 {
+		t.Fatalf("Expected the queue to be drained, got %d messages published", published)
+	}
+	if err := q.Enqueue(context.TODO(), newMessage("foo", nil, "application/json"), publish); err != ErrPublishQueueClosed {
+		t.Fatalf("Expected ErrPublishQueueClosed, got %v", err)
+	}
+}
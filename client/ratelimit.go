@@ -0,0 +1,96 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrRateLimited is returned when a call is over the rate limit of the
+	// service or endpoint called
+	ErrRateLimited = errors.New("go.micro.client", "rate limited", 429)
+)
+
+// RateLimits are the token bucket limiters of the calls to services and endpoints
+type RateLimits struct {
+	sync.RWMutex
+	// limiters by service, or service and endpoint
+	limiters map[string]*rate.Limiter
+}
+
+// NewRateLimits returns rate limits without any limit
+func NewRateLimits() *RateLimits {
+	return &RateLimits{
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Set limits the calls to the endpoint of the service to rps per second with
+// bursts of up to burst calls. A blank endpoint limits all the calls to the
+// service, and a rps of zero removes the limit.
+func (r *RateLimits) Set(service, endpoint string, rps float64, burst int) {
+	k := service
+	if len(endpoint) > 0 {
+		k = service + "/" + endpoint
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if rps <= 0 {
+		delete(r.limiters, k)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	r.limiters[k] = rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// Allow takes a token of the limits of the service and the endpoint of the
+// request, returning ErrRateLimited if there's none left. A token is only
+// taken if both have one, so the calls denied don't use up the other limit.
+func (r *RateLimits) Allow(req Request) error {
+	if r == nil {
+		return nil
+	}
+
+	r.RLock()
+	service := r.limiters[req.Service()]
+	endpoint := r.limiters[req.Service()+"/"+req.Endpoint()]
+	r.RUnlock()
+
+	now := time.Now()
+	e, ok := reserve(endpoint, now)
+	if !ok {
+		return ErrRateLimited
+	}
+	if _, ok := reserve(service, now); !ok {
+		// refund the token of the endpoint
+		if e != nil {
+			e.CancelAt(now)
+		}
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// reserve takes a token of the limiter if one is available now, the limiter
+// may be nil for no limit
+func reserve(l *rate.Limiter, now time.Time) (*rate.Reservation, bool) {
+	if l == nil {
+		return nil, true
+	}
+	res := l.ReserveN(now, 1)
+	if !res.OK() {
+		return nil, false
+	}
+	if res.DelayFrom(now) > 0 {
+		res.CancelAt(now)
+		return nil, false
+	}
+	return res, true
+}
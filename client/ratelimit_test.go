@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router/static"
+)
+
+func TestRateLimits(t *testing.T) {
+	r := NewRateLimits()
+	r.Set("foo", "", 1, 3)
+	r.Set("bar", "Bar.Baz", 1, 1)
+
+	foo := newRequest("foo", "Foo.Bar", nil, "application/json")
+	for i := 0; i < 3; i++ {
+		if err := r.Allow(foo); err != nil {
+			t.Fatalf("Unexpected error of call %d in the burst: %v", i, err)
+		}
+	}
+	if err := r.Allow(foo); err != ErrRateLimited {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+
+	// only the endpoint of the service is limited
+	baz := newRequest("bar", "Bar.Baz", nil, "application/json")
+	other := newRequest("bar", "Bar.Qux", nil, "application/json")
+	if err := r.Allow(baz); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Allow(baz); err != ErrRateLimited {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := r.Allow(other); err != nil {
+			t.Fatalf("Unexpected error of an endpoint without limit: %v", err)
+		}
+	}
+
+	// the calls denied by the service don't use up the endpoint's tokens
+	r.Set("qux", "", 1, 1)
+	r.Set("qux", "Qux.Foo", 1, 2)
+	qux := newRequest("qux", "Qux.Foo", nil, "application/json")
+	if err := r.Allow(qux); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Allow(qux); err != ErrRateLimited {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	r.Set("qux", "", 0, 0)
+	if err := r.Allow(qux); err != nil {
+		t.Fatalf("Expected the token of the endpoint refunded, got %v", err)
+	}
+
+	// a zero rate removes the limit
+	r.Set("foo", "", 0, 0)
+	if err := r.Allow(foo); err != nil {
+		t.Fatal(err)
+	}
+
+	// no limits allow everything
+	var none *RateLimits
+	if err := none.Allow(foo); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitCall(t *testing.T) {
+	var calls int
+	c := NewClient(
+		Router(static.NewRouter()),
+		RateLimit("foo", 1, 2),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				calls++
+				return nil
+			}
+		}),
+	)
+
+	req := c.NewRequest("foo", "Foo.Bar", nil)
+	for i := 0; i < 2; i++ {
+		if err := c.Call(context.TODO(), req, nil); err != nil {
+			t.Fatalf("Unexpected error of call %d: %v", i, err)
+		}
+	}
+	if err := c.Call(context.TODO(), req, nil); err != ErrRateLimited {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls, got %d", calls)
+	}
+}
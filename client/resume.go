@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	merrors "github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+var (
+	// ResumeTokenHeader is the metadata key of the token a stream is resumed from
+	ResumeTokenHeader = "Micro-Resume-Token"
+	// DefaultMaxResumes is the max number of times a stream is resumed
+	DefaultMaxResumes = 10
+	// TransportErrorId is the id of the errors of the transport, the streams
+	// which broke with them are resumed
+	TransportErrorId = "go.micro.client.transport"
+)
+
+// ResumeFunc returns the token the server resumes a broken stream from e.g.
+// the offset of the last message received. It's sent in the metadata of the
+// reopened stream under ResumeTokenHeader.
+type ResumeFunc func(ctx context.Context, req Request) string
+
+// ResumeStream returns a stream which reopens itself when broken by an error of
+// the transport, e.g. a connection reset, if the call options set a resume func
+// with WithResume. It's reopened at most DefaultMaxResumes times. Sends and
+// receives failing on a broken stream are made again on the reopened stream.
+func ResumeStream(ctx context.Context, req Request, stream Stream, opts CallOptions, open func(ctx context.Context) (Stream, error)) Stream {
+	if opts.Resume == nil || opts.ResumeAttempts <= 0 {
+		return stream
+	}
+
+	return &resumeStream{
+		ctx:    ctx,
+		req:    req,
+		opts:   opts,
+		open:   open,
+		stream: stream,
+		done:   make(chan struct{}),
+	}
+}
+
+type resumeStream struct {
+	ctx  context.Context
+	req  Request
+	opts CallOptions
+	open func(ctx context.Context) (Stream, error)
+
+	sync.RWMutex
+	stream Stream
+	closed bool
+	// failed is true once the stream couldn't be reopened
+	failed bool
+	// resumes is the number of times the stream was reopened
+	resumes int
+	// resuming is closed once the stream being reopened is, nil if it isn't
+	resuming chan struct{}
+	// done is closed when the stream is
+	done chan struct{}
+}
+
+func (r *resumeStream) current() Stream {
+	r.RLock()
+	defer r.RUnlock()
+	return r.stream
+}
+
+func (r *resumeStream) Context() context.Context {
+	return r.current().Context()
+}
+
+func (r *resumeStream) Request() Request {
+	return r.req
+}
+
+func (r *resumeStream) Response() Response {
+	return r.current().Response()
+}
+
+func (r *resumeStream) Send(msg interface{}) error {
+	for {
+		stream := r.current()
+		err := stream.Send(msg)
+		if err == nil || !resumable(err) {
+			return err
+		}
+		if rerr := r.resume(stream, err); rerr != nil {
+			return rerr
+		}
+	}
+}
+
+func (r *resumeStream) Recv(msg interface{}) error {
+	for {
+		stream := r.current()
+		err := stream.Recv(msg)
+		if err == nil || !resumable(err) {
+			return err
+		}
+		if rerr := r.resume(stream, err); rerr != nil {
+			return rerr
+		}
+	}
+}
+
+func (r *resumeStream) Error() error {
+	return r.current().Error()
+}
+
+func (r *resumeStream) Close() error {
+	r.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.done)
+	}
+	stream := r.stream
+	r.Unlock()
+	return stream.Close()
+}
+
+// resumable returns whether the stream broke in the transport, rather than
+// ended or failed in the server
+func resumable(err error) bool {
+	if err == io.EOF {
+		return false
+	}
+	if err == io.ErrUnexpectedEOF || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		return true
+	}
+	merr, ok := err.(*merrors.Error)
+	return ok && merr.Id == TransportErrorId
+}
+
+// resume reopens the broken stream, unless it was already reopened or is
+// being reopened by a concurrent send or receive. It returns the error the
+// stream broke with once out of attempts or resumes. The lock isn't held
+// while reopening so the stream can be closed meanwhile.
+func (r *resumeStream) resume(broken Stream, err error) error {
+	r.Lock()
+	if r.closed || r.failed || r.ctx.Err() != nil {
+		r.Unlock()
+		return err
+	}
+	if r.stream != broken {
+		// reopened by a concurrent send or receive
+		r.Unlock()
+		return nil
+	}
+	if ch := r.resuming; ch != nil {
+		// being reopened by a concurrent send or receive
+		r.Unlock()
+		select {
+		case <-ch:
+			return nil
+		case <-r.done:
+			return err
+		}
+	}
+	if r.resumes >= DefaultMaxResumes {
+		r.Unlock()
+		return err
+	}
+	r.resumes++
+	ch := make(chan struct{})
+	r.resuming = ch
+	r.Unlock()
+
+	broken.Close()
+	stream, rerr := r.reopen(err)
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.resuming = nil
+	close(ch)
+
+	if rerr != nil {
+		r.failed = true
+		return rerr
+	}
+	if r.closed {
+		stream.Close()
+		return err
+	}
+	r.stream = stream
+	return nil
+}
+
+// reopen the stream, up to the resume attempts
+func (r *resumeStream) reopen(err error) (Stream, error) {
+	for i := 0; i < r.opts.ResumeAttempts; i++ {
+		if r.opts.Backoff != nil {
+			d, berr := r.opts.Backoff(r.ctx, r.req, i+1)
+			if berr != nil {
+				return nil, err
+			}
+			if d > 0 {
+				select {
+				case <-r.ctx.Done():
+					return nil, err
+				case <-r.done:
+					return nil, err
+				case <-time.After(d):
+				}
+			}
+		}
+
+		ReportRetry(r.ctx, r.opts, NewRetryEvent(r.req, RetryResume, i, err.Error()))
+
+		ctx := r.ctx
+		if token := r.opts.Resume(r.ctx, r.req); len(token) > 0 {
+			ctx = metadata.Set(ctx, ResumeTokenHeader, token)
+		}
+
+		stream, oerr := r.open(ctx)
+		if oerr == nil {
+			return stream, nil
+		}
+		log.Debugf("Failed to resume stream to %s %s: %v", r.req.Service(), r.req.Endpoint(), oerr)
+	}
+
+	return nil, err
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+// testStream receives the numbers from its offset, breaking after a few
+type testStream struct {
+	req    Request
+	offset int
+	breaks int
+
+	sync.Mutex
+	closed bool
+}
+
+func (t *testStream) Context() context.Context { return context.TODO() }
+func (t *testStream) Request() Request         { return t.req }
+func (t *testStream) Response() Response       { return nil }
+func (t *testStream) Send(interface{}) error   { return nil }
+func (t *testStream) Error() error             { return nil }
+
+func (t *testStream) Recv(msg interface{}) error {
+	t.Lock()
+	defer t.Unlock()
+	if t.closed {
+		return io.EOF
+	}
+	if t.offset == 10 {
+		return io.EOF
+	}
+	if t.breaks == 0 {
+		return errors.InternalServerError(TransportErrorId, "connection reset")
+	}
+	t.breaks--
+	*msg.(*int) = t.offset
+	t.offset++
+	return nil
+}
+
+func (t *testStream) Close() error {
+	t.Lock()
+	defer t.Unlock()
+	t.closed = true
+	return nil
+}
+
+func TestResumeStream(t *testing.T) {
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	var last, resumes int
+	opts := CallOptions{
+		Resume: func(ctx context.Context, req Request) string {
+			return strconv.Itoa(last + 1)
+		},
+		ResumeAttempts: 1,
+		RetryHooks: []RetryHook{func(ctx context.Context, e RetryEvent) {
+			if e.Type == RetryResume {
+				resumes++
+			}
+		}},
+	}
+
+	open := func(ctx context.Context) (Stream, error) {
+		// the stream is reopened from the token
+		token, ok := metadata.Get(ctx, ResumeTokenHeader)
+		if !ok {
+			t.Fatal("Expected the resume token in the metadata")
+		}
+		offset, _ := strconv.Atoi(token)
+		return &testStream{req: req, offset: offset, breaks: 3}, nil
+	}
+
+	stream := ResumeStream(context.TODO(), req, &testStream{req: req, breaks: 3}, opts, open)
+
+	var got []int
+	for {
+		var n int
+		err := stream.Recv(&n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, n)
+		last = n
+	}
+
+	if len(got) != 10 {
+		t.Fatalf("Expected to receive 10 numbers, got %v", got)
+	}
+	for i, n := range got {
+		if n != i {
+			t.Fatalf("Expected the numbers in order, got %v", got)
+		}
+	}
+	if resumes != 3 {
+		t.Fatalf("Expected 3 resumes, got %d", resumes)
+	}
+
+	// without a resume func the stream isn't wrapped
+	s := &testStream{req: req}
+	if ResumeStream(context.TODO(), req, s, CallOptions{}, open) != s {
+		t.Fatal("Expected the stream not to be wrapped")
+	}
+}
+
+func TestResumeStreamAttempts(t *testing.T) {
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	opts := CallOptions{
+		Resume:         func(ctx context.Context, req Request) string { return "" },
+		ResumeAttempts: 2,
+	}
+
+	var opens int
+	stream := ResumeStream(context.TODO(), req, &testStream{req: req}, opts, func(ctx context.Context) (Stream, error) {
+		opens++
+		return nil, errors.InternalServerError("test", "unavailable")
+	})
+
+	var n int
+	if err := stream.Recv(&n); err == nil {
+		t.Fatal("Expected the error of the broken stream")
+	}
+	if opens != 2 {
+		t.Fatalf("Expected 2 attempts to reopen the stream, got %d", opens)
+	}
+}
+
+func TestResumeStreamErrors(t *testing.T) {
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	var opens int
+	open := func(ctx context.Context) (Stream, error) {
+		opens++
+		return &testStream{req: req, breaks: 1}, nil
+	}
+	opts := CallOptions{
+		Resume:         func(ctx context.Context, req Request) string { return "" },
+		ResumeAttempts: 1,
+	}
+
+	// errors of the server aren't resumed
+	stream := ResumeStream(context.TODO(), req, &errStream{testStream{req: req}, errors.InternalServerError("test", "failed")}, opts, open)
+	var n int
+	if err := stream.Recv(&n); err == nil || opens != 0 {
+		t.Fatalf("Expected the error of the server without resuming, got %v after %d opens", err, opens)
+	}
+
+	// a stream is resumed at most DefaultMaxResumes times
+	stream = ResumeStream(context.TODO(), req, &testStream{req: req}, opts, open)
+	var err error
+	for err == nil {
+		err = stream.Recv(&n)
+	}
+	if err == io.EOF || opens != DefaultMaxResumes {
+		t.Fatalf("Expected the stream to break after %d resumes, got %v after %d", DefaultMaxResumes, err, opens)
+	}
+}
+
+func TestResumeStreamClose(t *testing.T) {
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	opts := CallOptions{
+		Resume:         func(ctx context.Context, req Request) string { return "" },
+		ResumeAttempts: 1,
+		Backoff: func(ctx context.Context, req Request, attempts int) (time.Duration, error) {
+			return time.Hour, nil
+		},
+	}
+
+	stream := ResumeStream(context.TODO(), req, &testStream{req: req}, opts, func(ctx context.Context) (Stream, error) {
+		return &testStream{req: req}, nil
+	})
+
+	errc := make(chan error, 1)
+	go func() {
+		var n int
+		errc <- stream.Recv(&n)
+	}()
+
+	// closing isn't blocked by the backoff, which it ends
+	time.Sleep(time.Millisecond * 50)
+	closed := make(chan struct{})
+	go func() {
+		stream.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected close not to wait for the backoff")
+	}
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("Expected the error of the broken stream")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the receive to end once closed")
+	}
+}
+
+// errStream fails with the error
+type errStream struct {
+	testStream
+	err error
+}
+
+func (e *errStream) Recv(msg interface{}) error {
+	return e.err
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/micro/go-micro/v2/errors"
 )
@@ -33,3 +34,114 @@ func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (
 		return false, nil
 	}
 }
+
+// RetryOn retries a request on the errors with the codes e.g. 408, 502, 503
+func RetryOn(codes ...int32) RetryFunc {
+	return func(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
+		if err == nil {
+			return false, nil
+		}
+
+		e := errors.FromError(err)
+		for _, code := range codes {
+			if e.Code == code {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// RetryIf retries a request on the errors fn returns true for
+func RetryIf(fn func(err error) bool) RetryFunc {
+	return func(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
+		if err == nil {
+			return false, nil
+		}
+		return fn(err), nil
+	}
+}
+
+// RetryEventType is the kind of retry decision reported to retry hooks
+type RetryEventType int
+
+const (
+	// RetryBackoff is reported before sleeping ahead of an attempt
+	RetryBackoff RetryEventType = iota
+	// RetryAttempt is reported when a failed attempt is going to be retried
+	RetryAttempt
+	// RetryGiveUp is reported when a failed call isn't retried any more
+	RetryGiveUp
+	// RetryHedge is reported when a hedged request is sent
+	RetryHedge
+	// RetryReject is reported when a circuit breaker rejects a call
+	RetryReject
+	// RetryResume is reported when a broken stream is reopened
+	RetryResume
+	// RetryFallback is reported when a failed call is answered by its fallback
+	RetryFallback
+)
+
+// String returns human readable retry event type
+func (t RetryEventType) String() string {
+	switch t {
+	case RetryBackoff:
+		return "backoff"
+	case RetryAttempt:
+		return "retry"
+	case RetryGiveUp:
+		return "give-up"
+	case RetryHedge:
+		return "hedge"
+	case RetryReject:
+		return "breaker-reject"
+	case RetryResume:
+		return "resume"
+	case RetryFallback:
+		return "fallback"
+	default:
+		return "unknown"
+	}
+}
+
+// RetryEvent is a retry decision made for a call
+type RetryEvent struct {
+	// Type of decision
+	Type RetryEventType
+	// Service called
+	Service string
+	// Endpoint called
+	Endpoint string
+	// Attempt is the zero based attempt the decision was made for
+	Attempt int
+	// Reason for the decision e.g. the error of the failed attempt
+	Reason string
+	// Backoff is how long the client sleeps before the attempt
+	Backoff time.Duration
+	// Timestamp of the decision
+	Timestamp time.Time
+}
+
+// RetryHook is called with every retry decision. Hooks are called inline
+// with the call so must not block e.g. increment a counter or write a log.
+type RetryHook func(ctx context.Context, e RetryEvent)
+
+// NewRetryEvent returns a retry event for an attempt of the request
+func NewRetryEvent(req Request, t RetryEventType, attempt int, reason string) RetryEvent {
+	return RetryEvent{
+		Type:      t,
+		Service:   req.Service(),
+		Endpoint:  req.Endpoint(),
+		Attempt:   attempt,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+}
+
+// ReportRetry calls the retry hooks of the call with the event. Call wrappers
+// which hedge requests or break circuits use it to report their decisions.
+func ReportRetry(ctx context.Context, opts CallOptions, e RetryEvent) {
+	for _, h := range opts.RetryHooks {
+		h(ctx, e)
+	}
+}
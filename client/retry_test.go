@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+func TestRetryOn(t *testing.T) {
+	fn := RetryOn(502, 503)
+	req := newRequest("foo", "Foo.Bar", nil, "application/json")
+
+	testData := []struct {
+		err   error
+		retry bool
+	}{
+		{nil, false},
+		{errors.ServiceUnavailable("foo", "unavailable"), true},
+		{errors.New("foo", "bad gateway", 502), true},
+		{errors.InternalServerError("foo", "failed"), false},
+		{fmt.Errorf("failed"), false},
+	}
+
+	for _, d := range testData {
+		retry, err := fn(context.TODO(), req, 0, d.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry != d.retry {
+			t.Fatalf("Expected retry %v for %v, got %v", d.retry, d.err, retry)
+		}
+	}
+
+	fn = RetryIf(func(err error) bool { return err.Error() == "retry" })
+	if retry, _ := fn(context.TODO(), req, 0, fmt.Errorf("retry")); !retry {
+		t.Fatal("Expected the error to be retried")
+	}
+	if retry, _ := fn(context.TODO(), req, 0, fmt.Errorf("failed")); retry {
+		t.Fatal("Expected the error not to be retried")
+	}
+}
@@ -3,6 +3,8 @@ package client
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/selector"
 	"github.com/micro/go-micro/v2/transport"
 	"github.com/micro/go-micro/v2/util/buf"
 	"github.com/micro/go-micro/v2/util/net"
@@ -24,22 +27,34 @@ type rpcClient struct {
 	opts Options
 	pool pool.Pool
 	seq  uint64
+	// the health check of the pooled connections
+	check atomic.Value
+
+	// pools of the services with their own pool size, replaced on every
+	// change so calls to the other services read them without locking
+	sync.Mutex
+	pools atomic.Value
+}
+
+// servicePool is the pool of a service with its own pool size
+type servicePool struct {
+	size int
+	pool pool.Pool
+	// calls using the pool, a replaced pool is closed once none is left
+	refs    int
+	retired bool
 }
 
 func newRpcClient(opt ...Option) Client {
 	opts := NewOptions(opt...)
 
-	p := pool.NewPool(
-		pool.Size(opts.PoolSize),
-		pool.TTL(opts.PoolTTL),
-		pool.Transport(opts.Transport),
-	)
-
 	rc := &rpcClient{
 		opts: opts,
-		pool: p,
 		seq:  0,
 	}
+	rc.check.Store(opts.PoolCheck)
+	rc.pool = rc.newPool(opts.PoolSize)
+	rc.pools.Store(map[string]*servicePool{})
 	rc.once.Store(false)
 
 	c := Client(rc)
@@ -62,6 +77,135 @@ func (r *rpcClient) newCodec(contentType string) (codec.NewCodec, error) {
 	return nil, fmt.Errorf("Unsupported Content-Type: %s", contentType)
 }
 
+// newPool returns a connection pool of the size configured with the options
+func (r *rpcClient) newPool(size int) pool.Pool {
+	return pool.NewPool(
+		pool.Size(size),
+		pool.TTL(r.opts.PoolTTL),
+		pool.IdleTimeout(r.opts.PoolIdleTimeout),
+		pool.HealthCheck(r.opts.PoolCheckInterval, r.checkConn),
+		pool.Transport(r.opts.Transport),
+	)
+}
+
+// checkConn checks the health of an idle connection with the current check
+// of the options, so the pools created already use it once changed
+func (r *rpcClient) checkConn(c pool.Conn) error {
+	check, _ := r.check.Load().(pool.CheckFunc)
+	if check == nil {
+		return nil
+	}
+	return check(c)
+}
+
+// PoolStats returns the stats of the connection pools of the client, false
+// if the client doesn't pool connections or is wrapped
+func PoolStats(c Client) (pool.Stats, bool) {
+	p, ok := c.(interface{ PoolStats() pool.Stats })
+	if !ok {
+		return pool.Stats{}, false
+	}
+	return p.PoolStats(), true
+}
+
+// PoolStats returns the stats of the connection pools of the client
+func (r *rpcClient) PoolStats() pool.Stats {
+	stats, _ := pool.ReadStats(r.pool)
+	for _, sp := range r.servicePools() {
+		s, ok := pool.ReadStats(sp.pool)
+		if !ok {
+			continue
+		}
+		stats.Idle += s.Idle
+		stats.InUse += s.InUse
+		stats.Dialed += s.Dialed
+		stats.Reused += s.Reused
+		stats.Evicted += s.Evicted
+		stats.Failed += s.Failed
+	}
+
+	return stats
+}
+
+// poolFor returns the connection pool of the service, which is the pool of
+// the client unless the pool size of the service is set
+func (r *rpcClient) poolFor(service string) (pool.Pool, func()) {
+	o, _ := r.opts.Services.Get(service)
+	shared := o.PoolSize <= 0 || o.PoolSize == r.opts.PoolSize
+
+	// most services use the pool of the client
+	if _, ok := r.servicePools()[service]; !ok && shared {
+		return r.pool, func() {}
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	pools := r.servicePools()
+	sp, ok := pools[service]
+	if ok && (shared || sp.size != o.PoolSize) {
+		// the pool is recreated if the size changed
+		r.retire(service)
+		ok = false
+	}
+	if shared {
+		return r.pool, func() {}
+	}
+	if !ok {
+		sp = &servicePool{
+			size: o.PoolSize,
+			pool: r.newPool(o.PoolSize),
+		}
+		r.updatePools(func(pools map[string]*servicePool) {
+			pools[service] = sp
+		})
+	}
+
+	sp.refs++
+	return sp.pool, func() {
+		r.Lock()
+		sp.refs--
+		if sp.retired && sp.refs == 0 {
+			sp.pool.Close()
+		}
+		r.Unlock()
+	}
+}
+
+// servicePools returns the pools of the services by name
+func (r *rpcClient) servicePools() map[string]*servicePool {
+	pools, _ := r.pools.Load().(map[string]*servicePool)
+	return pools
+}
+
+// updatePools replaces the pools of the services with a copy changed by fn.
+// Should be called under lock.
+func (r *rpcClient) updatePools(fn func(map[string]*servicePool)) {
+	old := r.servicePools()
+	pools := make(map[string]*servicePool, len(old)+1)
+	for k, v := range old {
+		pools[k] = v
+	}
+	fn(pools)
+	r.pools.Store(pools)
+}
+
+// retire removes the pool of the service, it's closed once the calls using
+// it released their connections. Should be called under lock.
+func (r *rpcClient) retire(service string) {
+	sp, ok := r.servicePools()[service]
+	if !ok {
+		return
+	}
+	r.updatePools(func(pools map[string]*servicePool) {
+		delete(pools, service)
+	})
+	sp.retired = true
+	if sp.refs == 0 {
+		sp.pool.Close()
+	}
+}
+
 func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request, resp interface{}, opts CallOptions) error {
 	msg := &transport.Message{
 		Header: make(map[string]string),
@@ -86,6 +230,9 @@ func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request,
 	// set the accept header
 	msg.Header["Accept"] = req.ContentType()
 
+	// negotiate the compression of the bodies
+	enc := negotiateCompression(msg, node, opts.Compression)
+
 	// setup old protocol
 	cf := setupProtocol(msg, node)
 
@@ -106,13 +253,16 @@ func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request,
 		dOpts = append(dOpts, transport.WithTimeout(opts.DialTimeout))
 	}
 
-	c, err := r.pool.Get(node.Address, dOpts...)
+	p, done := r.poolFor(req.Service())
+	c, err := p.Get(node.Address, dOpts...)
 	if err != nil {
+		done()
 		return errors.InternalServerError("go.micro.client", "connection error: %v", err)
 	}
+	release := r.opts.Metrics.Conn(req.Service(), node.Address)
 
 	seq := atomic.AddUint64(&r.seq, 1) - 1
-	codec := newRpcCodec(msg, c, cf, "")
+	codec := newRpcCodec(msg, c, cf, "", enc)
 
 	rsp := &rpcResponse{
 		socket: c,
@@ -126,8 +276,12 @@ func (r *rpcClient) call(ctx context.Context, node *registry.Node, req Request,
 		response: rsp,
 		codec:    codec,
 		closed:   make(chan bool),
-		release:  func(err error) { r.pool.Release(c, err) },
-		sendEOS:  false,
+		release: func(err error) {
+			p.Release(c, err)
+			release()
+			done()
+		},
+		sendEOS: false,
 	}
 	// close the stream on exiting this function
 	defer stream.Close()
@@ -200,6 +354,9 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 	// set the accept header
 	msg.Header["Accept"] = req.ContentType()
 
+	// negotiate the compression of the bodies
+	enc := negotiateCompression(msg, node, opts.Compression)
+
 	// set old codecs
 	cf := setupProtocol(msg, node)
 
@@ -230,7 +387,7 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 	id := fmt.Sprintf("%v", seq)
 
 	// create codec with stream id
-	codec := newRpcCodec(msg, c, cf, id)
+	codec := newRpcCodec(msg, c, cf, id, enc)
 
 	rsp := &rpcResponse{
 		socket: c,
@@ -290,22 +447,29 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 func (r *rpcClient) Init(opts ...Option) error {
 	size := r.opts.PoolSize
 	ttl := r.opts.PoolTTL
+	idle := r.opts.PoolIdleTimeout
+	interval := r.opts.PoolCheckInterval
 	tr := r.opts.Transport
 
 	for _, o := range opts {
 		o(&r.opts)
 	}
+	r.check.Store(r.opts.PoolCheck)
 
 	// update pool configuration if the options changed
-	if size != r.opts.PoolSize || ttl != r.opts.PoolTTL || tr != r.opts.Transport {
+	if size != r.opts.PoolSize || ttl != r.opts.PoolTTL || idle != r.opts.PoolIdleTimeout ||
+		interval != r.opts.PoolCheckInterval || tr != r.opts.Transport {
+		// retire the pools of the services, they're recreated on use
+		r.Lock()
+		for service := range r.servicePools() {
+			r.retire(service)
+		}
+		r.Unlock()
+
 		// close existing pool
 		r.pool.Close()
 		// create new pool
-		r.pool = pool.NewPool(
-			pool.Size(r.opts.PoolSize),
-			pool.TTL(r.opts.PoolTTL),
-			pool.Transport(r.opts.Transport),
-		)
+		r.pool = r.newPool(r.opts.PoolSize)
 	}
 
 	return nil
@@ -316,11 +480,20 @@ func (r *rpcClient) Options() Options {
 }
 
 func (r *rpcClient) Call(ctx context.Context, request Request, response interface{}, opts ...CallOption) error {
+	// answer the failed call with its fallback, if any
+	callOpts := r.opts.Services.CallOptions(request.Service(), r.opts.CallOptions, opts...)
+	return CallFallback(ctx, request, response, callOpts, func(response interface{}) error {
+		done := r.opts.Metrics.Start(request)
+		err := ChainUnary(r.invoke, r.opts.UnaryInterceptors...)(ctx, request, response, opts...)
+		done(err)
+		return err
+	})
+}
+
+// invoke makes the call at the end of the interceptors
+func (r *rpcClient) invoke(ctx context.Context, request Request, response interface{}, opts ...CallOption) error {
 	// make a copy of call opts
-	callOpts := r.opts.CallOptions
-	for _, opt := range opts {
-		opt(&callOpts)
-	}
+	callOpts := r.opts.Services.CallOptions(request.Service(), r.opts.CallOptions, opts...)
 
 	// check if we already have a deadline
 	if d, ok := ctx.Deadline(); !ok {
@@ -342,6 +515,14 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 	default:
 	}
 
+	// fail fast over the rate limit of the service
+	if err := r.opts.RateLimits.Allow(request); err != nil {
+		return err
+	}
+
+	// mirror the call to the services testing with it
+	MirrorCall(ctx, r, request, response, callOpts, r.opts.Mirrors)
+
 	// make copy of call method
 	rcall := r.call
 
@@ -350,6 +531,9 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 		rcall = callOpts.CallWrappers[i-1](rcall)
 	}
 
+	// track the routes attempted so retries go elsewhere
+	ctx = selector.NewAttemptsContext(ctx)
+
 	// return errors.New("go.micro.client", "request timeout", 408)
 	call := func(i int) error {
 		// call backoff first. Someone may want an initial start delay
@@ -360,6 +544,9 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 
 		// only sleep if greater than 0
 		if t.Seconds() > 0 {
+			e := NewRetryEvent(request, RetryBackoff, i, "")
+			e.Backoff = t
+			ReportRetry(ctx, callOpts, e)
 			time.Sleep(t)
 		}
 
@@ -372,22 +559,45 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 			callOpts.Selector = r.opts.Selector
 		}
 
-		// lookup the route to send the request via
-		route, err := LookupRoute(request, callOpts)
+		// check the circuit breaker before making the attempt
+		done, err := AllowCall(ctx, request, callOpts, i)
 		if err != nil {
 			return err
 		}
 
-		// pass a node to enable backwards comparability as changing the
-		// call func would be a breaking change.
-		// todo v3: change the call func to accept a route
-		node := &registry.Node{Address: route.Address, Metadata: route.Metadata}
+		// make the call, hedging it if the call options ask for it
+		err = Hedge(ctx, request, callOpts, i, response, func(ctx context.Context, response interface{}) error {
+			// lookup the route to send the request via
+			route, err := LookupRouteContext(ctx, request, callOpts)
+			if err != nil {
+				return err
+			}
 
-		// make the call
-		err = rcall(ctx, node, request, response, callOpts)
+			// pass a node to enable backwards comparability as changing the
+			// call func would be a breaking change.
+			// todo v3: change the call func to accept a route
+			node := &registry.Node{Address: route.Address, Metadata: route.Metadata}
 
-		// record the result of the call to inform future routing decisions
-		r.opts.Selector.Record(*route, err)
+			// make the call
+			start := time.Now()
+			err = rcall(ctx, node, request, response, callOpts)
+
+			// a hedge cancelled once another answered says nothing of the
+			// route, it's recorded so the selector releases its load
+			if err != nil && ctx.Err() == context.Canceled {
+				r.opts.Selector.Record(*route, selector.ErrCanceled)
+				return err
+			}
+
+			// record the result of the call to inform future routing decisions
+			latency := time.Since(start)
+			r.opts.Selector.Record(*route, err)
+			selector.Observe(r.opts.Selector, *route, latency, err)
+			callOpts.Router.Feedback(*route, latency, err)
+
+			return err
+		})
+		done(err)
 
 		return err
 	}
@@ -403,6 +613,9 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 	ch := make(chan error, retries+1)
 	var gerr error
 
+	// count the request against the retry budget
+	callOpts.RetryBudget.Deposit()
+
 	for i := 0; i <= retries; i++ {
 		go func(i int) {
 			ch <- call(i)
@@ -419,13 +632,24 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 
 			retry, rerr := callOpts.Retry(ctx, request, i, err)
 			if rerr != nil {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, rerr.Error()))
 				return rerr
 			}
 
 			if !retry {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, err.Error()))
 				return err
 			}
 
+			if i == retries {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, "retries exhausted: "+err.Error()))
+			} else if !callOpts.RetryBudget.Withdraw() {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, "retry budget exhausted: "+err.Error()))
+				return err
+			} else {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryAttempt, i, err.Error()))
+			}
+
 			gerr = err
 		}
 	}
@@ -434,12 +658,28 @@ func (r *rpcClient) Call(ctx context.Context, request Request, response interfac
 }
 
 func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOption) (Stream, error) {
-	// make a copy of call opts
-	callOpts := r.opts.CallOptions
-	for _, opt := range opts {
-		opt(&callOpts)
+	done := r.opts.Metrics.Start(request)
+	streamer := ChainStream(r.newStream, r.opts.StreamInterceptors...)
+	stream, err := streamer(ctx, request, opts...)
+	if err != nil {
+		done(err)
+		return nil, err
 	}
 
+	// reopen the stream through the interceptors if it breaks
+	callOpts := r.opts.Services.CallOptions(request.Service(), r.opts.CallOptions, opts...)
+	stream = ResumeStream(ctx, request, stream, callOpts, func(ctx context.Context) (Stream, error) {
+		return streamer(ctx, request, opts...)
+	})
+
+	return StreamDone(stream, done), nil
+}
+
+// newStream opens the stream at the end of the interceptors
+func (r *rpcClient) newStream(ctx context.Context, request Request, opts ...CallOption) (Stream, error) {
+	// make a copy of call opts
+	callOpts := r.opts.Services.CallOptions(request.Service(), r.opts.CallOptions, opts...)
+
 	// should we noop right here?
 	select {
 	case <-ctx.Done():
@@ -447,6 +687,14 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 	default:
 	}
 
+	// fail fast over the rate limit of the service
+	if err := r.opts.RateLimits.Allow(request); err != nil {
+		return nil, err
+	}
+
+	// track the routes attempted so retries go elsewhere
+	ctx = selector.NewAttemptsContext(ctx)
+
 	call := func(i int) (Stream, error) {
 		// call backoff first. Someone may want an initial start delay
 		t, err := callOpts.Backoff(ctx, request, i)
@@ -456,6 +704,9 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 
 		// only sleep if greater than 0
 		if t.Seconds() > 0 {
+			e := NewRetryEvent(request, RetryBackoff, i, "")
+			e.Backoff = t
+			ReportRetry(ctx, callOpts, e)
 			time.Sleep(t)
 		}
 
@@ -468,9 +719,16 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 			callOpts.Selector = r.opts.Selector
 		}
 
+		// check the circuit breaker before making the attempt
+		done, err := AllowCall(ctx, request, callOpts, i)
+		if err != nil {
+			return nil, err
+		}
+
 		// lookup the route to send the request via
-		route, err := LookupRoute(request, callOpts)
+		route, err := LookupRouteContext(ctx, request, callOpts)
 		if err != nil {
+			done(err)
 			return nil, err
 		}
 
@@ -480,10 +738,15 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 		node := &registry.Node{Address: route.Address, Metadata: route.Metadata}
 
 		// perform the call
+		start := time.Now()
 		stream, err := r.stream(ctx, node, request, callOpts)
 
 		// record the result of the call to inform future routing decisions
+		latency := time.Since(start)
 		r.opts.Selector.Record(*route, err)
+		selector.Observe(r.opts.Selector, *route, latency, err)
+		callOpts.Router.Feedback(*route, latency, err)
+		done(err)
 
 		return stream, err
 	}
@@ -504,6 +767,9 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 	ch := make(chan response, retries+1)
 	var grr error
 
+	// count the request against the retry budget
+	callOpts.RetryBudget.Deposit()
+
 	for i := 0; i <= retries; i++ {
 		go func(i int) {
 			s, err := call(i)
@@ -521,11 +787,22 @@ func (r *rpcClient) Stream(ctx context.Context, request Request, opts ...CallOpt
 
 			retry, rerr := callOpts.Retry(ctx, request, i, rsp.err)
 			if rerr != nil {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, rerr.Error()))
 				return nil, rerr
 			}
 
 			if !retry {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, rsp.err.Error()))
+				return nil, rsp.err
+			}
+
+			if i == retries {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, "retries exhausted: "+rsp.err.Error()))
+			} else if !callOpts.RetryBudget.Withdraw() {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryGiveUp, i, "retry budget exhausted: "+rsp.err.Error()))
 				return nil, rsp.err
+			} else {
+				ReportRetry(ctx, callOpts, NewRetryEvent(request, RetryAttempt, i, rsp.err.Error()))
 			}
 
 			grr = rsp.err
@@ -543,6 +820,11 @@ func (r *rpcClient) Publish(ctx context.Context, msg Message, opts ...PublishOpt
 		o(&options)
 	}
 
+	// queue the message to be published in the background
+	if options.Async {
+		return r.opts.PublishQueue.Enqueue(ctx, msg, r.Publish, opts...)
+	}
+
 	md, ok := metadata.FromContext(ctx)
 	if !ok {
 		md = make(map[string]string)
@@ -552,6 +834,10 @@ func (r *rpcClient) Publish(ctx context.Context, msg Message, opts ...PublishOpt
 	md["Content-Type"] = msg.ContentType()
 	md["Micro-Topic"] = msg.Topic()
 	md["Micro-Id"] = id
+	// the time of publishing, for subscribers to report their lag
+	if r.opts.Metrics != nil {
+		md["Micro-Timestamp"] = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
 
 	// set the topic
 	topic := msg.Topic()
@@ -598,7 +884,7 @@ func (r *rpcClient) Publish(ctx context.Context, msg Message, opts ...PublishOpt
 		r.once.Store(true)
 	}
 
-	return r.opts.Broker.Publish(topic, &broker.Message{
+	return PublishBroker(options, r.opts.Broker).Publish(topic, &broker.Message{
 		Header: md,
 		Body:   body,
 	}, broker.PublishContext(options.Context))
@@ -609,7 +895,11 @@ func (r *rpcClient) NewMessage(topic string, message interface{}, opts ...Messag
 }
 
 func (r *rpcClient) NewRequest(service, method string, request interface{}, reqOpts ...RequestOption) Request {
-	return newRequest(service, method, request, r.opts.ContentType, reqOpts...)
+	contentType := r.opts.ContentType
+	if o, ok := r.opts.Services.Get(service); ok && len(o.ContentType) > 0 {
+		contentType = o.ContentType
+	}
+	return newRequest(service, method, request, contentType, reqOpts...)
 }
 
 func (r *rpcClient) String() string {
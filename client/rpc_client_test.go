@@ -3,11 +3,15 @@ package client
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/util/pool"
 )
 
 func newTestRegistry() registry.Registry {
@@ -155,3 +159,101 @@ func TestCallWrapper(t *testing.T) {
 		t.Fatal("wrapper not called")
 	}
 }
+
+func TestCallRetryHooks(t *testing.T) {
+	var called int
+
+	wrap := func(cf CallFunc) CallFunc {
+		return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+			called++
+			return errors.InternalServerError("test.error", "retry request")
+		}
+	}
+
+	var events []RetryEvent
+	hook := func(ctx context.Context, e RetryEvent) {
+		events = append(events, e)
+	}
+
+	c := NewClient(
+		Router(router.NewRouter(router.Registry(newTestRegistry()))),
+		WrapCall(wrap),
+		Retries(2),
+		Retry(RetryOnError),
+		Backoff(func(ctx context.Context, req Request, attempts int) (time.Duration, error) {
+			return time.Duration(attempts) * time.Millisecond, nil
+		}),
+		RetryHooks(hook),
+	)
+
+	req := c.NewRequest("test.service", "Test.Endpoint", nil)
+	if err := c.Call(context.Background(), req, nil, WithAddress("10.1.10.1:8080")); err == nil {
+		t.Fatal("expected the call to fail")
+	}
+
+	expected := []RetryEventType{RetryAttempt, RetryBackoff, RetryAttempt, RetryBackoff, RetryGiveUp}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d retry events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, e := range events {
+		if e.Type != expected[i] {
+			t.Errorf("event %d: expected %s, got %s", i, expected[i], e.Type)
+		}
+		if e.Service != "test.service" || e.Endpoint != "Test.Endpoint" {
+			t.Errorf("event %d: unexpected request %s %s", i, e.Service, e.Endpoint)
+		}
+	}
+	if e := events[1]; e.Attempt != 1 || e.Backoff != time.Millisecond {
+		t.Errorf("expected a backoff of 1ms before attempt 1, got %v before %d", e.Backoff, e.Attempt)
+	}
+	if e := events[4]; e.Attempt != 2 || !strings.HasPrefix(e.Reason, "retries exhausted") {
+		t.Errorf("expected to give up after attempt 2, got %q after %d", e.Reason, e.Attempt)
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	c := NewClient(PoolIdleTimeout(time.Minute))
+
+	stats, ok := PoolStats(c)
+	if !ok {
+		t.Fatal("Expected the stats of the pool of the client")
+	}
+	if stats.Idle != 0 || stats.Dialed != 0 {
+		t.Fatalf("Expected an empty pool, got %+v", stats)
+	}
+
+	// the pool is recreated with the health check
+	if err := c.Init(PoolHealthCheck(time.Second, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := PoolStats(c); !ok {
+		t.Fatal("Expected the stats of the pool of the client")
+	}
+
+	if _, ok := PoolStats(NewClient(Wrap(func(c Client) Client { return struct{ Client }{c} }))); ok {
+		t.Fatal("Expected no stats of a wrapped client")
+	}
+}
+
+func TestPoolCheck(t *testing.T) {
+	r := NewClient().(*rpcClient)
+	if r.opts.PoolCheckInterval != DefaultPoolCheckInterval {
+		t.Fatalf("Expected the idle conns checked every %v, got %v", DefaultPoolCheckInterval, r.opts.PoolCheckInterval)
+	}
+	if err := r.checkConn(nil); err != nil {
+		t.Fatalf("Expected the conns healthy without a check, got %v", err)
+	}
+
+	// the pool created already checks the conns with the new check
+	p := r.pool
+	broken := fmt.Errorf("broken")
+	if err := r.Init(PoolHealthCheck(DefaultPoolCheckInterval, func(pool.Conn) error { return broken })); err != nil {
+		t.Fatal(err)
+	}
+	if r.pool != p {
+		t.Fatal("Expected the pool to be kept")
+	}
+	if err := r.checkConn(nil); err != broken {
+		t.Fatalf("Expected the new check, got %v", err)
+	}
+}
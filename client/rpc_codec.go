@@ -14,6 +14,7 @@ import (
 	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/transport"
+	"github.com/micro/go-micro/v2/util/compress"
 )
 
 const (
@@ -42,6 +43,9 @@ type rpcCodec struct {
 
 	// signify if its a stream
 	stream string
+
+	// compressor of the bodies sent, if any
+	enc compress.Compressor
 }
 
 type readWriteCloser struct {
@@ -151,7 +155,23 @@ func setupProtocol(msg *transport.Message, node *registry.Node) codec.NewCodec {
 	return defaultCodecs[msg.Header["Content-Type"]]
 }
 
-func newRpcCodec(req *transport.Message, client transport.Client, c codec.NewCodec, stream string) codec.Codec {
+// negotiateCompression asks for responses compressed with the encoding, and
+// returns the compressor of the requests if the node accepts the encoding
+func negotiateCompression(msg *transport.Message, node *registry.Node, encoding string) compress.Compressor {
+	if len(encoding) == 0 {
+		return nil
+	}
+
+	msg.Header[compress.AcceptHeader] = encoding
+
+	if !compress.Accepts(node.Metadata[compress.MetadataKey], encoding) {
+		return nil
+	}
+	c, _ := compress.Get(encoding)
+	return c
+}
+
+func newRpcCodec(req *transport.Message, client transport.Client, c codec.NewCodec, stream string, enc compress.Compressor) codec.Codec {
 	rwc := &readWriteCloser{
 		wbuf: bytes.NewBuffer(nil),
 		rbuf: bytes.NewBuffer(nil),
@@ -162,6 +182,7 @@ func newRpcCodec(req *transport.Message, client transport.Client, c codec.NewCod
 		codec:  c(rwc),
 		req:    req,
 		stream: stream,
+		enc:    enc,
 	}
 	return r
 }
@@ -203,6 +224,11 @@ func (c *rpcCodec) Write(m *codec.Message, body interface{}) error {
 		Body:   m.Body,
 	}
 
+	// compress the body if the server accepts it
+	if err := compress.Encode(&msg, c.enc); err != nil {
+		return errors.InternalServerError("go.micro.client.codec", err.Error())
+	}
+
 	// send the request
 	if err := c.client.Send(&msg); err != nil {
 		return errors.InternalServerError("go.micro.client.transport", err.Error())
@@ -219,6 +245,11 @@ func (c *rpcCodec) ReadHeader(m *codec.Message, r codec.MessageType) error {
 		return errors.InternalServerError("go.micro.client.transport", err.Error())
 	}
 
+	// decompress the body
+	if err := compress.Decode(&tm, 0); err != nil {
+		return errors.InternalServerError("go.micro.client.codec", err.Error())
+	}
+
 	c.buf.rbuf.Reset()
 	c.buf.rbuf.Write(tm.Body)
 
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ServiceOptions override the client options for the calls to a service
+type ServiceOptions struct {
+	// ContentType of the requests, which selects the codec
+	ContentType string
+	// PoolSize of the connections to each node of the service
+	PoolSize int
+	// CallOptions applied to every call to the service ahead of those
+	// passed to the call
+	CallOptions []CallOption
+}
+
+// ServiceOption sets an option of a service
+type ServiceOption func(o *ServiceOptions)
+
+// ServiceContentType sets the content type of the requests to the service
+func ServiceContentType(ct string) ServiceOption {
+	return func(o *ServiceOptions) {
+		o.ContentType = ct
+	}
+}
+
+// ServicePoolSize sets the size of the connection pool of the service
+func ServicePoolSize(size int) ServiceOption {
+	return func(o *ServiceOptions) {
+		o.PoolSize = size
+	}
+}
+
+// ServiceCallOptions adds call options to every call to the service e.g.
+// WithRequestTimeout or WithRetries
+func ServiceCallOptions(opts ...CallOption) ServiceOption {
+	return func(o *ServiceOptions) {
+		o.CallOptions = append(o.CallOptions, opts...)
+	}
+}
+
+// ServiceSettings are the options of a service as read from config e.g.
+// {"timeout": "2s", "retries": 3, "pool_size": 10, "content_type": "application/protobuf"}
+type ServiceSettings struct {
+	// Timeout of the requests e.g. 2s
+	Timeout string `json:"timeout"`
+	// DialTimeout of the connections e.g. 1s
+	DialTimeout string `json:"dial_timeout"`
+	// Retries of the calls, unset keeps those of the client
+	Retries *int `json:"retries"`
+	// PoolSize of the connections to each node
+	PoolSize int `json:"pool_size"`
+	// ContentType of the requests
+	ContentType string `json:"content_type"`
+	// Addresses of the service, called rather than using service discovery
+	Addresses []string `json:"addresses"`
+}
+
+// Options returns the service options of the settings, durations which
+// can't be parsed are ignored
+func (s ServiceSettings) Options() []ServiceOption {
+	var opts []ServiceOption
+
+	if d, err := time.ParseDuration(s.Timeout); err == nil {
+		opts = append(opts, ServiceCallOptions(WithRequestTimeout(d)))
+	}
+	if d, err := time.ParseDuration(s.DialTimeout); err == nil {
+		opts = append(opts, ServiceCallOptions(WithDialTimeout(d)))
+	}
+	if s.Retries != nil {
+		opts = append(opts, ServiceCallOptions(WithRetries(*s.Retries)))
+	}
+	if s.PoolSize > 0 {
+		opts = append(opts, ServicePoolSize(s.PoolSize))
+	}
+	if len(s.ContentType) > 0 {
+		opts = append(opts, ServiceContentType(s.ContentType))
+	}
+	if len(s.Addresses) > 0 {
+		opts = append(opts, ServiceCallOptions(WithAddress(s.Addresses...)))
+	}
+
+	return opts
+}
+
+// Services are the options of the services called, they can be updated while
+// calls are made e.g. as the config changes. The options set in code are
+// kept apart from those loaded from config, which override them, so loading
+// the config doesn't drop them.
+type Services struct {
+	sync.Mutex
+	// options set in code and loaded from config by service
+	set    map[string]ServiceOptions
+	loaded map[string]ServiceOptions
+
+	// the merged options by service, replaced on every change so calls
+	// read them without locking
+	services atomic.Value
+}
+
+// NewServices returns an empty set of service options
+func NewServices() *Services {
+	s := &Services{
+		set:    make(map[string]ServiceOptions),
+		loaded: make(map[string]ServiceOptions),
+	}
+	s.services.Store(map[string]ServiceOptions{})
+	return s
+}
+
+// Set the options of a service, replacing those set before
+func (s *Services) Set(service string, opts ...ServiceOption) {
+	var options ServiceOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	s.Lock()
+	s.set[service] = options
+	s.merge()
+	s.Unlock()
+}
+
+// Update the options of a service, keeping those set before
+func (s *Services) Update(service string, opts ...ServiceOption) {
+	s.Lock()
+	defer s.Unlock()
+
+	options := s.set[service]
+	// copy the call options so those of calls made meanwhile don't change
+	options.CallOptions = append([]CallOption(nil), options.CallOptions...)
+	for _, o := range opts {
+		o(&options)
+	}
+	s.set[service] = options
+	s.merge()
+}
+
+// Load replaces the options of the services loaded from config with the
+// settings, the options set in code are kept
+func (s *Services) Load(settings map[string]ServiceSettings) {
+	loaded := make(map[string]ServiceOptions, len(settings))
+	for name, set := range settings {
+		var options ServiceOptions
+		for _, o := range set.Options() {
+			o(&options)
+		}
+		loaded[name] = options
+	}
+
+	s.Lock()
+	s.loaded = loaded
+	s.merge()
+	s.Unlock()
+}
+
+// merge the options loaded from config into those set in code. Should be
+// called under lock.
+func (s *Services) merge() {
+	services := make(map[string]ServiceOptions, len(s.set)+len(s.loaded))
+	for name, o := range s.set {
+		services[name] = o
+	}
+
+	for name, l := range s.loaded {
+		o := services[name]
+		if len(l.ContentType) > 0 {
+			o.ContentType = l.ContentType
+		}
+		if l.PoolSize > 0 {
+			o.PoolSize = l.PoolSize
+		}
+		// the call options loaded are applied last so they take precedence
+		o.CallOptions = append(append([]CallOption(nil), o.CallOptions...), l.CallOptions...)
+		services[name] = o
+	}
+
+	s.services.Store(services)
+}
+
+// Get the options of a service
+func (s *Services) Get(service string) (ServiceOptions, bool) {
+	if s == nil {
+		return ServiceOptions{}, false
+	}
+
+	services, _ := s.services.Load().(map[string]ServiceOptions)
+	opts, ok := services[service]
+	return opts, ok
+}
+
+// CallOptions returns the call options of the client overridden by those of
+// the service and then the call
+func (s *Services) CallOptions(service string, callOpts CallOptions, opts ...CallOption) CallOptions {
+	if o, ok := s.Get(service); ok {
+		for _, opt := range o.CallOptions {
+			opt(&callOpts)
+		}
+	}
+	for _, opt := range opts {
+		opt(&callOpts)
+	}
+	return callOpts
+}
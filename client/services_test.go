@@ -0,0 +1,116 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServices(t *testing.T) {
+	c := NewClient(
+		ContentType("application/json"),
+		RequestTimeout(time.Second),
+		ServiceConfig("foo",
+			ServiceContentType("application/protobuf"),
+			ServicePoolSize(5),
+			ServiceCallOptions(WithRetries(5), WithRequestTimeout(2*time.Second)),
+		),
+	)
+	opts := c.Options()
+
+	// the options of the service override those of the client
+	callOpts := opts.Services.CallOptions("foo", opts.CallOptions)
+	if callOpts.Retries != 5 || callOpts.RequestTimeout != 2*time.Second {
+		t.Fatalf("Expected the options of the service, got %d retries and %v timeout", callOpts.Retries, callOpts.RequestTimeout)
+	}
+
+	// and those of the call override both
+	callOpts = opts.Services.CallOptions("foo", opts.CallOptions, WithRetries(0))
+	if callOpts.Retries != 0 || callOpts.RequestTimeout != 2*time.Second {
+		t.Fatalf("Expected the options of the call, got %d retries and %v timeout", callOpts.Retries, callOpts.RequestTimeout)
+	}
+
+	// other services get the options of the client
+	callOpts = opts.Services.CallOptions("bar", opts.CallOptions)
+	if callOpts.Retries != DefaultRetries || callOpts.RequestTimeout != time.Second {
+		t.Fatalf("Expected the options of the client, got %d retries and %v timeout", callOpts.Retries, callOpts.RequestTimeout)
+	}
+
+	if ct := c.NewRequest("foo", "Foo.Bar", nil).ContentType(); ct != "application/protobuf" {
+		t.Fatalf("Expected the content type of the service, got %s", ct)
+	}
+	if ct := c.NewRequest("bar", "Bar.Baz", nil).ContentType(); ct != "application/json" {
+		t.Fatalf("Expected the content type of the client, got %s", ct)
+	}
+
+	// the service has its own pool
+	r := c.(*rpcClient)
+	if p, _ := r.poolFor("foo"); p == r.pool {
+		t.Fatal("Expected the service to have its own pool")
+	}
+	if p, _ := r.poolFor("bar"); p != r.pool {
+		t.Fatal("Expected the service to use the pool of the client")
+	}
+
+	// loading settings overrides the options set in code, which are kept
+	retries := 1
+	opts.Services.Load(map[string]ServiceSettings{
+		"foo": {Retries: &retries},
+		"bar": {Timeout: "3s", Retries: &retries, ContentType: "application/grpc+json"},
+	})
+
+	callOpts = opts.Services.CallOptions("foo", opts.CallOptions)
+	if callOpts.Retries != 1 || callOpts.RequestTimeout != 2*time.Second {
+		t.Fatalf("Expected the loaded options merged, got %d retries and %v timeout", callOpts.Retries, callOpts.RequestTimeout)
+	}
+	if ct := c.NewRequest("foo", "Foo.Bar", nil).ContentType(); ct != "application/protobuf" {
+		t.Fatalf("Expected the content type set in code, got %s", ct)
+	}
+	callOpts = opts.Services.CallOptions("bar", opts.CallOptions)
+	if callOpts.Retries != 1 || callOpts.RequestTimeout != 3*time.Second {
+		t.Fatalf("Expected the loaded options, got %d retries and %v timeout", callOpts.Retries, callOpts.RequestTimeout)
+	}
+	if ct := c.NewRequest("bar", "Bar.Baz", nil).ContentType(); ct != "application/grpc+json" {
+		t.Fatalf("Expected the loaded content type, got %s", ct)
+	}
+
+	// reloading drops the options loaded before
+	opts.Services.Load(nil)
+	if _, ok := opts.Services.Get("bar"); ok {
+		t.Fatal("Expected the loaded options of bar to be removed")
+	}
+	if _, ok := opts.Services.Get("foo"); !ok {
+		t.Fatal("Expected the options of foo set in code to be kept")
+	}
+}
+
+func TestServicePoolRetire(t *testing.T) {
+	c := NewClient(ServiceConfig("foo", ServicePoolSize(5)))
+	r := c.(*rpcClient)
+
+	p, done := r.poolFor("foo")
+	sp := r.servicePools()["foo"]
+
+	// the pool in use is replaced once the size changes, but not closed
+	c.Options().Services.Set("foo", ServicePoolSize(10))
+	if np, ndone := r.poolFor("foo"); np == p {
+		t.Fatal("Expected the pool to be replaced")
+	} else {
+		ndone()
+	}
+
+	r.Lock()
+	retired, refs := sp.retired, sp.refs
+	r.Unlock()
+	if !retired || refs != 1 {
+		t.Fatalf("Expected the replaced pool to be retired while in use, got retired %v with %d calls", retired, refs)
+	}
+
+	// and closed once released
+	done()
+	r.Lock()
+	refs = sp.refs
+	r.Unlock()
+	if refs != 0 {
+		t.Fatalf("Expected no calls using the retired pool, got %d", refs)
+	}
+}
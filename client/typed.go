@@ -0,0 +1,34 @@
+//go:build go1.18
+
+package client
+
+import (
+	"context"
+	"reflect"
+)
+
+// TypedCall calls the endpoint of the service with the request, returning
+// the response of its type, for callers without generated clients e.g.
+// gateways and tests. The response type can be a struct or a pointer to
+// one, which is allocated.
+//
+//	rsp, err := client.TypedCall[*pb.Request, *pb.Response](ctx, c, "go.micro.service.greeter", "Say.Hello", req)
+func TypedCall[TReq, TRsp any](ctx context.Context, c Client, service, endpoint string, req TReq, opts ...CallOption) (TRsp, error) {
+	var rsp TRsp
+
+	// allocate the value of a pointer response
+	out := interface{}(&rsp)
+	if t := reflect.TypeOf(rsp); t != nil && t.Kind() == reflect.Ptr {
+		v := reflect.New(t.Elem())
+		rsp = v.Interface().(TRsp)
+		out = rsp
+	}
+
+	request := c.NewRequest(service, endpoint, req)
+	if err := c.Call(ctx, request, out, opts...); err != nil {
+		var zero TRsp
+		return zero, err
+	}
+
+	return rsp, nil
+}
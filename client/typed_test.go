@@ -0,0 +1,61 @@
+//go:build go1.18
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router/static"
+)
+
+type typedRequest struct {
+	Name string
+}
+
+type typedResponse struct {
+	Greeting string
+}
+
+func TestTypedCall(t *testing.T) {
+	c := NewClient(
+		Router(static.NewRouter()),
+		Retries(0),
+		WrapCall(func(CallFunc) CallFunc {
+			return func(ctx context.Context, node *registry.Node, req Request, rsp interface{}, opts CallOptions) error {
+				name := req.Body().(*typedRequest).Name
+				if len(name) == 0 {
+					return errors.BadRequest("greeter", "missing name")
+				}
+				rsp.(*typedResponse).Greeting = "Hello " + name
+				return nil
+			}
+		}),
+	)
+
+	// a pointer response is allocated
+	rsp, err := TypedCall[*typedRequest, *typedResponse](context.TODO(), c, "greeter", "Say.Hello", &typedRequest{"John"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Greeting != "Hello John" {
+		t.Fatalf("Expected the greeting, got %q", rsp.Greeting)
+	}
+
+	// a struct response is set
+	val, err := TypedCall[*typedRequest, typedResponse](context.TODO(), c, "greeter", "Say.Hello", &typedRequest{"Jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.Greeting != "Hello Jane" {
+		t.Fatalf("Expected the greeting, got %q", val.Greeting)
+	}
+
+	// the zero response is returned with the error
+	rsp, err = TypedCall[*typedRequest, *typedResponse](context.TODO(), c, "greeter", "Say.Hello", &typedRequest{})
+	if err == nil || rsp != nil {
+		t.Fatalf("Expected an error without a response, got %v and %v", err, rsp)
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"math/rand"
 
 	"github.com/micro/go-micro/v2/errors"
@@ -12,16 +13,65 @@ import (
 // LookupRoute for a request using the router and then choose one using the selector
 func LookupRoute(req Request, opts CallOptions) (*router.Route, error) {
 	// check to see if the proxy has been set, if it has we don't need to lookup the routes; net.Proxy
-	// returns a slice of addresses, so we'll use a random one. Eventually we should to use the
-	// selector for this.
+	// returns a slice of addresses, which are also those set with WithAddress.
 	service, addresses, _ := pnet.Proxy(req.Service(), opts.Address)
 	if len(addresses) > 0 {
-		return &router.Route{
-			Service: service,
-			Address: addresses[rand.Int()%len(addresses)],
-		}, nil
+		return selectAddress(req, service, addresses, opts)
 	}
 
+	routes, err := lookupRoutes(req, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectRoute(req, routes, opts)
+}
+
+// selectRoute selects the route to use for the request
+func selectRoute(req Request, routes []router.Route, opts CallOptions) (*router.Route, error) {
+	if route, err := opts.Selector.Select(routes, opts.SelectOptions...); err == selector.ErrNoneAvailable {
+		return nil, errors.InternalServerError("go.micro.client", "service %s: %s", req.Service(), err.Error())
+	} else if err != nil {
+		return nil, errors.InternalServerError("go.micro.client", "error getting next %s node: %s", req.Service(), err.Error())
+	} else {
+		return route, nil
+	}
+}
+
+// selectAddress selects one of the addresses which aren't dead, or any of them
+// if they all are, using the selector
+func selectAddress(req Request, service string, addresses []string, opts CallOptions) (*router.Route, error) {
+	// only the static addresses are probed, the others are healthy
+	if opts.Prober != nil {
+		if healthy := opts.Prober.Healthy(addresses); len(healthy) > 0 {
+			addresses = healthy
+		}
+	}
+
+	routes := make([]router.Route, len(addresses))
+	for i, a := range addresses {
+		routes[i] = router.Route{Service: service, Address: a}
+	}
+
+	if opts.Selector == nil {
+		return &routes[rand.Int()%len(routes)], nil
+	}
+	return selectRoute(req, routes, opts)
+}
+
+// ExplainRoute explains how the selector would choose the route for the request
+// among the routes found by the router, without sending the request
+func ExplainRoute(req Request, opts CallOptions) (*selector.Explanation, error) {
+	routes, err := lookupRoutes(req, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return selector.Explain(opts.Selector, routes, opts.SelectOptions...)
+}
+
+// lookupRoutes returns the routes which can be used to execute the request
+func lookupRoutes(req Request, opts CallOptions) ([]router.Route, error) {
 	// construct the router query
 	query := []router.QueryOption{
 		router.QueryService(req.Service()),
@@ -43,12 +93,25 @@ func LookupRoute(req Request, opts CallOptions) (*router.Route, error) {
 		return nil, errors.InternalServerError("go.micro.client", "error getting next %s node: %s", req.Service(), err.Error())
 	}
 
-	// select the route to use for the request
-	if route, err := opts.Selector.Select(routes, opts.SelectOptions...); err == selector.ErrNoneAvailable {
-		return nil, errors.InternalServerError("go.micro.client", "service %s: %s", req.Service(), err.Error())
-	} else if err != nil {
-		return nil, errors.InternalServerError("go.micro.client", "error getting next %s node: %s", req.Service(), err.Error())
-	} else {
-		return route, nil
+	return routes, nil
+}
+
+// LookupRouteContext looks up the route for a request like LookupRoute but excludes routes
+// already attempted by earlier retries of the request, tracked by the selector in the context.
+// The context is passed to the selector so it can select the route based on the request.
+func LookupRouteContext(ctx context.Context, req Request, opts CallOptions) (*router.Route, error) {
+	// copy the select options so the filter isn't added to the callers slice
+	n := len(opts.SelectOptions)
+	opts.SelectOptions = append(opts.SelectOptions[:n:n],
+		selector.WithFilter(selector.ExcludeAttempted(ctx)),
+		selector.WithContext(ctx),
+	)
+
+	route, err := LookupRoute(req, opts)
+	if err != nil {
+		return nil, err
 	}
+
+	selector.Attempt(ctx, *route)
+	return route, nil
 }
@@ -4,6 +4,7 @@ package cmd
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -87,6 +88,26 @@ var (
 			EnvVars: []string{"MICRO_CLIENT_POOL_TTL"},
 			Usage:   "Sets the client connection pool ttl. e.g 500ms, 5s, 1m. Default: 1m",
 		},
+		&cli.IntFlag{
+			Name:    "client_pool_max_idle",
+			EnvVars: []string{"MICRO_CLIENT_POOL_MAX_IDLE"},
+			Usage:   "Sets the max idle connections per host of the grpc client. Default: 50",
+		},
+		&cli.StringFlag{
+			Name:    "client_max_conn_age",
+			EnvVars: []string{"MICRO_CLIENT_MAX_CONN_AGE"},
+			Usage:   "Sets the max age of the connections of the grpc client. e.g 5m, 1h. Default: the pool ttl",
+		},
+		&cli.StringFlag{
+			Name:    "client_keepalive_time",
+			EnvVars: []string{"MICRO_CLIENT_KEEPALIVE_TIME"},
+			Usage:   "Sets how long a connection of the grpc client is idle before it's pinged. e.g 30s, 1m",
+		},
+		&cli.StringFlag{
+			Name:    "client_keepalive_timeout",
+			EnvVars: []string{"MICRO_CLIENT_KEEPALIVE_TIMEOUT"},
+			Usage:   "Sets how long the grpc client waits for a keepalive ping ack. e.g 10s. Default: 20s",
+		},
 		&cli.IntFlag{
 			Name:    "register_ttl",
 			EnvVars: []string{"MICRO_REGISTER_TTL"},
@@ -99,6 +120,21 @@ var (
 			Value:   30,
 			Usage:   "Register interval in seconds",
 		},
+		&cli.IntFlag{
+			Name:    "register_jitter",
+			EnvVars: []string{"MICRO_REGISTER_JITTER"},
+			Usage:   "Max random jitter added to the register interval in seconds",
+		},
+		&cli.Float64Flag{
+			Name:    "register_limit",
+			EnvVars: []string{"MICRO_REGISTER_LIMIT"},
+			Usage:   "Max registry writes per second made by the server, 0 is unlimited",
+		},
+		&cli.IntFlag{
+			Name:    "register_burst",
+			EnvVars: []string{"MICRO_REGISTER_BURST"},
+			Usage:   "Number of registry writes the server can make at once when limited",
+		},
 		&cli.StringFlag{
 			Name:    "server",
 			EnvVars: []string{"MICRO_SERVER"},
@@ -124,6 +160,16 @@ var (
 			EnvVars: []string{"MICRO_SERVER_ADDRESS"},
 			Usage:   "Bind address for the server. 127.0.0.1:8080",
 		},
+		&cli.StringSliceFlag{
+			Name:    "server_listen",
+			EnvVars: []string{"MICRO_SERVER_LISTEN"},
+			Usage:   "Addresses the server listens on as well as the server_address. unix:///var/run/service.sock",
+		},
+		&cli.StringSliceFlag{
+			Name:    "server_interceptors",
+			EnvVars: []string{"MICRO_SERVER_INTERCEPTORS"},
+			Usage:   "Names of the interceptors of the server requests and streams, in the order they're called",
+		},
 		&cli.StringFlag{
 			Name:    "server_advertise",
 			EnvVars: []string{"MICRO_SERVER_ADVERTISE"},
@@ -138,7 +184,7 @@ var (
 		&cli.StringFlag{
 			Name:    "broker",
 			EnvVars: []string{"MICRO_BROKER"},
-			Usage:   "Broker for pub/sub. http, nats, rabbitmq",
+			Usage:   "Broker for pub/sub. http, nats, redisstream, gcppubsub, rabbitmq, pulsar",
 		},
 		&cli.StringFlag{
 			Name:    "broker_address",
@@ -160,15 +206,25 @@ var (
 			Usage:   "Client key for TLS with broker",
 			EnvVars: []string{"MICRO_BROKER_TLS_KEY"},
 		},
+		&cli.BoolFlag{
+			Name:    "broker_auth",
+			EnvVars: []string{"MICRO_BROKER_AUTH"},
+			Usage:   "Verify the service has access to the topics it publishes and subscribes to",
+		},
 		&cli.StringFlag{
 			Name:    "profile",
 			Usage:   "Debug profiler for cpu and memory stats",
 			EnvVars: []string{"MICRO_DEBUG_PROFILE"},
 		},
+		&cli.StringFlag{
+			Name:    "log_levels",
+			Usage:   "Log levels of modules e.g. router=debug,client=warn",
+			EnvVars: []string{"MICRO_LOG_LEVELS"},
+		},
 		&cli.StringFlag{
 			Name:    "registry",
 			EnvVars: []string{"MICRO_REGISTRY"},
-			Usage:   "Registry for discovery. etcd, mdns",
+			Usage:   "Registry for discovery. etcd, mdns, nats, nacos",
 		},
 		&cli.StringFlag{
 			Name:    "registry_address",
@@ -201,6 +257,21 @@ var (
 			EnvVars: []string{"MICRO_RUNTIME_SOURCE"},
 			Value:   "github.com/micro/services",
 		},
+		&cli.StringFlag{
+			Name:    "runtime_verify",
+			Usage:   "Runtime artifact verification mode e.g none, permissive, enforcing",
+			EnvVars: []string{"MICRO_RUNTIME_VERIFY"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "runtime_verify_keys",
+			Usage:   "Public keys trusted to sign runtime artifacts (base64 encoded ed25519)",
+			EnvVars: []string{"MICRO_RUNTIME_VERIFY_KEYS"},
+		},
+		&cli.StringFlag{
+			Name:    "runtime_ports",
+			Usage:   "Range of ports the local runtime allocates to services e.g 10000-11000. Defaults to any free port",
+			EnvVars: []string{"MICRO_RUNTIME_PORTS"},
+		},
 		&cli.StringFlag{
 			Name:    "selector",
 			EnvVars: []string{"MICRO_SELECTOR"},
@@ -236,6 +307,27 @@ var (
 			EnvVars: []string{"MICRO_TRANSPORT_ADDRESS"},
 			Usage:   "Comma-separated list of transport addresses",
 		},
+		&cli.BoolFlag{
+			Name:    "transport_reuse_port",
+			EnvVars: []string{"MICRO_TRANSPORT_REUSE_PORT"},
+			Usage:   "Set SO_REUSEPORT so several instances can listen on the same port",
+		},
+		&cli.IntFlag{
+			Name:    "transport_keepalive",
+			EnvVars: []string{"MICRO_TRANSPORT_KEEPALIVE"},
+			Usage:   "TCP keep alive period of the connections in seconds, negative to disable",
+		},
+		&cli.BoolFlag{
+			Name:    "transport_no_delay",
+			EnvVars: []string{"MICRO_TRANSPORT_NO_DELAY"},
+			Value:   true,
+			Usage:   "Set TCP_NODELAY on the connections, false to coalesce small writes",
+		},
+		&cli.IntFlag{
+			Name:    "transport_backlog",
+			EnvVars: []string{"MICRO_TRANSPORT_BACKLOG"},
+			Usage:   "Size of the queue of the connections pending accept",
+		},
 		&cli.StringFlag{
 			Name:    "tracer",
 			EnvVars: []string{"MICRO_TRACER"},
@@ -282,6 +374,16 @@ var (
 			EnvVars: []string{"MICRO_AUTH_PRIVATE_KEY"},
 			Usage:   "Private key for JWT auth (base64 encoded PEM)",
 		},
+		&cli.StringSliceFlag{
+			Name:    "auth_verification_keys",
+			EnvVars: []string{"MICRO_AUTH_VERIFICATION_KEYS"},
+			Usage:   "Previous public keys still accepted for JWT auth after a key rotation (base64 encoded PEM)",
+		},
+		&cli.StringSliceFlag{
+			Name:    "auth_break_glass",
+			EnvVars: []string{"MICRO_AUTH_BREAK_GLASS"},
+			Usage:   "Emergency accounts which can login when the auth service is unreachable (id:bcrypt hash)",
+		},
 		&cli.StringFlag{
 			Name:    "config",
 			EnvVars: []string{"MICRO_CONFIG"},
@@ -297,6 +399,41 @@ var (
 			Usage:   "Comma-separated list of router addresses",
 			EnvVars: []string{"MICRO_ROUTER_ADDRESS"},
 		},
+		&cli.StringFlag{
+			Name:    "router_advertise_interval",
+			EnvVars: []string{"MICRO_ROUTER_ADVERTISE_INTERVAL"},
+			Usage:   "Sets how often the router advertises route updates. e.g 500ms, 5s, 1m. Default: 10s",
+		},
+		&cli.StringFlag{
+			Name:    "router_advert_ttl",
+			EnvVars: []string{"MICRO_ROUTER_ADVERT_TTL"},
+			Usage:   "Sets the TTL of the router adverts. e.g 30s, 2m. Default: 2m",
+		},
+		&cli.Float64Flag{
+			Name:    "router_flap_penalty",
+			EnvVars: []string{"MICRO_ROUTER_FLAP_PENALTY"},
+			Usage:   "Penalty added to a route for every change, enables route flap damping",
+		},
+		&cli.StringFlag{
+			Name:    "router_flap_half_life",
+			EnvVars: []string{"MICRO_ROUTER_FLAP_HALF_LIFE"},
+			Usage:   "Sets the time it takes for a route flap penalty to halve. Default: 30s",
+		},
+		&cli.Float64Flag{
+			Name:    "router_flap_suppress",
+			EnvVars: []string{"MICRO_ROUTER_FLAP_SUPPRESS"},
+			Usage:   "Flap penalty over which a route is no longer advertised. Default: 200",
+		},
+		&cli.Float64Flag{
+			Name:    "router_flap_recover",
+			EnvVars: []string{"MICRO_ROUTER_FLAP_RECOVER"},
+			Usage:   "Flap penalty under which a suppressed route is advertised again. Default: 20",
+		},
+		&cli.StringFlag{
+			Name:    "router_flap_max_suppress",
+			EnvVars: []string{"MICRO_ROUTER_FLAP_MAX_SUPPRESS"},
+			Usage:   "Sets the max time a flapping route is suppressed for. Default: 90s",
+		},
 	}
 
 	DefaultBrokers = map[string]func(...broker.Option) broker.Broker{}
@@ -324,6 +461,10 @@ var (
 	DefaultProfiles = map[string]func(...profile.Option) profile.Profile{}
 
 	DefaultConfigs = map[string]func(...config.Option) (config.Config, error){}
+
+	DefaultInterceptors = map[string]server.UnaryInterceptor{}
+
+	DefaultStreamInterceptors = map[string]server.StreamInterceptor{}
 )
 
 func init() {
@@ -359,6 +500,9 @@ func newCmd(opts ...Option) Cmd {
 		Auths:      DefaultAuths,
 		Profiles:   DefaultProfiles,
 		Configs:    DefaultConfigs,
+
+		Interceptors:       DefaultInterceptors,
+		StreamInterceptors: DefaultStreamInterceptors,
 	}
 
 	for _, o := range opts {
@@ -380,6 +524,10 @@ func newCmd(opts ...Option) Cmd {
 	cmd.app.Action = func(c *cli.Context) error {
 		return nil
 	}
+	cmd.app.Commands = []*cli.Command{
+		tool(NewCommand()),
+		tool(StoreCommand()),
+	}
 
 	if len(options.Version) == 0 {
 		cmd.app.HideVersion = true
@@ -397,6 +545,18 @@ func (c *cmd) Options() Options {
 }
 
 func (c *cmd) Before(ctx *cli.Context) error {
+	// Record the flags in effect
+	setFlags(ctx)
+
+	// Set the log levels of the modules
+	if l := ctx.String("log_levels"); len(l) > 0 {
+		levels, err := logger.ParseLevels(l)
+		if err != nil {
+			logger.Fatalf("Error parsing log_levels: %v", err)
+		}
+		logger.SetModuleLevels(levels)
+	}
+
 	// Setup client options
 	var clientOpts []client.Option
 
@@ -424,6 +584,34 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		clientOpts = append(clientOpts, client.PoolTTL(d))
 	}
 
+	if r := ctx.Int("client_pool_max_idle"); r > 0 {
+		clientOpts = append(clientOpts, grpc.PoolMaxIdle(r))
+	}
+
+	if t := ctx.String("client_max_conn_age"); len(t) > 0 {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			logger.Fatalf("failed to parse client_max_conn_age: %v", t)
+		}
+		clientOpts = append(clientOpts, grpc.MaxConnectionAge(d))
+	}
+
+	if t := ctx.String("client_keepalive_time"); len(t) > 0 {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			logger.Fatalf("failed to parse client_keepalive_time: %v", t)
+		}
+		clientOpts = append(clientOpts, grpc.KeepaliveTime(d))
+	}
+
+	if t := ctx.String("client_keepalive_timeout"); len(t) > 0 {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			logger.Fatalf("failed to parse client_keepalive_timeout: %v", t)
+		}
+		clientOpts = append(clientOpts, grpc.KeepaliveTimeout(d))
+	}
+
 	// Setup server options
 	var serverOpts []server.Option
 
@@ -458,6 +646,25 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		serverOpts = append(serverOpts, server.Address(ctx.String("server_address")))
 	}
 
+	if addrs := ctx.StringSlice("server_listen"); len(addrs) > 0 {
+		serverOpts = append(serverOpts, server.Listen(addrs...))
+	}
+
+	// the interceptors are called in the order they're named
+	for _, name := range ctx.StringSlice("server_interceptors") {
+		unary, uok := c.opts.Interceptors[name]
+		stream, sok := c.opts.StreamInterceptors[name]
+		if !uok && !sok {
+			logger.Fatalf("Server interceptor %s not found", name)
+		}
+		if uok {
+			serverOpts = append(serverOpts, server.Intercept(unary))
+		}
+		if sok {
+			serverOpts = append(serverOpts, server.InterceptStream(stream))
+		}
+	}
+
 	if len(ctx.String("server_advertise")) > 0 {
 		serverOpts = append(serverOpts, server.Advertise(ctx.String("server_advertise")))
 	}
@@ -470,6 +677,14 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		serverOpts = append(serverOpts, server.RegisterInterval(val*time.Second))
 	}
 
+	if val := time.Duration(ctx.Int("register_jitter")); val > 0 {
+		serverOpts = append(serverOpts, server.RegisterJitter(val*time.Second))
+	}
+
+	if val := ctx.Float64("register_limit"); val > 0 {
+		serverOpts = append(serverOpts, server.RegisterLimit(val, ctx.Int("register_burst")))
+	}
+
 	// setup a client to use when calling the runtime. It is important the auth client is wrapped
 	// after the cache client since the wrappers are applied in reverse order and the cache will use
 	// some of the headers set by the auth client.
@@ -494,6 +709,16 @@ func (c *cmd) Before(ctx *cli.Context) error {
 	if len(ctx.String("auth_private_key")) > 0 {
 		authOpts = append(authOpts, auth.PrivateKey(ctx.String("auth_private_key")))
 	}
+	if keys := ctx.StringSlice("auth_verification_keys"); len(keys) > 0 {
+		authOpts = append(authOpts, auth.VerificationKeys(keys...))
+	}
+	if creds := ctx.StringSlice("auth_break_glass"); len(creds) > 0 {
+		accounts, err := auth.ParseBreakGlass(creds...)
+		if err != nil {
+			logger.Fatalf("Error parsing break-glass credentials: %v", err)
+		}
+		authOpts = append(authOpts, auth.BreakGlass(accounts...))
+	}
 	if ns := ctx.String("service_namespace"); len(ns) > 0 {
 		serverOpts = append(serverOpts, server.Namespace(ns))
 		authOpts = append(authOpts, auth.Issuer(ns))
@@ -613,6 +838,45 @@ func (c *cmd) Before(ctx *cli.Context) error {
 	if len(ctx.String("router_address")) > 0 {
 		routerOpts = append(routerOpts, router.Address(ctx.String("router_address")))
 	}
+	if t := ctx.String("router_advertise_interval"); len(t) > 0 {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			logger.Fatalf("failed to parse router_advertise_interval: %v", t)
+		}
+		routerOpts = append(routerOpts, router.AdvertiseInterval(d))
+	}
+	if t := ctx.String("router_advert_ttl"); len(t) > 0 {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			logger.Fatalf("failed to parse router_advert_ttl: %v", t)
+		}
+		routerOpts = append(routerOpts, router.AdvertTTL(d))
+	}
+	if p := ctx.Float64("router_flap_penalty"); p > 0 {
+		damping := router.DefaultDamping
+		damping.Penalty = p
+		if t := ctx.String("router_flap_half_life"); len(t) > 0 {
+			d, err := time.ParseDuration(t)
+			if err != nil {
+				logger.Fatalf("failed to parse router_flap_half_life: %v", t)
+			}
+			damping.HalfLife = d
+		}
+		if ctx.IsSet("router_flap_suppress") {
+			damping.Suppress = ctx.Float64("router_flap_suppress")
+		}
+		if ctx.IsSet("router_flap_recover") {
+			damping.Recover = ctx.Float64("router_flap_recover")
+		}
+		if t := ctx.String("router_flap_max_suppress"); len(t) > 0 {
+			d, err := time.ParseDuration(t)
+			if err != nil {
+				logger.Fatalf("failed to parse router_flap_max_suppress: %v", t)
+			}
+			damping.MaxSuppress = d
+		}
+		routerOpts = append(routerOpts, router.FlapDamping(damping))
+	}
 	if name := ctx.String("router"); len(name) > 0 && (*c.opts.Router).String() != name {
 		r, ok := c.opts.Routers[name]
 		if !ok {
@@ -665,6 +929,41 @@ func (c *cmd) Before(ctx *cli.Context) error {
 	if len(ctx.String("runtime_source")) > 0 {
 		runtimeOpts = append(runtimeOpts, runtime.WithSource(ctx.String("runtime_source")))
 	}
+	switch mode := ctx.String("runtime_verify"); mode {
+	case "", "none":
+	case "permissive":
+		runtimeOpts = append(runtimeOpts, runtime.WithVerify(runtime.VerifyPermissive))
+	case "enforcing":
+		runtimeOpts = append(runtimeOpts, runtime.WithVerify(runtime.VerifyEnforcing))
+	default:
+		logger.Fatalf("Unsupported runtime verify mode: %s", mode)
+	}
+	if keys := ctx.StringSlice("runtime_verify_keys"); len(keys) > 0 {
+		verifyKeys, err := runtime.ParseVerifyKeys(keys...)
+		if err != nil {
+			logger.Fatalf("Error parsing runtime verify keys: %v", err)
+		}
+		runtimeOpts = append(runtimeOpts, runtime.WithVerifyKeys(verifyKeys...))
+	}
+
+	if ports := ctx.String("runtime_ports"); len(ports) > 0 {
+		var min, max int
+		if _, err := fmt.Sscanf(ports, "%d-%d", &min, &max); err != nil || min <= 0 || max < min {
+			logger.Fatalf("Invalid runtime ports %s, expected a range e.g. 10000-11000", ports)
+		}
+		runtimeOpts = append(runtimeOpts, runtime.WithPortRange(min, max))
+	}
+
+	// pass the registry, broker and transport set by flags on to the services
+	var serviceEnv []string
+	for _, name := range []string{"registry", "registry_address", "broker", "broker_address", "transport", "transport_address"} {
+		if ctx.IsSet(name) {
+			serviceEnv = append(serviceEnv, "MICRO_"+strings.ToUpper(name)+"="+ctx.String(name))
+		}
+	}
+	if len(serviceEnv) > 0 {
+		runtimeOpts = append(runtimeOpts, runtime.WithServiceEnv(serviceEnv...))
+	}
 
 	// Set the runtime
 	if name := ctx.String("runtime"); len(name) > 0 {
@@ -716,12 +1015,31 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		}
 	}
 
+	// verify the access to the topics with the rules of the auth
+	if ctx.Bool("broker_auth") && *c.opts.Broker != nil {
+		*c.opts.Broker = wrapper.AuthBroker(authFn, *c.opts.Broker)
+		serverOpts = append(serverOpts, server.Broker(*c.opts.Broker))
+		clientOpts = append(clientOpts, client.Broker(*c.opts.Broker))
+	}
+
 	// Setup the transport options
 	var transportOpts []transport.Option
 	if len(ctx.String("transport_address")) > 0 {
 		addresses := strings.Split(ctx.String("transport_address"), ",")
 		transportOpts = append(transportOpts, transport.Addrs(addresses...))
 	}
+	if ctx.Bool("transport_reuse_port") {
+		transportOpts = append(transportOpts, transport.ReusePort(true))
+	}
+	if ctx.IsSet("transport_keepalive") {
+		transportOpts = append(transportOpts, transport.KeepAlive(time.Duration(ctx.Int("transport_keepalive"))*time.Second))
+	}
+	if ctx.IsSet("transport_no_delay") {
+		transportOpts = append(transportOpts, transport.NoDelay(ctx.Bool("transport_no_delay")))
+	}
+	if n := ctx.Int("transport_backlog"); n > 0 {
+		transportOpts = append(transportOpts, transport.Backlog(n))
+	}
 
 	// Set the transport
 	if name := ctx.String("transport"); len(name) > 0 && (*c.opts.Transport).String() != name {
@@ -823,3 +1141,21 @@ func NewCmd(opts ...Option) Cmd {
 func Run() error {
 	return DefaultCmd.Run()
 }
+
+// tool makes the process exit once the command and its subcommands are done,
+// so a service run with a command doesn't start
+func tool(c *cli.Command) *cli.Command {
+	if action := c.Action; action != nil {
+		c.Action = func(ctx *cli.Context) error {
+			if err := action(ctx); err != nil {
+				return err
+			}
+			os.Exit(0)
+			return nil
+		}
+	}
+	for _, sub := range c.Subcommands {
+		tool(sub)
+	}
+	return c
+}
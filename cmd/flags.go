@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/micro/cli/v2"
+)
+
+var (
+	// flags set when the command ran by name
+	flags = struct {
+		sync.RWMutex
+		values map[string]string
+	}{values: make(map[string]string)}
+
+	// sensitive are the parts of the names of flags whose values are redacted
+	sensitive = []string{"secret", "password", "token", "key", "credentials"}
+)
+
+// Flags returns the flags set on the command line or in the env when the
+// command ran, with the values of secrets redacted
+func Flags() map[string]string {
+	flags.RLock()
+	defer flags.RUnlock()
+
+	values := make(map[string]string, len(flags.values))
+	for k, v := range flags.values {
+		values[k] = v
+	}
+	return values
+}
+
+// setFlags records the flags set in the context
+func setFlags(ctx *cli.Context) {
+	values := make(map[string]string)
+	for _, f := range ctx.App.Flags {
+		name := f.Names()[0]
+		if !ctx.IsSet(name) {
+			continue
+		}
+		values[name] = redact(name, fmt.Sprint(ctx.Value(name)))
+	}
+
+	flags.Lock()
+	flags.values = values
+	flags.Unlock()
+}
+
+// redact hides secrets and the passwords of urls e.g. database addresses
+func redact(name, value string) string {
+	for _, s := range sensitive {
+		if strings.Contains(name, s) {
+			return "[redacted]"
+		}
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "redacted")
+			return u.String()
+		}
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/micro/cli/v2"
+	"github.com/micro/go-micro/v2/util/scaffold"
+)
+
+// NewCommand returns the new command which generates the skeleton of a service
+// e.g. new --namespace=go.micro --plugin=registry/etcd greeter. It's a command
+// of the default app.
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "new",
+		Usage:     "Create a service template",
+		ArgsUsage: "[alias]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "namespace",
+				Usage: "Namespace for the service e.g go.micro",
+				Value: scaffold.DefaultNamespace,
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Type of service e.g service, api, web",
+				Value: scaffold.DefaultType,
+			},
+			&cli.StringFlag{
+				Name:  "module",
+				Usage: "Go module path for the service e.g github.com/micro/example. Defaults to the alias",
+			},
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "Directory to create the service in. Defaults to the alias",
+			},
+			&cli.StringSliceFlag{
+				Name:  "plugin",
+				Usage: "Plugin to import e.g registry/etcd or a full import path",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 1 {
+				return fmt.Errorf("specify the alias of the service e.g. %s new greeter", ctx.App.Name)
+			}
+
+			paths, err := scaffold.Generate(
+				scaffold.Alias(ctx.Args().First()),
+				scaffold.Namespace(ctx.String("namespace")),
+				scaffold.Type(ctx.String("type")),
+				scaffold.Module(ctx.String("module")),
+				scaffold.Dir(ctx.String("dir")),
+				scaffold.Plugins(ctx.StringSlice("plugin")...),
+			)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("Creating service", ctx.Args().First())
+			for _, path := range paths {
+				fmt.Println("  ", path)
+			}
+			fmt.Println("\nGenerate the proto code and build with: make build")
+
+			return nil
+		},
+	}
+}
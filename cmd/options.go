@@ -53,6 +53,10 @@ type Options struct {
 	Auths      map[string]func(...auth.Option) auth.Auth
 	Profiles   map[string]func(...profile.Option) profile.Profile
 
+	// Interceptors of the server by name, see the server_interceptors flag
+	Interceptors       map[string]server.UnaryInterceptor
+	StreamInterceptors map[string]server.StreamInterceptor
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -185,6 +189,22 @@ func NewSelector(name string, s func(...selector.Option) selector.Selector) Opti
 	}
 }
 
+// NewInterceptor registers an interceptor of the requests of the server, to
+// be set with the server_interceptors flag
+func NewInterceptor(name string, i server.UnaryInterceptor) Option {
+	return func(o *Options) {
+		o.Interceptors[name] = i
+	}
+}
+
+// NewStreamInterceptor registers an interceptor of the streams of the server,
+// to be set with the server_interceptors flag
+func NewStreamInterceptor(name string, i server.StreamInterceptor) Option {
+	return func(o *Options) {
+		o.StreamInterceptors[name] = i
+	}
+}
+
 // New server func
 func NewServer(name string, s func(...server.Option) server.Server) Option {
 	return func(o *Options) {
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/micro/cli/v2"
+	"github.com/micro/go-micro/v2/store"
+)
+
+// StoreCommand returns the store command which exports and imports the records
+// of the store configured by the store flags e.g. export with --store=file and
+// import the file with --store=cockroach to migrate between backends. Pass
+// --checkpoint to resume an interrupted export or import. It's a command of
+// the default app.
+func StoreCommand() *cli.Command {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "file",
+			Usage: "File to export to or import from. Defaults to stdout or stdin",
+		},
+		&cli.StringFlag{
+			Name:  "checkpoint",
+			Usage: "File the progress is saved to and resumed from",
+		},
+		&cli.StringFlag{
+			Name:  "database",
+			Usage: "Database to export or import. Defaults to the store database",
+		},
+		&cli.StringFlag{
+			Name:  "table",
+			Usage: "Table to export or import. Defaults to the store table",
+		},
+		&cli.UintFlag{
+			Name:  "batch_size",
+			Usage: "Number of records processed between checkpoints",
+			Value: store.DefaultBatchSize,
+		},
+	}
+
+	return &cli.Command{
+		Name:  "store",
+		Usage: "Export and import the records of the store",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Export the records of the store",
+				Flags: append(flags, &cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Export the keys with the prefix",
+				}),
+				Action: func(ctx *cli.Context) error {
+					w := io.Writer(os.Stdout)
+
+					if path := ctx.String("file"); len(path) > 0 {
+						// append to the output of the interrupted session
+						cp, err := loadCheckpoint(ctx)
+						if err != nil {
+							return err
+						}
+
+						f, err := store.ResumeExport(path, cp)
+						if err != nil {
+							return err
+						}
+						defer f.Close()
+						w = f
+					}
+
+					cp, err := store.Export(store.DefaultStore, w, bulkOptions(ctx)...)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(os.Stderr, "Exported %d records\n", cp.Count)
+					return nil
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "Import records exported from a store",
+				Flags: flags,
+				Action: func(ctx *cli.Context) error {
+					r := io.Reader(os.Stdin)
+
+					if path := ctx.String("file"); len(path) > 0 {
+						f, err := os.Open(path)
+						if err != nil {
+							return err
+						}
+						defer f.Close()
+						r = f
+					}
+
+					cp, err := store.Import(store.DefaultStore, r, bulkOptions(ctx)...)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(os.Stderr, "Imported %d records\n", cp.Count)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// loadCheckpoint loads the checkpoint of the store command if there's one
+func loadCheckpoint(ctx *cli.Context) (*store.Checkpoint, error) {
+	if len(ctx.String("checkpoint")) == 0 {
+		return nil, nil
+	}
+	return store.LoadCheckpoint(ctx.String("checkpoint"))
+}
+
+// bulkOptions returns the bulk options set by the store command flags
+func bulkOptions(ctx *cli.Context) []store.BulkOption {
+	opts := []store.BulkOption{
+		store.BulkBatchSize(ctx.Uint("batch_size")),
+		store.BulkCheckpoint(ctx.String("checkpoint")),
+		store.BulkProgress(func(p store.Progress) {
+			if p.Total > 0 {
+				fmt.Fprintf(os.Stderr, "%d/%d records, last key %s\n", p.Count, p.Total, p.Key)
+			} else {
+				fmt.Fprintf(os.Stderr, "%d records, last key %s\n", p.Count, p.Key)
+			}
+		}),
+	}
+	if db, table := ctx.String("database"), ctx.String("table"); len(db) > 0 || len(table) > 0 {
+		opts = append(opts, store.BulkFrom(db, table))
+	}
+	if ctx.IsSet("prefix") {
+		opts = append(opts, store.BulkPrefix(ctx.String("prefix")))
+	}
+	return opts
+}
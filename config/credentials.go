@@ -0,0 +1,24 @@
+package config
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/auth/credentials"
+)
+
+// CredentialsProvider returns a provider of the credentials at the path of the
+// config e.g. {"username": "foo", "password": "bar", "expiry": "2020-01-01T00:00:00Z"}.
+// The config is read on every call so credentials renewed by a dynamic source
+// are picked up, wrap it in a credentials.Renewer to cache them.
+func CredentialsProvider(c Config, path ...string) credentials.Provider {
+	return credentials.ProviderFunc(func(ctx context.Context) (*credentials.Credentials, error) {
+		var creds credentials.Credentials
+		if err := c.Get(path...).Scan(&creds); err != nil {
+			return nil, err
+		}
+		if len(creds.Username) == 0 && len(creds.Password) == 0 && len(creds.Token) == 0 {
+			return nil, credentials.ErrNoCredentials
+		}
+		return &creds, nil
+	})
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v2/auth/credentials"
+	"github.com/micro/go-micro/v2/config/source/memory"
+)
+
+func TestCredentialsProvider(t *testing.T) {
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(memory.NewSource(memory.WithJSON([]byte(`{"store": {"username": "foo", "password": "bar"}}`)))); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := CredentialsProvider(c, "store").Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.Username != "foo" || creds.Password != "bar" {
+		t.Fatalf("Expected the credentials in the config, got %+v", creds)
+	}
+
+	if _, err := CredentialsProvider(c, "broker").Credentials(context.Background()); err != credentials.ErrNoCredentials {
+		t.Fatalf("Expected no credentials, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+// Package feature provides feature flags read from config. Flags are evaluated
+// locally and updated as the config changes, e.g.
+//
+//	{
+//	  "features": {
+//	    "new-checkout": {
+//	      "enabled": true,
+//	      "percentage": 20,
+//	      "rules": [{"attribute": "type", "values": ["service"], "enabled": false}]
+//	    }
+//	  }
+//	}
+//
+// enables new-checkout for 20% of the accounts, except service accounts.
+package feature
+
+import (
+	"context"
+	"hash/crc32"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/logger"
+)
+
+var (
+	// DefaultPath is the config path the flags are read from
+	DefaultPath = []string{"features"}
+
+	// DefaultRetry is how long to wait before reading the default flags again
+	// after failing to
+	DefaultRetry = time.Second * 10
+
+	// default flags read from the default config, retried after a failure
+	defaultMu     sync.Mutex
+	defaultFlags  *Flags
+	defaultFailed time.Time
+)
+
+// Flag is a feature flag
+type Flag struct {
+	// Enabled turns the flag on, a disabled flag is off for everyone
+	Enabled bool `json:"enabled"`
+	// Percentage of the accounts, between 0 and 100, the flag is on for. Zero
+	// turns it off for every account, unset turns it on for every account.
+	Percentage *float64 `json:"percentage,omitempty"`
+	// Rules are evaluated in order before the percentage, the first rule
+	// matching the account decides the flag
+	Rules []Rule `json:"rules"`
+}
+
+// Rule turns a flag on or off for the accounts with an attribute value
+type Rule struct {
+	// Attribute of the account: id, type, issuer, scope or a metadata key
+	Attribute string `json:"attribute"`
+	// Values the attribute is matched against
+	Values []string `json:"values"`
+	// Enabled is the value of the flag for the matching accounts
+	Enabled bool `json:"enabled"`
+}
+
+// Evaluate returns whether the flag is on for the account, which may be nil
+func (f *Flag) Evaluate(name string, acc *auth.Account) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	for _, rule := range f.Rules {
+		if rule.Match(acc) {
+			return rule.Enabled
+		}
+	}
+
+	if f.Percentage == nil || *f.Percentage >= 100 {
+		return true
+	}
+	if *f.Percentage <= 0 {
+		return false
+	}
+	// a rollout can't be evaluated without an account
+	if acc == nil {
+		return false
+	}
+
+	// hash the account so it gets the same value every time
+	bucket := crc32.ChecksumIEEE([]byte(name+"/"+acc.ID)) % 10000
+	return float64(bucket) < *f.Percentage*100
+}
+
+// Percent returns the percentage to set in a flag
+func Percent(p float64) *float64 {
+	return &p
+}
+
+// Match returns true if the attribute of the account has one of the values
+func (r *Rule) Match(acc *auth.Account) bool {
+	if acc == nil {
+		return false
+	}
+
+	var attrs []string
+	switch r.Attribute {
+	case "id":
+		attrs = []string{acc.ID}
+	case "type":
+		attrs = []string{acc.Type}
+	case "issuer":
+		attrs = []string{acc.Issuer}
+	case "scope":
+		attrs = acc.Scopes
+	default:
+		v, ok := acc.Metadata[r.Attribute]
+		if !ok {
+			return false
+		}
+		attrs = []string{v}
+	}
+
+	for _, attr := range attrs {
+		for _, v := range r.Values {
+			if attr == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Flags are the feature flags in a config
+type Flags struct {
+	opts Options
+
+	sync.RWMutex
+	flags   map[string]*Flag
+	watcher config.Watcher
+}
+
+// NewFlags reads the flags from the config and watches it for changes
+func NewFlags(opts ...Option) (*Flags, error) {
+	options := Options{
+		Config: config.DefaultConfig,
+		Path:   DefaultPath,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	f := &Flags{
+		opts:  options,
+		flags: make(map[string]*Flag),
+	}
+
+	if err := options.Config.Get(options.Path...).Scan(&f.flags); err != nil {
+		return nil, err
+	}
+
+	w, err := options.Config.Watch(options.Path...)
+	if err != nil {
+		return nil, err
+	}
+	f.watcher = w
+
+	go f.watch()
+
+	return f, nil
+}
+
+// watch updates the flags as the config changes until the watcher is stopped
+func (f *Flags) watch() {
+	for {
+		v, err := f.watcher.Next()
+		if err != nil {
+			return
+		}
+
+		flags := make(map[string]*Flag)
+		if err := v.Scan(&flags); err != nil {
+			logger.Errorf("Error reading feature flags: %v", err)
+			continue
+		}
+
+		f.Lock()
+		f.flags = flags
+		f.Unlock()
+	}
+}
+
+// Flag returns the flag with the name
+func (f *Flags) Flag(name string) (*Flag, bool) {
+	f.RLock()
+	defer f.RUnlock()
+
+	flag, ok := f.flags[name]
+	return flag, ok
+}
+
+// Enabled returns whether the flag is on for the account in the context. Flags
+// which don't exist are off.
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	flag, ok := f.Flag(name)
+	if !ok {
+		return false
+	}
+
+	acc, _ := auth.AccountFromContext(ctx)
+	return flag.Evaluate(name, acc)
+}
+
+// Close stops watching the config
+func (f *Flags) Close() error {
+	return f.watcher.Stop()
+}
+
+// defaults returns the flags of the default config, nil if they can't be read.
+// A failure to read them is retried after the DefaultRetry.
+func defaults() *Flags {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultFlags != nil {
+		return defaultFlags
+	}
+	if !defaultFailed.IsZero() && time.Since(defaultFailed) < DefaultRetry {
+		return nil
+	}
+
+	flags, err := NewFlags()
+	if err != nil {
+		logger.Errorf("Error reading feature flags: %v", err)
+		defaultFailed = time.Now()
+		return nil
+	}
+	defaultFlags = flags
+	return defaultFlags
+}
+
+// Enabled returns whether the flag in the default config is on for the account
+// in the context. Flags which can't be read are off.
+func Enabled(ctx context.Context, name string) bool {
+	flags := defaults()
+	if flags == nil {
+		return false
+	}
+	return flags.Enabled(ctx, name)
+}
@@ -0,0 +1,112 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/config/source"
+	"github.com/micro/go-micro/v2/config/source/memory"
+)
+
+func TestFlagEvaluate(t *testing.T) {
+	flag := &Flag{
+		Enabled:    true,
+		Percentage: Percent(50),
+		Rules: []Rule{
+			{Attribute: "type", Values: []string{"service"}, Enabled: false},
+			{Attribute: "team", Values: []string{"beta"}, Enabled: true},
+		},
+	}
+
+	service := &auth.Account{ID: "svc", Type: "service"}
+	if flag.Evaluate("checkout", service) {
+		t.Error("Expected the flag to be off for service accounts")
+	}
+
+	beta := &auth.Account{ID: "beta", Type: "user", Metadata: map[string]string{"team": "beta"}}
+	if !flag.Evaluate("checkout", beta) {
+		t.Error("Expected the flag to be on for the beta team")
+	}
+
+	if flag.Evaluate("checkout", nil) {
+		t.Error("Expected a rollout to be off without an account")
+	}
+
+	// about half the accounts get the flag, always the same ones
+	var on int
+	for i := 0; i < 1000; i++ {
+		acc := &auth.Account{ID: fmt.Sprintf("user-%d", i)}
+		v := flag.Evaluate("checkout", acc)
+		if v != flag.Evaluate("checkout", acc) {
+			t.Fatalf("Expected the flag to be stable for %s", acc.ID)
+		}
+		if v {
+			on++
+		}
+	}
+	if on < 400 || on > 600 {
+		t.Errorf("Expected the flag to be on for about half the accounts, got %d of 1000", on)
+	}
+
+	// zero is off for everyone, unset on for everyone
+	acc := &auth.Account{ID: "user-1"}
+	flag.Percentage = Percent(0)
+	if flag.Evaluate("checkout", acc) {
+		t.Error("Expected a zero percentage to be off")
+	}
+	flag.Percentage = nil
+	if !flag.Evaluate("checkout", acc) {
+		t.Error("Expected an unset percentage to be on")
+	}
+
+	flag.Enabled = false
+	if flag.Evaluate("checkout", beta) {
+		t.Error("Expected a disabled flag to be off for everyone")
+	}
+}
+
+func TestFlagsWatch(t *testing.T) {
+	src := memory.NewSource(memory.WithJSON([]byte(`{"features": {"checkout": {"enabled": true}}}`)))
+
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(src); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err := NewFlags(Config(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer flags.Close()
+
+	ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: "user-1"})
+	if !flags.Enabled(ctx, "checkout") {
+		t.Fatal("Expected checkout to be enabled")
+	}
+	if flags.Enabled(ctx, "missing") {
+		t.Fatal("Expected a missing flag to be off")
+	}
+
+	// turn the flag off in the config, the write is retried since the config
+	// starts watching the source in the background
+	deadline := time.Now().Add(time.Second * 5)
+	for flags.Enabled(ctx, "checkout") {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected checkout to be disabled after the config changed")
+		}
+		if err := src.Write(&source.ChangeSet{
+			Data:   []byte(`{"features": {"checkout": {"enabled": false}}}`),
+			Format: "json",
+		}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+}
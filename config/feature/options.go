@@ -0,0 +1,30 @@
+package feature
+
+import (
+	"github.com/micro/go-micro/v2/config"
+)
+
+// Options for the feature flags
+type Options struct {
+	// Config the flags are read from
+	Config config.Config
+	// Path of the flags in the config
+	Path []string
+}
+
+// Option sets values in Options
+type Option func(o *Options)
+
+// Config sets the config the flags are read from
+func Config(c config.Config) Option {
+	return func(o *Options) {
+		o.Config = c
+	}
+}
+
+// Path sets the path of the flags in the config
+func Path(path ...string) Option {
+	return func(o *Options) {
+		o.Path = path
+	}
+}
@@ -0,0 +1,54 @@
+// Package services loads the options of the services called by a client from
+// config, e.g.
+//
+//	{
+//	  "client": {
+//	    "services": {
+//	      "go.micro.srv.foo": {"timeout": "2s", "retries": 1}
+//	    }
+//	  }
+//	}
+//
+// sets the timeout and retries of the calls to go.micro.srv.foo.
+package services
+
+import (
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/logger"
+)
+
+// Watch loads the options of the services called by a client from the path of
+// the config, see client.ServiceSettings, and reloads them as the config
+// changes until the watcher returned is stopped. Pass it the Services of the
+// client options, the options set in code are kept.
+func Watch(c config.Config, s *client.Services, path ...string) (config.Watcher, error) {
+	settings := make(map[string]client.ServiceSettings)
+	if err := c.Get(path...).Scan(&settings); err != nil {
+		return nil, err
+	}
+	s.Load(settings)
+
+	w, err := c.Watch(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			v, err := w.Next()
+			if err != nil {
+				return
+			}
+
+			settings := make(map[string]client.ServiceSettings)
+			if err := v.Scan(&settings); err != nil {
+				logger.Errorf("Error reading client service config: %v", err)
+				continue
+			}
+			s.Load(settings)
+		}
+	}()
+
+	return w, nil
+}
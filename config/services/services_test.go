@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/config/source"
+	"github.com/micro/go-micro/v2/config/source/memory"
+)
+
+func TestWatch(t *testing.T) {
+	c, err := config.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := memory.NewSource(memory.WithJSON([]byte(`{"client": {"services": {"foo": {"retries": 3, "timeout": "2s"}}}}`)))
+	if err := c.Load(src); err != nil {
+		t.Fatal(err)
+	}
+
+	s := client.NewServices()
+	w, err := Watch(c, s, "client", "services")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	opts := s.CallOptions("foo", client.CallOptions{})
+	if opts.Retries != 3 || opts.RequestTimeout != 2*time.Second {
+		t.Fatalf("Expected the options in the config, got %d retries and %v timeout", opts.Retries, opts.RequestTimeout)
+	}
+
+	// the options are reloaded as the config changes, the change is written
+	// until it's seen as the config may not be watching the source yet
+	for i := 0; i < 100; i++ {
+		src.Write(&source.ChangeSet{
+			Data:   []byte(`{"client": {"services": {"foo": {"retries": 1}}}}`),
+			Format: "json",
+		})
+		time.Sleep(10 * time.Millisecond)
+		if opts = s.CallOptions("foo", client.CallOptions{}); opts.Retries == 1 {
+			break
+		}
+	}
+	if opts.Retries != 1 || opts.RequestTimeout != 0 {
+		t.Fatalf("Expected the options to be reloaded, got %d retries and %v timeout", opts.Retries, opts.RequestTimeout)
+	}
+}
@@ -42,17 +42,24 @@ func (m *service) Read() (set *source.ChangeSet, err error) {
 
 func (m *service) Watch() (w source.Watcher, err error) {
 	client := proto.NewConfigService(m.serviceName, m.opts.Client)
-	stream, err := client.Watch(context.Background(), &proto.WatchRequest{
-		Namespace: m.namespace,
-		Path:      m.path,
-	})
+	open := func() (proto.Config_WatchService, error) {
+		return client.Watch(context.Background(), &proto.WatchRequest{
+			Namespace: m.namespace,
+			Path:      m.path,
+		})
+	}
+
+	stream, err := open()
 	if err != nil {
 		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 			logger.Error("watch err: ", err)
 		}
-		return
+		return nil, err
 	}
-	return newWatcher(stream)
+
+	// the watcher reopens the stream if it drops, reading the current change
+	// set to catch up on anything pushed while it was down
+	return newWatcher(stream, open, m.Read)
 }
 
 // Write is unsupported
@@ -1,27 +1,122 @@
 package service
 
 import (
+	"sync"
+	"time"
+
 	"github.com/micro/go-micro/v2/config/source"
 	proto "github.com/micro/go-micro/v2/config/source/service/proto"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/util/backoff"
 )
 
 type watcher struct {
+	sync.RWMutex
 	stream proto.Config_WatchService
+	exit   chan bool
+
+	// open creates a new watch stream, used to reconnect
+	open func() (proto.Config_WatchService, error)
+	// read returns the current change set, used to catch up after reconnecting
+	read func() (*source.ChangeSet, error)
+
+	// token is the checksum of the last change set returned. Changes made
+	// while the stream was down are detected by comparing it with the current
+	// change set once reconnected.
+	token string
 }
 
-func newWatcher(stream proto.Config_WatchService) (source.Watcher, error) {
-	return &watcher{stream: stream}, nil
+func newWatcher(stream proto.Config_WatchService, open func() (proto.Config_WatchService, error), read func() (*source.ChangeSet, error)) (source.Watcher, error) {
+	return &watcher{
+		stream: stream,
+		exit:   make(chan bool),
+		open:   open,
+		read:   read,
+	}, nil
+}
+
+// reconnect re-establishes the stream, backing off between attempts, and
+// returns the change set missed while it was down if there is one
+func (w *watcher) reconnect() (*source.ChangeSet, error) {
+	for i := 0; ; i++ {
+		select {
+		case <-w.exit:
+			return nil, source.ErrWatcherStopped
+		case <-time.After(backoff.Do(i)):
+		}
+
+		stream, err := w.open()
+		if err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Config watch reconnect failed: %v", err)
+			}
+			continue
+		}
+
+		w.Lock()
+		select {
+		case <-w.exit:
+			w.Unlock()
+			stream.Close()
+			return nil, source.ErrWatcherStopped
+		default:
+		}
+		w.stream.Close()
+		w.stream = stream
+		w.Unlock()
+
+		cs, err := w.read()
+		if err != nil || cs == nil || cs.Checksum == w.token {
+			return nil, nil
+		}
+		return cs, nil
+	}
 }
 
 func (w *watcher) Next() (*source.ChangeSet, error) {
-	var rsp proto.WatchResponse
-	err := w.stream.RecvMsg(&rsp)
-	if err != nil {
-		return nil, err
+	for {
+		w.RLock()
+		stream := w.stream
+		w.RUnlock()
+
+		var rsp proto.WatchResponse
+		err := stream.RecvMsg(&rsp)
+		if err == nil {
+			cs := toChangeSet(rsp.ChangeSet)
+			w.token = cs.Checksum
+			return cs, nil
+		}
+
+		select {
+		case <-w.exit:
+			return nil, source.ErrWatcherStopped
+		default:
+		}
+
+		if w.open == nil {
+			return nil, err
+		}
+
+		cs, err := w.reconnect()
+		if err != nil {
+			return nil, err
+		}
+		if cs != nil {
+			w.token = cs.Checksum
+			return cs, nil
+		}
 	}
-	return toChangeSet(rsp.ChangeSet), nil
 }
 
 func (w *watcher) Stop() error {
+	w.Lock()
+	defer w.Unlock()
+
+	select {
+	case <-w.exit:
+		return nil
+	default:
+		close(w.exit)
+	}
 	return w.stream.Close()
 }
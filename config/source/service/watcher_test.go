@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/micro/go-micro/v2/config/source"
+	proto "github.com/micro/go-micro/v2/config/source/service/proto"
+)
+
+type testStream struct {
+	changes []*proto.ChangeSet
+}
+
+func (t *testStream) Context() context.Context  { return context.TODO() }
+func (t *testStream) SendMsg(interface{}) error { return nil }
+func (t *testStream) Close() error              { return nil }
+func (t *testStream) RecvMsg(msg interface{}) error {
+	rsp, err := t.Recv()
+	if err != nil {
+		return err
+	}
+	*msg.(*proto.WatchResponse) = *rsp
+	return nil
+}
+func (t *testStream) Recv() (*proto.WatchResponse, error) {
+	if len(t.changes) == 0 {
+		return nil, errors.New("stream closed")
+	}
+	c := t.changes[0]
+	t.changes = t.changes[1:]
+	return &proto.WatchResponse{ChangeSet: c}, nil
+}
+
+func TestWatcherReconnect(t *testing.T) {
+	first := &testStream{changes: []*proto.ChangeSet{{Checksum: "a"}}}
+	second := &testStream{changes: []*proto.ChangeSet{{Checksum: "c"}}}
+
+	// the config changed to b while the stream was down
+	current := &proto.ChangeSet{Checksum: "b"}
+
+	w, err := newWatcher(
+		first,
+		func() (proto.Config_WatchService, error) { return second, nil },
+		func() (*source.ChangeSet, error) { return toChangeSet(current), nil },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []string{"a", "b", "c"} {
+		cs, err := w.Next()
+		if err != nil {
+			t.Fatalf("Expected change set %s, got error: %v", expect, err)
+		}
+		if cs.Checksum != expect {
+			t.Fatalf("Expected change set %s, got %s", expect, cs.Checksum)
+		}
+	}
+
+	if err := w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Next(); err != source.ErrWatcherStopped {
+		t.Fatalf("Expected watcher stopped, got %v", err)
+	}
+}
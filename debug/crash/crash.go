@@ -0,0 +1,224 @@
+// Package crash collects crash reports when a service dies so postmortems
+// are possible without core dumps
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/debug/log"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/store"
+)
+
+var (
+	// DefaultRecords is the number of recent log records in a report
+	DefaultRecords = 100
+	// Prefix of the store keys and file names of reports
+	Prefix = "crash"
+)
+
+// Report is the state of the process when it crashed
+type Report struct {
+	// Id of the report
+	Id string `json:"id"`
+	// Name of the service which crashed
+	Name string `json:"name"`
+	// Reason is the panic value, fatal log message or signal
+	Reason string `json:"reason"`
+	// Timestamp of the crash
+	Timestamp time.Time `json:"timestamp"`
+	// Build the binary was built from
+	Build Build `json:"build"`
+	// Goroutines is a dump of the stacks of all goroutines
+	Goroutines string `json:"goroutines"`
+	// Logs are the most recent log records
+	Logs []log.Record `json:"logs"`
+}
+
+// Build describes the binary which crashed
+type Build struct {
+	Go      string `json:"go"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+// Collector collects and persists crash reports
+type Collector struct {
+	opts Options
+
+	sync.Mutex
+	// signals the collector is notified of
+	sig  chan os.Signal
+	exit chan bool
+	// removes the fatal log hook
+	unhook func()
+}
+
+// NewCollector returns a new crash report collector
+func NewCollector(opts ...Option) *Collector {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Collector{opts: options}
+}
+
+// Collect builds a crash report for the current state of the process
+func (c *Collector) Collect(reason string) *Report {
+	r := &Report{
+		Id:         uuid.New().String(),
+		Name:       c.opts.Name,
+		Reason:     reason,
+		Timestamp:  time.Now(),
+		Build:      buildInfo(),
+		Goroutines: string(goroutines()),
+	}
+
+	if c.opts.Log != nil && c.opts.Records > 0 {
+		// the log may be broken too so a failed read leaves out the logs
+		if records, err := c.opts.Log.Read(log.Count(c.opts.Records)); err == nil {
+			r.Logs = records
+		}
+	}
+
+	return r
+}
+
+// Save persists the report to the store or writes it to the directory
+func (c *Collector) Save(r *Report) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s/%s/%d-%s", Prefix, r.Name, r.Timestamp.Unix(), r.Id)
+
+	if c.opts.Store != nil {
+		return c.opts.Store.Write(&store.Record{
+			Key:   key,
+			Value: b,
+			Metadata: map[string]interface{}{
+				"name":   r.Name,
+				"reason": r.Reason,
+			},
+		})
+	}
+
+	name := fmt.Sprintf("%s-%d-%s.json", Prefix, r.Timestamp.Unix(), r.Id)
+	if len(r.Name) > 0 {
+		name = fmt.Sprintf("%s-%s-%d-%s.json", Prefix, r.Name, r.Timestamp.Unix(), r.Id)
+	}
+
+	return ioutil.WriteFile(filepath.Join(c.opts.Dir, name), b, 0600)
+}
+
+// report collects and saves a report, logging where it failed
+func (c *Collector) report(reason string) {
+	if err := c.Save(c.Collect(reason)); err != nil {
+		logger.Errorf("Failed to save crash report: %v", err)
+	}
+}
+
+// Recover saves a crash report for a panic and then carries on panicking.
+// It must be deferred e.g. defer c.Recover() at the top of main or a goroutine.
+// Nil dereferences and other faults in Go code are delivered as panics so are
+// caught here.
+func (c *Collector) Recover() {
+	if r := recover(); r != nil {
+		c.report(fmt.Sprintf("panic: %v", r))
+		panic(r)
+	}
+}
+
+// Start saves a crash report on a fatal log or on a fatal signal sent to the
+// process before it exits
+func (c *Collector) Start() {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.exit != nil {
+		return
+	}
+
+	c.sig = make(chan os.Signal, 1)
+	c.exit = make(chan bool)
+
+	// include every goroutine if the runtime crashes
+	debug.SetTraceback("all")
+
+	c.unhook = logger.OnFatal(func(msg string) {
+		c.report("fatal: " + msg)
+	})
+
+	signal.Notify(c.sig, syscall.SIGSEGV, syscall.SIGBUS, syscall.SIGABRT)
+
+	go func(sig chan os.Signal, exit chan bool) {
+		select {
+		case s := <-sig:
+			c.report("signal: " + s.String())
+			os.Exit(2)
+		case <-exit:
+		}
+	}(c.sig, c.exit)
+}
+
+// Stop stops collecting crash reports on fatal logs and signals
+func (c *Collector) Stop() {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.exit == nil {
+		return
+	}
+
+	c.unhook()
+	signal.Stop(c.sig)
+	close(c.exit)
+	c.sig = nil
+	c.exit = nil
+	c.unhook = nil
+}
+
+// buildInfo returns the build the binary was built from
+func buildInfo() Build {
+	b := Build{
+		Go:   runtime.Version(),
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		b.Path = info.Main.Path
+		b.Version = info.Main.Version
+		b.Sum = info.Main.Sum
+	}
+
+	return b
+}
+
+// goroutines returns the stacks of all goroutines
+func goroutines() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
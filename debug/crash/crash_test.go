@@ -0,0 +1,88 @@
+package crash
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v2/debug/log"
+	"github.com/micro/go-micro/v2/store/memory"
+)
+
+func TestCollect(t *testing.T) {
+	l := log.NewLog()
+	l.Write(log.Record{Message: "about to crash"})
+
+	c := NewCollector(Name("go.micro.srv.foo"), Log(l))
+	r := c.Collect("panic: boom")
+
+	if r.Name != "go.micro.srv.foo" || r.Reason != "panic: boom" {
+		t.Fatalf("unexpected report %s %s", r.Name, r.Reason)
+	}
+	if !strings.Contains(r.Goroutines, "TestCollect") {
+		t.Errorf("expected the goroutine dump to contain the test")
+	}
+	if len(r.Logs) != 1 || r.Logs[0].Message != "about to crash" {
+		t.Errorf("expected the recent log records, got %v", r.Logs)
+	}
+	if len(r.Build.Go) == 0 {
+		t.Errorf("expected the go version in the build info")
+	}
+}
+
+func TestRecoverDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewCollector(Name("foo"), Dir(dir))
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected the panic to carry on")
+			}
+		}()
+		defer c.Recover()
+		panic("boom")
+	}()
+
+	files, err := filepath.Glob(filepath.Join(dir, "crash-foo-*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one report, got %v %v", files, err)
+	}
+
+	b, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Reason != "panic: boom" {
+		t.Errorf("unexpected reason %s", r.Reason)
+	}
+}
+
+func TestSaveStore(t *testing.T) {
+	s := memory.NewStore()
+	c := NewCollector(Name("foo"), Store(s))
+
+	if err := c.Save(c.Collect("fatal: boom")); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !strings.HasPrefix(keys[0], "crash/foo/") {
+		t.Errorf("expected one report in the store, got %v", keys)
+	}
+}
@@ -0,0 +1,69 @@
+package crash
+
+import (
+	"os"
+
+	"github.com/micro/go-micro/v2/debug/log"
+	"github.com/micro/go-micro/v2/store"
+)
+
+// Options are crash collector options
+type Options struct {
+	// Name of the service the reports are for
+	Name string
+	// Store to persist reports to, takes precedence over Dir
+	Store store.Store
+	// Dir to write reports to when no store is set
+	Dir string
+	// Log to read the recent log records from
+	Log log.Log
+	// Records is the number of recent log records to include
+	Records int
+}
+
+// Option sets a crash collector option
+type Option func(o *Options)
+
+// Name of the service the reports are for
+func Name(n string) Option {
+	return func(o *Options) {
+		o.Name = n
+	}
+}
+
+// Store persists the reports to the store
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Dir writes the reports to files in the directory
+func Dir(d string) Option {
+	return func(o *Options) {
+		o.Dir = d
+	}
+}
+
+// Log sets the log to read the recent log records from
+func Log(l log.Log) Option {
+	return func(o *Options) {
+		o.Log = l
+	}
+}
+
+// Records sets the number of recent log records to include
+func Records(n int) Option {
+	return func(o *Options) {
+		o.Records = n
+	}
+}
+
+// DefaultOptions returns default options
+func DefaultOptions() Options {
+	return Options{
+		Dir:     os.TempDir(),
+		Log:     log.DefaultLog,
+		Records: DefaultRecords,
+	}
+}
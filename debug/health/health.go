@@ -0,0 +1,144 @@
+// Package health keeps the health status of the services of a server, served
+// by the grpc health checking protocol and the debug handler
+package health
+
+import (
+	"sync"
+)
+
+// Status of a service
+type Status int
+
+const (
+	// StatusUnknown is the status of a service never set
+	StatusUnknown Status = iota
+	// StatusServing is the status of a service serving requests
+	StatusServing
+	// StatusNotServing is the status of a service not serving requests
+	StatusNotServing
+)
+
+// String returns human readable status
+func (s Status) String() string {
+	switch s {
+	case StatusServing:
+		return "serving"
+	case StatusNotServing:
+		return "not serving"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// DefaultHealth is the health of the services of the process, reported
+	// by the debug handlers without a server. The servers have one of their own.
+	DefaultHealth = NewHealth()
+)
+
+// Health is the health status of services. The status of the empty service
+// name is the overall health of the server.
+type Health struct {
+	sync.RWMutex
+	statuses map[string]Status
+	watchers map[string][]chan Status
+	// set once shutdown, statuses can't be set until resumed
+	shutdown bool
+}
+
+// NewHealth returns the health of services with only the server serving
+func NewHealth() *Health {
+	return &Health{
+		statuses: map[string]Status{"": StatusServing},
+		watchers: make(map[string][]chan Status),
+	}
+}
+
+// Set the status of the service, ignored once shutdown
+func (h *Health) Set(service string, status Status) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.shutdown {
+		return
+	}
+	h.set(service, status)
+}
+
+func (h *Health) set(service string, status Status) {
+	h.statuses[service] = status
+
+	for _, ch := range h.watchers[service] {
+		// drop the stale status of a slow watcher
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- status
+	}
+}
+
+// Get the status of the service, false if never set
+func (h *Health) Get(service string) (Status, bool) {
+	h.RLock()
+	defer h.RUnlock()
+
+	status, ok := h.statuses[service]
+	return status, ok
+}
+
+// Watch the status of the service, the channel receives its current status
+// and then its latest one on every change until stopped
+func (h *Health) Watch(service string) (<-chan Status, func()) {
+	ch := make(chan Status, 1)
+
+	h.Lock()
+	ch <- h.statuses[service]
+	h.watchers[service] = append(h.watchers[service], ch)
+	h.Unlock()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			h.Lock()
+			defer h.Unlock()
+
+			watchers := h.watchers[service]
+			for i, w := range watchers {
+				if w == ch {
+					h.watchers[service] = append(watchers[:i], watchers[i+1:]...)
+					break
+				}
+			}
+			if len(h.watchers[service]) == 0 {
+				delete(h.watchers, service)
+			}
+		})
+	}
+
+	return ch, stop
+}
+
+// Shutdown sets all services not serving e.g. when the server stops, and
+// ignores the statuses set until resumed
+func (h *Health) Shutdown() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.shutdown = true
+	for service := range h.statuses {
+		h.set(service, StatusNotServing)
+	}
+}
+
+// Resume sets all services serving e.g. when the server starts, and accepts
+// the statuses set again
+func (h *Health) Resume() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.shutdown = false
+	for service := range h.statuses {
+		h.set(service, StatusServing)
+	}
+}
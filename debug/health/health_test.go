@@ -0,0 +1,53 @@
+package health
+
+import (
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	h := NewHealth()
+
+	if s, ok := h.Get(""); !ok || s != StatusServing {
+		t.Fatalf("Expected the server to be serving, got %v", s)
+	}
+	if _, ok := h.Get("foo"); ok {
+		t.Fatal("Expected the status of foo not to be set")
+	}
+
+	ch, stop := h.Watch("foo")
+	if s := <-ch; s != StatusUnknown {
+		t.Fatalf("Expected the status of foo to be unknown, got %v", s)
+	}
+
+	h.Set("foo", StatusServing)
+	if s := <-ch; s != StatusServing {
+		t.Fatalf("Expected foo to be serving, got %v", s)
+	}
+
+	// statuses can't be set once shutdown
+	h.Shutdown()
+	if s := <-ch; s != StatusNotServing {
+		t.Fatalf("Expected foo not to be serving, got %v", s)
+	}
+	h.Set("foo", StatusServing)
+	if s, _ := h.Get("foo"); s != StatusNotServing {
+		t.Fatalf("Expected foo not to be serving once shutdown, got %v", s)
+	}
+
+	h.Resume()
+	if s, _ := h.Get(""); s != StatusServing {
+		t.Fatalf("Expected the server to be serving once resumed, got %v", s)
+	}
+
+	// a stopped watch isn't sent statuses
+	stop()
+	stop()
+	h.Set("foo", StatusNotServing)
+	select {
+	case s := <-ch:
+		if s != StatusServing {
+			t.Fatalf("Expected no status after stop, got %v", s)
+		}
+	default:
+	}
+}
@@ -0,0 +1,92 @@
+// Package manifest describes a service for catalogs and dependency graphs:
+// its endpoints and their schemas, the topics it subscribes to, the services
+// it depends on and the flags it runs with.
+package manifest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/server"
+)
+
+var (
+	// DependenciesKey is the registry node metadata key of the dependencies
+	DependenciesKey = "dependencies"
+
+	current struct {
+		sync.RWMutex
+		manifest *Manifest
+	}
+)
+
+// Manifest of a service
+type Manifest struct {
+	// Name of the service
+	Name string `json:"name"`
+	// Version of the service
+	Version string `json:"version"`
+	// Endpoints served with the schemas of their requests and responses
+	Endpoints []*registry.Endpoint `json:"endpoints"`
+	// Topics subscribed to
+	Topics []string `json:"topics"`
+	// Dependencies are the services the service calls
+	Dependencies []string `json:"dependencies"`
+	// Flags in effect, secrets are redacted
+	Flags map[string]string `json:"flags"`
+}
+
+// Generate generates the manifest of the service served by the server from
+// its options and the handlers and subscribers registered with it
+func Generate(srv server.Server, dependencies []string, flags map[string]string) (*Manifest, error) {
+	opts := srv.Options()
+
+	m := &Manifest{
+		Name:         opts.Name,
+		Version:      opts.Version,
+		Dependencies: unique(dependencies),
+		Flags:        flags,
+	}
+
+	endpoints, _ := server.Endpoints(srv)
+	for _, e := range endpoints {
+		if e.Metadata["subscriber"] == "true" {
+			m.Topics = append(m.Topics, e.Metadata["topic"])
+			continue
+		}
+		m.Endpoints = append(m.Endpoints, e)
+	}
+
+	m.Topics = unique(m.Topics)
+	return m, nil
+}
+
+// Set the manifest of the service
+func Set(m *Manifest) {
+	current.Lock()
+	current.manifest = m
+	current.Unlock()
+}
+
+// Get the manifest of the service, nil if it wasn't generated
+func Get() *Manifest {
+	current.RLock()
+	defer current.RUnlock()
+	return current.manifest
+}
+
+// unique returns the sorted unique values
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if len(v) == 0 || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
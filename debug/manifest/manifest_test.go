@@ -0,0 +1,67 @@
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/server"
+)
+
+type Request struct {
+	Name string
+}
+
+type Response struct {
+	Msg string
+}
+
+type Foo struct{}
+
+func (f *Foo) Call(ctx context.Context, req *Request, rsp *Response) error {
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	srv := server.NewServer(
+		server.Name("go.micro.service.foo"),
+		server.Version("1.0.0"),
+		server.Registry(memory.NewRegistry()),
+	)
+
+	if err := srv.Handle(srv.NewHandler(&Foo{})); err != nil {
+		t.Fatal(err)
+	}
+	sub := srv.NewSubscriber("events", func(ctx context.Context, req *Request) error {
+		return nil
+	})
+	if err := srv.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Generate(srv, []string{"go.micro.service.bar", "go.micro.service.baz", "go.micro.service.bar"}, map[string]string{"registry": "memory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Name != "go.micro.service.foo" || m.Version != "1.0.0" {
+		t.Fatalf("Unexpected service %s %s", m.Name, m.Version)
+	}
+	if len(m.Endpoints) != 1 || m.Endpoints[0].Name != "Foo.Call" || m.Endpoints[0].Request.Type != "Request" {
+		t.Fatalf("Unexpected endpoints %+v", m.Endpoints)
+	}
+	if len(m.Topics) != 1 || m.Topics[0] != "events" {
+		t.Fatalf("Unexpected topics %v", m.Topics)
+	}
+	if len(m.Dependencies) != 2 || m.Dependencies[0] != "go.micro.service.bar" {
+		t.Fatalf("Unexpected dependencies %v", m.Dependencies)
+	}
+	if m.Flags["registry"] != "memory" {
+		t.Fatalf("Unexpected flags %v", m.Flags)
+	}
+
+	Set(m)
+	if Get() != m {
+		t.Fatal("Expected the manifest to be set")
+	}
+}
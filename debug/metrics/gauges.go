@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Gauges keeps the values of gauges changed by deltas, e.g. the number of
+// requests in flight, and reports them to a reporter
+type Gauges struct {
+	reporter Reporter
+
+	sync.Mutex
+	// values by metric and tags
+	values map[string]int64
+}
+
+// NewGauges returns gauges reported to the reporter
+func NewGauges(r Reporter) *Gauges {
+	return &Gauges{
+		reporter: r,
+		values:   make(map[string]int64),
+	}
+}
+
+// Add adds the delta to the gauge with the tags and reports its value. The
+// value is reported under lock so concurrent changes are reported in order.
+func (g *Gauges) Add(name string, tags Tags, delta int64) {
+	k := key(name, tags)
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.values[k] += delta
+	v := g.values[k]
+	if v == 0 {
+		delete(g.values, k)
+	}
+
+	g.reporter.Gauge(name, float64(v), tags)
+}
+
+// key returns the key of the metric with the tags
+func key(name string, tags Tags) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteString("/" + k + "=" + tags[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+// Package memory provides an in memory metrics reporter
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/metrics"
+)
+
+// Reporter keeps the metrics in memory, to be read e.g. by the debug handler
+type Reporter struct {
+	buckets []time.Duration
+
+	sync.RWMutex
+	// metrics by name and tags
+	metrics map[string]*metrics.Metric
+}
+
+func (r *Reporter) metric(name string, t metrics.Type, tags metrics.Tags) *metrics.Metric {
+	k := key(name, tags)
+
+	m, ok := r.metrics[k]
+	if !ok {
+		m = &metrics.Metric{
+			Name: name,
+			Type: t,
+			Tags: make(metrics.Tags, len(tags)),
+		}
+		for k, v := range tags {
+			m.Tags[k] = v
+		}
+		if t == metrics.TypeTiming {
+			m.Buckets = make([]metrics.Bucket, len(r.buckets))
+			for i, b := range r.buckets {
+				m.Buckets[i].Bound = b
+			}
+		}
+		r.metrics[k] = m
+	}
+
+	return m
+}
+
+func (r *Reporter) Count(name string, value int64, tags metrics.Tags) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.metric(name, metrics.TypeCounter, tags).Value += float64(value)
+	return nil
+}
+
+func (r *Reporter) Gauge(name string, value float64, tags metrics.Tags) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.metric(name, metrics.TypeGauge, tags).Value = value
+	return nil
+}
+
+func (r *Reporter) Timing(name string, value time.Duration, tags metrics.Tags) error {
+	r.Lock()
+	defer r.Unlock()
+
+	m := r.metric(name, metrics.TypeTiming, tags)
+	m.Value += value.Seconds()
+	m.Count++
+	for i := range m.Buckets {
+		if value <= m.Buckets[i].Bound {
+			m.Buckets[i].Count++
+		}
+	}
+	return nil
+}
+
+// Read returns a copy of the metrics sorted by name and tags
+func (r *Reporter) Read() ([]*metrics.Metric, error) {
+	r.RLock()
+	keys := make([]string, 0, len(r.metrics))
+	for k := range r.metrics {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	list := make([]*metrics.Metric, 0, len(keys))
+	for _, k := range keys {
+		m := *r.metrics[k]
+		m.Buckets = append([]metrics.Bucket(nil), m.Buckets...)
+		list = append(list, &m)
+	}
+	r.RUnlock()
+
+	return list, nil
+}
+
+// key of the metric of the name and tags
+func key(name string, tags metrics.Tags) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// NewReporter returns an in memory reporter of timings with the bucket
+// bounds, metrics.DefaultBuckets if none
+func NewReporter(buckets ...time.Duration) metrics.Reporter {
+	if len(buckets) == 0 {
+		buckets = metrics.DefaultBuckets
+	}
+
+	return &Reporter{
+		buckets: buckets,
+		metrics: make(map[string]*metrics.Metric),
+	}
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/metrics"
+)
+
+func TestReporter(t *testing.T) {
+	r := NewReporter(10*time.Millisecond, 100*time.Millisecond)
+	tags := metrics.Tags{"service": "foo"}
+
+	r.Count("requests", 1, tags)
+	r.Count("requests", 2, metrics.Tags{"service": "foo"})
+	r.Count("requests", 1, metrics.Tags{"service": "bar"})
+	r.Gauge("in_flight", 3, tags)
+	r.Gauge("in_flight", 1, tags)
+	r.Timing("latency", 5*time.Millisecond, tags)
+	r.Timing("latency", 50*time.Millisecond, tags)
+	r.Timing("latency", time.Second, tags)
+
+	list, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 4 {
+		t.Fatalf("Expected 4 metrics, got %d", len(list))
+	}
+
+	// sorted by name then tags
+	inFlight, latency, bar, foo := list[0], list[1], list[2], list[3]
+	if inFlight.Type != metrics.TypeGauge || inFlight.Value != 1 {
+		t.Fatalf("Expected the last value of the gauge, got %+v", inFlight)
+	}
+	if bar.Tags["service"] != "bar" || bar.Value != 1 || foo.Value != 3 {
+		t.Fatalf("Expected the counters by tags, got %+v and %+v", bar, foo)
+	}
+	if latency.Type != metrics.TypeTiming || latency.Count != 3 {
+		t.Fatalf("Expected 3 timings, got %+v", latency)
+	}
+	if latency.Buckets[0].Count != 1 || latency.Buckets[1].Count != 2 {
+		t.Fatalf("Unexpected buckets %+v", latency.Buckets)
+	}
+}
+
+func TestGauges(t *testing.T) {
+	r := NewReporter()
+	g := metrics.NewGauges(r)
+
+	foo := metrics.Tags{"service": "foo"}
+	g.Add("in_flight", foo, 1)
+	g.Add("in_flight", metrics.Tags{"service": "foo"}, 1)
+	g.Add("in_flight", metrics.Tags{"service": "bar"}, 1)
+	g.Add("in_flight", foo, -1)
+
+	list, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range list {
+		want := 1.0
+		if m.Value != want {
+			t.Fatalf("Expected %s of %s to be %v, got %v", m.Name, m.Tags["service"], want, m.Value)
+		}
+	}
+}
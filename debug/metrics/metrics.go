@@ -0,0 +1,106 @@
+// Package metrics provides an interface for reporting metrics
+package metrics
+
+import (
+	"time"
+)
+
+// Reporter is an interface for reporting metrics to a backend e.g. prometheus
+type Reporter interface {
+	// Count adds the value to a counter
+	Count(name string, value int64, tags Tags) error
+	// Gauge sets the value of a gauge
+	Gauge(name string, value float64, tags Tags) error
+	// Timing records a duration in a histogram
+	Timing(name string, value time.Duration, tags Tags) error
+	// Read the metrics reported
+	Read() ([]*Metric, error)
+}
+
+// Tags are the dimensions of a metric e.g. the service and endpoint called
+type Tags map[string]string
+
+// Type of a metric
+type Type int
+
+const (
+	// TypeCounter is a metric which only goes up
+	TypeCounter Type = iota
+	// TypeGauge is a metric which goes up and down
+	TypeGauge
+	// TypeTiming is a histogram of durations
+	TypeTiming
+)
+
+// String returns human readable metric type
+func (t Type) String() string {
+	switch t {
+	case TypeCounter:
+		return "counter"
+	case TypeGauge:
+		return "gauge"
+	case TypeTiming:
+		return "timing"
+	default:
+		return "unknown"
+	}
+}
+
+// Metric is the value of a metric with its tags
+type Metric struct {
+	// Name of the metric
+	Name string
+	// Type of the metric
+	Type Type
+	// Tags of the metric
+	Tags Tags
+	// Value of a counter or gauge, the sum of the durations of a timing
+	Value float64
+	// Count of the durations of a timing
+	Count int64
+	// Buckets of a timing
+	Buckets []Bucket
+}
+
+// Bucket is the number of durations of a timing up to its bound
+type Bucket struct {
+	Bound time.Duration
+	Count int64
+}
+
+var (
+	// DefaultBuckets are the bounds of the buckets of timings
+	DefaultBuckets = []time.Duration{
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+		25 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2500 * time.Millisecond,
+		5 * time.Second,
+		10 * time.Second,
+	}
+
+	DefaultReporter Reporter = new(noop)
+)
+
+type noop struct{}
+
+func (n *noop) Count(string, int64, Tags) error {
+	return nil
+}
+
+func (n *noop) Gauge(string, float64, Tags) error {
+	return nil
+}
+
+func (n *noop) Timing(string, time.Duration, Tags) error {
+	return nil
+}
+
+func (n *noop) Read() ([]*Metric, error) {
+	return nil, nil
+}
@@ -0,0 +1,123 @@
+// Package prometheus exposes the metrics of a reporter in the prometheus text
+// format, to be scraped by prometheus
+package prometheus
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/micro/go-micro/v2/debug/metrics"
+)
+
+// ContentType is the content type of the prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// escaper escapes the values of the labels
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Handler serves the metrics read from the reporter to prometheus
+func Handler(r metrics.Reporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		list, err := r.Read()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		Write(w, list)
+	})
+}
+
+// Write the metrics in the prometheus text format. The dots of the names are
+// replaced with underscores and the timings are written as histograms in
+// seconds e.g. micro.server.latency as micro_server_latency_bucket,
+// micro_server_latency_sum and micro_server_latency_count.
+func Write(w io.Writer, list []*metrics.Metric) error {
+	// the samples of a metric are written together
+	list = append([]*metrics.Metric(nil), list...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	b := bufio.NewWriter(w)
+
+	var last string
+	for _, m := range list {
+		name := sanitize(m.Name)
+		if name != last {
+			b.WriteString("# TYPE " + name + " " + typeOf(m.Type) + "\n")
+			last = name
+		}
+
+		switch m.Type {
+		case metrics.TypeTiming:
+			for _, bk := range m.Buckets {
+				le := strconv.FormatFloat(bk.Bound.Seconds(), 'g', -1, 64)
+				sample(b, name+"_bucket", m.Tags, "le", le, float64(bk.Count))
+			}
+			sample(b, name+"_bucket", m.Tags, "le", "+Inf", float64(m.Count))
+			sample(b, name+"_sum", m.Tags, "", "", m.Value)
+			sample(b, name+"_count", m.Tags, "", "", float64(m.Count))
+		default:
+			sample(b, name, m.Tags, "", "", m.Value)
+		}
+	}
+
+	return b.Flush()
+}
+
+// typeOf returns the prometheus type of the metric type
+func typeOf(t metrics.Type) string {
+	switch t {
+	case metrics.TypeCounter:
+		return "counter"
+	case metrics.TypeGauge:
+		return "gauge"
+	case metrics.TypeTiming:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// sample writes a sample with the tags sorted as labels, and the extra label
+// if any e.g. the bound of a bucket
+func sample(b *bufio.Writer, name string, tags metrics.Tags, key, val string, value float64) {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	labels := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		labels = append(labels, sanitize(k)+"="+`"`+escaper.Replace(tags[k])+`"`)
+	}
+	if len(key) > 0 {
+		labels = append(labels, key+"="+`"`+val+`"`)
+	}
+
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteString("{" + strings.Join(labels, ",") + "}")
+	}
+	b.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+// sanitize replaces the characters invalid in prometheus names with
+// underscores
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,51 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/metrics"
+	"github.com/micro/go-micro/v2/debug/metrics/memory"
+)
+
+func TestHandler(t *testing.T) {
+	r := memory.NewReporter(10*time.Millisecond, 100*time.Millisecond)
+	tags := metrics.Tags{"service": "foo", "endpoint": "Foo.Bar"}
+
+	r.Count("micro.server.requests", 2, metrics.Tags{"service": "foo", "code": "200"})
+	r.Count("micro.server.requests", 1, metrics.Tags{"service": "foo", "code": "500"})
+	r.Gauge("micro.server.in_flight", 3, metrics.Tags{"topic": "a\"b"})
+	r.Timing("micro.server.latency", 5*time.Millisecond, tags)
+	r.Timing("micro.server.latency", 50*time.Millisecond, tags)
+	r.Timing("micro.server.latency", time.Second, tags)
+
+	w := httptest.NewRecorder()
+	Handler(r).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatalf("Expected content type %s, got %s", ContentType, ct)
+	}
+
+	expected := `# TYPE micro_server_in_flight gauge
+micro_server_in_flight{topic="a\"b"} 3
+# TYPE micro_server_latency histogram
+micro_server_latency_bucket{endpoint="Foo.Bar",service="foo",le="0.01"} 1
+micro_server_latency_bucket{endpoint="Foo.Bar",service="foo",le="0.1"} 2
+micro_server_latency_bucket{endpoint="Foo.Bar",service="foo",le="+Inf"} 3
+micro_server_latency_sum{endpoint="Foo.Bar",service="foo"} 1.055
+micro_server_latency_count{endpoint="Foo.Bar",service="foo"} 3
+# TYPE micro_server_requests counter
+micro_server_requests{code="200",service="foo"} 2
+micro_server_requests{code="500",service="foo"} 1
+`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	if s := sanitize("micro.client.pool-size"); s != "micro_client_pool_size" {
+		t.Fatalf("Expected micro_client_pool_size, got %s", s)
+	}
+}
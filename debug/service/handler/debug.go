@@ -3,29 +3,52 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/debug/health"
 	"github.com/micro/go-micro/v2/debug/log"
+	"github.com/micro/go-micro/v2/debug/manifest"
 	proto "github.com/micro/go-micro/v2/debug/service/proto"
 	"github.com/micro/go-micro/v2/debug/stats"
 	"github.com/micro/go-micro/v2/debug/trace"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/router/export"
 	"github.com/micro/go-micro/v2/server"
 )
 
 // NewHandler returns an instance of the Debug Handler
 func NewHandler(c client.Client) *Debug {
 	return &Debug{
-		log:   log.DefaultLog,
-		stats: stats.DefaultStats,
-		trace: trace.DefaultTracer,
-		cache: c.Options().Cache,
+		health: health.DefaultHealth,
+		log:    log.DefaultLog,
+		stats:  stats.DefaultStats,
+		trace:  trace.DefaultTracer,
+		cache:  c.Options().Cache,
+		router: c.Options().Router,
 	}
 }
 
+// NewServerHandler returns an instance of the Debug Handler which also
+// describes the endpoints and the registration of the server, and reports
+// its health
+func NewServerHandler(c client.Client, s server.Server) *Debug {
+	d := NewHandler(c)
+	d.server = s
+	if h := s.Options().Health; h != nil {
+		d.health = h
+	}
+	return d
+}
+
 type Debug struct {
 	// must honour the debug handler
 	proto.DebugHandler
+	// the health of the services
+	health *health.Health
 	// the logger for retrieving logs
 	log log.Log
 	// the stats collector
@@ -34,10 +57,25 @@ type Debug struct {
 	trace trace.Tracer
 	// the cache
 	cache *client.Cache
+	// the router used by the client
+	router router.Router
+	// the server serving the handlers
+	server server.Server
 }
 
+// Health returns the health status of the service, or of the server if none,
+// "ok" if serving
 func (d *Debug) Health(ctx context.Context, req *proto.HealthRequest, rsp *proto.HealthResponse) error {
-	rsp.Status = "ok"
+	status, ok := d.health.Get(req.Service)
+	if !ok {
+		return errors.NotFound("go.micro.debug", "unknown service %s", req.Service)
+	}
+
+	if status == health.StatusServing {
+		rsp.Status = "ok"
+	} else {
+		rsp.Status = status.String()
+	}
 	return nil
 }
 
@@ -174,3 +212,152 @@ func (d *Debug) Cache(ctx context.Context, req *proto.CacheRequest, rsp *proto.C
 	rsp.Values = d.cache.List()
 	return nil
 }
+
+// Router returns the status of the router used by the client
+func (d *Debug) Router(ctx context.Context, req *proto.RouterRequest, rsp *proto.RouterResponse) error {
+	if d.router == nil {
+		return nil
+	}
+
+	status := d.router.Status()
+
+	rsp.Status = status.Code.String()
+	if status.Error != nil {
+		rsp.Error = status.Error.Error()
+	}
+	rsp.Routes = uint64(status.Routes)
+	rsp.Subscribers = uint64(status.Subscribers)
+	rsp.Adverts = status.Adverts
+	rsp.Watching = status.Watching
+
+	rsp.Services = make(map[string]uint64, len(status.Services))
+	for service, routes := range status.Services {
+		rsp.Services[service] = uint64(routes)
+	}
+
+	return nil
+}
+
+// Table exports the routing table of the router, by default as JSON
+func (d *Debug) Table(ctx context.Context, req *proto.TableRequest, rsp *proto.TableResponse) error {
+	if d.router == nil {
+		return nil
+	}
+
+	format := export.Format(req.Format)
+	if len(format) == 0 {
+		format = export.JSON
+	}
+
+	snapshot, err := d.router.Table().Dump()
+	if err != nil {
+		return errors.InternalServerError("go.micro.debug", "failed to dump routing table: %v", err)
+	}
+
+	table, err := export.Marshal(snapshot, format)
+	if err == export.ErrUnknownFormat {
+		return errors.BadRequest("go.micro.debug", "unknown table format %s", format)
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.debug", "failed to export routing table: %v", err)
+	}
+
+	rsp.Format = string(format)
+	rsp.Table = table
+	rsp.Routes = uint64(len(snapshot.Routes))
+
+	return nil
+}
+
+// Manifest returns the manifest of the service
+func (d *Debug) Manifest(ctx context.Context, req *proto.ManifestRequest, rsp *proto.ManifestResponse) error {
+	m := manifest.Get()
+	if m == nil {
+		return errors.NotFound("go.micro.debug", "manifest not generated")
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return errors.InternalServerError("go.micro.debug", "failed to encode manifest: %v", err)
+	}
+
+	rsp.Name = m.Name
+	rsp.Version = m.Version
+	rsp.Manifest = b
+
+	return nil
+}
+
+// Endpoints returns the endpoints of the handlers and subscribers of the
+// server with the schemas of their requests and responses
+func (d *Debug) Endpoints(ctx context.Context, req *proto.EndpointsRequest, rsp *proto.EndpointsResponse) error {
+	if d.server == nil {
+		return nil
+	}
+
+	endpoints, ok := server.Endpoints(d.server)
+	if !ok {
+		return errors.NotImplemented("go.micro.debug", "server %s doesn't describe its endpoints", d.server.String())
+	}
+
+	opts := d.server.Options()
+	rsp.Name = opts.Name
+	rsp.Version = opts.Version
+
+	for _, e := range endpoints {
+		ep := &proto.Endpoint{
+			Name:     e.Name,
+			Request:  toValue(e.Request),
+			Response: toValue(e.Response),
+			Metadata: e.Metadata,
+		}
+
+		if e.Metadata["subscriber"] == "true" {
+			rsp.Subscribers = append(rsp.Subscribers, ep)
+		} else {
+			rsp.Handlers = append(rsp.Handlers, ep)
+		}
+	}
+
+	return nil
+}
+
+// Registration returns the status of the registration of the server, so a
+// service failing to register is visible while it retries
+func (d *Debug) Registration(ctx context.Context, req *proto.RegistrationRequest, rsp *proto.RegistrationResponse) error {
+	if d.server == nil {
+		return nil
+	}
+
+	status, ok := server.RegistrationStatus(d.server)
+	if !ok {
+		return errors.NotImplemented("go.micro.debug", "server %s doesn't track its registration", d.server.String())
+	}
+
+	rsp.Registered = status.Registered
+	rsp.Failures = int64(status.Failures)
+	if status.Error != nil {
+		rsp.Error = status.Error.Error()
+	}
+	if !status.Updated.IsZero() {
+		rsp.Updated = status.Updated.Unix()
+	}
+
+	return nil
+}
+
+func toValue(v *registry.Value) *proto.Value {
+	if v == nil {
+		return nil
+	}
+
+	values := make([]*proto.Value, 0, len(v.Values))
+	for _, vv := range v.Values {
+		values = append(values, toValue(vv))
+	}
+
+	return &proto.Value{
+		Name:   v.Name,
+		Type:   v.Type,
+		Values: values,
+	}
+}
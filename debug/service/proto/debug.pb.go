@@ -652,6 +652,610 @@ func (m *CacheResponse) GetValues() map[string]string {
 	return nil
 }
 
+type RouterRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RouterRequest) Reset()         { *m = RouterRequest{} }
+func (m *RouterRequest) String() string { return proto.CompactTextString(m) }
+func (*RouterRequest) ProtoMessage()    {}
+func (*RouterRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{11}
+}
+
+func (m *RouterRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RouterRequest.Unmarshal(m, b)
+}
+func (m *RouterRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RouterRequest.Marshal(b, m, deterministic)
+}
+func (m *RouterRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RouterRequest.Merge(m, src)
+}
+func (m *RouterRequest) XXX_Size() int {
+	return xxx_messageInfo_RouterRequest.Size(m)
+}
+func (m *RouterRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RouterRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RouterRequest proto.InternalMessageInfo
+
+type RouterResponse struct {
+	Status               string            `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error                string            `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Routes               uint64            `protobuf:"varint,3,opt,name=routes,proto3" json:"routes,omitempty"`
+	Services             map[string]uint64 `protobuf:"bytes,4,rep,name=services,proto3" json:"services,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Subscribers          uint64            `protobuf:"varint,5,opt,name=subscribers,proto3" json:"subscribers,omitempty"`
+	Adverts              uint64            `protobuf:"varint,6,opt,name=adverts,proto3" json:"adverts,omitempty"`
+	Watching             bool              `protobuf:"varint,7,opt,name=watching,proto3" json:"watching,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *RouterResponse) Reset()         { *m = RouterResponse{} }
+func (m *RouterResponse) String() string { return proto.CompactTextString(m) }
+func (*RouterResponse) ProtoMessage()    {}
+func (*RouterResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{12}
+}
+
+func (m *RouterResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RouterResponse.Unmarshal(m, b)
+}
+func (m *RouterResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RouterResponse.Marshal(b, m, deterministic)
+}
+func (m *RouterResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RouterResponse.Merge(m, src)
+}
+func (m *RouterResponse) XXX_Size() int {
+	return xxx_messageInfo_RouterResponse.Size(m)
+}
+func (m *RouterResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RouterResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RouterResponse proto.InternalMessageInfo
+
+func (m *RouterResponse) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *RouterResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *RouterResponse) GetRoutes() uint64 {
+	if m != nil {
+		return m.Routes
+	}
+	return 0
+}
+
+func (m *RouterResponse) GetServices() map[string]uint64 {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+func (m *RouterResponse) GetSubscribers() uint64 {
+	if m != nil {
+		return m.Subscribers
+	}
+	return 0
+}
+
+func (m *RouterResponse) GetAdverts() uint64 {
+	if m != nil {
+		return m.Adverts
+	}
+	return 0
+}
+
+func (m *RouterResponse) GetWatching() bool {
+	if m != nil {
+		return m.Watching
+	}
+	return false
+}
+
+type TableRequest struct {
+	Format               string   `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TableRequest) Reset()         { *m = TableRequest{} }
+func (m *TableRequest) String() string { return proto.CompactTextString(m) }
+func (*TableRequest) ProtoMessage()    {}
+func (*TableRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{13}
+}
+
+func (m *TableRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TableRequest.Unmarshal(m, b)
+}
+func (m *TableRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TableRequest.Marshal(b, m, deterministic)
+}
+func (m *TableRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TableRequest.Merge(m, src)
+}
+func (m *TableRequest) XXX_Size() int {
+	return xxx_messageInfo_TableRequest.Size(m)
+}
+func (m *TableRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TableRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TableRequest proto.InternalMessageInfo
+
+func (m *TableRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+type TableResponse struct {
+	Format               string   `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Table                []byte   `protobuf:"bytes,2,opt,name=table,proto3" json:"table,omitempty"`
+	Routes               uint64   `protobuf:"varint,3,opt,name=routes,proto3" json:"routes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TableResponse) Reset()         { *m = TableResponse{} }
+func (m *TableResponse) String() string { return proto.CompactTextString(m) }
+func (*TableResponse) ProtoMessage()    {}
+func (*TableResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{14}
+}
+
+func (m *TableResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TableResponse.Unmarshal(m, b)
+}
+func (m *TableResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TableResponse.Marshal(b, m, deterministic)
+}
+func (m *TableResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TableResponse.Merge(m, src)
+}
+func (m *TableResponse) XXX_Size() int {
+	return xxx_messageInfo_TableResponse.Size(m)
+}
+func (m *TableResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TableResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TableResponse proto.InternalMessageInfo
+
+func (m *TableResponse) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *TableResponse) GetTable() []byte {
+	if m != nil {
+		return m.Table
+	}
+	return nil
+}
+
+func (m *TableResponse) GetRoutes() uint64 {
+	if m != nil {
+		return m.Routes
+	}
+	return 0
+}
+
+type ManifestRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ManifestRequest) Reset()         { *m = ManifestRequest{} }
+func (m *ManifestRequest) String() string { return proto.CompactTextString(m) }
+func (*ManifestRequest) ProtoMessage()    {}
+func (*ManifestRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{15}
+}
+
+func (m *ManifestRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ManifestRequest.Unmarshal(m, b)
+}
+func (m *ManifestRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ManifestRequest.Marshal(b, m, deterministic)
+}
+func (m *ManifestRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ManifestRequest.Merge(m, src)
+}
+func (m *ManifestRequest) XXX_Size() int {
+	return xxx_messageInfo_ManifestRequest.Size(m)
+}
+func (m *ManifestRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ManifestRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ManifestRequest proto.InternalMessageInfo
+
+type ManifestResponse struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Manifest             []byte   `protobuf:"bytes,3,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ManifestResponse) Reset()         { *m = ManifestResponse{} }
+func (m *ManifestResponse) String() string { return proto.CompactTextString(m) }
+func (*ManifestResponse) ProtoMessage()    {}
+func (*ManifestResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{16}
+}
+
+func (m *ManifestResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ManifestResponse.Unmarshal(m, b)
+}
+func (m *ManifestResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ManifestResponse.Marshal(b, m, deterministic)
+}
+func (m *ManifestResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ManifestResponse.Merge(m, src)
+}
+func (m *ManifestResponse) XXX_Size() int {
+	return xxx_messageInfo_ManifestResponse.Size(m)
+}
+func (m *ManifestResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ManifestResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ManifestResponse proto.InternalMessageInfo
+
+func (m *ManifestResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ManifestResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ManifestResponse) GetManifest() []byte {
+	if m != nil {
+		return m.Manifest
+	}
+	return nil
+}
+
+type EndpointsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EndpointsRequest) Reset()         { *m = EndpointsRequest{} }
+func (m *EndpointsRequest) String() string { return proto.CompactTextString(m) }
+func (*EndpointsRequest) ProtoMessage()    {}
+func (*EndpointsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{17}
+}
+
+func (m *EndpointsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EndpointsRequest.Unmarshal(m, b)
+}
+func (m *EndpointsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EndpointsRequest.Marshal(b, m, deterministic)
+}
+func (m *EndpointsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EndpointsRequest.Merge(m, src)
+}
+func (m *EndpointsRequest) XXX_Size() int {
+	return xxx_messageInfo_EndpointsRequest.Size(m)
+}
+func (m *EndpointsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EndpointsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EndpointsRequest proto.InternalMessageInfo
+
+type EndpointsResponse struct {
+	Name                 string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version              string      `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Handlers             []*Endpoint `protobuf:"bytes,3,rep,name=handlers,proto3" json:"handlers,omitempty"`
+	Subscribers          []*Endpoint `protobuf:"bytes,4,rep,name=subscribers,proto3" json:"subscribers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *EndpointsResponse) Reset()         { *m = EndpointsResponse{} }
+func (m *EndpointsResponse) String() string { return proto.CompactTextString(m) }
+func (*EndpointsResponse) ProtoMessage()    {}
+func (*EndpointsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{18}
+}
+
+func (m *EndpointsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EndpointsResponse.Unmarshal(m, b)
+}
+func (m *EndpointsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EndpointsResponse.Marshal(b, m, deterministic)
+}
+func (m *EndpointsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EndpointsResponse.Merge(m, src)
+}
+func (m *EndpointsResponse) XXX_Size() int {
+	return xxx_messageInfo_EndpointsResponse.Size(m)
+}
+func (m *EndpointsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EndpointsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EndpointsResponse proto.InternalMessageInfo
+
+func (m *EndpointsResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *EndpointsResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *EndpointsResponse) GetHandlers() []*Endpoint {
+	if m != nil {
+		return m.Handlers
+	}
+	return nil
+}
+
+func (m *EndpointsResponse) GetSubscribers() []*Endpoint {
+	if m != nil {
+		return m.Subscribers
+	}
+	return nil
+}
+
+type Endpoint struct {
+	Name                 string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Request              *Value            `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	Response             *Value            `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	Metadata             map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Endpoint) Reset()         { *m = Endpoint{} }
+func (m *Endpoint) String() string { return proto.CompactTextString(m) }
+func (*Endpoint) ProtoMessage()    {}
+func (*Endpoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{19}
+}
+
+func (m *Endpoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Endpoint.Unmarshal(m, b)
+}
+func (m *Endpoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Endpoint.Marshal(b, m, deterministic)
+}
+func (m *Endpoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Endpoint.Merge(m, src)
+}
+func (m *Endpoint) XXX_Size() int {
+	return xxx_messageInfo_Endpoint.Size(m)
+}
+func (m *Endpoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_Endpoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Endpoint proto.InternalMessageInfo
+
+func (m *Endpoint) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Endpoint) GetRequest() *Value {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *Endpoint) GetResponse() *Value {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *Endpoint) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type Value struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Values               []*Value `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Value) Reset()         { *m = Value{} }
+func (m *Value) String() string { return proto.CompactTextString(m) }
+func (*Value) ProtoMessage()    {}
+func (*Value) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{20}
+}
+
+func (m *Value) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Value.Unmarshal(m, b)
+}
+func (m *Value) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Value.Marshal(b, m, deterministic)
+}
+func (m *Value) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Value.Merge(m, src)
+}
+func (m *Value) XXX_Size() int {
+	return xxx_messageInfo_Value.Size(m)
+}
+func (m *Value) XXX_DiscardUnknown() {
+	xxx_messageInfo_Value.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Value proto.InternalMessageInfo
+
+func (m *Value) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Value) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Value) GetValues() []*Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type RegistrationRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegistrationRequest) Reset()         { *m = RegistrationRequest{} }
+func (m *RegistrationRequest) String() string { return proto.CompactTextString(m) }
+func (*RegistrationRequest) ProtoMessage()    {}
+func (*RegistrationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{21}
+}
+
+func (m *RegistrationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegistrationRequest.Unmarshal(m, b)
+}
+func (m *RegistrationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegistrationRequest.Marshal(b, m, deterministic)
+}
+func (m *RegistrationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegistrationRequest.Merge(m, src)
+}
+func (m *RegistrationRequest) XXX_Size() int {
+	return xxx_messageInfo_RegistrationRequest.Size(m)
+}
+func (m *RegistrationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegistrationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegistrationRequest proto.InternalMessageInfo
+
+type RegistrationResponse struct {
+	Registered           bool     `protobuf:"varint,1,opt,name=registered,proto3" json:"registered,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Failures             int64    `protobuf:"varint,3,opt,name=failures,proto3" json:"failures,omitempty"`
+	Updated              int64    `protobuf:"varint,4,opt,name=updated,proto3" json:"updated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegistrationResponse) Reset()         { *m = RegistrationResponse{} }
+func (m *RegistrationResponse) String() string { return proto.CompactTextString(m) }
+func (*RegistrationResponse) ProtoMessage()    {}
+func (*RegistrationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_df91f41a5db378e6, []int{22}
+}
+
+func (m *RegistrationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegistrationResponse.Unmarshal(m, b)
+}
+func (m *RegistrationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegistrationResponse.Marshal(b, m, deterministic)
+}
+func (m *RegistrationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegistrationResponse.Merge(m, src)
+}
+func (m *RegistrationResponse) XXX_Size() int {
+	return xxx_messageInfo_RegistrationResponse.Size(m)
+}
+func (m *RegistrationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegistrationResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegistrationResponse proto.InternalMessageInfo
+
+func (m *RegistrationResponse) GetRegistered() bool {
+	if m != nil {
+		return m.Registered
+	}
+	return false
+}
+
+func (m *RegistrationResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *RegistrationResponse) GetFailures() int64 {
+	if m != nil {
+		return m.Failures
+	}
+	return 0
+}
+
+func (m *RegistrationResponse) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("SpanType", SpanType_name, SpanType_value)
 	proto.RegisterType((*HealthRequest)(nil), "HealthRequest")
@@ -668,53 +1272,95 @@ func init() {
 	proto.RegisterType((*CacheRequest)(nil), "CacheRequest")
 	proto.RegisterType((*CacheResponse)(nil), "CacheResponse")
 	proto.RegisterMapType((map[string]string)(nil), "CacheResponse.ValuesEntry")
+	proto.RegisterType((*RouterRequest)(nil), "RouterRequest")
+	proto.RegisterType((*RouterResponse)(nil), "RouterResponse")
+	proto.RegisterMapType((map[string]uint64)(nil), "RouterResponse.ServicesEntry")
+	proto.RegisterType((*TableRequest)(nil), "TableRequest")
+	proto.RegisterType((*TableResponse)(nil), "TableResponse")
+	proto.RegisterType((*ManifestRequest)(nil), "ManifestRequest")
+	proto.RegisterType((*ManifestResponse)(nil), "ManifestResponse")
+	proto.RegisterType((*EndpointsRequest)(nil), "EndpointsRequest")
+	proto.RegisterType((*EndpointsResponse)(nil), "EndpointsResponse")
+	proto.RegisterType((*Endpoint)(nil), "Endpoint")
+	proto.RegisterMapType((map[string]string)(nil), "Endpoint.MetadataEntry")
+	proto.RegisterType((*Value)(nil), "Value")
+	proto.RegisterType((*RegistrationRequest)(nil), "RegistrationRequest")
+	proto.RegisterType((*RegistrationResponse)(nil), "RegistrationResponse")
 }
 
 func init() { proto.RegisterFile("debug/service/proto/debug.proto", fileDescriptor_df91f41a5db378e6) }
 
 var fileDescriptor_df91f41a5db378e6 = []byte{
-	// 646 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0xdb, 0x6e, 0xd3, 0x4a,
-	0x14, 0x8d, 0xed, 0x38, 0xb1, 0x77, 0x62, 0x9f, 0x6a, 0xce, 0x45, 0x96, 0x0f, 0xd0, 0xca, 0x12,
-	0x52, 0xb8, 0x68, 0x02, 0xe1, 0x85, 0xcb, 0x1b, 0x14, 0x09, 0xa4, 0xd2, 0x4a, 0xd3, 0x96, 0xf7,
-	0xa9, 0x3d, 0x4a, 0x03, 0xf5, 0x85, 0x99, 0x71, 0xa5, 0xbc, 0xf0, 0x23, 0xfc, 0x04, 0xff, 0x82,
-	0xf8, 0x1f, 0x34, 0x17, 0xb7, 0xb6, 0x10, 0xaa, 0x10, 0x6f, 0x5e, 0x6b, 0xaf, 0xd9, 0xd9, 0x7b,
-	0x69, 0x65, 0xc3, 0x6e, 0xc1, 0xce, 0xda, 0xf5, 0x52, 0x30, 0x7e, 0xb9, 0xc9, 0xd9, 0xb2, 0xe1,
-	0xb5, 0xac, 0x97, 0x9a, 0xc3, 0xfa, 0x3b, 0xbb, 0x07, 0xd1, 0x1b, 0x46, 0x2f, 0xe4, 0x39, 0x61,
-	0x9f, 0x5a, 0x26, 0x24, 0x4a, 0x60, 0x6a, 0xd5, 0x89, 0xb3, 0xe7, 0x2c, 0x42, 0xd2, 0xc1, 0x6c,
-	0x01, 0x71, 0x27, 0x15, 0x4d, 0x5d, 0x09, 0x86, 0xfe, 0x83, 0x89, 0x90, 0x54, 0xb6, 0xc2, 0x4a,
-	0x2d, 0xca, 0x16, 0x30, 0x3f, 0x96, 0x54, 0x8a, 0x9b, 0x7b, 0x7e, 0x77, 0x20, 0xb2, 0x52, 0xdb,
-	0xf3, 0x16, 0x84, 0x72, 0x53, 0x32, 0x21, 0x69, 0xd9, 0x68, 0xf5, 0x98, 0x5c, 0x13, 0xba, 0x93,
-	0xa4, 0x5c, 0xb2, 0x22, 0x71, 0x75, 0xad, 0x83, 0x6a, 0x96, 0xb6, 0x51, 0xc2, 0xc4, 0xd3, 0x05,
-	0x8b, 0x14, 0x5f, 0xb2, 0xb2, 0xe6, 0xdb, 0x64, 0x6c, 0x78, 0x83, 0x54, 0x27, 0x79, 0xce, 0x19,
-	0x2d, 0x44, 0xe2, 0x9b, 0x4e, 0x16, 0xa2, 0x18, 0xdc, 0x75, 0x9e, 0x4c, 0x34, 0xe9, 0xae, 0x73,
-	0x94, 0x42, 0xc0, 0xcd, 0x22, 0x22, 0x99, 0x6a, 0xf6, 0x0a, 0xab, 0xee, 0x8c, 0xf3, 0x9a, 0x8b,
-	0x24, 0x30, 0xdd, 0x0d, 0xca, 0x3e, 0x00, 0x1c, 0xd4, 0xeb, 0x1b, 0xf7, 0x37, 0x0e, 0x72, 0x46,
-	0x4b, 0xbd, 0x4e, 0x40, 0x2c, 0x42, 0xff, 0x80, 0x9f, 0xd7, 0x6d, 0x25, 0xf5, 0x32, 0x1e, 0x31,
-	0x40, 0xb1, 0x62, 0x53, 0xe5, 0x4c, 0xaf, 0xe2, 0x11, 0x03, 0xb2, 0xaf, 0x0e, 0x4c, 0x08, 0xcb,
-	0x6b, 0x5e, 0xfc, 0x6c, 0x9e, 0xd7, 0x37, 0xef, 0x31, 0x04, 0x25, 0x93, 0xb4, 0xa0, 0x92, 0x26,
-	0xee, 0x9e, 0xb7, 0x98, 0xad, 0xfe, 0xc5, 0xe6, 0x21, 0x7e, 0x67, 0xf9, 0xd7, 0x95, 0xe4, 0x5b,
-	0x72, 0x25, 0x53, 0x93, 0x97, 0x4c, 0x08, 0xba, 0x36, 0xb6, 0x86, 0xa4, 0x83, 0xe9, 0x0b, 0x88,
-	0x06, 0x8f, 0xd0, 0x0e, 0x78, 0x1f, 0xd9, 0xd6, 0x2e, 0xa8, 0x3e, 0xd5, 0xb8, 0x97, 0xf4, 0xa2,
-	0x65, 0x7a, 0xb7, 0x90, 0x18, 0xf0, 0xdc, 0x7d, 0xea, 0x64, 0x77, 0x60, 0x7e, 0xc2, 0x69, 0xce,
-	0x3a, 0x83, 0x62, 0x70, 0x37, 0x85, 0x7d, 0xea, 0x6e, 0x8a, 0xec, 0x21, 0x44, 0xb6, 0x6e, 0x53,
-	0xf1, 0x3f, 0xf8, 0xa2, 0xa1, 0x95, 0x0a, 0x9a, 0x9a, 0xdb, 0xc7, 0xc7, 0x0d, 0xad, 0x88, 0xe1,
-	0xb2, 0x2f, 0x2e, 0x8c, 0x15, 0x56, 0x3f, 0x28, 0xd5, 0x33, 0xdb, 0xc9, 0x00, 0xdb, 0xdc, 0xed,
-	0x9a, 0x2b, 0xcf, 0x1b, 0xca, 0x99, 0x35, 0x37, 0x24, 0x16, 0x21, 0x04, 0xe3, 0x8a, 0x96, 0xc6,
-	0xdc, 0x90, 0xe8, 0xef, 0x7e, 0xde, 0xfc, 0x61, 0xde, 0x52, 0x08, 0x8a, 0x96, 0x53, 0xb9, 0xa9,
-	0x2b, 0x9b, 0x95, 0x2b, 0x8c, 0x96, 0x3d, 0xa3, 0xa7, 0x7a, 0xe0, 0xbf, 0xf5, 0xc0, 0xbf, 0xb4,
-	0xf9, 0x36, 0x8c, 0xe5, 0xb6, 0x61, 0x3a, 0x44, 0xf1, 0x2a, 0xd4, 0xe2, 0x93, 0x6d, 0xc3, 0x88,
-	0xa6, 0xff, 0xcc, 0xeb, 0x18, 0xe6, 0xaf, 0x68, 0x7e, 0xde, 0x79, 0x9d, 0x7d, 0x86, 0xc8, 0x62,
-	0xeb, 0xed, 0x0a, 0x26, 0x5a, 0xdd, 0x99, 0x9b, 0xe2, 0x41, 0x1d, 0xbf, 0xd7, 0x45, 0x33, 0xb2,
-	0x55, 0xa6, 0xcf, 0x60, 0xd6, 0xa3, 0x7f, 0x67, 0x9e, 0xfb, 0x77, 0x21, 0xe8, 0xd6, 0x43, 0x33,
-	0x98, 0xbe, 0x3d, 0x7c, 0x79, 0x74, 0x7a, 0xb8, 0xbf, 0x33, 0x42, 0x73, 0x08, 0x8e, 0x4e, 0x4f,
-	0x0c, 0x72, 0x56, 0xdf, 0x1c, 0xf0, 0xf7, 0xd5, 0xa1, 0x42, 0xbb, 0xe0, 0x1d, 0xd4, 0x6b, 0x34,
-	0xc3, 0xd7, 0xff, 0xa8, 0x74, 0x6a, 0x83, 0x9b, 0x8d, 0x1e, 0x39, 0xe8, 0x01, 0x4c, 0xcc, 0x61,
-	0x42, 0x31, 0x1e, 0x1c, 0xb3, 0xf4, 0x2f, 0x3c, 0xbc, 0x58, 0xd9, 0x08, 0x2d, 0xc0, 0xd7, 0x07,
-	0x07, 0x45, 0xb8, 0x7f, 0xa3, 0xd2, 0x18, 0x0f, 0xee, 0x90, 0x51, 0xea, 0x10, 0xa2, 0x08, 0xf7,
-	0xc3, 0x9a, 0xc6, 0x78, 0x90, 0x4d, 0xa3, 0xd4, 0x96, 0xa1, 0x08, 0xf7, 0xad, 0x4e, 0xe3, 0xa1,
-	0x93, 0xd9, 0xe8, 0x6c, 0xa2, 0xaf, 0xee, 0x93, 0x1f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x22, 0x65,
-	0x99, 0x10, 0x98, 0x05, 0x00, 0x00,
+	// 1091 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0x4f, 0x6f, 0xdc, 0x44,
+	0x14, 0xdf, 0x5d, 0xef, 0x1f, 0xef, 0xdb, 0xb5, 0x93, 0x4c, 0x93, 0x62, 0x19, 0x9a, 0x46, 0x23,
+	0x15, 0x05, 0x8a, 0x26, 0x34, 0xe5, 0x40, 0x41, 0x5c, 0xa0, 0x95, 0x40, 0x6a, 0x1b, 0x69, 0x92,
+	0x70, 0xe2, 0x32, 0x59, 0x4f, 0x36, 0x86, 0xac, 0x6d, 0x66, 0xc6, 0x41, 0xb9, 0x70, 0xe0, 0x3b,
+	0x70, 0xe2, 0xce, 0x99, 0xcf, 0x82, 0x04, 0x9f, 0x07, 0xcd, 0x3f, 0xc7, 0x4e, 0x13, 0x22, 0xe8,
+	0xcd, 0xbf, 0x37, 0xcf, 0x6f, 0xde, 0xfb, 0xcd, 0x9b, 0xdf, 0x1b, 0x78, 0x98, 0xf1, 0x93, 0x7a,
+	0xb9, 0x27, 0xb9, 0xb8, 0xc8, 0x17, 0x7c, 0xaf, 0x12, 0xa5, 0x2a, 0xf7, 0x8c, 0x8d, 0x98, 0x6f,
+	0xfc, 0x01, 0x44, 0x5f, 0x73, 0x76, 0xae, 0xce, 0x28, 0xff, 0xb1, 0xe6, 0x52, 0xa1, 0x04, 0x26,
+	0xce, 0x3b, 0xe9, 0xef, 0xf4, 0x77, 0xa7, 0xd4, 0x43, 0xbc, 0x0b, 0xb1, 0x77, 0x95, 0x55, 0x59,
+	0x48, 0x8e, 0xee, 0xc3, 0x58, 0x2a, 0xa6, 0x6a, 0xe9, 0x5c, 0x1d, 0xc2, 0xbb, 0x30, 0x3f, 0x54,
+	0x4c, 0xc9, 0xbb, 0x63, 0xfe, 0xd5, 0x87, 0xc8, 0xb9, 0xba, 0x98, 0xef, 0xc1, 0x54, 0xe5, 0x2b,
+	0x2e, 0x15, 0x5b, 0x55, 0xc6, 0x7b, 0x48, 0xaf, 0x0c, 0x26, 0x92, 0x62, 0x42, 0xf1, 0x2c, 0x19,
+	0x98, 0x35, 0x0f, 0x75, 0x2e, 0x75, 0xa5, 0x1d, 0x93, 0xc0, 0x2c, 0x38, 0xa4, 0xed, 0x2b, 0xbe,
+	0x2a, 0xc5, 0x65, 0x32, 0xb4, 0x76, 0x8b, 0x74, 0x24, 0x75, 0x26, 0x38, 0xcb, 0x64, 0x32, 0xb2,
+	0x91, 0x1c, 0x44, 0x31, 0x0c, 0x96, 0x8b, 0x64, 0x6c, 0x8c, 0x83, 0xe5, 0x02, 0xa5, 0x10, 0x0a,
+	0x5b, 0x88, 0x4c, 0x26, 0xc6, 0xda, 0x60, 0x1d, 0x9d, 0x0b, 0x51, 0x0a, 0x99, 0x84, 0x36, 0xba,
+	0x45, 0xf8, 0x7b, 0x80, 0x97, 0xe5, 0xf2, 0xce, 0xfa, 0x2d, 0x83, 0x82, 0xb3, 0x95, 0x29, 0x27,
+	0xa4, 0x0e, 0xa1, 0x4d, 0x18, 0x2d, 0xca, 0xba, 0x50, 0xa6, 0x98, 0x80, 0x5a, 0xa0, 0xad, 0x32,
+	0x2f, 0x16, 0xdc, 0x94, 0x12, 0x50, 0x0b, 0xf0, 0x1f, 0x7d, 0x18, 0x53, 0xbe, 0x28, 0x45, 0xf6,
+	0x26, 0x79, 0x41, 0x9b, 0xbc, 0x27, 0x10, 0xae, 0xb8, 0x62, 0x19, 0x53, 0x2c, 0x19, 0xec, 0x04,
+	0xbb, 0xb3, 0xfd, 0x2d, 0x62, 0x7f, 0x24, 0xaf, 0x9c, 0xfd, 0x45, 0xa1, 0xc4, 0x25, 0x6d, 0xdc,
+	0x74, 0xe6, 0x2b, 0x2e, 0x25, 0x5b, 0x5a, 0x5a, 0xa7, 0xd4, 0xc3, 0xf4, 0x73, 0x88, 0x3a, 0x3f,
+	0xa1, 0x75, 0x08, 0x7e, 0xe0, 0x97, 0xae, 0x40, 0xfd, 0xa9, 0xd3, 0xbd, 0x60, 0xe7, 0x35, 0x37,
+	0xb5, 0x4d, 0xa9, 0x05, 0x9f, 0x0d, 0x3e, 0xed, 0xe3, 0x6d, 0x98, 0x1f, 0x09, 0xb6, 0xe0, 0x9e,
+	0xa0, 0x18, 0x06, 0x79, 0xe6, 0x7e, 0x1d, 0xe4, 0x19, 0xfe, 0x08, 0x22, 0xb7, 0xee, 0xba, 0xe2,
+	0x5d, 0x18, 0xc9, 0x8a, 0x15, 0xba, 0xd1, 0x74, 0xde, 0x23, 0x72, 0x58, 0xb1, 0x82, 0x5a, 0x1b,
+	0xfe, 0x6d, 0x00, 0x43, 0x8d, 0xf5, 0x86, 0x4a, 0xff, 0xe6, 0x22, 0x59, 0xe0, 0x82, 0x0f, 0x7c,
+	0x70, 0xcd, 0x79, 0xc5, 0x04, 0x77, 0xe4, 0x4e, 0xa9, 0x43, 0x08, 0xc1, 0xb0, 0x60, 0x2b, 0x4b,
+	0xee, 0x94, 0x9a, 0xef, 0x76, 0xbf, 0x8d, 0xba, 0xfd, 0x96, 0x42, 0x98, 0xd5, 0x82, 0xa9, 0xbc,
+	0x2c, 0x5c, 0xaf, 0x34, 0x18, 0xed, 0xb5, 0x88, 0x9e, 0x98, 0x84, 0xef, 0x99, 0x84, 0x6f, 0xa5,
+	0xf9, 0x01, 0x0c, 0xd5, 0x65, 0xc5, 0x4d, 0x13, 0xc5, 0xfb, 0x53, 0xe3, 0x7c, 0x74, 0x59, 0x71,
+	0x6a, 0xcc, 0x6f, 0xc7, 0x75, 0x0c, 0xf3, 0xaf, 0xd8, 0xe2, 0xcc, 0x73, 0x8d, 0x7f, 0x86, 0xc8,
+	0x61, 0xc7, 0xed, 0x3e, 0x8c, 0x8d, 0xb7, 0x27, 0x37, 0x25, 0x9d, 0x75, 0xf2, 0xad, 0x59, 0xb4,
+	0x29, 0x3b, 0xcf, 0xf4, 0x19, 0xcc, 0x5a, 0xe6, 0xff, 0x94, 0xcf, 0x1a, 0x44, 0xb4, 0xac, 0x15,
+	0x17, 0x3e, 0xa1, 0xdf, 0x07, 0x10, 0x7b, 0xcb, 0xbf, 0x0b, 0x8b, 0x8e, 0x6a, 0x2e, 0x98, 0x8f,
+	0x6a, 0x80, 0xf6, 0x16, 0xfa, 0x7f, 0xe9, 0xaf, 0xbe, 0x45, 0xe8, 0x19, 0x84, 0xee, 0x9e, 0xc9,
+	0x64, 0x68, 0x4a, 0x7b, 0x40, 0xba, 0x1b, 0x91, 0x43, 0xb7, 0xee, 0x0e, 0xc4, 0xbb, 0xa3, 0x1d,
+	0x98, 0xc9, 0xfa, 0x44, 0x2e, 0x44, 0x7e, 0xc2, 0x85, 0x57, 0x88, 0xb6, 0x49, 0x77, 0x06, 0xcb,
+	0x2e, 0xb8, 0x50, 0xd2, 0x1d, 0xbf, 0x87, 0xba, 0x33, 0x7e, 0x62, 0x6a, 0x71, 0x96, 0x17, 0x4b,
+	0xa3, 0x17, 0x21, 0x6d, 0xb0, 0x3e, 0xc9, 0xce, 0x96, 0x77, 0x31, 0x37, 0x6c, 0x33, 0xf7, 0x3e,
+	0xcc, 0x8f, 0xd8, 0xc9, 0x79, 0x73, 0x6b, 0xee, 0xc3, 0xf8, 0xb4, 0x14, 0x2b, 0xa6, 0x3c, 0x4b,
+	0x16, 0xe1, 0x63, 0x88, 0x9c, 0xdf, 0x15, 0x9d, 0x37, 0x39, 0x9a, 0xfb, 0xa2, 0x1d, 0xcd, 0x56,
+	0x73, 0x6a, 0xc1, 0x6d, 0x74, 0xe2, 0x0d, 0x58, 0x7b, 0xc5, 0x8a, 0xfc, 0x94, 0x4b, 0xe5, 0x8f,
+	0xee, 0x3b, 0x58, 0xbf, 0x32, 0xb9, 0xcd, 0xfc, 0x35, 0xea, 0x77, 0xaf, 0xd1, 0x05, 0x17, 0x52,
+	0xdf, 0x15, 0x7b, 0x72, 0x1e, 0x6a, 0xb2, 0x56, 0x2e, 0x82, 0xd9, 0x6e, 0x4e, 0x1b, 0x8c, 0x11,
+	0xac, 0xbf, 0x28, 0xb2, 0xaa, 0xcc, 0x8b, 0x66, 0x94, 0xe0, 0x5f, 0xfb, 0xb0, 0xd1, 0x32, 0xfe,
+	0xaf, 0x3d, 0x1f, 0x41, 0x78, 0xc6, 0x8a, 0xec, 0x5c, 0x9f, 0x6c, 0x60, 0xfa, 0x62, 0x4a, 0x7c,
+	0x4c, 0xda, 0x2c, 0xa1, 0xc7, 0xdd, 0x1e, 0x18, 0x5e, 0xf7, 0x6c, 0xaf, 0xe2, 0xbf, 0xfb, 0x10,
+	0xfa, 0x95, 0x1b, 0xd3, 0xd9, 0x81, 0x89, 0x9b, 0x1a, 0x26, 0x9d, 0xd9, 0xfe, 0xd8, 0x5e, 0x2c,
+	0xea, 0xcd, 0x08, 0xeb, 0x39, 0x63, 0x0b, 0x4a, 0x82, 0x8e, 0x4b, 0x63, 0x47, 0x4f, 0x5b, 0xca,
+	0x62, 0x13, 0x7a, 0xa7, 0x49, 0xe8, 0x36, 0x75, 0x79, 0x3b, 0xf9, 0x38, 0x80, 0x91, 0x49, 0xe2,
+	0xc6, 0xa2, 0x90, 0xd3, 0x2d, 0xfb, 0x97, 0xf9, 0x46, 0xdb, 0x8d, 0x9c, 0x58, 0x6e, 0x7d, 0x11,
+	0xce, 0x8a, 0xb7, 0xe0, 0x1e, 0xe5, 0xcb, 0x5c, 0x2a, 0x2b, 0x96, 0xfe, 0x60, 0x7f, 0xe9, 0xc3,
+	0x66, 0xd7, 0xee, 0x4a, 0xde, 0x06, 0x10, 0xc6, 0xce, 0x05, 0xb7, 0x33, 0x22, 0xa4, 0x2d, 0xcb,
+	0x2d, 0x9a, 0x90, 0x42, 0x78, 0xca, 0xf2, 0xf3, 0x5a, 0xb8, 0x36, 0x0e, 0x68, 0x83, 0x75, 0x67,
+	0xd4, 0x55, 0xc6, 0xb4, 0xa8, 0xdb, 0x41, 0xea, 0xe1, 0x87, 0x8f, 0x20, 0xf4, 0xd2, 0x8b, 0x66,
+	0x30, 0xf9, 0xe6, 0xf5, 0x97, 0x07, 0xc7, 0xaf, 0x9f, 0xaf, 0xf7, 0xd0, 0x1c, 0xc2, 0x83, 0xe3,
+	0x23, 0x8b, 0xfa, 0xfb, 0x7f, 0x06, 0x30, 0x7a, 0xae, 0x1f, 0x51, 0xe8, 0x21, 0x04, 0x2f, 0xcb,
+	0x25, 0x9a, 0x91, 0xab, 0x69, 0x9f, 0x4e, 0xdc, 0x50, 0xc5, 0xbd, 0x8f, 0xfb, 0xe8, 0x31, 0x8c,
+	0xed, 0xa3, 0x09, 0xc5, 0xa4, 0xf3, 0xd0, 0x4a, 0xd7, 0x48, 0xf7, 0x35, 0x85, 0x7b, 0x68, 0x17,
+	0x46, 0xe6, 0x31, 0x84, 0x22, 0xd2, 0x7e, 0x3f, 0xa5, 0x31, 0xe9, 0xbc, 0x91, 0xac, 0xa7, 0x19,
+	0x90, 0x28, 0x22, 0xed, 0x41, 0x9a, 0xc6, 0xa4, 0x33, 0x37, 0xad, 0xa7, 0x91, 0x73, 0x14, 0x91,
+	0xf6, 0x18, 0x48, 0xe3, 0xae, 0xca, 0xe3, 0x9e, 0x4e, 0xd5, 0xaa, 0x23, 0x8a, 0x49, 0x47, 0xa1,
+	0xd3, 0xb5, 0x6b, 0xb2, 0xe9, 0x12, 0x30, 0x6a, 0x11, 0x91, 0xb6, 0x26, 0xa5, 0xb1, 0x87, 0x8d,
+	0xe7, 0x13, 0x08, 0xbd, 0x46, 0xa0, 0x75, 0x72, 0x4d, 0x41, 0xd2, 0x0d, 0x72, 0x5d, 0x40, 0x70,
+	0x0f, 0x7d, 0x02, 0xd3, 0xe6, 0x8e, 0xa3, 0x0d, 0x72, 0x5d, 0x04, 0x52, 0x44, 0xde, 0x90, 0x00,
+	0xdc, 0x43, 0x5f, 0xc0, 0xbc, 0xdd, 0x40, 0x68, 0x93, 0xdc, 0xd0, 0x67, 0xe9, 0x16, 0xb9, 0xa9,
+	0xcb, 0x70, 0xef, 0x64, 0x6c, 0x1e, 0xc4, 0x4f, 0xff, 0x19, 0x00, 0x08, 0xa7, 0x50, 0xbd, 0x33,
+	0x0b, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -734,6 +1380,11 @@ type DebugClient interface {
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
 	Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (*TraceResponse, error)
 	Cache(ctx context.Context, in *CacheRequest, opts ...grpc.CallOption) (*CacheResponse, error)
+	Router(ctx context.Context, in *RouterRequest, opts ...grpc.CallOption) (*RouterResponse, error)
+	Table(ctx context.Context, in *TableRequest, opts ...grpc.CallOption) (*TableResponse, error)
+	Manifest(ctx context.Context, in *ManifestRequest, opts ...grpc.CallOption) (*ManifestResponse, error)
+	Endpoints(ctx context.Context, in *EndpointsRequest, opts ...grpc.CallOption) (*EndpointsResponse, error)
+	Registration(ctx context.Context, in *RegistrationRequest, opts ...grpc.CallOption) (*RegistrationResponse, error)
 }
 
 type debugClient struct {
@@ -812,6 +1463,51 @@ func (c *debugClient) Cache(ctx context.Context, in *CacheRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *debugClient) Router(ctx context.Context, in *RouterRequest, opts ...grpc.CallOption) (*RouterResponse, error) {
+	out := new(RouterResponse)
+	err := c.cc.Invoke(ctx, "/Debug/Router", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) Table(ctx context.Context, in *TableRequest, opts ...grpc.CallOption) (*TableResponse, error) {
+	out := new(TableResponse)
+	err := c.cc.Invoke(ctx, "/Debug/Table", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) Manifest(ctx context.Context, in *ManifestRequest, opts ...grpc.CallOption) (*ManifestResponse, error) {
+	out := new(ManifestResponse)
+	err := c.cc.Invoke(ctx, "/Debug/Manifest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) Endpoints(ctx context.Context, in *EndpointsRequest, opts ...grpc.CallOption) (*EndpointsResponse, error) {
+	out := new(EndpointsResponse)
+	err := c.cc.Invoke(ctx, "/Debug/Endpoints", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) Registration(ctx context.Context, in *RegistrationRequest, opts ...grpc.CallOption) (*RegistrationResponse, error) {
+	out := new(RegistrationResponse)
+	err := c.cc.Invoke(ctx, "/Debug/Registration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DebugServer is the server API for Debug service.
 type DebugServer interface {
 	Log(*LogRequest, Debug_LogServer) error
@@ -819,6 +1515,11 @@ type DebugServer interface {
 	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
 	Trace(context.Context, *TraceRequest) (*TraceResponse, error)
 	Cache(context.Context, *CacheRequest) (*CacheResponse, error)
+	Router(context.Context, *RouterRequest) (*RouterResponse, error)
+	Table(context.Context, *TableRequest) (*TableResponse, error)
+	Manifest(context.Context, *ManifestRequest) (*ManifestResponse, error)
+	Endpoints(context.Context, *EndpointsRequest) (*EndpointsResponse, error)
+	Registration(context.Context, *RegistrationRequest) (*RegistrationResponse, error)
 }
 
 // UnimplementedDebugServer can be embedded to have forward compatible implementations.
@@ -840,6 +1541,21 @@ func (*UnimplementedDebugServer) Trace(ctx context.Context, req *TraceRequest) (
 func (*UnimplementedDebugServer) Cache(ctx context.Context, req *CacheRequest) (*CacheResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Cache not implemented")
 }
+func (*UnimplementedDebugServer) Router(ctx context.Context, req *RouterRequest) (*RouterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Router not implemented")
+}
+func (*UnimplementedDebugServer) Table(ctx context.Context, req *TableRequest) (*TableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Table not implemented")
+}
+func (*UnimplementedDebugServer) Manifest(ctx context.Context, req *ManifestRequest) (*ManifestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Manifest not implemented")
+}
+func (*UnimplementedDebugServer) Endpoints(ctx context.Context, req *EndpointsRequest) (*EndpointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Endpoints not implemented")
+}
+func (*UnimplementedDebugServer) Registration(ctx context.Context, req *RegistrationRequest) (*RegistrationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Registration not implemented")
+}
 
 func RegisterDebugServer(s *grpc.Server, srv DebugServer) {
 	s.RegisterService(&_Debug_serviceDesc, srv)
@@ -938,6 +1654,96 @@ func _Debug_Cache_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Debug_Router_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RouterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).Router(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Debug/Router",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).Router(ctx, req.(*RouterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_Table_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).Table(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Debug/Table",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).Table(ctx, req.(*TableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_Manifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ManifestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).Manifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Debug/Manifest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).Manifest(ctx, req.(*ManifestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_Endpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EndpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).Endpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Debug/Endpoints",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).Endpoints(ctx, req.(*EndpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_Registration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegistrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).Registration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Debug/Registration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).Registration(ctx, req.(*RegistrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Debug_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "Debug",
 	HandlerType: (*DebugServer)(nil),
@@ -958,6 +1764,26 @@ var _Debug_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Cache",
 			Handler:    _Debug_Cache_Handler,
 		},
+		{
+			MethodName: "Router",
+			Handler:    _Debug_Router_Handler,
+		},
+		{
+			MethodName: "Table",
+			Handler:    _Debug_Table_Handler,
+		},
+		{
+			MethodName: "Manifest",
+			Handler:    _Debug_Manifest_Handler,
+		},
+		{
+			MethodName: "Endpoints",
+			Handler:    _Debug_Endpoints_Handler,
+		},
+		{
+			MethodName: "Registration",
+			Handler:    _Debug_Registration_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -47,6 +47,11 @@ type DebugService interface {
 	Stats(ctx context.Context, in *StatsRequest, opts ...client.CallOption) (*StatsResponse, error)
 	Trace(ctx context.Context, in *TraceRequest, opts ...client.CallOption) (*TraceResponse, error)
 	Cache(ctx context.Context, in *CacheRequest, opts ...client.CallOption) (*CacheResponse, error)
+	Router(ctx context.Context, in *RouterRequest, opts ...client.CallOption) (*RouterResponse, error)
+	Table(ctx context.Context, in *TableRequest, opts ...client.CallOption) (*TableResponse, error)
+	Manifest(ctx context.Context, in *ManifestRequest, opts ...client.CallOption) (*ManifestResponse, error)
+	Endpoints(ctx context.Context, in *EndpointsRequest, opts ...client.CallOption) (*EndpointsResponse, error)
+	Registration(ctx context.Context, in *RegistrationRequest, opts ...client.CallOption) (*RegistrationResponse, error)
 }
 
 type debugService struct {
@@ -150,6 +155,56 @@ func (c *debugService) Cache(ctx context.Context, in *CacheRequest, opts ...clie
 	return out, nil
 }
 
+func (c *debugService) Router(ctx context.Context, in *RouterRequest, opts ...client.CallOption) (*RouterResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Router", in)
+	out := new(RouterResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugService) Table(ctx context.Context, in *TableRequest, opts ...client.CallOption) (*TableResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Table", in)
+	out := new(TableResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugService) Manifest(ctx context.Context, in *ManifestRequest, opts ...client.CallOption) (*ManifestResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Manifest", in)
+	out := new(ManifestResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugService) Endpoints(ctx context.Context, in *EndpointsRequest, opts ...client.CallOption) (*EndpointsResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Endpoints", in)
+	out := new(EndpointsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugService) Registration(ctx context.Context, in *RegistrationRequest, opts ...client.CallOption) (*RegistrationResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Registration", in)
+	out := new(RegistrationResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Debug service
 
 type DebugHandler interface {
@@ -158,6 +213,11 @@ type DebugHandler interface {
 	Stats(context.Context, *StatsRequest, *StatsResponse) error
 	Trace(context.Context, *TraceRequest, *TraceResponse) error
 	Cache(context.Context, *CacheRequest, *CacheResponse) error
+	Router(context.Context, *RouterRequest, *RouterResponse) error
+	Table(context.Context, *TableRequest, *TableResponse) error
+	Manifest(context.Context, *ManifestRequest, *ManifestResponse) error
+	Endpoints(context.Context, *EndpointsRequest, *EndpointsResponse) error
+	Registration(context.Context, *RegistrationRequest, *RegistrationResponse) error
 }
 
 func RegisterDebugHandler(s server.Server, hdlr DebugHandler, opts ...server.HandlerOption) error {
@@ -167,6 +227,11 @@ func RegisterDebugHandler(s server.Server, hdlr DebugHandler, opts ...server.Han
 		Stats(ctx context.Context, in *StatsRequest, out *StatsResponse) error
 		Trace(ctx context.Context, in *TraceRequest, out *TraceResponse) error
 		Cache(ctx context.Context, in *CacheRequest, out *CacheResponse) error
+		Router(ctx context.Context, in *RouterRequest, out *RouterResponse) error
+		Table(ctx context.Context, in *TableRequest, out *TableResponse) error
+		Manifest(ctx context.Context, in *ManifestRequest, out *ManifestResponse) error
+		Endpoints(ctx context.Context, in *EndpointsRequest, out *EndpointsResponse) error
+		Registration(ctx context.Context, in *RegistrationRequest, out *RegistrationResponse) error
 	}
 	type Debug struct {
 		debug
@@ -234,3 +299,23 @@ func (h *debugHandler) Trace(ctx context.Context, in *TraceRequest, out *TraceRe
 func (h *debugHandler) Cache(ctx context.Context, in *CacheRequest, out *CacheResponse) error {
 	return h.DebugHandler.Cache(ctx, in, out)
 }
+
+func (h *debugHandler) Router(ctx context.Context, in *RouterRequest, out *RouterResponse) error {
+	return h.DebugHandler.Router(ctx, in, out)
+}
+
+func (h *debugHandler) Table(ctx context.Context, in *TableRequest, out *TableResponse) error {
+	return h.DebugHandler.Table(ctx, in, out)
+}
+
+func (h *debugHandler) Manifest(ctx context.Context, in *ManifestRequest, out *ManifestResponse) error {
+	return h.DebugHandler.Manifest(ctx, in, out)
+}
+
+func (h *debugHandler) Endpoints(ctx context.Context, in *EndpointsRequest, out *EndpointsResponse) error {
+	return h.DebugHandler.Endpoints(ctx, in, out)
+}
+
+func (h *debugHandler) Registration(ctx context.Context, in *RegistrationRequest, out *RegistrationResponse) error {
+	return h.DebugHandler.Registration(ctx, in, out)
+}
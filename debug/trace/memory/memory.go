@@ -68,8 +68,10 @@ func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *trac
 }
 
 func (t *Tracer) Finish(s *trace.Span) error {
-	// set finished time
-	s.Duration = time.Since(s.Started)
+	// set finished time unless it's already finished e.g. by a sampler
+	if s.Duration == 0 {
+		s.Duration = time.Since(s.Started)
+	}
 	// save the span
 	t.buffer.Put(s)
 
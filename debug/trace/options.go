@@ -1,8 +1,12 @@
 package trace
 
+import "context"
+
 type Options struct {
 	// Size is the size of ring buffer
 	Size int
+	// Alternative options
+	Context context.Context
 }
 
 type Option func(o *Options)
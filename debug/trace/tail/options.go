@@ -0,0 +1,47 @@
+package tail
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/trace"
+)
+
+type tracerKey struct{}
+
+type latencyKey struct{}
+
+type waitKey struct{}
+
+type maxTracesKey struct{}
+
+// Tracer sets the tracer the sampled traces are exported to, by default the
+// DefaultTracer
+func Tracer(t trace.Tracer) trace.Option {
+	return setOption(tracerKey{}, t)
+}
+
+// Latency sets the duration of a span over which its trace is kept
+func Latency(d time.Duration) trace.Option {
+	return setOption(latencyKey{}, d)
+}
+
+// Wait sets how long the spans of a trace are buffered for after the last
+// one finished before the trace is sampled
+func Wait(d time.Duration) trace.Option {
+	return setOption(waitKey{}, d)
+}
+
+// MaxTraces sets the max number of traces buffered
+func MaxTraces(n int) trace.Option {
+	return setOption(maxTracesKey{}, n)
+}
+
+func setOption(k, v interface{}) trace.Option {
+	return func(o *trace.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
@@ -0,0 +1,158 @@
+// Package tail is a tracer which samples traces once they're complete. The
+// spans of a trace are buffered briefly and exported to the underlying tracer
+// only if one of them was slow or failed, so the interesting traces are kept
+// without the cost of exporting every trace.
+package tail
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/trace"
+)
+
+var (
+	// DefaultLatency is the duration of a span over which its trace is kept
+	DefaultLatency = 500 * time.Millisecond
+	// DefaultWait is how long the spans of a trace are buffered for
+	DefaultWait = 5 * time.Second
+	// DefaultMaxTraces is the default max number of traces buffered
+	DefaultMaxTraces = 1000
+)
+
+type tracer struct {
+	tracer    trace.Tracer
+	latency   time.Duration
+	wait      time.Duration
+	maxTraces int
+
+	sync.Mutex
+	// traces buffered by id
+	traces map[string]*buffer
+}
+
+// buffer holds the spans of a trace until it's sampled
+type buffer struct {
+	spans []*trace.Span
+	// timer flushing the trace once no span finished for the wait
+	timer *time.Timer
+}
+
+// NewTracer returns a tail sampling tracer
+func NewTracer(opts ...trace.Option) trace.Tracer {
+	options := trace.DefaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	t := &tracer{
+		tracer:    trace.DefaultTracer,
+		latency:   DefaultLatency,
+		wait:      DefaultWait,
+		maxTraces: DefaultMaxTraces,
+		traces:    make(map[string]*buffer),
+	}
+
+	if ctx := options.Context; ctx != nil {
+		if tr, ok := ctx.Value(tracerKey{}).(trace.Tracer); ok && tr != nil {
+			t.tracer = tr
+		}
+		if d, ok := ctx.Value(latencyKey{}).(time.Duration); ok && d > 0 {
+			t.latency = d
+		}
+		if d, ok := ctx.Value(waitKey{}).(time.Duration); ok && d > 0 {
+			t.wait = d
+		}
+		if n, ok := ctx.Value(maxTracesKey{}).(int); ok && n > 0 {
+			t.maxTraces = n
+		}
+	}
+
+	return t
+}
+
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, *trace.Span) {
+	return t.tracer.Start(ctx, name)
+}
+
+// Finish buffers the span until its trace is sampled
+func (t *tracer) Finish(s *trace.Span) error {
+	if s == nil {
+		return nil
+	}
+
+	// the span finishes now, not when it's exported
+	if s.Duration == 0 {
+		s.Duration = time.Since(s.Started)
+	}
+
+	t.Lock()
+	b, ok := t.traces[s.Trace]
+	if !ok {
+		if len(t.traces) >= t.maxTraces {
+			t.Unlock()
+			// too many traces buffered, sample the span on its own
+			return t.sample([]*trace.Span{s})
+		}
+
+		// sample the trace once the rest of its spans had time to finish
+		id := s.Trace
+		b = &buffer{timer: time.AfterFunc(t.wait, func() {
+			t.flush(id)
+		})}
+		t.traces[id] = b
+	} else if b.timer.Stop() {
+		// wait for the spans finishing after this one, unless the trace is
+		// being flushed in which case the span is flushed with it
+		b.timer.Reset(t.wait)
+	}
+
+	b.spans = append(b.spans, s)
+	t.Unlock()
+
+	return nil
+}
+
+func (t *tracer) Read(opts ...trace.ReadOption) ([]*trace.Span, error) {
+	return t.tracer.Read(opts...)
+}
+
+// flush samples the buffered spans of the trace
+func (t *tracer) flush(id string) {
+	t.Lock()
+	b, ok := t.traces[id]
+	delete(t.traces, id)
+	t.Unlock()
+
+	if ok {
+		t.sample(b.spans)
+	}
+}
+
+// sample exports the spans if any of them was slow or failed
+func (t *tracer) sample(spans []*trace.Span) error {
+	if !t.keep(spans) {
+		return nil
+	}
+
+	for _, s := range spans {
+		if err := t.tracer.Finish(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keep returns true if the trace is kept
+func (t *tracer) keep(spans []*trace.Span) bool {
+	for _, s := range spans {
+		if s.Duration >= t.latency {
+			return true
+		}
+		if len(s.Metadata["error"]) > 0 {
+			return true
+		}
+	}
+	return false
+}
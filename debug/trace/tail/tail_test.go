@@ -0,0 +1,107 @@
+package tail
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/trace"
+	"github.com/micro/go-micro/v2/debug/trace/memory"
+)
+
+func TestTail(t *testing.T) {
+	backend := memory.NewTracer()
+	tr := NewTracer(Tracer(backend), Latency(time.Millisecond*20), Wait(time.Millisecond*50))
+
+	// a fast trace is dropped
+	ctx, root := tr.Start(context.Background(), "fast")
+	_, child := tr.Start(ctx, "fast.child")
+	tr.Finish(child)
+	tr.Finish(root)
+
+	// a slow trace is kept with all its spans
+	ctx, slow := tr.Start(context.Background(), "slow")
+	_, fast := tr.Start(ctx, "slow.child")
+	tr.Finish(fast)
+	time.Sleep(time.Millisecond * 25)
+	tr.Finish(slow)
+
+	// a failed trace is kept
+	_, failed := tr.Start(context.Background(), "failed")
+	failed.Metadata["error"] = "boom"
+	tr.Finish(failed)
+
+	// nothing is exported before the trace is sampled
+	if spans, _ := tr.Read(); len(spans) != 0 {
+		t.Fatalf("Expected no spans before sampling, got %d", len(spans))
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	for _, tc := range []struct {
+		trace string
+		spans int
+	}{
+		{root.Trace, 0},
+		{slow.Trace, 2},
+		{failed.Trace, 1},
+	} {
+		spans, err := tr.Read(trace.ReadTrace(tc.trace))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(spans) != tc.spans {
+			t.Fatalf("Expected %d spans for trace %s, got %d", tc.spans, tc.trace, len(spans))
+		}
+	}
+
+	// the duration isn't extended by the buffering
+	spans, _ := tr.Read(trace.ReadTrace(fast.Trace))
+	for _, s := range spans {
+		if s.Name == "slow.child" && s.Duration >= time.Millisecond*20 {
+			t.Fatalf("Expected the duration of the span when it finished, got %v", s.Duration)
+		}
+	}
+}
+
+func TestTailMaxTraces(t *testing.T) {
+	backend := memory.NewTracer()
+	tr := NewTracer(Tracer(backend), Wait(time.Hour), MaxTraces(1))
+
+	_, first := tr.Start(context.Background(), "first")
+	tr.Finish(first)
+
+	// the buffer is full so the failed span is sampled right away
+	_, failed := tr.Start(context.Background(), "failed")
+	failed.Metadata["error"] = "boom"
+	tr.Finish(failed)
+
+	if spans, _ := tr.Read(); len(spans) != 1 || spans[0].Trace != failed.Trace {
+		t.Fatalf("Expected the failed span to be exported, got %v", spans)
+	}
+}
+
+func TestTailWaitRestart(t *testing.T) {
+	backend := memory.NewTracer()
+	tr := NewTracer(Tracer(backend), Latency(time.Millisecond*10), Wait(time.Millisecond*50))
+
+	// the spans keep finishing for longer than the wait
+	ctx, root := tr.Start(context.Background(), "root")
+	for i := 0; i < 4; i++ {
+		_, child := tr.Start(ctx, "root.child")
+		tr.Finish(child)
+		time.Sleep(time.Millisecond * 30)
+	}
+	tr.Finish(root)
+
+	// the trace is sampled with all its spans once none finished for the wait
+	if spans, _ := tr.Read(trace.ReadTrace(root.Trace)); len(spans) != 0 {
+		t.Fatalf("Expected the trace to be buffered while its spans finish, got %d spans", len(spans))
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	if spans, _ := tr.Read(trace.ReadTrace(root.Trace)); len(spans) != 5 {
+		t.Fatalf("Expected the trace to be kept with its 5 spans, got %d", len(spans))
+	}
+}
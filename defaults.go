@@ -18,15 +18,21 @@ import (
 	smucp "github.com/micro/go-micro/v2/server/mucp"
 
 	// brokers
+	"github.com/micro/go-micro/v2/broker/gcppubsub"
 	brokerHttp "github.com/micro/go-micro/v2/broker/http"
 	"github.com/micro/go-micro/v2/broker/memory"
 	"github.com/micro/go-micro/v2/broker/nats"
+	brokerPulsar "github.com/micro/go-micro/v2/broker/pulsar"
+	"github.com/micro/go-micro/v2/broker/rabbitmq"
+	"github.com/micro/go-micro/v2/broker/redisstream"
 	brokerSrv "github.com/micro/go-micro/v2/broker/service"
 
 	// registries
 	"github.com/micro/go-micro/v2/registry/etcd"
 	"github.com/micro/go-micro/v2/registry/mdns"
 	rmem "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/registry/nacos"
+	rnats "github.com/micro/go-micro/v2/registry/nats"
 	regSrv "github.com/micro/go-micro/v2/registry/service"
 
 	// routers
@@ -41,8 +47,14 @@ import (
 	srvRuntime "github.com/micro/go-micro/v2/runtime/service"
 
 	// selectors
+	hashSelector "github.com/micro/go-micro/v2/selector/consistenthash"
+	failSelector "github.com/micro/go-micro/v2/selector/failover"
+	ewmaSelector "github.com/micro/go-micro/v2/selector/peakewma"
 	randSelector "github.com/micro/go-micro/v2/selector/random"
 	roundSelector "github.com/micro/go-micro/v2/selector/roundrobin"
+	stickySelector "github.com/micro/go-micro/v2/selector/sticky"
+	subsetSelector "github.com/micro/go-micro/v2/selector/subset"
+	weightSelector "github.com/micro/go-micro/v2/selector/weighted"
 
 	// transports
 	thttp "github.com/micro/go-micro/v2/transport/http"
@@ -55,6 +67,7 @@ import (
 	// tracers
 	// jTracer "github.com/micro/go-micro/v2/debug/trace/jaeger"
 	memTracer "github.com/micro/go-micro/v2/debug/trace/memory"
+	tailTracer "github.com/micro/go-micro/v2/debug/trace/tail"
 
 	// auth
 	jwtAuth "github.com/micro/go-micro/v2/auth/jwt"
@@ -84,6 +97,10 @@ func init() {
 	cmd.DefaultBrokers["memory"] = memory.NewBroker
 	cmd.DefaultBrokers["nats"] = nats.NewBroker
 	cmd.DefaultBrokers["http"] = brokerHttp.NewBroker
+	cmd.DefaultBrokers["redisstream"] = redisstream.NewBroker
+	cmd.DefaultBrokers["gcppubsub"] = gcppubsub.NewBroker
+	cmd.DefaultBrokers["rabbitmq"] = rabbitmq.NewBroker
+	cmd.DefaultBrokers["pulsar"] = brokerPulsar.NewBroker
 
 	// config
 	cmd.DefaultConfigs["service"] = config.NewConfig
@@ -101,6 +118,8 @@ func init() {
 	cmd.DefaultRegistries["etcd"] = etcd.NewRegistry
 	cmd.DefaultRegistries["mdns"] = mdns.NewRegistry
 	cmd.DefaultRegistries["memory"] = rmem.NewRegistry
+	cmd.DefaultRegistries["nats"] = rnats.NewRegistry
+	cmd.DefaultRegistries["nacos"] = nacos.NewRegistry
 
 	// runtime
 	cmd.DefaultRuntimes["local"] = lRuntime.NewRuntime
@@ -114,8 +133,14 @@ func init() {
 	cmd.DefaultRouters["service"] = srvRouter.NewRouter
 
 	// selector
+	cmd.DefaultSelectors["consistenthash"] = hashSelector.NewSelector
+	cmd.DefaultSelectors["failover"] = failSelector.NewSelector
+	cmd.DefaultSelectors["peakewma"] = ewmaSelector.NewSelector
 	cmd.DefaultSelectors["random"] = randSelector.NewSelector
 	cmd.DefaultSelectors["roundrobin"] = roundSelector.NewSelector
+	cmd.DefaultSelectors["sticky"] = stickySelector.NewSelector
+	cmd.DefaultSelectors["subset"] = subsetSelector.NewSelector
+	cmd.DefaultSelectors["weighted"] = weightSelector.NewSelector
 
 	// server
 	cmd.DefaultServers["mucp"] = smucp.NewServer
@@ -127,6 +152,7 @@ func init() {
 
 	// trace
 	cmd.DefaultTracers["memory"] = memTracer.NewTracer
+	cmd.DefaultTracers["tail"] = tailTracer.NewTracer
 
 	// transport
 	cmd.DefaultTransports["memory"] = tmem.NewTransport
@@ -111,6 +111,16 @@ func Conflict(id, format string, a ...interface{}) error {
 	}
 }
 
+// RequestEntityTooLarge generates a 413 error.
+func RequestEntityTooLarge(id, format string, a ...interface{}) error {
+	return &Error{
+		Id:     id,
+		Code:   413,
+		Detail: fmt.Sprintf(format, a...),
+		Status: http.StatusText(413),
+	}
+}
+
 // InternalServerError generates a 500 error.
 func InternalServerError(id, format string, a ...interface{}) error {
 	return &Error{
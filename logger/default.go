@@ -19,6 +19,11 @@ func init() {
 		lvl = InfoLevel
 	}
 
+	// e.g. MICRO_LOG_LEVELS=router=debug,client=warn
+	if levels, err := ParseLevels(os.Getenv("MICRO_LOG_LEVELS")); err == nil {
+		SetModuleLevels(levels)
+	}
+
 	DefaultLogger = NewHelper(NewLogger(WithLevel(lvl)))
 }
 
@@ -39,11 +44,20 @@ func (l *defaultLogger) String() string {
 	return "default"
 }
 
+// Fields returns a copy of the logger logging its fields merged with the
+// fields passed, which take precedence
 func (l *defaultLogger) Fields(fields map[string]interface{}) Logger {
-	l.Lock()
-	l.opts.Fields = copyFields(fields)
-	l.Unlock()
-	return l
+	l.RLock()
+	opts := l.opts
+	nfields := copyFields(l.opts.Fields)
+	l.RUnlock()
+
+	for k, v := range fields {
+		nfields[k] = v
+	}
+	opts.Fields = nfields
+
+	return &defaultLogger{opts: opts}
 }
 
 func copyFields(src map[string]interface{}) map[string]interface{} {
@@ -78,16 +92,25 @@ func logCallerfilePath(loggingFilePath string) string {
 	return loggingFilePath[idx+1:]
 }
 
-func (l *defaultLogger) Log(level Level, v ...interface{}) {
-	// TODO decide does we need to write message if log level not used?
-	if !l.opts.Level.Enabled(level) {
-		return
+// enabled returns true if the level is logged, the level of the module
+// logged in the fields overrides the level of the logger
+func (l *defaultLogger) enabled(level Level, fields map[string]interface{}) bool {
+	if lvl, ok := moduleLevel(fields); ok {
+		return lvl.Enabled(level)
 	}
+	return l.opts.Level.Enabled(level)
+}
 
+func (l *defaultLogger) Log(level Level, v ...interface{}) {
 	l.RLock()
 	fields := copyFields(l.opts.Fields)
 	l.RUnlock()
 
+	// TODO decide does we need to write message if log level not used?
+	if !l.enabled(level, fields) {
+		return
+	}
+
 	fields["level"] = level.String()
 
 	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount); ok {
@@ -120,15 +143,15 @@ func (l *defaultLogger) Log(level Level, v ...interface{}) {
 }
 
 func (l *defaultLogger) Logf(level Level, format string, v ...interface{}) {
-	//	 TODO decide does we need to write message if log level not used?
-	if level < l.opts.Level {
-		return
-	}
-
 	l.RLock()
 	fields := copyFields(l.opts.Fields)
 	l.RUnlock()
 
+	//	 TODO decide does we need to write message if log level not used?
+	if !l.enabled(level, fields) {
+		return
+	}
+
 	fields["level"] = level.String()
 
 	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount); ok {
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	hookMu    sync.RWMutex
+	hookId    int
+	exitHooks []exitHook
+)
+
+type exitHook struct {
+	id int
+	fn func(msg string)
+}
+
+// OnFatal registers a hook which is called with the message of a fatal log
+// before the process exits e.g. to flush buffers or write a crash report.
+// The func returned removes the hook.
+func OnFatal(fn func(msg string)) func() {
+	hookMu.Lock()
+	hookId++
+	id := hookId
+	exitHooks = append(exitHooks, exitHook{id, fn})
+	hookMu.Unlock()
+
+	return func() {
+		hookMu.Lock()
+		defer hookMu.Unlock()
+		for i, h := range exitHooks {
+			if h.id == id {
+				// copy so the hooks being run aren't changed
+				hooks := make([]exitHook, 0, len(exitHooks)-1)
+				hooks = append(hooks, exitHooks[:i]...)
+				exitHooks = append(hooks, exitHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// exit runs the fatal hooks and exits the process
+func exit(msg string) {
+	hookMu.RLock()
+	hooks := exitHooks
+	hookMu.RUnlock()
+
+	for _, h := range hooks {
+		h.fn(msg)
+	}
+
+	os.Exit(1)
+}
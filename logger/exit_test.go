@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestOnFatal(t *testing.T) {
+	var called []string
+	remove := OnFatal(func(msg string) {
+		called = append(called, "a")
+	})
+	defer OnFatal(func(msg string) {
+		called = append(called, "b")
+	})()
+
+	remove()
+	// removing twice is a no-op
+	remove()
+
+	hookMu.RLock()
+	hooks := exitHooks
+	hookMu.RUnlock()
+	for _, h := range hooks {
+		h.fn("fatal")
+	}
+
+	if len(called) != 1 || called[0] != "b" {
+		t.Fatalf("Expected only the hook left to be called, got %v", called)
+	}
+}
@@ -1,7 +1,7 @@
 package logger
 
 import (
-	"os"
+	"fmt"
 )
 
 type Helper struct {
@@ -88,7 +88,7 @@ func (h *Helper) Fatal(args ...interface{}) {
 		return
 	}
 	h.Logger.Fields(h.fields).Log(FatalLevel, args...)
-	os.Exit(1)
+	exit(fmt.Sprint(args...))
 }
 
 func (h *Helper) Fatalf(template string, args ...interface{}) {
@@ -96,7 +96,17 @@ func (h *Helper) Fatalf(template string, args ...interface{}) {
 		return
 	}
 	h.Logger.Fields(h.fields).Logf(FatalLevel, template, args...)
-	os.Exit(1)
+	exit(fmt.Sprintf(template, args...))
+}
+
+// Fields returns a logger logging the fields merged with the fields of the
+// helper, the fields passed take precedence
+func (h *Helper) Fields(fields map[string]interface{}) Logger {
+	nfields := copyFields(h.fields)
+	for k, v := range fields {
+		nfields[k] = v
+	}
+	return h.Logger.Fields(nfields)
 }
 
 func (h *Helper) WithError(err error) *Helper {
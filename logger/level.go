@@ -2,7 +2,6 @@ package logger
 
 import (
 	"fmt"
-	"os"
 )
 
 type Level int8
@@ -108,12 +107,12 @@ func Errorf(template string, args ...interface{}) {
 
 func Fatal(args ...interface{}) {
 	DefaultLogger.Log(FatalLevel, args...)
-	os.Exit(1)
+	exit(fmt.Sprint(args...))
 }
 
 func Fatalf(template string, args ...interface{}) {
 	DefaultLogger.Logf(FatalLevel, template, args...)
-	os.Exit(1)
+	exit(fmt.Sprintf(template, args...))
 }
 
 // Returns true if the given level is at or lower the current logger level
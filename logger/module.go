@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// ModuleKey is the field the name of the module is logged with
+	ModuleKey = "module"
+
+	// levels of the modules overriding the level of the logger
+	modules = struct {
+		sync.RWMutex
+		levels map[string]Level
+	}{levels: make(map[string]Level)}
+)
+
+// ParseLevels parses module levels in the form router=debug,client=warn
+func ParseLevels(s string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("invalid module level %q, expected module=level", part)
+		}
+		lvl, err := GetLevel(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(kv[0])] = lvl
+	}
+	return levels, nil
+}
+
+// SetModuleLevel sets the level of a module
+func SetModuleLevel(name string, level Level) {
+	modules.Lock()
+	modules.levels[name] = level
+	modules.Unlock()
+}
+
+// SetModuleLevels replaces the levels of the modules
+func SetModuleLevels(levels map[string]Level) {
+	modules.Lock()
+	modules.levels = make(map[string]Level, len(levels))
+	for k, v := range levels {
+		modules.levels[k] = v
+	}
+	modules.Unlock()
+}
+
+// GetModuleLevel returns the level of the module, false if it isn't set
+func GetModuleLevel(name string) (Level, bool) {
+	modules.RLock()
+	defer modules.RUnlock()
+	lvl, ok := modules.levels[name]
+	return lvl, ok
+}
+
+// moduleLevel returns the level set for the module logged in the fields
+func moduleLevel(fields map[string]interface{}) (Level, bool) {
+	name, ok := fields[ModuleKey].(string)
+	if !ok {
+		return InfoLevel, false
+	}
+	return GetModuleLevel(name)
+}
+
+// NewModule returns a logger for a module e.g. the router. Entries are logged
+// by the DefaultLogger with the module field at the level set for the module,
+// or the level of the DefaultLogger if there isn't one.
+func NewModule(name string) *Helper {
+	return &Helper{
+		Logger: &moduleLogger{name: name},
+		fields: map[string]interface{}{ModuleKey: name},
+	}
+}
+
+type moduleLogger struct {
+	name string
+}
+
+func (m *moduleLogger) Init(opts ...Option) error {
+	return DefaultLogger.Init(opts...)
+}
+
+// Options returns the options of the DefaultLogger with the level of the module
+func (m *moduleLogger) Options() Options {
+	opts := DefaultLogger.Options()
+	if lvl, ok := GetModuleLevel(m.name); ok {
+		opts.Level = lvl
+	}
+	return opts
+}
+
+func (m *moduleLogger) Fields(fields map[string]interface{}) Logger {
+	fields = copyFields(fields)
+	fields[ModuleKey] = m.name
+	return DefaultLogger.Fields(fields)
+}
+
+func (m *moduleLogger) Log(level Level, v ...interface{}) {
+	m.Fields(nil).Log(level, v...)
+}
+
+func (m *moduleLogger) Logf(level Level, format string, v ...interface{}) {
+	m.Fields(nil).Logf(level, format, v...)
+}
+
+func (m *moduleLogger) String() string {
+	return m.name
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("router=debug, client=warn,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 2 || levels["router"] != DebugLevel || levels["client"] != WarnLevel {
+		t.Fatalf("Unexpected levels %v", levels)
+	}
+
+	for _, s := range []string{"router", "=debug", "router=loud"} {
+		if _, err := ParseLevels(s); err == nil {
+			t.Fatalf("Expected an error parsing %q", s)
+		}
+	}
+}
+
+func TestModule(t *testing.T) {
+	defer SetModuleLevels(nil)
+
+	log := NewModule("router")
+	base := DefaultLogger.Options().Level
+
+	if lvl := log.Options().Level; lvl != base {
+		t.Fatalf("Expected the level of the default logger %v, got %v", base, lvl)
+	}
+
+	SetModuleLevel("router", TraceLevel)
+	if !V(TraceLevel, log) {
+		t.Fatal("Expected the module to log at trace level")
+	}
+	if V(TraceLevel, NewModule("client")) && base > TraceLevel {
+		t.Fatal("Expected other modules to keep the level of the default logger")
+	}
+
+	// the level of the module overrides the logger
+	l := NewLogger(WithLevel(ErrorLevel)).Fields(map[string]interface{}{ModuleKey: "router"}).(*defaultLogger)
+	if !l.enabled(DebugLevel, l.opts.Fields) {
+		t.Fatal("Expected debug entries of the module to be logged")
+	}
+
+	SetModuleLevel("router", ErrorLevel)
+	if l.enabled(WarnLevel, l.opts.Fields) {
+		t.Fatal("Expected warn entries of the module to be dropped")
+	}
+}
+
+func TestFieldsCopy(t *testing.T) {
+	l := NewLogger()
+	l.Fields(map[string]interface{}{ModuleKey: "router"})
+
+	if _, ok := l.Options().Fields[ModuleKey]; ok {
+		t.Fatal("Expected the fields to not change the logger")
+	}
+}
+
+func TestFieldsMerge(t *testing.T) {
+	l := NewLogger(WithFields(map[string]interface{}{"service": "foo"}))
+	l = l.Fields(map[string]interface{}{"node": "1"})
+	l = l.Fields(map[string]interface{}{"node": "2"})
+
+	fields := l.Options().Fields
+	if fields["service"] != "foo" || fields["node"] != "2" {
+		t.Fatalf("Expected the fields to be merged, got %v", fields)
+	}
+
+	// the fields of a helper are kept too
+	h := NewHelper(l).WithFields(map[string]interface{}{"error": "failed"})
+	fields = h.Fields(map[string]interface{}{"node": "3"}).Options().Fields
+	if fields["service"] != "foo" || fields["error"] != "failed" || fields["node"] != "3" {
+		t.Fatalf("Expected the fields of the helper to be merged, got %v", fields)
+	}
+}
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/micro/go-micro/v2/transport"
+)
+
+const (
+	// encodingHeader is the message header set when the body is compressed
+	encodingHeader = "Micro-Encoding"
+	// gzipEncoding is the encoding of gzipped message bodies
+	gzipEncoding = "gzip"
+)
+
+// errTooLarge is returned when a body decompresses to more than the max size
+var errTooLarge = errors.New("decompressed body exceeds the max size")
+
+// compress gzips the body
+func compress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress returns the message body, unzipping it if it was compressed, up
+// to max bytes, or DefaultMaxAdvertSize if max is zero or less
+func decompress(msg *transport.Message, max int) ([]byte, error) {
+	if msg.Header[encodingHeader] != gzipEncoding {
+		return msg.Body, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(msg.Body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	if max <= 0 {
+		max = DefaultMaxAdvertSize
+	}
+
+	// read one more byte than the max to know if the body exceeds it
+	body, err := ioutil.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > max {
+		return nil, errTooLarge
+	}
+
+	return body, nil
+}
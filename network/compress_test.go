@@ -0,0 +1,34 @@
+package network
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/micro/go-micro/v2/transport"
+)
+
+func TestDecompress(t *testing.T) {
+	body := bytes.Repeat([]byte("advert"), 1024)
+
+	cbody, err := compress(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &transport.Message{
+		Header: map[string]string{encodingHeader: gzipEncoding},
+		Body:   cbody,
+	}
+
+	b, err := decompress(msg, len(body))
+	if err != nil {
+		t.Fatalf("Unexpected error decompressing %v", err)
+	}
+	if !bytes.Equal(b, body) {
+		t.Fatal("Expected the body decompressed")
+	}
+
+	// a body decompressing to more than the max is rejected
+	if _, err := decompress(msg, len(body)-1); err != errTooLarge {
+		t.Fatalf("Expected the body to be too large, got %v", err)
+	}
+}
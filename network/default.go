@@ -316,6 +316,8 @@ func (n *network) advertise(advertChan <-chan *router.Advert) {
 					Router:  event.Route.Router,
 					Link:    event.Route.Link,
 					Metric:  event.Route.Metric,
+					Origin:  event.Route.Origin,
+					Hops:    event.Route.Hops,
 				}
 
 				// override the various values
@@ -645,7 +647,15 @@ func (n *network) processCtrlChan(listener tunnel.Listener) {
 			case "advert":
 				pbRtrAdvert := &pbRtr.Advert{}
 
-				if err := proto.Unmarshal(m.msg.Body, pbRtrAdvert); err != nil {
+				body, err := decompress(m.msg, n.options.MaxAdvertSize)
+				if err != nil {
+					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+						logger.Debugf("Network fail to decompress advert message: %v", err)
+					}
+					continue
+				}
+
+				if err := proto.Unmarshal(body, pbRtrAdvert); err != nil {
 					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 						logger.Debugf("Network fail to unmarshal advert message: %v", err)
 					}
@@ -698,6 +708,8 @@ func (n *network) processCtrlChan(listener tunnel.Listener) {
 						Router:  event.Route.Router,
 						Link:    event.Route.Link,
 						Metric:  event.Route.Metric,
+						Origin:  event.Route.Origin,
+						Hops:    event.Route.Hops,
 					}
 
 					// calculate route metric and add to the advertised metric
@@ -985,9 +997,9 @@ func (n *network) processNetChan(listener tunnel.Listener) {
 				// add all the routes we have received in the sync message
 				for _, pbRoute := range pbNetSync.Routes {
 					// unmarshal the routes received from remote peer
-					route := pbUtil.ProtoToRoute(pbRoute)
-					// continue if we are the originator of the route
-					if route.Router == n.router.Options().Id {
+					// continue if we are the originator of the route or the peer learned it from us
+					route, ok := router.LearnRoute(pbUtil.ProtoToRoute(pbRoute), pbNetSync.Peer.Node.Id, n.router.Options())
+					if !ok {
 						if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 							logger.Debugf("Network node %s skipping route addition: route already present", n.id)
 						}
@@ -1008,18 +1020,14 @@ func (n *network) processNetChan(listener tunnel.Listener) {
 					//          maybe we should not be this clever ¯\_(ツ)_/¯          //
 					/////////////////////////////////////////////////////////////////////
 					// lookup best routes for the services in the just received route
-					q := []router.QueryOption{
-						router.QueryService(route.Service),
-						router.QueryStrategy(n.router.Options().Advertise),
-					}
-
-					routes, err := n.router.Table().Query(q...)
+					routes, err := n.router.Table().Query(router.QueryService(route.Service))
 					if err != nil && err != router.ErrRouteNotFound {
 						if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 							logger.Debugf("Network node %s failed listing best routes for %s: %v", n.id, route.Service, err)
 						}
 						continue
 					}
+					routes = n.router.Options().Advertise.Advertise(routes)
 
 					// we found no routes for the given service
 					// create the new route we have just received
@@ -1391,16 +1399,15 @@ func (n *network) manage() {
 // based on the advertisement strategy encoded in protobuf
 // It returns error if the routes failed to be retrieved from the routing table
 func (n *network) getProtoRoutes() ([]*pbRtr.Route, error) {
-	// get a list of the best routes for each service in our routing table
-	q := []router.QueryOption{
-		router.QueryStrategy(n.router.Options().Advertise),
-	}
-
-	routes, err := n.router.Table().Query(q...)
+	// get a list of the routes for each service in our routing table
+	routes, err := n.router.Table().Query()
 	if err != nil && err != router.ErrRouteNotFound {
 		return nil, err
 	}
 
+	// pick the routes to advertise with the router advertising strategy
+	routes = n.router.Options().Advertise.Advertise(routes)
+
 	// encode the routes to protobuf
 	pbRoutes := make([]*pbRtr.Route, 0, len(routes))
 	for _, route := range routes {
@@ -1484,6 +1491,14 @@ func (n *network) sendTo(method, channel string, peer *node, msg proto.Message)
 		tmsg.Header["Micro-Peer"] = peer.id
 	}
 
+	// compress large adverts
+	if method == "advert" && n.options.CompressSize > 0 && len(body) > n.options.CompressSize {
+		if cbody, err := compress(body); err == nil {
+			tmsg.Header[encodingHeader] = gzipEncoding
+			tmsg.Body = cbody
+		}
+	}
+
 	if err := c.Send(tmsg); err != nil {
 		// TODO: Lookup peer in our graph
 		if peerNode := n.GetPeerNode(peer.id); peerNode != nil {
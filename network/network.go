@@ -24,6 +24,9 @@ var (
 	// PruneTime defines time interval to periodically check nodes that need to be pruned
 	// due to their not announcing their presence within this time interval
 	PruneTime = 90 * time.Second
+	// DefaultMaxAdvertSize is the max size in bytes a compressed advert is
+	// decompressed to, unless set with MaxAdvertSize
+	DefaultMaxAdvertSize = 64 << 20
 )
 
 // Error is network node errors
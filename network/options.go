@@ -32,6 +32,11 @@ type Options struct {
 	Proxy proxy.Proxy
 	// Resolver is network resolver
 	Resolver resolver.Resolver
+	// CompressSize is the advert size in bytes above which adverts are gzipped
+	CompressSize int
+	// MaxAdvertSize is the max size in bytes a compressed advert is
+	// decompressed to
+	MaxAdvertSize int
 }
 
 // Id sets the id of the network node
@@ -97,6 +102,23 @@ func Resolver(r resolver.Resolver) Option {
 	}
 }
 
+// CompressAdverts gzips route adverts larger than size bytes before sending
+// them to peers. A size of zero disables compression.
+func CompressAdverts(size int) Option {
+	return func(o *Options) {
+		o.CompressSize = size
+	}
+}
+
+// MaxAdvertSize sets the max size in bytes a compressed advert received from a
+// peer is decompressed to, larger adverts are dropped. Defaults to
+// DefaultMaxAdvertSize.
+func MaxAdvertSize(size int) Option {
+	return func(o *Options) {
+		o.MaxAdvertSize = size
+	}
+}
+
 // DefaultOptions returns network default options
 func DefaultOptions() Options {
 	return Options{
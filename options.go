@@ -10,6 +10,7 @@ import (
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/cmd"
 	"github.com/micro/go-micro/v2/config"
+	"github.com/micro/go-micro/v2/debug/crash"
 	"github.com/micro/go-micro/v2/debug/profile"
 	"github.com/micro/go-micro/v2/debug/trace"
 	"github.com/micro/go-micro/v2/registry"
@@ -35,6 +36,8 @@ type Options struct {
 	Runtime   runtime.Runtime
 	Transport transport.Transport
 	Profile   profile.Profile
+	// Crash collects crash reports while the service runs
+	Crash *crash.Collector
 
 	// Before and After funcs
 	BeforeStart []func() error
@@ -42,6 +45,12 @@ type Options struct {
 	AfterStart  []func() error
 	AfterStop   []func() error
 
+	// WaitFor are the services to wait for before starting
+	WaitFor []string
+
+	// Dependencies are the services the service calls
+	Dependencies []string
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -120,6 +129,14 @@ func Profile(p profile.Profile) Option {
 	}
 }
 
+// Crash collects crash reports on panics, fatal logs and fatal signals
+// while the service runs
+func Crash(c *crash.Collector) Option {
+	return func(o *Options) {
+		o.Crash = c
+	}
+}
+
 // Server to be used for service
 func Server(s server.Server) Option {
 	return func(o *Options) {
@@ -324,6 +341,22 @@ func BeforeStart(fn func() error) Option {
 	}
 }
 
+// WaitFor blocks the service start until each of the named services has
+// a node in the registry, so dependencies can come up in any order
+func WaitFor(services ...string) Option {
+	return func(o *Options) {
+		o.WaitFor = append(o.WaitFor, services...)
+	}
+}
+
+// Dependencies declares the services the service calls. They're listed in
+// the manifest of the service and the metadata of its registry nodes.
+func Dependencies(services ...string) Option {
+	return func(o *Options) {
+		o.Dependencies = append(o.Dependencies, services...)
+	}
+}
+
 // BeforeStop run funcs before service stops
 func BeforeStop(fn func() error) Option {
 	return func(o *Options) {
@@ -0,0 +1,39 @@
+package registry
+
+import "crypto/subtle"
+
+// Authorizer decides whether the holder of a token may write to a domain.
+// Registries which support it call the authorizer before registering or
+// deregistering a service so one namespace cannot modify another's services.
+type Authorizer func(token, domain string) error
+
+// Authorize checks the token has write access to the domain using the
+// authorizer set in the options. If no authorizer is set all writes are
+// allowed. Any error returned by the authorizer is reported as ErrForbidden.
+func Authorize(o Options, token, domain string) error {
+	if o.Authorizer == nil {
+		return nil
+	}
+	if err := o.Authorizer(token, domain); err != nil {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// DomainTokens returns an authorizer which allows writes to a domain only
+// with one of the tokens listed for it. Domains which are not listed are
+// open to any token. Tokens are compared in constant time.
+func DomainTokens(tokens map[string][]string) Authorizer {
+	return func(token, domain string) error {
+		allowed, ok := tokens[domain]
+		if !ok {
+			return nil
+		}
+		for _, t := range allowed {
+			if len(t) > 0 && subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				return nil
+			}
+		}
+		return ErrForbidden
+	}
+}
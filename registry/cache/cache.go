@@ -49,7 +49,12 @@ type services map[string][]*registry.Service
 type ttls map[string]time.Time
 type watched map[string]bool
 
-var defaultTTL = time.Minute
+var (
+	defaultTTL = time.Minute
+
+	// log of the registry module
+	log = logger.NewModule("registry")
+)
 
 func backoff(attempts int) time.Duration {
 	if attempts == 0 {
@@ -368,8 +373,8 @@ func (c *cache) run(domain string) {
 			c.setStatus(err)
 
 			if a > 3 {
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debug("rcache: ", err, " backing off ", d)
+				if logger.V(logger.DebugLevel, log) {
+					log.Debug("rcache: ", err, " backing off ", d)
 				}
 				a = 0
 			}
@@ -393,8 +398,8 @@ func (c *cache) run(domain string) {
 			c.setStatus(err)
 
 			if b > 3 {
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debug("rcache: ", err, " backing off ", d)
+				if logger.V(logger.DebugLevel, log) {
+					log.Debug("rcache: ", err, " backing off ", d)
 				}
 				b = 0
 			}
@@ -17,6 +17,7 @@ import (
 	"github.com/coreos/etcd/clientv3"
 	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
 	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/micro/go-micro/v2/auth/credentials"
 	"github.com/micro/go-micro/v2/logger"
 	"github.com/micro/go-micro/v2/registry"
 	hash "github.com/mitchellh/hashstructure"
@@ -28,10 +29,23 @@ const (
 	defaultDomain = "inf"
 )
 
+var (
+	// log of the registry module
+	log = logger.NewModule("registry")
+)
+
 type etcdRegistry struct {
-	client  *clientv3.Client
 	options registry.Options
 
+	// client is replaced when the credentials are renewed
+	mtx    sync.RWMutex
+	client *clientv3.Client
+	// renewer of the credentials issued dynamically
+	renewer *credentials.Renewer
+
+	// clients using domain scoped credentials
+	clients map[string]*clientv3.Client
+
 	// register and leases are grouped by domain
 	sync.RWMutex
 	register map[string]register
@@ -87,6 +101,24 @@ func configure(e *etcdRegistry, opts ...registry.Option) error {
 		}
 	}
 
+	// credentials issued dynamically are fetched when configuring and
+	// renewed before they expire
+	var renewer *credentials.Renewer
+	if e.options.Credentials != nil {
+		var err error
+		renewer, err = credentials.NewRenewer(e.options.Credentials, credentials.RenewTimeout(e.options.Timeout))
+		if err != nil {
+			return err
+		}
+		creds, err := renewer.Credentials(context.Background())
+		if err != nil {
+			renewer.Stop()
+			return err
+		}
+		config.Username = creds.Username
+		config.Password = creds.Password
+	}
+
 	var cAddrs []string
 
 	for _, address := range e.options.Addrs {
@@ -110,12 +142,113 @@ func configure(e *etcdRegistry, opts ...registry.Option) error {
 
 	cli, err := clientv3.New(config)
 	if err != nil {
+		if renewer != nil {
+			renewer.Stop()
+		}
 		return err
 	}
+
+	e.mtx.Lock()
+	if e.renewer != nil {
+		e.renewer.Stop()
+	}
 	e.client = cli
+	e.renewer = renewer
+	e.mtx.Unlock()
+
+	if renewer != nil {
+		renewer.Notify(e.renew(config))
+	}
+
+	// setup a client for each domain with its own credentials
+	clients := make(map[string]*clientv3.Client)
+	if e.options.Context != nil {
+		creds, _ := e.options.Context.Value(domainAuthKey{}).(map[string]*authCreds)
+		for domain, u := range creds {
+			dc := config
+			dc.Username = u.Username
+			dc.Password = u.Password
+
+			dcli, err := clientv3.New(dc)
+			if err != nil {
+				closeClients(clients)
+				return err
+			}
+			clients[domain] = dcli
+		}
+	}
+
+	e.mtx.Lock()
+	old := e.clients
+	e.clients = clients
+	e.mtx.Unlock()
+
+	// the clients replaced are closed once the calls in flight timed out
+	if len(old) > 0 {
+		time.AfterFunc(e.options.Timeout, func() {
+			closeClients(old)
+		})
+	}
+
 	return nil
 }
 
+// closeClients closes the domain clients
+func closeClients(clients map[string]*clientv3.Client) {
+	for _, cli := range clients {
+		cli.Close()
+	}
+}
+
+// renew returns the func replacing the client with one using the renewed
+// credentials. The previous client is closed once the calls in flight timed out.
+func (e *etcdRegistry) renew(config clientv3.Config) func(*credentials.Credentials) {
+	return func(creds *credentials.Credentials) {
+		config.Username = creds.Username
+		config.Password = creds.Password
+
+		cli, err := clientv3.New(config)
+		if err != nil {
+			log.Errorf("Error creating etcd client with renewed credentials: %v", err)
+			return
+		}
+
+		e.mtx.Lock()
+		old := e.client
+		e.client = cli
+		e.mtx.Unlock()
+
+		time.AfterFunc(e.options.Timeout, func() {
+			old.Close()
+		})
+	}
+}
+
+// getClient returns the client used to read from all domains
+func (e *etcdRegistry) getClient() *clientv3.Client {
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+	return e.client
+}
+
+// clientFor returns the client used to write to the domain
+func (e *etcdRegistry) clientFor(domain string) *clientv3.Client {
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+	if cli, ok := e.clients[domain]; ok {
+		return cli
+	}
+	return e.client
+}
+
+// authError converts etcd permission errors to registry.ErrForbidden
+func authError(err error) error {
+	if err == rpctypes.ErrPermissionDenied {
+		return registry.ErrForbidden
+	}
+	return err
+}
+
 func encode(s *registry.Service) string {
 	b, _ := json.Marshal(s)
 	return string(b)
@@ -166,6 +299,10 @@ func (e *etcdRegistry) registerNode(s *registry.Service, node *registry.Node, op
 	if len(options.Domain) == 0 {
 		options.Domain = defaultDomain
 	}
+	if err := registry.Authorize(e.options, options.AuthToken, options.Domain); err != nil {
+		return err
+	}
+	client := e.clientFor(options.Domain)
 
 	// set the domain in metadata so it can be retrieved by wildcard queries
 	if s.Metadata == nil {
@@ -194,9 +331,9 @@ func (e *etcdRegistry) registerNode(s *registry.Service, node *registry.Node, op
 
 		// look for the existing key
 		key := nodePath(options.Domain, s.Name, node.Id)
-		rsp, err := e.client.Get(ctx, key, clientv3.WithSerializable())
+		rsp, err := client.Get(ctx, key, clientv3.WithSerializable())
 		if err != nil {
-			return err
+			return authError(err)
 		}
 
 		// get the existing lease
@@ -231,17 +368,17 @@ func (e *etcdRegistry) registerNode(s *registry.Service, node *registry.Node, op
 
 	// renew the lease if it exists
 	if leaseID > 0 {
-		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-			logger.Tracef("Renewing existing lease for %s %d", s.Name, leaseID)
+		if logger.V(logger.TraceLevel, log) {
+			log.Tracef("Renewing existing lease for %s %d", s.Name, leaseID)
 		}
 
-		if _, err := e.client.KeepAliveOnce(context.TODO(), leaseID); err != nil {
+		if _, err := client.KeepAliveOnce(context.TODO(), leaseID); err != nil {
 			if err != rpctypes.ErrLeaseNotFound {
-				return err
+				return authError(err)
 			}
 
-			if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-				logger.Tracef("Lease not found for %s %d", s.Name, leaseID)
+			if logger.V(logger.TraceLevel, log) {
+				log.Tracef("Lease not found for %s %d", s.Name, leaseID)
 			}
 
 			// lease not found do register
@@ -262,8 +399,8 @@ func (e *etcdRegistry) registerNode(s *registry.Service, node *registry.Node, op
 
 	// the service is unchanged, skip registering
 	if ok && v == h && !leaseNotFound {
-		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-			logger.Tracef("Service %s node %s unchanged skipping registration", s.Name, node.Id)
+		if logger.V(logger.TraceLevel, log) {
+			log.Tracef("Service %s node %s unchanged skipping registration", s.Name, node.Id)
 		}
 		return nil
 	}
@@ -289,14 +426,14 @@ func (e *etcdRegistry) registerNode(s *registry.Service, node *registry.Node, op
 	var lgr *clientv3.LeaseGrantResponse
 	if options.TTL.Seconds() > 0 {
 		// get a lease used to expire keys since we have a ttl
-		lgr, err = e.client.Grant(ctx, int64(options.TTL.Seconds()))
+		lgr, err = client.Grant(ctx, int64(options.TTL.Seconds()))
 		if err != nil {
-			return err
+			return authError(err)
 		}
 	}
 
-	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-		logger.Tracef("Registering %s id %s with ttl %v", service.Name, node.Id, options.TTL)
+	if logger.V(logger.TraceLevel, log) {
+		log.Tracef("Registering %s id %s with ttl %v", service.Name, node.Id, options.TTL)
 	}
 
 	// create an entry for the node
@@ -306,8 +443,8 @@ func (e *etcdRegistry) registerNode(s *registry.Service, node *registry.Node, op
 	}
 
 	key := nodePath(options.Domain, s.Name, node.Id)
-	if _, err = e.client.Put(ctx, key, encode(service), putOpts...); err != nil {
-		return err
+	if _, err = client.Put(ctx, key, encode(service), putOpts...); err != nil {
+		return authError(err)
 	}
 
 	e.Lock()
@@ -335,6 +472,10 @@ func (e *etcdRegistry) Deregister(s *registry.Service, opts ...registry.Deregist
 	if len(options.Domain) == 0 {
 		options.Domain = defaultDomain
 	}
+	if err := registry.Authorize(e.options, options.AuthToken, options.Domain); err != nil {
+		return err
+	}
+	client := e.clientFor(options.Domain)
 
 	for _, node := range s.Nodes {
 		e.Lock()
@@ -347,12 +488,12 @@ func (e *etcdRegistry) Deregister(s *registry.Service, opts ...registry.Deregist
 		ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
 		defer cancel()
 
-		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-			logger.Tracef("Deregistering %s id %s", s.Name, node.Id)
+		if logger.V(logger.TraceLevel, log) {
+			log.Tracef("Deregistering %s id %s", s.Name, node.Id)
 		}
 
-		if _, err := e.client.Delete(ctx, nodePath(options.Domain, s.Name, node.Id)); err != nil {
-			return err
+		if _, err := client.Delete(ctx, nodePath(options.Domain, s.Name, node.Id)); err != nil {
+			return authError(err)
 		}
 	}
 
@@ -391,7 +532,7 @@ func (e *etcdRegistry) GetService(name string, opts ...registry.GetOption) ([]*r
 
 	var results []*mvccpb.KeyValue
 	if options.Domain == registry.WildcardDomain {
-		rsp, err := e.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSerializable())
+		rsp, err := e.getClient().Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSerializable())
 		if err != nil {
 			return nil, err
 		}
@@ -405,7 +546,7 @@ func (e *etcdRegistry) GetService(name string, opts ...registry.GetOption) ([]*r
 		}
 	} else {
 		prefix := servicePath(options.Domain, name) + "/"
-		rsp, err := e.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSerializable())
+		rsp, err := e.getClient().Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSerializable())
 		if err != nil {
 			return nil, err
 		}
@@ -469,7 +610,7 @@ func (e *etcdRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Se
 	ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
 	defer cancel()
 
-	rsp, err := e.client.Get(ctx, p, clientv3.WithPrefix(), clientv3.WithSerializable())
+	rsp, err := e.getClient().Get(ctx, p, clientv3.WithPrefix(), clientv3.WithSerializable())
 	if err != nil {
 		return nil, err
 	}
@@ -9,6 +9,8 @@ import (
 
 type authKey struct{}
 
+type domainAuthKey struct{}
+
 type logConfigKey struct{}
 
 type authCreds struct {
@@ -26,6 +28,25 @@ func Auth(username, password string) registry.Option {
 	}
 }
 
+// DomainAuth sets the username/password used when writing to a domain. Paired
+// with an etcd role granting access to the domain's key prefix only, this stops
+// services in one domain from registering or deregistering in another.
+func DomainAuth(domain, username, password string) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		creds := map[string]*authCreds{}
+		if c, ok := o.Context.Value(domainAuthKey{}).(map[string]*authCreds); ok {
+			for d, u := range c {
+				creds[d] = u
+			}
+		}
+		creds[domain] = &authCreds{Username: username, Password: password}
+		o.Context = context.WithValue(o.Context, domainAuthKey{}, creds)
+	}
+}
+
 // LogConfig allows you to set etcd log config
 func LogConfig(config *zap.Config) registry.Option {
 	return func(o *registry.Options) {
@@ -0,0 +1,72 @@
+package etcd
+
+import (
+	"context"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+// Snapshot returns all the services in a domain. Every node is stored under its own
+// key so listing the domain prefix returns the complete set of services and nodes.
+func (e *etcdRegistry) Snapshot(domain string) ([]*registry.Service, error) {
+	return e.ListServices(registry.ListDomain(domain))
+}
+
+// Restore writes the services in a domain directly to etcd. The nodes share a single
+// lease when a TTL is provided rather than granting one per node.
+func (e *etcdRegistry) Restore(domain string, services []*registry.Service, opts ...registry.RegisterOption) error {
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(domain) == 0 {
+		domain = defaultDomain
+	}
+	if err := registry.Authorize(e.options, options.AuthToken, domain); err != nil {
+		return err
+	}
+	client := e.clientFor(domain)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
+	defer cancel()
+
+	var putOpts []clientv3.OpOption
+	if options.TTL.Seconds() > 0 {
+		lgr, err := client.Grant(ctx, int64(options.TTL.Seconds()))
+		if err != nil {
+			return authError(err)
+		}
+		putOpts = append(putOpts, clientv3.WithLease(lgr.ID))
+	}
+
+	for _, s := range services {
+		// copy the metadata and set the domain we're restoring into
+		md := make(map[string]string, len(s.Metadata)+1)
+		for k, v := range s.Metadata {
+			md[k] = v
+		}
+		md["domain"] = domain
+
+		for _, node := range s.Nodes {
+			service := &registry.Service{
+				Name:      s.Name,
+				Version:   s.Version,
+				Metadata:  md,
+				Endpoints: s.Endpoints,
+				Nodes:     []*registry.Node{node},
+			}
+
+			if logger.V(logger.TraceLevel, log) {
+				log.Tracef("Restoring %s id %s in domain %s", s.Name, node.Id, domain)
+			}
+
+			if _, err := client.Put(ctx, nodePath(domain, s.Name, node.Id), encode(service), putOpts...); err != nil {
+				return authError(err)
+			}
+		}
+	}
+
+	return nil
+}
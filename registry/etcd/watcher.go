@@ -43,10 +43,12 @@ func newEtcdWatcher(r *etcdRegistry, timeout time.Duration, opts ...registry.Wat
 		watchPath = servicePath(wo.Domain, wo.Service) + "/"
 	}
 
+	client := r.getClient()
+
 	return &etcdWatcher{
 		stop:    stop,
-		w:       r.client.Watch(ctx, watchPath, clientv3.WithPrefix(), clientv3.WithPrevKV()),
-		client:  r.client,
+		w:       client.Watch(ctx, watchPath, clientv3.WithPrefix(), clientv3.WithPrevKV()),
+		client:  client,
 		timeout: timeout,
 	}, nil
 }
@@ -0,0 +1,102 @@
+// Package limit provides a registry which rate limits writes
+package limit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrLimitExceeded is returned when a write could not be made within the timeout
+	ErrLimitExceeded = errors.New("registry write limit exceeded")
+
+	defaultRate    = 1.0
+	defaultBurst   = 5
+	defaultTimeout = time.Second * 30
+)
+
+type Options struct {
+	// Rate is the number of writes allowed per second
+	Rate float64
+	// Burst is the number of writes which can be made at once
+	Burst int
+	// Timeout is the max time a write waits for the limiter
+	Timeout time.Duration
+}
+
+type Option func(o *Options)
+
+// limit is a token bucket limiter on the Register and Deregister calls
+// made to the underlying registry. Reads are passed through.
+type limit struct {
+	registry.Registry
+	opts    Options
+	limiter *rate.Limiter
+}
+
+func (l *limit) wait(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.opts.Timeout)
+	defer cancel()
+
+	if err := l.limiter.Wait(ctx); err != nil {
+		return ErrLimitExceeded
+	}
+	return nil
+}
+
+func (l *limit) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if err := l.wait(options.Context); err != nil {
+		return err
+	}
+
+	return l.Registry.Register(s, opts...)
+}
+
+func (l *limit) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	var options registry.DeregisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if err := l.wait(options.Context); err != nil {
+		return err
+	}
+
+	return l.Registry.Deregister(s, opts...)
+}
+
+func (l *limit) String() string {
+	return l.Registry.String()
+}
+
+// New returns a registry which limits the rate of writes to r
+func New(r registry.Registry, opts ...Option) registry.Registry {
+	options := Options{
+		Rate:    defaultRate,
+		Burst:   defaultBurst,
+		Timeout: defaultTimeout,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &limit{
+		Registry: r,
+		opts:     options,
+		limiter:  rate.NewLimiter(rate.Limit(options.Rate), options.Burst),
+	}
+}
@@ -0,0 +1,32 @@
+package limit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestLimit(t *testing.T) {
+	r := New(memory.NewRegistry(), WithRate(0.1), WithBurst(1), WithTimeout(time.Millisecond*10))
+
+	svc := &registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "localhost:9999"}},
+	}
+
+	if err := r.Register(svc); err != nil {
+		t.Fatalf("Unexpected register error: %v", err)
+	}
+
+	if err := r.Register(svc); err != ErrLimitExceeded {
+		t.Fatalf("Expected %v got %v", ErrLimitExceeded, err)
+	}
+
+	// reads are not limited
+	if _, err := r.GetService("foo"); err != nil {
+		t.Fatalf("Unexpected get error: %v", err)
+	}
+}
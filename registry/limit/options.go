@@ -0,0 +1,26 @@
+package limit
+
+import (
+	"time"
+)
+
+// WithRate sets the number of registry writes allowed per second
+func WithRate(r float64) Option {
+	return func(o *Options) {
+		o.Rate = r
+	}
+}
+
+// WithBurst sets the number of writes which can be made at once
+func WithBurst(b int) Option {
+	return func(o *Options) {
+		o.Burst = b
+	}
+}
+
+// WithTimeout sets the max time a write waits for the limiter
+func WithTimeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = t
+	}
+}
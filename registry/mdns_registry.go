@@ -86,8 +86,8 @@ func encode(txt *mdnsTxt) ([]string, error) {
 	w := zlib.NewWriter(&buf)
 	defer func() {
 		if closeErr := w.Close(); closeErr != nil {
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Errorf("[mdns] registry close encoding writer err: %v", closeErr)
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("[mdns] registry close encoding writer err: %v", closeErr)
 			}
 		}
 	}()
@@ -252,8 +252,8 @@ func registerService(service *Service, entries []*mdnsEntry, options RegisterOpt
 		}
 		port, _ := strconv.Atoi(pt)
 
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("[mdns] registry create new service with ip: %s for: %s", net.ParseIP(host).String(), host)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("[mdns] registry create new service with ip: %s for: %s", net.ParseIP(host).String(), host)
 		}
 		// we got here, new node
 		s, err := mdns.NewMDNSService(
@@ -482,8 +482,8 @@ func (m *mdnsRegistry) GetService(service string, opts ...GetOption) ([]*Service
 				} else if len(e.AddrV6) > 0 {
 					addr = "[" + e.AddrV6.String() + "]"
 				} else {
-					if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-						logger.Infof("[mdns]: invalid endpoint received: %v", e)
+					if logger.V(logger.InfoLevel, log) {
+						log.Infof("[mdns]: invalid endpoint received: %v", e)
 					}
 					continue
 				}
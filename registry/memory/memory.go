@@ -14,6 +14,9 @@ import (
 var (
 	sendEventTime = 10 * time.Millisecond
 	ttlPruneTime  = time.Second
+
+	// log of the registry module
+	log = logger.NewModule("registry")
 )
 
 type node struct {
@@ -81,8 +84,8 @@ func (m *Registry) ttlPrune() {
 					for version, record := range versions {
 						for id, n := range record.Nodes {
 							if n.TTL != 0 && time.Since(n.LastSeen) > n.TTL {
-								if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-									logger.Debugf("Registry TTL expired for node %s of service %s", n.Id, service)
+								if logger.V(logger.DebugLevel, log) {
+									log.Debugf("Registry TTL expired for node %s of service %s", n.Id, service)
 								}
 								delete(m.records[domain][service][version].Nodes, id)
 							}
@@ -170,6 +173,9 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 	if len(options.Domain) == 0 {
 		options.Domain = registry.DefaultDomain
 	}
+	if err := registry.Authorize(m.options, options.AuthToken, options.Domain); err != nil {
+		return err
+	}
 
 	// get the services for this domain from the registry
 	srvs, ok := m.records[options.Domain]
@@ -192,8 +198,8 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 
 	if _, ok := srvs[s.Name][s.Version]; !ok {
 		srvs[s.Name][s.Version] = r
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Registry added new service: %s, version: %s", s.Name, s.Version)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Registry added new service: %s, version: %s", s.Name, s.Version)
 		}
 		m.records[options.Domain] = srvs
 		go m.sendEvent(&registry.Result{Action: "create", Service: s})
@@ -220,15 +226,15 @@ func (m *Registry) Register(s *registry.Service, opts ...registry.RegisterOption
 	}
 
 	if addedNodes {
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Registry added new node to service: %s, version: %s", s.Name, s.Version)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Registry added new node to service: %s, version: %s", s.Name, s.Version)
 		}
 		go m.sendEvent(&registry.Result{Action: "update", Service: s})
 	} else {
 		// refresh TTL and timestamp
 		for _, n := range s.Nodes {
-			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-				logger.Debugf("Updated registration for service: %s, version: %s", s.Name, s.Version)
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Updated registration for service: %s, version: %s", s.Name, s.Version)
 			}
 			srvs[s.Name][s.Version].Nodes[n.Id].TTL = options.TTL
 			srvs[s.Name][s.Version].Nodes[n.Id].LastSeen = time.Now()
@@ -251,6 +257,9 @@ func (m *Registry) Deregister(s *registry.Service, opts ...registry.DeregisterOp
 	if len(options.Domain) == 0 {
 		options.Domain = registry.DefaultDomain
 	}
+	if err := registry.Authorize(m.options, options.AuthToken, options.Domain); err != nil {
+		return err
+	}
 
 	// domain is set in metadata so it can be passed to watchers
 	if s.Metadata == nil {
@@ -278,8 +287,8 @@ func (m *Registry) Deregister(s *registry.Service, opts ...registry.DeregisterOp
 	// deregister all of the service nodes from this version
 	for _, n := range s.Nodes {
 		if _, ok := version.Nodes[n.Id]; ok {
-			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-				logger.Debugf("Registry removed node from service: %s, version: %s", s.Name, s.Version)
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Registry removed node from service: %s, version: %s", s.Name, s.Version)
 			}
 			delete(version.Nodes, n.Id)
 		}
@@ -299,8 +308,8 @@ func (m *Registry) Deregister(s *registry.Service, opts ...registry.DeregisterOp
 		delete(m.records[options.Domain], s.Name)
 		go m.sendEvent(&registry.Result{Action: "delete", Service: s})
 
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Registry removed service: %s", s.Name)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Registry removed service: %s", s.Name)
 		}
 		return nil
 	}
@@ -308,8 +317,8 @@ func (m *Registry) Deregister(s *registry.Service, opts ...registry.DeregisterOp
 	// there are other versions of the service running, so only remove this version of it
 	delete(m.records[options.Domain][s.Name], s.Version)
 	go m.sendEvent(&registry.Result{Action: "delete", Service: s})
-	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-		logger.Debugf("Registry removed service: %s, version: %s", s.Name, s.Version)
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("Registry removed service: %s, version: %s", s.Name, s.Version)
 	}
 
 	return nil
@@ -280,3 +280,36 @@ func TestMemoryWildcard(t *testing.T) {
 		t.Errorf("Expected 2 records, got %v", len(recs))
 	}
 }
+
+func TestMemoryAuthorizer(t *testing.T) {
+	m := NewRegistry(registry.WithAuthorizer(registry.DomainTokens(map[string][]string{
+		"one": {"secret"},
+	})))
+	testSrv := &registry.Service{Name: "foo", Version: "1.0.0"}
+
+	if err := m.Register(testSrv, registry.RegisterDomain("one")); err != registry.ErrForbidden {
+		t.Fatalf("Expected forbidden without token, got %v", err)
+	}
+	if err := m.Register(testSrv, registry.RegisterDomain("one"), registry.RegisterAuthToken("wrong")); err != registry.ErrForbidden {
+		t.Fatalf("Expected forbidden with wrong token, got %v", err)
+	}
+	if err := m.Register(testSrv, registry.RegisterDomain("one"), registry.RegisterAuthToken("secret")); err != nil {
+		t.Fatalf("Register err: %v", err)
+	}
+	if err := m.Register(testSrv, registry.RegisterDomain("two")); err != nil {
+		t.Fatalf("Register err: %v", err)
+	}
+
+	if err := m.Deregister(testSrv, registry.DeregisterDomain("one")); err != registry.ErrForbidden {
+		t.Fatalf("Expected forbidden without token, got %v", err)
+	}
+	if recs, err := m.ListServices(registry.ListDomain("one")); err != nil {
+		t.Errorf("List err: %v", err)
+	} else if len(recs) != 1 {
+		t.Errorf("Expected 1 record, got %v", len(recs))
+	}
+
+	if err := m.Deregister(testSrv, registry.DeregisterDomain("one"), registry.DeregisterAuthToken("secret")); err != nil {
+		t.Fatalf("Deregister err: %v", err)
+	}
+}
@@ -0,0 +1,548 @@
+// Package nacos provides a nacos registry using the nacos open api
+package nacos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+)
+
+const (
+	// separator between the domain and service name in the nacos service name
+	separator = "::"
+	// codeNotFound is returned by nacos when a beat is sent for an unknown instance
+	codeNotFound = 20404
+)
+
+var (
+	defaultAddr         = "127.0.0.1:8848"
+	defaultGroup        = "DEFAULT_GROUP"
+	defaultHeartbeat    = time.Second * 5
+	defaultPollInterval = time.Second * 10
+
+	// log of the registry module
+	log = logger.NewModule("registry")
+)
+
+type nacosRegistry struct {
+	opts   registry.Options
+	client *http.Client
+	addrs  []string
+
+	namespace    string
+	group        string
+	heartbeat    time.Duration
+	pollInterval time.Duration
+
+	sync.Mutex
+	// heartbeats of the registered nodes
+	beats map[string]chan bool
+}
+
+// instance is a nacos service instance
+type instance struct {
+	InstanceId  string            `json:"instanceId"`
+	Ip          string            `json:"ip"`
+	Port        int               `json:"port"`
+	Weight      float64           `json:"weight"`
+	Healthy     bool              `json:"healthy"`
+	Enabled     bool              `json:"enabled"`
+	ServiceName string            `json:"serviceName"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+type instanceList struct {
+	Name  string      `json:"name"`
+	Hosts []*instance `json:"hosts"`
+}
+
+type serviceList struct {
+	Count int      `json:"count"`
+	Doms  []string `json:"doms"`
+}
+
+// beat is sent to nacos to keep an ephemeral instance alive
+type beat struct {
+	ServiceName string            `json:"serviceName"`
+	Ip          string            `json:"ip"`
+	Port        int               `json:"port"`
+	Weight      float64           `json:"weight"`
+	Metadata    map[string]string `json:"metadata"`
+	Scheduled   bool              `json:"scheduled"`
+}
+
+type beatResponse struct {
+	Code int `json:"code"`
+}
+
+func configure(n *nacosRegistry, opts ...registry.Option) error {
+	for _, o := range opts {
+		o(&n.opts)
+	}
+
+	if n.opts.Timeout == 0 {
+		n.opts.Timeout = time.Second * 5
+	}
+
+	n.namespace = ""
+	n.group = defaultGroup
+	n.heartbeat = defaultHeartbeat
+	n.pollInterval = defaultPollInterval
+
+	if n.opts.Context != nil {
+		if v, ok := n.opts.Context.Value(namespaceKey{}).(string); ok {
+			n.namespace = v
+		}
+		if v, ok := n.opts.Context.Value(groupKey{}).(string); ok && len(v) > 0 {
+			n.group = v
+		}
+		if v, ok := n.opts.Context.Value(heartbeatKey{}).(time.Duration); ok && v > 0 {
+			n.heartbeat = v
+		}
+		if v, ok := n.opts.Context.Value(pollIntervalKey{}).(time.Duration); ok && v > 0 {
+			n.pollInterval = v
+		}
+	}
+
+	var addrs []string
+	for _, addr := range n.opts.Addrs {
+		if len(addr) == 0 {
+			continue
+		}
+		addr = strings.TrimPrefix(strings.TrimPrefix(addr, "http://"), "https://")
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "8848")
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		addrs = []string{defaultAddr}
+	}
+	n.addrs = addrs
+
+	transport := &http.Transport{}
+	if n.opts.Secure || n.opts.TLSConfig != nil {
+		transport.TLSClientConfig = n.opts.TLSConfig
+	}
+	n.client = &http.Client{
+		Timeout:   n.opts.Timeout,
+		Transport: transport,
+	}
+
+	return nil
+}
+
+// serviceName returns the nacos service name for a service in a domain. The
+// domain is encoded in the name so wildcard queries can be served by nacos.
+func serviceName(domain, name string) string {
+	return domain + separator + name
+}
+
+// splitServiceName returns the domain and service name of a nacos service name
+func splitServiceName(s string) (string, string, bool) {
+	parts := strings.SplitN(s, separator, 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// do makes a request against the nacos naming api trying each address in turn
+func (n *nacosRegistry) do(method, path string, params url.Values, rsp interface{}) error {
+	if len(n.namespace) > 0 {
+		params.Set("namespaceId", n.namespace)
+	}
+
+	scheme := "http"
+	if n.opts.Secure || n.opts.TLSConfig != nil {
+		scheme = "https"
+	}
+
+	var gerr error
+
+	for _, addr := range n.addrs {
+		uri := fmt.Sprintf("%s://%s/nacos/v1/ns%s?%s", scheme, addr, path, params.Encode())
+
+		req, err := http.NewRequest(method, uri, nil)
+		if err != nil {
+			return err
+		}
+
+		r, err := n.client.Do(req)
+		if err != nil {
+			gerr = err
+			continue
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			gerr = err
+			continue
+		}
+
+		// try the next server on server errors
+		if r.StatusCode >= 500 {
+			gerr = fmt.Errorf("nacos %s %s error: %s", method, path, string(b))
+			continue
+		}
+
+		if r.StatusCode != http.StatusOK {
+			return fmt.Errorf("nacos %s %s error: %s", method, path, string(b))
+		}
+
+		if rsp == nil {
+			return nil
+		}
+
+		return json.Unmarshal(b, rsp)
+	}
+
+	return gerr
+}
+
+func (n *nacosRegistry) Init(opts ...registry.Option) error {
+	return configure(n, opts...)
+}
+
+func (n *nacosRegistry) Options() registry.Options {
+	return n.opts
+}
+
+// nodeParams returns the instance params of a service node
+func (n *nacosRegistry) nodeParams(domain string, s *registry.Service, node *registry.Node) (url.Values, error) {
+	host, pt, err := net.SplitHostPort(node.Address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(pt)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("serviceName", serviceName(domain, s.Name))
+	params.Set("groupName", n.group)
+	params.Set("ip", host)
+	params.Set("port", strconv.Itoa(port))
+	params.Set("ephemeral", "true")
+	return params, nil
+}
+
+func (n *nacosRegistry) registerNode(domain string, s *registry.Service, node *registry.Node) error {
+	params, err := n.nodeParams(domain, s, node)
+	if err != nil {
+		return err
+	}
+
+	md, err := encodeMetadata(domain, s, node)
+	if err != nil {
+		return err
+	}
+
+	params.Set("weight", "1")
+	params.Set("enabled", "true")
+	params.Set("healthy", "true")
+	params.Set("metadata", md)
+
+	return n.do(http.MethodPost, "/instance", params, nil)
+}
+
+// sendBeat keeps the node alive in nacos, registering it again if nacos
+// has already expired it
+func (n *nacosRegistry) sendBeat(domain string, s *registry.Service, node *registry.Node) error {
+	params, err := n.nodeParams(domain, s, node)
+	if err != nil {
+		return err
+	}
+
+	md, err := instanceMetadata(domain, s, node)
+	if err != nil {
+		return err
+	}
+
+	port, _ := strconv.Atoi(params.Get("port"))
+	b, err := json.Marshal(&beat{
+		ServiceName: n.group + "@@" + params.Get("serviceName"),
+		Ip:          params.Get("ip"),
+		Port:        port,
+		Weight:      1,
+		Metadata:    beatMetadata(md),
+		Scheduled:   true,
+	})
+	if err != nil {
+		return err
+	}
+	params.Set("beat", string(b))
+
+	var rsp beatResponse
+	if err := n.do(http.MethodPut, "/instance/beat", params, &rsp); err != nil {
+		return err
+	}
+
+	if rsp.Code == codeNotFound {
+		return n.registerNode(domain, s, node)
+	}
+
+	return nil
+}
+
+func (n *nacosRegistry) startBeat(domain string, s *registry.Service, node *registry.Node) {
+	key := serviceName(domain, s.Name) + "/" + node.Id
+
+	n.Lock()
+	if _, ok := n.beats[key]; ok {
+		n.Unlock()
+		return
+	}
+	exit := make(chan bool)
+	n.beats[key] = exit
+	n.Unlock()
+
+	go func() {
+		t := time.NewTicker(n.heartbeat)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				if err := n.sendBeat(domain, s, node); err != nil {
+					if logger.V(logger.DebugLevel, log) {
+						log.Debugf("[nacos] registry heartbeat for %s %s error: %v", s.Name, node.Id, err)
+					}
+				}
+			case <-exit:
+				return
+			}
+		}
+	}()
+}
+
+func (n *nacosRegistry) stopBeat(domain string, s *registry.Service, node *registry.Node) {
+	key := serviceName(domain, s.Name) + "/" + node.Id
+
+	n.Lock()
+	defer n.Unlock()
+
+	if exit, ok := n.beats[key]; ok {
+		close(exit)
+		delete(n.beats, key)
+	}
+}
+
+func (n *nacosRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	var gerr error
+
+	for _, node := range s.Nodes {
+		if logger.V(logger.TraceLevel, log) {
+			log.Tracef("[nacos] Registering %s id %s", s.Name, node.Id)
+		}
+
+		if err := n.registerNode(options.Domain, s, node); err != nil {
+			gerr = err
+			continue
+		}
+
+		n.startBeat(options.Domain, s, node)
+	}
+
+	return gerr
+}
+
+func (n *nacosRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	var options registry.DeregisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	for _, node := range s.Nodes {
+		n.stopBeat(options.Domain, s, node)
+
+		params, err := n.nodeParams(options.Domain, s, node)
+		if err != nil {
+			return err
+		}
+
+		if logger.V(logger.TraceLevel, log) {
+			log.Tracef("[nacos] Deregistering %s id %s", s.Name, node.Id)
+		}
+
+		if err := n.do(http.MethodDelete, "/instance", params, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listNames returns the nacos service names in the group
+func (n *nacosRegistry) listNames() ([]string, error) {
+	var names []string
+
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("groupName", n.group)
+		params.Set("pageNo", strconv.Itoa(page))
+		params.Set("pageSize", "500")
+
+		var rsp serviceList
+		if err := n.do(http.MethodGet, "/service/list", params, &rsp); err != nil {
+			return nil, err
+		}
+
+		names = append(names, rsp.Doms...)
+		if len(rsp.Doms) == 0 || len(names) >= rsp.Count {
+			return names, nil
+		}
+	}
+}
+
+// getServices returns the services registered under a nacos service name
+func (n *nacosRegistry) getServices(name string) ([]*registry.Service, error) {
+	params := url.Values{}
+	params.Set("serviceName", name)
+	params.Set("groupName", n.group)
+	params.Set("healthyOnly", "true")
+
+	var rsp instanceList
+	if err := n.do(http.MethodGet, "/instance/list", params, &rsp); err != nil {
+		return nil, err
+	}
+
+	return decodeInstances(name, rsp.Hosts), nil
+}
+
+// namesFor returns the nacos service names matching a service in a domain
+func (n *nacosRegistry) namesFor(domain, service string) ([]string, error) {
+	if domain != registry.WildcardDomain {
+		return []string{serviceName(domain, service)}, nil
+	}
+
+	names, err := n.listNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []string
+	for _, name := range names {
+		if _, s, ok := splitServiceName(name); ok && s == service {
+			matched = append(matched, name)
+		}
+	}
+	return matched, nil
+}
+
+func (n *nacosRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	var options registry.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	names, err := n.namesFor(options.Domain, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []*registry.Service
+	for _, name := range names {
+		srvs, err := n.getServices(name)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, srvs...)
+	}
+
+	if len(services) == 0 {
+		return nil, registry.ErrNotFound
+	}
+
+	return services, nil
+}
+
+func (n *nacosRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	var options registry.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	names, err := n.listNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []*registry.Service
+	for _, name := range names {
+		domain, _, ok := splitServiceName(name)
+		if !ok {
+			continue
+		}
+		if options.Domain != registry.WildcardDomain && options.Domain != domain {
+			continue
+		}
+
+		srvs, err := n.getServices(name)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, srvs...)
+	}
+
+	// sort the services
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	return services, nil
+}
+
+func (n *nacosRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	return newNacosWatcher(n, opts...)
+}
+
+func (n *nacosRegistry) String() string {
+	return "nacos"
+}
+
+// NewRegistry returns a new nacos registry
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	n := &nacosRegistry{
+		beats: make(map[string]chan bool),
+	}
+	configure(n, opts...)
+	return n
+}
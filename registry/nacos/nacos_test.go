@@ -0,0 +1,147 @@
+package nacos
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+// testServer is a minimal implementation of the nacos naming api
+func testServer() *httptest.Server {
+	var mtx sync.Mutex
+	instances := make(map[string][]*instance)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		q := r.URL.Query()
+		name := q.Get("serviceName")
+
+		switch {
+		case r.URL.Path == "/nacos/v1/ns/instance" && r.Method == http.MethodPost:
+			var md map[string]string
+			json.Unmarshal([]byte(q.Get("metadata")), &md)
+			port := 0
+			json.Unmarshal([]byte(q.Get("port")), &port)
+			instances[name] = append(instances[name], &instance{
+				Ip:       q.Get("ip"),
+				Port:     port,
+				Healthy:  true,
+				Enabled:  true,
+				Metadata: md,
+			})
+			w.Write([]byte("ok"))
+		case r.URL.Path == "/nacos/v1/ns/instance" && r.Method == http.MethodDelete:
+			delete(instances, name)
+			w.Write([]byte("ok"))
+		case r.URL.Path == "/nacos/v1/ns/instance/list":
+			json.NewEncoder(w).Encode(&instanceList{Name: name, Hosts: instances[name]})
+		case r.URL.Path == "/nacos/v1/ns/service/list":
+			var names []string
+			for k := range instances {
+				names = append(names, k)
+			}
+			json.NewEncoder(w).Encode(&serviceList{Count: len(names), Doms: names})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRegistry(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	r := NewRegistry(registry.Addrs(strings.TrimPrefix(srv.URL, "http://")))
+
+	svc := &registry.Service{
+		Name:      "foo",
+		Version:   "1.0.0",
+		Metadata:  map[string]string{"foo": "bar"},
+		Endpoints: []*registry.Endpoint{{Name: "Foo.Bar"}},
+		Nodes: []*registry.Node{
+			{Id: "foo-1", Address: "10.0.0.1:8080", Metadata: map[string]string{"protocol": "grpc"}},
+		},
+	}
+
+	if err := r.Register(svc, registry.RegisterDomain("test")); err != nil {
+		t.Fatalf("Unexpected register error: %v", err)
+	}
+	defer r.Deregister(svc, registry.DeregisterDomain("test"))
+
+	srvs, err := r.GetService("foo", registry.GetDomain("test"))
+	if err != nil {
+		t.Fatalf("Unexpected get error: %v", err)
+	}
+	if len(srvs) != 1 {
+		t.Fatalf("Expected 1 service, got %v", len(srvs))
+	}
+
+	s := srvs[0]
+	if s.Version != "1.0.0" || s.Metadata["domain"] != "test" || s.Metadata["foo"] != "bar" {
+		t.Fatalf("Unexpected service %+v", s)
+	}
+	if len(s.Endpoints) != 1 || s.Endpoints[0].Name != "Foo.Bar" {
+		t.Fatalf("Unexpected endpoints %+v", s.Endpoints)
+	}
+	if len(s.Nodes) != 1 || s.Nodes[0].Id != "foo-1" || s.Nodes[0].Address != "10.0.0.1:8080" {
+		t.Fatalf("Unexpected nodes %+v", s.Nodes)
+	}
+	if s.Nodes[0].Metadata["protocol"] != "grpc" {
+		t.Fatalf("Unexpected node metadata %+v", s.Nodes[0].Metadata)
+	}
+
+	// wildcard queries
+	if _, err := r.GetService("foo", registry.GetDomain(registry.WildcardDomain)); err != nil {
+		t.Fatalf("Unexpected wildcard get error: %v", err)
+	}
+
+	// other domains
+	if _, err := r.GetService("foo"); err != registry.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", registry.ErrNotFound, err)
+	}
+
+	list, err := r.ListServices(registry.ListDomain("test"))
+	if err != nil {
+		t.Fatalf("Unexpected list error: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("Expected 1 service, got %v", len(list))
+	}
+}
+
+func TestBeatMetadata(t *testing.T) {
+	beats := make(chan *beat, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b beat
+		json.Unmarshal([]byte(r.URL.Query().Get("beat")), &b)
+		beats <- &b
+		json.NewEncoder(w).Encode(&beatResponse{Code: 10200})
+	}))
+	defer srv.Close()
+
+	r := NewRegistry(registry.Addrs(strings.TrimPrefix(srv.URL, "http://"))).(*nacosRegistry)
+
+	svc := &registry.Service{
+		Name:      "foo",
+		Version:   "1.0.0",
+		Endpoints: []*registry.Endpoint{{Name: "Foo.Bar"}},
+	}
+	node := &registry.Node{Id: "foo-1", Address: "10.0.0.1:8080", Metadata: map[string]string{"secret": "value"}}
+
+	if err := r.sendBeat("test", svc, node); err != nil {
+		t.Fatalf("Unexpected beat error: %v", err)
+	}
+
+	// only the keys identifying the node are sent
+	b := <-beats
+	if len(b.Metadata) != 2 || b.Metadata[idKey] != "foo-1" || b.Metadata[versionKey] != "1.0.0" {
+		t.Fatalf("Unexpected beat metadata %+v", b.Metadata)
+	}
+}
@@ -0,0 +1,44 @@
+package nacos
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+type namespaceKey struct{}
+type groupKey struct{}
+type heartbeatKey struct{}
+type pollIntervalKey struct{}
+
+// Namespace sets the nacos namespace id services are registered in
+func Namespace(id string) registry.Option {
+	return setRegistryOption(namespaceKey{}, id)
+}
+
+// Group sets the nacos group services are registered in
+func Group(g string) registry.Option {
+	return setRegistryOption(groupKey{}, g)
+}
+
+// Heartbeat sets the interval on which registered instances send beats to
+// nacos. Nacos marks an ephemeral instance unhealthy after 15 seconds without
+// a beat so this should be well below that.
+func Heartbeat(d time.Duration) registry.Option {
+	return setRegistryOption(heartbeatKey{}, d)
+}
+
+// PollInterval sets the interval on which watchers poll nacos for changes
+func PollInterval(d time.Duration) registry.Option {
+	return setRegistryOption(pollIntervalKey{}, d)
+}
+
+func setRegistryOption(k, v interface{}) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
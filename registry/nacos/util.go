@@ -0,0 +1,138 @@
+package nacos
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+// keys used to store the service in the nacos instance metadata
+const (
+	idKey        = "micro.id"
+	versionKey   = "micro.version"
+	endpointsKey = "micro.endpoints"
+	metadataKey  = "micro.metadata"
+)
+
+// beatKeys are the keys of the instance metadata sent with the beats, which
+// only identify the node. The rest is only sent when registering it.
+var beatKeys = []string{idKey, versionKey}
+
+// beatMetadata returns the whitelisted keys of the instance metadata
+func beatMetadata(md map[string]string) map[string]string {
+	bmd := make(map[string]string, len(beatKeys))
+	for _, k := range beatKeys {
+		if v, ok := md[k]; ok {
+			bmd[k] = v
+		}
+	}
+	return bmd
+}
+
+// encodeMetadata returns the encoded instance metadata for a node
+func encodeMetadata(domain string, s *registry.Service, node *registry.Node) (string, error) {
+	md, err := instanceMetadata(domain, s, node)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(md)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// instanceMetadata returns the instance metadata for a node. The node metadata
+// is stored as is while the service attributes are stored under reserved keys.
+func instanceMetadata(domain string, s *registry.Service, node *registry.Node) (map[string]string, error) {
+	md := make(map[string]string, len(node.Metadata)+4)
+	for k, v := range node.Metadata {
+		md[k] = v
+	}
+
+	// set the domain in metadata so it can be retrieved by wildcard queries
+	smd := make(map[string]string, len(s.Metadata)+1)
+	for k, v := range s.Metadata {
+		smd[k] = v
+	}
+	smd["domain"] = domain
+
+	b, err := json.Marshal(smd)
+	if err != nil {
+		return nil, err
+	}
+	md[metadataKey] = string(b)
+
+	b, err = json.Marshal(s.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	md[endpointsKey] = string(b)
+
+	md[idKey] = node.Id
+	md[versionKey] = s.Version
+
+	return md, nil
+}
+
+// decodeInstances groups the instances of a nacos service by version
+func decodeInstances(serviceName string, hosts []*instance) []*registry.Service {
+	domain, name, ok := splitServiceName(serviceName)
+	if !ok {
+		return nil
+	}
+
+	versions := make(map[string]*registry.Service)
+	var services []*registry.Service
+
+	for _, h := range hosts {
+		if !h.Enabled || !h.Healthy {
+			continue
+		}
+
+		version := h.Metadata[versionKey]
+
+		s, ok := versions[version]
+		if !ok {
+			s = &registry.Service{
+				Name:    name,
+				Version: version,
+			}
+			if v, ok := h.Metadata[metadataKey]; ok {
+				json.Unmarshal([]byte(v), &s.Metadata)
+			}
+			if s.Metadata == nil {
+				s.Metadata = map[string]string{"domain": domain}
+			}
+			if v, ok := h.Metadata[endpointsKey]; ok {
+				json.Unmarshal([]byte(v), &s.Endpoints)
+			}
+			versions[version] = s
+			services = append(services, s)
+		}
+
+		md := make(map[string]string, len(h.Metadata))
+		for k, v := range h.Metadata {
+			if strings.HasPrefix(k, "micro.") {
+				continue
+			}
+			md[k] = v
+		}
+
+		id := h.Metadata[idKey]
+		if len(id) == 0 {
+			id = h.InstanceId
+		}
+
+		s.Nodes = append(s.Nodes, &registry.Node{
+			Id:       id,
+			Address:  net.JoinHostPort(h.Ip, strconv.Itoa(h.Port)),
+			Metadata: md,
+		})
+	}
+
+	return services
+}
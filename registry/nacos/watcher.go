@@ -0,0 +1,174 @@
+package nacos
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+	hash "github.com/mitchellh/hashstructure"
+)
+
+// nacosWatcher polls nacos for the watched services and emits the
+// difference between each poll as registry results
+type nacosWatcher struct {
+	n    *nacosRegistry
+	wo   registry.WatchOptions
+	next chan *registry.Result
+	exit chan bool
+
+	// hashes of the services seen in the last poll
+	services map[string]*registry.Service
+	hashes   map[string]uint64
+}
+
+func newNacosWatcher(n *nacosRegistry, opts ...registry.WatchOption) (registry.Watcher, error) {
+	var wo registry.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	if len(wo.Domain) == 0 {
+		wo.Domain = registry.DefaultDomain
+	}
+
+	w := &nacosWatcher{
+		n:        n,
+		wo:       wo,
+		next:     make(chan *registry.Result, 32),
+		exit:     make(chan bool),
+		services: make(map[string]*registry.Service),
+		hashes:   make(map[string]uint64),
+	}
+
+	// load the initial state so only changes are emitted
+	services, err := w.poll()
+	if err != nil {
+		return nil, err
+	}
+	for key, s := range services {
+		h, _ := hash.Hash(s, nil)
+		w.services[key] = s
+		w.hashes[key] = h
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// poll returns the watched services keyed by domain, name and version
+func (w *nacosWatcher) poll() (map[string]*registry.Service, error) {
+	var names []string
+
+	if len(w.wo.Service) > 0 && w.wo.Domain != registry.WildcardDomain {
+		names = []string{serviceName(w.wo.Domain, w.wo.Service)}
+	} else {
+		all, err := w.n.listNames()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range all {
+			domain, service, ok := splitServiceName(name)
+			if !ok {
+				continue
+			}
+			if w.wo.Domain != registry.WildcardDomain && w.wo.Domain != domain {
+				continue
+			}
+			if len(w.wo.Service) > 0 && w.wo.Service != service {
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+
+	services := make(map[string]*registry.Service)
+	for _, name := range names {
+		srvs, err := w.n.getServices(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range srvs {
+			services[name+"/"+s.Version] = s
+		}
+	}
+
+	return services, nil
+}
+
+func (w *nacosWatcher) run() {
+	t := time.NewTicker(w.n.pollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.exit:
+			return
+		case <-t.C:
+		}
+
+		services, err := w.poll()
+		if err != nil {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("[nacos] registry watcher poll error: %v", err)
+			}
+			continue
+		}
+
+		var results []*registry.Result
+
+		for key, s := range services {
+			h, err := hash.Hash(s, nil)
+			if err != nil {
+				continue
+			}
+
+			old, ok := w.hashes[key]
+			switch {
+			case !ok:
+				results = append(results, &registry.Result{Action: registry.Create.String(), Service: s})
+			case old != h:
+				results = append(results, &registry.Result{Action: registry.Update.String(), Service: s})
+			default:
+				continue
+			}
+
+			w.hashes[key] = h
+			w.services[key] = s
+		}
+
+		for key, s := range w.services {
+			if _, ok := services[key]; ok {
+				continue
+			}
+			results = append(results, &registry.Result{Action: registry.Delete.String(), Service: s})
+			delete(w.hashes, key)
+			delete(w.services, key)
+		}
+
+		for _, r := range results {
+			select {
+			case w.next <- r:
+			case <-w.exit:
+				return
+			}
+		}
+	}
+}
+
+func (w *nacosWatcher) Next() (*registry.Result, error) {
+	select {
+	case r := <-w.next:
+		return r, nil
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+func (w *nacosWatcher) Stop() {
+	select {
+	case <-w.exit:
+		return
+	default:
+		close(w.exit)
+	}
+}
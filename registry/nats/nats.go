@@ -0,0 +1,562 @@
+// Package nats provides a NATS registry using broadcast queries
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+	util "github.com/micro/go-micro/v2/util/registry"
+	"github.com/nats-io/nats.go"
+)
+
+var (
+	defaultQueryTopic = "micro.registry.nats.query"
+	defaultWatchTopic = "micro.registry.nats.watch"
+
+	// the interval the expired nodes are pruned at
+	ttlPruneTime = time.Second
+
+	// log of the registry module
+	log = logger.NewModule("registry")
+)
+
+const (
+	getAction  = "get"
+	listAction = "list"
+)
+
+type natsRegistry struct {
+	addrs      []string
+	opts       registry.Options
+	nopts      nats.Options
+	queryTopic string
+	watchTopic string
+
+	sync.RWMutex
+	conn *nats.Conn
+	exit chan bool
+	// services registered by this instance grouped by domain
+	services map[string]map[string][]*registry.Service
+	// expiry of the nodes registered with a ttl
+	expiry map[string]time.Time
+}
+
+// message is sent on both the query and watch topics. Queries carry
+// the get or list action, watch broadcasts the registry result action.
+type message struct {
+	Action  string            `json:"action"`
+	Domain  string            `json:"domain"`
+	Service *registry.Service `json:"service"`
+}
+
+func configure(n *natsRegistry, opts ...registry.Option) error {
+	for _, o := range opts {
+		o(&n.opts)
+	}
+
+	natsOptions := nats.GetDefaultOptions()
+	if nopts, ok := n.opts.Context.Value(optionsKey{}).(nats.Options); ok {
+		natsOptions = nopts
+	}
+
+	queryTopic := defaultQueryTopic
+	if qt, ok := n.opts.Context.Value(queryTopicKey{}).(string); ok {
+		queryTopic = qt
+	}
+
+	watchTopic := defaultWatchTopic
+	if wt, ok := n.opts.Context.Value(watchTopicKey{}).(string); ok {
+		watchTopic = wt
+	}
+
+	// registry.Options have higher priority than nats.Options
+	// only if Addrs, Secure or TLSConfig were not set through a registry.Option
+	// we read them from nats.Option
+	if len(n.opts.Addrs) == 0 {
+		n.opts.Addrs = natsOptions.Servers
+	}
+
+	if !n.opts.Secure {
+		n.opts.Secure = natsOptions.Secure
+	}
+
+	if n.opts.TLSConfig == nil {
+		n.opts.TLSConfig = natsOptions.TLSConfig
+	}
+
+	// check & add nats:// prefix (this makes also sure that the addresses
+	// stored in natsRegistry.addrs and options.Addrs are identical)
+	n.opts.Addrs = setAddrs(n.opts.Addrs)
+
+	n.addrs = n.opts.Addrs
+	n.nopts = natsOptions
+	n.queryTopic = queryTopic
+	n.watchTopic = watchTopic
+
+	return nil
+}
+
+func setAddrs(addrs []string) []string {
+	//nolint:prealloc
+	var cAddrs []string
+	for _, addr := range addrs {
+		if len(addr) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(addr, "nats://") {
+			addr = "nats://" + addr
+		}
+		cAddrs = append(cAddrs, addr)
+	}
+	if len(cAddrs) == 0 {
+		cAddrs = []string{nats.DefaultURL}
+	}
+	return cAddrs
+}
+
+// getConn returns the nats connection, connecting and subscribing to
+// the query topic if required
+func (n *natsRegistry) getConn() (*nats.Conn, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.conn != nil {
+		return n.conn, nil
+	}
+
+	opts := n.nopts
+	opts.Servers = n.addrs
+	opts.Secure = n.opts.Secure
+	opts.TLSConfig = n.opts.TLSConfig
+
+	// secure might not be set
+	if opts.TLSConfig != nil {
+		opts.Secure = true
+	}
+
+	c, err := opts.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	// every instance answers queries for the services it has registered
+	if _, err := c.Subscribe(n.queryTopic, n.handleQuery); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	n.conn = c
+	n.exit = make(chan bool)
+	go n.ttlPrune(n.exit)
+	return n.conn, nil
+}
+
+// nodeKey returns the key of the expiry of a node
+func nodeKey(domain string, s *registry.Service, node *registry.Node) string {
+	return domain + "/" + s.Name + "/" + s.Version + "/" + node.Id
+}
+
+// ttlPrune deregisters the nodes not registered again within their ttl,
+// until exit is closed
+func (n *natsRegistry) ttlPrune(exit chan bool) {
+	prune := time.NewTicker(ttlPruneTime)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-prune.C:
+		}
+
+		for _, r := range n.expire(time.Now()) {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("[nats] registry TTL expired for service %s", r.Service.Name)
+			}
+			if err := n.publish(registry.Delete.String(), r.Domain, r.Service); err != nil && logger.V(logger.DebugLevel, log) {
+				log.Debugf("[nats] registry failed to publish expiry: %v", err)
+			}
+		}
+	}
+}
+
+// expire removes the nodes expired by now, returning the services of the
+// nodes removed
+func (n *natsRegistry) expire(now time.Time) []*message {
+	n.Lock()
+	defer n.Unlock()
+
+	var expired []*message
+	for domain, services := range n.services {
+		for name, versions := range services {
+			var removed []*registry.Service
+			for _, srv := range versions {
+				var nodes []*registry.Node
+				for _, node := range srv.Nodes {
+					key := nodeKey(domain, srv, node)
+					if t, ok := n.expiry[key]; ok && now.After(t) {
+						delete(n.expiry, key)
+						nodes = append(nodes, node)
+					}
+				}
+				if len(nodes) == 0 {
+					continue
+				}
+				s := util.CopyService(srv)
+				s.Nodes = nodes
+				removed = append(removed, s)
+				expired = append(expired, &message{Domain: domain, Service: s})
+			}
+			if len(removed) == 0 {
+				continue
+			}
+			services[name] = util.Remove(versions, removed)
+			if len(services[name]) == 0 {
+				delete(services, name)
+			}
+		}
+		if len(services) == 0 {
+			delete(n.services, domain)
+		}
+	}
+	return expired
+}
+
+// handleQuery responds to a get or list query with the matching
+// services registered by this instance
+func (n *natsRegistry) handleQuery(m *nats.Msg) {
+	if len(m.Reply) == 0 {
+		return
+	}
+
+	var q message
+	if err := json.Unmarshal(m.Data, &q); err != nil {
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("[nats] registry failed to decode query: %v", err)
+		}
+		return
+	}
+
+	var results []*message
+
+	n.RLock()
+	for domain, services := range n.services {
+		if q.Domain != registry.WildcardDomain && q.Domain != domain {
+			continue
+		}
+
+		for name, versions := range services {
+			if q.Action == getAction && (q.Service == nil || q.Service.Name != name) {
+				continue
+			}
+			for _, srv := range versions {
+				results = append(results, &message{Action: q.Action, Domain: domain, Service: srv})
+			}
+		}
+	}
+	n.RUnlock()
+
+	for _, r := range results {
+		b, err := json.Marshal(r)
+		if err != nil {
+			continue
+		}
+		if err := m.Respond(b); err != nil {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("[nats] registry failed to respond to query: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// query broadcasts a query and collects the responses until the timeout
+// expires or the quorum of distinct nodes is reached
+func (n *natsRegistry) query(q *message, quorum int) ([]*registry.Service, error) {
+	conn, err := n.getConn()
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := nats.NewInbox()
+	ch := make(chan *nats.Msg, 64)
+
+	sub, err := conn.ChanSubscribe(inbox, ch)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	b, err := json.Marshal(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.PublishMsg(&nats.Msg{Subject: n.queryTopic, Reply: inbox, Data: b}); err != nil {
+		return nil, err
+	}
+
+	timeout := time.After(n.opts.Timeout)
+	versions := make(map[string]*registry.Service)
+	// the nodes seen, a node answering twice counts once
+	nodes := make(map[string]bool)
+
+loop:
+	for {
+		select {
+		case m := <-ch:
+			var r message
+			if err := json.Unmarshal(m.Data, &r); err != nil || r.Service == nil {
+				continue
+			}
+
+			addResponse(versions, nodes, &r)
+
+			if quorum > 0 && len(nodes) >= quorum {
+				break loop
+			}
+		case <-timeout:
+			break loop
+		}
+	}
+
+	services := make([]*registry.Service, 0, len(versions))
+	for _, s := range versions {
+		services = append(services, s)
+	}
+
+	return services, nil
+}
+
+// addResponse merges the service of a response into the versions, adding
+// the nodes not seen yet
+func addResponse(versions map[string]*registry.Service, nodes map[string]bool, r *message) {
+	// services with the same name may exist in separate domains and are
+	// returned individually for wildcard queries
+	key := r.Domain + "/" + r.Service.Name + "/" + r.Service.Version
+	s, ok := versions[key]
+	if !ok {
+		s = util.CopyService(r.Service)
+		s.Nodes = nil
+		versions[key] = s
+	}
+	for _, node := range r.Service.Nodes {
+		id := nodeKey(r.Domain, s, node)
+		if nodes[id] {
+			continue
+		}
+		nodes[id] = true
+		s.Nodes = append(s.Nodes, node)
+	}
+}
+
+func (n *natsRegistry) publish(action, domain string, s *registry.Service) error {
+	conn, err := n.getConn()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(&message{Action: action, Domain: domain, Service: s})
+	if err != nil {
+		return err
+	}
+
+	return conn.Publish(n.watchTopic, b)
+}
+
+func (n *natsRegistry) Init(opts ...registry.Option) error {
+	n.Lock()
+	defer n.Unlock()
+
+	// reset the connection so the new options are applied on next use
+	if n.conn != nil {
+		close(n.exit)
+		n.conn.Close()
+		n.conn = nil
+	}
+
+	return configure(n, opts...)
+}
+
+func (n *natsRegistry) Options() registry.Options {
+	return n.opts
+}
+
+func (n *natsRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	// ensure we're connected and answering queries
+	if _, err := n.getConn(); err != nil {
+		return err
+	}
+
+	// set the domain in metadata so it can be retrieved by wildcard queries
+	srv := util.CopyService(s)
+	srv.Metadata = make(map[string]string, len(s.Metadata)+1)
+	for k, v := range s.Metadata {
+		srv.Metadata[k] = v
+	}
+	srv.Metadata["domain"] = options.Domain
+
+	action := registry.Create.String()
+
+	n.Lock()
+	if _, ok := n.services[options.Domain]; !ok {
+		n.services[options.Domain] = make(map[string][]*registry.Service)
+	}
+	for _, v := range n.services[options.Domain][s.Name] {
+		if v.Version == s.Version {
+			action = registry.Update.String()
+			break
+		}
+	}
+	n.services[options.Domain][s.Name] = util.Merge(n.services[options.Domain][s.Name], []*registry.Service{srv})
+	// the nodes registered with a ttl expire unless registered again
+	for _, node := range srv.Nodes {
+		key := nodeKey(options.Domain, srv, node)
+		if options.TTL > 0 {
+			n.expiry[key] = time.Now().Add(options.TTL)
+		} else {
+			delete(n.expiry, key)
+		}
+	}
+	n.Unlock()
+
+	return n.publish(action, options.Domain, srv)
+}
+
+func (n *natsRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	if len(s.Nodes) == 0 {
+		return errors.New("Require at least one node")
+	}
+
+	var options registry.DeregisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	n.Lock()
+	for _, node := range s.Nodes {
+		delete(n.expiry, nodeKey(options.Domain, s, node))
+	}
+	if services, ok := n.services[options.Domain]; ok {
+		services[s.Name] = util.Remove(services[s.Name], []*registry.Service{s})
+		if len(services[s.Name]) == 0 {
+			delete(services, s.Name)
+		}
+		if len(services) == 0 {
+			delete(n.services, options.Domain)
+		}
+	}
+	n.Unlock()
+
+	return n.publish(registry.Delete.String(), options.Domain, s)
+}
+
+func (n *natsRegistry) GetService(s string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	var options registry.GetOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	q := &message{
+		Action:  getAction,
+		Domain:  options.Domain,
+		Service: &registry.Service{Name: s},
+	}
+
+	services, err := n.query(q, getQuorum(n.opts))
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, registry.ErrNotFound
+	}
+	return services, nil
+}
+
+func (n *natsRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	var options registry.ListOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Domain) == 0 {
+		options.Domain = registry.DefaultDomain
+	}
+
+	services, err := n.query(&message{Action: listAction, Domain: options.Domain}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// sort the services
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	return services, nil
+}
+
+func (n *natsRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	conn, err := n.getConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var wo registry.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	if len(wo.Domain) == 0 {
+		wo.Domain = registry.DefaultDomain
+	}
+
+	ch := make(chan *nats.Msg, 32)
+	sub, err := conn.ChanSubscribe(n.watchTopic, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &natsWatcher{sub: sub, ch: ch, exit: make(chan bool), wo: wo}, nil
+}
+
+func (n *natsRegistry) String() string {
+	return "nats"
+}
+
+// NewRegistry returns a new nats registry
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	options := registry.Options{
+		Timeout: time.Millisecond * 100,
+		Context: context.Background(),
+	}
+
+	n := &natsRegistry{
+		opts:     options,
+		services: make(map[string]map[string][]*registry.Service),
+		expiry:   make(map[string]time.Time),
+	}
+	configure(n, opts...)
+	return n
+}
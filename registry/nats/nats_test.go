@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/nats-io/nats.go"
+)
+
+func TestInitAddrs(t *testing.T) {
+	testData := []struct {
+		name   string
+		opts   []registry.Option
+		expect []string
+	}{
+		{"default", nil, []string{nats.DefaultURL}},
+		{"registryOpts", []registry.Option{registry.Addrs("10.0.0.1:4222")}, []string{"nats://10.0.0.1:4222"}},
+		{"natsOpts", []registry.Option{Options(nats.Options{Servers: []string{"nats://10.0.0.2:4222"}})}, []string{"nats://10.0.0.2:4222"}},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			r := NewRegistry(d.opts...).(*natsRegistry)
+			if len(r.addrs) != len(d.expect) {
+				t.Fatalf("Expected %v got %v", d.expect, r.addrs)
+			}
+			for i, addr := range d.expect {
+				if r.addrs[i] != addr {
+					t.Fatalf("Expected %s got %s", addr, r.addrs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTopics(t *testing.T) {
+	r := NewRegistry(QueryTopic("foo.query"), WatchTopic("foo.watch")).(*natsRegistry)
+	if r.queryTopic != "foo.query" {
+		t.Fatalf("Expected query topic foo.query got %s", r.queryTopic)
+	}
+	if r.watchTopic != "foo.watch" {
+		t.Fatalf("Expected watch topic foo.watch got %s", r.watchTopic)
+	}
+}
+
+func TestAddResponse(t *testing.T) {
+	versions := make(map[string]*registry.Service)
+	nodes := make(map[string]bool)
+
+	node := func(id string) *registry.Node { return &registry.Node{Id: id} }
+	responses := []*message{
+		{Domain: "micro", Service: &registry.Service{Name: "foo", Version: "1", Nodes: []*registry.Node{node("1")}}},
+		// the same node answering twice
+		{Domain: "micro", Service: &registry.Service{Name: "foo", Version: "1", Nodes: []*registry.Node{node("1")}}},
+		{Domain: "micro", Service: &registry.Service{Name: "foo", Version: "1", Nodes: []*registry.Node{node("2")}}},
+		{Domain: "other", Service: &registry.Service{Name: "foo", Version: "1", Nodes: []*registry.Node{node("1")}}},
+	}
+	for _, r := range responses {
+		addResponse(versions, nodes, r)
+	}
+
+	if len(nodes) != 3 {
+		t.Fatalf("Expected 3 distinct nodes, got %d", len(nodes))
+	}
+	if n := len(versions["micro/foo/1"].Nodes); n != 2 {
+		t.Fatalf("Expected 2 nodes in the micro domain, got %d", n)
+	}
+	if n := len(versions["other/foo/1"].Nodes); n != 1 {
+		t.Fatalf("Expected 1 node in the other domain, got %d", n)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	r := NewRegistry().(*natsRegistry)
+
+	srv := &registry.Service{Name: "foo", Version: "1", Nodes: []*registry.Node{{Id: "1"}, {Id: "2"}}}
+	r.services["micro"] = map[string][]*registry.Service{"foo": {srv}}
+
+	now := time.Now()
+	r.expiry[nodeKey("micro", srv, srv.Nodes[0])] = now.Add(time.Second)
+	r.expiry[nodeKey("micro", srv, srv.Nodes[1])] = now.Add(time.Minute)
+
+	if expired := r.expire(now); len(expired) != 0 {
+		t.Fatalf("Expected no node expired, got %d", len(expired))
+	}
+
+	expired := r.expire(now.Add(2 * time.Second))
+	if len(expired) != 1 || len(expired[0].Service.Nodes) != 1 || expired[0].Service.Nodes[0].Id != "1" {
+		t.Fatalf("Expected node 1 expired, got %+v", expired)
+	}
+	if nodes := r.services["micro"]["foo"][0].Nodes; len(nodes) != 1 || nodes[0].Id != "2" {
+		t.Fatalf("Expected node 2 left, got %+v", nodes)
+	}
+
+	r.expire(now.Add(2 * time.Minute))
+	if len(r.services) != 0 || len(r.expiry) != 0 {
+		t.Fatalf("Expected the service removed once all its nodes expired, got %+v", r.services)
+	}
+}
@@ -0,0 +1,54 @@
+package nats
+
+import (
+	"encoding/json"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/nats-io/nats.go"
+)
+
+type natsWatcher struct {
+	sub  *nats.Subscription
+	ch   chan *nats.Msg
+	exit chan bool
+	wo   registry.WatchOptions
+}
+
+func (n *natsWatcher) Next() (*registry.Result, error) {
+	for {
+		var m *nats.Msg
+		select {
+		case m = <-n.ch:
+		case <-n.exit:
+			return nil, registry.ErrWatcherStopped
+		}
+
+		var msg message
+		if err := json.Unmarshal(m.Data, &msg); err != nil || msg.Service == nil {
+			continue
+		}
+
+		if n.wo.Domain != registry.WildcardDomain && n.wo.Domain != msg.Domain {
+			continue
+		}
+
+		if len(n.wo.Service) > 0 && msg.Service.Name != n.wo.Service {
+			continue
+		}
+
+		return &registry.Result{
+			Action:  msg.Action,
+			Service: msg.Service,
+		}, nil
+	}
+}
+
+func (n *natsWatcher) Stop() {
+	select {
+	case <-n.exit:
+		return
+	default:
+		close(n.exit)
+		n.sub.Unsubscribe()
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/tls"
 	"time"
+
+	"github.com/micro/go-micro/v2/auth/credentials"
 )
 
 type Options struct {
@@ -11,6 +13,10 @@ type Options struct {
 	Timeout   time.Duration
 	Secure    bool
 	TLSConfig *tls.Config
+	// Authorizer checks writes to a domain are allowed
+	Authorizer Authorizer
+	// Credentials used to connect to the backend
+	Credentials credentials.Provider
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -23,6 +29,8 @@ type RegisterOptions struct {
 	Context context.Context
 	// Domain to register the service in
 	Domain string
+	// AuthToken used to authorize the write to the domain
+	AuthToken string
 }
 
 type WatchOptions struct {
@@ -40,6 +48,8 @@ type DeregisterOptions struct {
 	Context context.Context
 	// Domain the service was registered in
 	Domain string
+	// AuthToken used to authorize the write to the domain
+	AuthToken string
 }
 
 type GetOptions struct {
@@ -54,6 +64,14 @@ type ListOptions struct {
 	Domain string
 }
 
+// Credentials sets the provider of the credentials used to connect to the
+// backend, they are renewed before they expire
+func Credentials(p credentials.Provider) Option {
+	return func(o *Options) {
+		o.Credentials = p
+	}
+}
+
 // Addrs is the registry addresses to use
 func Addrs(addrs ...string) Option {
 	return func(o *Options) {
@@ -81,6 +99,13 @@ func TLSConfig(t *tls.Config) Option {
 	}
 }
 
+// WithAuthorizer sets the authorizer used to check writes to a domain
+func WithAuthorizer(a Authorizer) Option {
+	return func(o *Options) {
+		o.Authorizer = a
+	}
+}
+
 func RegisterTTL(t time.Duration) RegisterOption {
 	return func(o *RegisterOptions) {
 		o.TTL = t
@@ -99,6 +124,13 @@ func RegisterDomain(d string) RegisterOption {
 	}
 }
 
+// RegisterAuthToken sets the token used to authorize the registration
+func RegisterAuthToken(t string) RegisterOption {
+	return func(o *RegisterOptions) {
+		o.AuthToken = t
+	}
+}
+
 // Watch a service
 func WatchService(name string) WatchOption {
 	return func(o *WatchOptions) {
@@ -130,6 +162,13 @@ func DeregisterDomain(d string) DeregisterOption {
 	}
 }
 
+// DeregisterAuthToken sets the token used to authorize the deregistration
+func DeregisterAuthToken(t string) DeregisterOption {
+	return func(o *DeregisterOptions) {
+		o.AuthToken = t
+	}
+}
+
 func GetContext(ctx context.Context) GetOption {
 	return func(o *GetOptions) {
 		o.Context = ctx
@@ -3,6 +3,8 @@ package registry
 
 import (
 	"errors"
+
+	"github.com/micro/go-micro/v2/logger"
 )
 
 const (
@@ -19,6 +21,11 @@ var (
 	ErrNotFound = errors.New("service not found")
 	// Watcher stopped error when watcher is stopped
 	ErrWatcherStopped = errors.New("watcher stopped")
+	// Forbidden error when a write to a domain is not authorized
+	ErrForbidden = errors.New("forbidden")
+
+	// log of the registry module
+	log = logger.NewModule("registry")
 )
 
 // The registry provides an interface for service discovery
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/registry"
+	util "github.com/micro/go-micro/v2/util/registry"
+)
+
+// cache holds the results of GetService so repeated lookups don't each become
+// an RPC. Entries are dropped when a watch event is seen for the service and
+// otherwise expire after the ttl.
+type cache struct {
+	ttl time.Duration
+
+	sync.RWMutex
+	// entries grouped by domain then service name
+	entries map[string]map[string]*cacheEntry
+	// the domains being watched for changes
+	watching map[string]bool
+}
+
+type cacheEntry struct {
+	services []*registry.Service
+	expiry   time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:      ttl,
+		entries:  make(map[string]map[string]*cacheEntry),
+		watching: make(map[string]bool),
+	}
+}
+
+func (c *cache) get(domain, name string) ([]*registry.Service, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	e, ok := c.entries[domain][name]
+	if !ok || time.Now().After(e.expiry) {
+		return nil, false
+	}
+	return util.Copy(e.services), true
+}
+
+func (c *cache) set(domain, name string, services []*registry.Service) {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.entries[domain]; !ok {
+		c.entries[domain] = make(map[string]*cacheEntry)
+	}
+	c.entries[domain][name] = &cacheEntry{
+		services: util.Copy(services),
+		expiry:   time.Now().Add(c.ttl),
+	}
+}
+
+func (c *cache) del(domain, name string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.entries[domain], name)
+}
+
+// reset drops every entry in the domain
+func (c *cache) reset(domain string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.entries, domain)
+}
+
+// watch starts invalidating the domain's entries from the watcher unless it's
+// already being watched
+func (c *cache) watch(domain string, fn func() (registry.Watcher, error)) {
+	c.Lock()
+	if c.watching[domain] {
+		c.Unlock()
+		return
+	}
+	c.watching[domain] = true
+	c.Unlock()
+
+	go func() {
+		defer func() {
+			// entries can't be trusted without the watch
+			c.Lock()
+			delete(c.watching, domain)
+			delete(c.entries, domain)
+			c.Unlock()
+		}()
+
+		w, err := fn()
+		if err != nil {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Registry cache failed to watch domain %s: %v", domain, err)
+			}
+			return
+		}
+		defer w.Stop()
+
+		for {
+			res, err := w.Next()
+			if err != nil {
+				return
+			}
+			if res.Service == nil {
+				c.reset(domain)
+				continue
+			}
+			c.del(domain, res.Service.Name)
+		}
+	}()
+}
@@ -0,0 +1,144 @@
+// Package handler implements the registry handler served by registry services
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+	service "github.com/micro/go-micro/v2/registry/service"
+	pb "github.com/micro/go-micro/v2/registry/service/proto"
+)
+
+// NewRegistry returns a handler which serves the given registry. Writes are
+// authorized with the token sent by the caller using the authorizer of the
+// registry.
+func NewRegistry(r registry.Registry) *Registry {
+	return &Registry{registry: r}
+}
+
+type Registry struct {
+	// must honour the registry handler
+	pb.RegistryHandler
+	// the served registry
+	registry registry.Registry
+}
+
+// authorize checks the token the caller sent may write to the domain
+func (r *Registry) authorize(ctx context.Context, domain string) (string, error) {
+	token, _ := metadata.Get(ctx, service.TokenHeader)
+	if err := registry.Authorize(r.registry.Options(), token, domain); err != nil {
+		return "", errors.Forbidden("go.micro.registry", "not authorized to write to domain %q", domain)
+	}
+	return token, nil
+}
+
+func domain(o *pb.Options) string {
+	if o == nil || len(o.Domain) == 0 {
+		return registry.DefaultDomain
+	}
+	return o.Domain
+}
+
+func (r *Registry) GetService(ctx context.Context, req *pb.GetRequest, rsp *pb.GetResponse) error {
+	services, err := r.registry.GetService(req.Service, registry.GetDomain(domain(req.Options)))
+	if err == registry.ErrNotFound {
+		return errors.NotFound("go.micro.registry", err.Error())
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.registry", "failed to get service: %s", err)
+	}
+
+	rsp.Services = make([]*pb.Service, 0, len(services))
+	for _, srv := range services {
+		rsp.Services = append(rsp.Services, service.ToProto(srv))
+	}
+
+	return nil
+}
+
+func (r *Registry) Register(ctx context.Context, req *pb.Service, rsp *pb.EmptyResponse) error {
+	dom := domain(req.Options)
+	token, err := r.authorize(ctx, dom)
+	if err != nil {
+		return err
+	}
+
+	opts := []registry.RegisterOption{
+		registry.RegisterDomain(dom),
+		registry.RegisterAuthToken(token),
+	}
+	if req.Options != nil && req.Options.Ttl > 0 {
+		opts = append(opts, registry.RegisterTTL(time.Duration(req.Options.Ttl)*time.Second))
+	}
+
+	if err := r.registry.Register(service.ToService(req), opts...); err == registry.ErrForbidden {
+		return errors.Forbidden("go.micro.registry", "not authorized to write to domain %q", dom)
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.registry", "failed to register service: %s", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) Deregister(ctx context.Context, req *pb.Service, rsp *pb.EmptyResponse) error {
+	dom := domain(req.Options)
+	token, err := r.authorize(ctx, dom)
+	if err != nil {
+		return err
+	}
+
+	opts := []registry.DeregisterOption{
+		registry.DeregisterDomain(dom),
+		registry.DeregisterAuthToken(token),
+	}
+
+	if err := r.registry.Deregister(service.ToService(req), opts...); err == registry.ErrForbidden {
+		return errors.Forbidden("go.micro.registry", "not authorized to write to domain %q", dom)
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.registry", "failed to deregister service: %s", err)
+	}
+
+	return nil
+}
+
+func (r *Registry) ListServices(ctx context.Context, req *pb.ListRequest, rsp *pb.ListResponse) error {
+	services, err := r.registry.ListServices(registry.ListDomain(domain(req.Options)))
+	if err != nil {
+		return errors.InternalServerError("go.micro.registry", "failed to list services: %s", err)
+	}
+
+	rsp.Services = make([]*pb.Service, 0, len(services))
+	for _, srv := range services {
+		rsp.Services = append(rsp.Services, service.ToProto(srv))
+	}
+
+	return nil
+}
+
+func (r *Registry) Watch(ctx context.Context, req *pb.WatchRequest, stream pb.Registry_WatchStream) error {
+	watcher, err := r.registry.Watch(
+		registry.WatchService(req.Service),
+		registry.WatchDomain(domain(req.Options)),
+	)
+	if err != nil {
+		return errors.InternalServerError("go.micro.registry", "failed to watch: %s", err)
+	}
+	defer watcher.Stop()
+
+	for {
+		res, err := watcher.Next()
+		if err != nil {
+			return errors.InternalServerError("go.micro.registry", "watch error: %s", err)
+		}
+
+		if err := stream.Send(&pb.Result{
+			Action:    res.Action,
+			Service:   service.ToProto(res.Service),
+			Timestamp: time.Now().Unix(),
+		}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+	service "github.com/micro/go-micro/v2/registry/service"
+	pb "github.com/micro/go-micro/v2/registry/service/proto"
+)
+
+func TestRegistryAuthorize(t *testing.T) {
+	r := memory.NewRegistry(registry.WithAuthorizer(registry.DomainTokens(map[string][]string{
+		"foo": {"secret"},
+	})))
+	h := NewRegistry(r)
+
+	srv := &pb.Service{
+		Name:    "test",
+		Version: "1",
+		Nodes:   []*pb.Node{{Id: "test-1", Address: "10.0.0.1:8080"}},
+		Options: &pb.Options{Domain: "foo"},
+	}
+
+	testData := []struct {
+		name  string
+		token string
+		code  int32
+	}{
+		{"no token", "", 403},
+		{"wrong token", "wrong", 403},
+		{"token", "secret", 0},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(d.token) > 0 {
+				ctx = metadata.Set(ctx, service.TokenHeader, d.token)
+			}
+
+			err := h.Register(ctx, srv, &pb.EmptyResponse{})
+			if d.code == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected register error %v", err)
+				}
+				if err := h.Deregister(ctx, srv, &pb.EmptyResponse{}); err != nil {
+					t.Fatalf("Unexpected deregister error %v", err)
+				}
+				return
+			}
+
+			if merr, ok := err.(*errors.Error); !ok || merr.Code != d.code {
+				t.Fatalf("Expected register error code %d, got %v", d.code, err)
+			}
+			if err := h.Deregister(ctx, srv, &pb.EmptyResponse{}); err == nil {
+				t.Fatal("Expected deregister to be forbidden")
+			}
+			if _, err := r.GetService("test", registry.GetDomain("foo")); err != registry.ErrNotFound {
+				t.Fatalf("Expected the service not to be registered, got %v", err)
+			}
+		})
+	}
+
+	// domains which aren't listed are open to any token
+	open := &pb.Service{Name: "test", Version: "1", Options: &pb.Options{Domain: "bar"}}
+	if err := h.Register(context.Background(), open, &pb.EmptyResponse{}); err != nil {
+		t.Fatalf("Unexpected register error %v", err)
+	}
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/registry"
 )
 
 type clientKey struct{}
+type cacheTTLKey struct{}
+type registerBatchKey struct{}
 
 // WithClient sets the RPC client
 func WithClient(c client.Client) registry.Option {
@@ -19,3 +22,31 @@ func WithClient(c client.Client) registry.Option {
 		o.Context = context.WithValue(o.Context, clientKey{}, c)
 	}
 }
+
+// CacheTTL enables the cache of service lookups, caching them for up to the
+// ttl. Cached lookups are dropped as soon as a watch event is seen for the
+// service so the ttl only bounds how stale they can get. Lookups aren't
+// cached by default.
+func CacheTTL(t time.Duration) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+
+		o.Context = context.WithValue(o.Context, cacheTTLKey{}, t)
+	}
+}
+
+// RegisterBatch queues registrations for up to the window and sends them
+// together, a registration replacing any queued for the same nodes. Register
+// returns once the batch was sent, with a BatchError aggregating the errors
+// of the services of the batch which failed to register.
+func RegisterBatch(window time.Duration) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+
+		o.Context = context.WithValue(o.Context, registerBatchKey{}, window)
+	}
+}
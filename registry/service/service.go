@@ -3,11 +3,17 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/client/grpc"
 	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/registry"
 	pb "github.com/micro/go-micro/v2/registry/service/proto"
 )
@@ -15,6 +21,11 @@ import (
 var (
 	// The default service name
 	DefaultService = "go.micro.registry"
+	// TokenHeader is the metadata header the auth token of a write is sent in
+	TokenHeader = "Micro-Registry-Token"
+
+	// log of the registry module
+	log = logger.NewModule("registry")
 )
 
 type serviceRegistry struct {
@@ -25,6 +36,54 @@ type serviceRegistry struct {
 	address []string
 	// client to call registry
 	client pb.RegistryService
+	// cache of service lookups, nil if disabled
+	cache *cache
+
+	// registrations waiting to be sent
+	sync.Mutex
+	batch *batch
+}
+
+// batch is the registrations waiting to be sent together
+type batch struct {
+	services map[string]*batched
+	// done is closed once the batch was sent, err is then set
+	done chan struct{}
+	err  error
+}
+
+// batched is a registration waiting to be sent and the token authorizing it
+type batched struct {
+	srv   *pb.Service
+	token string
+}
+
+// BatchError is the error of a batch of registrations, the errors of the
+// services which failed to register keyed by their name and version
+type BatchError struct {
+	Errors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for k := range e.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		msgs = append(msgs, k+": "+e.Errors[k].Error())
+	}
+	return fmt.Sprintf("failed to register %d services: %s", len(keys), strings.Join(msgs, "; "))
+}
+
+// withToken returns the context with the auth token of a write set in its metadata
+func withToken(ctx context.Context, token string) context.Context {
+	if len(token) == 0 {
+		return ctx
+	}
+	return metadata.Set(ctx, TokenHeader, token)
 }
 
 func (s *serviceRegistry) callOpts() []client.CallOption {
@@ -61,10 +120,70 @@ func (s *serviceRegistry) Init(opts ...registry.Option) error {
 	}
 
 	s.client = pb.NewRegistryService(DefaultService, cli)
+	s.cache = cacheFromOptions(s.opts)
 
 	return nil
 }
 
+// cacheFromOptions returns the lookup cache, nil unless enabled with CacheTTL
+func cacheFromOptions(opts registry.Options) *cache {
+	ttl, ok := opts.Context.Value(cacheTTLKey{}).(time.Duration)
+	if !ok || ttl <= 0 {
+		return nil
+	}
+	return newCache(ttl)
+}
+
+func batchKey(srv *pb.Service) string {
+	ids := make([]string, 0, len(srv.Nodes))
+	for _, n := range srv.Nodes {
+		ids = append(ids, n.Id)
+	}
+	return srv.Options.Domain + "/" + srv.Name + "/" + srv.Version + "/" + strings.Join(ids, ",")
+}
+
+// queue adds the registration to the batch, replacing any earlier one of the
+// same nodes, and schedules the batch to be sent when it is first created.
+// It returns the batch the registration is sent in.
+func (s *serviceRegistry) queue(srv *pb.Service, token string, window time.Duration) *batch {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.batch == nil {
+		s.batch = &batch{
+			services: make(map[string]*batched),
+			done:     make(chan struct{}),
+		}
+		time.AfterFunc(window, s.flush)
+	}
+	s.batch.services[batchKey(srv)] = &batched{srv: srv, token: token}
+	return s.batch
+}
+
+// flush sends the queued registrations, aggregating the errors of the batch
+func (s *serviceRegistry) flush() {
+	s.Lock()
+	b := s.batch
+	s.batch = nil
+	s.Unlock()
+
+	if b == nil {
+		return
+	}
+
+	errs := make(map[string]error)
+	for _, r := range b.services {
+		ctx := withToken(context.TODO(), r.token)
+		if _, err := s.client.Register(ctx, r.srv, s.callOpts()...); err != nil {
+			errs[r.srv.Name+"@"+r.srv.Version] = err
+		}
+	}
+	if len(errs) > 0 {
+		b.err = &BatchError{Errors: errs}
+	}
+	close(b.done)
+}
+
 func (s *serviceRegistry) Options() registry.Options {
 	return s.opts
 }
@@ -83,8 +202,20 @@ func (s *serviceRegistry) Register(srv *registry.Service, opts ...registry.Regis
 	pbSrv.Options.Ttl = int64(options.TTL.Seconds())
 	pbSrv.Options.Domain = options.Domain
 
-	// register the service
-	_, err := s.client.Register(options.Context, pbSrv, s.callOpts()...)
+	// queue the registration if batching
+	if w, ok := s.opts.Context.Value(registerBatchKey{}).(time.Duration); ok && w > 0 {
+		b := s.queue(pbSrv, options.AuthToken, w)
+		select {
+		case <-b.done:
+			return b.err
+		case <-options.Context.Done():
+			return options.Context.Err()
+		}
+	}
+
+	// register the service, the handler authorizes the token
+	ctx := withToken(options.Context, options.AuthToken)
+	_, err := s.client.Register(ctx, pbSrv, s.callOpts()...)
 	return err
 }
 
@@ -101,8 +232,16 @@ func (s *serviceRegistry) Deregister(srv *registry.Service, opts ...registry.Der
 	pbSrv := ToProto(srv)
 	pbSrv.Options.Domain = options.Domain
 
-	// deregister the service
-	_, err := s.client.Deregister(options.Context, pbSrv, s.callOpts()...)
+	// drop any queued registration so it isn't sent after the deregister
+	s.Lock()
+	if s.batch != nil {
+		delete(s.batch.services, batchKey(pbSrv))
+	}
+	s.Unlock()
+
+	// deregister the service, the handler authorizes the token
+	ctx := withToken(options.Context, options.AuthToken)
+	_, err := s.client.Deregister(ctx, pbSrv, s.callOpts()...)
 	return err
 }
 
@@ -115,6 +254,17 @@ func (s *serviceRegistry) GetService(name string, opts ...registry.GetOption) ([
 		options.Context = context.TODO()
 	}
 
+	// wildcard lookups span domains so aren't cached
+	cached := s.cache != nil && options.Domain != registry.WildcardDomain
+	if cached {
+		if services, ok := s.cache.get(options.Domain, name); ok {
+			return services, nil
+		}
+		s.cache.watch(options.Domain, func() (registry.Watcher, error) {
+			return s.Watch(registry.WatchDomain(options.Domain))
+		})
+	}
+
 	rsp, err := s.client.GetService(options.Context, &pb.GetRequest{
 		Service: name, Options: &pb.Options{Domain: options.Domain},
 	}, s.callOpts()...)
@@ -129,6 +279,11 @@ func (s *serviceRegistry) GetService(name string, opts ...registry.GetOption) ([
 	for _, service := range rsp.Services {
 		services = append(services, ToService(service))
 	}
+
+	if cached {
+		s.cache.set(options.Domain, name, services)
+	}
+
 	return services, nil
 }
 
@@ -164,15 +319,42 @@ func (s *serviceRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher,
 		options.Context = context.TODO()
 	}
 
-	stream, err := s.client.Watch(options.Context, &pb.WatchRequest{
-		Service: options.Service, Options: &pb.Options{Domain: options.Domain},
-	}, s.callOpts()...)
+	open := func() (pb.Registry_WatchService, error) {
+		return s.client.Watch(options.Context, &pb.WatchRequest{
+			Service: options.Service, Options: &pb.Options{Domain: options.Domain},
+		}, s.callOpts()...)
+	}
 
+	stream, err := open()
 	if err != nil {
 		return nil, err
 	}
 
-	return newWatcher(stream), nil
+	w := newWatcher(stream).(*serviceWatcher)
+	w.open = open
+	w.list = func() ([]*registry.Service, error) {
+		if len(options.Service) == 0 {
+			return s.ListServices(registry.ListContext(options.Context), registry.ListDomain(options.Domain))
+		}
+
+		// bypass the cache, it's the cache being resynced
+		rsp, err := s.client.GetService(options.Context, &pb.GetRequest{
+			Service: options.Service, Options: &pb.Options{Domain: options.Domain},
+		}, s.callOpts()...)
+		if verr, ok := err.(*errors.Error); ok && verr.Code == 404 {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		services := make([]*registry.Service, 0, len(rsp.Services))
+		for _, service := range rsp.Services {
+			services = append(services, ToService(service))
+		}
+		return services, nil
+	}
+
+	return w, nil
 }
 
 func (s *serviceRegistry) String() string {
@@ -212,5 +394,6 @@ func NewRegistry(opts ...registry.Option) registry.Registry {
 		name:    name,
 		address: addrs,
 		client:  pb.NewRegistryService(name, cli),
+		cache:   cacheFromOptions(options),
 	}
 }
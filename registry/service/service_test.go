@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+	pb "github.com/micro/go-micro/v2/registry/service/proto"
+)
+
+// testClient fails the registrations of the services named in fail
+type testClient struct {
+	pb.RegistryService
+
+	sync.Mutex
+	fail   map[string]bool
+	tokens []string
+}
+
+func (t *testClient) Register(ctx context.Context, in *pb.Service, opts ...client.CallOption) (*pb.EmptyResponse, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	token, _ := metadata.Get(ctx, TokenHeader)
+	t.tokens = append(t.tokens, token)
+
+	if t.fail[in.Name] {
+		return nil, errors.New("unavailable")
+	}
+	return &pb.EmptyResponse{}, nil
+}
+
+func TestRegisterBatch(t *testing.T) {
+	c := &testClient{fail: map[string]bool{"bar": true}}
+	r := NewRegistry(RegisterBatch(time.Millisecond * 10)).(*serviceRegistry)
+	r.client = c
+
+	errs := make(chan error, 2)
+	for _, name := range []string{"foo", "bar"} {
+		srv := &registry.Service{Name: name, Version: "1"}
+		go func() {
+			errs <- r.Register(srv, registry.RegisterAuthToken("secret"))
+		}()
+	}
+
+	for i := 0; i < 2; i++ {
+		err := <-errs
+		berr, ok := err.(*BatchError)
+		if !ok {
+			t.Fatalf("Expected a batch error, got %v", err)
+		}
+		if len(berr.Errors) != 1 || berr.Errors["bar@1"] == nil {
+			t.Fatalf("Expected the error of bar, got %v", berr.Errors)
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	for _, token := range c.tokens {
+		if token != "secret" {
+			t.Fatalf("Expected the token to be sent, got %q", token)
+		}
+	}
+}
+
+func TestCacheOptIn(t *testing.T) {
+	if r := NewRegistry().(*serviceRegistry); r.cache != nil {
+		t.Fatal("Expected lookups not to be cached by default")
+	}
+	if r := NewRegistry(CacheTTL(time.Minute)).(*serviceRegistry); r.cache == nil {
+		t.Fatal("Expected lookups to be cached")
+	}
+}
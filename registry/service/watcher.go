@@ -1,35 +1,176 @@
 package service
 
 import (
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
 	"github.com/micro/go-micro/v2/registry"
 	pb "github.com/micro/go-micro/v2/registry/service/proto"
+	"github.com/micro/go-micro/v2/util/backoff"
+	util "github.com/micro/go-micro/v2/util/registry"
 )
 
 type serviceWatcher struct {
+	// guards the stream so a reconnect can't swap it while stopping
+	sync.Mutex
 	stream pb.Registry_WatchService
 	closed chan bool
+
+	// open creates a new watch stream, used to reconnect
+	open func() (pb.Registry_WatchService, error)
+	// list returns the current services, used to resync after reconnecting
+	list func() ([]*registry.Service, error)
+
+	// services seen on the stream, keyed by domain and name
+	known map[string][]*registry.Service
+	// results generated by a resync waiting to be returned
+	pending []*registry.Result
 }
 
-func (s *serviceWatcher) Next() (*registry.Result, error) {
-	// check if closed
-	select {
-	case <-s.closed:
-		return nil, registry.ErrWatcherStopped
-	default:
+func serviceKey(s *registry.Service) string {
+	if s.Metadata == nil {
+		return s.Name
 	}
+	return s.Metadata["domain"] + "/" + s.Name
+}
 
-	r, err := s.stream.Recv()
-	if err != nil {
-		return nil, err
+// track keeps a record of the services seen so a resync can work out what was
+// deleted while the stream was down
+func (s *serviceWatcher) track(r *registry.Result) {
+	if r.Service == nil {
+		return
 	}
+	key := serviceKey(r.Service)
+	srvs := []*registry.Service{r.Service}
 
-	return &registry.Result{
-		Action:  r.Action,
-		Service: ToService(r.Service),
-	}, nil
+	switch r.Action {
+	case "create", "update":
+		if old, ok := s.known[key]; ok {
+			s.known[key] = util.Merge(old, srvs)
+		} else {
+			s.known[key] = util.Copy(srvs)
+		}
+	case "delete":
+		if rem := util.Remove(s.known[key], srvs); len(rem) > 0 {
+			s.known[key] = rem
+		} else {
+			delete(s.known, key)
+		}
+	}
+}
+
+// reconnect re-establishes the stream, backing off between attempts, then
+// queues the results needed to bring the consumer up to date
+func (s *serviceWatcher) reconnect() error {
+	for i := 0; ; i++ {
+		select {
+		case <-s.closed:
+			return registry.ErrWatcherStopped
+		case <-time.After(backoff.Do(i)):
+		}
+
+		stream, err := s.open()
+		if err != nil {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Registry watch reconnect failed: %v", err)
+			}
+			continue
+		}
+
+		current, err := s.list()
+		if err != nil {
+			stream.Close()
+			continue
+		}
+
+		s.Lock()
+		select {
+		case <-s.closed:
+			s.Unlock()
+			stream.Close()
+			return registry.ErrWatcherStopped
+		default:
+		}
+		s.stream.Close()
+		s.stream = stream
+		s.Unlock()
+
+		s.resync(current)
+		return nil
+	}
+}
+
+// resync queues an update for every current service and a delete for any
+// nodes seen before the stream was lost which no longer exist
+func (s *serviceWatcher) resync(current []*registry.Service) {
+	byKey := make(map[string][]*registry.Service)
+	for _, srv := range current {
+		key := serviceKey(srv)
+		byKey[key] = append(byKey[key], srv)
+	}
+
+	for key, old := range s.known {
+		stale := util.Remove(old, byKey[key])
+		for _, srv := range stale {
+			s.pending = append(s.pending, &registry.Result{Action: "delete", Service: srv})
+		}
+	}
+
+	for _, srv := range current {
+		s.pending = append(s.pending, &registry.Result{Action: "update", Service: srv})
+	}
+
+	s.known = make(map[string][]*registry.Service)
+	for _, r := range s.pending {
+		s.track(r)
+	}
+}
+
+func (s *serviceWatcher) Next() (*registry.Result, error) {
+	for {
+		// check if closed
+		select {
+		case <-s.closed:
+			return nil, registry.ErrWatcherStopped
+		default:
+		}
+
+		if len(s.pending) > 0 {
+			r := s.pending[0]
+			s.pending = s.pending[1:]
+			return r, nil
+		}
+
+		s.Lock()
+		stream := s.stream
+		s.Unlock()
+
+		r, err := stream.Recv()
+		if err != nil {
+			if s.open == nil {
+				return nil, err
+			}
+			if err := s.reconnect(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		res := &registry.Result{
+			Action:  r.Action,
+			Service: ToService(r.Service),
+		}
+		s.track(res)
+
+		return res, nil
+	}
 }
 
 func (s *serviceWatcher) Stop() {
+	s.Lock()
+	defer s.Unlock()
+
 	select {
 	case <-s.closed:
 		return
@@ -43,5 +184,6 @@ func newWatcher(stream pb.Registry_WatchService) registry.Watcher {
 	return &serviceWatcher{
 		stream: stream,
 		closed: make(chan bool),
+		known:  make(map[string][]*registry.Service),
 	}
 }
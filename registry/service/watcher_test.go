@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/micro/go-micro/v2/registry"
+	pb "github.com/micro/go-micro/v2/registry/service/proto"
+)
+
+type testStream struct {
+	results []*pb.Result
+}
+
+func (t *testStream) Context() context.Context  { return context.TODO() }
+func (t *testStream) SendMsg(interface{}) error { return nil }
+func (t *testStream) RecvMsg(interface{}) error { return nil }
+func (t *testStream) Close() error              { return nil }
+func (t *testStream) Recv() (*pb.Result, error) {
+	if len(t.results) == 0 {
+		return nil, errors.New("stream closed")
+	}
+	r := t.results[0]
+	t.results = t.results[1:]
+	return r, nil
+}
+
+func testService(nodes ...string) *registry.Service {
+	s := &registry.Service{Name: "foo", Version: "1.0.0", Metadata: map[string]string{"domain": "test"}}
+	for _, n := range nodes {
+		s.Nodes = append(s.Nodes, &registry.Node{Id: n, Address: n})
+	}
+	return s
+}
+
+func TestWatcherResync(t *testing.T) {
+	first := &testStream{results: []*pb.Result{
+		{Action: "create", Service: ToProto(testService("a"))},
+		{Action: "update", Service: ToProto(testService("b"))},
+	}}
+	second := &testStream{results: []*pb.Result{
+		{Action: "update", Service: ToProto(testService("c"))},
+	}}
+
+	w := newWatcher(first).(*serviceWatcher)
+	w.open = func() (pb.Registry_WatchService, error) { return second, nil }
+	// node a was deregistered while the stream was down
+	w.list = func() ([]*registry.Service, error) {
+		return []*registry.Service{testService("b")}, nil
+	}
+
+	expect := []struct {
+		action string
+		nodes  []string
+	}{
+		{"create", []string{"a"}},
+		{"update", []string{"b"}},
+		{"delete", []string{"a"}},
+		{"update", []string{"b"}},
+		{"update", []string{"c"}},
+	}
+
+	for i, e := range expect {
+		r, err := w.Next()
+		if err != nil {
+			t.Fatalf("Result %d: unexpected error %v", i, err)
+		}
+		if r.Action != e.action {
+			t.Fatalf("Result %d: expected action %s got %s", i, e.action, r.Action)
+		}
+		if len(r.Service.Nodes) != len(e.nodes) {
+			t.Fatalf("Result %d: expected %d nodes got %d", i, len(e.nodes), len(r.Service.Nodes))
+		}
+		for j, n := range e.nodes {
+			if r.Service.Nodes[j].Id != n {
+				t.Fatalf("Result %d: expected node %s got %s", i, n, r.Service.Nodes[j].Id)
+			}
+		}
+	}
+
+	w.Stop()
+	if _, err := w.Next(); err != registry.ErrWatcherStopped {
+		t.Fatalf("Expected watcher stopped got %v", err)
+	}
+}
+
+func TestWatcherStopReconnect(t *testing.T) {
+	w := newWatcher(&testStream{}).(*serviceWatcher)
+	w.open = func() (pb.Registry_WatchService, error) { return &testStream{}, nil }
+	w.list = func() ([]*registry.Service, error) { return nil, nil }
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := w.Next()
+		errs <- err
+	}()
+
+	w.Stop()
+	if err := <-errs; err != registry.ErrWatcherStopped {
+		t.Fatalf("Expected %v, got %v", registry.ErrWatcherStopped, err)
+	}
+}
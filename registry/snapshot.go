@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Snapshotter is implemented by registries which can export and import
+// the services in a domain natively rather than one service at a time
+type Snapshotter interface {
+	// Snapshot returns all the services in a domain including their nodes
+	Snapshot(domain string) ([]*Service, error)
+	// Restore registers the services in a domain
+	Restore(domain string, services []*Service, opts ...RegisterOption) error
+}
+
+// snapshot is the portable document produced by Snapshot
+type snapshot struct {
+	Domain   string     `json:"domain"`
+	Created  time.Time  `json:"created"`
+	Services []*Service `json:"services"`
+}
+
+// Snapshot dumps all the services in a domain to a portable JSON document which
+// can be restored into any registry, e.g. when migrating between backends
+func Snapshot(r Registry, domain string) ([]byte, error) {
+	if len(domain) == 0 {
+		domain = DefaultDomain
+	}
+
+	var services []*Service
+	var err error
+
+	if s, ok := r.(Snapshotter); ok {
+		services, err = s.Snapshot(domain)
+	} else {
+		services, err = snapshotServices(r, domain)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&snapshot{
+		Domain:   domain,
+		Created:  time.Now(),
+		Services: services,
+	})
+}
+
+// snapshotServices lists the services in a domain and looks each of them up
+// since not every registry returns the nodes when listing
+func snapshotServices(r Registry, domain string) ([]*Service, error) {
+	list, err := r.ListServices(ListDomain(domain))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var services []*Service
+
+	for _, s := range list {
+		if seen[s.Name] {
+			continue
+		}
+		seen[s.Name] = true
+
+		srvs, err := r.GetService(s.Name, GetDomain(domain))
+		if err == ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		services = append(services, srvs...)
+	}
+
+	return services, nil
+}
+
+// Restore registers the services in a snapshot with the registry. Services are
+// registered in the domain they were exported from unless a RegisterDomain option
+// is passed. Snapshots of the wildcard domain restore each service into the domain
+// recorded in its metadata.
+func Restore(r Registry, b []byte, opts ...RegisterOption) error {
+	var snap snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return err
+	}
+
+	var options RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// group the services by the domain they're restored in
+	domains := make(map[string][]*Service)
+	for _, s := range snap.Services {
+		if len(s.Nodes) == 0 {
+			continue
+		}
+
+		domain := options.Domain
+		if len(domain) == 0 {
+			domain = snap.Domain
+		}
+		if domain == WildcardDomain {
+			domain = DefaultDomain
+			if d, ok := s.Metadata["domain"]; ok && len(d) > 0 {
+				domain = d
+			}
+		}
+
+		domains[domain] = append(domains[domain], s)
+	}
+
+	for domain, services := range domains {
+		if s, ok := r.(Snapshotter); ok {
+			if err := s.Restore(domain, services, opts...); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// the domain option is appended so it takes priority
+		rOpts := append(append([]RegisterOption{}, opts...), RegisterDomain(domain))
+		for _, s := range services {
+			if err := r.Register(s, rOpts...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	src := memory.NewRegistry()
+	dst := memory.NewRegistry()
+
+	services := []*registry.Service{
+		{
+			Name:    "foo",
+			Version: "1.0.0",
+			Nodes: []*registry.Node{
+				{Id: "foo-1", Address: "10.0.0.1:8080"},
+				{Id: "foo-2", Address: "10.0.0.2:8080"},
+			},
+		},
+		{
+			Name:    "bar",
+			Version: "1.0.0",
+			Nodes:   []*registry.Node{{Id: "bar-1", Address: "10.0.0.3:8080"}},
+		},
+	}
+
+	for _, s := range services {
+		if err := src.Register(s, registry.RegisterDomain("test")); err != nil {
+			t.Fatalf("Unexpected register error: %v", err)
+		}
+	}
+
+	b, err := registry.Snapshot(src, "test")
+	if err != nil {
+		t.Fatalf("Unexpected snapshot error: %v", err)
+	}
+
+	if err := registry.Restore(dst, b); err != nil {
+		t.Fatalf("Unexpected restore error: %v", err)
+	}
+
+	srvs, err := dst.GetService("foo", registry.GetDomain("test"))
+	if err != nil {
+		t.Fatalf("Unexpected get error: %v", err)
+	}
+	if len(srvs) != 1 || len(srvs[0].Nodes) != 2 {
+		t.Fatalf("Expected 1 service with 2 nodes, got %+v", srvs)
+	}
+
+	// restore into another domain
+	if err := registry.Restore(dst, b, registry.RegisterDomain("other")); err != nil {
+		t.Fatalf("Unexpected restore error: %v", err)
+	}
+	if _, err := dst.GetService("bar", registry.GetDomain("other")); err != nil {
+		t.Fatalf("Unexpected get error: %v", err)
+	}
+}
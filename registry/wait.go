@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/util/backoff"
+)
+
+var (
+	// DefaultWaitTimeout is how long to wait for services when the context has no deadline
+	DefaultWaitTimeout = time.Minute
+	// MaxWaitBackoff is the max time to wait between service lookups
+	MaxWaitBackoff = 5 * time.Second
+)
+
+// WaitFor blocks until each of the named services has at least one node in the
+// default registry. It's used on startup to wait for the service dependencies.
+func WaitFor(ctx context.Context, services ...string) error {
+	return WaitForServices(ctx, DefaultRegistry, services...)
+}
+
+// WaitForServices blocks until each of the named services has at least one node
+// in the registry, retrying the lookups with backoff. Nodes which failed to renew
+// their registration expire from the registry so any node found is considered
+// healthy. It gives up once the context is done or, if the context has no deadline,
+// after DefaultWaitTimeout.
+func WaitForServices(ctx context.Context, r Registry, services ...string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultWaitTimeout)
+		defer cancel()
+	}
+
+	pending := services
+
+	for attempt := 1; ; attempt++ {
+		var missing []string
+		for _, name := range pending {
+			if !hasNodes(r, name) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		pending = missing
+
+		wait := backoff.Do(attempt)
+		if wait > MaxWaitBackoff {
+			wait = MaxWaitBackoff
+		}
+
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Waiting %v for services: %s", wait, strings.Join(pending, ", "))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up waiting for services %s: %v", strings.Join(pending, ", "), ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// hasNodes returns true if any version of the service has a node
+func hasNodes(r Registry, name string) bool {
+	services, err := r.GetService(name)
+	if err != nil {
+		return false
+	}
+
+	for _, s := range services {
+		if len(s.Nodes) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
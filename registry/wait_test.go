@@ -0,0 +1,46 @@
+package registry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestWaitForServices(t *testing.T) {
+	r := memory.NewRegistry()
+
+	foo := &registry.Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "10.0.0.1:8080"}},
+	}
+	if err := r.Register(foo); err != nil {
+		t.Fatalf("Unexpected register error: %v", err)
+	}
+
+	// give up when a service never comes up
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := registry.WaitForServices(ctx, r, "foo", "bar"); err == nil {
+		t.Fatal("Expected error waiting for a missing service")
+	}
+
+	// register the dependency while waiting
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		r.Register(&registry.Service{
+			Name:    "bar",
+			Version: "1.0.0",
+			Nodes:   []*registry.Node{{Id: "bar-1", Address: "10.0.0.2:8080"}},
+		})
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := registry.WaitForServices(ctx, r, "foo", "bar"); err != nil {
+		t.Fatalf("Unexpected error waiting for services: %v", err)
+	}
+}
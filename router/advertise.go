@@ -0,0 +1,163 @@
+package router
+
+import (
+	"strings"
+	"time"
+)
+
+var (
+	// DefaultAdvertBuffer is the number of adverts buffered for a subscriber
+	DefaultAdvertBuffer = 128
+)
+
+// OverflowPolicy decides what happens to an advert when a subscriber's buffer is full
+type OverflowPolicy int
+
+const (
+	// Block waits for the subscriber to make room, up to the timeout if one is set
+	Block OverflowPolicy = iota
+	// DropOldest drops the oldest buffered advert to make room
+	DropOldest
+	// DropNewest drops the advert being sent
+	DropNewest
+)
+
+// String returns human readable overflow policy
+func (p OverflowPolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop-oldest"
+	case DropNewest:
+		return "drop-newest"
+	default:
+		return "unknown"
+	}
+}
+
+// AdvertiseOption sets advert subscription options
+type AdvertiseOption func(*AdvertiseOptions)
+
+// AdvertiseOptions filter the events sent to an advert subscriber
+type AdvertiseOptions struct {
+	// Service is the prefix of the services to receive events for
+	Service string
+	// Network the routes must be in
+	Network string
+	// Link the routes must use
+	Link string
+	// Buffer is the number of adverts buffered for the subscriber
+	Buffer int
+	// Overflow is the policy applied when the buffer is full
+	Overflow OverflowPolicy
+	// Timeout is how long the Block policy waits before dropping the advert.
+	// Zero waits until the advert is received or the router stops.
+	Timeout time.Duration
+	// Evict is the number of overflows in a row after which the subscriber is
+	// evicted and its channel closed. Zero never evicts the subscriber.
+	Evict int
+}
+
+// AdvertiseService only sends events for services with the name prefix
+func AdvertiseService(prefix string) AdvertiseOption {
+	return func(o *AdvertiseOptions) {
+		o.Service = prefix
+	}
+}
+
+// AdvertiseNetwork only sends events for routes in the network
+func AdvertiseNetwork(n string) AdvertiseOption {
+	return func(o *AdvertiseOptions) {
+		o.Network = n
+	}
+}
+
+// AdvertiseLink only sends events for routes using the link
+func AdvertiseLink(l string) AdvertiseOption {
+	return func(o *AdvertiseOptions) {
+		o.Link = l
+	}
+}
+
+// AdvertiseBuffer sets the number of adverts buffered for the subscriber
+func AdvertiseBuffer(n int) AdvertiseOption {
+	return func(o *AdvertiseOptions) {
+		o.Buffer = n
+	}
+}
+
+// AdvertiseOverflow sets the policy applied when the subscriber's buffer is full
+func AdvertiseOverflow(p OverflowPolicy) AdvertiseOption {
+	return func(o *AdvertiseOptions) {
+		o.Overflow = p
+	}
+}
+
+// AdvertiseTimeout sets how long the Block policy waits before dropping an advert
+func AdvertiseTimeout(t time.Duration) AdvertiseOption {
+	return func(o *AdvertiseOptions) {
+		o.Timeout = t
+	}
+}
+
+// AdvertiseEvict evicts the subscriber after n overflows in a row, closing its channel
+func AdvertiseEvict(n int) AdvertiseOption {
+	return func(o *AdvertiseOptions) {
+		o.Evict = n
+	}
+}
+
+// NewAdvertise creates new advertise options, by default every event is sent
+// and the router blocks until the subscriber has room for the advert
+func NewAdvertise(opts ...AdvertiseOption) AdvertiseOptions {
+	options := AdvertiseOptions{
+		Network:  "*",
+		Link:     "*",
+		Buffer:   DefaultAdvertBuffer,
+		Overflow: Block,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
+
+// match checks if the route passes the filters
+func (o AdvertiseOptions) match(r Route) bool {
+	if !strings.HasPrefix(r.Service, o.Service) {
+		return false
+	}
+	if o.Network != "*" && o.Network != r.Network {
+		return false
+	}
+	if o.Link != "*" && o.Link != r.Link {
+		return false
+	}
+	return true
+}
+
+// FilterAdvert returns a copy of the advert holding only the events which pass
+// the filters. Route updates left without any events are dropped and nil is
+// returned, announcements are always returned.
+func FilterAdvert(a *Advert, opts AdvertiseOptions) *Advert {
+	events := make([]*Event, 0, len(a.Events))
+	for _, e := range a.Events {
+		if opts.match(e.Route) {
+			events = append(events, e)
+		}
+	}
+
+	if len(events) == len(a.Events) {
+		return a
+	}
+	if len(events) == 0 && a.Type == RouteUpdate {
+		return nil
+	}
+
+	filtered := *a
+	filtered.Events = events
+	return &filtered
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterAdvert(t *testing.T) {
+	routes := []Route{
+		{Service: "go.micro.srv.foo", Network: "micro", Link: "local"},
+		{Service: "go.micro.srv.bar", Network: "micro", Link: "network"},
+		{Service: "go.micro.api.baz", Network: "other", Link: "local"},
+	}
+
+	a := &Advert{Id: "router", Type: RouteUpdate, Timestamp: time.Now()}
+	for _, r := range routes {
+		a.Events = append(a.Events, &Event{Type: Create, Route: r})
+	}
+
+	testData := []struct {
+		opts   []AdvertiseOption
+		expect int
+	}{
+		{nil, 3},
+		{[]AdvertiseOption{AdvertiseService("go.micro.srv")}, 2},
+		{[]AdvertiseOption{AdvertiseNetwork("other")}, 1},
+		{[]AdvertiseOption{AdvertiseService("go.micro.srv"), AdvertiseLink("network")}, 1},
+		{[]AdvertiseOption{AdvertiseService("go.micro.web")}, 0},
+	}
+
+	for _, d := range testData {
+		fa := FilterAdvert(a, NewAdvertise(d.opts...))
+		if d.expect == 0 {
+			if fa != nil {
+				t.Fatalf("expected advert to be dropped, got %d events", len(fa.Events))
+			}
+			continue
+		}
+		if fa == nil || len(fa.Events) != d.expect {
+			t.Fatalf("expected %d events, got %v", d.expect, fa)
+		}
+	}
+
+	// announcements are always sent
+	a.Type = Announce
+	if fa := FilterAdvert(a, NewAdvertise(AdvertiseService("go.micro.web"))); fa == nil || len(fa.Events) != 0 {
+		t.Fatalf("expected empty announcement, got %v", fa)
+	}
+
+	// the original advert is untouched
+	if len(a.Events) != 3 {
+		t.Fatalf("expected original advert to keep 3 events, got %d", len(a.Events))
+	}
+}
+
+func TestSubscriberOverflow(t *testing.T) {
+	advert := func(id string) *Advert {
+		return &Advert{Id: id, Type: Announce}
+	}
+
+	testData := []struct {
+		opts    []AdvertiseOption
+		dropped int
+		first   string
+	}{
+		{[]AdvertiseOption{AdvertiseBuffer(1), AdvertiseOverflow(DropNewest)}, 1, "a"},
+		{[]AdvertiseOption{AdvertiseBuffer(1), AdvertiseOverflow(DropOldest)}, 1, "b"},
+		{[]AdvertiseOption{AdvertiseBuffer(1), AdvertiseTimeout(time.Millisecond)}, 1, "a"},
+	}
+
+	for _, d := range testData {
+		s := newSubscriber(NewAdvertise(d.opts...))
+		exit := make(chan bool)
+
+		if dropped, ok := s.send(advert("a"), exit); dropped != 0 || !ok {
+			t.Fatalf("expected the first advert to be buffered, dropped %d", dropped)
+		}
+		if dropped, ok := s.send(advert("b"), exit); dropped != d.dropped || !ok {
+			t.Errorf("%s: expected %d adverts dropped, got %d", s.opts.Overflow, d.dropped, dropped)
+		}
+		if a := <-s.ch; a.Id != d.first {
+			t.Errorf("%s: expected advert %s buffered, got %s", s.opts.Overflow, d.first, a.Id)
+		}
+	}
+}
+
+func TestSubscriberEviction(t *testing.T) {
+	r := newRouter().(*router)
+	r.exit = make(chan bool)
+
+	sub := newSubscriber(NewAdvertise(AdvertiseBuffer(1), AdvertiseOverflow(DropNewest), AdvertiseEvict(2)))
+	r.subscribers["slow"] = sub
+
+	for i := 0; i < 3; i++ {
+		r.publishAdvert(RouteUpdate, []*Event{{Type: Create, Route: Route{Service: "foo"}}}, r.exit)
+	}
+
+	status := r.Status()
+	if status.Dropped != 2 || status.Evicted != 1 || status.Subscribers != 0 {
+		t.Fatalf("expected 2 adverts dropped and the subscriber evicted, got %+v", status)
+	}
+
+	// the buffered advert is still received before the channel is closed
+	if a := <-sub.ch; a == nil {
+		t.Fatal("expected the buffered advert")
+	}
+	if _, ok := <-sub.ch; ok {
+		t.Fatal("expected the evicted subscriber's channel to be closed")
+	}
+}
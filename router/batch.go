@@ -0,0 +1,86 @@
+package router
+
+// coalesce merges an event for a route with the event already pending for it
+// so only the net change over the flush window is advertised. A nil result
+// means the two events cancel out and nothing needs to be advertised.
+func coalesce(prev, next *Event) *Event {
+	if prev == nil {
+		return next
+	}
+
+	switch prev.Type {
+	case Create:
+		switch next.Type {
+		case Delete:
+			// the route came and went within the window
+			return nil
+		case Update:
+			// the route is still new to anyone receiving the advert
+			e := *next
+			e.Type = Create
+			return &e
+		}
+	case Delete:
+		if next.Type == Create {
+			// the route was replaced
+			e := *next
+			e.Type = Update
+			return &e
+		}
+	}
+
+	return next
+}
+
+// delta tracks the metric last advertised for each route so events which don't
+// change what has already been advertised can be dropped
+type delta map[uint64]int64
+
+// seed records routes which have been advertised
+func (d delta) seed(events []*Event) {
+	for _, e := range events {
+		if e.Type != Delete {
+			d[e.Route.Hash()] = e.Route.Metric
+		}
+	}
+}
+
+// changed returns the events which change the advertised routes and records them
+func (d delta) changed(events []*Event) []*Event {
+	var out []*Event
+
+	for _, e := range events {
+		hash := e.Route.Hash()
+
+		if e.Type == Delete {
+			delete(d, hash)
+			out = append(out, e)
+			continue
+		}
+
+		if metric, ok := d[hash]; ok && metric == e.Route.Metric {
+			continue
+		}
+
+		d[hash] = e.Route.Metric
+		out = append(out, e)
+	}
+
+	return out
+}
+
+// chunk splits events into batches of at most size events. A size of zero or
+// less returns the events as a single batch.
+func chunk(events []*Event, size int) [][]*Event {
+	if size <= 0 || len(events) <= size {
+		return [][]*Event{events}
+	}
+
+	var batches [][]*Event
+	for len(events) > size {
+		batches = append(batches, events[:size:size])
+		events = events[size:]
+	}
+
+	return append(batches, events)
+}
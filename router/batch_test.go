@@ -0,0 +1,78 @@
+package router
+
+import "testing"
+
+func TestCoalesce(t *testing.T) {
+	route := Route{Service: "foo", Address: "127.0.0.1:8080", Link: "local"}
+
+	testData := []struct {
+		prev, next EventType
+		want       EventType
+		dropped    bool
+	}{
+		{Create, Delete, 0, true},
+		{Create, Update, Create, false},
+		{Delete, Create, Update, false},
+		{Update, Update, Update, false},
+		{Update, Delete, Delete, false},
+	}
+
+	for _, d := range testData {
+		e := coalesce(&Event{Type: d.prev, Route: route}, &Event{Type: d.next, Route: route})
+		if d.dropped {
+			if e != nil {
+				t.Errorf("%s then %s: expected event to be dropped, got %s", d.prev, d.next, e.Type)
+			}
+			continue
+		}
+		if e == nil {
+			t.Errorf("%s then %s: expected %s, got nil", d.prev, d.next, d.want)
+			continue
+		}
+		if e.Type != d.want {
+			t.Errorf("%s then %s: expected %s, got %s", d.prev, d.next, d.want, e.Type)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	route := Route{Service: "foo", Address: "127.0.0.1:8080", Link: "local", Metric: 10}
+
+	d := make(delta)
+	d.seed([]*Event{{Type: Create, Route: route}})
+
+	// an update which doesn't change the metric isn't advertised
+	if events := d.changed([]*Event{{Type: Update, Route: route}}); len(events) != 0 {
+		t.Errorf("Expected no events, got %d", len(events))
+	}
+
+	route.Metric = 20
+	if events := d.changed([]*Event{{Type: Update, Route: route}}); len(events) != 1 {
+		t.Errorf("Expected 1 event, got %d", len(events))
+	}
+
+	if events := d.changed([]*Event{{Type: Delete, Route: route}}); len(events) != 1 {
+		t.Errorf("Expected 1 event, got %d", len(events))
+	}
+
+	// once deleted the route is advertised again when created
+	if events := d.changed([]*Event{{Type: Create, Route: route}}); len(events) != 1 {
+		t.Errorf("Expected 1 event, got %d", len(events))
+	}
+}
+
+func TestChunk(t *testing.T) {
+	events := make([]*Event, 5)
+
+	batches := chunk(events, 2)
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(batches))
+	}
+	if len(batches[2]) != 1 {
+		t.Errorf("Expected last batch of 1, got %d", len(batches[2]))
+	}
+
+	if batches := chunk(events, 0); len(batches) != 1 || len(batches[0]) != 5 {
+		t.Errorf("Expected a single batch of 5")
+	}
+}
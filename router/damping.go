@@ -0,0 +1,113 @@
+package router
+
+import (
+	"math"
+	"time"
+)
+
+var (
+	// DefaultDamping are the usual flap damping thresholds. Damping is disabled
+	// unless they are set with the FlapDamping option.
+	DefaultDamping = Damping{
+		Penalty:     100,
+		HalfLife:    30 * time.Second,
+		Suppress:    200,
+		Recover:     20,
+		MaxSuppress: 90 * time.Second,
+	}
+)
+
+// Damping configures route flap damping. Every event of a route adds Penalty to
+// the route penalty which then decays exponentially with HalfLife. A route whose
+// penalty exceeds Suppress isn't advertised until the penalty decays below Recover
+// or the route has been suppressed for MaxSuppress. A zero Penalty disables damping.
+type Damping struct {
+	// Penalty added for every route event
+	Penalty float64
+	// HalfLife is the time it takes for the penalty to halve
+	HalfLife time.Duration
+	// Suppress is the penalty over which the route is suppressed
+	Suppress float64
+	// Recover is the penalty under which the route is advertised again
+	Recover float64
+	// MaxSuppress is the max time the route is suppressed for
+	MaxSuppress time.Duration
+}
+
+// flap is the flap state of a route
+type flap struct {
+	// penalty as of the last update
+	penalty float64
+	// updated is when the penalty was last decayed
+	updated time.Time
+	// suppressed is when the route was suppressed, zero if it's not
+	suppressed time.Time
+}
+
+// dampener tracks the flap penalties of routes
+type dampener struct {
+	opts  Damping
+	flaps map[uint64]*flap
+}
+
+func newDampener(opts Damping) *dampener {
+	return &dampener{
+		opts:  opts,
+		flaps: make(map[uint64]*flap),
+	}
+}
+
+// decay decays the flap penalty up to now
+func (d *dampener) decay(f *flap, now time.Time) {
+	if d.opts.HalfLife > 0 {
+		elapsed := now.Sub(f.updated).Seconds()
+		f.penalty *= math.Exp(-math.Ln2 * elapsed / d.opts.HalfLife.Seconds())
+	}
+	f.updated = now
+}
+
+// record penalises an event of the route, suppressing the route once its
+// penalty exceeds the suppress threshold
+func (d *dampener) record(hash uint64, now time.Time) {
+	if d.opts.Penalty <= 0 {
+		return
+	}
+
+	f, ok := d.flaps[hash]
+	if !ok {
+		f = &flap{updated: now}
+		d.flaps[hash] = f
+	}
+
+	d.decay(f, now)
+	f.penalty += d.opts.Penalty
+
+	if f.suppressed.IsZero() && f.penalty > d.opts.Suppress {
+		f.suppressed = now
+	}
+}
+
+// release decays the penalties, releases the suppressed routes which have
+// recovered or reached the max suppress time and forgets the settled routes
+func (d *dampener) release(now time.Time) {
+	for hash, f := range d.flaps {
+		d.decay(f, now)
+
+		if !f.suppressed.IsZero() {
+			if f.penalty >= d.opts.Recover && (d.opts.MaxSuppress <= 0 || now.Sub(f.suppressed) < d.opts.MaxSuppress) {
+				continue
+			}
+			f.suppressed = time.Time{}
+		}
+
+		if f.penalty < d.opts.Recover {
+			delete(d.flaps, hash)
+		}
+	}
+}
+
+// suppressed returns true if the route is suppressed
+func (d *dampener) suppressed(hash uint64) bool {
+	f, ok := d.flaps[hash]
+	return ok && !f.suppressed.IsZero()
+}
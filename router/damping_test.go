@@ -0,0 +1,66 @@
+package router
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDampener(t *testing.T) {
+	opts := DefaultDamping
+	opts.MaxSuppress = 10 * opts.HalfLife
+
+	d := newDampener(opts)
+	now := time.Now()
+
+	// two quick changes stay under the suppress threshold
+	d.record(1, now)
+	d.record(1, now)
+	d.release(now)
+	if d.suppressed(1) {
+		t.Fatal("expected route to be advertised after 2 flaps")
+	}
+
+	// the third one suppresses the route
+	d.record(1, now)
+	d.release(now)
+	if !d.suppressed(1) {
+		t.Fatal("expected route to be suppressed after 3 flaps")
+	}
+
+	// the penalty halves every half life: 300 -> 37.5 isn't recovered yet
+	now = now.Add(3 * opts.HalfLife)
+	d.release(now)
+	if !d.suppressed(1) {
+		t.Fatal("expected route to still be suppressed")
+	}
+
+	// 300 -> 18.75 recovers and the settled route is forgotten
+	now = now.Add(opts.HalfLife)
+	d.release(now)
+	if d.suppressed(1) {
+		t.Fatal("expected route to recover")
+	}
+	if len(d.flaps) != 0 {
+		t.Fatalf("expected settled route to be forgotten, got %d flaps", len(d.flaps))
+	}
+
+	// routes keep being suppressed at most for the max suppress time,
+	// even when their penalty hasn't decayed below the recover threshold
+	for i := 0; i < 1000; i++ {
+		d.record(2, now)
+	}
+	d.release(now.Add(opts.MaxSuppress))
+	if d.suppressed(2) {
+		t.Fatal("expected route to be released after the max suppress time")
+	}
+
+	// damping is disabled without a penalty
+	d = newDampener(Damping{})
+	for i := 0; i < 10; i++ {
+		d.record(3, now)
+	}
+	d.release(now)
+	if d.suppressed(3) {
+		t.Fatal("expected damping to be disabled")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,12 +17,26 @@ import (
 var (
 	// AdvertiseEventsTick is time interval in which the router advertises route updates
 	AdvertiseEventsTick = 10 * time.Second
+	// DefaultFlushSize is the default max number of events in a single advert
+	DefaultFlushSize = 1000
+	// DefaultMaxHops is the default max number of routers a learned route may go through
+	DefaultMaxHops int64 = 16
 	// DefaultAdvertTTL is default advertisement TTL
 	DefaultAdvertTTL = 2 * time.Minute
+	// RefreshRoutesTick is time interval in which local routes are refreshed and stale routes pruned
+	RefreshRoutesTick = time.Minute
+	// DefaultRouteTTL is how long a local route lives without being refreshed
+	DefaultRouteTTL = 3 * RefreshRoutesTick
 )
 
 // router implements default router
 type router struct {
+	// number of adverts published, dropped and subscribers evicted,
+	// first to keep them 64-bit aligned
+	adverts uint64
+	dropped uint64
+	evicted uint64
+
 	sync.RWMutex
 
 	running   bool
@@ -32,7 +47,12 @@ type router struct {
 
 	// advert subscribers
 	sub         sync.RWMutex
-	subscribers map[string]chan *Advert
+	subscribers map[string]*subscriber
+
+	// health reported by Status
+	health   sync.RWMutex
+	watching int
+	lastErr  error
 }
 
 // newRouter creates new router and returns it
@@ -48,7 +68,7 @@ func newRouter(opts ...Option) Router {
 	// construct the router
 	r := &router{
 		options:     options,
-		subscribers: make(map[string]chan *Advert),
+		subscribers: make(map[string]*subscriber),
 	}
 
 	// create the new table, passing the fetchRoute method in as a fallback if
@@ -60,25 +80,42 @@ func newRouter(opts ...Option) Router {
 	return r
 }
 
-// Init initializes router with given options
+// Init initializes router with given options. The router is restarted keeping
+// its advert subscribers, which are sent an announce of the full routing table.
 func (r *router) Init(opts ...Option) error {
-	// stop the router before we initialize
-	if err := r.Close(); err != nil {
-		return err
-	}
-
 	r.Lock()
 	defer r.Unlock()
 
+	// stop the router before we initialize
+	r.stop()
+
 	for _, o := range opts {
 		o(&r.options)
 	}
 
 	// restart the router
 	if err := r.start(); err != nil {
+		r.closeSubscribers()
 		return err
 	}
 
+	r.sub.RLock()
+	subscribers := len(r.subscribers)
+	r.sub.RUnlock()
+
+	if subscribers == 0 {
+		return nil
+	}
+
+	// replay the routing table to the subscribers kept across the restart
+	events, err := r.flushRouteEvents(Create)
+	if err != nil {
+		r.closeSubscribers()
+		return fmt.Errorf("failed to flush routes: %s", err)
+	}
+
+	r.advertise(events)
+
 	return nil
 }
 
@@ -119,9 +156,9 @@ func (r *router) manageRoute(route Route, action string) error {
 	return nil
 }
 
-// manageServiceRoutes applies action to all routes of the service.
+// manageServiceRoutes applies action to all routes of the service found in the source.
 // It returns error of the action fails with error.
-func (r *router) manageRoutes(service *registry.Service, action, network string) error {
+func (r *router) manageRoutes(service *registry.Service, action, network, src string) error {
 	// action is the routing table action
 	action = strings.ToLower(action)
 
@@ -136,7 +173,7 @@ func (r *router) manageRoutes(service *registry.Service, action, network string)
 			Router:   r.options.Id,
 			Link:     DefaultLink,
 			Metric:   DefaultLocalMetric,
-			Metadata: node.Metadata,
+			Metadata: sourceMetadata(node.Metadata, src),
 		}
 
 		if err := r.manageRoute(route, action); err != nil {
@@ -147,32 +184,27 @@ func (r *router) manageRoutes(service *registry.Service, action, network string)
 	return nil
 }
 
-// manageRegistryRoutes applies action to all routes of each service found in the registry.
+// manageRegistryRoutes applies action to all routes of each service found in the source registry.
 // It returns error if either the services failed to be listed or the routing table action fails.
-func (r *router) manageRegistryRoutes(reg registry.Registry, action string) error {
-	services, err := reg.ListServices(registry.ListDomain(registry.WildcardDomain))
+func (r *router) manageRegistryRoutes(src source, action string) error {
+	services, err := src.registry.ListServices(registry.ListDomain(registry.WildcardDomain))
 	if err != nil {
-		return fmt.Errorf("failed listing services: %v", err)
+		return fmt.Errorf("failed listing services in %s: %v", src.name, err)
 	}
 
 	// add each service node as a separate route
 	for _, service := range services {
 		// get the services domain from metadata. Fallback to wildcard.
-		var domain string
-		if service.Metadata != nil && len(service.Metadata["domain"]) > 0 {
-			domain = service.Metadata["domain"]
-		} else {
-			domain = registry.WildcardDomain
-		}
+		domain := serviceDomain(service)
 
 		// get the service to retrieve all its info
-		srvs, err := reg.GetService(service.Name, registry.GetDomain(domain))
+		srvs, err := src.registry.GetService(service.Name, registry.GetDomain(domain))
 		if err != nil {
 			continue
 		}
 		// manage the routes for all returned services
 		for _, srv := range srvs {
-			if err := r.manageRoutes(srv, action, domain); err != nil {
+			if err := r.manageRoutes(srv, action, domain, src.name); err != nil {
 				return err
 			}
 		}
@@ -181,24 +213,67 @@ func (r *router) manageRegistryRoutes(reg registry.Registry, action string) erro
 	return nil
 }
 
-// fetchRoutes retrieves all the routes for a given service and creates them in the routing table
+// fetchRoutes retrieves all the routes for a given service from every registry and creates
+// them in the routing table. Routes found in the registries which can be reached are created
+// even when another registry fails.
 func (r *router) fetchRoutes(service string) error {
-	services, err := r.options.Registry.GetService(service, registry.GetDomain(registry.WildcardDomain))
-	if err == registry.ErrNotFound {
-		return nil
-	} else if err != nil {
-		return fmt.Errorf("failed getting services: %v", err)
+	var gerr error
+
+	for _, src := range r.sources() {
+		services, err := src.registry.GetService(service, registry.GetDomain(registry.WildcardDomain))
+		if err == registry.ErrNotFound {
+			continue
+		} else if err != nil {
+			gerr = fmt.Errorf("failed getting services from %s: %v", src.name, err)
+			continue
+		}
+
+		for _, srv := range services {
+			if err := r.manageRoutes(srv, "create", serviceDomain(srv), src.name); err != nil {
+				return err
+			}
+		}
 	}
 
-	for _, srv := range services {
-		var domain string
-		if srv.Metadata != nil && len(srv.Metadata["domain"]) > 0 {
-			domain = srv.Metadata["domain"]
-		} else {
-			domain = registry.WildcardDomain
+	return gerr
+}
+
+// isLocal checks if the route was created from the local registry
+func (r *router) isLocal(route Route) bool {
+	return route.Router == r.options.Id && route.Link == DefaultLink && len(route.Gateway) == 0
+}
+
+// pruneRoutes refreshes the local routes in the table from the registry and deletes
+// those which weren't refreshed within the ttl. This removes routes whose registry
+// delete event was missed, e.g. while the registry watcher was reconnecting.
+func (r *router) pruneRoutes(ttl time.Duration) error {
+	routes, err := r.table.List()
+	if err != nil {
+		return err
+	}
+
+	services := make(map[string]bool)
+	for _, route := range routes {
+		if r.isLocal(route) {
+			services[route.Service] = true
 		}
+	}
+
+	// don't prune anything if the registry can't be reached
+	for service := range services {
+		if err := r.fetchRoutes(service); err != nil {
+			return err
+		}
+	}
 
-		if err := r.manageRoutes(srv, "create", domain); err != nil {
+	for _, route := range r.table.stale(ttl) {
+		if !r.isLocal(route) {
+			continue
+		}
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Router pruning stale route for service %s: %s", route.Service, route.Address)
+		}
+		if err := r.table.Delete(route); err != nil && err != ErrRouteNotFound {
 			return err
 		}
 	}
@@ -206,9 +281,34 @@ func (r *router) fetchRoutes(service string) error {
 	return nil
 }
 
-// watchRegistry watches registry and updates routing table based on the received events.
+// refreshRoutes periodically prunes stale routes until the router exits
+func (r *router) refreshRoutes(ttl time.Duration, exit chan bool) {
+	tick := RefreshRoutesTick
+	if ttl < tick {
+		tick = ttl
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-ticker.C:
+			if err := r.pruneRoutes(ttl); err != nil {
+				r.setError(err)
+				if logger.V(logger.WarnLevel, log) {
+					log.Warnf("Error pruning routes: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// watchRegistry watches the source registry and updates routing table based on the received events.
 // It returns error if either the registry watcher fails with error or if the routing table update fails.
-func (r *router) watchRegistry(w registry.Watcher) error {
+func (r *router) watchRegistry(src source, w registry.Watcher, done chan bool) error {
 	exit := make(chan bool)
 
 	defer func() {
@@ -219,9 +319,9 @@ func (r *router) watchRegistry(w registry.Watcher) error {
 		defer w.Stop()
 
 		select {
-		case <-exit:
+		case <-done:
 			return
-		case <-r.exit:
+		case <-exit:
 			return
 		}
 	}()
@@ -240,14 +340,9 @@ func (r *router) watchRegistry(w registry.Watcher) error {
 		}
 
 		// get the services domain from metadata. Fallback to wildcard.
-		var domain string
-		if res.Service.Metadata != nil && len(res.Service.Metadata["domain"]) > 0 {
-			domain = res.Service.Metadata["domain"]
-		} else {
-			domain = registry.WildcardDomain
-		}
+		domain := serviceDomain(res.Service)
 
-		if err := r.manageRoutes(res.Service, res.Action, domain); err != nil {
+		if err := r.manageRoutes(res.Service, res.Action, domain, src.name); err != nil {
 			return err
 		}
 	}
@@ -255,9 +350,9 @@ func (r *router) watchRegistry(w registry.Watcher) error {
 	return nil
 }
 
-// watchTable watches routing table entries and either adds or deletes locally registered service to/from network registry
+// watchTable watches routing table entries and sends them to the event channel until done is closed
 // It returns error if the locally registered services either fails to be added/deleted to/from network registry.
-func (r *router) watchTable(w Watcher) error {
+func (r *router) watchTable(w Watcher, eventChan chan *Event, done chan bool) error {
 	exit := make(chan bool)
 
 	defer func() {
@@ -270,7 +365,7 @@ func (r *router) watchTable(w Watcher) error {
 		defer w.Stop()
 
 		select {
-		case <-r.exit:
+		case <-done:
 			return
 		case <-exit:
 			return
@@ -287,9 +382,9 @@ func (r *router) watchTable(w Watcher) error {
 		}
 
 		select {
-		case <-r.exit:
+		case <-done:
 			return nil
-		case r.eventChan <- event:
+		case eventChan <- event:
 			// process event
 		}
 	}
@@ -297,72 +392,141 @@ func (r *router) watchTable(w Watcher) error {
 	return nil
 }
 
+// publishEvents publishes the events in adverts of at most FlushSize events
+func (r *router) publishEvents(advType AdvertType, events []*Event, exit chan bool) {
+	for _, batch := range chunk(events, r.options.FlushSize) {
+		r.publishAdvert(advType, batch, exit)
+	}
+}
+
 // publishAdvert publishes router advert to advert channel
-func (r *router) publishAdvert(advType AdvertType, events []*Event) {
+func (r *router) publishAdvert(advType AdvertType, events []*Event, exit chan bool) {
+	ttl := r.options.AdvertTTL
+	if ttl <= 0 {
+		ttl = DefaultAdvertTTL
+	}
+
 	a := &Advert{
 		Id:        r.options.Id,
 		Type:      advType,
-		TTL:       DefaultAdvertTTL,
+		TTL:       ttl,
 		Timestamp: time.Now(),
 		Events:    events,
 	}
 
+	atomic.AddUint64(&r.adverts, 1)
+
+	var evict []string
+
 	r.sub.RLock()
-	for _, sub := range r.subscribers {
-		// now send the message
-		select {
-		case sub <- a:
-		case <-r.exit:
+	for id, sub := range r.subscribers {
+		// only send the events the subscriber asked for
+		sa := FilterAdvert(a, sub.opts)
+		if sa == nil {
+			continue
+		}
+
+		// now send the message applying the subscriber's overflow policy
+		dropped, ok := sub.send(sa, exit)
+		if !ok {
 			r.sub.RUnlock()
 			return
 		}
+
+		if dropped > 0 {
+			atomic.AddUint64(&r.dropped, uint64(dropped))
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Router %s dropped %d adverts for subscriber %s with policy %s", r.options.Id, dropped, id, sub.opts.Overflow)
+			}
+		}
+
+		if sub.evict() {
+			evict = append(evict, id)
+		}
 	}
 	r.sub.RUnlock()
+
+	if len(evict) == 0 {
+		return
+	}
+
+	// evict the subscribers which keep overflowing
+	r.sub.Lock()
+	for _, id := range evict {
+		sub, ok := r.subscribers[id]
+		if !ok {
+			continue
+		}
+		if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Router %s evicting subscriber %s after %d overflows", r.options.Id, id, atomic.LoadInt64(&sub.overflows))
+		}
+		close(sub.ch)
+		delete(r.subscribers, id)
+		atomic.AddUint64(&r.evicted, 1)
+	}
+	r.sub.Unlock()
 }
 
 // adverts maintains a map of router adverts
 type adverts map[uint64]*Event
 
-// advertiseEvents advertises routing table events
-// It suppresses unhealthy flapping events and advertises healthy events upstream.
-func (r *router) advertiseEvents() error {
+// advertiseEvents advertises the routing table events received on the event channel until done is closed.
+// Events are coalesced per route over the flush interval and only the routes
+// which changed since they were last advertised are published.
+func (r *router) advertiseEvents(announced []*Event, eventChan chan *Event, done chan bool) error {
+	interval := r.options.FlushInterval
+	if interval <= 0 {
+		interval = AdvertiseEventsTick
+	}
+
 	// ticker to periodically scan event for advertising
-	ticker := time.NewTicker(AdvertiseEventsTick)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// adverts is a map of advert events
 	adverts := make(adverts)
 
+	// advertised keeps track of the routes already advertised
+	advertised := make(delta)
+	advertised.seed(announced)
+
+	// damp suppresses the flapping routes
+	damp := newDampener(r.options.Damping)
+
 	// routing table watcher
 	w, err := r.Watch()
 	if err != nil {
 		return err
 	}
-	defer w.Stop()
 
+	// the watcher is owned by the goroutine which stops it once done
 	go func() {
 		var err error
 
 		for {
 			select {
-			case <-r.exit:
+			case <-done:
+				if w != nil {
+					w.Stop()
+				}
 				return
 			default:
 				if w == nil {
 					// routing table watcher
 					w, err = r.Watch()
 					if err != nil {
-						if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-							logger.Errorf("Error creating watcher: %v", err)
+						if logger.V(logger.ErrorLevel, log) {
+							log.Errorf("Error creating watcher: %v", err)
 						}
 						time.Sleep(time.Second)
 						continue
 					}
 				}
 
-				if err := r.watchTable(w); err != nil {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						logger.Errorf("Error watching table: %v", err)
+				if err := r.watchTable(w, eventChan, done); err != nil {
+					r.setError(err)
+					if logger.V(logger.ErrorLevel, log) {
+						log.Errorf("Error watching table: %v", err)
 					}
 					time.Sleep(time.Second)
 				}
@@ -376,41 +540,49 @@ func (r *router) advertiseEvents() error {
 		}
 	}()
 
-	for {
-		select {
-		case <-ticker.C:
-			// If we're not advertising any events then sip processing them entirely
-			if r.options.Advertise == AdvertiseNone {
+	// flush advertises the pending events which change the advertised routes
+	flush := func() {
+		var events []*Event
+
+		damp.release(time.Now())
+
+		for key, event := range adverts {
+			// hold the events of suppressed routes until they settle
+			if damp.suppressed(key) {
 				continue
 			}
 
-			var events []*Event
+			// copy the event and append
+			e := new(Event)
+			// this is ok, because router.Event only contains builtin types
+			// and no references so this creates a deep copy of struct Event
+			*e = *event
+			events = append(events, e)
+			// delete the advert from adverts
+			delete(adverts, key)
+		}
 
-			// collect all events which are not flapping
-			for key, event := range adverts {
-				// if we only advertise local routes skip processing anything not link local
-				if r.options.Advertise == AdvertiseLocal && event.Route.Link != "local" {
-					continue
-				}
+		events = advertised.changed(events)
 
-				// copy the event and append
-				e := new(Event)
-				// this is ok, because router.Event only contains builtin types
-				// and no references so this creates a deep copy of struct Event
-				*e = *event
-				events = append(events, e)
-				// delete the advert from adverts
-				delete(adverts, key)
+		// advertise events to subscribers
+		if len(events) > 0 {
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Router publishing %d events", len(events))
 			}
+			go r.publishEvents(RouteUpdate, events, done)
+		}
+	}
 
-			// advertise events to subscribers
-			if len(events) > 0 {
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debugf("Router publishing %d events", len(events))
-				}
-				go r.publishAdvert(RouteUpdate, events)
+	for {
+		select {
+		case <-ticker.C:
+			// If we're not advertising any events then sip processing them entirely
+			if r.options.Advertise == AdvertiseNone {
+				continue
 			}
-		case e := <-r.eventChan:
+
+			flush()
+		case e := <-eventChan:
 			// if event is nil, continue
 			if e == nil {
 				continue
@@ -421,32 +593,29 @@ func (r *router) advertiseEvents() error {
 				continue
 			}
 
-			// if we only advertise local routes skip processing anything not link local
-			if r.options.Advertise == AdvertiseLocal && e.Route.Link != "local" {
+			// skip processing any route the strategy doesn't advertise
+			if len(r.options.Advertise.Advertise([]Route{e.Route})) == 0 {
 				continue
 			}
 
-			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-				logger.Debugf("Router processing table event %s for service %s %s", e.Type, e.Route.Service, e.Route.Address)
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Router processing table event %s for service %s %s", e.Type, e.Route.Service, e.Route.Address)
 			}
 
-			// check if we have already registered the route
+			// merge the event with any pending event for the route
 			hash := e.Route.Hash()
-			ev, ok := adverts[hash]
-			if !ok {
-				ev = e
-				adverts[hash] = e
-				continue
+			damp.record(hash, time.Now())
+			if ev := coalesce(adverts[hash], e); ev != nil {
+				adverts[hash] = ev
+			} else {
+				delete(adverts, hash)
 			}
 
-			// override the route event only if the previous event was different
-			if ev.Type != e.Type {
-				ev = e
-			}
-		case <-r.exit:
-			if w != nil {
-				w.Stop()
+			// don't wait for the tick if there's a full advert pending
+			if r.options.FlushSize > 0 && len(adverts) >= r.options.FlushSize {
+				flush()
 			}
+		case <-done:
 			return nil
 		}
 	}
@@ -468,13 +637,25 @@ func (r *router) start() error {
 	if r.running {
 		return nil
 	}
-	logger.Info("Starting service router")
+	log.Info("Starting service router")
 
-	if r.options.Prewarm {
-		logger.Info("Prewarming router")
+	if r.options.Bootstrap != nil {
+		log.Info("Bootstrapping router")
+		// load the routes of the peer table into the routing table
+		snapshot, err := r.options.Bootstrap.Dump()
+		if err != nil {
+			return fmt.Errorf("failed dumping bootstrap table: %s", err)
+		}
+		if err := r.table.Load(snapshot); err != nil {
+			return fmt.Errorf("failed loading bootstrap routes: %s", err)
+		}
+	} else if r.options.Prewarm {
+		log.Info("Prewarming router")
 		// add all local service routes into the routing table
-		if err := r.manageRegistryRoutes(r.options.Registry, "create"); err != nil {
-			return fmt.Errorf("failed adding registry routes: %s", err)
+		for _, src := range r.sources() {
+			if err := r.manageRegistryRoutes(src, "create"); err != nil {
+				return fmt.Errorf("failed adding registry routes: %s", err)
+			}
 		}
 	}
 
@@ -498,57 +679,84 @@ func (r *router) start() error {
 	// create error and exit channels
 	r.exit = make(chan bool)
 
-	// registry watcher
-	w, err := r.options.Registry.Watch(registry.WatchDomain(registry.WildcardDomain))
-	if err != nil {
-		return fmt.Errorf("failed creating registry watcher: %v", err)
+	// registry watchers, one per registry the local routes are built from
+	sources := r.sources()
+	watchers := make([]registry.Watcher, len(sources))
+	for i, src := range sources {
+		w, err := src.watch()
+		if err != nil {
+			for _, w := range watchers[:i] {
+				w.Stop()
+			}
+			return fmt.Errorf("failed creating %s registry watcher: %v", src.name, err)
+		}
+		watchers[i] = w
 	}
 
 	r.running = true
-	go func() {
-		var err error
 
-		for {
-			select {
-			case <-r.exit:
-				if w != nil {
-					w.Stop()
-				}
-				return
-			default:
-				if w == nil {
-					w, err = r.options.Registry.Watch()
-					if err != nil {
-						if logger.V(logger.WarnLevel, logger.DefaultLogger) {
-							logger.Warnf("failed creating registry watcher: %v", err)
-						}
-						time.Sleep(time.Second)
-						continue
-					}
-				}
+	// prune routes missed by the registry watcher
+	if r.options.RouteTTL > 0 {
+		go r.refreshRoutes(r.options.RouteTTL, r.exit)
+	}
+
+	// merge the watch streams of the registries into the table
+	for i, src := range sources {
+		go r.watchSource(src, watchers[i], r.exit)
+	}
+
+	return nil
+}
+
+// watchSource watches the source registry until the router exits, recreating the watcher on error
+func (r *router) watchSource(src source, w registry.Watcher, exit chan bool) {
+	var err error
 
-				if err := r.watchRegistry(w); err != nil {
-					if logger.V(logger.WarnLevel, logger.DefaultLogger) {
-						logger.Warnf("Error watching the registry: %v", err)
+	for {
+		select {
+		case <-exit:
+			if w != nil {
+				w.Stop()
+			}
+			return
+		default:
+			if w == nil {
+				w, err = src.watch()
+				if err != nil {
+					r.setError(err)
+					if logger.V(logger.WarnLevel, log) {
+						log.Warnf("failed creating %s registry watcher: %v", src.name, err)
 					}
 					time.Sleep(time.Second)
+					continue
 				}
+			}
 
-				if w != nil {
-					w.Stop()
-					w = nil
+			r.setWatching(true)
+			err = r.watchRegistry(src, w, exit)
+			r.setWatching(false)
+
+			if err != nil {
+				r.setError(err)
+				if logger.V(logger.WarnLevel, log) {
+					log.Warnf("Error watching the %s registry: %v", src.name, err)
 				}
+				time.Sleep(time.Second)
 			}
-		}
-	}()
 
-	return nil
+			if w != nil {
+				w.Stop()
+				w = nil
+			}
+		}
+	}
 }
 
 // Advertise stars advertising the routes to the network and returns the advertisements channel to consume from.
 // If the router is already advertising it returns the channel to consume from.
 // It returns error if either the router is not running or if the routing table fails to list the routes to advertise.
-func (r *router) Advertise() (<-chan *Advert, error) {
+// Subscribers can pass options to only receive events for the routes they're interested in.
+func (r *router) Advertise(opts ...AdvertiseOption) (<-chan *Advert, error) {
 	r.Lock()
 	defer r.Unlock()
 
@@ -562,9 +770,9 @@ func (r *router) Advertise() (<-chan *Advert, error) {
 
 	// already advertising
 	if r.eventChan != nil {
-		advertChan := make(chan *Advert, 128)
-		r.subscribers[uuid.New().String()] = advertChan
-		return advertChan, nil
+		sub := newSubscriber(NewAdvertise(opts...))
+		r.subscribers[uuid.New().String()] = sub
+		return sub.ch, nil
 	}
 
 	// list all the routes and pack them into even slice to advertise
@@ -573,31 +781,33 @@ func (r *router) Advertise() (<-chan *Advert, error) {
 		return nil, fmt.Errorf("failed to flush routes: %s", err)
 	}
 
+	// create advert subscriber
+	sub := newSubscriber(NewAdvertise(opts...))
+	r.subscribers[uuid.New().String()] = sub
+
+	// advertise your presence
+	r.advertise(events)
+
+	return sub.ch, nil
+}
+
+// advertise announces the events and starts advertising the routing table events
+// to the subscribers until the router stops. Should be called under lock.
+func (r *router) advertise(events []*Event) {
 	// create event channels
 	r.eventChan = make(chan *Event)
+	eventChan, exit := r.eventChan, r.exit
 
-	// create advert channel
-	advertChan := make(chan *Advert, 128)
-	r.subscribers[uuid.New().String()] = advertChan
-
-	// advertise your presence
-	go r.publishAdvert(Announce, events)
+	go r.publishEvents(Announce, events, exit)
 
 	go func() {
-		select {
-		case <-r.exit:
-			return
-		default:
-			if err := r.advertiseEvents(); err != nil {
-				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-					logger.Errorf("Error adveritising events: %v", err)
-				}
+		if err := r.advertiseEvents(events, eventChan, exit); err != nil {
+			r.setError(err)
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Error adveritising events: %v", err)
 			}
 		}
 	}()
-
-	return advertChan, nil
-
 }
 
 // Process updates the routing table using the advertised values
@@ -611,24 +821,24 @@ func (r *router) Process(a *Advert) error {
 		return events[i].Timestamp.Before(events[j].Timestamp)
 	})
 
-	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-		logger.Tracef("Router %s processing advert from: %s", r.options.Id, a.Id)
+	if logger.V(logger.TraceLevel, log) {
+		log.Tracef("Router %s processing advert from: %s", r.options.Id, a.Id)
 	}
 
 	for _, event := range events {
-		// skip if the router is the origin of this route
-		if event.Route.Router == r.options.Id {
-			if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-				logger.Tracef("Router skipping processing its own route: %s", r.options.Id)
+		// skip the routers own routes, the routes the advertising router learned
+		// from this router and the routes which went through too many routers
+		route, ok := LearnRoute(event.Route, a.Id, r.options)
+		if !ok {
+			if logger.V(logger.TraceLevel, log) {
+				log.Tracef("Router %s skipping route for service %s from router %s: origin %s hops %d", r.options.Id, route.Service, a.Id, route.Origin, route.Hops)
 			}
 			continue
 		}
-		// create a copy of the route
-		route := event.Route
 		action := event.Type
 
-		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-			logger.Tracef("Router %s applying %s from router %s for service %s %s", r.options.Id, action, route.Router, route.Service, route.Address)
+		if logger.V(logger.TraceLevel, log) {
+			log.Tracef("Router %s applying %s from router %s for service %s %s", r.options.Id, action, route.Router, route.Service, route.Address)
 		}
 
 		if err := r.manageRoute(route, action.String()); err != nil {
@@ -642,18 +852,16 @@ func (r *router) Process(a *Advert) error {
 // flushRouteEvents returns a slice of events, one per each route in the routing table
 func (r *router) flushRouteEvents(evType EventType) ([]*Event, error) {
 	// get a list of routes for each service in our routing table
-	// for the configured advertising strategy
-	q := []QueryOption{
-		QueryStrategy(r.options.Advertise),
-	}
-
-	routes, err := r.Table().Query(q...)
+	routes, err := r.Table().Query()
 	if err != nil && err != ErrRouteNotFound {
 		return nil, err
 	}
 
-	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-		logger.Debugf("Router advertising %d routes with strategy %s", len(routes), r.options.Advertise)
+	// pick the routes to advertise with the configured advertising strategy
+	routes = r.options.Advertise.Advertise(routes)
+
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("Router advertising %d routes with strategy %s", len(routes), r.options.Advertise)
 	}
 
 	// build a list of events to advertise
@@ -678,6 +886,12 @@ func (r *router) Lookup(q ...QueryOption) ([]Route, error) {
 	return r.table.Query(q...)
 }
 
+// Feedback adjusts the metric of the route returned by lookups using moving averages
+// of the latency and error rate reported for it, so healthy and fast routes are preferred
+func (r *router) Feedback(route Route, rtt time.Duration, err error) error {
+	return r.table.observe(route, rtt, err)
+}
+
 // Watch routes
 func (r *router) Watch(opts ...WatchOption) (Watcher, error) {
 	return r.table.Watch(opts...)
@@ -688,36 +902,107 @@ func (r *router) Close() error {
 	r.Lock()
 	defer r.Unlock()
 
+	r.stop()
+	r.closeSubscribers()
+
+	return nil
+}
+
+// stop the router, the advert subscribers are kept. Should be called under lock.
+func (r *router) stop() {
 	select {
 	case <-r.exit:
-		return nil
+		return
 	default:
 		if r.exit != nil {
 			close(r.exit)
 
 			// extract the events
 			r.drain()
+		}
+	}
 
-			r.sub.Lock()
-			// close advert subscribers
-			for id, sub := range r.subscribers {
-				// close the channel
-				close(sub)
-				// delete the subscriber
-				delete(r.subscribers, id)
-			}
-			r.sub.Unlock()
+	// remove event chan, it's not closed since the table watcher
+	// may still be sending to it until it sees the router exit
+	r.eventChan = nil
+
+	r.running = false
+}
+
+// closeSubscribers closes and removes all the advert subscribers
+func (r *router) closeSubscribers() {
+	r.sub.Lock()
+	defer r.sub.Unlock()
+
+	for id, sub := range r.subscribers {
+		// close the channel
+		close(sub.ch)
+		// delete the subscriber
+		delete(r.subscribers, id)
+	}
+}
+
+// setError records the last error encountered by the router
+func (r *router) setError(err error) {
+	r.health.Lock()
+	r.lastErr = err
+	r.health.Unlock()
+}
+
+// setWatching records a registry watch starting or stopping
+func (r *router) setWatching(w bool) {
+	r.health.Lock()
+	if w {
+		r.watching++
+	} else {
+		r.watching--
+	}
+	r.health.Unlock()
+}
+
+// Status returns the state of the router
+func (r *router) Status() Status {
+	r.RLock()
+	sources := len(r.options.Registries)
+	if sources == 0 {
+		sources = 1
+	}
+	code := Stopped
+	if r.running {
+		code = Running
+		if r.eventChan != nil {
+			code = Advertising
 		}
 	}
+	r.RUnlock()
 
-	// close and remove event chan
-	if r.eventChan != nil {
-		close(r.eventChan)
-		r.eventChan = nil
+	r.sub.RLock()
+	subscribers := len(r.subscribers)
+	r.sub.RUnlock()
+
+	r.health.RLock()
+	// the router is watching once every registry is being watched
+	watching, lastErr := r.watching == sources, r.lastErr
+	r.health.RUnlock()
+
+	// the routes can't be kept up to date without the registry watch
+	if code != Stopped && !watching && lastErr != nil {
+		code = Error
 	}
 
-	r.running = false
-	return nil
+	routes, _ := r.table.List()
+
+	return Status{
+		Code:        code,
+		Error:       lastErr,
+		Routes:      len(routes),
+		Services:    countRoutes(routes),
+		Subscribers: subscribers,
+		Adverts:     atomic.LoadUint64(&r.adverts),
+		Dropped:     atomic.LoadUint64(&r.dropped),
+		Evicted:     atomic.LoadUint64(&r.evicted),
+		Watching:    watching,
+	}
 }
 
 // String prints debugging information about router
@@ -3,10 +3,12 @@ package router
 import (
 	"fmt"
 	"os"
+	"path"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/registry/memory"
 )
 
@@ -130,3 +132,284 @@ func TestRouterAdvertise(t *testing.T) {
 		t.Errorf("failed to stop router: %v", err)
 	}
 }
+
+func TestRouterPruneRoutes(t *testing.T) {
+	reg := memory.NewRegistry()
+	r := newRouter(Registry(reg)).(*router)
+
+	alive := &registry.Service{
+		Name:    "alive",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "alive-1", Address: "10.0.0.1:8080"}},
+	}
+	dead := &registry.Service{
+		Name:    "dead",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "dead-1", Address: "10.0.0.2:8080"}},
+	}
+
+	for _, s := range []*registry.Service{alive, dead} {
+		if err := reg.Register(s); err != nil {
+			t.Fatalf("failed to register %s: %v", s.Name, err)
+		}
+		if err := r.fetchRoutes(s.Name); err != nil {
+			t.Fatalf("failed to fetch routes for %s: %v", s.Name, err)
+		}
+	}
+
+	// deregister without the router seeing the event
+	if err := reg.Deregister(dead); err != nil {
+		t.Fatalf("failed to deregister: %v", err)
+	}
+
+	ttl := 10 * time.Millisecond
+	time.Sleep(2 * ttl)
+
+	if err := r.pruneRoutes(ttl); err != nil {
+		t.Fatalf("failed to prune routes: %v", err)
+	}
+
+	routes, err := r.table.List()
+	if err != nil {
+		t.Fatalf("failed to list routes: %v", err)
+	}
+	if len(routes) != 1 || routes[0].Service != "alive" {
+		t.Fatalf("expected only the alive route, got %v", routes)
+	}
+}
+
+func TestRouterStatus(t *testing.T) {
+	r := routerTestSetup().(*router)
+
+	if code := r.Status().Code; code != Stopped {
+		t.Fatalf("expected router to be stopped, got %s", code)
+	}
+
+	routes := []Route{
+		{Service: "foo", Address: "10.0.0.1:8080", Link: "local"},
+		{Service: "foo", Address: "10.0.0.2:8080", Link: "local"},
+		{Service: "bar", Address: "10.0.0.3:8080", Link: "local"},
+	}
+	for _, route := range routes {
+		if err := r.table.Create(route); err != nil {
+			t.Fatalf("failed to create route: %v", err)
+		}
+	}
+
+	r.running = true
+	r.setError(fmt.Errorf("watch failed"))
+
+	status := r.Status()
+	if status.Code != Error {
+		t.Errorf("expected router in error without the registry watch, got %s", status.Code)
+	}
+	if status.Routes != 3 {
+		t.Errorf("expected 3 routes, got %d", status.Routes)
+	}
+	if status.Services["foo"] != 2 || status.Services["bar"] != 1 {
+		t.Errorf("unexpected routes per service: %v", status.Services)
+	}
+
+	r.setWatching(true)
+	if status := r.Status(); status.Code != Running || status.Error == nil {
+		t.Errorf("expected running router reporting the last error, got %s %v", status.Code, status.Error)
+	}
+}
+
+func TestRouterInitKeepsSubscribers(t *testing.T) {
+	r := newRouter(Registry(memory.NewRegistry()), Advertise(AdvertiseAll)).(*router)
+	if err := r.Init(); err != nil {
+		t.Fatalf("failed to start router: %v", err)
+	}
+	defer r.Close()
+
+	route := Route{Service: "foo", Address: "10.0.0.1:8080", Link: "local"}
+	if err := r.table.Create(route); err != nil {
+		t.Fatalf("failed to create route: %v", err)
+	}
+
+	ch, err := r.Advertise()
+	if err != nil {
+		t.Fatalf("failed to start advertising: %v", err)
+	}
+
+	announce := func() {
+		select {
+		case a, ok := <-ch:
+			if !ok {
+				t.Fatal("advert channel closed")
+			}
+			if a.Type != Announce || len(a.Events) != 1 {
+				t.Fatalf("expected announce of 1 route, got %s of %d routes", a.Type, len(a.Events))
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for announce")
+		}
+	}
+
+	announce()
+
+	// changing the options restarts the router and replays the table
+	if err := r.Init(Network("foo")); err != nil {
+		t.Fatalf("failed to init router: %v", err)
+	}
+
+	announce()
+
+	if code := r.Status().Code; code != Advertising {
+		t.Errorf("expected router to be advertising, got %s", code)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("failed to close router: %v", err)
+	}
+
+	if _, ok := <-ch; ok {
+		t.Error("expected advert channel to be closed")
+	}
+}
+
+// globStrategy advertises the services matching a name glob
+type globStrategy string
+
+func (g globStrategy) Advertise(routes []Route) []Route {
+	var results []Route
+	for _, route := range routes {
+		if ok, _ := path.Match(string(g), route.Service); ok {
+			results = append(results, route)
+		}
+	}
+	return results
+}
+
+func (g globStrategy) String() string {
+	return "glob"
+}
+
+func TestRouterAdvertStrategy(t *testing.T) {
+	routes := []Route{
+		{Service: "go.micro.srv.foo", Address: "10.0.0.1:8080", Link: "local", Metric: 10},
+		{Service: "go.micro.srv.foo", Address: "10.0.0.2:8080", Link: "network", Metric: 5},
+		{Service: "go.micro.api.bar", Address: "10.0.0.3:8080", Link: "local", Metric: 1},
+	}
+
+	testCases := []struct {
+		strategy AdvertStrategy
+		expected int
+	}{
+		{AdvertiseAll, 3},
+		{AdvertiseBest, 2},
+		{AdvertiseLocal, 2},
+		{AdvertiseNone, 0},
+		{globStrategy("go.micro.srv.*"), 2},
+	}
+
+	for _, tc := range testCases {
+		r := newRouter(Registry(memory.NewRegistry()), Advertise(tc.strategy)).(*router)
+		for _, route := range routes {
+			if err := r.table.Create(route); err != nil {
+				t.Fatalf("failed to create route: %v", err)
+			}
+		}
+
+		events, err := r.flushRouteEvents(Update)
+		if err != nil {
+			t.Fatalf("failed to flush route events: %v", err)
+		}
+		if len(events) != tc.expected {
+			t.Errorf("strategy %s: expected %d events, got %d", tc.strategy, tc.expected, len(events))
+		}
+	}
+}
+
+func TestRouterProcessSplitHorizon(t *testing.T) {
+	r := newRouter(Registry(memory.NewRegistry()), Id("r1"), MaxHops(2)).(*router)
+
+	advert := &Advert{
+		Id:   "r2",
+		Type: RouteUpdate,
+		Events: []*Event{
+			// learned by r2 from a third router
+			{Type: Create, Route: Route{Service: "foo", Address: "10.0.0.1:8080", Router: "r3", Origin: "r3", Hops: 1}},
+			// learned by r2 from r1 so must not come back
+			{Type: Create, Route: Route{Service: "bar", Address: "10.0.0.2:8080", Router: "r4", Origin: "r1", Hops: 1}},
+			// owned by r1
+			{Type: Create, Route: Route{Service: "baz", Address: "10.0.0.3:8080", Router: "r1"}},
+			// went through too many routers
+			{Type: Create, Route: Route{Service: "qux", Address: "10.0.0.4:8080", Router: "r5", Origin: "r5", Hops: 2}},
+		},
+	}
+
+	if err := r.Process(advert); err != nil {
+		t.Fatalf("failed to process advert: %v", err)
+	}
+
+	routes, err := r.table.List()
+	if err != nil {
+		t.Fatalf("failed to list routes: %v", err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("expected only the foo route to be learned, got %v", routes)
+	}
+	if route := routes[0]; route.Service != "foo" || route.Origin != "r2" || route.Hops != 2 {
+		t.Errorf("expected foo learned from r2 over 2 hops, got %s from %s over %d hops", route.Service, route.Origin, route.Hops)
+	}
+}
+
+func TestRouterRegistries(t *testing.T) {
+	onprem := memory.NewRegistry()
+	cloud := memory.NewRegistry()
+
+	r := newRouter().(*router)
+	if err := r.Init(Registry(onprem, cloud), RouteTTL(0)); err != nil {
+		t.Fatalf("failed to start router: %v", err)
+	}
+	defer r.Close()
+
+	register := func(reg registry.Registry, address string) {
+		svc := &registry.Service{
+			Name:    "foo",
+			Version: "1.0.0",
+			Nodes:   []*registry.Node{{Id: address, Address: address}},
+		}
+		if err := reg.Register(svc); err != nil {
+			t.Fatalf("failed to register: %v", err)
+		}
+	}
+	register(onprem, "10.0.0.1:8080")
+	register(cloud, "10.1.0.1:8080")
+
+	// wait for both watch streams to be merged into the table
+	var routes []Route
+	for i := 0; i < 100; i++ {
+		routes, _ = r.table.List()
+		if len(routes) == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected a route from each registry, got %v", routes)
+	}
+
+	sources := map[string]string{}
+	for _, route := range routes {
+		sources[route.Address] = route.Metadata[RegistryLabel]
+	}
+	if sources["10.0.0.1:8080"] != "memory" || sources["10.1.0.1:8080"] != "memory-1" {
+		t.Errorf("expected the routes tagged with their registry, got %v", sources)
+	}
+
+	// the routes of a registry can be selected by its label
+	found, err := r.Lookup(QueryService("foo"), QueryMetadata(map[string]string{RegistryLabel: "memory-1"}))
+	if err != nil {
+		t.Fatalf("failed to lookup routes: %v", err)
+	}
+	if len(found) != 1 || found[0].Address != "10.1.0.1:8080" {
+		t.Errorf("expected the cloud route, got %v", found)
+	}
+
+	if status := r.Status(); !status.Watching {
+		t.Errorf("expected both registries to be watched")
+	}
+}
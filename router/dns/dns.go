@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/micro/go-micro/v2/router"
 )
@@ -41,7 +42,7 @@ func (d *dns) Table() router.Table {
 	return d.table
 }
 
-func (d *dns) Advertise() (<-chan *router.Advert, error) {
+func (d *dns) Advertise(opts ...router.AdvertiseOption) (<-chan *router.Advert, error) {
 	return nil, nil
 }
 
@@ -85,6 +86,14 @@ func (t *table) List() ([]router.Route, error) {
 	return nil, nil
 }
 
+func (t *table) Dump() (*router.Snapshot, error) {
+	return &router.Snapshot{Timestamp: time.Now()}, nil
+}
+
+func (t *table) Load(*router.Snapshot) error {
+	return nil
+}
+
 func (t *table) Query(opts ...router.QueryOption) ([]router.Route, error) {
 	options := router.NewQuery(opts...)
 
@@ -128,3 +137,11 @@ func (t *table) Query(opts ...router.QueryOption) ([]router.Route, error) {
 	}
 	return result, nil
 }
+
+func (d *dns) Feedback(route router.Route, rtt time.Duration, err error) error {
+	return nil
+}
+
+func (d *dns) Status() router.Status {
+	return router.Status{Code: router.Running}
+}
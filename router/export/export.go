@@ -0,0 +1,111 @@
+// Package export encodes routing tables in standard formats so they can be
+// diffed between routers, replayed in tests and inspected by tooling
+package export
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/micro/go-micro/v2/router"
+	pb "github.com/micro/go-micro/v2/router/service/proto"
+	pbUtil "github.com/micro/go-micro/v2/util/proto"
+)
+
+// Format is a routing table export format
+type Format string
+
+const (
+	// JSON is the protobuf JSON mapping of the snapshot
+	JSON Format = "json"
+	// Protobuf is the protobuf wire encoding of the snapshot
+	Protobuf Format = "protobuf"
+)
+
+var (
+	// ErrUnknownFormat is returned for unsupported export formats
+	ErrUnknownFormat = errors.New("unknown export format")
+)
+
+// Export takes a snapshot of the routing table and encodes it in the given format
+func Export(t router.Table, f Format) ([]byte, error) {
+	snapshot, err := t.Dump()
+	if err != nil {
+		return nil, err
+	}
+
+	return Marshal(snapshot, f)
+}
+
+// Import decodes the exported routing table and loads its routes into the table
+func Import(t router.Table, b []byte, f Format) error {
+	snapshot, err := Unmarshal(b, f)
+	if err != nil {
+		return err
+	}
+
+	return t.Load(snapshot)
+}
+
+// Marshal encodes the snapshot in the given format. The routes are sorted
+// and the encoding is deterministic so exports of equal tables are equal.
+func Marshal(s *router.Snapshot, f Format) ([]byte, error) {
+	routes := make([]router.Route, len(s.Routes))
+	copy(routes, s.Routes)
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Service != routes[j].Service {
+			return routes[i].Service < routes[j].Service
+		}
+		if routes[i].Address != routes[j].Address {
+			return routes[i].Address < routes[j].Address
+		}
+		return routes[i].Hash() < routes[j].Hash()
+	})
+
+	msg := pbUtil.SnapshotToProto(&router.Snapshot{
+		Timestamp: s.Timestamp,
+		Routes:    routes,
+	})
+
+	switch f {
+	case JSON:
+		var buf bytes.Buffer
+		m := &jsonpb.Marshaler{OrigName: true, Indent: "  "}
+		if err := m.Marshal(&buf, msg); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Protobuf:
+		buf := proto.NewBuffer(nil)
+		buf.SetDeterministic(true)
+		if err := buf.Marshal(msg); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Unmarshal decodes a snapshot encoded in the given format
+func Unmarshal(b []byte, f Format) (*router.Snapshot, error) {
+	msg := new(pb.Snapshot)
+
+	switch f {
+	case JSON:
+		if err := jsonpb.Unmarshal(bytes.NewReader(b), msg); err != nil {
+			return nil, err
+		}
+	case Protobuf:
+		if err := proto.Unmarshal(b, msg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	return pbUtil.ProtoToSnapshot(msg), nil
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/router"
+)
+
+func TestMarshal(t *testing.T) {
+	snapshot := &router.Snapshot{
+		Timestamp: time.Unix(1600000000, 0),
+		Routes: []router.Route{
+			{Service: "foo", Address: "10.0.0.2:8080", Link: "local", Metric: 1, Metadata: map[string]string{"registry": "mdns"}},
+			{Service: "bar", Address: "10.0.0.3:8080", Link: "network", Metric: 10, Router: "r1", Origin: "r2", Hops: 1},
+			{Service: "foo", Address: "10.0.0.1:8080", Link: "local", Metric: 1},
+		},
+	}
+
+	// the same routes in another order
+	reordered := &router.Snapshot{
+		Timestamp: snapshot.Timestamp,
+		Routes:    []router.Route{snapshot.Routes[2], snapshot.Routes[0], snapshot.Routes[1]},
+	}
+
+	for _, f := range []Format{JSON, Protobuf} {
+		b, err := Marshal(snapshot, f)
+		if err != nil {
+			t.Fatalf("%s: unexpected marshal error: %v", f, err)
+		}
+
+		rb, err := Marshal(reordered, f)
+		if err != nil {
+			t.Fatalf("%s: unexpected marshal error: %v", f, err)
+		}
+		if !bytes.Equal(b, rb) {
+			t.Errorf("%s: expected equal exports of equal tables", f)
+		}
+
+		s, err := Unmarshal(b, f)
+		if err != nil {
+			t.Fatalf("%s: unexpected unmarshal error: %v", f, err)
+		}
+		if !s.Timestamp.Equal(snapshot.Timestamp) {
+			t.Errorf("%s: expected timestamp %v, got %v", f, snapshot.Timestamp, s.Timestamp)
+		}
+		if len(s.Routes) != 3 {
+			t.Fatalf("%s: expected 3 routes, got %d", f, len(s.Routes))
+		}
+
+		// routes are sorted by service and address
+		if s.Routes[0].Service != "bar" || s.Routes[1].Address != "10.0.0.1:8080" {
+			t.Errorf("%s: unexpected route order: %v", f, s.Routes)
+		}
+		if s.Routes[0].Hash() != snapshot.Routes[1].Hash() || s.Routes[0].Origin != "r2" || s.Routes[0].Hops != 1 {
+			t.Errorf("%s: expected route %v, got %v", f, snapshot.Routes[1], s.Routes[0])
+		}
+		if s.Routes[2].Metadata["registry"] != "mdns" {
+			t.Errorf("%s: expected route metadata to be kept, got %v", f, s.Routes[2].Metadata)
+		}
+	}
+
+	if _, err := Marshal(snapshot, Format("yaml")); err != ErrUnknownFormat {
+		t.Errorf("expected unknown format error, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+var (
+	// FeedbackWeight is the weight given to each new observation in the moving averages
+	FeedbackWeight = 0.2
+	// LatencyMetric is the metric added to a route per millisecond of average latency
+	LatencyMetric = 1.0
+	// ErrorMetric is the metric added to a route which fails every call
+	ErrorMetric = 1000.0
+	// FeedbackDecay is the time the averages take to decay by a factor e
+	// without observations, so the routes avoided get another chance
+	FeedbackDecay = time.Minute
+)
+
+// feedback holds exponentially weighted moving averages of the
+// latency and error rate observed when calling a route
+type feedback struct {
+	sync.RWMutex
+	latency float64
+	errors  float64
+	seen    bool
+	// the time of the last observation
+	updated time.Time
+}
+
+// failed checks if the error reflects on the health of the route. Client errors
+// are the fault of the request rather than the node serving it.
+func failed(err error) bool {
+	if err == nil {
+		return false
+	}
+	if verr, ok := err.(*errors.Error); ok && verr.Code >= 400 && verr.Code < 500 && verr.Code != 408 {
+		return false
+	}
+	return true
+}
+
+// observe adds the result of a call to the averages
+func (f *feedback) observe(now time.Time, rtt time.Duration, err error) {
+	f.Lock()
+	defer f.Unlock()
+
+	ms := float64(rtt) / float64(time.Millisecond)
+	var e float64
+	if failed(err) {
+		e = 1
+	}
+
+	if !f.seen {
+		f.latency = ms
+		f.errors = e
+		f.seen = true
+		f.updated = now
+		return
+	}
+
+	latency, errors := f.decayed(now)
+	f.latency = FeedbackWeight*ms + (1-FeedbackWeight)*latency
+	f.errors = FeedbackWeight*e + (1-FeedbackWeight)*errors
+	f.updated = now
+}
+
+// decayed returns the averages decayed since the last observation. Should be
+// called under lock.
+func (f *feedback) decayed(now time.Time) (float64, float64) {
+	elapsed := now.Sub(f.updated)
+	if elapsed <= 0 || FeedbackDecay <= 0 {
+		return f.latency, f.errors
+	}
+	d := math.Exp(-float64(elapsed) / float64(FeedbackDecay))
+	return f.latency * d, f.errors * d
+}
+
+// metric returns the metric adjusted by the averages
+func (f *feedback) metric(now time.Time, metric int64) int64 {
+	f.RLock()
+	defer f.RUnlock()
+
+	latency, errors := f.decayed(now)
+	return metric + int64(latency*LatencyMetric+errors*ErrorMetric)
+}
@@ -0,0 +1,188 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidSelector is returned when a label selector can't be parsed
+	ErrInvalidSelector = errors.New("invalid selector")
+)
+
+// Operator is the comparison a requirement makes against route metadata
+type Operator int
+
+const (
+	// Equals matches routes with the key set to the value
+	Equals Operator = iota
+	// NotEquals matches routes without the key set to the value
+	NotEquals
+	// In matches routes with the key set to one of the values
+	In
+	// NotIn matches routes without the key set to any of the values
+	NotIn
+	// Exists matches routes with the key set
+	Exists
+	// DoesNotExist matches routes without the key set
+	DoesNotExist
+)
+
+// Requirement is a single condition on route metadata
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+// Matches returns true if the metadata meets the requirement
+func (r Requirement) Matches(md map[string]string) bool {
+	v, ok := md[r.Key]
+
+	switch r.Operator {
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	case Equals, In:
+		return ok && contains(r.Values, v)
+	case NotEquals, NotIn:
+		return !ok || !contains(r.Values, v)
+	}
+
+	return false
+}
+
+// Selector is a set of requirements which route metadata must all meet
+type Selector []Requirement
+
+// Matches returns true if the metadata meets every requirement
+func (s Selector) Matches(md map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(md) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSelector parses a comma separated label selector e.g.
+// "region=eu-west,canary!=true,tier in (web, api),!legacy"
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+
+	for _, term := range splitTerms(s) {
+		term = strings.TrimSpace(term)
+		if len(term) == 0 {
+			continue
+		}
+
+		r, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, r)
+	}
+
+	return sel, nil
+}
+
+// parseRequirement parses a single selector term
+func parseRequirement(term string) (Requirement, error) {
+	invalid := fmt.Errorf("%w: %q", ErrInvalidSelector, term)
+
+	// set based requirements e.g. tier in (web, api)
+	if i := strings.Index(term, "("); i > 0 {
+		if !strings.HasSuffix(term, ")") {
+			return Requirement{}, invalid
+		}
+
+		fields := strings.Fields(term[:i])
+		if len(fields) != 2 {
+			return Requirement{}, invalid
+		}
+
+		var op Operator
+		switch fields[1] {
+		case "in":
+			op = In
+		case "notin":
+			op = NotIn
+		default:
+			return Requirement{}, invalid
+		}
+
+		var values []string
+		for _, v := range strings.Split(term[i+1:len(term)-1], ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+
+		return Requirement{Key: fields[0], Operator: op, Values: values}, nil
+	}
+
+	// equality based requirements, checking != and == before =
+	for _, o := range []struct {
+		sep string
+		op  Operator
+	}{
+		{"!=", NotEquals},
+		{"==", Equals},
+		{"=", Equals},
+	} {
+		if i := strings.Index(term, o.sep); i >= 0 {
+			key := strings.TrimSpace(term[:i])
+			if len(key) == 0 {
+				return Requirement{}, invalid
+			}
+			val := strings.TrimSpace(term[i+len(o.sep):])
+			return Requirement{Key: key, Operator: o.op, Values: []string{val}}, nil
+		}
+	}
+
+	// existence requirements e.g. canary or !legacy
+	if strings.HasPrefix(term, "!") {
+		key := strings.TrimSpace(term[1:])
+		if len(key) == 0 {
+			return Requirement{}, invalid
+		}
+		return Requirement{Key: key, Operator: DoesNotExist}, nil
+	}
+
+	if strings.ContainsAny(term, " ()") {
+		return Requirement{}, invalid
+	}
+
+	return Requirement{Key: term, Operator: Exists}, nil
+}
+
+// splitTerms splits the selector on the commas not inside a set of values
+func splitTerms(s string) []string {
+	var terms []string
+	var depth, start int
+
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(terms, s[start:])
+}
+
+func contains(values []string, v string) bool {
+	for _, val := range values {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	md := map[string]string{"region": "eu-west", "canary": "true", "tier": "web"}
+
+	testData := []struct {
+		selector string
+		match    bool
+	}{
+		{"", true},
+		{"region=eu-west", true},
+		{"region==eu-west", true},
+		{"region!=eu-west", false},
+		{"region=eu-west,canary=true", true},
+		{"region=eu-west,canary=false", false},
+		{"tier in (web, api)", true},
+		{"tier notin (web, api)", false},
+		{"canary", true},
+		{"!canary", false},
+		{"!legacy", true},
+		{"legacy!=true", true},
+		{"tier in (web,api), region = eu-west", true},
+	}
+
+	for _, d := range testData {
+		sel, err := ParseSelector(d.selector)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", d.selector, err)
+			continue
+		}
+		if m := sel.Matches(md); m != d.match {
+			t.Errorf("%q: expected match %t, got %t", d.selector, d.match, m)
+		}
+	}
+
+	for _, s := range []string{"=foo", "tier in web", "tier maybe (web)", "!", "tier (web"} {
+		if _, err := ParseSelector(s); !errors.Is(err, ErrInvalidSelector) {
+			t.Errorf("%q: expected invalid selector, got %v", s, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/micro/go-micro/v2/registry"
@@ -19,12 +20,28 @@ type Options struct {
 	Network string
 	// Registry is the local registry
 	Registry registry.Registry
+	// Registries are all the registries local routes are built from
+	Registries []registry.Registry
 	// Advertise is the advertising strategy
-	Advertise Strategy
+	Advertise AdvertStrategy
 	// Context for additional options
 	Context context.Context
 	// Prewarm the route table on router startup
 	Prewarm bool
+	// Bootstrap is the table of a peer the route table is loaded from on startup
+	Bootstrap Table
+	// RouteTTL is how long a local route lives without being refreshed
+	RouteTTL time.Duration
+	// MaxHops is the max number of routers a learned route may go through
+	MaxHops int64
+	// FlushSize is the max number of events sent in a single advert
+	FlushSize int
+	// FlushInterval is how often pending route events are advertised
+	FlushInterval time.Duration
+	// AdvertTTL is the TTL of the published adverts
+	AdvertTTL time.Duration
+	// Damping is the route flap damping of advertised routes
+	Damping Damping
 }
 
 // Id sets Router Id
@@ -55,15 +72,21 @@ func Network(n string) Option {
 	}
 }
 
-// Registry sets the local registry
-func Registry(r registry.Registry) Option {
+// Registry sets the local registry. Given more than one registry the router
+// merges the services of them all into its table, tagging each route with
+// the registry it came from, and the first is used as the local registry.
+func Registry(r ...registry.Registry) Option {
 	return func(o *Options) {
-		o.Registry = r
+		if len(r) == 0 {
+			return
+		}
+		o.Registry = r[0]
+		o.Registries = r
 	}
 }
 
 // Advertise sets route advertising strategy
-func Advertise(a Strategy) Option {
+func Advertise(a AdvertStrategy) Option {
 	return func(o *Options) {
 		o.Advertise = a
 	}
@@ -76,6 +99,68 @@ func Prewarm(b bool) Option {
 	}
 }
 
+// Bootstrap sets the table of a peer router, e.g. one served by a router service,
+// whose snapshot is loaded into the route table on startup instead of prewarming
+// it from the registry.
+func Bootstrap(t Table) Option {
+	return func(o *Options) {
+		o.Bootstrap = t
+	}
+}
+
+// RouteTTL sets how long a route from the local registry lives without being
+// refreshed before it's pruned. A ttl of zero disables pruning.
+func RouteTTL(t time.Duration) Option {
+	return func(o *Options) {
+		o.RouteTTL = t
+	}
+}
+
+// FlushSize sets the max number of events in a single advert. Pending events are
+// advertised as soon as this many have built up and larger adverts, such as the
+// announce advert, are split. A size of zero means no limit.
+func FlushSize(n int) Option {
+	return func(o *Options) {
+		o.FlushSize = n
+	}
+}
+
+// FlushInterval sets how often pending route events are coalesced and advertised
+func FlushInterval(t time.Duration) Option {
+	return func(o *Options) {
+		o.FlushInterval = t
+	}
+}
+
+// AdvertiseInterval sets how often the router advertises route updates.
+// It's the same as FlushInterval.
+func AdvertiseInterval(t time.Duration) Option {
+	return FlushInterval(t)
+}
+
+// AdvertTTL sets the TTL of the adverts published by the router
+func AdvertTTL(t time.Duration) Option {
+	return func(o *Options) {
+		o.AdvertTTL = t
+	}
+}
+
+// FlapDamping sets the route flap damping thresholds. Routes which change too
+// often are suppressed from the adverts until they settle.
+func FlapDamping(d Damping) Option {
+	return func(o *Options) {
+		o.Damping = d
+	}
+}
+
+// MaxHops sets the max number of routers a route learned from an advert may
+// have gone through. A max of zero means no limit.
+func MaxHops(n int64) Option {
+	return func(o *Options) {
+		o.MaxHops = n
+	}
+}
+
 // DefaultOptions returns router default options
 func DefaultOptions() Options {
 	return Options{
@@ -85,5 +170,9 @@ func DefaultOptions() Options {
 		Registry:  registry.DefaultRegistry,
 		Advertise: AdvertiseLocal,
 		Context:   context.Background(),
+		RouteTTL:  DefaultRouteTTL,
+		FlushSize: DefaultFlushSize,
+		MaxHops:   DefaultMaxHops,
+		AdvertTTL: DefaultAdvertTTL,
 	}
 }
@@ -19,6 +19,10 @@ type QueryOptions struct {
 	Router string
 	// Strategy is routing strategy
 	Strategy Strategy
+	// MaxMetric is the highest route metric to return, zero for no limit
+	MaxMetric int64
+	// Selector is the requirements the route metadata must meet
+	Selector Selector
 }
 
 func QueryVersion(s string) QueryOption {
@@ -67,6 +71,29 @@ func QueryStrategy(s Strategy) QueryOption {
 	}
 }
 
+// QueryMaxMetric only returns routes with a metric of at most n
+func QueryMaxMetric(n int64) QueryOption {
+	return func(o *QueryOptions) {
+		o.MaxMetric = n
+	}
+}
+
+// QueryMetadata only returns routes with metadata matching all the key values
+func QueryMetadata(md map[string]string) QueryOption {
+	return func(o *QueryOptions) {
+		for k, v := range md {
+			o.Selector = append(o.Selector, Requirement{Key: k, Operator: Equals, Values: []string{v}})
+		}
+	}
+}
+
+// QuerySelector only returns routes with metadata meeting the selector
+func QuerySelector(s Selector) QueryOption {
+	return func(o *QueryOptions) {
+		o.Selector = append(o.Selector, s...)
+	}
+}
+
 // NewQuery creates new query and returns it
 func NewQuery(opts ...QueryOption) QueryOptions {
 	// default options
@@ -2,6 +2,7 @@ package router
 
 import (
 	"hash/fnv"
+	"strconv"
 )
 
 var (
@@ -9,6 +10,8 @@ var (
 	DefaultLink = "local"
 	// DefaultLocalMetric is default route cost for a local route
 	DefaultLocalMetric int64 = 1
+	// DefaultWeight is the weight of a route without weight metadata
+	DefaultWeight int64 = 1
 )
 
 // Route is network route
@@ -31,12 +34,58 @@ type Route struct {
 	Metric int64
 	// Metadata for the route
 	Metadata map[string]string
+	// Origin is the id of the router the route was learned from
+	Origin string
+	// Hops is the number of routers the route was advertised through
+	Hops int64
 }
 
-// Hash returns route hash sum.
+// Hash returns route hash sum. The source label is part of the hash so the
+// same node found in different sources yields distinct routes, the rest of the
+// metadata isn't so updating it e.g. the weight replaces the route.
 func (r *Route) Hash() uint64 {
 	h := fnv.New64()
 	h.Reset()
 	h.Write([]byte(r.Service + r.Version + r.Address + r.Gateway + r.Network + r.Router + r.Link))
+
+	if src, ok := r.Metadata[RegistryLabel]; ok {
+		h.Write([]byte{0})
+		h.Write([]byte(src))
+	}
+
 	return h.Sum64()
 }
+
+// LearnRoute returns the route as learned by the router with the given options
+// from the advert of a peer. It returns false for routes which must not be
+// learned to prevent routing loops: the routers own routes, routes the peer
+// learned from the router (split horizon) and routes over the max hop count.
+func LearnRoute(route Route, peer string, opts Options) (Route, bool) {
+	if route.Router == opts.Id || route.Origin == opts.Id {
+		return route, false
+	}
+
+	route.Hops++
+	if opts.MaxHops > 0 && route.Hops > opts.MaxHops {
+		return route, false
+	}
+	route.Origin = peer
+
+	return route, true
+}
+
+// Weight returns the route weight set in the "weight" metadata. Routes with
+// the same metric are ordered by weight, the highest weight first.
+func (r *Route) Weight() int64 {
+	return ParseWeight(r.Metadata["weight"])
+}
+
+// ParseWeight parses the weight of a route. A weight of 0 drains the route, a
+// missing, invalid or negative weight is the DefaultWeight.
+func ParseWeight(v string) int64 {
+	w, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || w < 0 {
+		return DefaultWeight
+	}
+	return w
+}
@@ -22,3 +22,58 @@ func TestHash(t *testing.T) {
 		t.Errorf("identical routes result in different hashes")
 	}
 }
+
+func TestHashMetadata(t *testing.T) {
+	route1 := Route{
+		Service:  "dest.svc",
+		Address:  "10.0.0.1:8080",
+		Link:     DefaultLink,
+		Metadata: map[string]string{RegistryLabel: "mdns", "weight": "2"},
+	}
+
+	route2 := route1
+	route2.Metadata = map[string]string{"weight": "2", RegistryLabel: "mdns"}
+
+	if route1.Hash() != route2.Hash() {
+		t.Errorf("routes with the same metadata result in different hashes")
+	}
+
+	route2.Metadata = map[string]string{RegistryLabel: "etcd", "weight": "2"}
+
+	if route1.Hash() == route2.Hash() {
+		t.Errorf("routes from different sources result in the same hash")
+	}
+
+	route2.Metadata = map[string]string{RegistryLabel: "mdns", "weight": "0"}
+
+	if route1.Hash() != route2.Hash() {
+		t.Errorf("routes with different weights result in different hashes")
+	}
+}
+
+func TestWeight(t *testing.T) {
+	testData := map[string]int64{
+		"":     DefaultWeight,
+		"foo":  DefaultWeight,
+		"-1":   DefaultWeight,
+		"0":    0,
+		"5":    5,
+		"1000": 1000,
+	}
+
+	for v, want := range testData {
+		if got := ParseWeight(v); got != want {
+			t.Errorf("Expected weight %q to be %d, got %d", v, want, got)
+		}
+	}
+
+	// the weight of a route without metadata is the default
+	route := Route{Service: "dest.svc"}
+	if w := route.Weight(); w != DefaultWeight {
+		t.Errorf("Expected the default weight, got %d", w)
+	}
+	route.Metadata = map[string]string{"weight": "0"}
+	if w := route.Weight(); w != 0 {
+		t.Errorf("Expected a drained route, got weight %d", w)
+	}
+}
@@ -3,6 +3,8 @@ package router
 
 import (
 	"time"
+
+	"github.com/micro/go-micro/v2/logger"
 )
 
 var (
@@ -14,6 +16,9 @@ var (
 	DefaultNetwork = "micro"
 	// DefaultRouter is default network router
 	DefaultRouter Router = nil
+
+	// log of the router module
+	log = logger.NewModule("router")
 )
 
 // Router is an interface for a routing control plane
@@ -25,13 +30,17 @@ type Router interface {
 	// The routing table
 	Table() Table
 	// Advertise advertises routes
-	Advertise() (<-chan *Advert, error)
+	Advertise(...AdvertiseOption) (<-chan *Advert, error)
 	// Process processes incoming adverts
 	Process(*Advert) error
 	// Lookup queries routes in the routing table
 	Lookup(...QueryOption) ([]Route, error)
+	// Feedback reports the latency and error of a call made using a route
+	Feedback(Route, time.Duration, error) error
 	// Watch returns a watcher which tracks updates to the routing table
 	Watch(opts ...WatchOption) (Watcher, error)
+	// Status returns the state of the router
+	Status() Status
 	// Close the router
 	Close() error
 	// Returns the router implementation
@@ -50,6 +59,19 @@ type Table interface {
 	List() ([]Route, error)
 	// Query routes in the routing table
 	Query(...QueryOption) ([]Route, error)
+	// Dump takes a snapshot of the routing table
+	Dump() (*Snapshot, error)
+	// Load creates or updates the routes of a snapshot in the routing table,
+	// deleting the other routes of the services of the snapshot
+	Load(*Snapshot) error
+}
+
+// Snapshot is a point in time copy of a routing table
+type Snapshot struct {
+	// Timestamp is when the snapshot was taken
+	Timestamp time.Time
+	// Routes stored in the table
+	Routes []Route
 }
 
 // Option used by the router
@@ -105,6 +127,14 @@ type Advert struct {
 	Events []*Event
 }
 
+// AdvertStrategy decides which routes the router advertises
+type AdvertStrategy interface {
+	// Advertise returns the routes to advertise out of the given routes
+	Advertise(routes []Route) []Route
+	// String returns the name of the strategy
+	String() string
+}
+
 // Strategy is route advertisement strategy
 type Strategy int
 
@@ -136,6 +166,32 @@ func (s Strategy) String() string {
 	}
 }
 
+// Advertise returns the routes to advertise for the built-in strategy
+func (s Strategy) Advertise(routes []Route) []Route {
+	switch s {
+	case AdvertiseAll:
+		return routes
+	case AdvertiseNone:
+		return nil
+	}
+
+	// group the routes by service to pick the ones to advertise
+	services := make(map[string]map[uint64]Route)
+	for _, route := range routes {
+		if _, ok := services[route.Service]; !ok {
+			services[route.Service] = make(map[uint64]Route)
+		}
+		services[route.Service][route.Hash()] = route
+	}
+
+	var results []Route
+	for _, r := range services {
+		results = append(results, findRoutes(r, "*", "*", "*", "*", s, nil)...)
+	}
+
+	return results
+}
+
 // NewRouter creates new Router and returns it
 func NewRouter(opts ...Option) Router {
 	return newRouter(opts...)
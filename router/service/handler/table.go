@@ -0,0 +1,118 @@
+// Package handler implements the routing table handler served by router services
+package handler
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/router"
+	pb "github.com/micro/go-micro/v2/router/service/proto"
+	"github.com/micro/go-micro/v2/server"
+	pbUtil "github.com/micro/go-micro/v2/util/proto"
+)
+
+// NewTable returns a handler which serves the given routing table
+func NewTable(t router.Table) *Table {
+	return &Table{table: t}
+}
+
+// RegisterTable serves the routing table with the server as the table of a
+// router service, e.g. for peers to bootstrap from with router.Bootstrap
+func RegisterTable(s server.Server, t router.Table, opts ...server.HandlerOption) error {
+	return pb.RegisterTableHandler(s, NewTable(t), opts...)
+}
+
+type Table struct {
+	// must honour the table handler
+	pb.TableHandler
+	// the served routing table
+	table router.Table
+}
+
+func (t *Table) Create(ctx context.Context, route *pb.Route, resp *pb.CreateResponse) error {
+	if err := t.table.Create(pbUtil.ProtoToRoute(route)); err != nil && err != router.ErrDuplicateRoute {
+		return errors.InternalServerError("go.micro.router", "failed to create route: %s", err)
+	}
+
+	return nil
+}
+
+func (t *Table) Delete(ctx context.Context, route *pb.Route, resp *pb.DeleteResponse) error {
+	if err := t.table.Delete(pbUtil.ProtoToRoute(route)); err != nil && err != router.ErrRouteNotFound {
+		return errors.InternalServerError("go.micro.router", "failed to delete route: %s", err)
+	}
+
+	return nil
+}
+
+func (t *Table) Update(ctx context.Context, route *pb.Route, resp *pb.UpdateResponse) error {
+	if err := t.table.Update(pbUtil.ProtoToRoute(route)); err != nil {
+		return errors.InternalServerError("go.micro.router", "failed to update route: %s", err)
+	}
+
+	return nil
+}
+
+func (t *Table) List(ctx context.Context, req *pb.Request, resp *pb.ListResponse) error {
+	routes, err := t.table.List()
+	if err != nil {
+		return errors.InternalServerError("go.micro.router", "failed to list routes: %s", err)
+	}
+
+	resp.Routes = make([]*pb.Route, 0, len(routes))
+	for _, route := range routes {
+		resp.Routes = append(resp.Routes, pbUtil.RouteToProto(route))
+	}
+
+	return nil
+}
+
+func (t *Table) Query(ctx context.Context, req *pb.QueryRequest, resp *pb.QueryResponse) error {
+	var opts []router.QueryOption
+	if q := req.Query; q != nil {
+		if len(q.Service) > 0 {
+			opts = append(opts, router.QueryService(q.Service))
+		}
+		if len(q.Gateway) > 0 {
+			opts = append(opts, router.QueryGateway(q.Gateway))
+		}
+		if len(q.Network) > 0 {
+			opts = append(opts, router.QueryNetwork(q.Network))
+		}
+	}
+
+	routes, err := t.table.Query(opts...)
+	if err != nil && err != router.ErrRouteNotFound {
+		return errors.InternalServerError("go.micro.router", "failed to lookup routes: %s", err)
+	}
+
+	resp.Routes = make([]*pb.Route, 0, len(routes))
+	for _, route := range routes {
+		resp.Routes = append(resp.Routes, pbUtil.RouteToProto(route))
+	}
+
+	return nil
+}
+
+// Dump returns a snapshot of the routing table, e.g. to bootstrap another router
+func (t *Table) Dump(ctx context.Context, req *pb.Request, resp *pb.Snapshot) error {
+	snap, err := t.table.Dump()
+	if err != nil {
+		return errors.InternalServerError("go.micro.router", "failed to dump routes: %s", err)
+	}
+
+	s := pbUtil.SnapshotToProto(snap)
+	resp.Timestamp = s.Timestamp
+	resp.Routes = s.Routes
+
+	return nil
+}
+
+// Load loads the routes of a snapshot into the routing table
+func (t *Table) Load(ctx context.Context, req *pb.Snapshot, resp *pb.LoadResponse) error {
+	if err := t.table.Load(pbUtil.ProtoToSnapshot(req)); err != nil {
+		return errors.InternalServerError("go.micro.router", "failed to load routes: %s", err)
+	}
+
+	return nil
+}
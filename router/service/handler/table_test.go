@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"testing"
+
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/registry"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/router/service"
+	"github.com/micro/go-micro/v2/router/service/handler"
+	"github.com/micro/go-micro/v2/server"
+	tmemory "github.com/micro/go-micro/v2/transport/memory"
+)
+
+func TestTableSnapshot(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	tr := tmemory.NewTransport()
+
+	// the table served by the router service
+	served := router.NewRouter(router.Registry(reg)).Table()
+	route := router.Route{
+		Service: "foo",
+		Address: "10.0.0.1:8080",
+		Gateway: "*",
+		Network: registry.DefaultDomain,
+		Router:  "peer",
+		Link:    "local",
+		Metric:  10,
+	}
+	if err := served.Create(route); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := server.NewServer(
+		server.Name(router.DefaultName),
+		server.Address("127.0.0.1:0"),
+		server.Broker(bmemory.NewBroker()),
+		server.Registry(reg),
+		server.Transport(tr),
+	)
+	if err := handler.RegisterTable(srv, served); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Transport(tr),
+	)
+	peer := service.NewRouter(router.Address(srv.Options().Address), service.Client(cli)).Table()
+
+	// dump the served table
+	snap, err := peer.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Routes) != 1 || snap.Routes[0].Address != route.Address {
+		t.Fatalf("Expected the served route, got %+v", snap.Routes)
+	}
+
+	// load a snapshot into the served table
+	moved := route
+	moved.Address = "10.0.0.2:8080"
+	if err := peer.Load(&router.Snapshot{Routes: []router.Route{moved}}); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := served.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Address != moved.Address {
+		t.Fatalf("Expected the loaded route to replace the route of the service, got %+v", routes)
+	}
+}
@@ -724,10 +724,14 @@ type Route struct {
 	// the metric / score of this route
 	Metric int64 `protobuf:"varint,7,opt,name=metric,proto3" json:"metric,omitempty"`
 	// metadata for the route
-	Metadata             map[string]string `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Metadata map[string]string `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// the router the route was learned from
+	Origin string `protobuf:"bytes,9,opt,name=origin,proto3" json:"origin,omitempty"`
+	// the number of routers the route was advertised through
+	Hops                 int64    `protobuf:"varint,10,opt,name=hops,proto3" json:"hops,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Route) Reset()         { *m = Route{} }
@@ -811,6 +815,98 @@ func (m *Route) GetMetadata() map[string]string {
 	return nil
 }
 
+func (m *Route) GetOrigin() string {
+	if m != nil {
+		return m.Origin
+	}
+	return ""
+}
+
+func (m *Route) GetHops() int64 {
+	if m != nil {
+		return m.Hops
+	}
+	return 0
+}
+
+type Snapshot struct {
+	Timestamp            int64    `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Routes               []*Route `protobuf:"bytes,2,rep,name=routes,proto3" json:"routes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Snapshot) Reset()         { *m = Snapshot{} }
+func (m *Snapshot) String() string { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()    {}
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3123ad01af3cc940, []int{16}
+}
+
+func (m *Snapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Snapshot.Unmarshal(m, b)
+}
+func (m *Snapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Snapshot.Marshal(b, m, deterministic)
+}
+func (m *Snapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Snapshot.Merge(m, src)
+}
+func (m *Snapshot) XXX_Size() int {
+	return xxx_messageInfo_Snapshot.Size(m)
+}
+func (m *Snapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_Snapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Snapshot proto.InternalMessageInfo
+
+func (m *Snapshot) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Snapshot) GetRoutes() []*Route {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+type LoadResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LoadResponse) Reset()         { *m = LoadResponse{} }
+func (m *LoadResponse) String() string { return proto.CompactTextString(m) }
+func (*LoadResponse) ProtoMessage()    {}
+func (*LoadResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3123ad01af3cc940, []int{17}
+}
+
+func (m *LoadResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoadResponse.Unmarshal(m, b)
+}
+func (m *LoadResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoadResponse.Marshal(b, m, deterministic)
+}
+func (m *LoadResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoadResponse.Merge(m, src)
+}
+func (m *LoadResponse) XXX_Size() int {
+	return xxx_messageInfo_LoadResponse.Size(m)
+}
+func (m *LoadResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoadResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoadResponse proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterEnum("go.micro.router.AdvertType", AdvertType_name, AdvertType_value)
 	proto.RegisterEnum("go.micro.router.EventType", EventType_name, EventType_value)
@@ -831,58 +927,65 @@ func init() {
 	proto.RegisterType((*Query)(nil), "go.micro.router.Query")
 	proto.RegisterType((*Route)(nil), "go.micro.router.Route")
 	proto.RegisterMapType((map[string]string)(nil), "go.micro.router.Route.MetadataEntry")
+	proto.RegisterType((*Snapshot)(nil), "go.micro.router.Snapshot")
+	proto.RegisterType((*LoadResponse)(nil), "go.micro.router.LoadResponse")
 }
 
 func init() { proto.RegisterFile("router/service/proto/router.proto", fileDescriptor_3123ad01af3cc940) }
 
 var fileDescriptor_3123ad01af3cc940 = []byte{
-	// 728 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xcb, 0x4e, 0xdb, 0x5c,
-	0x10, 0xb6, 0x9d, 0x38, 0x21, 0xf3, 0x87, 0x90, 0xff, 0xe8, 0x17, 0xbf, 0x95, 0x16, 0x9a, 0x5a,
-	0x5d, 0x20, 0x44, 0xed, 0x2a, 0xdd, 0x20, 0xe8, 0x85, 0x4b, 0xa9, 0x2a, 0x95, 0x4a, 0xad, 0x05,
-	0xaa, 0xd4, 0x9d, 0x89, 0x47, 0xc1, 0x4a, 0x62, 0x9b, 0xe3, 0xe3, 0xa0, 0x3c, 0x47, 0x9f, 0xa1,
-	0x8b, 0x6e, 0xfb, 0x4a, 0x7d, 0x91, 0xea, 0x5c, 0x0c, 0x49, 0x1c, 0xa3, 0xc2, 0xca, 0x73, 0xfd,
-	0xe6, 0x72, 0x66, 0xc6, 0xf0, 0x94, 0xc6, 0x19, 0x43, 0xea, 0xa6, 0x48, 0x27, 0x61, 0x1f, 0xdd,
-	0x84, 0xc6, 0x2c, 0x76, 0xa5, 0xd0, 0x11, 0x0c, 0x59, 0x1b, 0xc4, 0xce, 0x38, 0xec, 0xd3, 0xd8,
-	0x91, 0x62, 0xbb, 0x01, 0x75, 0x0f, 0xaf, 0x32, 0x4c, 0x99, 0x0d, 0xb0, 0xe2, 0x61, 0x9a, 0xc4,
-	0x51, 0x8a, 0xf6, 0x1b, 0x68, 0x9e, 0x86, 0x29, 0xcb, 0x79, 0xe2, 0x40, 0x4d, 0x38, 0xa4, 0x96,
-	0xde, 0xad, 0x6c, 0xfd, 0xd3, 0x5b, 0x77, 0x16, 0x80, 0x1c, 0x8f, 0x7f, 0x3c, 0x65, 0x65, 0xbf,
-	0x86, 0xd5, 0xd3, 0x38, 0x1e, 0x66, 0x89, 0x02, 0x27, 0x3b, 0x60, 0x5e, 0x65, 0x48, 0xa7, 0x96,
-	0xde, 0xd5, 0x97, 0xfa, 0x7f, 0xe1, 0x5a, 0x4f, 0x1a, 0xd9, 0x07, 0xd0, 0xca, 0xdd, 0x1f, 0x98,
-	0xc0, 0x2b, 0x68, 0x4a, 0xc4, 0x07, 0xc5, 0x7f, 0x0b, 0xab, 0xca, 0xfb, 0x81, 0xe1, 0x5b, 0xd0,
-	0xfc, 0xea, 0xb3, 0xfe, 0x65, 0xde, 0xdb, 0x9f, 0x3a, 0xd4, 0x0e, 0x83, 0x09, 0x52, 0x46, 0x5a,
-	0x60, 0x84, 0x81, 0x48, 0xa3, 0xe1, 0x19, 0x61, 0x40, 0x5c, 0xa8, 0xb2, 0x69, 0x82, 0x96, 0xd1,
-	0xd5, 0xb7, 0x5a, 0xbd, 0x47, 0x05, 0x60, 0xe9, 0x76, 0x36, 0x4d, 0xd0, 0x13, 0x86, 0xe4, 0x31,
-	0x34, 0x58, 0x38, 0xc6, 0x94, 0xf9, 0xe3, 0xc4, 0xaa, 0x74, 0xf5, 0xad, 0x8a, 0x77, 0x2b, 0x20,
-	0x6d, 0xa8, 0x30, 0x36, 0xb2, 0xaa, 0x42, 0xce, 0x49, 0x9e, 0x3b, 0x4e, 0x30, 0x62, 0xa9, 0x65,
-	0x96, 0xe4, 0x7e, 0xc2, 0xd5, 0x9e, 0xb2, 0xb2, 0xff, 0x85, 0xb5, 0xcf, 0x34, 0xee, 0x63, 0x9a,
-	0xde, 0x8c, 0x43, 0x1b, 0x5a, 0xc7, 0x14, 0x7d, 0x86, 0xb3, 0x92, 0x77, 0x38, 0xc2, 0x79, 0xc9,
-	0x79, 0x12, 0xcc, 0xda, 0x7c, 0xd7, 0xc1, 0x14, 0xd0, 0x85, 0x9a, 0x9d, 0xb9, 0x9a, 0x3b, 0xcb,
-	0x13, 0xfa, 0xeb, 0x92, 0x77, 0xc0, 0x14, 0x7e, 0xa2, 0xe8, 0xf2, 0xb7, 0x91, 0x46, 0xf6, 0x39,
-	0x98, 0xe2, 0x6d, 0x89, 0x05, 0x75, 0xb5, 0x29, 0x2a, 0xb3, 0x9c, 0xe5, 0x9a, 0x81, 0xcf, 0xf0,
-	0xda, 0x9f, 0x8a, 0x0c, 0x1b, 0x5e, 0xce, 0x72, 0x4d, 0x84, 0xec, 0x3a, 0xa6, 0x43, 0x91, 0x46,
-	0xc3, 0xcb, 0x59, 0xfb, 0x97, 0x01, 0xa6, 0x88, 0x73, 0x37, 0xae, 0x1f, 0x04, 0x14, 0xd3, 0x34,
-	0xc7, 0x55, 0xec, 0x6c, 0xc4, 0x4a, 0x69, 0xc4, 0xea, 0x5c, 0x44, 0xb2, 0xae, 0x66, 0x92, 0x5a,
-	0xa6, 0x50, 0x28, 0x8e, 0x10, 0xa8, 0x8e, 0xc2, 0x68, 0x68, 0xd5, 0x84, 0x54, 0xd0, 0xdc, 0x76,
-	0x8c, 0x8c, 0x86, 0x7d, 0xab, 0x2e, 0xba, 0xa7, 0x38, 0x72, 0x00, 0x2b, 0x63, 0x64, 0x7e, 0xe0,
-	0x33, 0xdf, 0x5a, 0x11, 0xd3, 0xf1, 0x6c, 0x79, 0xf7, 0x9c, 0x4f, 0xca, 0xec, 0x24, 0x62, 0x74,
-	0xea, 0xdd, 0x78, 0x75, 0xf6, 0x61, 0x75, 0x4e, 0xc5, 0x07, 0x70, 0x88, 0x53, 0x55, 0x3a, 0x27,
-	0xc9, 0x7f, 0x60, 0x4e, 0xfc, 0x51, 0x86, 0xaa, 0x68, 0xc9, 0xec, 0x19, 0xbb, 0xfa, 0x76, 0x0f,
-	0xe0, 0x76, 0xbc, 0x09, 0x81, 0x96, 0xe4, 0x0e, 0xa3, 0x28, 0xce, 0xa2, 0x3e, 0xb6, 0x35, 0xd2,
-	0x86, 0xa6, 0x94, 0xc9, 0xd9, 0x6a, 0xeb, 0xdb, 0x2e, 0x34, 0x6e, 0xc6, 0x83, 0x00, 0xd4, 0xe4,
-	0x60, 0xb6, 0x35, 0x4e, 0xcb, 0x91, 0x6c, 0xeb, 0x9c, 0x56, 0x0e, 0x46, 0xef, 0x87, 0x01, 0x35,
-	0x4f, 0xb6, 0xe6, 0x23, 0xd4, 0xe4, 0x5d, 0x21, 0x9b, 0x85, 0x32, 0xe7, 0xee, 0x55, 0xe7, 0x49,
-	0xa9, 0x5e, 0x0d, 0xb7, 0x46, 0x8e, 0xc0, 0x14, 0x3b, 0x4e, 0x36, 0x0a, 0xb6, 0xb3, 0xbb, 0xdf,
-	0x29, 0xd9, 0x37, 0x5b, 0x7b, 0xa1, 0x93, 0x23, 0x68, 0xc8, 0xf2, 0xc2, 0x14, 0x89, 0x55, 0x6c,
-	0xbd, 0x82, 0xf8, 0xbf, 0xe4, 0x2a, 0x08, 0x8c, 0xf7, 0x50, 0x57, 0xfb, 0x4a, 0xca, 0xec, 0x3a,
-	0xdd, 0x82, 0x62, 0x71, 0xc5, 0xb5, 0xde, 0x6f, 0x03, 0xcc, 0x33, 0xff, 0x62, 0x84, 0xe4, 0x38,
-	0xef, 0x2a, 0x29, 0xd9, 0xa5, 0x25, 0xed, 0x59, 0xb8, 0x0f, 0x1a, 0x07, 0x91, 0xcf, 0x71, 0x0f,
-	0x90, 0x85, 0x93, 0x22, 0x40, 0xe4, 0x3b, 0xde, 0x03, 0x64, 0xe1, 0x0a, 0x69, 0xe4, 0x10, 0xaa,
-	0xfc, 0x67, 0x76, 0x47, 0x7f, 0x8b, 0x2f, 0x38, 0xfb, 0xf7, 0xb3, 0x35, 0xf2, 0x21, 0x3f, 0x1a,
-	0x1b, 0x25, 0x3f, 0x0e, 0x05, 0xb4, 0x59, 0xa6, 0xce, 0x91, 0x8e, 0xf6, 0xbe, 0xed, 0x0e, 0x42,
-	0x76, 0x99, 0x5d, 0x38, 0xfd, 0x78, 0xec, 0x0a, 0x53, 0x77, 0x10, 0x3f, 0x97, 0xc4, 0xa4, 0xe7,
-	0x2e, 0xfb, 0x87, 0xef, 0x4b, 0xe1, 0x45, 0x4d, 0x70, 0x2f, 0xff, 0x04, 0x00, 0x00, 0xff, 0xff,
-	0xce, 0x3b, 0xf2, 0x50, 0xe9, 0x07, 0x00, 0x00,
+	// 802 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xcb, 0x6e, 0xd3, 0x4c,
+	0x14, 0xb6, 0x9d, 0x38, 0x89, 0xcf, 0x9f, 0xa6, 0xf9, 0x47, 0xbf, 0xfa, 0x9b, 0x40, 0x4b, 0xb0,
+	0x58, 0x54, 0x55, 0xb1, 0x51, 0xd8, 0x54, 0x2d, 0x85, 0x5e, 0x11, 0x12, 0x45, 0x02, 0xd3, 0x0a,
+	0xc4, 0xce, 0x8d, 0x47, 0x89, 0x95, 0xc4, 0xe3, 0xda, 0xe3, 0x54, 0x79, 0x0e, 0x9e, 0x81, 0x05,
+	0x2f, 0xc5, 0x92, 0xe7, 0x40, 0x73, 0x71, 0x9a, 0xc4, 0x71, 0xd5, 0x76, 0x95, 0x73, 0xfd, 0xce,
+	0x99, 0x73, 0x73, 0xe0, 0x59, 0x4c, 0x52, 0x8a, 0x63, 0x27, 0xc1, 0xf1, 0x38, 0xe8, 0x62, 0x27,
+	0x8a, 0x09, 0x25, 0x8e, 0x10, 0xda, 0x9c, 0x41, 0xab, 0x3d, 0x62, 0x8f, 0x82, 0x6e, 0x4c, 0x6c,
+	0x21, 0xb6, 0x0c, 0xa8, 0xba, 0xf8, 0x2a, 0xc5, 0x09, 0xb5, 0x00, 0x6a, 0x2e, 0x4e, 0x22, 0x12,
+	0x26, 0xd8, 0x7a, 0x03, 0xf5, 0xb3, 0x20, 0xa1, 0x19, 0x8f, 0x6c, 0xa8, 0x70, 0x87, 0xc4, 0x54,
+	0xdb, 0xa5, 0xcd, 0x7f, 0x3a, 0x6b, 0xf6, 0x02, 0x90, 0xed, 0xb2, 0x1f, 0x57, 0x5a, 0x59, 0xfb,
+	0xb0, 0x72, 0x46, 0xc8, 0x20, 0x8d, 0x24, 0x38, 0xda, 0x06, 0xfd, 0x2a, 0xc5, 0xf1, 0xc4, 0x54,
+	0xdb, 0xea, 0x52, 0xff, 0xcf, 0x4c, 0xeb, 0x0a, 0x23, 0xeb, 0x00, 0x1a, 0x99, 0xfb, 0x03, 0x13,
+	0x78, 0x0d, 0x75, 0x81, 0xf8, 0xa0, 0xf8, 0x6f, 0x61, 0x45, 0x7a, 0x3f, 0x30, 0x7c, 0x03, 0xea,
+	0x5f, 0x3d, 0xda, 0xed, 0x67, 0xb5, 0xfd, 0xa5, 0x42, 0xe5, 0xd0, 0x1f, 0xe3, 0x98, 0xa2, 0x06,
+	0x68, 0x81, 0xcf, 0xd3, 0x30, 0x5c, 0x2d, 0xf0, 0x91, 0x03, 0x65, 0x3a, 0x89, 0xb0, 0xa9, 0xb5,
+	0xd5, 0xcd, 0x46, 0xe7, 0x71, 0x0e, 0x58, 0xb8, 0x9d, 0x4f, 0x22, 0xec, 0x72, 0x43, 0xf4, 0x04,
+	0x0c, 0x1a, 0x8c, 0x70, 0x42, 0xbd, 0x51, 0x64, 0x96, 0xda, 0xea, 0x66, 0xc9, 0xbd, 0x11, 0xa0,
+	0x26, 0x94, 0x28, 0x1d, 0x9a, 0x65, 0x2e, 0x67, 0x24, 0xcb, 0x1d, 0x8f, 0x71, 0x48, 0x13, 0x53,
+	0x2f, 0xc8, 0xfd, 0x94, 0xa9, 0x5d, 0x69, 0x65, 0xfd, 0x0b, 0xab, 0x9f, 0x62, 0xd2, 0xc5, 0x49,
+	0x32, 0x1d, 0x87, 0x26, 0x34, 0x8e, 0x63, 0xec, 0x51, 0x3c, 0x2b, 0x39, 0xc1, 0x43, 0x3c, 0x2f,
+	0xb9, 0x88, 0xfc, 0x59, 0x9b, 0x1f, 0x2a, 0xe8, 0x1c, 0x3a, 0xf7, 0x66, 0x7b, 0xee, 0xcd, 0xad,
+	0xe5, 0x09, 0xdd, 0xf9, 0xc9, 0xdb, 0xa0, 0x73, 0x3f, 0xfe, 0xe8, 0xe2, 0xde, 0x08, 0x23, 0xeb,
+	0x02, 0x74, 0xde, 0x5b, 0x64, 0x42, 0x55, 0x6e, 0x8a, 0xcc, 0x2c, 0x63, 0x99, 0xa6, 0xe7, 0x51,
+	0x7c, 0xed, 0x4d, 0x78, 0x86, 0x86, 0x9b, 0xb1, 0x4c, 0x13, 0x62, 0x7a, 0x4d, 0xe2, 0x01, 0x4f,
+	0xc3, 0x70, 0x33, 0xd6, 0xfa, 0xad, 0x81, 0xce, 0xe3, 0xdc, 0x8e, 0xeb, 0xf9, 0x7e, 0x8c, 0x93,
+	0x24, 0xc3, 0x95, 0xec, 0x6c, 0xc4, 0x52, 0x61, 0xc4, 0xf2, 0x5c, 0x44, 0xb4, 0x26, 0x67, 0x32,
+	0x36, 0x75, 0xae, 0x90, 0x1c, 0x42, 0x50, 0x1e, 0x06, 0xe1, 0xc0, 0xac, 0x70, 0x29, 0xa7, 0x99,
+	0xed, 0x08, 0xd3, 0x38, 0xe8, 0x9a, 0x55, 0x5e, 0x3d, 0xc9, 0xa1, 0x03, 0xa8, 0x8d, 0x30, 0xf5,
+	0x7c, 0x8f, 0x7a, 0x66, 0x8d, 0x4f, 0xc7, 0xf3, 0xe5, 0xd5, 0xb3, 0x3f, 0x4a, 0xb3, 0xd3, 0x90,
+	0xc6, 0x13, 0x77, 0xea, 0xc5, 0x90, 0x49, 0x1c, 0xf4, 0x82, 0xd0, 0x34, 0x44, 0x16, 0x82, 0x63,
+	0x59, 0xf4, 0x49, 0x94, 0x98, 0xc0, 0xe3, 0x71, 0xba, 0xb5, 0x07, 0x2b, 0x73, 0x30, 0x6c, 0x58,
+	0x07, 0x78, 0x22, 0xcb, 0xc4, 0x48, 0xf4, 0x1f, 0xe8, 0x63, 0x6f, 0x98, 0x62, 0x59, 0x20, 0xc1,
+	0xec, 0x6a, 0x3b, 0xaa, 0xf5, 0x0d, 0x6a, 0x5f, 0x42, 0x2f, 0x4a, 0xfa, 0x84, 0xce, 0xcf, 0x83,
+	0xba, 0x38, 0x0f, 0x37, 0xcb, 0xaa, 0xdd, 0x75, 0x59, 0xcf, 0x88, 0xe7, 0x67, 0x73, 0xbb, 0xd5,
+	0x01, 0xb8, 0x59, 0x3a, 0x84, 0xa0, 0x21, 0xb8, 0xc3, 0x30, 0x24, 0x69, 0xd8, 0xc5, 0x4d, 0x05,
+	0x35, 0xa1, 0x2e, 0x64, 0x62, 0xe2, 0x9b, 0xea, 0x96, 0x03, 0xc6, 0x74, 0x68, 0x11, 0x40, 0x45,
+	0xac, 0x4b, 0x53, 0x61, 0xb4, 0x58, 0x94, 0xa6, 0xca, 0x68, 0xe9, 0xa0, 0x75, 0x7e, 0x6a, 0x50,
+	0x71, 0x45, 0xc3, 0x3e, 0x40, 0x45, 0x5c, 0x3b, 0xb4, 0x91, 0xcb, 0x74, 0xee, 0x8a, 0xb6, 0x9e,
+	0x16, 0xea, 0xe5, 0xca, 0x29, 0xe8, 0x08, 0x74, 0x7e, 0x79, 0xd0, 0x7a, 0xce, 0x76, 0xf6, 0x22,
+	0xb5, 0x0a, 0xae, 0x80, 0xa5, 0xbc, 0x54, 0xd1, 0x11, 0x18, 0xe2, 0x79, 0x41, 0x82, 0x91, 0x99,
+	0xaf, 0x9e, 0x84, 0xf8, 0xbf, 0xe0, 0x56, 0x71, 0x8c, 0x77, 0x50, 0x95, 0x57, 0x04, 0x15, 0xd9,
+	0xb5, 0xda, 0x39, 0xc5, 0xe2, 0xe1, 0x51, 0x3a, 0x7f, 0x4a, 0xa0, 0x9f, 0x7b, 0x97, 0x43, 0x8c,
+	0x8e, 0xb3, 0xaa, 0xa2, 0x82, 0x86, 0x2e, 0x29, 0xcf, 0xc2, 0xd5, 0x52, 0xd0, 0x71, 0xd6, 0x8e,
+	0x7b, 0x80, 0x2c, 0x1c, 0x3a, 0x0e, 0x22, 0xfa, 0x78, 0x0f, 0x90, 0x85, 0xdb, 0xa8, 0xa0, 0x43,
+	0x28, 0xb3, 0x4f, 0xec, 0x2d, 0xf5, 0xcd, 0x77, 0x70, 0xf6, 0x9b, 0x6c, 0x29, 0xe8, 0x7d, 0x76,
+	0xca, 0xd6, 0x0b, 0x3e, 0x67, 0x12, 0x68, 0xa3, 0x48, 0x3d, 0x45, 0xda, 0x87, 0xf2, 0x49, 0x3a,
+	0x8a, 0x6e, 0x49, 0xe6, 0x51, 0x4e, 0x93, 0x6d, 0x23, 0x1f, 0xba, 0x32, 0xdb, 0x20, 0x54, 0x6c,
+	0xb4, 0xec, 0x31, 0x33, 0x3b, 0x67, 0x29, 0x47, 0xbb, 0xdf, 0x77, 0x7a, 0x01, 0xed, 0xa7, 0x97,
+	0x76, 0x97, 0x8c, 0x1c, 0x6e, 0xe9, 0xf4, 0xc8, 0x0b, 0x41, 0x8c, 0x3b, 0xce, 0xb2, 0x3f, 0x37,
+	0x7b, 0x42, 0x78, 0x59, 0xe1, 0xdc, 0xab, 0xbf, 0x03, 0x00, 0x3d, 0x1b, 0x44, 0xe0, 0x02, 0x09,
+	0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1137,6 +1240,8 @@ type TableClient interface {
 	Update(ctx context.Context, in *Route, opts ...grpc.CallOption) (*UpdateResponse, error)
 	List(ctx context.Context, in *Request, opts ...grpc.CallOption) (*ListResponse, error)
 	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	Dump(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Snapshot, error)
+	Load(ctx context.Context, in *Snapshot, opts ...grpc.CallOption) (*LoadResponse, error)
 }
 
 type tableClient struct {
@@ -1192,6 +1297,24 @@ func (c *tableClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *tableClient) Dump(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Snapshot, error) {
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, "/go.micro.router.Table/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tableClient) Load(ctx context.Context, in *Snapshot, opts ...grpc.CallOption) (*LoadResponse, error) {
+	out := new(LoadResponse)
+	err := c.cc.Invoke(ctx, "/go.micro.router.Table/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TableServer is the server API for Table service.
 type TableServer interface {
 	Create(context.Context, *Route) (*CreateResponse, error)
@@ -1199,6 +1322,8 @@ type TableServer interface {
 	Update(context.Context, *Route) (*UpdateResponse, error)
 	List(context.Context, *Request) (*ListResponse, error)
 	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	Dump(context.Context, *Request) (*Snapshot, error)
+	Load(context.Context, *Snapshot) (*LoadResponse, error)
 }
 
 // UnimplementedTableServer can be embedded to have forward compatible implementations.
@@ -1220,6 +1345,12 @@ func (*UnimplementedTableServer) List(ctx context.Context, req *Request) (*ListR
 func (*UnimplementedTableServer) Query(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
 }
+func (*UnimplementedTableServer) Dump(ctx context.Context, req *Request) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Dump not implemented")
+}
+func (*UnimplementedTableServer) Load(ctx context.Context, req *Snapshot) (*LoadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Load not implemented")
+}
 
 func RegisterTableServer(s *grpc.Server, srv TableServer) {
 	s.RegisterService(&_Table_serviceDesc, srv)
@@ -1315,6 +1446,42 @@ func _Table_Query_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Table_Dump_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TableServer).Dump(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.micro.router.Table/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TableServer).Dump(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Table_Load_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Snapshot)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TableServer).Load(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.micro.router.Table/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TableServer).Load(ctx, req.(*Snapshot))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Table_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "go.micro.router.Table",
 	HandlerType: (*TableServer)(nil),
@@ -1339,6 +1506,14 @@ var _Table_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Query",
 			Handler:    _Table_Query_Handler,
 		},
+		{
+			MethodName: "Dump",
+			Handler:    _Table_Dump_Handler,
+		},
+		{
+			MethodName: "Load",
+			Handler:    _Table_Load_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "router/service/proto/router.proto",
@@ -307,6 +307,8 @@ type TableService interface {
 	Update(ctx context.Context, in *Route, opts ...client.CallOption) (*UpdateResponse, error)
 	List(ctx context.Context, in *Request, opts ...client.CallOption) (*ListResponse, error)
 	Query(ctx context.Context, in *QueryRequest, opts ...client.CallOption) (*QueryResponse, error)
+	Dump(ctx context.Context, in *Request, opts ...client.CallOption) (*Snapshot, error)
+	Load(ctx context.Context, in *Snapshot, opts ...client.CallOption) (*LoadResponse, error)
 }
 
 type tableService struct {
@@ -371,6 +373,26 @@ func (c *tableService) Query(ctx context.Context, in *QueryRequest, opts ...clie
 	return out, nil
 }
 
+func (c *tableService) Dump(ctx context.Context, in *Request, opts ...client.CallOption) (*Snapshot, error) {
+	req := c.c.NewRequest(c.name, "Table.Dump", in)
+	out := new(Snapshot)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tableService) Load(ctx context.Context, in *Snapshot, opts ...client.CallOption) (*LoadResponse, error) {
+	req := c.c.NewRequest(c.name, "Table.Load", in)
+	out := new(LoadResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Table service
 
 type TableHandler interface {
@@ -379,6 +401,8 @@ type TableHandler interface {
 	Update(context.Context, *Route, *UpdateResponse) error
 	List(context.Context, *Request, *ListResponse) error
 	Query(context.Context, *QueryRequest, *QueryResponse) error
+	Dump(context.Context, *Request, *Snapshot) error
+	Load(context.Context, *Snapshot, *LoadResponse) error
 }
 
 func RegisterTableHandler(s server.Server, hdlr TableHandler, opts ...server.HandlerOption) error {
@@ -388,6 +412,8 @@ func RegisterTableHandler(s server.Server, hdlr TableHandler, opts ...server.Han
 		Update(ctx context.Context, in *Route, out *UpdateResponse) error
 		List(ctx context.Context, in *Request, out *ListResponse) error
 		Query(ctx context.Context, in *QueryRequest, out *QueryResponse) error
+		Dump(ctx context.Context, in *Request, out *Snapshot) error
+		Load(ctx context.Context, in *Snapshot, out *LoadResponse) error
 	}
 	type Table struct {
 		table
@@ -419,3 +445,11 @@ func (h *tableHandler) List(ctx context.Context, in *Request, out *ListResponse)
 func (h *tableHandler) Query(ctx context.Context, in *QueryRequest, out *QueryResponse) error {
 	return h.TableHandler.Query(ctx, in, out)
 }
+
+func (h *tableHandler) Dump(ctx context.Context, in *Request, out *Snapshot) error {
+	return h.TableHandler.Dump(ctx, in, out)
+}
+
+func (h *tableHandler) Load(ctx context.Context, in *Snapshot, out *LoadResponse) error {
+	return h.TableHandler.Load(ctx, in, out)
+}
@@ -88,7 +88,7 @@ func (s *svc) Table() router.Table {
 	return s.table
 }
 
-func (s *svc) advertiseEvents(advertChan chan *router.Advert, stream pb.Router_AdvertiseService) error {
+func (s *svc) advertiseEvents(advertChan chan *router.Advert, stream pb.Router_AdvertiseService, opts router.AdvertiseOptions) error {
 	go func() {
 		<-s.exit
 		stream.Close()
@@ -115,6 +115,8 @@ func (s *svc) advertiseEvents(advertChan chan *router.Advert, stream pb.Router_A
 				Link:     event.Route.Link,
 				Metric:   event.Route.Metric,
 				Metadata: event.Route.Metadata,
+				Origin:   event.Route.Origin,
+				Hops:     event.Route.Hops,
 			}
 
 			events[i] = &router.Event{
@@ -125,12 +127,15 @@ func (s *svc) advertiseEvents(advertChan chan *router.Advert, stream pb.Router_A
 			}
 		}
 
-		advert := &router.Advert{
+		advert := router.FilterAdvert(&router.Advert{
 			Id:        resp.Id,
 			Type:      router.AdvertType(resp.Type),
 			Timestamp: time.Unix(0, resp.Timestamp),
 			TTL:       time.Duration(resp.Ttl),
 			Events:    events,
+		}, opts)
+		if advert == nil {
+			continue
 		}
 
 		select {
@@ -148,7 +153,8 @@ func (s *svc) advertiseEvents(advertChan chan *router.Advert, stream pb.Router_A
 }
 
 // Advertise advertises routes to the network
-func (s *svc) Advertise() (<-chan *router.Advert, error) {
+// Filters are applied as adverts are received from the stream.
+func (s *svc) Advertise(opts ...router.AdvertiseOption) (<-chan *router.Advert, error) {
 	s.Lock()
 	defer s.Unlock()
 
@@ -159,7 +165,7 @@ func (s *svc) Advertise() (<-chan *router.Advert, error) {
 
 	// create advertise and event channels
 	advertChan := make(chan *router.Advert)
-	go s.advertiseEvents(advertChan, stream)
+	go s.advertiseEvents(advertChan, stream, router.NewAdvertise(opts...))
 
 	return advertChan, nil
 }
@@ -176,6 +182,8 @@ func (s *svc) Process(advert *router.Advert) error {
 			Link:     event.Route.Link,
 			Metric:   event.Route.Metric,
 			Metadata: event.Route.Metadata,
+			Origin:   event.Route.Origin,
+			Hops:     event.Route.Hops,
 		}
 		e := &pb.Event{
 			Id:        event.Id,
@@ -234,9 +242,16 @@ func (s *svc) Lookup(q ...router.QueryOption) ([]router.Route, error) {
 		return nil, err
 	}
 
-	routes := make([]router.Route, len(resp.Routes))
-	for i, route := range resp.Routes {
-		routes[i] = router.Route{
+	routes := make([]router.Route, 0, len(resp.Routes))
+	for _, route := range resp.Routes {
+		// the max metric and selector aren't sent to the router so are applied here
+		if query.MaxMetric > 0 && route.Metric > query.MaxMetric {
+			continue
+		}
+		if !query.Selector.Matches(route.Metadata) {
+			continue
+		}
+		routes = append(routes, router.Route{
 			Service:  route.Service,
 			Address:  route.Address,
 			Gateway:  route.Gateway,
@@ -244,7 +259,9 @@ func (s *svc) Lookup(q ...router.QueryOption) ([]router.Route, error) {
 			Link:     route.Link,
 			Metric:   route.Metric,
 			Metadata: route.Metadata,
-		}
+			Origin:   route.Origin,
+			Hops:     route.Hops,
+		})
 	}
 
 	return routes, nil
@@ -269,3 +286,27 @@ func (s *svc) Watch(opts ...router.WatchOption) (router.Watcher, error) {
 func (s *svc) String() string {
 	return "service"
 }
+
+// Feedback is not supported by the router service
+func (s *svc) Feedback(route router.Route, rtt time.Duration, err error) error {
+	return nil
+}
+
+// Status returns the state of the remote routing table as seen by this client
+func (s *svc) Status() router.Status {
+	routes, err := s.table.List()
+	if err != nil {
+		return router.Status{Code: router.Error, Error: err}
+	}
+
+	services := make(map[string]int)
+	for _, route := range routes {
+		services[route.Service]++
+	}
+
+	return router.Status{
+		Code:     router.Running,
+		Routes:   len(routes),
+		Services: services,
+	}
+}
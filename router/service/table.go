@@ -6,6 +6,7 @@ import (
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/router"
 	pb "github.com/micro/go-micro/v2/router/service/proto"
+	pbUtil "github.com/micro/go-micro/v2/util/proto"
 )
 
 type table struct {
@@ -22,6 +23,8 @@ func (t *table) Create(r router.Route) error {
 		Network: r.Network,
 		Link:    r.Link,
 		Metric:  r.Metric,
+		Origin:  r.Origin,
+		Hops:    r.Hops,
 	}
 
 	if _, err := t.table.Create(context.Background(), route, t.callOpts...); err != nil {
@@ -40,6 +43,8 @@ func (t *table) Delete(r router.Route) error {
 		Network: r.Network,
 		Link:    r.Link,
 		Metric:  r.Metric,
+		Origin:  r.Origin,
+		Hops:    r.Hops,
 	}
 
 	if _, err := t.table.Delete(context.Background(), route, t.callOpts...); err != nil {
@@ -58,6 +63,8 @@ func (t *table) Update(r router.Route) error {
 		Network: r.Network,
 		Link:    r.Link,
 		Metric:  r.Metric,
+		Origin:  r.Origin,
+		Hops:    r.Hops,
 	}
 
 	if _, err := t.table.Update(context.Background(), route, t.callOpts...); err != nil {
@@ -83,6 +90,8 @@ func (t *table) List() ([]router.Route, error) {
 			Network: route.Network,
 			Link:    route.Link,
 			Metric:  route.Metric,
+			Origin:  route.Origin,
+			Hops:    route.Hops,
 		}
 	}
 
@@ -116,8 +125,33 @@ func (t *table) Query(q ...router.QueryOption) ([]router.Route, error) {
 			Network: route.Network,
 			Link:    route.Link,
 			Metric:  route.Metric,
+			Origin:  route.Origin,
+			Hops:    route.Hops,
 		}
 	}
 
 	return routes, nil
 }
+
+// Dump takes a snapshot of the routing table
+func (t *table) Dump() (*router.Snapshot, error) {
+	resp, err := t.table.Dump(context.Background(), &pb.Request{}, t.callOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pbUtil.ProtoToSnapshot(resp), nil
+}
+
+// Load loads the snapshot routes into the routing table
+func (t *table) Load(s *router.Snapshot) error {
+	if s == nil {
+		return nil
+	}
+
+	if _, err := t.table.Load(context.Background(), pbUtil.SnapshotToProto(s), t.callOpts...); err != nil {
+		return err
+	}
+
+	return nil
+}
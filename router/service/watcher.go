@@ -63,6 +63,8 @@ func (w *watcher) watch(stream pb.Router_WatchService) error {
 			Link:     resp.Route.Link,
 			Metric:   resp.Route.Metric,
 			Metadata: resp.Route.Metadata,
+			Origin:   resp.Route.Origin,
+			Hops:     resp.Route.Hops,
 		}
 
 		event := &router.Event{
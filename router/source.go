@@ -0,0 +1,66 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/micro/go-micro/v2/registry"
+)
+
+var (
+	// RegistryLabel is the metadata key of local routes holding the registry
+	// the route was found in e.g. select routes with "registry=etcd"
+	RegistryLabel = "registry"
+)
+
+// source is a registry local routes are built from
+type source struct {
+	name     string
+	registry registry.Registry
+}
+
+// watch returns a watcher of the services in all domains of the source
+func (s source) watch() (registry.Watcher, error) {
+	return s.registry.Watch(registry.WatchDomain(registry.WildcardDomain))
+}
+
+// sources returns the registries the router builds local routes from. Each is
+// named after the registry, suffixed with its position if the name is taken.
+func (r *router) sources() []source {
+	regs := r.options.Registries
+	if len(regs) == 0 {
+		regs = []registry.Registry{r.options.Registry}
+	}
+
+	sources := make([]source, 0, len(regs))
+	names := make(map[string]bool, len(regs))
+
+	for i, reg := range regs {
+		name := reg.String()
+		if names[name] {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		names[name] = true
+		sources = append(sources, source{name: name, registry: reg})
+	}
+
+	return sources
+}
+
+// serviceDomain returns the domain of the service from its metadata, falling
+// back to the wildcard domain
+func serviceDomain(s *registry.Service) string {
+	if s.Metadata != nil && len(s.Metadata["domain"]) > 0 {
+		return s.Metadata["domain"]
+	}
+	return registry.WildcardDomain
+}
+
+// sourceMetadata returns a copy of the node metadata tagged with the source
+func sourceMetadata(md map[string]string, src string) map[string]string {
+	tagged := make(map[string]string, len(md)+1)
+	for k, v := range md {
+		tagged[k] = v
+	}
+	tagged[RegistryLabel] = src
+	return tagged
+}
@@ -1,6 +1,8 @@
 package static
 
 import (
+	"time"
+
 	"github.com/micro/go-micro/v2/router"
 )
 
@@ -33,7 +35,7 @@ func (s *static) Table() router.Table {
 	return nil
 }
 
-func (s *static) Advertise() (<-chan *router.Advert, error) {
+func (s *static) Advertise(opts ...router.AdvertiseOption) (<-chan *router.Advert, error) {
 	return nil, nil
 }
 
@@ -75,6 +77,14 @@ func (t *table) List() ([]router.Route, error) {
 	return nil, nil
 }
 
+func (t *table) Dump() (*router.Snapshot, error) {
+	return &router.Snapshot{Timestamp: time.Now()}, nil
+}
+
+func (t *table) Load(*router.Snapshot) error {
+	return nil
+}
+
 func (t *table) Query(opts ...router.QueryOption) ([]router.Route, error) {
 	options := router.NewQuery(opts...)
 
@@ -88,3 +98,11 @@ func (t *table) Query(opts ...router.QueryOption) ([]router.Route, error) {
 		},
 	}, nil
 }
+
+func (s *static) Feedback(route router.Route, rtt time.Duration, err error) error {
+	return nil
+}
+
+func (s *static) Status() router.Status {
+	return router.Status{Code: router.Running}
+}
@@ -0,0 +1,48 @@
+package router
+
+// Status is the state of the router, used to inspect a running router
+type Status struct {
+	// Code is the router status code
+	Code StatusCode
+	// Error is the last error encountered by the router
+	Error error
+	// Routes is the number of routes in the table
+	Routes int
+	// Services is the number of routes per service
+	Services map[string]int
+	// Subscribers is the number of advert subscribers
+	Subscribers int
+	// Adverts is the number of adverts published
+	Adverts uint64
+	// Dropped is the number of adverts dropped for subscribers which were full
+	Dropped uint64
+	// Evicted is the number of subscribers evicted for overflowing
+	Evicted uint64
+	// Watching is true while the router is watching the registry
+	Watching bool
+}
+
+// String returns human readable status code
+func (s StatusCode) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Advertising:
+		return "advertising"
+	case Stopped:
+		return "stopped"
+	case Error:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// countRoutes returns the number of routes per service
+func countRoutes(routes []Route) map[string]int {
+	services := make(map[string]int)
+	for _, route := range routes {
+		services[route.Service]++
+	}
+	return services
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// subscriber receives adverts which pass its filters
+type subscriber struct {
+	// number of adverts dropped, first to keep it 64-bit aligned
+	dropped uint64
+	// number of overflows in a row
+	overflows int64
+
+	ch   chan *Advert
+	opts AdvertiseOptions
+}
+
+// newSubscriber creates a subscriber with a buffered advert channel
+func newSubscriber(opts AdvertiseOptions) *subscriber {
+	size := opts.Buffer
+	if size < 0 {
+		size = 0
+	}
+
+	return &subscriber{
+		ch:   make(chan *Advert, size),
+		opts: opts,
+	}
+}
+
+// send delivers the advert according to the subscriber's overflow policy. It
+// returns the number of adverts dropped and false if the router exited first.
+func (s *subscriber) send(a *Advert, exit chan bool) (int, bool) {
+	// fast path when there's room in the buffer
+	select {
+	case s.ch <- a:
+		atomic.StoreInt64(&s.overflows, 0)
+		return 0, true
+	default:
+	}
+
+	var dropped int
+
+	switch s.opts.Overflow {
+	case DropNewest:
+		dropped = 1
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- a:
+				return s.overflow(dropped), true
+			case <-exit:
+				return s.overflow(dropped), false
+			default:
+			}
+
+			// make room by dropping the oldest advert if it's still there
+			select {
+			case <-s.ch:
+				dropped++
+			default:
+			}
+		}
+	default:
+		var timeout <-chan time.Time
+		if s.opts.Timeout > 0 {
+			timer := time.NewTimer(s.opts.Timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case s.ch <- a:
+			atomic.StoreInt64(&s.overflows, 0)
+			return 0, true
+		case <-timeout:
+			dropped = 1
+		case <-exit:
+			return 0, false
+		}
+	}
+
+	return s.overflow(dropped), true
+}
+
+// overflow records an overflow which dropped the given number of adverts
+func (s *subscriber) overflow(dropped int) int {
+	atomic.AddUint64(&s.dropped, uint64(dropped))
+	atomic.AddInt64(&s.overflows, 1)
+	return dropped
+}
+
+// evict returns true once the subscriber overflowed too many times in a row
+func (s *subscriber) evict() bool {
+	return s.opts.Evict > 0 && atomic.LoadInt64(&s.overflows) >= int64(s.opts.Evict)
+}
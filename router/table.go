@@ -2,6 +2,8 @@ package router
 
 import (
 	"errors"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -23,6 +25,10 @@ type table struct {
 	fetchRoutes func(string) error
 	// routes stores service routes
 	routes map[string]map[uint64]Route
+	// refreshed stores when each route was last created or updated
+	refreshed map[uint64]time.Time
+	// feedback stores the observed latency and errors of routes
+	feedback map[uint64]*feedback
 	// watchers stores table watchers
 	watchers map[string]*tableWatcher
 }
@@ -32,6 +38,8 @@ func newTable(fetchRoutes func(string) error, opts ...Option) *table {
 	return &table{
 		fetchRoutes: fetchRoutes,
 		routes:      make(map[string]map[uint64]Route),
+		refreshed:   make(map[uint64]time.Time),
+		feedback:    make(map[uint64]*feedback),
 		watchers:    make(map[string]*tableWatcher),
 	}
 }
@@ -63,6 +71,9 @@ func (t *table) Create(r Route) error {
 	t.Lock()
 	defer t.Unlock()
 
+	// creating an existing route still counts as a refresh
+	t.refreshed[sum] = time.Now()
+
 	// check if there are any routes in the table for the route destination
 	if _, ok := t.routes[service]; !ok {
 		t.routes[service] = make(map[uint64]Route)
@@ -71,8 +82,8 @@ func (t *table) Create(r Route) error {
 	// add new route to the table for the route destination
 	if _, ok := t.routes[service][sum]; !ok {
 		t.routes[service][sum] = r
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Router emitting %s for route: %s", Create, r.Address)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Router emitting %s for route: %s", Create, r.Address)
 		}
 		go t.sendEvent(&Event{Type: Create, Timestamp: time.Now(), Route: r})
 		return nil
@@ -98,11 +109,13 @@ func (t *table) Delete(r Route) error {
 	}
 
 	delete(t.routes[service], sum)
+	delete(t.refreshed, sum)
+	delete(t.feedback, sum)
 	if len(t.routes[service]) == 0 {
 		delete(t.routes, service)
 	}
-	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-		logger.Debugf("Router emitting %s for route: %s", Delete, r.Address)
+	if logger.V(logger.DebugLevel, log) {
+		log.Debugf("Router emitting %s for route: %s", Delete, r.Address)
 	}
 	go t.sendEvent(&Event{Type: Delete, Timestamp: time.Now(), Route: r})
 
@@ -117,6 +130,8 @@ func (t *table) Update(r Route) error {
 	t.Lock()
 	defer t.Unlock()
 
+	t.refreshed[sum] = time.Now()
+
 	// check if the route destination has any routes in the table
 	if _, ok := t.routes[service]; !ok {
 		t.routes[service] = make(map[uint64]Route)
@@ -124,8 +139,8 @@ func (t *table) Update(r Route) error {
 
 	if _, ok := t.routes[service][sum]; !ok {
 		t.routes[service][sum] = r
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Router emitting %s for route: %s", Update, r.Address)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Router emitting %s for route: %s", Update, r.Address)
 		}
 		go t.sendEvent(&Event{Type: Update, Timestamp: time.Now(), Route: r})
 		return nil
@@ -152,6 +167,147 @@ func (t *table) List() ([]Route, error) {
 	return routes, nil
 }
 
+// Dump takes a snapshot of all the routes in the table
+func (t *table) Dump() (*Snapshot, error) {
+	routes, err := t.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Timestamp: time.Now(),
+		Routes:    routes,
+	}, nil
+}
+
+// Load creates the snapshot routes missing from the table, updates the
+// existing ones and deletes the other routes of the services of the snapshot.
+// Watchers are sent the events of the changes.
+func (t *table) Load(s *Snapshot) error {
+	if s == nil {
+		return nil
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	// the routes of the snapshot by service
+	loaded := make(map[string]map[uint64]Route)
+	for _, r := range s.Routes {
+		if _, ok := loaded[r.Service]; !ok {
+			loaded[r.Service] = make(map[uint64]Route)
+		}
+		loaded[r.Service][r.Hash()] = r
+	}
+
+	var events []*Event
+	emit := func(typ EventType, r Route) {
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Router emitting %s for route: %s", typ, r.Address)
+		}
+		events = append(events, &Event{Type: typ, Timestamp: time.Now(), Route: r})
+	}
+
+	for service, routes := range loaded {
+		if _, ok := t.routes[service]; !ok {
+			t.routes[service] = make(map[uint64]Route)
+		}
+
+		// delete the routes of the service missing from the snapshot
+		for sum, r := range t.routes[service] {
+			if _, ok := routes[sum]; ok {
+				continue
+			}
+			delete(t.routes[service], sum)
+			delete(t.refreshed, sum)
+			delete(t.feedback, sum)
+			emit(Delete, r)
+		}
+
+		for sum, r := range routes {
+			t.refreshed[sum] = time.Now()
+
+			if old, ok := t.routes[service][sum]; !ok {
+				emit(Create, r)
+			} else if !reflect.DeepEqual(old, r) {
+				emit(Update, r)
+			}
+			t.routes[service][sum] = r
+		}
+	}
+
+	if len(events) > 0 {
+		go func() {
+			for _, e := range events {
+				t.sendEvent(e)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// observe records the result of a call made using the route
+func (t *table) observe(r Route, rtt time.Duration, err error) error {
+	service := r.Service
+	sum := r.Hash()
+
+	// the feedback of the routes called already only needs the read lock
+	t.RLock()
+	_, found := t.routes[service][sum]
+	f, ok := t.feedback[sum]
+	t.RUnlock()
+
+	if !found {
+		return ErrRouteNotFound
+	}
+
+	if !ok {
+		t.Lock()
+		if _, found := t.routes[service][sum]; !found {
+			t.Unlock()
+			return ErrRouteNotFound
+		}
+		if f, ok = t.feedback[sum]; !ok {
+			f = new(feedback)
+			t.feedback[sum] = f
+		}
+		t.Unlock()
+	}
+	f.observe(time.Now(), rtt, err)
+
+	return nil
+}
+
+// adjust sets the metric of the routes using the feedback recorded for them.
+// Should be called under lock.
+func (t *table) adjust(routes []Route) []Route {
+	now := time.Now()
+	for i, r := range routes {
+		if f, ok := t.feedback[r.Hash()]; ok {
+			routes[i].Metric = f.metric(now, r.Metric)
+		}
+	}
+	return routes
+}
+
+// stale returns the routes which have not been refreshed within the ttl
+func (t *table) stale(ttl time.Duration) []Route {
+	t.RLock()
+	defer t.RUnlock()
+
+	var routes []Route
+	for _, rmap := range t.routes {
+		for sum, route := range rmap {
+			if time.Since(t.refreshed[sum]) > ttl {
+				routes = append(routes, route)
+			}
+		}
+	}
+
+	return routes
+}
+
 // isMatch checks if the route matches given query options
 func isMatch(route Route, address, gateway, network, router string, strategy Strategy) bool {
 	// matches the values provided
@@ -195,12 +351,12 @@ func isMatch(route Route, address, gateway, network, router string, strategy Str
 }
 
 // findRoutes finds all the routes for given network and router and returns them
-func findRoutes(routes map[uint64]Route, address, gateway, network, router string, strategy Strategy) []Route {
+func findRoutes(routes map[uint64]Route, address, gateway, network, router string, strategy Strategy, selector Selector) []Route {
 	// routeMap stores the routes we're going to advertise
 	routeMap := make(map[string][]Route)
 
 	for _, route := range routes {
-		if isMatch(route, address, gateway, network, router, strategy) {
+		if isMatch(route, address, gateway, network, router, strategy) && selector.Matches(route.Metadata) {
 			// add matchihg route to the routeMap
 			routeKey := route.Service + "@" + route.Network
 			// append the first found route to routeMap
@@ -238,6 +394,29 @@ func findRoutes(routes map[uint64]Route, address, gateway, network, router strin
 	return results
 }
 
+// orderRoutes drops the routes with a metric above the max and sorts the rest
+// by metric, lowest first, then by weight, highest first
+func orderRoutes(routes []Route, maxMetric int64) []Route {
+	if maxMetric > 0 {
+		var filtered []Route
+		for _, route := range routes {
+			if route.Metric <= maxMetric {
+				filtered = append(filtered, route)
+			}
+		}
+		routes = filtered
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Metric != routes[j].Metric {
+			return routes[i].Metric < routes[j].Metric
+		}
+		return routes[i].Weight() > routes[j].Weight()
+	})
+
+	return routes
+}
+
 // Lookup queries routing table and returns all routes that match the lookup query
 func (t *table) Query(q ...QueryOption) ([]Route, error) {
 	// create new query options
@@ -261,7 +440,8 @@ func (t *table) Query(q ...QueryOption) ([]Route, error) {
 			return nil, false
 		}
 
-		return findRoutes(routes, opts.Address, opts.Gateway, opts.Network, opts.Router, opts.Strategy), true
+		found := findRoutes(routes, opts.Address, opts.Gateway, opts.Network, opts.Router, opts.Strategy, opts.Selector)
+		return orderRoutes(t.adjust(found), opts.MaxMetric), true
 	}
 
 	if opts.Service != "*" {
@@ -286,11 +466,11 @@ func (t *table) Query(q ...QueryOption) ([]Route, error) {
 	// search through all destinations
 	t.RLock()
 	for _, routes := range t.routes {
-		results = append(results, findRoutes(routes, opts.Address, opts.Gateway, opts.Network, opts.Router, opts.Strategy)...)
+		results = append(results, t.adjust(findRoutes(routes, opts.Address, opts.Gateway, opts.Network, opts.Router, opts.Strategy, opts.Selector))...)
 	}
 	t.RUnlock()
 
-	return results, nil
+	return orderRoutes(results, opts.MaxMetric), nil
 }
 
 // Watch returns routing table entry watcher
@@ -3,6 +3,9 @@ package router
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
 )
 
 func testSetup() (*table, Route) {
@@ -87,6 +90,33 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateMetadata(t *testing.T) {
+	table, route := testSetup()
+	route.Metadata = map[string]string{RegistryLabel: "mdns", "weight": "1"}
+
+	if err := table.Create(route); err != nil {
+		t.Fatalf("error adding route: %s", err)
+	}
+
+	// changing the metadata replaces the route
+	route.Metadata = map[string]string{RegistryLabel: "mdns", "weight": "5"}
+
+	if err := table.Update(route); err != nil {
+		t.Fatalf("error updating route: %s", err)
+	}
+
+	routes, err := table.List()
+	if err != nil {
+		t.Fatalf("error listing routes: %s", err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("expected 1 route, found: %d", len(routes))
+	}
+	if routes[0].Metadata["weight"] != "5" {
+		t.Fatalf("expected the updated metadata, found: %v", routes[0].Metadata)
+	}
+}
+
 func TestList(t *testing.T) {
 	table, route := testSetup()
 
@@ -109,6 +139,55 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestDumpLoad(t *testing.T) {
+	table, route := testSetup()
+
+	svc := []string{"one.svc", "two.svc", "three.svc"}
+
+	for i := 0; i < len(svc); i++ {
+		route.Service = svc[i]
+		if err := table.Create(route); err != nil {
+			t.Errorf("error adding route: %s", err)
+		}
+	}
+
+	snapshot, err := table.Dump()
+	if err != nil {
+		t.Fatalf("error dumping routes: %s", err)
+	}
+
+	if len(snapshot.Routes) != len(svc) {
+		t.Errorf("incorrect number of routes dumped. Expected: %d, found: %d", len(svc), len(snapshot.Routes))
+	}
+
+	peer, _ := testSetup()
+
+	// existing routes are updated rather than duplicated
+	route.Metric = 20
+	if err := peer.Create(route); err != nil {
+		t.Errorf("error adding route: %s", err)
+	}
+
+	if err := peer.Load(snapshot); err != nil {
+		t.Fatalf("error loading routes: %s", err)
+	}
+
+	routes, err := peer.List()
+	if err != nil {
+		t.Errorf("error listing routes: %s", err)
+	}
+
+	if len(routes) != len(svc) {
+		t.Errorf("incorrect number of routes loaded. Expected: %d, found: %d", len(svc), len(routes))
+	}
+
+	for _, r := range routes {
+		if r.Metric != 10 {
+			t.Errorf("incorrect route metric for %s. Expected: %d, found: %d", r.Service, 10, r.Metric)
+		}
+	}
+}
+
 func TestQuery(t *testing.T) {
 	table, route := testSetup()
 
@@ -293,7 +372,7 @@ func TestQuery(t *testing.T) {
 func TestFallback(t *testing.T) {
 
 	r := &router{
-		subscribers: make(map[string]chan *Advert),
+		subscribers: make(map[string]*subscriber),
 		options:     DefaultOptions(),
 	}
 	route := Route{
@@ -333,7 +412,7 @@ func TestFallback(t *testing.T) {
 
 func TestFallbackError(t *testing.T) {
 	r := &router{
-		subscribers: make(map[string]chan *Advert),
+		subscribers: make(map[string]*subscriber),
 		options:     DefaultOptions(),
 	}
 	r.table = newTable(func(s string) error {
@@ -346,3 +425,221 @@ func TestFallbackError(t *testing.T) {
 	}
 
 }
+
+func TestQueryMetric(t *testing.T) {
+	table := newTable(nil)
+
+	routes := []Route{
+		{Service: "svc", Address: "gateway", Gateway: "gw", Link: "network", Metric: 100},
+		{Service: "svc", Address: "local-light", Link: "local", Metric: 1},
+		{Service: "svc", Address: "local-heavy", Link: "local", Metric: 1, Metadata: map[string]string{"weight": "5"}},
+		{Service: "svc", Address: "remote", Link: "network", Metric: 10},
+	}
+	for _, r := range routes {
+		if err := table.Create(r); err != nil {
+			t.Fatalf("error adding route: %s", err)
+		}
+	}
+
+	results, err := table.Query(QueryService("svc"))
+	if err != nil {
+		t.Fatalf("error looking up routes: %s", err)
+	}
+	expect := []string{"local-heavy", "local-light", "remote", "gateway"}
+	if len(results) != len(expect) {
+		t.Fatalf("expected %d routes, got %d", len(expect), len(results))
+	}
+	for i, addr := range expect {
+		if results[i].Address != addr {
+			t.Errorf("expected route %d to be %s, got %s", i, addr, results[i].Address)
+		}
+	}
+
+	results, err = table.Query(QueryService("svc"), QueryMaxMetric(10))
+	if err != nil {
+		t.Fatalf("error looking up routes: %s", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 routes, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Metric > 10 {
+			t.Errorf("expected no routes above metric 10, got %s with %d", r.Address, r.Metric)
+		}
+	}
+}
+
+func TestQueryFeedback(t *testing.T) {
+	table := newTable(nil)
+
+	fast := Route{Service: "svc", Address: "fast", Link: "local", Metric: 1}
+	slow := Route{Service: "svc", Address: "slow", Link: "local", Metric: 1}
+	failing := Route{Service: "svc", Address: "failing", Link: "local", Metric: 1}
+	for _, r := range []Route{failing, slow, fast} {
+		if err := table.Create(r); err != nil {
+			t.Fatalf("error adding route: %s", err)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		table.observe(fast, time.Millisecond, nil)
+		table.observe(slow, 100*time.Millisecond, nil)
+		table.observe(failing, time.Millisecond, fmt.Errorf("connection refused"))
+	}
+
+	// client errors don't count against a route
+	table.observe(fast, time.Millisecond, errors.BadRequest("svc", "bad request"))
+
+	routes, err := table.Query(QueryService("svc"))
+	if err != nil {
+		t.Fatalf("error looking up routes: %s", err)
+	}
+
+	expect := []string{"fast", "slow", "failing"}
+	for i, addr := range expect {
+		if routes[i].Address != addr {
+			t.Errorf("expected route %d to be %s, got %s with metric %d", i, addr, routes[i].Address, routes[i].Metric)
+		}
+	}
+
+	if err := table.observe(Route{Service: "svc", Address: "unknown"}, time.Millisecond, nil); err != ErrRouteNotFound {
+		t.Errorf("expected route not found, got %v", err)
+	}
+}
+
+func TestQueryMetadata(t *testing.T) {
+	table := newTable(nil)
+
+	routes := []Route{
+		{Service: "svc", Address: "eu", Link: "local", Metadata: map[string]string{"region": "eu-west"}},
+		{Service: "svc", Address: "eu-canary", Link: "local", Metadata: map[string]string{"region": "eu-west", "canary": "true"}},
+		{Service: "svc", Address: "us", Link: "local", Metadata: map[string]string{"region": "us-east"}},
+		{Service: "svc", Address: "none", Link: "local"},
+	}
+	for _, r := range routes {
+		if err := table.Create(r); err != nil {
+			t.Fatalf("error adding route: %s", err)
+		}
+	}
+
+	sel, err := ParseSelector("region in (eu-west, us-east), !canary")
+	if err != nil {
+		t.Fatalf("error parsing selector: %s", err)
+	}
+
+	testData := []struct {
+		opts   []QueryOption
+		expect []string
+	}{
+		{[]QueryOption{QueryMetadata(map[string]string{"region": "eu-west"})}, []string{"eu", "eu-canary"}},
+		{[]QueryOption{QueryMetadata(map[string]string{"region": "eu-west", "canary": "true"})}, []string{"eu-canary"}},
+		{[]QueryOption{QuerySelector(sel)}, []string{"eu", "us"}},
+	}
+
+	for _, d := range testData {
+		results, err := table.Query(append(d.opts, QueryService("svc"))...)
+		if err != nil {
+			t.Fatalf("error looking up routes: %s", err)
+		}
+
+		found := make(map[string]bool)
+		for _, r := range results {
+			found[r.Address] = true
+		}
+		if len(found) != len(d.expect) {
+			t.Errorf("expected routes %v, got %v", d.expect, results)
+			continue
+		}
+		for _, addr := range d.expect {
+			if !found[addr] {
+				t.Errorf("expected route %s in %v", addr, results)
+			}
+		}
+	}
+}
+
+func TestLoadEvents(t *testing.T) {
+	table, route := testSetup()
+
+	// a route of the service which isn't in the snapshot
+	stale := route
+	stale.Address = "stale.addr"
+	if err := table.Create(stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(route); err != nil {
+		t.Fatal(err)
+	}
+	// the routes of other services are kept
+	other := route
+	other.Service = "other.svc"
+	if err := table.Create(other); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for the events of the routes created
+	time.Sleep(10 * time.Millisecond)
+
+	w, err := table.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	updated := route
+	updated.Metric = 20
+	created := route
+	created.Address = "new.addr"
+
+	if err := table.Load(&Snapshot{Routes: []Route{updated, created}}); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(map[EventType]string)
+	for i := 0; i < 3; i++ {
+		e, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		events[e.Type] = e.Route.Address
+	}
+
+	if events[Create] != "new.addr" || events[Update] != "dest.addr" || events[Delete] != "stale.addr" {
+		t.Fatalf("Expected the events of the routes loaded, got %v", events)
+	}
+
+	routes, err := table.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("Expected 3 routes, got %v", routes)
+	}
+}
+
+func TestFeedbackDecay(t *testing.T) {
+	f := new(feedback)
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		f.observe(now, 100*time.Millisecond, fmt.Errorf("connection refused"))
+	}
+	penalty := f.metric(now, 0)
+	if penalty < 1000 {
+		t.Fatalf("expected a failing route to be penalised, got %d", penalty)
+	}
+
+	// the penalty decays without observations
+	if m := f.metric(now.Add(FeedbackDecay), 0); m >= penalty/2 {
+		t.Fatalf("expected the penalty to decay from %d, got %d", penalty, m)
+	}
+	if m := f.metric(now.Add(10*FeedbackDecay), 0); m != 0 {
+		t.Fatalf("expected the penalty to be gone, got %d", m)
+	}
+
+	// the observations after a while mix with the decayed averages
+	f.observe(now.Add(10*FeedbackDecay), time.Millisecond, nil)
+	if m := f.metric(now.Add(10*FeedbackDecay), 0); m != 0 {
+		t.Fatalf("expected a healthy route not to be penalised, got %d", m)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -32,6 +33,8 @@ type runtime struct {
 	// namespaces stores services grouped by namespace, e.g. namespaces["foo"]["go.micro.auth:latest"]
 	// would return the latest version of go.micro.auth from the foo namespace
 	namespaces map[string]map[string]*service
+	// ports allocated to the services
+	ports *ports
 }
 
 // NewRuntime creates new local runtime and returns it
@@ -53,6 +56,7 @@ func NewRuntime(opts ...Option) Runtime {
 		closed:     make(chan bool),
 		start:      make(chan *service, 128),
 		namespaces: make(map[string]map[string]*service),
+		ports:      newPorts(options.MinPort, options.MaxPort),
 	}
 }
 
@@ -66,7 +70,16 @@ func (r *runtime) checkoutSourceIfNeeded(s *Service) error {
 	cpath := filepath.Join(os.TempDir(), "micro", "uploads", s.Source)
 	path := strings.ReplaceAll(cpath, ".tar.gz", "")
 	if ex, _ := exists(cpath); ex {
-		err := os.RemoveAll(path)
+		// verify the uploaded source before unpacking it
+		checksum, err := Checksum(cpath)
+		if err != nil {
+			return err
+		}
+		if err := Verify(s, checksum, r.options); err != nil {
+			return err
+		}
+
+		err = os.RemoveAll(path)
 		if err != nil {
 			return err
 		}
@@ -81,6 +94,10 @@ func (r *runtime) checkoutSourceIfNeeded(s *Service) error {
 		s.Source = path
 		return nil
 	}
+	// sources checked out from git have no checksum to verify
+	if err := Verify(s, "", r.options); err != nil {
+		return err
+	}
 	source, err := git.ParseSourceLocal("", s.Source)
 	if err != nil {
 		return err
@@ -104,6 +121,10 @@ func (r *runtime) Init(opts ...Option) error {
 		o(&r.options)
 	}
 
+	r.ports.Lock()
+	r.ports.min, r.ports.max = r.options.MinPort, r.options.MaxPort
+	r.ports.Unlock()
+
 	return nil
 }
 
@@ -277,8 +298,8 @@ func (r *runtime) Create(s *Service, opts ...CreateOption) error {
 			return errors.New("Invalid credentials, expected format 'user:pass'")
 		}
 
-		options.Env = append(options.Env, "MICRO_AUTH_ID", comps[0])
-		options.Env = append(options.Env, "MICRO_AUTH_SECRET", comps[1])
+		options.Env = append(options.Env, "MICRO_AUTH_ID="+comps[0])
+		options.Env = append(options.Env, "MICRO_AUTH_SECRET="+comps[1])
 	}
 
 	if _, ok := r.namespaces[options.Namespace]; !ok {
@@ -288,6 +309,23 @@ func (r *runtime) Create(s *Service, opts ...CreateOption) error {
 		return errors.New("service already running")
 	}
 
+	// the env of the runtime e.g. the registry, overridden by the env of the service
+	options.Env = append(append([]string{}, r.options.Env...), options.Env...)
+
+	// allocate the server address unless the service sets its own, so the
+	// services don't conflict, and report it with the service
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]string)
+	}
+	if !hasEnv(options.Env, "MICRO_SERVER_ADDRESS") {
+		port, err := r.ports.allocate(options.Namespace + "/" + serviceKey(s))
+		if err != nil {
+			return err
+		}
+		s.Metadata["address"] = ":" + strconv.Itoa(port)
+		options.Env = append(options.Env, "MICRO_SERVER_ADDRESS="+s.Metadata["address"])
+	}
+
 	// create new service
 	service := newService(s, options)
 
@@ -303,6 +341,7 @@ func (r *runtime) Create(s *Service, opts ...CreateOption) error {
 	}
 	// start the service
 	if err := service.Start(); err != nil {
+		r.ports.release(options.Namespace + "/" + serviceKey(s))
 		return err
 	}
 	// save service
@@ -535,6 +574,7 @@ func (r *runtime) Delete(s *Service, opts ...DeleteOption) error {
 	if !service.Running() {
 		delete(srvs, service.key())
 		r.namespaces[options.Namespace] = srvs
+		r.ports.release(options.Namespace + "/" + service.key())
 		return nil
 	}
 	// otherwise stop it
@@ -544,6 +584,7 @@ func (r *runtime) Delete(s *Service, opts ...DeleteOption) error {
 	// delete it
 	delete(srvs, service.key())
 	r.namespaces[options.Namespace] = srvs
+	r.ports.release(options.Namespace + "/" + service.key())
 	return nil
 }
 
@@ -449,6 +449,11 @@ func (k *kubernetes) Create(s *runtime.Service, opts ...runtime.CreateOption) er
 	// determine the image from the source and options
 	options.Image = k.getImage(s, options)
 
+	// verify the image before deploying it
+	if err := runtime.Verify(s, imageDigest(options.Image), k.options); err != nil {
+		return err
+	}
+
 	// create a secret for the credentials if some where provided
 	if len(options.Credentials) > 0 {
 		secret, err := k.createCredentials(s, options)
@@ -689,6 +694,16 @@ func (k *kubernetes) getImage(s *runtime.Service, options runtime.CreateOptions)
 
 	return ""
 }
+
+// imageDigest returns the sha256 digest of an image referenced by digest, such
+// as micro/service@sha256:abc, otherwise an empty string
+func imageDigest(image string) string {
+	if i := strings.LastIndex(image, "@sha256:"); i >= 0 {
+		return image[i+len("@sha256:"):]
+	}
+	return ""
+}
+
 func (k *kubernetes) createCredentials(service *runtime.Service, options runtime.CreateOptions) (string, error) {
 	// validate the creds
 	comps := strings.Split(options.Credentials, ":")
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"context"
+	"crypto/ed25519"
 	"io"
 
 	"github.com/micro/go-micro/v2/client"
@@ -21,6 +22,15 @@ type Options struct {
 	Image string
 	// Client to use when making requests
 	Client client.Client
+	// Verify sets how strictly artifacts are verified before being deployed
+	Verify VerifyMode
+	// VerifyKeys are the public keys trusted to sign artifacts
+	VerifyKeys []ed25519.PublicKey
+	// Env is passed to every service e.g. the registry and broker settings
+	Env []string
+	// MinPort and MaxPort are the range of ports allocated to the services
+	// started by the local runtime, zero to use any free port
+	MinPort, MaxPort int
 }
 
 // WithSource sets the base image / repository
@@ -58,6 +68,35 @@ func WithClient(c client.Client) Option {
 	}
 }
 
+// WithVerify sets how strictly artifacts are verified before being deployed
+func WithVerify(m VerifyMode) Option {
+	return func(o *Options) {
+		o.Verify = m
+	}
+}
+
+// WithVerifyKeys sets the public keys trusted to sign artifacts
+func WithVerifyKeys(keys ...ed25519.PublicKey) Option {
+	return func(o *Options) {
+		o.VerifyKeys = keys
+	}
+}
+
+// WithServiceEnv sets the env passed to every service e.g. the registry and broker settings
+func WithServiceEnv(env ...string) Option {
+	return func(o *Options) {
+		o.Env = env
+	}
+}
+
+// WithPortRange sets the range of ports the local runtime allocates to services
+func WithPortRange(min, max int) Option {
+	return func(o *Options) {
+		o.MinPort = min
+		o.MaxPort = max
+	}
+}
+
 type CreateOption func(o *CreateOptions)
 
 type ReadOption func(o *ReadOptions)
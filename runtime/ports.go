@@ -0,0 +1,101 @@
+package runtime
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNoPorts is returned when every port in the range is in use
+	ErrNoPorts = errors.New("no free ports")
+)
+
+// ports allocates free local ports to the services started by the runtime
+type ports struct {
+	sync.Mutex
+	min, max int
+	// used ports by service key
+	used map[string]int
+}
+
+func newPorts(min, max int) *ports {
+	return &ports{
+		min:  min,
+		max:  max,
+		used: make(map[string]int),
+	}
+}
+
+// allocate returns the port allocated to the service, allocating a free one
+// if it doesn't have one yet
+func (p *ports) allocate(key string) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if port, ok := p.used[key]; ok {
+		return port, nil
+	}
+
+	taken := make(map[int]bool, len(p.used))
+	for _, port := range p.used {
+		taken[port] = true
+	}
+
+	// without a range the os picks a free port
+	if p.min <= 0 || p.max < p.min {
+		for i := 0; i < 10; i++ {
+			port, err := free(0)
+			if err != nil {
+				return 0, err
+			}
+			if !taken[port] {
+				p.used[key] = port
+				return port, nil
+			}
+		}
+		return 0, ErrNoPorts
+	}
+
+	for port := p.min; port <= p.max; port++ {
+		if taken[port] {
+			continue
+		}
+		if _, err := free(port); err != nil {
+			continue
+		}
+		p.used[key] = port
+		return port, nil
+	}
+
+	return 0, ErrNoPorts
+}
+
+// release frees the port allocated to the service
+func (p *ports) release(key string) {
+	p.Lock()
+	delete(p.used, key)
+	p.Unlock()
+}
+
+// free checks the port is free by binding it, port 0 binds any free port
+func free(port int) (int, error) {
+	l, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// hasEnv returns true if the env var is set in env
+func hasEnv(env []string, key string) bool {
+	for _, e := range env {
+		if strings.HasPrefix(e, key+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestPortsAllocate(t *testing.T) {
+	// find a free range by binding the first port
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	min := l.Addr().(*net.TCPAddr).Port
+
+	p := newPorts(min, min+2)
+
+	// the bound port is skipped
+	foo, err := p.allocate("default/foo:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if foo == min {
+		t.Fatalf("Expected the port in use to be skipped, got %d", foo)
+	}
+
+	// the same service keeps its port
+	if again, _ := p.allocate("default/foo:latest"); again != foo {
+		t.Fatalf("Expected the service to keep port %d, got %d", foo, again)
+	}
+
+	bar, err := p.allocate("default/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bar == foo || bar == min {
+		t.Fatalf("Expected a different free port, got %d", bar)
+	}
+
+	if _, err := p.allocate("default/baz:latest"); err != ErrNoPorts {
+		t.Fatalf("Expected no free ports, got %v", err)
+	}
+
+	// released ports are allocated again
+	p.release("default/foo:latest")
+	if baz, err := p.allocate("default/baz:latest"); err != nil || baz != foo {
+		t.Fatalf("Expected the released port %d, got %d %v", foo, baz, err)
+	}
+}
+
+func TestPortsAny(t *testing.T) {
+	p := newPorts(0, 0)
+
+	seen := make(map[int]bool)
+	for i := 0; i < 5; i++ {
+		port, err := p.allocate("default/svc-" + strconv.Itoa(i) + ":latest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[port] {
+			t.Fatalf("Expected unique ports, got %d twice", port)
+		}
+		seen[port] = true
+	}
+}
@@ -0,0 +1,110 @@
+package runtime
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/micro/go-micro/v2/logger"
+)
+
+// VerifyMode is how strictly the runtime verifies the artifacts it deploys
+type VerifyMode int
+
+const (
+	// VerifyNone deploys artifacts without verifying them
+	VerifyNone VerifyMode = iota
+	// VerifyPermissive refuses artifacts with an invalid checksum or signature
+	// but deploys unsigned artifacts with a warning
+	VerifyPermissive
+	// VerifyEnforcing refuses artifacts which aren't signed by a trusted key
+	VerifyEnforcing
+)
+
+const (
+	// ChecksumKey is the service metadata key holding the sha256 checksum of the artifact
+	ChecksumKey = "checksum"
+	// SignatureKey is the service metadata key holding the base64 encoded ed25519
+	// signature of the artifact checksum
+	SignatureKey = "signature"
+)
+
+var (
+	// ErrUnsigned is returned when enforcing and the artifact isn't signed
+	ErrUnsigned = errors.New("artifact is not signed")
+	// ErrInvalidSignature is returned when the artifact isn't signed by a trusted key
+	ErrInvalidSignature = errors.New("invalid artifact signature")
+	// ErrChecksumMismatch is returned when the artifact doesn't match its checksum
+	ErrChecksumMismatch = errors.New("artifact checksum mismatch")
+)
+
+// ParseVerifyKeys parses base64 encoded ed25519 public keys
+func ParseVerifyKeys(keys ...string) ([]ed25519.PublicKey, error) {
+	parsed := make([]ed25519.PublicKey, 0, len(keys))
+	for _, k := range keys {
+		b, err := base64.StdEncoding.DecodeString(k)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key")
+		}
+		parsed = append(parsed, ed25519.PublicKey(b))
+	}
+	return parsed, nil
+}
+
+// Checksum returns the hex encoded sha256 checksum of the file
+func Checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks the checksum of an artifact, computed by the runtime, against the
+// checksum and signature in the service metadata. An empty checksum means the
+// runtime couldn't compute one and the artifact is treated as unsigned.
+func Verify(s *Service, checksum string, opts Options) error {
+	if opts.Verify == VerifyNone {
+		return nil
+	}
+
+	expected, signature := s.Metadata[ChecksumKey], s.Metadata[SignatureKey]
+
+	if len(checksum) > 0 && len(expected) > 0 && checksum != expected {
+		return ErrChecksumMismatch
+	}
+
+	if len(checksum) == 0 || len(signature) == 0 {
+		if opts.Verify == VerifyEnforcing {
+			return ErrUnsigned
+		}
+		logger.Warnf("Runtime deploying unsigned artifact for service %s %s", s.Name, s.Version)
+		return nil
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	for _, key := range opts.VerifyKeys {
+		if ed25519.Verify(key, []byte(checksum), sig) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
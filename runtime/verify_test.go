@@ -0,0 +1,49 @@
+package runtime
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksum := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(checksum)))
+
+	signed := &Service{Name: "foo", Metadata: map[string]string{
+		ChecksumKey:  checksum,
+		SignatureKey: signature,
+	}}
+	unsigned := &Service{Name: "foo"}
+
+	enforcing := Options{Verify: VerifyEnforcing, VerifyKeys: []ed25519.PublicKey{pub}}
+	permissive := Options{Verify: VerifyPermissive, VerifyKeys: []ed25519.PublicKey{pub}}
+
+	testData := []struct {
+		name     string
+		service  *Service
+		checksum string
+		opts     Options
+		err      error
+	}{
+		{"signed", signed, checksum, enforcing, nil},
+		{"unsigned enforcing", unsigned, checksum, enforcing, ErrUnsigned},
+		{"unsigned permissive", unsigned, checksum, permissive, nil},
+		{"no checksum", signed, "", enforcing, ErrUnsigned},
+		{"tampered", signed, "deadbeef", permissive, ErrChecksumMismatch},
+		{"untrusted key", signed, checksum, Options{Verify: VerifyEnforcing}, ErrInvalidSignature},
+		{"disabled", unsigned, "", Options{}, nil},
+	}
+
+	for _, d := range testData {
+		if err := Verify(d.service, d.checksum, d.opts); err != d.err {
+			t.Errorf("%s: expected %v, got %v", d.name, d.err, err)
+		}
+	}
+}
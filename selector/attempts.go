@@ -0,0 +1,62 @@
+package selector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/micro/go-micro/v2/router"
+)
+
+type attemptsKey struct{}
+
+// attempts are the route addresses already tried for a request
+type attempts struct {
+	sync.RWMutex
+	addrs map[string]bool
+}
+
+// NewAttemptsContext returns a context which tracks the routes attempted for a request so
+// retries can be sent elsewhere. If the context is already tracking attempts it's returned.
+func NewAttemptsContext(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(attemptsKey{}).(*attempts); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, attemptsKey{}, &attempts{addrs: make(map[string]bool)})
+}
+
+// Attempt records the route was attempted for the request in the context
+func Attempt(ctx context.Context, route router.Route) {
+	a, ok := ctx.Value(attemptsKey{}).(*attempts)
+	if !ok {
+		return
+	}
+	a.Lock()
+	a.addrs[route.Address] = true
+	a.Unlock()
+}
+
+// ExcludeAttempted returns a filter which removes the routes already attempted for the
+// request in the context. If every route has been attempted they're all returned so a
+// retry can still be made.
+func ExcludeAttempted(ctx context.Context) Filter {
+	return func(old []router.Route) []router.Route {
+		a, ok := ctx.Value(attemptsKey{}).(*attempts)
+		if !ok {
+			return old
+		}
+
+		a.RLock()
+		defer a.RUnlock()
+
+		var routes []router.Route
+		for _, r := range old {
+			if !a.addrs[r.Address] {
+				routes = append(routes, r)
+			}
+		}
+		if len(routes) == 0 {
+			return old
+		}
+		return routes
+	}
+}
@@ -0,0 +1,263 @@
+// Package consistenthash is a selector which hashes a key from the request metadata onto
+// a ring of routes, so the requests with the same key stick to the same route
+package consistenthash
+
+import (
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+var (
+	// DefaultHeader is the request metadata header hashed by default
+	DefaultHeader = "Micro-Hash-Key"
+	// DefaultReplicas is the default number of virtual nodes per route
+	DefaultReplicas = 100
+	// DefaultLoadFactor is the default bound of the load of a route relative to the average
+	DefaultLoadFactor = 1.25
+)
+
+// NewSelector returns a consistent hashing selector. Requests without the hashed
+// header are sent to a random route.
+func NewSelector(opts ...selector.Option) selector.Selector {
+	c := &consistentHash{
+		load: make(map[string]int64),
+	}
+	c.Init(opts...)
+	return c
+}
+
+type consistentHash struct {
+	sync.Mutex
+
+	opts       selector.Options
+	header     string
+	replicas   int
+	loadFactor float64
+
+	// ring of the last selected routes
+	ring *ring
+	// load is the number of requests in flight per route address
+	load map[string]int64
+}
+
+// ring is a hash ring of route addresses
+type ring struct {
+	// key identifies the routes on the ring
+	key string
+	// points are the sorted virtual node hashes
+	points []uint32
+	// owners maps the virtual nodes to route addresses
+	owners map[uint32]string
+}
+
+func newRing(key string, addrs []string, replicas int) *ring {
+	r := &ring{
+		key:    key,
+		owners: make(map[uint32]string, len(addrs)*replicas),
+	}
+
+	for _, addr := range addrs {
+		for i := 0; i < replicas; i++ {
+			point := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + addr))
+			r.points = append(r.points, point)
+			r.owners[point] = addr
+		}
+	}
+
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+
+	return r
+}
+
+func (c *consistentHash) Init(opts ...selector.Option) error {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, o := range opts {
+		o(&c.opts)
+	}
+
+	c.header = DefaultHeader
+	c.replicas = DefaultReplicas
+	c.loadFactor = DefaultLoadFactor
+
+	if ctx := c.opts.Context; ctx != nil {
+		if h, ok := ctx.Value(headerKey{}).(string); ok && len(h) > 0 {
+			c.header = h
+		}
+		if n, ok := ctx.Value(replicasKey{}).(int); ok && n > 0 {
+			c.replicas = n
+		}
+		if f, ok := ctx.Value(loadFactorKey{}).(float64); ok {
+			c.loadFactor = f
+		}
+	}
+
+	// the ring is rebuilt with the new replicas on the next selection
+	c.ring = nil
+
+	return nil
+}
+
+func (c *consistentHash) Options() selector.Options {
+	c.Lock()
+	defer c.Unlock()
+	return c.opts
+}
+
+func (c *consistentHash) Select(routes []router.Route, opts ...selector.SelectOption) (*router.Route, error) {
+	// parse the options
+	options := selector.NewSelectOptions(opts...)
+
+	// apply the filters
+	for _, f := range options.Filters {
+		routes = f(routes)
+	}
+
+	// we can't select from an empty pool of routes
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// requests without a key can go to any route
+	key := c.key(options)
+	if len(key) == 0 || len(routes) == 1 {
+		route := &routes[rand.Intn(len(routes))]
+		c.load[route.Address]++
+		return route, nil
+	}
+
+	i, _ := c.pick(routes, key)
+	c.load[routes[i].Address]++
+	return &routes[i], nil
+}
+
+// Explain scores the routes by their requests in flight and explains which
+// route the key of the request is hashed to
+func (c *consistentHash) Explain(routes []router.Route, opts ...selector.SelectOption) (*selector.Explanation, error) {
+	options := selector.NewSelectOptions(opts...)
+	exp := selector.NewExplanation(c.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+	if len(routes) == 0 {
+		exp.Reason = "no routes passed the filters"
+		return exp, selector.ErrNoneAvailable
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, route := range routes {
+		cand := exp.Candidate(route)
+		cand.Score = float64(c.load[route.Address])
+		cand.Reason = fmt.Sprintf("%d requests in flight", c.load[route.Address])
+	}
+
+	key := c.key(options)
+	if len(key) == 0 {
+		exp.Selected = &routes[rand.Intn(len(routes))]
+		exp.Reason = fmt.Sprintf("no %s header, picked at random", c.header)
+		return exp, nil
+	}
+
+	i, reason := c.pick(routes, key)
+	exp.Selected = &routes[i]
+	exp.Reason = reason
+	return exp, nil
+}
+
+// key returns the hashed key of the request
+func (c *consistentHash) key(options selector.SelectOptions) string {
+	if options.Context == nil {
+		return ""
+	}
+	key, _ := metadata.Get(options.Context, c.header)
+	return key
+}
+
+// pick returns the index of the route the key is hashed to and why it was
+// picked. Should be called under lock.
+func (c *consistentHash) pick(routes []router.Route, key string) (int, string) {
+	// index the routes by address, sorted so the ring can be reused
+	byAddr := make(map[string]int, len(routes))
+	addrs := make([]string, 0, len(routes))
+	for i, route := range routes {
+		if _, ok := byAddr[route.Address]; ok {
+			continue
+		}
+		byAddr[route.Address] = i
+		addrs = append(addrs, route.Address)
+	}
+	sort.Strings(addrs)
+
+	if ringKey := strings.Join(addrs, ","); c.ring == nil || c.ring.key != ringKey {
+		c.ring = newRing(ringKey, addrs, c.replicas)
+	}
+
+	// bound the load of each route to the load factor of the average
+	max := int64(math.MaxInt64)
+	if c.loadFactor > 0 {
+		var total int64
+		for _, addr := range addrs {
+			total += c.load[addr]
+		}
+		max = int64(math.Ceil(float64(total+1) * c.loadFactor / float64(len(addrs))))
+	}
+
+	// walk the ring from the key to the first route which isn't overloaded
+	points := c.ring.points
+	hash := crc32.ChecksumIEEE([]byte(key))
+	start := sort.Search(len(points), func(i int) bool { return points[i] >= hash })
+
+	owner := c.ring.owners[points[start%len(points)]]
+	reason := fmt.Sprintf("key %s hashes to %s", key, owner)
+
+	for i := 0; i < len(points); i++ {
+		addr := c.ring.owners[points[(start+i)%len(points)]]
+		if c.load[addr] >= max {
+			continue
+		}
+		if addr != owner {
+			reason = fmt.Sprintf("key %s hashes to %s which is at its max load of %d, next route on the ring", key, owner, max)
+		}
+		owner = addr
+		break
+	}
+
+	return byAddr[owner], reason
+}
+
+// Record marks the request sent to the route as done
+func (c *consistentHash) Record(route router.Route, err error) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.load[route.Address] <= 1 {
+		delete(c.load, route.Address)
+	} else {
+		c.load[route.Address]--
+	}
+
+	return nil
+}
+
+func (c *consistentHash) Close() error {
+	return nil
+}
+
+func (c *consistentHash) String() string {
+	return "consistenthash"
+}
@@ -0,0 +1,85 @@
+package consistenthash
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+func TestConsistentHash(t *testing.T) {
+	selector.Tests(t, NewSelector())
+}
+
+func testRoutes(n int) []router.Route {
+	routes := make([]router.Route, n)
+	for i := range routes {
+		routes[i] = router.Route{Service: "go.micro.service.foo", Address: fmt.Sprintf("127.0.0.1:%d", 8000+i)}
+	}
+	return routes
+}
+
+func withKey(key string) selector.SelectOption {
+	return selector.WithContext(metadata.Set(context.Background(), DefaultHeader, key))
+}
+
+func TestConsistentHashSticky(t *testing.T) {
+	s := NewSelector()
+	routes := testRoutes(5)
+
+	picks := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		route, err := s.Select(routes, withKey(key))
+		if err != nil {
+			t.Fatalf("Unexpected select error: %v", err)
+		}
+		s.Record(*route, nil)
+		picks[key] = route.Address
+
+		// the same key sticks to the same route
+		again, _ := s.Select(routes, withKey(key))
+		s.Record(*again, nil)
+		if again.Address != route.Address {
+			t.Fatalf("Expected key %s to stick to %s, got %s", key, route.Address, again.Address)
+		}
+	}
+
+	// removing a route only moves the keys which were on it
+	removed := routes[0].Address
+	for key, addr := range picks {
+		route, _ := s.Select(routes[1:], withKey(key))
+		s.Record(*route, nil)
+		if addr != removed && route.Address != addr {
+			t.Errorf("Expected key %s to stay on %s, moved to %s", key, addr, route.Address)
+		}
+	}
+}
+
+func TestConsistentHashBoundedLoad(t *testing.T) {
+	s := NewSelector(LoadFactor(1), Header("Micro-Session"))
+	routes := testRoutes(4)
+	ctx := metadata.Set(context.Background(), "Micro-Session", "hot")
+
+	// requests in flight for a hot key spill over to the next routes
+	used := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		route, err := s.Select(routes, selector.WithContext(ctx))
+		if err != nil {
+			t.Fatalf("Unexpected select error: %v", err)
+		}
+		used[route.Address]++
+	}
+
+	if len(used) != len(routes) {
+		t.Fatalf("Expected the load to spread over %d routes, got %v", len(routes), used)
+	}
+	for addr, n := range used {
+		if n != 2 {
+			t.Errorf("Expected 2 requests in flight to %s, got %d", addr, n)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package consistenthash
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/selector"
+)
+
+type headerKey struct{}
+
+type replicasKey struct{}
+
+type loadFactorKey struct{}
+
+// Header sets the request metadata header whose value is hashed onto the ring
+func Header(h string) selector.Option {
+	return setOption(headerKey{}, h)
+}
+
+// Replicas sets the number of virtual nodes each route has on the ring
+func Replicas(n int) selector.Option {
+	return setOption(replicasKey{}, n)
+}
+
+// LoadFactor bounds the requests in flight to a route to the given factor of the
+// average, once reached the next route on the ring is selected. A factor of zero
+// disables the bound.
+func LoadFactor(f float64) selector.Option {
+	return setOption(loadFactorKey{}, f)
+}
+
+func setOption(k, v interface{}) selector.Option {
+	return func(o *selector.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
@@ -0,0 +1,227 @@
+package selector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/router"
+)
+
+var (
+	// DefaultEjectErrors is the number of consecutive errors ejecting a
+	// route, zero so routes aren't ejected unless enabled with EjectErrors
+	DefaultEjectErrors = 0
+	// DefaultEjectDuration is how long a route is ejected for
+	DefaultEjectDuration = 30 * time.Second
+	// DefaultMaxEjectPercent is the max percentage of the routes ejected
+	DefaultMaxEjectPercent = 50
+)
+
+type ejectErrorsKey struct{}
+
+type ejectDurationKey struct{}
+
+type maxEjectPercentKey struct{}
+
+type ejectRouterKey struct{}
+
+// EjectErrors enables the ejection of routes by the default selector, setting
+// the number of consecutive errors after which a route is ejected from
+// selection. Zero or less never ejects routes, the default.
+func EjectErrors(n int) Option {
+	return setOption(ejectErrorsKey{}, n)
+}
+
+// EjectDuration sets how long the default selector ejects a route for
+func EjectDuration(d time.Duration) Option {
+	return setOption(ejectDurationKey{}, d)
+}
+
+// MaxEjectPercent sets the max percentage of the routes of a selection the
+// default selector ejects, so failures across a service don't eject all of it
+func MaxEjectPercent(p int) Option {
+	return setOption(maxEjectPercentKey{}, p)
+}
+
+// EjectRouter sets the router the default selector watches to forget the
+// errors of the routes deleted from it, so the state of routes which are gone
+// doesn't build up
+func EjectRouter(r router.Router) Option {
+	return setOption(ejectRouterKey{}, r)
+}
+
+func setOption(k, v interface{}) Option {
+	return func(o *Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// ejector ejects the routes which repeatedly fail from selection for a while,
+// like a circuit breaker per route
+type ejector struct {
+	sync.RWMutex
+
+	errors     int
+	duration   time.Duration
+	maxPercent int
+
+	// routes by address
+	routes map[string]*ejection
+	// watcher of the deleted routes, nil if not watching
+	watcher router.Watcher
+}
+
+type ejection struct {
+	// errors is the number of consecutive errors
+	errors int
+	// ejected is when the route was ejected, zero if it isn't
+	ejected time.Time
+}
+
+func newEjector() *ejector {
+	return &ejector{
+		errors:     DefaultEjectErrors,
+		duration:   DefaultEjectDuration,
+		maxPercent: DefaultMaxEjectPercent,
+		routes:     make(map[string]*ejection),
+	}
+}
+
+func (e *ejector) init(opts Options) {
+	e.Lock()
+	defer e.Unlock()
+
+	if opts.Context == nil {
+		return
+	}
+	if n, ok := opts.Context.Value(ejectErrorsKey{}).(int); ok {
+		e.errors = n
+	}
+	if d, ok := opts.Context.Value(ejectDurationKey{}).(time.Duration); ok && d > 0 {
+		e.duration = d
+	}
+	if p, ok := opts.Context.Value(maxEjectPercentKey{}).(int); ok && p >= 0 && p <= 100 {
+		e.maxPercent = p
+	}
+	if r, ok := opts.Context.Value(ejectRouterKey{}).(router.Router); ok && r != nil && e.errors > 0 && e.watcher == nil {
+		w, err := r.Watch()
+		if err != nil {
+			return
+		}
+		e.watcher = w
+		go e.watch(w)
+	}
+}
+
+// watch forgets the routes deleted from the router until the watcher stops
+func (e *ejector) watch(w router.Watcher) {
+	for {
+		ev, err := w.Next()
+		if err != nil {
+			return
+		}
+		if ev.Type != router.Delete {
+			continue
+		}
+		e.Lock()
+		delete(e.routes, ev.Route.Address)
+		e.Unlock()
+	}
+}
+
+// close stops watching the router
+func (e *ejector) close() {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.watcher != nil {
+		e.watcher.Stop()
+		e.watcher = nil
+	}
+}
+
+// failure returns true if the error counts against the health of the route.
+// Errors returned by the handler of the request e.g. a bad request don't, but
+// timeouts and rate limiting do.
+func failure(err error) bool {
+	merr, ok := err.(*errors.Error)
+	if !ok || merr.Code <= 0 || merr.Code >= 500 {
+		return true
+	}
+	switch merr.Code {
+	case 408, 429:
+		return true
+	}
+	return false
+}
+
+// record counts the consecutive errors of the route
+func (e *ejector) record(route router.Route, err error) {
+	if err == ErrCanceled {
+		return
+	}
+	if err != nil && !failure(err) {
+		err = nil
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	if e.errors <= 0 {
+		return
+	}
+
+	ej, ok := e.routes[route.Address]
+	if err == nil {
+		// a success closes the circuit
+		if ok {
+			delete(e.routes, route.Address)
+		}
+		return
+	}
+
+	if !ok {
+		ej = &ejection{}
+		e.routes[route.Address] = ej
+	}
+	ej.errors++
+	if ej.errors >= e.errors {
+		ej.ejected = time.Now()
+		ej.errors = 0
+	}
+}
+
+// ejected returns true if the route is ejected. Should be called under lock.
+func (e *ejector) ejected(route router.Route, now time.Time) bool {
+	ej, ok := e.routes[route.Address]
+	return ok && !ej.ejected.IsZero() && now.Sub(ej.ejected) < e.duration
+}
+
+// filter removes the ejected routes, up to the max percentage of them
+func (e *ejector) filter(old []router.Route) []router.Route {
+	e.RLock()
+	defer e.RUnlock()
+
+	if len(e.routes) == 0 {
+		return old
+	}
+
+	max := len(old) * e.maxPercent / 100
+	now := time.Now()
+
+	var removed int
+	routes := make([]router.Route, 0, len(old))
+	for _, r := range old {
+		if removed < max && e.ejected(r, now) {
+			removed++
+			continue
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
@@ -0,0 +1,158 @@
+package selector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/router"
+)
+
+func TestEject(t *testing.T) {
+	s := NewSelector(EjectErrors(2), EjectDuration(time.Millisecond*50), MaxEjectPercent(50))
+
+	routes := make([]router.Route, 4)
+	for i := range routes {
+		routes[i] = router.Route{Service: "foo", Address: fmt.Sprintf("10.0.0.%d:8080", i)}
+	}
+
+	selected := func() map[string]bool {
+		picked := make(map[string]bool)
+		for i := 0; i < 200; i++ {
+			route, err := s.Select(routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			picked[route.Address] = true
+		}
+		return picked
+	}
+
+	if picked := selected(); len(picked) != 4 {
+		t.Fatalf("Expected every route to be selected, got %v", picked)
+	}
+
+	// errors of the handler don't eject the route
+	for i := 0; i < 5; i++ {
+		s.Record(routes[0], errors.BadRequest("foo", "bad request"))
+	}
+	if picked := selected(); !picked[routes[0].Address] {
+		t.Fatal("Expected bad requests to not eject the route")
+	}
+
+	// consecutive errors eject the route
+	s.Record(routes[0], errors.InternalServerError("foo", "error"))
+	s.Record(routes[0], nil)
+	s.Record(routes[0], errors.InternalServerError("foo", "error"))
+	if picked := selected(); !picked[routes[0].Address] {
+		t.Fatal("Expected a success to reset the errors")
+	}
+	s.Record(routes[0], errors.InternalServerError("foo", "error"))
+	if picked := selected(); picked[routes[0].Address] || len(picked) != 3 {
+		t.Fatalf("Expected the route to be ejected, got %v", picked)
+	}
+
+	exp, err := Explain(s, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := exp.Candidate(routes[0]); c.Score != 0 || exp.Selected.Address == routes[0].Address {
+		t.Fatalf("Expected the explanation to skip the ejected route, got %+v", c)
+	}
+
+	// no more than the max percentage of the routes are ejected
+	for _, r := range routes[1:] {
+		s.Record(r, fmt.Errorf("connection refused"))
+		s.Record(r, fmt.Errorf("connection refused"))
+	}
+	if picked := selected(); len(picked) != 2 {
+		t.Fatalf("Expected half the routes to be selected, got %v", picked)
+	}
+
+	// the routes are selected again after the ejection
+	time.Sleep(time.Millisecond * 60)
+	if picked := selected(); len(picked) != 4 {
+		t.Fatalf("Expected every route to be selected after the ejection, got %v", picked)
+	}
+}
+
+func TestEjectDisabled(t *testing.T) {
+	s := NewSelector(EjectErrors(0))
+	routes := []router.Route{{Service: "foo", Address: "10.0.0.1:8080"}, {Service: "foo", Address: "10.0.0.2:8080"}}
+
+	for i := 0; i < 10; i++ {
+		s.Record(routes[0], fmt.Errorf("connection refused"))
+	}
+
+	picked := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		route, _ := s.Select(routes)
+		picked[route.Address] = true
+	}
+	if len(picked) != 2 {
+		t.Fatalf("Expected no route to be ejected, got %v", picked)
+	}
+}
+
+func TestEjectFailures(t *testing.T) {
+	testData := []struct {
+		err     error
+		failure bool
+	}{
+		{fmt.Errorf("connection refused"), true},
+		{errors.InternalServerError("foo", "error"), true},
+		{errors.Timeout("foo", "timeout"), true},
+		{errors.New("foo", "too many requests", 429), true},
+		{errors.BadRequest("foo", "bad request"), false},
+		{errors.NotFound("foo", "not found"), false},
+	}
+
+	for _, d := range testData {
+		if f := failure(d.err); f != d.failure {
+			t.Fatalf("Expected %v to be a failure %v, got %v", d.err, d.failure, f)
+		}
+	}
+}
+
+func TestEjectOptIn(t *testing.T) {
+	s := NewSelector().(*random)
+	route := router.Route{Service: "foo", Address: "10.0.0.1:8080"}
+	for i := 0; i < 10; i++ {
+		s.Record(route, fmt.Errorf("connection refused"))
+	}
+	if len(s.eject.routes) != 0 {
+		t.Fatal("Expected routes not to be ejected by default")
+	}
+}
+
+func TestEjectRouterDelete(t *testing.T) {
+	r := router.NewRouter(router.Registry(memory.NewRegistry()))
+	defer r.Close()
+
+	s := NewSelector(EjectErrors(5), EjectRouter(r)).(*random)
+	defer s.Close()
+
+	route := router.Route{Service: "foo", Address: "10.0.0.1:8080", Network: "go.micro", Router: "test", Link: "local"}
+	if err := r.Table().Create(route); err != nil {
+		t.Fatal(err)
+	}
+	s.Record(route, fmt.Errorf("connection refused"))
+
+	if err := r.Table().Delete(route); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.eject.RLock()
+		n := len(s.eject.routes)
+		s.eject.RUnlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatal("Expected the deleted route to be forgotten")
+}
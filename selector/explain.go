@@ -0,0 +1,106 @@
+package selector
+
+import (
+	"github.com/micro/go-micro/v2/router"
+)
+
+// Candidate is a route considered for selection
+type Candidate struct {
+	// Route considered
+	Route router.Route
+	// Filter is the index of the select filter which removed the route,
+	// -1 if the route passed all the filters
+	Filter int
+	// Score given to the route by the selector, its meaning depends on the selector
+	Score float64
+	// Reason explains the score
+	Reason string
+}
+
+// Explanation is the dry run of a selection
+type Explanation struct {
+	// Selector which made the selection
+	Selector string
+	// Candidates are all the routes selected from
+	Candidates []Candidate
+	// Selected is the route which would be picked
+	Selected *router.Route
+	// Reason explains the pick
+	Reason string
+}
+
+// Explainer is implemented by selectors which can explain their selection
+type Explainer interface {
+	// Explain how a route would be selected without affecting future selections
+	Explain([]router.Route, ...SelectOption) (*Explanation, error)
+}
+
+// Explain explains how the selector selects a route. Selectors which don't
+// implement Explainer are asked to select a route, which may affect their
+// future selections.
+func Explain(s Selector, routes []router.Route, opts ...SelectOption) (*Explanation, error) {
+	if e, ok := s.(Explainer); ok {
+		return e.Explain(routes, opts...)
+	}
+
+	options := NewSelectOptions(opts...)
+	exp := NewExplanation(s.String(), routes, options.Filters)
+
+	route, err := s.Select(routes, opts...)
+	if err != nil {
+		return exp, err
+	}
+
+	exp.Selected = route
+	exp.Reason = "selected by " + s.String()
+	return exp, nil
+}
+
+// NewExplanation applies the filters to the routes recording which filter removed each route
+func NewExplanation(selector string, routes []router.Route, filters []Filter) *Explanation {
+	exp := &Explanation{
+		Selector:   selector,
+		Candidates: make([]Candidate, len(routes)),
+	}
+
+	for i, route := range routes {
+		exp.Candidates[i] = Candidate{Route: route, Filter: -1}
+	}
+
+	for i, f := range filters {
+		passed := make(map[uint64]bool)
+		for _, route := range f(exp.Routes()) {
+			passed[route.Hash()] = true
+		}
+
+		for j, c := range exp.Candidates {
+			if c.Filter == -1 && !passed[c.Route.Hash()] {
+				exp.Candidates[j].Filter = i
+				exp.Candidates[j].Reason = "removed by filter"
+			}
+		}
+	}
+
+	return exp
+}
+
+// Routes returns the routes which passed all the filters
+func (e *Explanation) Routes() []router.Route {
+	var routes []router.Route
+	for _, c := range e.Candidates {
+		if c.Filter == -1 {
+			routes = append(routes, c.Route)
+		}
+	}
+	return routes
+}
+
+// Candidate returns the candidate of the route
+func (e *Explanation) Candidate(route router.Route) *Candidate {
+	for i, c := range e.Candidates {
+		if c.Route.Hash() == route.Hash() {
+			return &e.Candidates[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Package failover is a selector for active/passive deployments. Routes are
+// partitioned into tiers by the priority in their metadata and a tier is only
+// selected from once every route of the higher tiers has failed.
+package failover
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+var (
+	// DefaultMetadataKey is the route metadata key the priority is read from
+	DefaultMetadataKey = "priority"
+	// DefaultFailures is the number of consecutive errors marking a route failed
+	DefaultFailures = 3
+	// DefaultCooldown is how long a failed route is skipped for
+	DefaultCooldown = 30 * time.Second
+
+	// priorities are the names of the tiers, routes without a priority are primary
+	priorities = map[string]int{
+		"primary":   0,
+		"secondary": 1,
+		"tertiary":  2,
+	}
+)
+
+// NewSelector returns a failover selector
+func NewSelector(opts ...selector.Option) selector.Selector {
+	f := &failover{
+		health: make(map[string]*health),
+	}
+	f.Init(opts...)
+	return f
+}
+
+type failover struct {
+	sync.Mutex
+
+	opts     selector.Options
+	key      string
+	failures int
+	cooldown time.Duration
+	selector selector.Selector
+
+	// health of the routes by address
+	health map[string]*health
+}
+
+// health of a route
+type health struct {
+	// failures is the number of consecutive errors
+	failures int
+	// failed is when the route was marked failed, zero if it's healthy
+	failed time.Time
+}
+
+func (f *failover) Init(opts ...selector.Option) error {
+	f.Lock()
+	defer f.Unlock()
+
+	for _, o := range opts {
+		o(&f.opts)
+	}
+
+	f.key = DefaultMetadataKey
+	f.failures = DefaultFailures
+	f.cooldown = DefaultCooldown
+	f.selector = selector.DefaultSelector
+
+	if ctx := f.opts.Context; ctx != nil {
+		if k, ok := ctx.Value(metadataKey{}).(string); ok && len(k) > 0 {
+			f.key = k
+		}
+		if n, ok := ctx.Value(failuresKey{}).(int); ok && n > 0 {
+			f.failures = n
+		}
+		if d, ok := ctx.Value(cooldownKey{}).(time.Duration); ok && d > 0 {
+			f.cooldown = d
+		}
+		if s, ok := ctx.Value(selectorKey{}).(selector.Selector); ok && s != nil {
+			f.selector = s
+		}
+	}
+
+	return nil
+}
+
+func (f *failover) Options() selector.Options {
+	f.Lock()
+	defer f.Unlock()
+	return f.opts
+}
+
+// priority returns the tier of the route, the lower the higher the priority
+func (f *failover) priority(route router.Route) int {
+	v := route.Metadata[f.key]
+	if p, ok := priorities[v]; ok {
+		return p
+	}
+	p, err := strconv.Atoi(v)
+	if err != nil || p < 0 {
+		return 0
+	}
+	return p
+}
+
+// failed returns true if the route is marked failed and still cooling down.
+// Should be called under lock.
+func (f *failover) failed(route router.Route, now time.Time) bool {
+	h, ok := f.health[route.Address]
+	if !ok || h.failed.IsZero() {
+		return false
+	}
+	return now.Sub(h.failed) < f.cooldown
+}
+
+// tier returns the healthy routes of the highest priority tier with any, or
+// every route if they've all failed, and the priority of the tier. Should be
+// called under lock.
+func (f *failover) tier(routes []router.Route) ([]router.Route, int) {
+	tiers := make(map[int][]router.Route)
+	var order []int
+
+	now := time.Now()
+	for _, route := range routes {
+		if f.failed(route, now) {
+			continue
+		}
+		p := f.priority(route)
+		if _, ok := tiers[p]; !ok {
+			order = append(order, p)
+		}
+		tiers[p] = append(tiers[p], route)
+	}
+
+	// better to try a failed route than none
+	if len(order) == 0 {
+		return routes, -1
+	}
+
+	sort.Ints(order)
+	return tiers[order[0]], order[0]
+}
+
+func (f *failover) Select(routes []router.Route, opts ...selector.SelectOption) (*router.Route, error) {
+	// parse the options
+	options := selector.NewSelectOptions(opts...)
+
+	// apply the filters
+	for _, filter := range options.Filters {
+		routes = filter(routes)
+	}
+
+	// we can't select from an empty pool of routes
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	f.Lock()
+	tier, _ := f.tier(routes)
+	sel := f.selector
+	f.Unlock()
+
+	return sel.Select(tier, selector.WithContext(options.Context))
+}
+
+// Explain scores the routes by their priority and explains which tier is selected from
+func (f *failover) Explain(routes []router.Route, opts ...selector.SelectOption) (*selector.Explanation, error) {
+	options := selector.NewSelectOptions(opts...)
+	exp := selector.NewExplanation(f.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+	if len(routes) == 0 {
+		exp.Reason = "no routes passed the filters"
+		return exp, selector.ErrNoneAvailable
+	}
+
+	f.Lock()
+	now := time.Now()
+	for _, route := range routes {
+		c := exp.Candidate(route)
+		p := f.priority(route)
+		c.Score = -float64(p)
+		if f.failed(route, now) {
+			c.Reason = fmt.Sprintf("priority %d, failed", p)
+		} else {
+			c.Reason = fmt.Sprintf("priority %d", p)
+		}
+	}
+	tier, p := f.tier(routes)
+	sel := f.selector
+	f.Unlock()
+
+	inner, err := selector.Explain(sel, tier, selector.WithContext(options.Context))
+	if err != nil {
+		exp.Reason = inner.Reason
+		return exp, err
+	}
+
+	exp.Selected = inner.Selected
+	if p < 0 {
+		exp.Reason = "every route has failed, picked from all of them"
+	} else {
+		exp.Reason = fmt.Sprintf("picked from the %d healthy routes of priority %d", len(tier), p)
+	}
+	return exp, nil
+}
+
+// Record marks the route failed after consecutive errors, a success marks it healthy
+func (f *failover) Record(route router.Route, err error) error {
+	f.Lock()
+
+	switch err {
+	case selector.ErrCanceled:
+		// nothing to learn of the health of the route
+	case nil:
+		delete(f.health, route.Address)
+	default:
+		h, ok := f.health[route.Address]
+		if !ok {
+			h = &health{}
+			f.health[route.Address] = h
+		}
+		h.failures++
+		if h.failures >= f.failures {
+			h.failed = time.Now()
+		}
+	}
+
+	sel := f.selector
+	f.Unlock()
+
+	return sel.Record(route, err)
+}
+
+func (f *failover) Close() error {
+	return nil
+}
+
+func (f *failover) String() string {
+	return "failover"
+}
@@ -0,0 +1,101 @@
+package failover
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+func TestFailover(t *testing.T) {
+	selector.Tests(t, NewSelector())
+}
+
+func TestFailoverTiers(t *testing.T) {
+	s := NewSelector(Failures(2), Cooldown(time.Millisecond*50))
+
+	var routes []router.Route
+	for i, p := range []string{"primary", "primary", "secondary", "2"} {
+		routes = append(routes, router.Route{
+			Service:  "go.micro.service.foo",
+			Address:  fmt.Sprintf("127.0.0.1:%d", 8000+i),
+			Metadata: map[string]string{"priority": p},
+		})
+	}
+
+	tierOf := func() map[string]bool {
+		picked := make(map[string]bool)
+		for i := 0; i < 50; i++ {
+			route, err := s.Select(routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			picked[route.Address] = true
+		}
+		return picked
+	}
+
+	fail := func(route router.Route) {
+		for i := 0; i < 2; i++ {
+			s.Record(route, errors.New("failed"))
+		}
+	}
+
+	// only the primaries are selected
+	for addr := range tierOf() {
+		if addr != routes[0].Address && addr != routes[1].Address {
+			t.Fatalf("Expected a primary route, got %s", addr)
+		}
+	}
+
+	// one failed primary leaves the other
+	fail(routes[0])
+	if picked := tierOf(); len(picked) != 1 || !picked[routes[1].Address] {
+		t.Fatalf("Expected the healthy primary, got %v", picked)
+	}
+
+	// the secondary once every primary failed
+	fail(routes[1])
+	if picked := tierOf(); len(picked) != 1 || !picked[routes[2].Address] {
+		t.Fatalf("Expected the secondary, got %v", picked)
+	}
+
+	exp, err := selector.Explain(s, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Selected.Address != routes[2].Address {
+		t.Fatalf("Expected the explanation to pick the secondary, got %s", exp.Selected.Address)
+	}
+
+	// a success marks the primary healthy again
+	s.Record(routes[0], nil)
+	if picked := tierOf(); len(picked) != 1 || !picked[routes[0].Address] {
+		t.Fatalf("Expected the recovered primary, got %v", picked)
+	}
+
+	// failed routes are tried again after the cooldown
+	fail(routes[0])
+	time.Sleep(time.Millisecond * 60)
+	for addr := range tierOf() {
+		if addr != routes[0].Address && addr != routes[1].Address {
+			t.Fatalf("Expected the primaries after the cooldown, got %s", addr)
+		}
+	}
+}
+
+func TestFailoverAllFailed(t *testing.T) {
+	s := NewSelector(Failures(1))
+	routes := []router.Route{{Service: "go.micro.service.foo", Address: "127.0.0.1:8000"}}
+
+	s.Record(routes[0], errors.New("failed"))
+
+	// a failed route is better than none
+	route, err := s.Select(routes)
+	if err != nil || route.Address != routes[0].Address {
+		t.Fatalf("Expected the failed route, got %v %v", route, err)
+	}
+}
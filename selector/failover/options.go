@@ -0,0 +1,45 @@
+package failover
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/selector"
+)
+
+type metadataKey struct{}
+
+type failuresKey struct{}
+
+type cooldownKey struct{}
+
+type selectorKey struct{}
+
+// MetadataKey sets the route metadata key the priority is read from
+func MetadataKey(k string) selector.Option {
+	return setOption(metadataKey{}, k)
+}
+
+// Failures sets the number of consecutive errors after which a route is marked failed
+func Failures(n int) selector.Option {
+	return setOption(failuresKey{}, n)
+}
+
+// Cooldown sets how long a failed route is skipped before it's tried again
+func Cooldown(d time.Duration) selector.Option {
+	return setOption(cooldownKey{}, d)
+}
+
+// Selector sets the selector used to pick a route within a tier
+func Selector(s selector.Selector) selector.Option {
+	return setOption(selectorKey{}, s)
+}
+
+func setOption(k, v interface{}) selector.Option {
+	return func(o *selector.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
@@ -0,0 +1,75 @@
+package selector
+
+import (
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/router"
+)
+
+// FilterLabel returns a filter which keeps the routes with the metadata value
+func FilterLabel(key, val string) Filter {
+	return func(old []router.Route) []router.Route {
+		var routes []router.Route
+		for _, r := range old {
+			if r.Metadata[key] == val {
+				routes = append(routes, r)
+			}
+		}
+		return routes
+	}
+}
+
+// FilterVersion returns a filter which keeps the routes of the version
+func FilterVersion(version string) Filter {
+	return func(old []router.Route) []router.Route {
+		var routes []router.Route
+		for _, r := range old {
+			if r.Version == version {
+				routes = append(routes, r)
+			}
+		}
+		return routes
+	}
+}
+
+// FilterVersionConstraint returns a filter which keeps the routes with a
+// version satisfying the constraint e.g. ">=1.2 <2". An invalid constraint
+// keeps no routes, use ParseConstraint to validate it first.
+func FilterVersionConstraint(constraint string) Filter {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		logger.Errorf("Error parsing version constraint: %v", err)
+	}
+
+	return func(old []router.Route) []router.Route {
+		var routes []router.Route
+		if c == nil {
+			return routes
+		}
+		for _, r := range old {
+			if c.Check(r.Version) {
+				routes = append(routes, r)
+			}
+		}
+		return routes
+	}
+}
+
+// FilterAny returns a filter which keeps the routes kept by any of the filters
+func FilterAny(filters ...Filter) Filter {
+	return func(old []router.Route) []router.Route {
+		keep := make(map[uint64]bool)
+		for _, f := range filters {
+			for _, r := range f(old) {
+				keep[r.Hash()] = true
+			}
+		}
+
+		var routes []router.Route
+		for _, r := range old {
+			if keep[r.Hash()] {
+				routes = append(routes, r)
+			}
+		}
+		return routes
+	}
+}
@@ -0,0 +1,96 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v2/router"
+)
+
+func TestFilters(t *testing.T) {
+	routes := []router.Route{
+		{Service: "foo", Version: "1.0.0", Address: "10.0.0.1:8080", Metadata: map[string]string{"env": "prod"}},
+		{Service: "foo", Version: "1.2.3", Address: "10.0.0.2:8080", Metadata: map[string]string{"env": "prod"}},
+		{Service: "foo", Version: "v1.9", Address: "10.0.0.3:8080", Metadata: map[string]string{"env": "canary"}},
+		{Service: "foo", Version: "2.0.0-beta.1", Address: "10.0.0.4:8080"},
+		{Service: "foo", Version: "latest", Address: "10.0.0.5:8080"},
+	}
+
+	addrs := func(routes []router.Route) string {
+		var s string
+		for _, r := range routes {
+			s += r.Address[7:8]
+		}
+		return s
+	}
+
+	testData := []struct {
+		name   string
+		filter Filter
+		expect string
+	}{
+		{"label", FilterLabel("env", "prod"), "12"},
+		{"version", FilterVersion("latest"), "5"},
+		{"range", FilterVersionConstraint(">=1.2 <2"), "23"},
+		{"prerelease", FilterVersionConstraint("<2"), "123"},
+		{"caret", FilterVersionConstraint("^1.2"), "23"},
+		{"tilde", FilterVersionConstraint("~1.2.0"), "2"},
+		{"alternatives", FilterVersionConstraint("=1.0.0 || >=2.0.0-alpha"), "14"},
+		{"not equal", FilterVersionConstraint(">=1, !=1.2.3"), "13"},
+		{"invalid", FilterVersionConstraint(">=one"), ""},
+		{"any", FilterAny(FilterLabel("env", "canary"), FilterVersion("latest")), "35"},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			if got := addrs(d.filter(routes)); got != d.expect {
+				t.Fatalf("Expected routes %s, got %s", d.expect, got)
+			}
+		})
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	for _, c := range []string{"", "||", ">=1.2.3.4", "^x"} {
+		if _, err := ParseConstraint(c); err == nil {
+			t.Fatalf("Expected an error parsing %q", c)
+		}
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	// in increasing order of precedence
+	versions := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+	}
+
+	for i := range versions {
+		for j := range versions {
+			a, err := ParseVersion(versions[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ParseVersion(versions[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expect := 0
+			if i < j {
+				expect = -1
+			} else if i > j {
+				expect = 1
+			}
+			if d := a.Compare(b); d != expect {
+				t.Fatalf("Expected %s compared to %s to be %d, got %d", versions[i], versions[j], expect, d)
+			}
+		}
+	}
+}
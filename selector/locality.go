@@ -0,0 +1,72 @@
+package selector
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/micro/go-micro/v2/router"
+)
+
+var (
+	// RegionKey is the route metadata key of the node region
+	RegionKey = "region"
+	// ZoneKey is the route metadata key of the node zone
+	ZoneKey = "zone"
+)
+
+// Locality is where the local node runs
+type Locality struct {
+	// Region of the local node e.g. eu-west-1
+	Region string
+	// Zone of the local node e.g. eu-west-1a
+	Zone string
+	// MinRoutes is the number of routes the local zone or region needs to have
+	// to be preferred, fewer and the selection falls back to the wider locality.
+	// Defaults to 1.
+	MinRoutes int
+}
+
+// LocalityFromEnv reads the locality from the MICRO_REGION, MICRO_ZONE and
+// MICRO_LOCALITY_MIN_ROUTES env vars
+func LocalityFromEnv() Locality {
+	l := Locality{
+		Region: os.Getenv("MICRO_REGION"),
+		Zone:   os.Getenv("MICRO_ZONE"),
+	}
+	if n, err := strconv.Atoi(os.Getenv("MICRO_LOCALITY_MIN_ROUTES")); err == nil {
+		l.MinRoutes = n
+	}
+	return l
+}
+
+// PreferLocality returns a filter which keeps the routes in the local zone, or
+// the local region if the zone doesn't have enough routes, to cut cross zone
+// traffic. If neither have enough routes they're all returned.
+func PreferLocality(l Locality) Filter {
+	min := l.MinRoutes
+	if min <= 0 {
+		min = 1
+	}
+
+	return func(old []router.Route) []router.Route {
+		var zone, region []router.Route
+		for _, r := range old {
+			inRegion := len(l.Region) > 0 && r.Metadata[RegionKey] == l.Region
+			if inRegion {
+				region = append(region, r)
+			}
+			// zone names may only be unique within a region
+			if len(l.Zone) > 0 && r.Metadata[ZoneKey] == l.Zone && (inRegion || len(l.Region) == 0) {
+				zone = append(zone, r)
+			}
+		}
+
+		if len(zone) >= min {
+			return zone
+		}
+		if len(region) >= min {
+			return region
+		}
+		return old
+	}
+}
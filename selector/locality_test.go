@@ -0,0 +1,58 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/micro/go-micro/v2/router"
+)
+
+func TestPreferLocality(t *testing.T) {
+	route := func(addr, region, zone string) router.Route {
+		return router.Route{
+			Service:  "go.micro.service.foo",
+			Address:  addr,
+			Metadata: map[string]string{RegionKey: region, ZoneKey: zone},
+		}
+	}
+
+	routes := []router.Route{
+		route("a", "eu-west-1", "eu-west-1a"),
+		route("b", "eu-west-1", "eu-west-1b"),
+		route("c", "eu-west-1", "eu-west-1b"),
+		route("d", "us-east-1", "us-east-1a"),
+	}
+
+	testData := []struct {
+		locality Locality
+		expect   []string
+	}{
+		// the local zone first
+		{Locality{Region: "eu-west-1", Zone: "eu-west-1a"}, []string{"a"}},
+		// the local region when the zone doesn't have enough routes
+		{Locality{Region: "eu-west-1", Zone: "eu-west-1a", MinRoutes: 2}, []string{"a", "b", "c"}},
+		{Locality{Region: "eu-west-1", Zone: "eu-west-1b", MinRoutes: 2}, []string{"b", "c"}},
+		// every route when the region doesn't have enough routes
+		{Locality{Region: "eu-west-1", MinRoutes: 4}, []string{"a", "b", "c", "d"}},
+		{Locality{Region: "ap-south-1", Zone: "ap-south-1a"}, []string{"a", "b", "c", "d"}},
+		// no locality
+		{Locality{}, []string{"a", "b", "c", "d"}},
+	}
+
+	for _, d := range testData {
+		got := PreferLocality(d.locality)(routes)
+
+		var addrs []string
+		for _, r := range got {
+			addrs = append(addrs, r.Address)
+		}
+
+		if len(addrs) != len(d.expect) {
+			t.Fatalf("Expected %v for %+v, got %v", d.expect, d.locality, addrs)
+		}
+		for i := range addrs {
+			if addrs[i] != d.expect[i] {
+				t.Fatalf("Expected %v for %+v, got %v", d.expect, d.locality, addrs)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package selector
+
+import (
+	"time"
+
+	"github.com/micro/go-micro/v2/router"
+)
+
+// Observer is implemented by selectors which use the latency of calls to inform selection
+type Observer interface {
+	// Observe the latency and error of a call made using a route
+	Observe(router.Route, time.Duration, error) error
+}
+
+// Observe reports the latency and error of a call to the selector if it's an Observer
+func Observe(s Selector, route router.Route, latency time.Duration, err error) error {
+	if o, ok := s.(Observer); ok {
+		return o.Observe(route, latency, err)
+	}
+	return nil
+}
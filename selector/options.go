@@ -1,9 +1,17 @@
 package selector
 
-import "github.com/micro/go-micro/v2/router"
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/router"
+)
 
 // Options used to configure a selector
-type Options struct{}
+type Options struct {
+	// Other options for implementations of the interface
+	// can be stored in a context
+	Context context.Context
+}
 
 // Option updates the options
 type Option func(*Options)
@@ -14,6 +22,8 @@ type Filter func([]router.Route) []router.Route
 // SelectOptions used to configure selection
 type SelectOptions struct {
 	Filters []Filter
+	// Context of the request the route is selected for
+	Context context.Context
 }
 
 // SelectOption updates the select options
@@ -26,6 +36,14 @@ func WithFilter(f Filter) SelectOption {
 	}
 }
 
+// WithContext passes the context of the request to the selector, e.g. so
+// it can select the route based on the request metadata
+func WithContext(ctx context.Context) SelectOption {
+	return func(o *SelectOptions) {
+		o.Context = ctx
+	}
+}
+
 // NewSelectOptions parses select options
 func NewSelectOptions(opts ...SelectOption) SelectOptions {
 	var options SelectOptions
@@ -0,0 +1,33 @@
+package peakewma
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/selector"
+)
+
+type decayKey struct{}
+
+type errorPenaltyKey struct{}
+
+// Decay sets the time over which the latency of a route decays, the lower the
+// quicker the selector reacts to a route getting faster
+func Decay(d time.Duration) selector.Option {
+	return setOption(decayKey{}, d)
+}
+
+// ErrorPenalty sets the latency recorded for a failed call, so routes returning
+// errors quickly aren't preferred over healthy ones
+func ErrorPenalty(d time.Duration) selector.Option {
+	return setOption(errorPenaltyKey{}, d)
+}
+
+func setOption(k, v interface{}) selector.Option {
+	return func(o *selector.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
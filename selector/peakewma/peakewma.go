@@ -0,0 +1,265 @@
+// Package peakewma is a latency aware selector. It tracks a peak sensitive moving
+// average of the latency of every route and picks the cheaper of two random
+// routes, the cost being the latency times the requests in flight, so load
+// shifts away from slow or failing routes.
+package peakewma
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+var (
+	// DefaultDecay is the default time over which the latency decays
+	DefaultDecay = 10 * time.Second
+	// DefaultErrorPenalty is the default latency recorded for a failed call
+	DefaultErrorPenalty = time.Second
+
+	// penalty is the cost of a route with requests in flight but no latency
+	// recorded yet, so new routes aren't flooded before their latency is known
+	penalty = math.MaxFloat64 / 2
+
+	// stateTTL is how long the latency of an unused route is kept
+	stateTTL = time.Minute * 15
+)
+
+// NewSelector returns a peak EWMA selector
+func NewSelector(opts ...selector.Option) selector.Selector {
+	p := &peakEWMA{
+		routes: make(map[string]*stats),
+	}
+	p.Init(opts...)
+	return p
+}
+
+type peakEWMA struct {
+	sync.Mutex
+
+	opts         selector.Options
+	decay        time.Duration
+	errorPenalty time.Duration
+
+	// routes are the stats per route address
+	routes map[string]*stats
+	// pruned is when unused stats were last removed
+	pruned time.Time
+}
+
+// stats are the latency and load of a route
+type stats struct {
+	// ewma is the moving average of the latency in nanoseconds
+	ewma float64
+	// pending is the number of requests in flight
+	pending int64
+	// updated is when the average was last updated
+	updated time.Time
+}
+
+func (p *peakEWMA) Init(opts ...selector.Option) error {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, o := range opts {
+		o(&p.opts)
+	}
+
+	p.decay = DefaultDecay
+	p.errorPenalty = DefaultErrorPenalty
+
+	if ctx := p.opts.Context; ctx != nil {
+		if d, ok := ctx.Value(decayKey{}).(time.Duration); ok && d > 0 {
+			p.decay = d
+		}
+		if d, ok := ctx.Value(errorPenaltyKey{}).(time.Duration); ok && d > 0 {
+			p.errorPenalty = d
+		}
+	}
+
+	return nil
+}
+
+func (p *peakEWMA) Options() selector.Options {
+	p.Lock()
+	defer p.Unlock()
+	return p.opts
+}
+
+// cost returns the cost of sending a request to the route. Should be called under lock.
+func (p *peakEWMA) cost(route router.Route, now time.Time) float64 {
+	s, ok := p.routes[route.Address]
+	if !ok {
+		return 0
+	}
+
+	ewma := p.decayed(s, now)
+	if ewma == 0 && s.pending > 0 {
+		return penalty
+	}
+	return ewma * float64(s.pending+1)
+}
+
+// decayed returns the average latency of the route decayed up to now
+func (p *peakEWMA) decayed(s *stats, now time.Time) float64 {
+	elapsed := now.Sub(s.updated)
+	if elapsed <= 0 {
+		return s.ewma
+	}
+	return s.ewma * math.Exp(-float64(elapsed)/float64(p.decay))
+}
+
+// pick returns the cheaper of two random routes and why it was picked. Should be called under lock.
+func (p *peakEWMA) pick(routes []router.Route, now time.Time) (int, string) {
+	if len(routes) == 1 {
+		return 0, "only route"
+	}
+
+	// power of two choices
+	a := rand.Intn(len(routes))
+	b := rand.Intn(len(routes) - 1)
+	if b >= a {
+		b++
+	}
+
+	ca, cb := p.cost(routes[a], now), p.cost(routes[b], now)
+	if cb < ca {
+		a, b, ca, cb = b, a, cb, ca
+	}
+
+	return a, fmt.Sprintf("cost %.0f is lower than the cost %.0f of %s", ca, cb, routes[b].Address)
+}
+
+func (p *peakEWMA) Select(routes []router.Route, opts ...selector.SelectOption) (*router.Route, error) {
+	// parse the options
+	options := selector.NewSelectOptions(opts...)
+
+	// apply the filters
+	for _, f := range options.Filters {
+		routes = f(routes)
+	}
+
+	// we can't select from an empty pool of routes
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	i, _ := p.pick(routes, now)
+
+	s, ok := p.routes[routes[i].Address]
+	if !ok {
+		s = &stats{updated: now}
+		p.routes[routes[i].Address] = s
+	}
+	s.pending++
+
+	p.prune(now)
+
+	return &routes[i], nil
+}
+
+// Explain scores the routes by their cost, the cheaper of two random routes is picked
+func (p *peakEWMA) Explain(routes []router.Route, opts ...selector.SelectOption) (*selector.Explanation, error) {
+	options := selector.NewSelectOptions(opts...)
+	exp := selector.NewExplanation(p.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+	if len(routes) == 0 {
+		exp.Reason = "no routes passed the filters"
+		return exp, selector.ErrNoneAvailable
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	for _, route := range routes {
+		c := exp.Candidate(route)
+		c.Score = p.cost(route, now)
+
+		s, ok := p.routes[route.Address]
+		if !ok {
+			c.Reason = "no latency recorded"
+			continue
+		}
+		c.Reason = fmt.Sprintf("latency %v with %d requests in flight", time.Duration(p.decayed(s, now)), s.pending)
+	}
+
+	i, reason := p.pick(routes, now)
+	exp.Selected = &routes[i]
+	exp.Reason = reason
+	return exp, nil
+}
+
+// Record marks the request sent to the route as done
+func (p *peakEWMA) Record(route router.Route, err error) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if s, ok := p.routes[route.Address]; ok && s.pending > 0 {
+		s.pending--
+	}
+
+	return nil
+}
+
+// Observe updates the average latency of the route. A latency above the
+// average replaces it so the selector reacts to slow routes immediately.
+func (p *peakEWMA) Observe(route router.Route, latency time.Duration, err error) error {
+	if err != nil && latency < p.errorPenalty {
+		latency = p.errorPenalty
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+
+	s, ok := p.routes[route.Address]
+	if !ok {
+		s = &stats{updated: now}
+		p.routes[route.Address] = s
+	}
+
+	rtt := float64(latency)
+	if rtt > s.ewma {
+		s.ewma = rtt
+	} else {
+		w := math.Exp(-float64(now.Sub(s.updated)) / float64(p.decay))
+		s.ewma = s.ewma*w + rtt*(1-w)
+	}
+	s.updated = now
+
+	return nil
+}
+
+// prune removes the stats of the routes which haven't been used for a while.
+// Should be called under lock.
+func (p *peakEWMA) prune(now time.Time) {
+	if now.Sub(p.pruned) < time.Minute {
+		return
+	}
+	p.pruned = now
+
+	for addr, s := range p.routes {
+		if s.pending == 0 && now.Sub(s.updated) > stateTTL {
+			delete(p.routes, addr)
+		}
+	}
+}
+
+func (p *peakEWMA) Close() error {
+	return nil
+}
+
+func (p *peakEWMA) String() string {
+	return "peakewma"
+}
@@ -0,0 +1,91 @@
+package peakewma
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+func TestPeakEWMA(t *testing.T) {
+	selector.Tests(t, NewSelector())
+}
+
+func testRoutes(n int) []router.Route {
+	routes := make([]router.Route, n)
+	for i := range routes {
+		routes[i] = router.Route{Service: "go.micro.service.foo", Address: fmt.Sprintf("127.0.0.1:%d", 8000+i)}
+	}
+	return routes
+}
+
+// simulate calls to the routes, the first route being slow or failing
+func simulate(s selector.Selector, routes []router.Route, calls int, slow time.Duration, err error) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < calls; i++ {
+		route, _ := s.Select(routes)
+		counts[route.Address]++
+
+		latency, rerr := time.Millisecond, error(nil)
+		if route.Address == routes[0].Address {
+			latency, rerr = slow, err
+		}
+
+		s.Record(*route, rerr)
+		selector.Observe(s, *route, latency, rerr)
+	}
+	return counts
+}
+
+func TestPeakEWMASlowRoute(t *testing.T) {
+	routes := testRoutes(3)
+	counts := simulate(NewSelector(), routes, 300, 100*time.Millisecond, nil)
+
+	// the slow route is only picked while the latency of the others isn't known
+	if c := counts[routes[0].Address]; c > 30 {
+		t.Fatalf("Expected the slow route to be avoided, got %d of 300 selections", c)
+	}
+}
+
+func TestPeakEWMAErrorPenalty(t *testing.T) {
+	routes := testRoutes(3)
+	s := NewSelector(ErrorPenalty(time.Second))
+
+	// failing fast is penalised as a slow call
+	counts := simulate(s, routes, 300, time.Microsecond, errors.New("failed"))
+	if c := counts[routes[0].Address]; c > 30 {
+		t.Fatalf("Expected the failing route to be avoided, got %d of 300 selections", c)
+	}
+}
+
+func TestPeakEWMAPending(t *testing.T) {
+	routes := testRoutes(2)
+	s := NewSelector()
+
+	// the same latency for both routes but requests in flight to the first
+	for _, route := range routes {
+		selector.Observe(s, route, time.Millisecond, nil)
+	}
+	for i := 0; i < 5; i++ {
+		s.(*peakEWMA).routes[routes[0].Address].pending++
+	}
+
+	for i := 0; i < 10; i++ {
+		route, _ := s.Select(routes)
+		if route.Address != routes[1].Address {
+			t.Fatalf("Expected the route with fewer requests in flight, got %s", route.Address)
+		}
+		s.Record(*route, nil)
+	}
+
+	exp, err := selector.Explain(s, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Candidate(routes[0]).Score <= exp.Candidate(routes[1]).Score {
+		t.Fatalf("Expected the loaded route to cost more, got %+v", exp.Candidates)
+	}
+}
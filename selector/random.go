@@ -1,19 +1,37 @@
 package selector
 
 import (
+	"fmt"
 	"math/rand"
+	"sync"
 
 	"github.com/micro/go-micro/v2/router"
 )
 
-type random struct{}
+type random struct {
+	sync.RWMutex
+	opts Options
+
+	// eject the routes which repeatedly fail
+	eject *ejector
+}
 
 func (r *random) Init(opts ...Option) error {
+	r.Lock()
+	for _, o := range opts {
+		o(&r.opts)
+	}
+	options := r.opts
+	r.Unlock()
+
+	r.eject.init(options)
 	return nil
 }
 
 func (r *random) Options() Options {
-	return Options{}
+	r.RLock()
+	defer r.RUnlock()
+	return r.opts
 }
 
 func (r *random) Select(routes []router.Route, opts ...SelectOption) (*router.Route, error) {
@@ -30,20 +48,55 @@ func (r *random) Select(routes []router.Route, opts ...SelectOption) (*router.Ro
 		return nil, ErrNoneAvailable
 	}
 
+	// skip the routes which repeatedly failed
+	routes = r.eject.filter(routes)
+
 	// if there is only one route provided we'll select it
 	if len(routes) == 1 {
 		return &routes[0], nil
 	}
 
 	// select a random route from the slice
-	return &routes[rand.Intn(len(routes)-1)], nil
+	return &routes[rand.Intn(len(routes))], nil
+}
+
+func (r *random) Explain(routes []router.Route, opts ...SelectOption) (*Explanation, error) {
+	options := NewSelectOptions(opts...)
+	exp := NewExplanation(r.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+	if len(routes) == 0 {
+		exp.Reason = "no routes passed the filters"
+		return exp, ErrNoneAvailable
+	}
+
+	// every route which isn't ejected is as likely to be selected
+	healthy := r.eject.filter(routes)
+	for _, route := range routes {
+		exp.Candidate(route).Reason = "ejected after repeated errors"
+	}
+	for _, route := range healthy {
+		c := exp.Candidate(route)
+		c.Score = 1 / float64(len(healthy))
+		c.Reason = "uniform chance of selection"
+	}
+
+	exp.Selected = &healthy[rand.Intn(len(healthy))]
+	exp.Reason = fmt.Sprintf("picked at random from %d routes", len(healthy))
+	if n := len(routes) - len(healthy); n > 0 {
+		exp.Reason += fmt.Sprintf(", %d ejected", n)
+	}
+	return exp, nil
 }
 
+// Record ejects the route from selection after repeated errors
 func (r *random) Record(route router.Route, err error) error {
+	r.eject.record(route, err)
 	return nil
 }
 
 func (r *random) Close() error {
+	r.eject.close()
 	return nil
 }
 
@@ -51,6 +104,8 @@ func (r *random) String() string {
 	return "random"
 }
 
-func newSelector(...Option) Selector {
-	return &random{}
+func newSelector(opts ...Option) Selector {
+	r := &random{eject: newEjector()}
+	r.Init(opts...)
+	return r
 }
@@ -1,6 +1,8 @@
 package roundrobin
 
 import (
+	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"time"
@@ -81,6 +83,58 @@ func (r *roundrobin) Select(routes []router.Route, opts ...selector.SelectOption
 	return &routes[0], nil
 }
 
+// Explain scores the routes by how long ago they were last used, the route
+// unused for the longest is picked
+func (r *roundrobin) Explain(routes []router.Route, opts ...selector.SelectOption) (*selector.Explanation, error) {
+	options := selector.NewSelectOptions(opts...)
+	exp := selector.NewExplanation(r.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+	if len(routes) == 0 {
+		exp.Reason = "no routes passed the filters"
+		return exp, selector.ErrNoneAvailable
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	for i, route := range routes {
+		c := exp.Candidate(route)
+
+		lastUsed, ok := r.routes[route.Hash()]
+		if !ok {
+			c.Score = math.Inf(1)
+			c.Reason = "never used"
+			// the first route not yet seen is prioritised
+			if exp.Selected == nil {
+				exp.Selected = &routes[i]
+				exp.Reason = "first route not used before"
+			}
+			continue
+		}
+
+		c.Score = now.Sub(lastUsed).Seconds()
+		c.Reason = fmt.Sprintf("last used %v ago", now.Sub(lastUsed))
+	}
+
+	if exp.Selected != nil {
+		return exp, nil
+	}
+
+	// otherwise the least recently used route is picked
+	selected := 0
+	for i, route := range routes {
+		if r.routes[route.Hash()].Before(r.routes[routes[selected].Hash()]) {
+			selected = i
+		}
+	}
+
+	exp.Selected = &routes[selected]
+	exp.Reason = "least recently used route"
+	return exp, nil
+}
+
 func (r *roundrobin) Record(srv router.Route, err error) error {
 	return nil
 }
@@ -13,6 +13,10 @@ var (
 
 	// ErrNoneAvailable is returned by select when no routes were provided to select from
 	ErrNoneAvailable = errors.New("none available")
+	// ErrCanceled is recorded for the calls cancelled before they answered,
+	// e.g. the hedges which lost. It releases the load of the route but says
+	// nothing of its health.
+	ErrCanceled = errors.New("call canceled")
 )
 
 // Selector selects a route from a pool
@@ -0,0 +1,48 @@
+package sticky
+
+import (
+	"context"
+	"time"
+
+	"github.com/micro/go-micro/v2/selector"
+)
+
+type keyFuncKey struct{}
+
+type ttlKey struct{}
+
+type sizeKey struct{}
+
+type selectorKey struct{}
+
+// KeyFunc sets the func returning the key of the caller pinned to a route,
+// by default the Micro-Sticky-Key header or the ID of the account
+func KeyFunc(fn func(ctx context.Context) string) selector.Option {
+	return setOption(keyFuncKey{}, fn)
+}
+
+// TTL sets how long a caller stays pinned to a route since it last used it
+func TTL(d time.Duration) selector.Option {
+	return setOption(ttlKey{}, d)
+}
+
+// Size sets the max number of pinned callers, the least recently used are
+// unpinned first
+func Size(n int) selector.Option {
+	return setOption(sizeKey{}, n)
+}
+
+// Selector sets the selector used to pick the route a caller is pinned to, by
+// default a selector of its own. Only the results of its picks are recorded to it.
+func Selector(s selector.Selector) selector.Option {
+	return setOption(selectorKey{}, s)
+}
+
+func setOption(k, v interface{}) selector.Option {
+	return func(o *selector.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
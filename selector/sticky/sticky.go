@@ -0,0 +1,304 @@
+// Package sticky is a selector which pins callers to a route, e.g. so the
+// requests of an account hit the same node and its caches. Callers are pinned
+// until they haven't used the route for the TTL or the route disappears, in
+// which case they're pinned to a new route.
+package sticky
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+var (
+	// DefaultHeader is the request metadata header keying the caller
+	DefaultHeader = "Micro-Sticky-Key"
+	// DefaultTTL is how long a caller stays pinned since it last used the route
+	DefaultTTL = 30 * time.Minute
+	// DefaultSize is the default max number of pinned callers
+	DefaultSize = 10000
+)
+
+// DefaultKey returns the Micro-Sticky-Key header of the request, or the ID of
+// the account making it
+func DefaultKey(ctx context.Context) string {
+	if key, ok := metadata.Get(ctx, DefaultHeader); ok && len(key) > 0 {
+		return key
+	}
+	if acc, ok := auth.AccountFromContext(ctx); ok && acc != nil {
+		return acc.ID
+	}
+	return ""
+}
+
+// NewSelector returns a sticky selector
+func NewSelector(opts ...selector.Option) selector.Selector {
+	s := &sticky{
+		pins:  make(map[string]*list.Element),
+		lru:   list.New(),
+		own:   selector.NewSelector(),
+		picks: make(map[uint64]int),
+	}
+	s.Init(opts...)
+	return s
+}
+
+type sticky struct {
+	sync.Mutex
+
+	opts     selector.Options
+	key      func(ctx context.Context) string
+	ttl      time.Duration
+	size     int
+	selector selector.Selector
+
+	// own is the selector used unless one is set, not shared with other selectors
+	own selector.Selector
+
+	// pins by caller and service, the most recently used at the front
+	pins map[string]*list.Element
+	lru  *list.List
+
+	// picks of the underlying selector by route hash which weren't recorded yet
+	picks map[uint64]int
+}
+
+// pin is a caller pinned to a route
+type pin struct {
+	key     string
+	address string
+	expires time.Time
+}
+
+func (s *sticky) Init(opts ...selector.Option) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, o := range opts {
+		o(&s.opts)
+	}
+
+	s.key = DefaultKey
+	s.ttl = DefaultTTL
+	s.size = DefaultSize
+	s.selector = s.own
+
+	if ctx := s.opts.Context; ctx != nil {
+		if fn, ok := ctx.Value(keyFuncKey{}).(func(context.Context) string); ok && fn != nil {
+			s.key = fn
+		}
+		if d, ok := ctx.Value(ttlKey{}).(time.Duration); ok && d > 0 {
+			s.ttl = d
+		}
+		if n, ok := ctx.Value(sizeKey{}).(int); ok && n > 0 {
+			s.size = n
+		}
+		if sel, ok := ctx.Value(selectorKey{}).(selector.Selector); ok && sel != nil {
+			s.selector = sel
+		}
+	}
+
+	// the picks of a replaced selector are never recorded
+	for h := range s.picks {
+		delete(s.picks, h)
+	}
+
+	// drop the least recently used pins over the new size
+	for s.lru.Len() > s.size {
+		s.remove(s.lru.Back())
+	}
+
+	return nil
+}
+
+func (s *sticky) Options() selector.Options {
+	s.Lock()
+	defer s.Unlock()
+	return s.opts
+}
+
+// pinKey returns the key of the caller's pin to a route of the service, empty
+// if the request has no caller
+func (s *sticky) pinKey(routes []router.Route, options selector.SelectOptions) string {
+	if options.Context == nil || len(routes) == 0 {
+		return ""
+	}
+	key := s.key(options.Context)
+	if len(key) == 0 {
+		return ""
+	}
+	return routes[0].Service + "/" + key
+}
+
+// pinned returns the index of the route the caller is pinned to, -1 if it isn't
+// pinned or the route is gone. Should be called under lock.
+func (s *sticky) pinned(key string, routes []router.Route, now time.Time) int {
+	el, ok := s.pins[key]
+	if !ok {
+		return -1
+	}
+
+	p := el.Value.(*pin)
+	if now.After(p.expires) {
+		s.remove(el)
+		return -1
+	}
+
+	for i, route := range routes {
+		if route.Address == p.address {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// save pins the caller to the route, or extends the pin. Should be called under lock.
+func (s *sticky) save(key string, route router.Route, now time.Time) {
+	if el, ok := s.pins[key]; ok {
+		p := el.Value.(*pin)
+		p.address = route.Address
+		p.expires = now.Add(s.ttl)
+		s.lru.MoveToFront(el)
+		return
+	}
+
+	s.pins[key] = s.lru.PushFront(&pin{
+		key:     key,
+		address: route.Address,
+		expires: now.Add(s.ttl),
+	})
+
+	if s.lru.Len() > s.size {
+		s.remove(s.lru.Back())
+	}
+}
+
+// remove unpins a caller. Should be called under lock.
+func (s *sticky) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.pins, el.Value.(*pin).key)
+}
+
+func (s *sticky) Select(routes []router.Route, opts ...selector.SelectOption) (*router.Route, error) {
+	// parse the options
+	options := selector.NewSelectOptions(opts...)
+
+	// apply the filters
+	for _, f := range options.Filters {
+		routes = f(routes)
+	}
+
+	// we can't select from an empty pool of routes
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	key := s.pinKey(routes, options)
+	if len(key) == 0 {
+		return s.pick(routes, options)
+	}
+
+	now := time.Now()
+	if i := s.pinned(key, routes, now); i >= 0 {
+		s.save(key, routes[i], now)
+		return &routes[i], nil
+	}
+
+	// pin the caller to a new route
+	route, err := s.pick(routes, options)
+	if err != nil {
+		return nil, err
+	}
+	s.save(key, *route, now)
+	return route, nil
+}
+
+// pick selects a route with the underlying selector. Should be called under lock.
+func (s *sticky) pick(routes []router.Route, options selector.SelectOptions) (*router.Route, error) {
+	route, err := s.selector.Select(routes, selector.WithContext(options.Context))
+	if err != nil {
+		return nil, err
+	}
+	s.picks[route.Hash()]++
+	return route, nil
+}
+
+// Explain explains whether the caller is pinned to a route
+func (s *sticky) Explain(routes []router.Route, opts ...selector.SelectOption) (*selector.Explanation, error) {
+	options := selector.NewSelectOptions(opts...)
+	exp := selector.NewExplanation(s.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+	if len(routes) == 0 {
+		exp.Reason = "no routes passed the filters"
+		return exp, selector.ErrNoneAvailable
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	key := s.pinKey(routes, options)
+	if i := s.pinned(key, routes, time.Now()); len(key) > 0 && i >= 0 {
+		exp.Selected = &routes[i]
+		exp.Reason = fmt.Sprintf("caller %s is pinned to the route", key)
+		exp.Candidate(routes[i]).Score = 1
+		exp.Candidate(routes[i]).Reason = "pinned"
+		return exp, nil
+	}
+
+	// the new route is picked by the underlying selector
+	inner, err := selector.Explain(s.selector, routes, selector.WithContext(options.Context))
+	if err != nil {
+		return exp, err
+	}
+	for _, c := range inner.Candidates {
+		*exp.Candidate(c.Route) = c
+	}
+	exp.Selected = inner.Selected
+	if len(key) == 0 {
+		exp.Reason = "no caller to pin, " + inner.Reason
+	} else {
+		exp.Reason = fmt.Sprintf("caller %s isn't pinned, %s", key, inner.Reason)
+	}
+	return exp, nil
+}
+
+// Record forwards the result of the routes picked by the underlying selector,
+// the calls to pinned routes weren't selected by it
+func (s *sticky) Record(route router.Route, err error) error {
+	s.Lock()
+	hash := route.Hash()
+	n, ok := s.picks[hash]
+	if !ok {
+		s.Unlock()
+		return nil
+	}
+	if n > 1 {
+		s.picks[hash] = n - 1
+	} else {
+		delete(s.picks, hash)
+	}
+	sel := s.selector
+	s.Unlock()
+
+	return sel.Record(route, err)
+}
+
+func (s *sticky) Close() error {
+	return s.own.Close()
+}
+
+func (s *sticky) String() string {
+	return "sticky"
+}
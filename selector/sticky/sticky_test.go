@@ -0,0 +1,155 @@
+package sticky
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+func TestSticky(t *testing.T) {
+	selector.Tests(t, NewSelector())
+}
+
+func testRoutes(n int) []router.Route {
+	routes := make([]router.Route, n)
+	for i := range routes {
+		routes[i] = router.Route{Service: "go.micro.service.foo", Address: fmt.Sprintf("127.0.0.1:%d", 8000+i)}
+	}
+	return routes
+}
+
+func withAccount(id string) selector.SelectOption {
+	return selector.WithContext(auth.ContextWithAccount(context.Background(), &auth.Account{ID: id}))
+}
+
+func TestStickyPin(t *testing.T) {
+	s := NewSelector()
+	routes := testRoutes(5)
+
+	first, err := s.Select(routes, withAccount("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		route, _ := s.Select(routes, withAccount("user-1"))
+		if route.Address != first.Address {
+			t.Fatalf("Expected the account to stick to %s, got %s", first.Address, route.Address)
+		}
+	}
+
+	// the header takes precedence over the account
+	ctx := metadata.Set(auth.ContextWithAccount(context.Background(), &auth.Account{ID: "user-1"}), DefaultHeader, "session-1")
+	session, _ := s.Select(routes, selector.WithContext(ctx))
+	for i := 0; i < 20; i++ {
+		route, _ := s.Select(routes, selector.WithContext(ctx))
+		if route.Address != session.Address {
+			t.Fatalf("Expected the session to stick to %s, got %s", session.Address, route.Address)
+		}
+	}
+
+	// the caller is pinned to another route when its route disappears
+	var remaining []router.Route
+	for _, r := range routes {
+		if r.Address != first.Address {
+			remaining = append(remaining, r)
+		}
+	}
+	repinned, err := s.Select(remaining, withAccount("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		route, _ := s.Select(routes, withAccount("user-1"))
+		if route.Address != repinned.Address {
+			t.Fatalf("Expected the account to be repinned to %s, got %s", repinned.Address, route.Address)
+		}
+	}
+
+	exp, err := selector.Explain(s, routes, withAccount("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Selected.Address != repinned.Address {
+		t.Fatalf("Expected the explanation to select the pinned route, got %s", exp.Selected.Address)
+	}
+}
+
+func TestStickyTTL(t *testing.T) {
+	s := NewSelector(TTL(time.Millisecond * 10)).(*sticky)
+	routes := testRoutes(2)
+
+	s.Select(routes, withAccount("user-1"))
+	if len(s.pins) != 1 {
+		t.Fatalf("Expected the account to be pinned, got %d pins", len(s.pins))
+	}
+
+	time.Sleep(time.Millisecond * 20)
+
+	key := routes[0].Service + "/user-1"
+	if i := s.pinned(key, routes, time.Now()); i != -1 {
+		t.Fatal("Expected the pin to expire")
+	}
+	if len(s.pins) != 0 {
+		t.Fatalf("Expected the expired pin to be removed, got %d pins", len(s.pins))
+	}
+}
+
+func TestStickySize(t *testing.T) {
+	s := NewSelector(Size(2)).(*sticky)
+	routes := testRoutes(2)
+
+	for _, id := range []string{"user-1", "user-2", "user-1", "user-3"} {
+		s.Select(routes, withAccount(id))
+	}
+
+	// user-2 is the least recently used
+	if len(s.pins) != 2 {
+		t.Fatalf("Expected 2 pins, got %d", len(s.pins))
+	}
+	if _, ok := s.pins[routes[0].Service+"/user-2"]; ok {
+		t.Fatal("Expected the least recently used pin to be evicted")
+	}
+}
+
+// recorder is a selector counting the results recorded to it
+type recorder struct {
+	selector.Selector
+	recorded int
+}
+
+func (r *recorder) Record(route router.Route, err error) error {
+	r.recorded++
+	return nil
+}
+
+func TestStickyRecord(t *testing.T) {
+	rec := &recorder{Selector: selector.NewSelector()}
+	s := NewSelector(Selector(rec))
+	routes := testRoutes(3)
+
+	// the first call is picked by the underlying selector, the rest are pinned
+	for i := 0; i < 5; i++ {
+		route, err := s.Select(routes, withAccount("user-1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Record(*route, nil)
+	}
+
+	if rec.recorded != 1 {
+		t.Fatalf("Expected only the pick of the underlying selector to be recorded, got %d", rec.recorded)
+	}
+
+	// calls without a caller are always picked by the underlying selector
+	route, _ := s.Select(routes)
+	s.Record(*route, nil)
+	if rec.recorded != 2 {
+		t.Fatalf("Expected 2 recorded results, got %d", rec.recorded)
+	}
+}
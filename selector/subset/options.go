@@ -0,0 +1,39 @@
+package subset
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/selector"
+)
+
+type sizeKey struct{}
+
+type clientIDKey struct{}
+
+type selectorKey struct{}
+
+// Size sets the number of routes of a service in the subset of the client
+func Size(n int) selector.Option {
+	return setOption(sizeKey{}, n)
+}
+
+// ClientID sets the ID the subset of the client is derived from, by default
+// the hostname. Numeric IDs e.g. the ordinal of a pod in a statefulset spread
+// the clients evenly across the routes, other IDs are hashed.
+func ClientID(id string) selector.Option {
+	return setOption(clientIDKey{}, id)
+}
+
+// Selector sets the selector used to pick a route from the subset
+func Selector(s selector.Selector) selector.Option {
+	return setOption(selectorKey{}, s)
+}
+
+func setOption(k, v interface{}) selector.Option {
+	return func(o *selector.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
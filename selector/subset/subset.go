@@ -0,0 +1,191 @@
+// Package subset is a selector which bounds the number of connections of a
+// client in very large fleets. Each client only selects from a stable subset
+// of the routes of a service, using the deterministic subsetting described in
+// the Google SRE book so the clients are spread evenly across the routes.
+package subset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+var (
+	// DefaultSize is the default number of routes in a subset
+	DefaultSize = 10
+)
+
+// NewSelector returns a subset selector
+func NewSelector(opts ...selector.Option) selector.Selector {
+	s := new(subset)
+	s.Init(opts...)
+	return s
+}
+
+type subset struct {
+	sync.RWMutex
+
+	opts     selector.Options
+	size     int
+	id       uint64
+	selector selector.Selector
+}
+
+// clientID converts the ID of the client to a number
+func clientID(id string) uint64 {
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return n
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return h.Sum64()
+}
+
+func (s *subset) Init(opts ...selector.Option) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, o := range opts {
+		o(&s.opts)
+	}
+
+	id, _ := os.Hostname()
+	s.size = DefaultSize
+	s.selector = selector.DefaultSelector
+
+	if ctx := s.opts.Context; ctx != nil {
+		if n, ok := ctx.Value(sizeKey{}).(int); ok && n > 0 {
+			s.size = n
+		}
+		if v, ok := ctx.Value(clientIDKey{}).(string); ok && len(v) > 0 {
+			id = v
+		}
+		if sel, ok := ctx.Value(selectorKey{}).(selector.Selector); ok && sel != nil {
+			s.selector = sel
+		}
+	}
+
+	s.id = clientID(id)
+
+	return nil
+}
+
+func (s *subset) Options() selector.Options {
+	s.RLock()
+	defer s.RUnlock()
+	return s.opts
+}
+
+// subset returns the routes in the subset of the client. The routes are
+// ordered by address and shuffled with the round of the client as the seed, so
+// the clients of a round get distinct subsets covering the routes.
+func (s *subset) subset(routes []router.Route) []router.Route {
+	s.RLock()
+	size, id := s.size, s.id
+	s.RUnlock()
+
+	if len(routes) <= size {
+		return routes
+	}
+
+	sorted := make([]router.Route, len(routes))
+	copy(sorted, routes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Address < sorted[j].Address
+	})
+
+	count := uint64(len(sorted) / size)
+	round := id / count
+	r := rand.New(rand.NewSource(int64(round)))
+	r.Shuffle(len(sorted), func(i, j int) {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	})
+
+	start := int(id%count) * size
+	return sorted[start : start+size]
+}
+
+func (s *subset) Select(routes []router.Route, opts ...selector.SelectOption) (*router.Route, error) {
+	// parse the options
+	options := selector.NewSelectOptions(opts...)
+
+	// apply the filters
+	for _, f := range options.Filters {
+		routes = f(routes)
+	}
+
+	// we can't select from an empty pool of routes
+	if len(routes) == 0 {
+		return nil, selector.ErrNoneAvailable
+	}
+
+	s.RLock()
+	sel := s.selector
+	s.RUnlock()
+
+	return sel.Select(s.subset(routes), selector.WithContext(options.Context))
+}
+
+// Explain explains which routes are in the subset of the client
+func (s *subset) Explain(routes []router.Route, opts ...selector.SelectOption) (*selector.Explanation, error) {
+	options := selector.NewSelectOptions(opts...)
+	exp := selector.NewExplanation(s.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+	if len(routes) == 0 {
+		exp.Reason = "no routes passed the filters"
+		return exp, selector.ErrNoneAvailable
+	}
+
+	subset := s.subset(routes)
+	in := make(map[string]bool, len(subset))
+	for _, route := range subset {
+		in[route.Address] = true
+	}
+	for _, route := range routes {
+		c := exp.Candidate(route)
+		if in[route.Address] {
+			c.Score = 1
+			c.Reason = "in the subset"
+		} else {
+			c.Reason = "not in the subset"
+		}
+	}
+
+	s.RLock()
+	sel := s.selector
+	s.RUnlock()
+
+	inner, err := selector.Explain(sel, subset, selector.WithContext(options.Context))
+	if err != nil {
+		exp.Reason = inner.Reason
+		return exp, err
+	}
+
+	exp.Selected = inner.Selected
+	exp.Reason = fmt.Sprintf("picked from the subset of %d of %d routes, %s", len(subset), len(routes), inner.Reason)
+	return exp, nil
+}
+
+func (s *subset) Record(route router.Route, err error) error {
+	s.RLock()
+	sel := s.selector
+	s.RUnlock()
+
+	return sel.Record(route, err)
+}
+
+func (s *subset) Close() error {
+	return nil
+}
+
+func (s *subset) String() string {
+	return "subset"
+}
@@ -0,0 +1,93 @@
+package subset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+func TestSubset(t *testing.T) {
+	selector.Tests(t, NewSelector())
+}
+
+func testRoutes(n int) []router.Route {
+	routes := make([]router.Route, n)
+	for i := range routes {
+		routes[i] = router.Route{Service: "go.micro.service.foo", Address: fmt.Sprintf("127.0.0.1:%d", 8000+i)}
+	}
+	return routes
+}
+
+func TestSubsetSelect(t *testing.T) {
+	routes := testRoutes(20)
+	s := NewSelector(Size(5), ClientID("client-1"))
+
+	subset := s.(*subset).subset(routes)
+	if len(subset) != 5 {
+		t.Fatalf("Expected 5 routes in the subset, got %d", len(subset))
+	}
+	picked := make(map[string]bool)
+	for _, route := range subset {
+		picked[route.Address] = true
+	}
+
+	for i := 0; i < 50; i++ {
+		route, err := s.Select(routes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !picked[route.Address] {
+			t.Fatalf("Expected a route of the subset, got %s", route.Address)
+		}
+	}
+
+	// the order of the routes doesn't change the subset
+	reversed := make([]router.Route, len(routes))
+	for i, r := range routes {
+		reversed[len(routes)-1-i] = r
+	}
+	for i := 0; i < 50; i++ {
+		route, _ := s.Select(reversed)
+		if !picked[route.Address] {
+			t.Fatalf("Expected a route of the subset, got %s", route.Address)
+		}
+	}
+
+	exp, err := selector.Explain(s, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !picked[exp.Selected.Address] {
+		t.Fatalf("Expected the explanation to pick a route of the subset, got %s", exp.Selected.Address)
+	}
+}
+
+func TestSubsetSpread(t *testing.T) {
+	routes := testRoutes(20)
+
+	// every route is in the subsets of the same number of clients
+	clients := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		s := NewSelector(Size(5), ClientID(fmt.Sprintf("%d", i))).(*subset)
+		for _, route := range s.subset(routes) {
+			clients[route.Address]++
+		}
+	}
+
+	for _, route := range routes {
+		if n := clients[route.Address]; n != 25 {
+			t.Fatalf("Expected route %s in 25 subsets, got %d", route.Address, n)
+		}
+	}
+}
+
+func TestSubsetSmall(t *testing.T) {
+	routes := testRoutes(3)
+	s := NewSelector(Size(5)).(*subset)
+
+	if got := s.subset(routes); len(got) != 3 {
+		t.Fatalf("Expected every route when there are fewer than the size, got %d", len(got))
+	}
+}
@@ -1,6 +1,7 @@
 package selector
 
 import (
+	"context"
 	"testing"
 
 	"github.com/micro/go-micro/v2/router"
@@ -44,6 +45,44 @@ func Tests(t *testing.T, s Selector) {
 			assert.Nil(t, err, "Error should be nil")
 			assert.True(t, filterApplied, "Filters should be applied")
 		})
+
+		t.Run("ExcludeAttempted", func(t *testing.T) {
+			ctx := NewAttemptsContext(context.Background())
+			Attempt(ctx, r1)
+
+			srv, err := s.Select([]router.Route{r1, r2}, WithFilter(ExcludeAttempted(ctx)))
+			assert.Nil(t, err, "Error should be nil")
+			assert.Equal(t, r2, *srv, "Expected the route not yet attempted to be returned")
+
+			// once every route has been attempted any can be selected
+			Attempt(ctx, r2)
+			srv, err = s.Select([]router.Route{r1, r2}, WithFilter(ExcludeAttempted(ctx)))
+			assert.Nil(t, err, "Error should be nil")
+			assert.NotNil(t, srv, "Expected a route to be returned")
+		})
+	})
+
+	t.Run("Explain", func(t *testing.T) {
+		exclude := func(rts []router.Route) []router.Route {
+			var out []router.Route
+			for _, r := range rts {
+				if r.Address != r1.Address {
+					out = append(out, r)
+				}
+			}
+			return out
+		}
+
+		exp, err := Explain(s, []router.Route{r1, r2}, WithFilter(exclude))
+		assert.Nil(t, err, "Error should be nil")
+		assert.Equal(t, 2, len(exp.Candidates), "Expected every route to be a candidate")
+		assert.Equal(t, 0, exp.Candidate(r1).Filter, "Expected the route to be removed by the filter")
+		assert.Equal(t, -1, exp.Candidate(r2).Filter, "Expected the route to pass the filter")
+		assert.Equal(t, r2, *exp.Selected, "Expected the route passing the filter to be selected")
+		assert.NotEmpty(t, exp.Reason, "Expected the selection to be explained")
+
+		_, err = Explain(s, []router.Route{r1}, WithFilter(exclude))
+		assert.Equal(t, ErrNoneAvailable, err, "Expected error to be none available")
 	})
 
 	t.Run("Record", func(t *testing.T) {
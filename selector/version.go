@@ -0,0 +1,234 @@
+package selector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version e.g. 1.2.3
+type Version struct {
+	Major, Minor, Patch int
+	// Prerelease e.g. beta.1, versions with a prerelease are lower
+	Prerelease string
+}
+
+// ParseVersion parses a semantic version, the v prefix, minor and patch are
+// optional e.g. v1.2 is 1.2.0
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	// build metadata doesn't change the precedence
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.Prerelease = s[i+1:]
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 if the version is lower, equal or greater than o
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	default:
+		return comparePrerelease(v.Prerelease, o.Prerelease)
+	}
+}
+
+// comparePrerelease compares the dot separated identifiers of prereleases in
+// turn: numerically if both are numeric, otherwise lexically with the numeric
+// ones lower. A prerelease with more identifiers is greater if the others are
+// equal, e.g. beta.2 < beta.11 < beta.11.1 < rc.1
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.ParseUint(as[i], 10, 64)
+		bn, berr := strconv.ParseUint(bs[i], 10, 64)
+
+		switch {
+		case aerr == nil && berr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aerr == nil:
+			return -1
+		case berr == nil:
+			return 1
+		case as[i] != bs[i]:
+			if as[i] < bs[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Constraint is a version range e.g. ">=1.2 <2" or "^1.2 || ^2"
+type Constraint struct {
+	// alternatives of which a version has to match every comparison of any
+	alternatives [][]comparison
+	raw          string
+}
+
+type comparison struct {
+	op      string
+	version Version
+}
+
+// operators ordered so the longest prefix matches first
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"}
+
+// ParseConstraint parses a version constraint. Comparisons separated by spaces
+// or commas must all match, alternatives are separated by ||. The operators are
+// =, !=, >, >=, <, <=, ^ (same major version) and ~ (same minor version).
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+
+	for _, alt := range strings.Split(s, "||") {
+		var comps []comparison
+		for _, f := range strings.FieldsFunc(alt, func(r rune) bool { return r == ' ' || r == ',' }) {
+			op := "="
+			for _, o := range operators {
+				if strings.HasPrefix(f, o) {
+					op = o
+					f = f[len(o):]
+					break
+				}
+			}
+			if op == "==" {
+				op = "="
+			}
+
+			v, err := ParseVersion(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid constraint %q: %v", s, err)
+			}
+			comps = append(comps, comparison{op, v})
+		}
+		if len(comps) == 0 {
+			return nil, fmt.Errorf("invalid constraint %q: empty range", s)
+		}
+		c.alternatives = append(c.alternatives, comps)
+	}
+
+	return c, nil
+}
+
+// Check returns true if the version satisfies the constraint, versions which
+// aren't semantic e.g. latest never do. Prereleases only satisfy alternatives
+// comparing against a prerelease of the same version, so <2 excludes 2.0.0-beta.
+func (c *Constraint) Check(version string) bool {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false
+	}
+
+	for _, comps := range c.alternatives {
+		if len(v.Prerelease) > 0 && !prerelease(comps, v) {
+			continue
+		}
+		ok := true
+		for _, comp := range comps {
+			if !comp.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// prerelease returns true if a comparison is against a prerelease of the version
+func prerelease(comps []comparison, v Version) bool {
+	for _, comp := range comps {
+		cv := comp.version
+		if len(cv.Prerelease) > 0 && cv.Major == v.Major && cv.Minor == v.Minor && cv.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+func (c comparison) check(v Version) bool {
+	d := v.Compare(c.version)
+
+	switch c.op {
+	case "!=":
+		return d != 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case "^":
+		return d >= 0 && v.Major == c.version.Major
+	case "~":
+		return d >= 0 && v.Major == c.version.Major && v.Minor == c.version.Minor
+	default:
+		return d == 0
+	}
+}
@@ -0,0 +1,31 @@
+package weighted
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/selector"
+)
+
+type metadataKey struct{}
+
+type roundRobinKey struct{}
+
+// MetadataKey sets the route metadata key the weight is read from
+func MetadataKey(k string) selector.Option {
+	return setOption(metadataKey{}, k)
+}
+
+// RoundRobin selects the routes in a smooth weighted round robin rather than
+// at random, so the share of each route is exact over a few selections
+func RoundRobin() selector.Option {
+	return setOption(roundRobinKey{}, true)
+}
+
+func setOption(k, v interface{}) selector.Option {
+	return func(o *selector.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
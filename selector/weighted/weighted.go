@@ -0,0 +1,244 @@
+// Package weighted is a selector which picks routes in proportion to the weight
+// in their node metadata, so nodes can be drained or canaried by updating their
+// weight in the registry. A weight of 0 drains the node, a missing or invalid
+// weight defaults to router.DefaultWeight.
+package weighted
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+var (
+	// DefaultMetadataKey is the route metadata key the weight is read from
+	DefaultMetadataKey = "weight"
+
+	// stateTTL is how long the round robin state of an unused route is kept
+	stateTTL = time.Minute * 15
+)
+
+// NewSelector returns a weighted random selector, use the RoundRobin option for
+// a weighted round robin
+func NewSelector(opts ...selector.Option) selector.Selector {
+	w := &weighted{
+		state: make(map[uint64]*state),
+	}
+	w.Init(opts...)
+	return w
+}
+
+type weighted struct {
+	sync.Mutex
+
+	opts       selector.Options
+	key        string
+	roundRobin bool
+
+	// state is the round robin state per route hash
+	state map[uint64]*state
+	// pruned is when unused state was last removed
+	pruned time.Time
+}
+
+// state is the smooth weighted round robin state of a route
+type state struct {
+	// current weight of the route
+	current int64
+	// used is when the route was last a candidate
+	used time.Time
+}
+
+func (w *weighted) Init(opts ...selector.Option) error {
+	w.Lock()
+	defer w.Unlock()
+
+	for _, o := range opts {
+		o(&w.opts)
+	}
+
+	w.key = DefaultMetadataKey
+	w.roundRobin = false
+
+	if ctx := w.opts.Context; ctx != nil {
+		if k, ok := ctx.Value(metadataKey{}).(string); ok && len(k) > 0 {
+			w.key = k
+		}
+		if rr, ok := ctx.Value(roundRobinKey{}).(bool); ok {
+			w.roundRobin = rr
+		}
+	}
+
+	return nil
+}
+
+func (w *weighted) Options() selector.Options {
+	w.Lock()
+	defer w.Unlock()
+	return w.opts
+}
+
+// weight returns the weight of the route, 0 if it's drained
+func (w *weighted) weight(route router.Route) int64 {
+	return router.ParseWeight(route.Metadata[w.key])
+}
+
+func (w *weighted) Select(routes []router.Route, opts ...selector.SelectOption) (*router.Route, error) {
+	// parse the options
+	options := selector.NewSelectOptions(opts...)
+
+	// apply the filters
+	for _, f := range options.Filters {
+		routes = f(routes)
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	i, err := w.pick(routes, true)
+	if err != nil {
+		return nil, err
+	}
+	return &routes[i], nil
+}
+
+// Explain scores the routes by their share of the selections
+func (w *weighted) Explain(routes []router.Route, opts ...selector.SelectOption) (*selector.Explanation, error) {
+	options := selector.NewSelectOptions(opts...)
+	exp := selector.NewExplanation(w.String(), routes, options.Filters)
+
+	routes = exp.Routes()
+
+	w.Lock()
+	defer w.Unlock()
+
+	var total int64
+	for _, route := range routes {
+		total += w.weight(route)
+	}
+
+	for _, route := range routes {
+		c := exp.Candidate(route)
+		weight := w.weight(route)
+		if weight == 0 {
+			c.Reason = "drained, weight 0"
+			continue
+		}
+		c.Score = float64(weight) / float64(total)
+		c.Reason = fmt.Sprintf("weight %d of %d", weight, total)
+	}
+
+	i, err := w.pick(routes, false)
+	if err == selector.ErrNoneAvailable && len(routes) > 0 {
+		exp.Reason = "all the routes are drained"
+		return exp, err
+	} else if err != nil {
+		exp.Reason = "no routes passed the filters"
+		return exp, err
+	}
+
+	exp.Selected = &routes[i]
+	if w.roundRobin {
+		exp.Reason = fmt.Sprintf("route with the highest current weight %d", w.current(routes[i])+w.weight(routes[i]))
+	} else {
+		exp.Reason = fmt.Sprintf("picked at random weighted by %s metadata", w.key)
+	}
+	return exp, nil
+}
+
+// current returns the current round robin weight of the route. Should be called under lock.
+func (w *weighted) current(route router.Route) int64 {
+	if s, ok := w.state[route.Hash()]; ok {
+		return s.current
+	}
+	return 0
+}
+
+// pick returns the index of the selected route, updating the round robin state
+// if update is set. Should be called under lock.
+func (w *weighted) pick(routes []router.Route, update bool) (int, error) {
+	var total int64
+	for _, route := range routes {
+		total += w.weight(route)
+	}
+
+	// no routes or all of them drained
+	if total == 0 {
+		return 0, selector.ErrNoneAvailable
+	}
+
+	if !w.roundRobin {
+		n := rand.Int63n(total)
+		for i, route := range routes {
+			if n -= w.weight(route); n < 0 {
+				return i, nil
+			}
+		}
+	}
+
+	// smooth weighted round robin: every route gains its weight and the one
+	// with the highest current weight is picked and loses the total
+	now := time.Now()
+	selected := -1
+	var max int64
+
+	for i, route := range routes {
+		weight := w.weight(route)
+		if weight == 0 {
+			continue
+		}
+
+		current := w.current(route) + weight
+		if selected == -1 || current > max {
+			selected, max = i, current
+		}
+
+		if update {
+			s, ok := w.state[route.Hash()]
+			if !ok {
+				s = &state{}
+				w.state[route.Hash()] = s
+			}
+			s.current = current
+			s.used = now
+		}
+	}
+
+	if update {
+		w.state[routes[selected].Hash()].current -= total
+		w.prune(now)
+	}
+
+	return selected, nil
+}
+
+// prune removes the state of the routes which haven't been candidates for a
+// while. Should be called under lock.
+func (w *weighted) prune(now time.Time) {
+	if now.Sub(w.pruned) < time.Minute {
+		return
+	}
+	w.pruned = now
+
+	for hash, s := range w.state {
+		if now.Sub(s.used) > stateTTL {
+			delete(w.state, hash)
+		}
+	}
+}
+
+func (w *weighted) Record(route router.Route, err error) error {
+	return nil
+}
+
+func (w *weighted) Close() error {
+	return nil
+}
+
+func (w *weighted) String() string {
+	return "weighted"
+}
@@ -0,0 +1,85 @@
+package weighted
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/selector"
+)
+
+func TestWeighted(t *testing.T) {
+	selector.Tests(t, NewSelector())
+}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	selector.Tests(t, NewSelector(RoundRobin()))
+}
+
+func weightedRoutes(weights ...string) []router.Route {
+	routes := make([]router.Route, len(weights))
+	for i, w := range weights {
+		routes[i] = router.Route{
+			Service:  "go.micro.service.foo",
+			Address:  fmt.Sprintf("127.0.0.1:%d", 8000+i),
+			Metadata: map[string]string{"weight": w},
+		}
+	}
+	return routes
+}
+
+func TestWeightedShare(t *testing.T) {
+	routes := weightedRoutes("3", "1", "0")
+
+	for _, s := range []selector.Selector{NewSelector(), NewSelector(RoundRobin())} {
+		counts := make(map[string]int)
+		for i := 0; i < 400; i++ {
+			route, err := s.Select(routes)
+			if err != nil {
+				t.Fatalf("Unexpected select error: %v", err)
+			}
+			counts[route.Address]++
+		}
+
+		if counts[routes[2].Address] != 0 {
+			t.Errorf("Expected the drained route not to be selected, got %d selections", counts[routes[2].Address])
+		}
+		if c := counts[routes[0].Address]; c < 250 || c > 350 {
+			t.Errorf("Expected about 300 selections of the route with weight 3, got %d", c)
+		}
+	}
+}
+
+func TestWeightedRoundRobinOrder(t *testing.T) {
+	s := NewSelector(RoundRobin())
+	routes := weightedRoutes("2", "1")
+
+	var picks string
+	for i := 0; i < 6; i++ {
+		route, _ := s.Select(routes)
+		if route.Address == routes[0].Address {
+			picks += "a"
+		} else {
+			picks += "b"
+		}
+	}
+
+	// smooth weighted round robin interleaves the picks
+	if picks != "abaaba" {
+		t.Fatalf("Expected the picks to be abaaba, got %s", picks)
+	}
+}
+
+func TestWeightedDrained(t *testing.T) {
+	s := NewSelector()
+	routes := weightedRoutes("0", "0")
+
+	if _, err := s.Select(routes); err != selector.ErrNoneAvailable {
+		t.Fatalf("Expected none available when every route is drained, got %v", err)
+	}
+
+	exp, err := selector.Explain(s, routes)
+	if err != selector.ErrNoneAvailable || exp.Reason != "all the routes are drained" {
+		t.Fatalf("Expected the explanation to report the drained routes, got %q %v", exp.Reason, err)
+	}
+}
@@ -0,0 +1,344 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/store"
+)
+
+// Redacted replaces the values of the fields redacted from the payloads
+const Redacted = "[REDACTED]"
+
+// AuditRecord is the record of a request, or a stream, handled by a server
+type AuditRecord struct {
+	// Id of the request, if set by the caller
+	Id string `json:"id,omitempty"`
+	// Time the request was received
+	Time time.Time `json:"time"`
+	// Service and Endpoint called
+	Service  string `json:"service"`
+	Endpoint string `json:"endpoint"`
+	// Account of the caller, blank if unauthenticated
+	Account string `json:"account,omitempty"`
+	// Remote address of the caller
+	Remote string `json:"remote,omitempty"`
+	// Latency of the request, or the duration of the stream
+	Latency time.Duration `json:"latency"`
+	// Code is the status code, 200 if the request succeeded
+	Code int32 `json:"code"`
+	// Error returned, if any
+	Error string `json:"error,omitempty"`
+	// Metadata of the request selected
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Request is the json encoded payload of the request, with the fields
+	// redacted, if recorded
+	Request json.RawMessage `json:"request,omitempty"`
+}
+
+// AuditSink writes the audit records e.g. to a log, a broker topic or a
+// store. It's called once each request is handled, so it must be fast, the
+// slow sinks are wrapped with NewBufferedAuditSink.
+type AuditSink interface {
+	Write(*AuditRecord) error
+}
+
+// DefaultAuditBufferSize is the number of records the buffered sinks hold
+var DefaultAuditBufferSize = 1024
+
+type bufferedAuditSink struct {
+	sink    AuditSink
+	records chan *AuditRecord
+}
+
+func (b *bufferedAuditSink) Write(rec *AuditRecord) error {
+	select {
+	case b.records <- rec:
+		return nil
+	default:
+		return fmt.Errorf("audit buffer of %d records is full, dropped the record", cap(b.records))
+	}
+}
+
+func (b *bufferedAuditSink) run() {
+	for rec := range b.records {
+		if err := b.sink.Write(rec); err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Failed to write the audit record of %s: %v", rec.Endpoint, err)
+		}
+	}
+}
+
+// NewBufferedAuditSink writes the records to the sink in the background, so
+// the requests don't wait for it. Up to size records are buffered, or
+// DefaultAuditBufferSize if zero, the records over it are dropped.
+func NewBufferedAuditSink(sink AuditSink, size int) AuditSink {
+	if size <= 0 {
+		size = DefaultAuditBufferSize
+	}
+	b := &bufferedAuditSink{
+		sink:    sink,
+		records: make(chan *AuditRecord, size),
+	}
+	go b.run()
+	return b
+}
+
+// AuditOptions are the options of the audit records
+type AuditOptions struct {
+	// Metadata are the keys of the metadata of the requests recorded
+	Metadata []string
+	// Payload records the payloads of the requests
+	Payload bool
+	// Redact are the names of the fields redacted from the payloads
+	Redact []string
+	// Auth inspects the tokens of the callers for their accounts, the
+	// wrappers setting the account in the context are inside the auditor
+	Auth func() auth.Auth
+}
+
+type AuditOption func(o *AuditOptions)
+
+// AuditAuth records the accounts of the callers by inspecting the tokens of
+// their Authorization headers with the auth returned by fn
+func AuditAuth(fn func() auth.Auth) AuditOption {
+	return func(o *AuditOptions) {
+		o.Auth = fn
+	}
+}
+
+// AuditMetadata records the metadata of the keys, e.g. a tenant, with the
+// requests
+func AuditMetadata(keys ...string) AuditOption {
+	return func(o *AuditOptions) {
+		o.Metadata = append(o.Metadata, keys...)
+	}
+}
+
+// AuditPayload records the payloads of the requests as json, with the values
+// of the fields of the names redacted at any depth, e.g. passwords
+func AuditPayload(redact ...string) AuditOption {
+	return func(o *AuditOptions) {
+		o.Payload = true
+		o.Redact = append(o.Redact, redact...)
+	}
+}
+
+// Auditor writes the audit records of the requests of a server to a sink
+type Auditor struct {
+	sink AuditSink
+	opts AuditOptions
+}
+
+// NewAuditor returns an auditor writing the records to the sink
+func NewAuditor(sink AuditSink, opts ...AuditOption) *Auditor {
+	var options AuditOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Auditor{
+		sink: sink,
+		opts: options,
+	}
+}
+
+// Record writes the record of the request, started at the time and returning
+// the error, to the sink
+func (a *Auditor) Record(ctx context.Context, req Request, start time.Time, err error) {
+	if a == nil {
+		return
+	}
+
+	rec := &AuditRecord{
+		Id:       req.Header()["Micro-Id"],
+		Time:     start,
+		Service:  req.Service(),
+		Endpoint: req.Endpoint(),
+		Latency:  time.Since(start),
+		Code:     status(err),
+	}
+
+	if acc, ok := a.account(ctx); ok {
+		rec.Account = acc.ID
+	}
+	if remote, ok := metadata.Get(ctx, "Remote"); ok {
+		rec.Remote = remote
+	}
+	if err != nil && rec.Code != 200 {
+		rec.Error = err.Error()
+	}
+
+	for _, k := range a.opts.Metadata {
+		if v, ok := metadata.Get(ctx, k); ok {
+			if rec.Metadata == nil {
+				rec.Metadata = make(map[string]string, len(a.opts.Metadata))
+			}
+			rec.Metadata[k] = v
+		}
+	}
+
+	if a.opts.Payload && req.Body() != nil {
+		b, rerr := redact(req.Body(), a.opts.Redact)
+		if rerr == nil {
+			rec.Request = b
+		} else if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Failed to encode the payload of %s for audit: %v", req.Endpoint(), rerr)
+		}
+	}
+
+	if werr := a.sink.Write(rec); werr != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+		logger.Errorf("Failed to write the audit record of %s: %v", req.Endpoint(), werr)
+	}
+}
+
+// account returns the account of the caller, from the context or else from
+// the token of the Authorization header
+func (a *Auditor) account(ctx context.Context) (*auth.Account, bool) {
+	if acc, ok := auth.AccountFromContext(ctx); ok {
+		return acc, true
+	}
+	if a.opts.Auth == nil {
+		return nil, false
+	}
+	hdr, ok := metadata.Get(ctx, "Authorization")
+	if !ok || !strings.HasPrefix(hdr, auth.BearerScheme) {
+		return nil, false
+	}
+	acc, err := a.opts.Auth().Inspect(strings.TrimPrefix(hdr, auth.BearerScheme))
+	if err != nil || acc == nil {
+		return nil, false
+	}
+	return acc, true
+}
+
+// redact encodes the payload as json with the values of the fields redacted
+func redact(payload interface{}, fields []string) (json.RawMessage, error) {
+	b, err := json.Marshal(payload)
+	if err != nil || len(fields) == 0 {
+		return b, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, fv := range t {
+				redacted := false
+				for _, f := range fields {
+					if strings.EqualFold(k, f) {
+						t[k] = Redacted
+						redacted = true
+						break
+					}
+				}
+				if !redacted {
+					walk(fv)
+				}
+			}
+		case []interface{}:
+			for _, e := range t {
+				walk(e)
+			}
+		}
+	}
+	walk(v)
+
+	return json.Marshal(v)
+}
+
+// AuditWrapper writes the audit records of the requests to the handler. It
+// wraps the other wrappers to record the requests they reject.
+func AuditWrapper(a *Auditor) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		if a == nil {
+			return fn
+		}
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			start := time.Now()
+			err := fn(ctx, req, rsp)
+			a.Record(ctx, req, start, err)
+			return err
+		}
+	}
+}
+
+type loggerAuditSink struct {
+	logger logger.Logger
+}
+
+func (l *loggerAuditSink) Write(rec *AuditRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	l.logger.Log(logger.InfoLevel, string(b))
+	return nil
+}
+
+// NewLoggerAuditSink logs the audit records as json at the info level, with
+// the default logger if nil
+func NewLoggerAuditSink(l logger.Logger) AuditSink {
+	if l == nil {
+		l = logger.DefaultLogger
+	}
+	return &loggerAuditSink{logger: l}
+}
+
+type brokerAuditSink struct {
+	broker broker.Broker
+	topic  string
+}
+
+func (b *brokerAuditSink) Write(rec *AuditRecord) error {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return b.broker.Publish(b.topic, &broker.Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+			"Micro-Topic":  b.topic,
+		},
+		Body: body,
+	})
+}
+
+// NewBrokerAuditSink publishes the audit records as json to the topic, in
+// the background through a buffer of DefaultAuditBufferSize records
+func NewBrokerAuditSink(b broker.Broker, topic string) AuditSink {
+	return NewBufferedAuditSink(&brokerAuditSink{broker: b, topic: topic}, 0)
+}
+
+type storeAuditSink struct {
+	store  store.Store
+	prefix string
+}
+
+func (s *storeAuditSink) Write(rec *AuditRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	// the keys sort by service and time
+	key := fmt.Sprintf("%s%s/%s/%s", s.prefix, rec.Service, rec.Time.UTC().Format(time.RFC3339Nano), rec.Id)
+	return s.store.Write(&store.Record{Key: key, Value: b})
+}
+
+// NewStoreAuditSink writes the audit records as json to the store, keyed by
+// the prefix, the service, the time and the id of the requests
+func NewStoreAuditSink(s store.Store, prefix string) AuditSink {
+	return &storeAuditSink{store: s, prefix: prefix}
+}
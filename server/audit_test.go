@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/store"
+	"github.com/micro/go-micro/v2/store/memory"
+)
+
+type testAuditSink struct {
+	records []*AuditRecord
+}
+
+func (s *testAuditSink) Write(rec *AuditRecord) error {
+	s.records = append(s.records, rec)
+	return nil
+}
+
+type testCredentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+type testLogin struct {
+	Credentials []testCredentials `json:"credentials"`
+	Token       string            `json:"token"`
+}
+
+func TestAuditWrapper(t *testing.T) {
+	sink := &testAuditSink{}
+	a := NewAuditor(sink, AuditMetadata("Tenant"), AuditPayload("password", "Token"))
+
+	fn := AuditWrapper(a)(func(ctx context.Context, req Request, rsp interface{}) error {
+		return errors.Forbidden("go.micro.service.foo", "denied")
+	})
+
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{
+		"Remote": "10.0.0.1:54321",
+		"Tenant": "acme",
+		"Secret": "s3cr3t",
+	})
+	ctx = auth.ContextWithAccount(ctx, &auth.Account{ID: "alice"})
+
+	req := &rpcRequest{
+		service:  "go.micro.service.foo",
+		endpoint: "Foo.Login",
+		header:   map[string]string{"Micro-Id": "1"},
+		rawBody: &testLogin{
+			Credentials: []testCredentials{{User: "alice", Password: "hunter2"}},
+			Token:       "abc",
+		},
+	}
+	if err := fn(ctx, req, nil); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected the error of the handler, got %v", err)
+	}
+
+	if len(sink.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(sink.records))
+	}
+	rec := sink.records[0]
+	if rec.Id != "1" || rec.Endpoint != "Foo.Login" || rec.Account != "alice" || rec.Remote != "10.0.0.1:54321" {
+		t.Fatalf("Unexpected record %+v", rec)
+	}
+	if rec.Code != 403 || !strings.Contains(rec.Error, "denied") {
+		t.Fatalf("Expected the status of the request, got %d %s", rec.Code, rec.Error)
+	}
+	if len(rec.Metadata) != 1 || rec.Metadata["Tenant"] != "acme" {
+		t.Fatalf("Expected only the metadata selected, got %v", rec.Metadata)
+	}
+
+	// the fields are redacted at any depth
+	expected := `{"credentials":[{"password":"[REDACTED]","user":"alice"}],"token":"[REDACTED]"}`
+	if string(rec.Request) != expected {
+		t.Fatalf("Expected the payload %s, got %s", expected, rec.Request)
+	}
+}
+
+func TestStoreAuditSink(t *testing.T) {
+	s := memory.NewStore()
+	sink := NewStoreAuditSink(s, "audit/")
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := sink.Write(&AuditRecord{Id: "1", Time: now, Service: "foo", Code: 200}); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.Read("audit/foo/", store.ReadPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Key != "audit/foo/2020-01-01T00:00:00Z/1" {
+		t.Fatalf("Unexpected records %+v", recs)
+	}
+
+	var rec AuditRecord
+	if err := json.Unmarshal(recs[0].Value, &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Id != "1" || rec.Code != 200 {
+		t.Fatalf("Unexpected record %+v", rec)
+	}
+}
+
+type blockingAuditSink struct {
+	release chan bool
+	written chan *AuditRecord
+}
+
+func (s *blockingAuditSink) Write(rec *AuditRecord) error {
+	<-s.release
+	s.written <- rec
+	return nil
+}
+
+func TestBufferedAuditSink(t *testing.T) {
+	sink := &blockingAuditSink{release: make(chan bool), written: make(chan *AuditRecord, 2)}
+	b := NewBufferedAuditSink(sink, 1)
+
+	// the writes don't wait for the sink, the ones over the buffer are dropped
+	if err := b.Write(&AuditRecord{Id: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	var dropped bool
+	for i := 0; i < 3; i++ {
+		if err := b.Write(&AuditRecord{Id: "2"}); err != nil {
+			dropped = true
+		}
+	}
+	if !dropped {
+		t.Fatal("Expected the records over the buffer to be dropped")
+	}
+
+	close(sink.release)
+	select {
+	case rec := <-sink.written:
+		if rec.Id != "1" {
+			t.Fatalf("Expected the first record, got %+v", rec)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the record to be written")
+	}
+}
@@ -0,0 +1,10 @@
+package server
+
+import (
+	"time"
+)
+
+// cpuTime isn't available on windows
+func cpuTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,95 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/registry"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/server"
+	gsrv "github.com/micro/go-micro/v2/server/grpc"
+	tgrpc "github.com/micro/go-micro/v2/transport/grpc"
+	"google.golang.org/grpc"
+
+	pb "github.com/micro/go-micro/v2/server/grpc/proto"
+)
+
+// blockingServer blocks the calls until released
+type blockingServer struct {
+	testServer
+	called  chan bool
+	release chan bool
+}
+
+func (s *blockingServer) Call(ctx context.Context, req *pb.Request, rsp *pb.Response) error {
+	s.called <- true
+	<-s.release
+	rsp.Msg = "Hello " + req.Name
+	return nil
+}
+
+func TestGRPCServerDrain(t *testing.T) {
+	r := rmemory.NewRegistry()
+	s := gsrv.NewServer(
+		server.Broker(bmemory.NewBroker()),
+		server.Name("foo"),
+		server.Registry(r),
+		server.Transport(tgrpc.NewTransport()),
+	)
+
+	h := &blockingServer{called: make(chan bool, 1), release: make(chan bool)}
+	pb.RegisterTestHandler(s, h)
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	addr := s.Options().Address
+
+	cc, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer cc.Close()
+
+	// a request in flight as the server stops
+	done := make(chan error, 1)
+	go func() {
+		_, err := pb.NewTestClient(cc).Call(context.TODO(), &pb.Request{Name: "John"})
+		done <- err
+	}()
+	<-h.called
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- s.Stop()
+	}()
+
+	// the connections are refused once drained, after deregistering
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the connections to be refused while draining")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := r.GetService("foo"); err != registry.ErrNotFound {
+		t.Fatalf("Expected the server deregistered before draining, got %v", err)
+	}
+
+	// the request in flight is served
+	close(h.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected the request in flight to be served, got %v", err)
+	}
+	if err := <-stopped; err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+}
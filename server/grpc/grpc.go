@@ -16,6 +16,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/debug/health"
 	"github.com/micro/go-micro/v2/errors"
 	pberr "github.com/micro/go-micro/v2/errors/proto"
 	"github.com/micro/go-micro/v2/logger"
@@ -24,6 +25,7 @@ import (
 	"github.com/micro/go-micro/v2/server"
 	"github.com/micro/go-micro/v2/util/addr"
 	"github.com/micro/go-micro/v2/util/backoff"
+	"github.com/micro/go-micro/v2/util/compress"
 	mgrpc "github.com/micro/go-micro/v2/util/grpc"
 	mnet "github.com/micro/go-micro/v2/util/net"
 	"golang.org/x/net/netutil"
@@ -32,8 +34,11 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/grpc/status"
 )
 
@@ -59,8 +64,16 @@ type grpcServer struct {
 	subscribers map[*subscriber][]broker.Subscriber
 	// marks the serve as started
 	started bool
+	// marks the server as stopping, new requests are rejected
+	draining bool
+	// limits the requests in flight
+	limiter server.Limiter
 	// used for first registration
 	registered bool
+	// status of the registration
+	registration server.Registration
+	// tracks the requests in flight
+	inflight server.Tracker
 
 	// registry service instance
 	rsvc *registry.Service
@@ -119,13 +132,27 @@ func (g *grpcServer) configure(opts ...server.Option) {
 		o(&g.opts)
 	}
 
-	g.wg = wait(g.opts.Context)
+	// keep the wait group of the requests in flight unless replaced
+	if wg := wait(g.opts.Context); wg != nil {
+		g.wg = wg
+	} else if g.wg == nil && g.opts.DrainTimeout > 0 {
+		g.wg = new(sync.WaitGroup)
+	}
 
-	maxMsgSize := g.getMaxMsgSize()
+	// the max request and response sizes override the max message size,
+	// the messages over them fail with a resource exhausted error
+	maxRecvSize := g.getMaxMsgSize()
+	maxSendSize := maxRecvSize
+	if g.opts.MaxRequestSize > 0 {
+		maxRecvSize = g.opts.MaxRequestSize
+	}
+	if g.opts.MaxResponseSize > 0 {
+		maxSendSize = g.opts.MaxResponseSize
+	}
 
 	gopts := []grpc.ServerOption{
-		grpc.MaxRecvMsgSize(maxMsgSize),
-		grpc.MaxSendMsgSize(maxMsgSize),
+		grpc.MaxRecvMsgSize(maxRecvSize),
+		grpc.MaxSendMsgSize(maxSendSize),
 		grpc.UnknownServiceHandler(g.handler),
 	}
 
@@ -139,6 +166,22 @@ func (g *grpcServer) configure(opts ...server.Option) {
 
 	g.rsvc = nil
 	g.srv = grpc.NewServer(gopts...)
+
+	// serve the grpc health checking protocol
+	grpc_health_v1.RegisterHealthServer(g.srv, &healthServer{g.opts.Health})
+	// describe the handlers with the grpc server reflection protocol
+	if g.reflection() {
+		rpb.RegisterServerReflectionServer(g.srv, &reflectionServer{g})
+	}
+}
+
+// reflection returns true if the server reflection protocol is served
+func (g *grpcServer) reflection() bool {
+	if g.opts.Context == nil {
+		return false
+	}
+	b, _ := g.opts.Context.Value(reflectionKey{}).(bool)
+	return b
 }
 
 func (g *grpcServer) getMaxMsgSize() int {
@@ -174,6 +217,32 @@ func (g *grpcServer) getGrpcOptions() []grpc.ServerOption {
 	return opts
 }
 
+// listen on the address, a unix domain socket for unix addresses, with the tls
+// config if any
+func (g *grpcServer) listen(addr string) (net.Listener, error) {
+	opts := g.Options()
+
+	// the sockets have the options of the transport
+	var so mnet.SocketOptions
+	if opts.Transport != nil {
+		so = opts.Transport.Options().Socket
+	}
+
+	return mnet.Listen(addr, func(addr string) (net.Listener, error) {
+		l, err := mnet.ListenSocket(addr, so)
+		if err != nil {
+			return nil, err
+		}
+
+		// check the tls config for secure connect
+		if opts.TLSConfig != nil {
+			return tls.NewListener(l, opts.TLSConfig), nil
+		}
+		// otherwise just plain listener
+		return l, nil
+	})
+}
+
 func (g *grpcServer) getListener() net.Listener {
 	if g.opts.Context == nil {
 		return nil
@@ -197,10 +266,33 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) (err err
 		}
 	}()
 
-	if g.wg != nil {
+	// the handlers which timed out hold the request until they return
+	pending := new(server.Pending)
+
+	// reject new requests once stopping, they're retried on another node.
+	// The others are waited for on stop.
+	g.RLock()
+	draining := g.draining
+	if g.wg != nil && !draining {
 		g.wg.Add(1)
-		defer g.wg.Done()
 	}
+	g.RUnlock()
+	if draining {
+		return status.Errorf(codes.Unavailable, "server is shutting down")
+	}
+	if g.wg != nil {
+		defer pending.After(g.wg.Done)
+	}
+
+	// reject the requests over the limit or when overloaded
+	release, ok := g.limiter.Acquire(g.opts.MaxConcurrentRequests, g.opts.LoadShedder)
+	if !ok {
+		return status.Errorf(codes.Unavailable, "server is overloaded")
+	}
+	defer pending.After(release)
+
+	g.inflight.Add()
+	defer pending.After(g.inflight.Done)
 
 	fullMethod, ok := grpc.MethodFromServerStream(stream)
 	if !ok {
@@ -241,7 +333,7 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) (err err
 	delete(md, "timeout")
 
 	// create new context
-	ctx := meta.NewContext(stream.Context(), md)
+	ctx := pending.NewContext(meta.NewContext(stream.Context(), md))
 
 	// get peer from context
 	if p, ok := peer.FromContext(stream.Context()); ok {
@@ -309,13 +401,16 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) (err err
 		return nil
 	}
 
-	// process the standard request flow
-	g.rpc.mu.Lock()
-	service := g.rpc.serviceMap[serviceName]
-	g.rpc.mu.Unlock()
+	// process the standard request flow, the version named by the method
+	// takes precedence over the header
+	serviceName, version := server.SplitVersion(serviceName)
+	if len(version) == 0 {
+		version = md[strings.ToLower(server.VersionHeader)]
+	}
+	service := g.rpc.lookup(serviceName, version)
 
 	if service == nil {
-		return status.New(codes.Unimplemented, fmt.Sprintf("unknown service %s", serviceName)).Err()
+		return status.New(codes.Unimplemented, fmt.Sprintf("unknown service %s", server.VersionedName(serviceName, version))).Err()
 	}
 
 	mtype := service.method[methodName]
@@ -390,10 +485,35 @@ func (g *grpcServer) processRequest(stream grpc.ServerStream, service *service,
 			return err
 		}
 
+		// recover the panics of the handler as errors
+		fn = server.RecoveryWrapper(g.opts.PanicHandler, g.opts.Metrics)(fn)
+
+		// cancel the handler running for too long
+		fn = server.TimeoutWrapper(g.handlerTimeout(service.name))(fn)
+
+		// reject the invalid requests
+		fn = server.ValidateWrapper(g.opts.Validate)(fn)
+
+		// reject the callers over their rate limits
+		fn = server.RateLimitWrapper(g.opts.RateLimits)(fn)
+
+		// hide the handler from callers in other namespaces
+		fn = server.NamespaceWrapper(service.name, g.handlerNamespaces(service.name), g.opts.CallerNamespace)(fn)
+
+		// intercept the request
+		fn = server.InterceptWrapper(g.opts.UnaryInterceptors...)(fn)
+
 		// wrap the handler func
 		for i := len(g.opts.HdlrWrappers); i > 0; i-- {
 			fn = g.opts.HdlrWrappers[i-1](fn)
 		}
+
+		// audit the requests, including the ones rejected by the wrappers
+		fn = server.AuditWrapper(g.opts.Auditor)(fn)
+
+		// report the requests, including the ones rejected by the wrappers
+		fn = server.MetricsWrapper(g.opts.Metrics)(fn)
+
 		statusCode := codes.OK
 		statusDesc := ""
 		// execute the handler
@@ -470,10 +590,31 @@ func (g *grpcServer) processStream(stream grpc.ServerStream, service *service, m
 		return nil
 	}
 
+	// recover the panics of the handler as errors
+	fn = server.RecoveryWrapper(opts.PanicHandler, opts.Metrics)(fn)
+
+	// reject the invalid messages received
+	fn = server.ValidateWrapper(opts.Validate)(fn)
+
+	// reject the callers over their rate limits
+	fn = server.RateLimitWrapper(opts.RateLimits)(fn)
+
+	// hide the handler from callers in other namespaces
+	fn = server.NamespaceWrapper(service.name, g.handlerNamespaces(service.name), opts.CallerNamespace)(fn)
+
+	// intercept the stream
+	fn = server.InterceptStreamWrapper(opts.StreamInterceptors...)(fn)
+
 	for i := len(opts.HdlrWrappers); i > 0; i-- {
 		fn = opts.HdlrWrappers[i-1](fn)
 	}
 
+	// audit the streams, including the ones rejected by the wrappers
+	fn = server.AuditWrapper(opts.Auditor)(fn)
+
+	// report the streams, including the ones rejected by the wrappers
+	fn = server.MetricsWrapper(opts.Metrics)(fn)
+
 	statusCode := codes.OK
 	statusDesc := ""
 
@@ -551,7 +692,7 @@ func (g *grpcServer) NewHandler(h interface{}, opts ...server.HandlerOption) ser
 }
 
 func (g *grpcServer) Handle(h server.Handler) error {
-	if err := g.rpc.register(h.Handler()); err != nil {
+	if err := g.rpc.register(h.Handler(), h.Options().Version); err != nil {
 		return err
 	}
 
@@ -559,6 +700,29 @@ func (g *grpcServer) Handle(h server.Handler) error {
 	return nil
 }
 
+// handlerNamespaces returns the namespaces the handler of the service is visible to
+func (g *grpcServer) handlerNamespaces(name string) []string {
+	g.RLock()
+	defer g.RUnlock()
+
+	if h, ok := g.handlers[name]; ok {
+		return h.Options().Namespaces
+	}
+	return nil
+}
+
+// handlerTimeout returns the timeout of the requests to the handler of the
+// service, the handler timeout of the server unless overridden
+func (g *grpcServer) handlerTimeout(name string) time.Duration {
+	g.RLock()
+	defer g.RUnlock()
+
+	if h, ok := g.handlers[name]; ok && h.Options().Timeout > 0 {
+		return h.Options().Timeout
+	}
+	return g.opts.HandlerTimeout
+}
+
 func (g *grpcServer) NewSubscriber(topic string, sb interface{}, opts ...server.SubscriberOption) server.Subscriber {
 	return newSubscriber(topic, sb, opts...)
 }
@@ -587,7 +751,67 @@ func (g *grpcServer) Subscribe(sb server.Subscriber) error {
 	return nil
 }
 
+// Endpoints returns the endpoints of the handlers and subscribers of the
+// server, except the internal ones
+func (g *grpcServer) Endpoints() []*registry.Endpoint {
+	g.RLock()
+	defer g.RUnlock()
+
+	// Maps are ordered randomly, sort the keys for consistency
+	var handlerList []string
+	for n, e := range g.handlers {
+		// Only advertise non internal handlers
+		if !e.Options().Internal {
+			handlerList = append(handlerList, n)
+		}
+	}
+	sort.Strings(handlerList)
+
+	var subscriberList []*subscriber
+	for e := range g.subscribers {
+		// Only advertise non internal subscribers
+		if !e.Options().Internal {
+			subscriberList = append(subscriberList, e)
+		}
+	}
+	sort.Slice(subscriberList, func(i, j int) bool {
+		return subscriberList[i].topic > subscriberList[j].topic
+	})
+
+	endpoints := make([]*registry.Endpoint, 0, len(handlerList)+len(subscriberList))
+	for _, n := range handlerList {
+		endpoints = append(endpoints, g.handlers[n].Endpoints()...)
+	}
+	for _, e := range subscriberList {
+		endpoints = append(endpoints, e.Endpoints()...)
+	}
+
+	return endpoints
+}
+
+// Register the server, recording the status of the registration
 func (g *grpcServer) Register() error {
+	err := g.register()
+	g.registration.Update(true, err, g.Options().OnRegisterError)
+	return err
+}
+
+// RegisterStatus returns the status of the registration of the server
+func (g *grpcServer) RegisterStatus() server.RegisterStatus {
+	return g.registration.Status()
+}
+
+// Inflight returns the number of requests, and messages, in flight
+func (g *grpcServer) Inflight() int {
+	return g.inflight.Count()
+}
+
+// Wait blocks until the server is stopped with no requests in flight
+func (g *grpcServer) Wait(ctx context.Context) error {
+	return g.inflight.Wait(ctx)
+}
+
+func (g *grpcServer) register() error {
 	g.RLock()
 	rsvc := g.rsvc
 	config := g.opts
@@ -631,16 +855,14 @@ func (g *grpcServer) Register() error {
 	var advt, host, port string
 	var cacheService bool
 
-	// check the advertise address first
-	// if it exists then use it, otherwise
-	// use the address
-	if len(config.Advertise) > 0 {
-		advt = config.Advertise
-	} else {
-		advt = config.Address
-	}
+	// the advertise address if set, otherwise the address listened on the
+	// other hosts can reach
+	advt = g.registration.Address(config)
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if strings.HasPrefix(advt, mnet.UnixScheme) {
+		// unix domain sockets are registered as is
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -674,37 +896,9 @@ func (g *grpcServer) Register() error {
 	node.Metadata["server"] = g.String()
 	node.Metadata["transport"] = g.String()
 	node.Metadata["protocol"] = "grpc"
+	node.Metadata[compress.MetadataKey] = compress.Supported()
 
-	g.RLock()
-	// Maps are ordered randomly, sort the keys for consistency
-	var handlerList []string
-	for n, e := range g.handlers {
-		// Only advertise non internal handlers
-		if !e.Options().Internal {
-			handlerList = append(handlerList, n)
-		}
-	}
-	sort.Strings(handlerList)
-
-	var subscriberList []*subscriber
-	for e := range g.subscribers {
-		// Only advertise non internal subscribers
-		if !e.Options().Internal {
-			subscriberList = append(subscriberList, e)
-		}
-	}
-	sort.Slice(subscriberList, func(i, j int) bool {
-		return subscriberList[i].topic > subscriberList[j].topic
-	})
-
-	endpoints := make([]*registry.Endpoint, 0, len(handlerList)+len(subscriberList))
-	for _, n := range handlerList {
-		endpoints = append(endpoints, g.handlers[n].Endpoints()...)
-	}
-	for _, e := range subscriberList {
-		endpoints = append(endpoints, e.Endpoints()...)
-	}
-	g.RUnlock()
+	endpoints := g.Endpoints()
 
 	service := &registry.Service{
 		Name:      config.Name,
@@ -751,13 +945,29 @@ func (g *grpcServer) Register() error {
 			opts = append(opts, broker.DisableAutoAck())
 		}
 
+		if d := sb.Options().Delivery; d != broker.DeliverDefault {
+			opts = append(opts, broker.DeliveryMode(d))
+		}
+
 		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
 			logger.Infof("Subscribing to topic: %s", sb.Topic())
 		}
+		// handle the messages with workers if the concurrency is bounded
+		workers := server.NewSubscriberWorkers(handler, sb, g.opts, g.wg)
+		if workers != nil {
+			handler = workers.Handle
+		}
+
 		sub, err := config.Broker.Subscribe(sb.Topic(), handler, opts...)
 		if err != nil {
+			if workers != nil {
+				workers.Stop()
+			}
 			return err
 		}
+		if workers != nil {
+			sub = workers.Subscriber(sub)
+		}
 		g.subscribers[sb] = []broker.Subscriber{sub}
 	}
 
@@ -769,7 +979,14 @@ func (g *grpcServer) Register() error {
 	return nil
 }
 
+// Deregister the server, recording the status of the registration
 func (g *grpcServer) Deregister() error {
+	err := g.deregister()
+	g.registration.Update(false, err, g.Options().OnRegisterError)
+	return err
+}
+
+func (g *grpcServer) deregister() error {
 	var err error
 	var advt, host, port string
 
@@ -777,16 +994,14 @@ func (g *grpcServer) Deregister() error {
 	config := g.opts
 	g.RUnlock()
 
-	// check the advertise address first
-	// if it exists then use it, otherwise
-	// use the address
-	if len(config.Advertise) > 0 {
-		advt = config.Advertise
-	} else {
-		advt = config.Address
-	}
+	// the advertise address if set, otherwise the address listened on the
+	// other hosts can reach
+	advt = g.registration.Address(config)
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if strings.HasPrefix(advt, mnet.UnixScheme) {
+		// unix domain sockets are registered as is
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -830,7 +1045,14 @@ func (g *grpcServer) Deregister() error {
 	}
 
 	g.registered = false
+	g.unsubscribe()
+
+	g.Unlock()
+	return nil
+}
 
+// unsubscribe closes the broker subscriptions, the lock must be held
+func (g *grpcServer) unsubscribe() {
 	wg := sync.WaitGroup{}
 	for sb, subs := range g.subscribers {
 		for _, sub := range subs {
@@ -846,9 +1068,6 @@ func (g *grpcServer) Deregister() error {
 		g.subscribers[sb] = nil
 	}
 	wg.Wait()
-
-	g.Unlock()
-	return nil
 }
 
 func (g *grpcServer) Start() error {
@@ -859,6 +1078,11 @@ func (g *grpcServer) Start() error {
 	}
 	g.RUnlock()
 
+	// limit the rate of registry writes
+	g.Lock()
+	g.opts.Registry = g.registration.Limit(g.opts)
+	g.Unlock()
+
 	config := g.Options()
 
 	// micro: config.Transport.Listen(config.Address)
@@ -868,32 +1092,45 @@ func (g *grpcServer) Start() error {
 		ts = l
 	} else {
 		var err error
-
-		// check the tls config for secure connect
-		if tc := config.TLSConfig; tc != nil {
-			ts, err = tls.Listen("tcp", config.Address, tc)
-			// otherwise just plain tcp listener
-		} else {
-			ts, err = net.Listen("tcp", config.Address)
+		if ts, err = g.listen(config.Address); err != nil {
+			return err
 		}
+	}
+
+	// listen on the other addresses, the address is the one registered
+	listeners := []net.Listener{ts}
+	for _, a := range config.Listeners {
+		l, err := g.listen(a)
 		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
 			return err
 		}
+		listeners = append(listeners, l)
 	}
 
-	if g.opts.Context != nil {
-		if c, ok := g.opts.Context.Value(maxConnKey{}).(int); ok && c > 0 {
-			ts = netutil.LimitListener(ts, c)
+	for i, l := range listeners {
+		if g.opts.Context != nil {
+			if c, ok := g.opts.Context.Value(maxConnKey{}).(int); ok && c > 0 {
+				listeners[i] = netutil.LimitListener(l, c)
+			}
 		}
-	}
 
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Server [grpc] Listening on %s", ts.Addr().String())
+		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+			logger.Infof("Server [grpc] Listening on %s", mnet.Addr(l.Addr()))
+		}
 	}
 	g.Lock()
-	g.opts.Address = ts.Addr().String()
+	g.opts.Address = mnet.Addr(ts.Addr())
 	g.Unlock()
 
+	addrs := make([]string, 0, len(listeners))
+	for _, l := range listeners {
+		addrs = append(addrs, mnet.Addr(l.Addr()))
+	}
+	g.registration.Listening(addrs...)
+
 	// only connect if we're subscribed
 	if len(g.subscribers) > 0 {
 		// connect to the broker
@@ -917,22 +1154,27 @@ func (g *grpcServer) Start() error {
 	}
 
 	// micro: go ts.Accept(s.accept)
-	go func() {
-		if err := g.srv.Serve(ts); err != nil {
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Errorf("gRPC Server start error: %v", err)
+	for _, l := range listeners {
+		go func(ts net.Listener) {
+			if err := g.srv.Serve(ts); err != nil {
+				// the listeners are closed as draining
+				g.RLock()
+				draining := g.draining
+				g.RUnlock()
+				if draining {
+					return
+				}
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("gRPC Server start error: %v", err)
+				}
 			}
-		}
-	}()
+		}(l)
+	}
 
 	go func() {
-		t := new(time.Ticker)
-
-		// only process if it exists
-		if g.opts.RegisterInterval > time.Duration(0) {
-			// new ticker
-			t = time.NewTicker(g.opts.RegisterInterval)
-		}
+		// register self on the jittered interval to spread registry
+		// writes, or retry the failures with a backoff
+		t := g.registration.After(g.opts.RegisterInterval, g.opts.RegisterJitter)
 
 		// return error chan
 		var ch chan error
@@ -941,12 +1183,13 @@ func (g *grpcServer) Start() error {
 		for {
 			select {
 			// register self on interval
-			case <-t.C:
+			case <-t:
 				if err := g.Register(); err != nil {
 					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 						logger.Error("Server register error: ", err)
 					}
 				}
+				t = g.registration.After(g.opts.RegisterInterval, g.opts.RegisterJitter)
 			// wait for exit
 			case ch = <-g.exit:
 				break Loop
@@ -961,9 +1204,32 @@ func (g *grpcServer) Start() error {
 			}
 		}
 
-		// wait for waitgroup
-		if g.wg != nil {
-			g.wg.Wait()
+		// health checks fail while draining
+		config.Health.Shutdown()
+
+		// stop receiving messages, even if deregister failed, then stop
+		// accepting requests and wait for the ones in flight
+		g.Lock()
+		g.unsubscribe()
+		g.draining = true
+		g.Unlock()
+
+		// stop accepting connections only once deregistered so clients don't
+		// select a node which is refusing connections. The existing
+		// connections are served until the grpc server stops.
+		for _, l := range listeners {
+			if err := l.Close(); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Server drain error: %v", err)
+				}
+			}
+		}
+
+		start := time.Now()
+		if g.wg != nil && !server.Drain(g.wg, g.opts.DrainTimeout) {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Server drain timeout after %v, closing with requests in flight", g.opts.DrainTimeout)
+			}
 		}
 
 		// stop the grpc server
@@ -974,9 +1240,15 @@ func (g *grpcServer) Start() error {
 			close(exit)
 		}()
 
+		// give the streams the rest of the drain timeout to finish
+		timeout := time.Second
+		if d := g.opts.DrainTimeout - time.Since(start); d > timeout {
+			timeout = d
+		}
+
 		select {
 		case <-exit:
-		case <-time.After(time.Second):
+		case <-time.After(timeout):
 			g.srv.Stop()
 		}
 
@@ -994,10 +1266,15 @@ func (g *grpcServer) Start() error {
 		}
 	}()
 
+	// the server is serving once started
+	config.Health.Resume()
+	config.Health.Set(config.Name, health.StatusServing)
+
 	// mark the server as started
 	g.Lock()
 	g.started = true
 	g.Unlock()
+	g.inflight.Start()
 
 	return nil
 }
@@ -1019,7 +1296,9 @@ func (g *grpcServer) Stop() error {
 		g.Lock()
 		g.rsvc = nil
 		g.started = false
+		g.draining = false
 		g.Unlock()
+		g.inflight.Stop()
 	}
 
 	return err
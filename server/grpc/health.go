@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/v2/debug/health"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthServer serves the grpc health checking protocol with the health of
+// the services of the server
+type healthServer struct {
+	health *health.Health
+}
+
+// servingStatus returns the grpc serving status of the health status
+func servingStatus(s health.Status) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	switch s {
+	case health.StatusServing:
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	case health.StatusNotServing:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	default:
+		return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+}
+
+func (h *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s, ok := h.health.Get(req.Service)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %s", req.Service)
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: servingStatus(s)}, nil
+}
+
+func (h *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ch, stop := h.health.Watch(req.Service)
+	defer stop()
+
+	// only send the changes of the serving status
+	last := grpc_health_v1.HealthCheckResponse_ServingStatus(-1)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case s := <-ch:
+			if ss := servingStatus(s); ss != last {
+				if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: ss}); err != nil {
+					return status.Errorf(codes.Canceled, "stream has ended: %v", err)
+				}
+				last = ss
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/debug/health"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/server"
+	gsrv "github.com/micro/go-micro/v2/server/grpc"
+	tgrpc "github.com/micro/go-micro/v2/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCHealth(t *testing.T) {
+	h := health.NewHealth()
+	s := gsrv.NewServer(
+		server.Broker(bmemory.NewBroker()),
+		server.Name("foo"),
+		server.Registry(rmemory.NewRegistry()),
+		server.Transport(tgrpc.NewTransport()),
+		server.Health(h),
+	)
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer cc.Close()
+
+	hc := grpc_health_v1.NewHealthClient(cc)
+	check := func(service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+		rsp, err := hc.Check(context.TODO(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			return 0, err
+		}
+		return rsp.Status, nil
+	}
+
+	// the server and its service are serving once started
+	for _, service := range []string{"", "foo"} {
+		if st, err := check(service); err != nil || st != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Fatalf("Expected %q to be serving, got %v %v", service, st, err)
+		}
+	}
+
+	// the health of a service can be set
+	h.Set("foo", health.StatusNotServing)
+	if st, err := check("foo"); err != nil || st != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Expected foo not to be serving, got %v %v", st, err)
+	}
+
+	// watch the changes of the health of the service
+	stream, err := hc.Watch(context.TODO(), &grpc_health_v1.HealthCheckRequest{Service: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp, err := stream.Recv(); err != nil || rsp.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Expected foo not to be serving, got %v %v", rsp, err)
+	}
+	h.Set("foo", health.StatusServing)
+	if rsp, err := stream.Recv(); err != nil || rsp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("Expected foo to be serving, got %v %v", rsp, err)
+	}
+
+	if _, err := check("bar"); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected an unknown service, got %v", err)
+	}
+}
+
+func TestGRPCHealthPerServer(t *testing.T) {
+	newServer := func(name string) server.Server {
+		return gsrv.NewServer(
+			server.Broker(bmemory.NewBroker()),
+			server.Name(name),
+			server.Registry(rmemory.NewRegistry()),
+			server.Transport(tgrpc.NewTransport()),
+		)
+	}
+
+	foo, bar := newServer("foo"), newServer("bar")
+	for _, s := range []server.Server{foo, bar} {
+		if err := s.Start(); err != nil {
+			t.Fatalf("failed to start: %v", err)
+		}
+	}
+	defer foo.Stop()
+
+	// the server stopping doesn't shut the others down
+	if err := bar.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	if st, _ := bar.Options().Health.Get(""); st != health.StatusNotServing {
+		t.Fatalf("Expected the stopped server not to be serving, got %v", st)
+	}
+	if st, _ := foo.Options().Health.Get(""); st != health.StatusServing {
+		t.Fatalf("Expected the other server to be serving, got %v", st)
+	}
+}
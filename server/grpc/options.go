@@ -8,6 +8,7 @@ import (
 	"github.com/micro/go-micro/v2/auth"
 	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/codec"
+	"github.com/micro/go-micro/v2/debug/health"
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/server"
 	"github.com/micro/go-micro/v2/transport"
@@ -21,6 +22,7 @@ type netListener struct{}
 type maxMsgSizeKey struct{}
 type maxConnKey struct{}
 type tlsAuth struct{}
+type reflectionKey struct{}
 
 // gRPC Codec to be used to encode/decode requests for a given content type
 func Codec(contentType string, c encoding.Codec) server.Option {
@@ -57,6 +59,12 @@ func Options(opts ...grpc.ServerOption) server.Option {
 	return setServerOption(grpcOptions{}, opts)
 }
 
+// Reflection serves the grpc server reflection protocol, describing the
+// handlers generated from protos to tools such as grpcurl. It's off by default.
+func Reflection(b bool) server.Option {
+	return setServerOption(reflectionKey{}, b)
+}
+
 //
 // MaxMsgSize set the maximum message in bytes the server can receive and
 // send.  Default maximum message size is 4 MB.
@@ -68,6 +76,7 @@ func MaxMsgSize(s int) server.Option {
 func newOptions(opt ...server.Option) server.Options {
 	opts := server.Options{
 		Auth:      auth.DefaultAuth,
+		Health:    health.NewHealth(),
 		Codecs:    make(map[string]codec.NewCodec),
 		Metadata:  map[string]string{},
 		Broker:    broker.DefaultBroker,
@@ -0,0 +1,227 @@
+package grpc
+
+import (
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// reflectionServer serves the grpc server reflection protocol, describing the
+// handlers of the server with the descriptors of their generated protos
+type reflectionServer struct {
+	g *grpcServer
+}
+
+// services returns the full names of the proto services of the handlers, and
+// of the services registered with the grpc server e.g. health
+func (r *reflectionServer) services() []string {
+	r.g.RLock()
+	var handlers []string
+	for name, h := range r.g.handlers {
+		if !h.Options().Internal {
+			handlers = append(handlers, name)
+		}
+	}
+	var services []string
+	for name := range r.g.srv.GetServiceInfo() {
+		services = append(services, name)
+	}
+	r.g.RUnlock()
+
+	for _, name := range handlers {
+		if sd := r.describe(name); sd != nil {
+			services = append(services, string(sd.FullName()))
+		}
+	}
+
+	sort.Strings(services)
+	return services
+}
+
+// describe returns the proto service of the handler, found in the file of
+// its request messages. A handler is only described by a service of its name
+// whose methods take the same messages, so services of the same name in other
+// packages don't match.
+func (r *reflectionServer) describe(name string) protoreflect.ServiceDescriptor {
+	r.g.rpc.mu.Lock()
+	svc, ok := r.g.rpc.serviceMap[name]
+	r.g.rpc.mu.Unlock()
+	if !ok || len(svc.method) == 0 {
+		return nil
+	}
+
+	var sd protoreflect.ServiceDescriptor
+	for mname, m := range svc.method {
+		// streams take their messages from the stream
+		if m.stream {
+			continue
+		}
+		in := message(m.ArgType)
+		if in == nil {
+			return nil
+		}
+
+		if sd == nil {
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(in.ParentFile().Path())
+			if err != nil {
+				return nil
+			}
+			if sd = fd.Services().ByName(protoreflect.Name(name)); sd == nil {
+				return nil
+			}
+		}
+
+		md := sd.Methods().ByName(protoreflect.Name(mname))
+		if md == nil || md.Input().FullName() != in.FullName() {
+			return nil
+		}
+	}
+	return sd
+}
+
+// message returns the descriptor of the proto message of the type, nil if
+// it isn't one
+func message(t reflect.Type) protoreflect.MessageDescriptor {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	m, ok := reflect.New(t).Interface().(protoiface.MessageV1)
+	if !ok {
+		return nil
+	}
+	return proto.MessageV2(m).ProtoReflect().Descriptor()
+}
+
+// files returns the encoded descriptors of the file and its dependencies
+func files(fd protoreflect.FileDescriptor) ([][]byte, error) {
+	var list [][]byte
+	seen := make(map[string]bool)
+
+	var add func(fd protoreflect.FileDescriptor) error
+	add = func(fd protoreflect.FileDescriptor) error {
+		if seen[fd.Path()] {
+			return nil
+		}
+		seen[fd.Path()] = true
+
+		b, err := protov2.Marshal(protodesc.ToFileDescriptorProto(fd))
+		if err != nil {
+			return err
+		}
+		list = append(list, b)
+
+		for i := 0; i < fd.Imports().Len(); i++ {
+			if err := add(fd.Imports().Get(i).FileDescriptor); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := add(fd); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// setFile sets the response to the descriptors of the file, or to the error
+// finding it
+func setFile(rsp *rpb.ServerReflectionResponse, fd protoreflect.FileDescriptor, err error) {
+	if err != nil {
+		setError(rsp, codes.NotFound, err)
+		return
+	}
+
+	list, err := files(fd)
+	if err != nil {
+		setError(rsp, codes.Internal, err)
+		return
+	}
+
+	rsp.MessageResponse = &rpb.ServerReflectionResponse_FileDescriptorResponse{
+		FileDescriptorResponse: &rpb.FileDescriptorResponse{FileDescriptorProto: list},
+	}
+}
+
+func setError(rsp *rpb.ServerReflectionResponse, code codes.Code, err error) {
+	rsp.MessageResponse = &rpb.ServerReflectionResponse_ErrorResponse{
+		ErrorResponse: &rpb.ErrorResponse{
+			ErrorCode:    int32(code),
+			ErrorMessage: err.Error(),
+		},
+	}
+}
+
+func (r *reflectionServer) ServerReflectionInfo(stream rpb.ServerReflection_ServerReflectionInfoServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		rsp := &rpb.ServerReflectionResponse{
+			ValidHost:       req.Host,
+			OriginalRequest: req,
+		}
+
+		switch mr := req.MessageRequest.(type) {
+		case *rpb.ServerReflectionRequest_FileByFilename:
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(mr.FileByFilename)
+			setFile(rsp, fd, err)
+		case *rpb.ServerReflectionRequest_FileContainingSymbol:
+			d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(mr.FileContainingSymbol))
+			if err != nil {
+				setError(rsp, codes.NotFound, err)
+			} else {
+				setFile(rsp, d.ParentFile(), nil)
+			}
+		case *rpb.ServerReflectionRequest_FileContainingExtension:
+			ext := mr.FileContainingExtension
+			xt, err := protoregistry.GlobalTypes.FindExtensionByNumber(protoreflect.FullName(ext.ContainingType), protoreflect.FieldNumber(ext.ExtensionNumber))
+			if err != nil {
+				setError(rsp, codes.NotFound, err)
+			} else {
+				setFile(rsp, xt.TypeDescriptor().ParentFile(), nil)
+			}
+		case *rpb.ServerReflectionRequest_AllExtensionNumbersOfType:
+			name := protoreflect.FullName(mr.AllExtensionNumbersOfType)
+			var numbers []int32
+			protoregistry.GlobalTypes.RangeExtensionsByMessage(name, func(xt protoreflect.ExtensionType) bool {
+				numbers = append(numbers, int32(xt.TypeDescriptor().Number()))
+				return true
+			})
+			rsp.MessageResponse = &rpb.ServerReflectionResponse_AllExtensionNumbersResponse{
+				AllExtensionNumbersResponse: &rpb.ExtensionNumberResponse{
+					BaseTypeName:    string(name),
+					ExtensionNumber: numbers,
+				},
+			}
+		case *rpb.ServerReflectionRequest_ListServices:
+			var services []*rpb.ServiceResponse
+			for _, name := range r.services() {
+				services = append(services, &rpb.ServiceResponse{Name: name})
+			}
+			rsp.MessageResponse = &rpb.ServerReflectionResponse_ListServicesResponse{
+				ListServicesResponse: &rpb.ListServiceResponse{Service: services},
+			}
+		default:
+			return status.Errorf(codes.InvalidArgument, "invalid reflection request %v", req.MessageRequest)
+		}
+
+		if err := stream.Send(rsp); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/server"
+	gsrv "github.com/micro/go-micro/v2/server/grpc"
+	pb "github.com/micro/go-micro/v2/server/grpc/proto"
+	tgrpc "github.com/micro/go-micro/v2/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGRPCReflection(t *testing.T) {
+	s := gsrv.NewServer(
+		server.Broker(bmemory.NewBroker()),
+		server.Name("foo"),
+		server.Registry(rmemory.NewRegistry()),
+		server.Transport(tgrpc.NewTransport()),
+		gsrv.Reflection(true),
+	)
+	if err := pb.RegisterTestHandler(s, &testServer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer cc.Close()
+
+	stream, err := rpb.NewServerReflectionClient(cc).ServerReflectionInfo(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.CloseSend()
+
+	call := func(req *rpb.ServerReflectionRequest) *rpb.ServerReflectionResponse {
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		rsp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rsp
+	}
+
+	// the handler is listed with its proto service
+	rsp := call(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	services := make(map[string]bool)
+	for _, s := range rsp.GetListServicesResponse().GetService() {
+		services[s.Name] = true
+	}
+	for _, name := range []string{"Test", "grpc.health.v1.Health"} {
+		if !services[name] {
+			t.Fatalf("Expected service %s to be listed, got %v", name, services)
+		}
+	}
+
+	// the file of the service describes its methods
+	rsp = call(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "Test"},
+	})
+	files := rsp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	if len(files) == 0 {
+		t.Fatalf("Expected the file of the service, got %v", rsp.GetErrorResponse())
+	}
+	fd := new(descriptorpb.FileDescriptorProto)
+	if err := proto.Unmarshal(files[0], fd); err != nil {
+		t.Fatal(err)
+	}
+	if fd.GetName() != "server/grpc/proto/test.proto" || len(fd.GetService()) != 1 || len(fd.GetService()[0].GetMethod()) != 3 {
+		t.Fatalf("Unexpected file descriptor %v", fd)
+	}
+	// the dependencies are sent along
+	if len(files) < 2 {
+		t.Fatalf("Expected the dependencies of the file, got %d files", len(files))
+	}
+
+	// unknown symbols are not found
+	rsp = call(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "foo.Bar"},
+	})
+	if rsp.GetErrorResponse() == nil {
+		t.Fatal("Expected an error finding an unknown symbol")
+	}
+}
+
+func TestGRPCReflectionOptIn(t *testing.T) {
+	s := gsrv.NewServer(
+		server.Broker(bmemory.NewBroker()),
+		server.Name("foo"),
+		server.Registry(rmemory.NewRegistry()),
+		server.Transport(tgrpc.NewTransport()),
+	)
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer s.Stop()
+
+	cc, err := grpc.Dial(s.Options().Address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	defer cc.Close()
+
+	stream, err := rpb.NewServerReflectionClient(cc).ServerReflectionInfo(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("Expected reflection to be off by default, got %v", err)
+	}
+}
@@ -50,6 +50,10 @@ func (r *rpcRequest) Header() map[string]string {
 }
 
 func (r *rpcRequest) Read() ([]byte, error) {
+	// a request which isn't a stream was already read
+	if !r.stream && r.body != nil {
+		return r.body, nil
+	}
 	f := &bytes.Frame{}
 	if err := r.codec.ReadBody(f); err != nil {
 		return nil, err
@@ -41,10 +41,15 @@ type service struct {
 
 // server represents an RPC Server.
 type rServer struct {
-	mu         sync.Mutex // protects the serviceMap
+	mu         sync.Mutex // protects the serviceMap and versions
 	serviceMap map[string]*service
+	// default versions of the handlers served only in versions
+	versions map[string]string
 }
 
+// versionedName is server.VersionedName, the receivers below shadow the package
+var versionedName = server.VersionedName
+
 // Is this an exported - upper case - name?
 func isExported(name string) bool {
 	rune, _ := utf8.DecodeRuneInString(name)
@@ -145,17 +150,21 @@ func prepareEndpoint(method reflect.Method) *methodType {
 	return &methodType{method: method, ArgType: argType, ReplyType: replyType, ContextType: contextType, stream: stream}
 }
 
-func (server *rServer) register(rcvr interface{}) error {
+func (server *rServer) register(rcvr interface{}, version string) error {
 	server.mu.Lock()
 	defer server.mu.Unlock()
 	if server.serviceMap == nil {
 		server.serviceMap = make(map[string]*service)
 	}
+	if server.versions == nil {
+		server.versions = make(map[string]string)
+	}
 	s := new(service)
 	s.typ = reflect.TypeOf(rcvr)
 	s.rcvr = reflect.ValueOf(rcvr)
-	sname := reflect.Indirect(s.rcvr).Type().Name()
-	if sname == "" {
+	name := reflect.Indirect(s.rcvr).Type().Name()
+	sname := versionedName(name, version)
+	if name == "" {
 		logger.Fatalf("rpc: no service name for type %v", s.typ.String())
 	}
 	if !isExported(sname) {
@@ -187,6 +196,29 @@ func (server *rServer) register(rcvr interface{}) error {
 		return errors.New(s)
 	}
 	server.serviceMap[s.name] = s
+
+	// the first version is the default
+	if _, ok := server.versions[name]; !ok && len(version) > 0 {
+		server.versions[name] = version
+	}
+	return nil
+}
+
+// lookup returns the service of the handler at the version. Without a version
+// it's the handler without one, otherwise its default version.
+func (server *rServer) lookup(name, version string) *service {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(version) > 0 {
+		return server.serviceMap[versionedName(name, version)]
+	}
+	if s, ok := server.serviceMap[name]; ok {
+		return s
+	}
+	if v, ok := server.versions[name]; ok {
+		return server.serviceMap[versionedName(name, v)]
+	}
 	return nil
 }
 
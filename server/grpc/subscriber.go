@@ -168,6 +168,14 @@ func validateSubscriber(sub server.Subscriber) error {
 
 func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broker.Handler {
 	return func(p broker.Event) (err error) {
+		g.inflight.Add()
+		defer g.inflight.Done()
+
+		// report the lag and the result of the message, once recovered
+		done := opts.Metrics.Message(p.Topic(), p.Message().Header)
+		defer func() {
+			done(err)
+		}()
 
 		defer func() {
 			if r := recover(); r != nil {
@@ -180,6 +188,15 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 		}()
 
 		msg := p.Message()
+		// drop the messages over the max size, they're acked as they'd
+		// never be handled if redelivered
+		if max := opts.MaxMessageSize; max > 0 && len(msg.Body) > max {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Dropping message on topic %s: message of %d bytes exceeds the max size of %d bytes", p.Topic(), len(msg.Body), max)
+			}
+			return nil
+		}
+
 		// if we don't have headers, create empty map
 		if msg.Header == nil {
 			msg.Header = make(map[string]string)
@@ -242,13 +259,24 @@ func (g *grpcServer) createSubHandler(sb *subscriber, opts server.Options) broke
 				return nil
 			}
 
+			// recover the panics of the subscriber as errors
+			fn = server.RecoverySubscriberWrapper(opts.PanicHandler, opts.Metrics)(fn)
+
 			for i := len(opts.SubWrappers); i > 0; i-- {
 				fn = opts.SubWrappers[i-1](fn)
 			}
 
-			if g.wg != nil {
+			// the messages received once stopping are redelivered
+			g.RLock()
+			draining := g.draining
+			if g.wg != nil && !draining {
 				g.wg.Add(1)
 			}
+			g.RUnlock()
+			if draining {
+				results <- errors.ServiceUnavailable(opts.Name, "server is shutting down")
+				continue
+			}
 			go func() {
 				if g.wg != nil {
 					defer g.wg.Done()
@@ -1,12 +1,154 @@
 package server
 
-import "context"
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/errors"
+)
 
 type HandlerOption func(*HandlerOptions)
 
 type HandlerOptions struct {
 	Internal bool
 	Metadata map[string]map[string]string
+	// Namespaces the handler is visible to, all namespaces if empty
+	Namespaces []string
+	// Timeout of the requests to the handler, overriding the handler
+	// timeout of the server
+	Timeout time.Duration
+	// Version of the handler, blank if it's not versioned
+	Version string
+}
+
+// NamespaceFunc returns the namespace of the caller of a handler
+type NamespaceFunc func(ctx context.Context) string
+
+// AccountNamespace returns the issuer of the caller's auth account as its
+// namespace, or an empty namespace if the caller has no account
+func AccountNamespace(ctx context.Context) string {
+	if acc, ok := auth.AccountFromContext(ctx); ok {
+		return acc.Issuer
+	}
+	return ""
+}
+
+// NamespaceWrapper only calls the handler for callers in the namespaces, anyone
+// else gets a not found error as if the handler didn't exist. It must wrap the
+// handler inside the wrappers which set the caller's account in the context.
+func NamespaceWrapper(name string, namespaces []string, caller NamespaceFunc) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		if len(namespaces) == 0 || caller == nil {
+			return fn
+		}
+
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			ns := caller(ctx)
+			for _, n := range namespaces {
+				if n == ns {
+					return fn(ctx, req, rsp)
+				}
+			}
+			return errors.NotFound(req.Service(), "unknown service %s", name)
+		}
+	}
+}
+
+// TimeoutWrapper cancels the context of the handler once the timeout elapsed,
+// returning a timeout error if the handler is still running. The handler
+// is unlimited if the timeout is zero. A handler which timed out is tracked
+// by the Pending of the context until it returns.
+func TimeoutWrapper(timeout time.Duration) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		if timeout <= 0 {
+			return fn
+		}
+
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			p, _ := ctx.Value(pendingKey{}).(*Pending)
+			if p != nil {
+				p.add()
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				if p != nil {
+					defer p.done()
+				}
+				// the panics of the handler are recovered in its goroutine
+				defer func() {
+					if r := recover(); r != nil {
+						done <- errors.InternalServerError(req.Service(), "panic recovered: %v", r)
+					}
+				}()
+				done <- fn(ctx, req, rsp)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				// the caller may have canceled the request earlier
+				return errors.Timeout(req.Service(), "handler %s timed out: %v", req.Endpoint(), ctx.Err())
+			}
+		}
+	}
+}
+
+type pendingKey struct{}
+
+// Pending tracks the handlers which keep running after timing out. The
+// servers release the resources of a request once its handlers returned, so a
+// handler which timed out still holds its concurrency slot and delays the
+// drain, while its timeout error is sent at once.
+type Pending struct {
+	sync.Mutex
+	running int
+	after   []func()
+}
+
+// NewContext returns a context in which the handlers are tracked
+func (p *Pending) NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pendingKey{}, p)
+}
+
+// After calls fn once the handlers tracked returned, at once if none is
+// running
+func (p *Pending) After(fn func()) {
+	p.Lock()
+	if p.running > 0 {
+		p.after = append(p.after, fn)
+		p.Unlock()
+		return
+	}
+	p.Unlock()
+	fn()
+}
+
+func (p *Pending) add() {
+	p.Lock()
+	p.running++
+	p.Unlock()
+}
+
+func (p *Pending) done() {
+	p.Lock()
+	p.running--
+	var after []func()
+	if p.running == 0 {
+		after, p.after = p.after, nil
+	}
+	p.Unlock()
+
+	for _, fn := range after {
+		fn()
+	}
 }
 
 type SubscriberOption func(*SubscriberOptions)
@@ -17,7 +159,15 @@ type SubscriberOptions struct {
 	AutoAck  bool
 	Queue    string
 	Internal bool
-	Context  context.Context
+	// Delivery is the delivery guarantee declared to the broker
+	Delivery broker.Delivery
+	// Concurrency is the number of workers handling the messages,
+	// overriding the subscriber concurrency of the server
+	Concurrency int
+	// QueueSize is the number of messages queued for the workers,
+	// overriding the subscriber queue size of the server
+	QueueSize int
+	Context   context.Context
 }
 
 // EndpointMetadata is a Handler option that allows metadata to be added to
@@ -28,6 +178,25 @@ func EndpointMetadata(name string, md map[string]string) HandlerOption {
 	}
 }
 
+// HandlerNamespaces makes the handler visible only to callers in the namespaces,
+// as returned by the server's caller namespace func. By default the namespace is
+// the issuer of the caller's account, so a single server can expose different
+// handlers to internal and external namespaces.
+func HandlerNamespaces(ns ...string) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Namespaces = append(o.Namespaces, ns...)
+	}
+}
+
+// Timeout of the requests to the handler, once elapsed its context is
+// canceled and a timeout error returned. It overrides the handler timeout of
+// the server.
+func Timeout(d time.Duration) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Timeout = d
+	}
+}
+
 // Internal Handler options specifies that a handler is not advertised
 // to the discovery system. In the future this may also limit request
 // to the internal network or authorised user.
@@ -72,6 +241,30 @@ func SubscriberQueue(n string) SubscriberOption {
 	}
 }
 
+// SubscriberDelivery declares the delivery semantics of the subscriber. The
+// server fails to start when the broker can't honour them.
+func SubscriberDelivery(d broker.Delivery) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.Delivery = d
+	}
+}
+
+// SubscriberConcurrency handles the messages of the subscriber with n
+// workers, instead of in the goroutines of the broker
+func SubscriberConcurrency(n int) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.Concurrency = n
+	}
+}
+
+// SubscriberQueueSize queues up to n messages for the workers of the
+// subscriber, the broker is blocked once the queue is full
+func SubscriberQueueSize(n int) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.QueueSize = n
+	}
+}
+
 // SubscriberContext set context options to allow broker SubscriberOption passed
 func SubscriberContext(ctx context.Context) SubscriberOption {
 	return func(o *SubscriberOptions) {
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/errors"
+)
+
+func TestNamespaceWrapper(t *testing.T) {
+	var called bool
+	fn := func(ctx context.Context, req Request, rsp interface{}) error {
+		called = true
+		return nil
+	}
+
+	internal := NamespaceWrapper("Admin", []string{"go.micro"}, AccountNamespace)(fn)
+	req := &rpcRequest{service: "go.micro.service.foo", endpoint: "Admin.Reset"}
+
+	testData := []struct {
+		account *auth.Account
+		visible bool
+	}{
+		{&auth.Account{ID: "internal", Issuer: "go.micro"}, true},
+		{&auth.Account{ID: "external", Issuer: "acme"}, false},
+		{nil, false},
+	}
+
+	for _, d := range testData {
+		called = false
+
+		ctx := context.Background()
+		if d.account != nil {
+			ctx = auth.ContextWithAccount(ctx, d.account)
+		}
+
+		err := internal(ctx, req, nil)
+		if d.visible && (err != nil || !called) {
+			t.Errorf("expected the handler to be called, got %v", err)
+		}
+		if !d.visible && (called || errors.FromError(err).Code != 404) {
+			t.Errorf("expected the handler to be hidden, got %v", err)
+		}
+	}
+
+	// handlers without namespaces are visible to everyone
+	called = false
+	if err := NamespaceWrapper("Greeter", nil, AccountNamespace)(fn)(context.Background(), req, nil); err != nil || !called {
+		t.Errorf("expected the handler to be called, got %v", err)
+	}
+}
+
+func TestTimeoutWrapper(t *testing.T) {
+	req := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}
+
+	canceled := make(chan bool, 1)
+	slow := func(ctx context.Context, req Request, rsp interface{}) error {
+		<-ctx.Done()
+		canceled <- true
+		return nil
+	}
+
+	// the context of the slow handler is canceled and a timeout returned
+	err := TimeoutWrapper(10*time.Millisecond)(slow)(context.Background(), req, nil)
+	if errors.FromError(err).Code != 408 {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the context of the handler to be canceled")
+	}
+
+	// the error of the handler is returned
+	failed := errors.BadRequest("go.micro.service.foo", "bad request")
+	err = TimeoutWrapper(time.Second)(func(ctx context.Context, req Request, rsp interface{}) error {
+		return failed
+	})(context.Background(), req, nil)
+	if err != failed {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+
+	// the panics of the handler are recovered
+	err = TimeoutWrapper(time.Second)(func(ctx context.Context, req Request, rsp interface{}) error {
+		panic("handler panic")
+	})(context.Background(), req, nil)
+	if errors.FromError(err).Code != 500 {
+		t.Fatalf("expected an internal server error, got %v", err)
+	}
+}
+
+func TestTimeoutWrapperPending(t *testing.T) {
+	req := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}
+
+	// the handler ignores the cancellation of its context
+	finish := make(chan bool)
+	stuck := func(ctx context.Context, req Request, rsp interface{}) error {
+		<-finish
+		return nil
+	}
+
+	pending := new(Pending)
+	ctx := pending.NewContext(context.Background())
+
+	// the timeout is returned at once
+	err := TimeoutWrapper(10*time.Millisecond)(stuck)(ctx, req, nil)
+	if errors.FromError(err).Code != 408 {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+
+	// the request is released once the handler returned
+	released := make(chan bool)
+	pending.After(func() {
+		close(released)
+	})
+	select {
+	case <-released:
+		t.Fatal("expected the request to be held by the handler")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(finish)
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("expected the request to be released")
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Inflight returns the number of requests, and messages, the server is
+// handling, false if the server doesn't track them
+func Inflight(s Server) (int, bool) {
+	i, ok := s.(interface{ Inflight() int })
+	if !ok {
+		return 0, false
+	}
+	return i.Inflight(), true
+}
+
+// WaitFor blocks until the server is stopped with no requests in flight, or
+// the context is done. Unlike the Wait option it's for the application: the
+// requests still in flight once the drain timeout elapsed are waited for too,
+// so it can flush its caches or close its databases only once the server is
+// done with them. It returns at once if the server isn't started.
+func WaitFor(ctx context.Context, s Server) error {
+	w, ok := s.(interface{ Wait(context.Context) error })
+	if !ok {
+		return errors.New("server doesn't track its requests in flight")
+	}
+	return w.Wait(ctx)
+}
+
+// Tracker tracks the requests in flight of a server, so it can be waited for
+// once stopped. The zero value is a stopped server with no requests in flight.
+type Tracker struct {
+	sync.Mutex
+	count   int
+	running bool
+	// idle is closed once the server is stopped with no requests in
+	// flight, it's nil while idle
+	idle chan struct{}
+}
+
+// Start marks the server as running
+func (t *Tracker) Start() {
+	t.Lock()
+	t.running = true
+	t.busy()
+	t.Unlock()
+}
+
+// Stop marks the server as stopped
+func (t *Tracker) Stop() {
+	t.Lock()
+	t.running = false
+	t.release()
+	t.Unlock()
+}
+
+// Add tracks a request in flight until Done is called
+func (t *Tracker) Add() {
+	t.Lock()
+	t.count++
+	t.busy()
+	t.Unlock()
+}
+
+// Done marks a request as handled
+func (t *Tracker) Done() {
+	t.Lock()
+	t.count--
+	t.release()
+	t.Unlock()
+}
+
+// Count returns the number of requests in flight
+func (t *Tracker) Count() int {
+	t.Lock()
+	defer t.Unlock()
+	return t.count
+}
+
+// Wait blocks until the server is stopped with no requests in flight, or the
+// context is done
+func (t *Tracker) Wait(ctx context.Context) error {
+	t.Lock()
+	idle := t.idle
+	t.Unlock()
+
+	if idle == nil {
+		return nil
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Tracker) busy() {
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+}
+
+func (t *Tracker) release() {
+	if t.count == 0 && !t.running && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	var tr Tracker
+
+	// a server never started is idle
+	if err := tr.Wait(context.TODO()); err != nil {
+		t.Fatalf("Expected no wait, got %v", err)
+	}
+
+	tr.Start()
+	tr.Add()
+	tr.Add()
+	if n := tr.Count(); n != 2 {
+		t.Fatalf("Expected 2 requests in flight, got %d", n)
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- tr.Wait(context.TODO())
+	}()
+
+	// the server is waited for until stopped with no requests in flight
+	tr.Done()
+	tr.Stop()
+	select {
+	case err := <-waited:
+		t.Fatalf("Expected to wait for the request in flight, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	tr.Done()
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the wait to return")
+	}
+
+	// the wait is canceled with the context
+	tr.Start()
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := tr.Wait(ctx); err != context.Canceled {
+		t.Fatalf("Expected the wait to be canceled, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+)
+
+// UnaryInterceptor intercepts the requests to the handlers of a server. It
+// calls the handler to continue the request, or returns without to short
+// circuit it e.g. with a cached response.
+type UnaryInterceptor func(ctx context.Context, req Request, rsp interface{}, handler HandlerFunc) error
+
+// StreamHandler handles a stream, it's the rest of the chain for an
+// interceptor
+type StreamHandler func(ctx context.Context, req Request, stream Stream) error
+
+// StreamInterceptor intercepts the streams of the handlers of a server. It
+// calls the handler to continue the stream, which it may wrap, or returns
+// without to close it.
+type StreamInterceptor func(ctx context.Context, req Request, stream Stream, handler StreamHandler) error
+
+// ChainUnary returns a handler calling the interceptors in order, the first
+// being the outermost, and then the handler
+func ChainUnary(handler HandlerFunc, interceptors ...UnaryInterceptor) HandlerFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req Request, rsp interface{}) error {
+			return interceptor(ctx, req, rsp, next)
+		}
+	}
+	return handler
+}
+
+// ChainStream returns a stream handler calling the interceptors in order, the
+// first being the outermost, and then the handler
+func ChainStream(handler StreamHandler, interceptors ...StreamInterceptor) StreamHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req Request, stream Stream) error {
+			return interceptor(ctx, req, stream, next)
+		}
+	}
+	return handler
+}
+
+// InterceptWrapper calls the interceptors of the requests before the handler,
+// see ChainUnary. The servers apply it within the handler wrappers.
+func InterceptWrapper(interceptors ...UnaryInterceptor) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		return ChainUnary(fn, interceptors...)
+	}
+}
+
+// InterceptStreamWrapper calls the interceptors of the streams before the
+// handler, see ChainStream. The servers apply it within the handler wrappers.
+func InterceptStreamWrapper(interceptors ...StreamInterceptor) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		if len(interceptors) == 0 {
+			return fn
+		}
+
+		handler := ChainStream(func(ctx context.Context, req Request, stream Stream) error {
+			return fn(ctx, req, stream)
+		}, interceptors...)
+
+		return func(ctx context.Context, req Request, stream interface{}) error {
+			s, ok := stream.(Stream)
+			if !ok {
+				return fn(ctx, req, stream)
+			}
+			return handler(ctx, req, s)
+		}
+	}
+}
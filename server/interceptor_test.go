@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestInterceptStreamWrapper(t *testing.T) {
+	var calls []string
+
+	intercept := func(name string) StreamInterceptor {
+		return func(ctx context.Context, req Request, stream Stream, handler StreamHandler) error {
+			calls = append(calls, name)
+			return handler(ctx, req, stream)
+		}
+	}
+
+	fn := InterceptStreamWrapper(intercept("first"), intercept("second"))(func(ctx context.Context, req Request, stream interface{}) error {
+		calls = append(calls, "stream")
+		return nil
+	})
+
+	req := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}
+	if err := fn(context.TODO(), req, &rpcStream{request: req}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ", "); got != "first, second, stream" {
+		t.Fatalf("Unexpected calls: %s", got)
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/debug/metrics"
+	"github.com/micro/go-micro/v2/errors"
+)
+
+const (
+	// MetricRequests counts the requests and streams handled by service,
+	// endpoint and status code
+	MetricRequests = "micro.server.requests"
+	// MetricLatency is the latency of the requests, and the duration of the
+	// streams, by service and endpoint
+	MetricLatency = "micro.server.latency"
+	// MetricInFlight is the number of requests and open streams by service
+	// and endpoint
+	MetricInFlight = "micro.server.in_flight"
+	// MetricMessages counts the messages handled by topic and status code
+	MetricMessages = "micro.server.messages"
+	// MetricLag is the time from the publishing of the messages to their
+	// handling by topic
+	MetricLag = "micro.server.lag"
+	// MetricPanics counts the panics recovered by service and endpoint, or
+	// by topic for the subscribers
+	MetricPanics = "micro.server.panics"
+)
+
+// HandlerMetrics reports the metrics of the handlers and subscribers of a
+// server to a reporter
+type HandlerMetrics struct {
+	reporter metrics.Reporter
+	gauges   *metrics.Gauges
+}
+
+// NewHandlerMetrics returns the metrics of a server reported to the reporter
+func NewHandlerMetrics(r metrics.Reporter) *HandlerMetrics {
+	return &HandlerMetrics{
+		reporter: r,
+		gauges:   metrics.NewGauges(r),
+	}
+}
+
+// status returns the status code of the error
+func status(err error) int32 {
+	// the end of a stream is not an error
+	if err == nil || err == lastStreamResponseError {
+		return 200
+	}
+	if c := errors.FromError(err).Code; c > 0 {
+		return c
+	}
+	return 500
+}
+
+// code returns the status code of the error as a tag
+func code(err error) string {
+	return strconv.Itoa(int(status(err)))
+}
+
+// Start reports the start of a request, or the opening of a stream, and
+// returns the func reporting its end with its error
+func (m *HandlerMetrics) Start(req Request) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+
+	tags := metrics.Tags{
+		"service":  req.Service(),
+		"endpoint": req.Endpoint(),
+	}
+	start := time.Now()
+
+	m.gauges.Add(MetricInFlight, tags, 1)
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			m.reporter.Timing(MetricLatency, time.Since(start), tags)
+			m.gauges.Add(MetricInFlight, tags, -1)
+			m.reporter.Count(MetricRequests, 1, metrics.Tags{
+				"service":  req.Service(),
+				"endpoint": req.Endpoint(),
+				"code":     code(err),
+			})
+		})
+	}
+}
+
+// Message reports the lag of a message of the topic, from the timestamp set
+// in its header on publishing, and returns the func reporting its handling
+// with its error
+func (m *HandlerMetrics) Message(topic string, header map[string]string) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+
+	if ts, err := strconv.ParseInt(header["Micro-Timestamp"], 10, 64); err == nil {
+		m.reporter.Timing(MetricLag, time.Since(time.Unix(0, ts)), metrics.Tags{"topic": topic})
+	}
+
+	return func(err error) {
+		m.reporter.Count(MetricMessages, 1, metrics.Tags{
+			"topic": topic,
+			"code":  code(err),
+		})
+	}
+}
+
+// Panic reports a panic recovered from a handler or subscriber
+func (m *HandlerMetrics) Panic(p *Panic) {
+	if m == nil {
+		return
+	}
+
+	tags := metrics.Tags{"topic": p.Topic}
+	if len(p.Topic) == 0 {
+		tags = metrics.Tags{
+			"service":  p.Service,
+			"endpoint": p.Endpoint,
+		}
+	}
+	m.reporter.Count(MetricPanics, 1, tags)
+}
+
+// MetricsWrapper reports the metrics of the requests to the handler. It wraps
+// the other wrappers to report the requests they reject.
+func MetricsWrapper(m *HandlerMetrics) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		if m == nil {
+			return fn
+		}
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			done := m.Start(req)
+			err := fn(ctx, req, rsp)
+			done(err)
+			return err
+		}
+	}
+}
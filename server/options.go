@@ -9,6 +9,8 @@ import (
 	"github.com/micro/go-micro/v2/auth"
 	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/codec"
+	"github.com/micro/go-micro/v2/debug/health"
+	"github.com/micro/go-micro/v2/debug/metrics"
 	"github.com/micro/go-micro/v2/debug/trace"
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/transport"
@@ -20,6 +22,7 @@ type Options struct {
 	Registry     registry.Registry
 	Tracer       trace.Tracer
 	Auth         auth.Auth
+	Health       *health.Health
 	Transport    transport.Transport
 	Metadata     map[string]string
 	Name         string
@@ -31,12 +34,84 @@ type Options struct {
 	HdlrWrappers []HandlerWrapper
 	SubWrappers  []SubscriberWrapper
 
+	// Interceptors of the requests and streams, the first is the
+	// outermost. They're called within the handler wrappers.
+	UnaryInterceptors  []UnaryInterceptor
+	StreamInterceptors []StreamInterceptor
+
+	// CallerNamespace returns the namespace of a caller to decide which
+	// handlers it can see
+	CallerNamespace NamespaceFunc
+
 	// RegisterCheck runs a check function before registering the service
 	RegisterCheck func(context.Context) error
 	// The register expiry time
 	RegisterTTL time.Duration
 	// The interval on which to register
 	RegisterInterval time.Duration
+	// The max random jitter added to the register interval
+	RegisterJitter time.Duration
+	// The max registry writes per second, zero is unlimited
+	RegisterLimit float64
+	// The number of registry writes allowed at once
+	RegisterBurst int
+	// OnRegisterError is called with the errors registering, or
+	// deregistering, the service
+	OnRegisterError func(error)
+
+	// MaxConcurrentRequests is the max number of requests in flight, the
+	// requests over it are rejected as unavailable. Zero is unlimited.
+	MaxConcurrentRequests int
+	// LoadShedder rejects the requests as unavailable when overloaded
+	LoadShedder Shedder
+
+	// MaxRequestSize is the max size in bytes of a request, or a message of
+	// a stream, received. Zero is unlimited.
+	MaxRequestSize int
+	// MaxResponseSize is the max size in bytes of a response, or a message
+	// of a stream, sent. Zero is unlimited.
+	MaxResponseSize int
+	// MaxMessageSize is the max size in bytes of a broker message handled
+	// by the subscribers. Zero is unlimited.
+	MaxMessageSize int
+
+	// SubscriberConcurrency is the number of workers handling the messages
+	// of each subscriber, zero handles them in the goroutines of the broker
+	SubscriberConcurrency int
+	// SubscriberQueueSize is the number of messages of each subscriber
+	// queued for its workers
+	SubscriberQueueSize int
+
+	// RateLimits are the limits of the requests of each caller to the
+	// endpoints, none if nil
+	RateLimits *RateLimits
+
+	// Auditor writes the audit records of the requests, none if nil
+	Auditor *Auditor
+
+	// Validate validates the requests before the handlers, none are
+	// validated if nil
+	Validate ValidateFunc
+
+	// PanicHandler handles the panics recovered from the handlers and
+	// subscribers, DefaultPanicHandler if nil
+	PanicHandler PanicFunc
+
+	// Listeners are the addresses the server listens on as well as the
+	// Address, which is the one registered, e.g. a unix domain socket
+	Listeners []string
+
+	// Metrics report the latency, status codes and requests in flight of
+	// the handlers, and the lag of the subscribers, none if nil
+	Metrics *HandlerMetrics
+
+	// HandlerTimeout is the time after which the context of a handler is
+	// canceled and a timeout error returned. Zero is unlimited.
+	HandlerTimeout time.Duration
+
+	// DrainTimeout is the time the server waits on stop for the requests
+	// and subscriber handlers in flight to finish, once deregistered
+	DrainTimeout time.Duration
 
 	// The router for requests
 	Router Router
@@ -65,6 +140,12 @@ func newOptions(opt ...Option) Options {
 		opts.Auth = auth.DefaultAuth
 	}
 
+	// each server has a health of its own, so it's not serving once
+	// stopped while the others still are
+	if opts.Health == nil {
+		opts.Health = health.NewHealth()
+	}
+
 	if opts.Broker == nil {
 		opts.Broker = broker.DefaultBroker
 	}
@@ -81,6 +162,10 @@ func newOptions(opt ...Option) Options {
 		opts.RegisterCheck = DefaultRegisterCheck
 	}
 
+	if opts.CallerNamespace == nil {
+		opts.CallerNamespace = AccountNamespace
+	}
+
 	if len(opts.Address) == 0 {
 		opts.Address = DefaultAddress
 	}
@@ -128,13 +213,22 @@ func Version(v string) Option {
 	}
 }
 
-// Address to bind to - host:port
+// Address to bind to - host:port, or unix:///path.sock for a unix domain
+// socket
 func Address(a string) Option {
 	return func(o *Options) {
 		o.Address = a
 	}
 }
 
+// Listen on the addresses as well as the Address, e.g. a unix domain socket
+// for sidecars while the Address is registered for the other services
+func Listen(addrs ...string) Option {
+	return func(o *Options) {
+		o.Listeners = append(o.Listeners, addrs...)
+	}
+}
+
 // The address to advertise for discovery - host:port
 func Advertise(a string) Option {
 	return func(o *Options) {
@@ -200,6 +294,14 @@ func Metadata(md map[string]string) Option {
 	}
 }
 
+// CallerNamespace sets the func returning the namespace of a caller, which
+// decides the handlers visible to it. Defaults to AccountNamespace.
+func CallerNamespace(fn NamespaceFunc) Option {
+	return func(o *Options) {
+		o.CallerNamespace = fn
+	}
+}
+
 // RegisterCheck run func before registry service
 func RegisterCheck(fn func(context.Context) error) Option {
 	return func(o *Options) {
@@ -221,6 +323,32 @@ func RegisterInterval(t time.Duration) Option {
 	}
 }
 
+// RegisterJitter adds a random delay of up to d to each register interval
+// so a fleet of services restarted together does not re-register in lockstep
+func RegisterJitter(d time.Duration) Option {
+	return func(o *Options) {
+		o.RegisterJitter = d
+	}
+}
+
+// RegisterLimit limits the rate of registry writes made by the server using
+// a token bucket of the given rate per second and burst size
+func RegisterLimit(r float64, burst int) Option {
+	return func(o *Options) {
+		o.RegisterLimit = r
+		o.RegisterBurst = burst
+	}
+}
+
+// OnRegisterError sets the func called with the errors registering, or
+// deregistering, the service. The failures are retried with an exponential
+// backoff up to the register interval.
+func OnRegisterError(fn func(error)) Option {
+	return func(o *Options) {
+		o.OnRegisterError = fn
+	}
+}
+
 // TLSConfig specifies a *tls.Config
 func TLSConfig(t *tls.Config) Option {
 	return func(o *Options) {
@@ -241,6 +369,20 @@ func TLSConfig(t *tls.Config) Option {
 	}
 }
 
+// TLSCertificate sets the func returning the certificate of the server on
+// each handshake, e.g. the GetCertificate func of a tls.Reloader, so the
+// certificate can be rotated without restarting the server
+func TLSCertificate(fn func(*tls.ClientHelloInfo) (*tls.Certificate, error)) Option {
+	return func(o *Options) {
+		config := new(tls.Config)
+		if o.TLSConfig != nil {
+			config = o.TLSConfig.Clone()
+		}
+		config.GetCertificate = fn
+		TLSConfig(config)(o)
+	}
+}
+
 // WithRouter sets the request router
 func WithRouter(r Router) Option {
 	return func(o *Options) {
@@ -264,6 +406,182 @@ func Wait(wg *sync.WaitGroup) Option {
 	}
 }
 
+// Health sets the health status of the services served by the server, the
+// server is not serving once stopped. Defaults to a health of its own.
+func Health(h *health.Health) Option {
+	return func(o *Options) {
+		o.Health = h
+	}
+}
+
+// MaxConcurrentRequests sets the max number of requests in flight, the
+// requests and streams over it are rejected with a retryable unavailable
+// error instead of piling up
+func MaxConcurrentRequests(n int) Option {
+	return func(o *Options) {
+		o.MaxConcurrentRequests = n
+	}
+}
+
+// LoadShedder sets the shedder rejecting requests with a retryable
+// unavailable error when the server is overloaded e.g. CPUShedder
+func LoadShedder(s Shedder) Option {
+	return func(o *Options) {
+		o.LoadShedder = s
+	}
+}
+
+// MaxRequestSize sets the max size in bytes of a request, or a message of a
+// stream, received. The requests over it are rejected with a 413 error
+// before being decoded.
+func MaxRequestSize(n int) Option {
+	return func(o *Options) {
+		o.MaxRequestSize = n
+	}
+}
+
+// MaxResponseSize sets the max size in bytes of a response, or a message of
+// a stream, sent. A 413 error is sent instead of the responses over it.
+func MaxResponseSize(n int) Option {
+	return func(o *Options) {
+		o.MaxResponseSize = n
+	}
+}
+
+// MaxMessageSize sets the max size in bytes of a broker message handled by
+// the subscribers. The messages over it are dropped with a 413 error
+// returned to the broker.
+func MaxMessageSize(n int) Option {
+	return func(o *Options) {
+		o.MaxMessageSize = n
+	}
+}
+
+// SubscriberWorkers handles the messages of each subscriber with n workers,
+// with up to size messages queued for them, unless set on the subscriber. The
+// broker is blocked once the queue is full, bounding the goroutines handling
+// bursts of messages.
+func SubscriberWorkers(n, size int) Option {
+	return func(o *Options) {
+		o.SubscriberConcurrency = n
+		o.SubscriberQueueSize = size
+	}
+}
+
+// RateLimit limits the requests of each caller to the endpoints of the
+// server to rps per second with bursts of up to burst requests. Requests over
+// the limit fail with a 429 error.
+func RateLimit(rps float64, burst int) Option {
+	return func(o *Options) {
+		if o.RateLimits == nil {
+			o.RateLimits = NewRateLimits(nil)
+		}
+		o.RateLimits.Set("", rps, burst)
+	}
+}
+
+// RateLimitEndpoint limits the requests of each caller to the endpoint,
+// overriding the limit of the server, see RateLimit
+func RateLimitEndpoint(endpoint string, rps float64, burst int) Option {
+	return func(o *Options) {
+		if o.RateLimits == nil {
+			o.RateLimits = NewRateLimits(nil)
+		}
+		o.RateLimits.Set(endpoint, rps, burst)
+	}
+}
+
+// RateLimitCaller sets the func identifying the callers of the rate limits,
+// AccountCaller by default
+func RateLimitCaller(fn CallerFunc) Option {
+	return func(o *Options) {
+		if o.RateLimits == nil {
+			o.RateLimits = NewRateLimits(fn)
+			return
+		}
+		o.RateLimits.Lock()
+		o.RateLimits.caller = fn
+		o.RateLimits.Unlock()
+	}
+}
+
+// Metrics reports the metrics of the handlers and subscribers to the
+// reporter, see HandlerMetrics
+func Metrics(r metrics.Reporter) Option {
+	return func(o *Options) {
+		o.Metrics = NewHandlerMetrics(r)
+	}
+}
+
+// Audit writes the audit records of the requests and streams to the sink. The
+// accounts of the callers are inspected with the auth of the server, unless
+// set with AuditAuth.
+func Audit(sink AuditSink, opts ...AuditOption) Option {
+	return func(o *Options) {
+		aopts := append([]AuditOption{AuditAuth(func() auth.Auth {
+			return o.Auth
+		})}, opts...)
+		o.Auditor = NewAuditor(sink, aopts...)
+	}
+}
+
+// Validate validates the requests before the handlers, and the messages
+// received by the streams, with ValidateMessage if fn is nil, e.g. the
+// messages generated by protoc-gen-validate. The invalid requests get a bad
+// request error listing the fields invalid.
+func Validate(fn ValidateFunc) Option {
+	return func(o *Options) {
+		if fn == nil {
+			fn = ValidateMessage
+		}
+		o.Validate = fn
+	}
+}
+
+// PanicHandler sets the func handling the panics recovered from the handlers
+// and subscribers, which are returned as internal server errors with the id of
+// the panic
+func PanicHandler(fn PanicFunc) Option {
+	return func(o *Options) {
+		o.PanicHandler = fn
+	}
+}
+
+// HandlerTimeout sets the time after which the context of a handler is
+// canceled and a timeout error returned, unless overridden with the Timeout
+// handler option. The streams aren't limited.
+func HandlerTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.HandlerTimeout = d
+	}
+}
+
+// DrainTimeout sets the time the server waits on stop for the requests and
+// subscriber handlers in flight to finish. The server is deregistered and
+// stops accepting requests first, then the transport is closed once drained
+// or on timeout.
+func DrainTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = d
+	}
+}
+
+// Intercept adds interceptors to the requests of the server, they're called
+// in the order they're added, within the handler wrappers
+func Intercept(i ...UnaryInterceptor) Option {
+	return func(o *Options) {
+		o.UnaryInterceptors = append(o.UnaryInterceptors, i...)
+	}
+}
+
+// InterceptStream adds interceptors to the streams of the server, they're
+// called in the order they're added, within the handler wrappers
+func InterceptStream(i ...StreamInterceptor) Option {
+	return func(o *Options) {
+		o.StreamInterceptors = append(o.StreamInterceptors, i...)
+	}
+}
+
 // Adds a handler Wrapper to a list of options passed into the server
 func WrapHandler(w HandlerWrapper) Option {
 	return func(o *Options) {
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+	"golang.org/x/time/rate"
+)
+
+// CallerFunc returns the identity of the caller of a handler
+type CallerFunc func(ctx context.Context) string
+
+// AccountCaller returns the id of the caller's auth account, or its remote
+// host if it has no account. It must be called inside the wrappers which set
+// the caller's account in the context.
+func AccountCaller(ctx context.Context) string {
+	if acc, ok := auth.AccountFromContext(ctx); ok {
+		return acc.ID
+	}
+
+	remote, _ := metadata.Get(ctx, "Remote")
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		return host
+	}
+	return remote
+}
+
+// RateLimits are the token bucket limits of the requests of each caller to
+// the endpoints of the server
+type RateLimits struct {
+	caller CallerFunc
+
+	sync.Mutex
+	// rates by endpoint, the default rate by a blank endpoint
+	rates map[string]rate.Limit
+	burst map[string]int
+	// limiters by endpoint and caller
+	limiters map[string]*callerLimiter
+	// last time the idle limiters were evicted
+	evicted time.Time
+}
+
+type callerLimiter struct {
+	*rate.Limiter
+	used time.Time
+}
+
+// NewRateLimits returns rate limits without any limit of the callers
+// identified by the caller func, AccountCaller if nil
+func NewRateLimits(caller CallerFunc) *RateLimits {
+	if caller == nil {
+		caller = AccountCaller
+	}
+
+	return &RateLimits{
+		caller:   caller,
+		rates:    make(map[string]rate.Limit),
+		burst:    make(map[string]int),
+		limiters: make(map[string]*callerLimiter),
+		evicted:  time.Now(),
+	}
+}
+
+// Set limits the requests of each caller to the endpoint to rps per second
+// with bursts of up to burst requests. A blank endpoint sets the default
+// limit of the endpoints without their own, and a rps of zero removes the
+// limit.
+func (r *RateLimits) Set(endpoint string, rps float64, burst int) {
+	r.Lock()
+	defer r.Unlock()
+
+	// the callers get the new limit on their next request
+	for k := range r.limiters {
+		delete(r.limiters, k)
+	}
+
+	if rps <= 0 {
+		delete(r.rates, endpoint)
+		delete(r.burst, endpoint)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	r.rates[endpoint] = rate.Limit(rps)
+	r.burst[endpoint] = burst
+}
+
+// evict removes the limiters unused long enough to be refilled, a new one
+// allows the same requests
+func (r *RateLimits) evict(now time.Time) {
+	for k, l := range r.limiters {
+		refill := time.Duration(float64(l.Burst()) / float64(l.Limit()) * float64(time.Second))
+		if now.Sub(l.used) > refill {
+			delete(r.limiters, k)
+		}
+	}
+	r.evicted = now
+}
+
+// Allow takes a token of the limit of the caller of the endpoint of the
+// request, returning a 429 error if there's none left
+func (r *RateLimits) Allow(ctx context.Context, req Request) error {
+	if r == nil {
+		return nil
+	}
+
+	endpoint := req.Endpoint()
+
+	r.Lock()
+	limit, ok := r.rates[endpoint]
+	if !ok {
+		endpoint = ""
+		limit, ok = r.rates[endpoint]
+	}
+	if !ok {
+		r.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	if now.Sub(r.evicted) > time.Minute {
+		r.evict(now)
+	}
+
+	k := endpoint + "/" + r.caller(ctx)
+	l, ok := r.limiters[k]
+	if !ok {
+		l = &callerLimiter{Limiter: rate.NewLimiter(limit, r.burst[endpoint])}
+		r.limiters[k] = l
+	}
+	l.used = now
+	r.Unlock()
+
+	if !l.AllowN(now, 1) {
+		return errors.New(req.Service(), "rate limited", 429)
+	}
+	return nil
+}
+
+// RateLimitWrapper rejects the requests of the callers over their rate
+// limits with a 429 error. It must wrap the handler inside the wrappers
+// which set the caller's account in the context.
+func RateLimitWrapper(r *RateLimits) HandlerWrapper {
+	return func(fn HandlerFunc) HandlerFunc {
+		if r == nil {
+			return fn
+		}
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			if err := r.Allow(ctx, req); err != nil {
+				return err
+			}
+			return fn(ctx, req, rsp)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+func TestAccountCaller(t *testing.T) {
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{"Remote": "10.0.0.1:54321"})
+	if c := AccountCaller(ctx); c != "10.0.0.1" {
+		t.Fatalf("Expected the remote host as the caller, got %q", c)
+	}
+
+	ctx = auth.ContextWithAccount(ctx, &auth.Account{ID: "alice"})
+	if c := AccountCaller(ctx); c != "alice" {
+		t.Fatalf("Expected the account as the caller, got %q", c)
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	r := NewRateLimits(func(ctx context.Context) string {
+		caller, _ := metadata.Get(ctx, "Caller")
+		return caller
+	})
+	r.Set("", 1, 2)
+	r.Set("Foo.Baz", 1, 1)
+
+	caller := func(name string) context.Context {
+		return metadata.Set(context.Background(), "Caller", name)
+	}
+	bar := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}
+	baz := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Baz"}
+
+	// the default limit applies to each caller
+	for _, c := range []string{"alice", "bob"} {
+		for i := 0; i < 2; i++ {
+			if err := r.Allow(caller(c), bar); err != nil {
+				t.Fatalf("Unexpected error of request %d of %s in the burst: %v", i, c, err)
+			}
+		}
+		if err := r.Allow(caller(c), bar); errors.FromError(err).Code != 429 {
+			t.Fatalf("Expected %s to be rate limited, got %v", c, err)
+		}
+	}
+
+	// the limit of the endpoint overrides the default one
+	if err := r.Allow(caller("alice"), baz); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Allow(caller("alice"), baz); errors.FromError(err).Code != 429 {
+		t.Fatalf("Expected the endpoint to be rate limited, got %v", err)
+	}
+
+	// a zero rate removes the limit
+	r.Set("", 0, 0)
+	for i := 0; i < 10; i++ {
+		if err := r.Allow(caller("alice"), bar); err != nil {
+			t.Fatalf("Unexpected error without limit: %v", err)
+		}
+	}
+
+	// the idle limiters are evicted once refilled
+	r.Set("", 1, 1)
+	r.Allow(caller("alice"), bar)
+	r.Lock()
+	r.evict(time.Now().Add(2 * time.Second))
+	n := len(r.limiters)
+	r.Unlock()
+	if n != 0 {
+		t.Fatalf("Expected the idle limiters to be evicted, got %d", n)
+	}
+
+	// no limits allow everything
+	var none *RateLimits
+	if err := none.Allow(caller("alice"), bar); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitWrapper(t *testing.T) {
+	r := NewRateLimits(nil)
+	r.Set("", 1, 1)
+
+	var called int
+	fn := RateLimitWrapper(r)(func(ctx context.Context, req Request, rsp interface{}) error {
+		called++
+		return nil
+	})
+
+	ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: "alice"})
+	req := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}
+	if err := fn(ctx, req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := fn(ctx, req, nil); errors.FromError(err).Code != 429 {
+		t.Fatalf("Expected a rate limited error, got %v", err)
+	}
+	if called != 1 {
+		t.Fatalf("Expected the handler to be called once, got %d", called)
+	}
+}
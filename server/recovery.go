@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/google/uuid"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/logger"
+)
+
+// Panic is a panic recovered from a handler or a subscriber
+type Panic struct {
+	// Id of the panic, returned to the caller in the error to correlate it
+	Id string
+	// Service and Endpoint of the handler, blank for a subscriber
+	Service  string
+	Endpoint string
+	// Topic of the subscriber, blank for a handler
+	Topic string
+	// Value passed to panic
+	Value interface{}
+	// Stack of the goroutine which panicked
+	Stack []byte
+}
+
+// PanicFunc handles the panics recovered e.g. to report them to an error
+// tracker. The stack of the panic is logged if nil.
+type PanicFunc func(ctx context.Context, p *Panic)
+
+// DefaultPanicHandler logs the panics with their stack
+func DefaultPanicHandler(ctx context.Context, p *Panic) {
+	if !logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+		return
+	}
+	if len(p.Topic) > 0 {
+		logger.Errorf("panic %s recovered from subscriber of topic %s: %v\n%s", p.Id, p.Topic, p.Value, p.Stack)
+		return
+	}
+	logger.Errorf("panic %s recovered from handler %s of %s: %v\n%s", p.Id, p.Endpoint, p.Service, p.Value, p.Stack)
+}
+
+// recovered reports the panic to the handler and the metrics, returning the
+// internal server error returned to the caller
+func recovered(ctx context.Context, fn PanicFunc, m *HandlerMetrics, id string, p *Panic) error {
+	p.Id = uuid.New().String()
+	p.Stack = debug.Stack()
+
+	if fn == nil {
+		fn = DefaultPanicHandler
+	}
+	fn(ctx, p)
+	m.Panic(p)
+
+	// the value recovered is not returned to the caller, only its id
+	return errors.InternalServerError(id, "panic recovered, correlation id %s", p.Id)
+}
+
+// RecoveryWrapper recovers the panics of the handler as internal server
+// errors, reporting them to the panic handler, DefaultPanicHandler if nil, and
+// the metrics. It must wrap the handler inside the other wrappers, e.g. in the
+// goroutine of the TimeoutWrapper.
+func RecoveryWrapper(fn PanicFunc, m *HandlerMetrics) HandlerWrapper {
+	return func(h HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req Request, rsp interface{}) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = recovered(ctx, fn, m, req.Service(), &Panic{
+						Service:  req.Service(),
+						Endpoint: req.Endpoint(),
+						Value:    r,
+					})
+				}
+			}()
+			return h(ctx, req, rsp)
+		}
+	}
+}
+
+// RecoverySubscriberWrapper recovers the panics of the subscriber as internal
+// server errors, see RecoveryWrapper
+func RecoverySubscriberWrapper(fn PanicFunc, m *HandlerMetrics) SubscriberWrapper {
+	return func(h SubscriberFunc) SubscriberFunc {
+		return func(ctx context.Context, msg Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = recovered(ctx, fn, m, "go.micro.server", &Panic{
+						Topic: msg.Topic(),
+						Value: r,
+					})
+				}
+			}()
+			return h(ctx, msg)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v2/debug/metrics/memory"
+	"github.com/micro/go-micro/v2/errors"
+)
+
+func TestRecoveryWrapper(t *testing.T) {
+	var recovered *Panic
+	fn := func(ctx context.Context, p *Panic) {
+		recovered = p
+	}
+	r := memory.NewReporter()
+	m := NewHandlerMetrics(r)
+
+	h := RecoveryWrapper(fn, m)(func(ctx context.Context, req Request, rsp interface{}) error {
+		panic("boom")
+	})
+	req := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}
+
+	// the caller gets the id of the panic, not its value
+	err := h(context.TODO(), req, nil)
+	merr := errors.FromError(err)
+	if merr.Code != 500 {
+		t.Fatalf("Expected an internal server error, got %v", err)
+	}
+	if recovered == nil || recovered.Value != "boom" || recovered.Endpoint != "Foo.Bar" || len(recovered.Stack) == 0 {
+		t.Fatalf("Expected the panic to be handled, got %+v", recovered)
+	}
+	if !strings.Contains(merr.Detail, recovered.Id) || strings.Contains(merr.Detail, "boom") {
+		t.Fatalf("Expected the id of the panic in the error, got %s", merr.Detail)
+	}
+
+	s := RecoverySubscriberWrapper(fn, m)(func(ctx context.Context, msg Message) error {
+		panic("bang")
+	})
+	if err := s(context.TODO(), &rpcMessage{topic: "foo"}); errors.FromError(err).Code != 500 {
+		t.Fatalf("Expected an internal server error, got %v", err)
+	}
+	if recovered.Value != "bang" || recovered.Topic != "foo" {
+		t.Fatalf("Expected the panic of the subscriber to be handled, got %+v", recovered)
+	}
+
+	list, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != MetricPanics || list[1].Name != MetricPanics {
+		t.Fatalf("Expected the panics to be counted, got %+v", list)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry"
+	"github.com/micro/go-micro/v2/registry/limit"
+	"github.com/micro/go-micro/v2/util/backoff"
+	"github.com/micro/go-micro/v2/util/jitter"
+	mnet "github.com/micro/go-micro/v2/util/net"
+)
+
+// RegisterStatus is the status of the registration of a server
+type RegisterStatus struct {
+	// Registered is true while the server is registered
+	Registered bool
+	// Error is the error of the last registration, or deregistration,
+	// nil if it succeeded
+	Error error
+	// Failures is the number of registrations failed in a row
+	Failures int
+	// Updated is the time of the last registration
+	Updated time.Time
+}
+
+// RegistrationStatus returns the status of the registration of the server,
+// false if the server doesn't track it
+func RegistrationStatus(s Server) (RegisterStatus, bool) {
+	r, ok := s.(interface{ RegisterStatus() RegisterStatus })
+	if !ok {
+		return RegisterStatus{}, false
+	}
+	return r.RegisterStatus(), true
+}
+
+// Registration tracks the status of the registration of a server, so the
+// failures are retried with an exponential backoff rather than on the next
+// register interval
+type Registration struct {
+	sync.RWMutex
+	status RegisterStatus
+	// limited is the registry wrapped to limit the rate of writes
+	limited registry.Registry
+	// listening are the addresses the server listens on
+	listening []string
+}
+
+// Limit returns the registry of the options wrapped to limit the rate of
+// writes if a limit is set. The wrapper is built once and reused, so starting
+// the server again doesn't stack another limiter on it.
+func (r *Registration) Limit(o Options) registry.Registry {
+	if o.RegisterLimit <= 0 {
+		return o.Registry
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.limited != nil && o.Registry == r.limited {
+		return r.limited
+	}
+
+	lopts := []limit.Option{limit.WithRate(o.RegisterLimit)}
+	if o.RegisterBurst > 0 {
+		lopts = append(lopts, limit.WithBurst(o.RegisterBurst))
+	}
+	r.limited = limit.New(o.Registry, lopts...)
+	return r.limited
+}
+
+// Listening records the addresses the server listens on, its address first
+func (r *Registration) Listening(addrs ...string) {
+	r.Lock()
+	defer r.Unlock()
+	r.listening = addrs
+}
+
+// Address returns the address registered: the advertise address if set,
+// otherwise the first address listened on which isn't a unix domain socket,
+// as the other hosts can't reach those, or the address if there's none
+func (r *Registration) Address(o Options) string {
+	if len(o.Advertise) > 0 {
+		return o.Advertise
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, addr := range r.listening {
+		if !strings.HasPrefix(addr, mnet.UnixScheme) {
+			return addr
+		}
+	}
+	return o.Address
+}
+
+// Update records the result of registering, or deregistering, the server and
+// reports the error to the callback, if any
+func (r *Registration) Update(registered bool, err error, fn func(error)) {
+	r.Lock()
+	r.status.Error = err
+	r.status.Updated = time.Now()
+	if err != nil {
+		r.status.Failures++
+	} else {
+		r.status.Registered = registered
+		r.status.Failures = 0
+	}
+	r.Unlock()
+
+	if err != nil && fn != nil {
+		fn(err)
+	}
+}
+
+// Status returns the status of the registration
+func (r *Registration) Status() RegisterStatus {
+	r.RLock()
+	defer r.RUnlock()
+	return r.status
+}
+
+// Next returns the time to wait before the next registration: the backoff of
+// the failures up to the interval, half of it jittered so the servers failing
+// together don't retry together, otherwise the interval with up to jitter
+// added. It's zero if the server isn't registered on an interval and the last
+// registration succeeded.
+func (r *Registration) Next(interval, jitt time.Duration) time.Duration {
+	r.RLock()
+	failures := r.status.Failures
+	r.RUnlock()
+
+	if failures > 0 {
+		d := backoff.Do(failures)
+		if interval > 0 && d > interval {
+			d = interval
+		}
+		return d/2 + jitter.Do(d/2)
+	}
+
+	if interval <= 0 {
+		return 0
+	}
+	return interval + jitter.Do(jitt)
+}
+
+// After returns a channel receiving the time of the next registration, nil
+// if there's none
+func (r *Registration) After(interval, jitt time.Duration) <-chan time.Time {
+	if d := r.Next(interval, jitt); d > 0 {
+		return time.After(d)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/registry/memory"
+)
+
+func TestRegistration(t *testing.T) {
+	var r Registration
+
+	if d := r.Next(0, 0); d != 0 {
+		t.Fatalf("Expected no registration without an interval, got %v", d)
+	}
+	if d := r.Next(time.Minute, 0); d != time.Minute {
+		t.Fatalf("Expected the interval, got %v", d)
+	}
+
+	var reported []error
+	fn := func(err error) {
+		reported = append(reported, err)
+	}
+
+	r.Update(true, nil, fn)
+	if s := r.Status(); !s.Registered || s.Error != nil || s.Updated.IsZero() {
+		t.Fatalf("Expected the server to be registered, got %+v", s)
+	}
+
+	// the failures are retried with a jittered exponential backoff up to the
+	// interval
+	failed := errors.New("registry unavailable")
+	var last time.Duration
+	for i := 1; i <= 3; i++ {
+		r.Update(true, failed, fn)
+		d := r.Next(0, 0)
+		if d <= last {
+			t.Fatalf("Expected the backoff to grow, got %v after %v", d, last)
+		}
+		last = d
+	}
+	if d := r.Next(time.Second, 0); d < time.Second/2 || d > time.Second {
+		t.Fatalf("Expected the backoff to be limited to the interval, got %v", d)
+	}
+	jittered := false
+	for i := 0; i < 10 && !jittered; i++ {
+		jittered = r.Next(0, 0) != last
+	}
+	if !jittered {
+		t.Fatalf("Expected the backoff to be jittered, got %v every time", last)
+	}
+	if len(reported) != 3 || reported[0] != failed {
+		t.Fatalf("Expected 3 errors reported, got %v", reported)
+	}
+
+	// the server is still registered until deregistered
+	s := r.Status()
+	if !s.Registered || s.Error != failed || s.Failures != 3 {
+		t.Fatalf("Unexpected status %+v", s)
+	}
+
+	r.Update(false, nil, fn)
+	if s := r.Status(); s.Registered || s.Error != nil || s.Failures != 0 {
+		t.Fatalf("Expected the server to be deregistered, got %+v", s)
+	}
+}
+
+func TestRegistrationLimit(t *testing.T) {
+	var r Registration
+
+	reg := memory.NewRegistry()
+	if l := r.Limit(Options{Registry: reg}); l != reg {
+		t.Fatal("Expected the registry not to be wrapped without a limit")
+	}
+
+	opts := Options{Registry: reg, RegisterLimit: 1, RegisterBurst: 2}
+	l := r.Limit(opts)
+	if l == reg {
+		t.Fatal("Expected the registry to be wrapped")
+	}
+
+	// starting again reuses the wrapper rather than wrapping it again
+	opts.Registry = l
+	if again := r.Limit(opts); again != l {
+		t.Fatal("Expected the limited registry to be reused")
+	}
+}
+
+func TestRegistrationAddress(t *testing.T) {
+	var r Registration
+
+	o := Options{Address: "unix:///var/run/service.sock"}
+	if a := r.Address(o); a != o.Address {
+		t.Fatalf("Expected the address before listening, got %s", a)
+	}
+
+	// the unix domain sockets are skipped
+	r.Listening("unix:///var/run/service.sock", "10.0.0.1:8080", "127.0.0.1:8080")
+	if a := r.Address(o); a != "10.0.0.1:8080" {
+		t.Fatalf("Expected the first tcp address, got %s", a)
+	}
+
+	// unless there's no other
+	r.Listening("unix:///var/run/service.sock")
+	if a := r.Address(o); a != o.Address {
+		t.Fatalf("Expected the unix socket, got %s", a)
+	}
+
+	o.Advertise = "10.0.0.2:8080"
+	if a := r.Address(o); a != o.Advertise {
+		t.Fatalf("Expected the advertise address, got %s", a)
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/micro/go-micro/v2/codec/jsonrpc"
 	"github.com/micro/go-micro/v2/codec/proto"
 	"github.com/micro/go-micro/v2/codec/protorpc"
+	merrors "github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/transport"
+	"github.com/micro/go-micro/v2/util/compress"
 	"github.com/oxtoacart/bpool"
 	"github.com/pkg/errors"
 )
@@ -24,6 +26,10 @@ type rpcCodec struct {
 	req *transport.Message
 	buf *readWriteCloser
 
+	// max size of the bodies received and sent, zero is unlimited
+	maxRecv int
+	maxSend int
+
 	// check if we're the first
 	sync.RWMutex
 	first chan bool
@@ -164,7 +170,7 @@ func setupProtocol(msg *transport.Message) codec.NewCodec {
 	return nil
 }
 
-func newRpcCodec(req *transport.Message, socket transport.Socket, c codec.NewCodec) codec.Codec {
+func newRpcCodec(req *transport.Message, socket transport.Socket, c codec.NewCodec, maxRecv, maxSend int) codec.Codec {
 	rwc := &readWriteCloser{
 		rbuf: bufferPool.Get(),
 		wbuf: bufferPool.Get(),
@@ -177,6 +183,8 @@ func newRpcCodec(req *transport.Message, socket transport.Socket, c codec.NewCod
 		socket:   socket,
 		protocol: "mucp",
 		first:    make(chan bool),
+		maxRecv:  maxRecv,
+		maxSend:  maxSend,
 	}
 
 	// if grpc pre-load the buffer
@@ -212,6 +220,19 @@ func (c *rpcCodec) ReadHeader(r *codec.Message, t codec.MessageType) error {
 		if err := c.socket.Recv(&tm); err != nil {
 			return err
 		}
+
+		// decompress the body, up to the max size
+		if err := compress.Decode(&tm, c.maxRecv); err != nil {
+			if err == compress.ErrTooLarge {
+				return merrors.RequestEntityTooLarge(tm.Header["Micro-Service"], "request decompresses to more than the max size of %d bytes", c.maxRecv)
+			}
+			return err
+		}
+
+		// reject the messages over the max size
+		if c.maxRecv > 0 && len(tm.Body) > c.maxRecv {
+			return tooLarge(tm.Header["Micro-Service"], "request", len(tm.Body), c.maxRecv)
+		}
 		// reset the read buffer
 		c.buf.rbuf.Reset()
 
@@ -325,16 +346,31 @@ func (c *rpcCodec) Write(r *codec.Message, b interface{}) error {
 		body = c.buf.wbuf.Bytes()
 	}
 
+	// send an error instead of a body over the max size
+	if c.maxSend > 0 && len(body) > c.maxSend {
+		m.Error = tooLarge(c.req.Header["Micro-Service"], "response", len(body), c.maxSend).Error()
+		m.Header["Micro-Error"] = m.Error
+		body = nil
+	}
+
 	// Set content type if theres content
 	if len(body) > 0 {
 		m.Header["Content-Type"] = c.req.Header["Content-Type"]
 	}
 
-	// send on the socket
-	return c.socket.Send(&transport.Message{
+	msg := &transport.Message{
 		Header: m.Header,
 		Body:   body,
-	})
+	}
+
+	// compress the body if the client accepts it
+	enc, _ := compress.Negotiate(c.req.Header[compress.AcceptHeader])
+	if err := compress.Encode(msg, enc); err != nil {
+		return err
+	}
+
+	// send on the socket
+	return c.socket.Send(msg)
 }
 
 func (c *rpcCodec) Close() error {
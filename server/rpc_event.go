@@ -16,6 +16,14 @@ func (e *event) Ack() error {
 	return nil
 }
 
+func (e *event) Nack(requeue bool) error {
+	// there is no redelivery
+	if requeue {
+		return broker.UnsupportedDelivery("transport", broker.AtLeastOnce)
+	}
+	return nil
+}
+
 func (e *event) Message() *broker.Message {
 	return e.message
 }
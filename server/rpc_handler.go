@@ -24,18 +24,28 @@ func newRpcHandler(handler interface{}, opts ...HandlerOption) Handler {
 
 	typ := reflect.TypeOf(handler)
 	hdlr := reflect.ValueOf(handler)
-	name := reflect.Indirect(hdlr).Type().Name()
+	base := reflect.Indirect(hdlr).Type().Name()
+	name := VersionedName(base, options.Version)
 
 	var endpoints []*registry.Endpoint
 
 	for m := 0; m < typ.NumMethod(); m++ {
 		if e := extractEndpoint(typ.Method(m)); e != nil {
-			e.Name = name + "." + e.Name
+			method := e.Name
+			e.Name = name + "." + method
 
-			for k, v := range options.Metadata[e.Name] {
+			for k, v := range options.Metadata[base+"."+method] {
 				e.Metadata[k] = v
 			}
 
+			// versioned endpoints may have metadata of their own
+			if len(options.Version) > 0 {
+				for k, v := range options.Metadata[e.Name] {
+					e.Metadata[k] = v
+				}
+				e.Metadata["version"] = options.Version
+			}
+
 			endpoints = append(endpoints, e)
 		}
 	}
@@ -67,6 +67,11 @@ func (r *rpcRequest) Read() ([]byte, error) {
 		return b, nil
 	}
 
+	// a request which isn't a stream has the one body, read by the codec
+	if c, ok := r.codec.(*rpcCodec); ok && !r.stream && c.req != nil {
+		return c.req.Body, nil
+	}
+
 	var msg transport.Message
 	err := r.socket.Recv(&msg)
 	if err != nil {
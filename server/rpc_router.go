@@ -15,6 +15,7 @@ import (
 	"runtime/debug"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -44,6 +45,10 @@ type service struct {
 	rcvr   reflect.Value          // receiver of methods for the service
 	typ    reflect.Type           // type of the receiver
 	method map[string]*methodType // registered methods
+	// namespaces the service is visible to
+	namespaces []string
+	// timeout of the requests overriding the one of the router
+	timeout time.Duration
 }
 
 type request struct {
@@ -60,8 +65,10 @@ type response struct {
 type router struct {
 	name string
 
-	mu         sync.Mutex // protects the serviceMap
+	mu         sync.Mutex // protects the serviceMap and versions
 	serviceMap map[string]*service
+	// default versions of the handlers served only in versions
+	versions map[string]string
 
 	reqLock sync.Mutex // protects freeReq
 	freeReq *request
@@ -71,6 +78,23 @@ type router struct {
 
 	// handler wrappers
 	hdlrWrappers []HandlerWrapper
+	// namespace of the caller deciding the services it can see
+	namespace NamespaceFunc
+	// timeout of the requests to the handlers
+	timeout time.Duration
+	// rate limits of the callers of the handlers
+	limits *RateLimits
+	// metrics of the requests to the handlers
+	metrics *HandlerMetrics
+	// handles the panics of the handlers and subscribers
+	panics PanicFunc
+	// writes the audit records of the requests
+	auditor *Auditor
+	// validates the requests, none if nil
+	validate ValidateFunc
+	// interceptors of the requests and streams
+	unaryInterceptors  []UnaryInterceptor
+	streamInterceptors []StreamInterceptor
 	// subscriber wrappers
 	subWrappers []SubscriberWrapper
 
@@ -95,6 +119,7 @@ func (r rpcRouter) ServeRequest(ctx context.Context, req Request, rsp Response)
 func newRpcRouter() *router {
 	return &router{
 		serviceMap:  make(map[string]*service),
+		versions:    make(map[string]string),
 		subscribers: make(map[string][]*subscriber),
 	}
 }
@@ -231,11 +256,39 @@ func (s *service) call(ctx context.Context, router *router, sending *sync.Mutex,
 			return nil
 		}
 
+		// recover the panics of the handler as errors
+		fn = RecoveryWrapper(router.panics, router.metrics)(fn)
+
+		// cancel the handler running for too long
+		timeout := router.timeout
+		if s.timeout > 0 {
+			timeout = s.timeout
+		}
+		fn = TimeoutWrapper(timeout)(fn)
+
+		// reject the invalid requests
+		fn = ValidateWrapper(router.validate)(fn)
+
+		// reject the callers over their rate limits
+		fn = RateLimitWrapper(router.limits)(fn)
+
+		// hide the handler from callers in other namespaces
+		fn = NamespaceWrapper(s.name, s.namespaces, router.namespace)(fn)
+
+		// intercept the request
+		fn = InterceptWrapper(router.unaryInterceptors...)(fn)
+
 		// wrap the handler
 		for i := len(router.hdlrWrappers); i > 0; i-- {
 			fn = router.hdlrWrappers[i-1](fn)
 		}
 
+		// audit the requests, including the ones rejected by the wrappers
+		fn = AuditWrapper(router.auditor)(fn)
+
+		// report the requests, including the ones rejected by the wrappers
+		fn = MetricsWrapper(router.metrics)(fn)
+
 		// execute handler
 		if err := fn(ctx, r, replyv.Interface()); err != nil {
 			return err
@@ -269,11 +322,32 @@ func (s *service) call(ctx context.Context, router *router, sending *sync.Mutex,
 		}
 	}
 
+	// recover the panics of the handler as errors
+	fn = RecoveryWrapper(router.panics, router.metrics)(fn)
+
+	// reject the invalid messages received
+	fn = ValidateWrapper(router.validate)(fn)
+
+	// reject the callers over their rate limits
+	fn = RateLimitWrapper(router.limits)(fn)
+
+	// hide the handler from callers in other namespaces
+	fn = NamespaceWrapper(s.name, s.namespaces, router.namespace)(fn)
+
+	// intercept the stream
+	fn = InterceptStreamWrapper(router.streamInterceptors...)(fn)
+
 	// wrap the handler
 	for i := len(router.hdlrWrappers); i > 0; i-- {
 		fn = router.hdlrWrappers[i-1](fn)
 	}
 
+	// audit the streams, including the ones rejected by the wrappers
+	fn = AuditWrapper(router.auditor)(fn)
+
+	// report the streams, including the ones rejected by the wrappers
+	fn = MetricsWrapper(router.metrics)(fn)
+
 	// client.Stream request
 	r.stream = true
 
@@ -391,26 +465,45 @@ func (router *router) readHeader(cc codec.Reader) (service *service, mtype *meth
 	// we can still recover and move on to the next request.
 	keepReading = true
 
-	serviceMethod := strings.Split(req.msg.Endpoint, ".")
-	if len(serviceMethod) != 2 {
+	name, version, method, ok := splitEndpoint(req.msg.Endpoint)
+	if !ok {
 		err = errors.New("rpc: service/endpoint request ill-formed: " + req.msg.Endpoint)
 		return
 	}
+	// the endpoint takes precedence over the header
+	if len(version) == 0 {
+		version = req.msg.Header[VersionHeader]
+	}
 	// Look up the request.
 	router.mu.Lock()
-	service = router.serviceMap[serviceMethod[0]]
+	service = router.lookup(name, version)
 	router.mu.Unlock()
 	if service == nil {
-		err = errors.New("rpc: can't find service " + serviceMethod[0])
+		err = errors.New("rpc: can't find service " + VersionedName(name, version))
 		return
 	}
-	mtype = service.method[serviceMethod[1]]
+	mtype = service.method[method]
 	if mtype == nil {
-		err = errors.New("rpc: can't find method " + serviceMethod[1])
+		err = errors.New("rpc: can't find method " + method)
 	}
 	return
 }
 
+// lookup returns the service of the handler at the version. Without a version
+// it's the handler without one, otherwise its default version.
+func (router *router) lookup(name, version string) *service {
+	if len(version) > 0 {
+		return router.serviceMap[VersionedName(name, version)]
+	}
+	if s, ok := router.serviceMap[name]; ok {
+		return s
+	}
+	if v, ok := router.versions[name]; ok {
+		return router.serviceMap[VersionedName(name, v)]
+	}
+	return nil
+}
+
 func (router *router) NewHandler(h interface{}, opts ...HandlerOption) Handler {
 	return newRpcHandler(h, opts...)
 }
@@ -421,6 +514,9 @@ func (router *router) Handle(h Handler) error {
 	if router.serviceMap == nil {
 		router.serviceMap = make(map[string]*service)
 	}
+	if router.versions == nil {
+		router.versions = make(map[string]string)
+	}
 
 	if len(h.Name()) == 0 {
 		return errors.New("rpc.Handle: handler has no name")
@@ -441,6 +537,8 @@ func (router *router) Handle(h Handler) error {
 
 	s.name = h.Name()
 	s.method = make(map[string]*methodType)
+	s.namespaces = h.Options().Namespaces
+	s.timeout = h.Options().Timeout
 
 	// Install the methods
 	for m := 0; m < s.typ.NumMethod(); m++ {
@@ -457,6 +555,13 @@ func (router *router) Handle(h Handler) error {
 
 	// save handler
 	router.serviceMap[s.name] = s
+
+	// the first version is the default
+	if name, version := SplitVersion(s.name); len(version) > 0 {
+		if _, ok := router.versions[name]; !ok {
+			router.versions[name] = version
+		}
+	}
 	return nil
 }
 
@@ -581,6 +686,9 @@ func (router *router) ProcessMessage(ctx context.Context, msg Message) (err erro
 				return err
 			}
 
+			// recover the panics of the subscriber as errors
+			fn = RecoverySubscriberWrapper(router.panics, router.metrics)(fn)
+
 			// wrap with subscriber wrappers
 			for i := len(router.subWrappers); i > 0; i-- {
 				fn = router.subWrappers[i-1](fn)
@@ -15,12 +15,15 @@ import (
 	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/codec"
 	raw "github.com/micro/go-micro/v2/codec/bytes"
+	"github.com/micro/go-micro/v2/debug/health"
+	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/logger"
 	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/transport"
 	"github.com/micro/go-micro/v2/util/addr"
 	"github.com/micro/go-micro/v2/util/backoff"
+	"github.com/micro/go-micro/v2/util/compress"
 	mnet "github.com/micro/go-micro/v2/util/net"
 	"github.com/micro/go-micro/v2/util/socket"
 )
@@ -35,8 +38,16 @@ type rpcServer struct {
 	subscribers map[Subscriber][]broker.Subscriber
 	// marks the serve as started
 	started bool
+	// marks the server as stopping, new requests are rejected
+	draining bool
+	// limits the requests in flight
+	limiter Limiter
 	// used for first registration
 	registered bool
+	// status of the registration
+	registration Registration
+	// tracks the requests in flight
+	inflight Tracker
 	// subscribe to service name
 	subscriber broker.Subscriber
 	// graceful exit
@@ -50,6 +61,15 @@ func newRpcServer(opts ...Option) Server {
 	router := newRpcRouter()
 	router.hdlrWrappers = options.HdlrWrappers
 	router.subWrappers = options.SubWrappers
+	router.namespace = options.CallerNamespace
+	router.timeout = options.HandlerTimeout
+	router.limits = options.RateLimits
+	router.metrics = options.Metrics
+	router.panics = options.PanicHandler
+	router.auditor = options.Auditor
+	router.validate = options.Validate
+	router.unaryInterceptors = options.UnaryInterceptors
+	router.streamInterceptors = options.StreamInterceptors
 
 	return &rpcServer{
 		opts:        options,
@@ -57,16 +77,48 @@ func newRpcServer(opts ...Option) Server {
 		handlers:    make(map[string]Handler),
 		subscribers: make(map[Subscriber][]broker.Subscriber),
 		exit:        make(chan chan error),
-		wg:          wait(options.Context),
+		wg:          waitFor(options),
 	}
 }
 
 // HandleEvent handles inbound messages to the service directly
 // TODO: handle requests from an event. We won't send a response.
-func (s *rpcServer) HandleEvent(e broker.Event) error {
+func (s *rpcServer) HandleEvent(e broker.Event) (err error) {
+	// the server waits for the subscriber handlers on stop, the messages
+	// received once stopping are redelivered
+	s.RLock()
+	wg, draining := s.wg, s.draining
+	if wg != nil && !draining {
+		wg.Add(1)
+	}
+	s.RUnlock()
+	if draining {
+		return errors.ServiceUnavailable(s.opts.Name, "server is shutting down")
+	}
+	if wg != nil {
+		defer wg.Done()
+	}
+	s.inflight.Add()
+	defer s.inflight.Done()
+
 	// formatting horrible cruft
 	msg := e.Message()
 
+	// report the lag and the result of the message
+	done := s.opts.Metrics.Message(e.Topic(), msg.Header)
+	defer func() {
+		done(err)
+	}()
+
+	// drop the messages over the max size, they're acked as they'd never
+	// be handled if redelivered
+	if max := s.opts.MaxMessageSize; max > 0 && len(msg.Body) > max {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			log.Warnf("Dropping message on topic %s: %v", e.Topic(), tooLarge(s.opts.Name, "message", len(msg.Body), max))
+		}
+		return nil
+	}
+
 	if msg.Header == nil {
 		// create empty map in case of headers empty to avoid panic later
 		msg.Header = make(map[string]string)
@@ -110,7 +162,9 @@ func (s *rpcServer) HandleEvent(e broker.Event) error {
 	}
 
 	// existing router
+	s.RLock()
 	r := Router(s.router)
+	s.RUnlock()
 
 	// if the router is present then execute it
 	if s.opts.Router != nil {
@@ -129,6 +183,26 @@ func (s *rpcServer) HandleEvent(e broker.Event) error {
 	return r.ProcessMessage(ctx, rpcMsg)
 }
 
+// rejectTooLarge answers a message the transport didn't read as it's over
+// the max request size
+func (s *rpcServer) rejectTooLarge(sock transport.Socket, msg transport.Message) {
+	service := getHeader("Micro-Service", msg.Header)
+	if len(service) == 0 {
+		service = s.opts.Name
+	}
+	err := errors.RequestEntityTooLarge(service, "request exceeds the max size of %d bytes", s.opts.MaxRequestSize)
+
+	hdr := make(map[string]string, len(msg.Header))
+	for k, v := range msg.Header {
+		hdr[k] = v
+	}
+	delete(hdr, "Content-Type")
+	hdr["Micro-Error"] = err.Error()
+	hdr["X-Micro-Error"] = err.Error()
+
+	sock.Send(&transport.Message{Header: hdr})
+}
+
 // ServeConn serves a single connection
 func (s *rpcServer) ServeConn(sock transport.Socket) {
 	// streams are multiplexed on Micro-Stream or Micro-Id header
@@ -166,7 +240,11 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 	for {
 		var msg transport.Message
 		// process inbound messages one at a time
-		if err := sock.Recv(&msg); err != nil {
+		if err := sock.Recv(&msg); err == transport.ErrMessageTooLarge {
+			// the rest of the body is unread, answer and close the connection
+			s.rejectTooLarge(sock, msg)
+			return
+		} else if err != nil {
 			return
 		}
 
@@ -308,7 +386,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		}
 
 		// create a new rpc codec based on the pseudo socket and codec
-		rcodec := newRpcCodec(&msg, psock, cf)
+		rcodec := newRpcCodec(&msg, psock, cf, s.opts.MaxRequestSize, s.opts.MaxResponseSize)
 		// check the protocol as well
 		protocol := rcodec.String()
 
@@ -333,7 +411,9 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		}
 
 		// set router
+		s.RLock()
 		r := Router(s.router)
+		s.RUnlock()
 
 		// if not nil use the router specified
 		if s.opts.Router != nil {
@@ -351,9 +431,46 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			r = rpcRouter{h: handler}
 		}
 
-		// wait for two coroutines to exit
-		// serve the request and process the outbound messages
-		wg.Add(2)
+		// reject the requests over the max size, and new requests once
+		// stopping or overloaded, they're retried on another node
+		// the requests are only waited for on stop until draining
+		s.RLock()
+		draining := s.draining
+		if draining {
+			wg.lg.Add(2)
+		} else {
+			wg.Add(2)
+		}
+		s.RUnlock()
+		done := wg.Done
+		if draining {
+			done = wg.lg.Done
+		}
+
+		release := func() {}
+		var reject error
+		if max := s.opts.MaxRequestSize; max > 0 && len(msg.Body) > max {
+			reject = tooLarge(request.service, "request", len(msg.Body), max)
+		} else if draining {
+			reject = errors.ServiceUnavailable(request.service, "server is shutting down")
+		} else if rel, ok := s.limiter.Acquire(s.opts.MaxConcurrentRequests, s.opts.LoadShedder); ok {
+			s.inflight.Add()
+			release = func() {
+				rel()
+				s.inflight.Done()
+			}
+		} else {
+			reject = errors.ServiceUnavailable(request.service, "server is overloaded")
+		}
+
+		if reject != nil {
+			r = rpcRouter{h: func(ctx context.Context, req Request, rsp interface{}) error {
+				return reject
+			}}
+		}
+
+		// two coroutines serve the request and process the outbound
+		// messages, they were added to the wait group
 
 		// process the outbound messages from the socket
 		go func(id string, psock *socket.Socket) {
@@ -367,7 +484,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 				// release the socket
 				pool.Release(psock)
 				// signal we're done
-				wg.Done()
+				done()
 
 				// recover any panics for outbound process
 				if r := recover(); r != nil {
@@ -392,13 +509,21 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 			}
 		}(id, psock)
 
+		// the handlers which timed out hold the request until they return
+		pending := new(Pending)
+		ctx = pending.NewContext(ctx)
+
 		// serve the request in a go routine as this may be a stream
 		go func(id string, psock *socket.Socket) {
 			defer func() {
-				// release the socket
-				pool.Release(psock)
-				// signal we're done
-				wg.Done()
+				pending.After(func() {
+					// release the request
+					release()
+					// release the socket
+					pool.Release(psock)
+					// signal we're done
+					done()
+				})
 
 				// recover any panics for call handler
 				if r := recover(); r != nil {
@@ -453,12 +578,25 @@ func (s *rpcServer) Init(opts ...Option) error {
 	for _, opt := range opts {
 		opt(&s.opts)
 	}
+	// wait for the requests on stop if the drain timeout was set
+	if s.wg == nil {
+		s.wg = waitFor(s.opts)
+	}
 	// update router if its the default
 	if s.opts.Router == nil {
 		r := newRpcRouter()
 		r.hdlrWrappers = s.opts.HdlrWrappers
 		r.serviceMap = s.router.serviceMap
 		r.subWrappers = s.opts.SubWrappers
+		r.namespace = s.opts.CallerNamespace
+		r.timeout = s.opts.HandlerTimeout
+		r.limits = s.opts.RateLimits
+		r.metrics = s.opts.Metrics
+		r.panics = s.opts.PanicHandler
+		r.auditor = s.opts.Auditor
+		r.validate = s.opts.Validate
+		r.unaryInterceptors = s.opts.UnaryInterceptors
+		r.streamInterceptors = s.opts.StreamInterceptors
 		s.router = r
 	}
 
@@ -500,7 +638,81 @@ func (s *rpcServer) Subscribe(sb Subscriber) error {
 	return nil
 }
 
+// Endpoints returns the endpoints of the handlers and subscribers of the
+// server, except the internal ones, false if the server doesn't describe them
+func Endpoints(s Server) ([]*registry.Endpoint, bool) {
+	e, ok := s.(interface{ Endpoints() []*registry.Endpoint })
+	if !ok {
+		return nil, false
+	}
+	return e.Endpoints(), true
+}
+
+// Endpoints returns the endpoints of the handlers and subscribers of the
+// server, except the internal ones
+func (s *rpcServer) Endpoints() []*registry.Endpoint {
+	s.RLock()
+	defer s.RUnlock()
+
+	// Maps are ordered randomly, sort the keys for consistency
+	var handlerList []string
+	for n, e := range s.handlers {
+		// Only advertise non internal handlers
+		if !e.Options().Internal {
+			handlerList = append(handlerList, n)
+		}
+	}
+
+	sort.Strings(handlerList)
+
+	var subscriberList []Subscriber
+	for e := range s.subscribers {
+		// Only advertise non internal subscribers
+		if !e.Options().Internal {
+			subscriberList = append(subscriberList, e)
+		}
+	}
+
+	sort.Slice(subscriberList, func(i, j int) bool {
+		return subscriberList[i].Topic() > subscriberList[j].Topic()
+	})
+
+	endpoints := make([]*registry.Endpoint, 0, len(handlerList)+len(subscriberList))
+
+	for _, n := range handlerList {
+		endpoints = append(endpoints, s.handlers[n].Endpoints()...)
+	}
+
+	for _, e := range subscriberList {
+		endpoints = append(endpoints, e.Endpoints()...)
+	}
+
+	return endpoints
+}
+
+// Register the server, recording the status of the registration
 func (s *rpcServer) Register() error {
+	err := s.register()
+	s.registration.Update(true, err, s.Options().OnRegisterError)
+	return err
+}
+
+// RegisterStatus returns the status of the registration of the server
+func (s *rpcServer) RegisterStatus() RegisterStatus {
+	return s.registration.Status()
+}
+
+// Inflight returns the number of requests, and messages, in flight
+func (s *rpcServer) Inflight() int {
+	return s.inflight.Count()
+}
+
+// Wait blocks until the server is stopped with no requests in flight
+func (s *rpcServer) Wait(ctx context.Context) error {
+	return s.inflight.Wait(ctx)
+}
+
+func (s *rpcServer) register() error {
 	s.RLock()
 	rsvc := s.rsvc
 	config := s.Options()
@@ -544,16 +756,14 @@ func (s *rpcServer) Register() error {
 	var advt, host, port string
 	var cacheService bool
 
-	// check the advertise address first
-	// if it exists then use it, otherwise
-	// use the address
-	if len(config.Advertise) > 0 {
-		advt = config.Advertise
-	} else {
-		advt = config.Address
-	}
+	// the advertise address if set, otherwise the address listened on the
+	// other hosts can reach
+	advt = s.registration.Address(config)
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if strings.HasPrefix(advt, mnet.UnixScheme) {
+		// unix domain sockets are registered as is
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -592,41 +802,9 @@ func (s *rpcServer) Register() error {
 	node.Metadata["server"] = s.String()
 	node.Metadata["registry"] = config.Registry.String()
 	node.Metadata["protocol"] = "mucp"
+	node.Metadata[compress.MetadataKey] = compress.Supported()
 
-	s.RLock()
-
-	// Maps are ordered randomly, sort the keys for consistency
-	var handlerList []string
-	for n, e := range s.handlers {
-		// Only advertise non internal handlers
-		if !e.Options().Internal {
-			handlerList = append(handlerList, n)
-		}
-	}
-
-	sort.Strings(handlerList)
-
-	var subscriberList []Subscriber
-	for e := range s.subscribers {
-		// Only advertise non internal subscribers
-		if !e.Options().Internal {
-			subscriberList = append(subscriberList, e)
-		}
-	}
-
-	sort.Slice(subscriberList, func(i, j int) bool {
-		return subscriberList[i].Topic() > subscriberList[j].Topic()
-	})
-
-	endpoints := make([]*registry.Endpoint, 0, len(handlerList)+len(subscriberList))
-
-	for _, n := range handlerList {
-		endpoints = append(endpoints, s.handlers[n].Endpoints()...)
-	}
-
-	for _, e := range subscriberList {
-		endpoints = append(endpoints, e.Endpoints()...)
-	}
+	endpoints := s.Endpoints()
 
 	service := &registry.Service{
 		Name:      config.Name,
@@ -636,8 +814,8 @@ func (s *rpcServer) Register() error {
 	}
 
 	// get registered value
+	s.RLock()
 	registered := s.registered
-
 	s.RUnlock()
 
 	if !registered {
@@ -689,10 +867,27 @@ func (s *rpcServer) Register() error {
 			opts = append(opts, broker.DisableAutoAck())
 		}
 
-		sub, err := config.Broker.Subscribe(sb.Topic(), s.HandleEvent, opts...)
+		if d := sb.Options().Delivery; d != broker.DeliverDefault {
+			opts = append(opts, broker.DeliveryMode(d))
+		}
+
+		// handle the messages with workers if the concurrency is bounded
+		handler := broker.Handler(s.HandleEvent)
+		workers := NewSubscriberWorkers(handler, sb, s.opts, s.wg)
+		if workers != nil {
+			handler = workers.Handle
+		}
+
+		sub, err := config.Broker.Subscribe(sb.Topic(), handler, opts...)
 		if err != nil {
+			if workers != nil {
+				workers.Stop()
+			}
 			return err
 		}
+		if workers != nil {
+			sub = workers.Subscriber(sub)
+		}
 		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
 			log.Infof("Subscribing to topic: %s", sub.Topic())
 		}
@@ -706,7 +901,14 @@ func (s *rpcServer) Register() error {
 	return nil
 }
 
+// Deregister the server, recording the status of the registration
 func (s *rpcServer) Deregister() error {
+	err := s.deregister()
+	s.registration.Update(false, err, s.Options().OnRegisterError)
+	return err
+}
+
+func (s *rpcServer) deregister() error {
 	var err error
 	var advt, host, port string
 
@@ -714,16 +916,14 @@ func (s *rpcServer) Deregister() error {
 	config := s.Options()
 	s.RUnlock()
 
-	// check the advertise address first
-	// if it exists then use it, otherwise
-	// use the address
-	if len(config.Advertise) > 0 {
-		advt = config.Advertise
-	} else {
-		advt = config.Address
-	}
+	// the advertise address if set, otherwise the address listened on the
+	// other hosts can reach
+	advt = s.registration.Address(config)
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
+	if strings.HasPrefix(advt, mnet.UnixScheme) {
+		// unix domain sockets are registered as is
+		host = advt
+	} else if cnt := strings.Count(advt, ":"); cnt >= 1 {
 		// ipv6 address in format [host]:port or ipv4 host:port
 		host, port, err = net.SplitHostPort(advt)
 		if err != nil {
@@ -770,7 +970,14 @@ func (s *rpcServer) Deregister() error {
 	}
 
 	s.registered = false
+	s.unsubscribe()
 
+	s.Unlock()
+	return nil
+}
+
+// unsubscribe closes the broker subscriptions, the lock must be held
+func (s *rpcServer) unsubscribe() {
 	// close the subscriber
 	if s.subscriber != nil {
 		s.subscriber.Unsubscribe()
@@ -780,15 +987,12 @@ func (s *rpcServer) Deregister() error {
 	for sb, subs := range s.subscribers {
 		for _, sub := range subs {
 			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-				log.Infof("Unsubscribing %s from topic: %s", node.Id, sub.Topic())
+				log.Infof("Unsubscribing %s-%s from topic: %s", s.opts.Name, s.opts.Id, sub.Topic())
 			}
 			sub.Unsubscribe()
 		}
 		s.subscribers[sb] = nil
 	}
-
-	s.Unlock()
-	return nil
 }
 
 func (s *rpcServer) Start() error {
@@ -799,10 +1003,15 @@ func (s *rpcServer) Start() error {
 	}
 	s.RUnlock()
 
+	// limit the rate of registry writes
+	s.Lock()
+	s.opts.Registry = s.registration.Limit(s.opts)
+	s.Unlock()
+
 	config := s.Options()
 
 	// start listening on the transport
-	ts, err := config.Transport.Listen(config.Address)
+	ts, err := config.Transport.Listen(config.Address, transport.MaxMessageSize(config.MaxRequestSize))
 	if err != nil {
 		return err
 	}
@@ -811,12 +1020,35 @@ func (s *rpcServer) Start() error {
 		log.Infof("Transport [%s] Listening on %s", config.Transport.String(), ts.Addr())
 	}
 
+	// listen on the other addresses, the address is the one registered
+	listeners := []transport.Listener{ts}
+	for _, a := range config.Listeners {
+		l, err := config.Transport.Listen(a, transport.MaxMessageSize(config.MaxRequestSize))
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return err
+		}
+
+		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+			log.Infof("Transport [%s] Listening on %s", config.Transport.String(), l.Addr())
+		}
+		listeners = append(listeners, l)
+	}
+
 	// swap address
 	s.Lock()
 	addr := s.opts.Address
 	s.opts.Address = ts.Addr()
 	s.Unlock()
 
+	addrs := make([]string, 0, len(listeners))
+	for _, l := range listeners {
+		addrs = append(addrs, l.Addr())
+	}
+	s.registration.Listening(addrs...)
+
 	bname := config.Broker.String()
 
 	// connect to the broker
@@ -847,42 +1079,40 @@ func (s *rpcServer) Start() error {
 
 	exit := make(chan bool)
 
-	go func() {
-		for {
-			// listen for connections
-			err := ts.Accept(s.ServeConn)
+	for _, l := range listeners {
+		go func(ts transport.Listener) {
+			for {
+				// listen for connections
+				err := ts.Accept(s.ServeConn)
 
-			// TODO: listen for messages
-			// msg := broker.Exchange(service).Consume()
+				// TODO: listen for messages
+				// msg := broker.Exchange(service).Consume()
 
-			select {
-			// check if we're supposed to exit
-			case <-exit:
-				return
-			// check the error and backoff
-			default:
-				if err != nil {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						log.Errorf("Accept error: %v", err)
+				select {
+				// check if we're supposed to exit
+				case <-exit:
+					return
+				// check the error and backoff
+				default:
+					if err != nil {
+						if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+							log.Errorf("Accept error: %v", err)
+						}
+						time.Sleep(time.Second)
+						continue
 					}
-					time.Sleep(time.Second)
-					continue
 				}
-			}
 
-			// no error just exit
-			return
-		}
-	}()
+				// no error just exit
+				return
+			}
+		}(l)
+	}
 
 	go func() {
-		t := new(time.Ticker)
-
-		// only process if it exists
-		if s.opts.RegisterInterval > time.Duration(0) {
-			// new ticker
-			t = time.NewTicker(s.opts.RegisterInterval)
-		}
+		// register self on the jittered interval to spread registry
+		// writes, or retry the failures with a backoff
+		t := s.registration.After(s.opts.RegisterInterval, s.opts.RegisterJitter)
 
 		// return error chan
 		var ch chan error
@@ -891,7 +1121,7 @@ func (s *rpcServer) Start() error {
 		for {
 			select {
 			// register self on interval
-			case <-t.C:
+			case <-t:
 				s.RLock()
 				registered := s.registered
 				s.RUnlock()
@@ -910,6 +1140,7 @@ func (s *rpcServer) Start() error {
 					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 						log.Errorf("Server %s-%s register check error: %s", config.Name, config.Id, rerr)
 					}
+					t = s.registration.After(s.opts.RegisterInterval, s.opts.RegisterJitter)
 					continue
 				}
 				if err := s.Register(); err != nil {
@@ -917,9 +1148,9 @@ func (s *rpcServer) Start() error {
 						log.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
 					}
 				}
+				t = s.registration.After(s.opts.RegisterInterval, s.opts.RegisterJitter)
 			// wait for exit
 			case ch = <-s.exit:
-				t.Stop()
 				close(exit)
 				break Loop
 			}
@@ -937,17 +1168,53 @@ func (s *rpcServer) Start() error {
 			}
 		}
 
+		// health checks fail while draining
+		config.Health.Shutdown()
+
+		// stop accepting connections only once deregistered so clients don't
+		// select a node which is refusing connections. Requests in flight on
+		// existing connections are still served, new ones are rejected. No
+		// message is received once unsubscribed, even if deregister failed.
+		s.Lock()
+		s.unsubscribe()
+		s.draining = true
+		s.Unlock()
+
+		// the listeners draining keep serving the existing connections, the
+		// others are closed
+		for _, l := range listeners {
+			var err error
+			if d, ok := l.(interface{ Drain() error }); ok {
+				err = d.Drain()
+			} else {
+				err = l.Close()
+			}
+			if err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					log.Errorf("Server %s-%s drain error: %s", config.Name, config.Id, err)
+				}
+			}
+		}
+
 		s.Lock()
 		swg := s.wg
 		s.Unlock()
 
 		// wait for requests to finish
-		if swg != nil {
-			swg.Wait()
+		if swg != nil && !Drain(swg, s.opts.DrainTimeout) {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				log.Warnf("Server %s-%s drain timeout after %v, closing with requests in flight", config.Name, config.Id, s.opts.DrainTimeout)
+			}
 		}
 
-		// close transport listener
-		ch <- ts.Close()
+		// close transport listeners
+		var cerr error
+		for _, l := range listeners {
+			if err := l.Close(); err != nil && cerr == nil {
+				cerr = err
+			}
+		}
+		ch <- cerr
 
 		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
 			log.Infof("Broker [%s] Disconnected from %s", bname, config.Broker.Address())
@@ -965,10 +1232,15 @@ func (s *rpcServer) Start() error {
 		s.Unlock()
 	}()
 
+	// the server is serving once started
+	config.Health.Resume()
+	config.Health.Set(config.Name, health.StatusServing)
+
 	// mark the server as started
 	s.Lock()
 	s.started = true
 	s.Unlock()
+	s.inflight.Start()
 
 	return nil
 }
@@ -987,7 +1259,9 @@ func (s *rpcServer) Stop() error {
 	err := <-ch
 	s.Lock()
 	s.started = false
+	s.draining = false
 	s.Unlock()
+	s.inflight.Stop()
 
 	return err
 }
@@ -0,0 +1,955 @@
+package server_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/debug/metrics"
+	mmemory "github.com/micro/go-micro/v2/debug/metrics/memory"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/registry"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/server"
+	"github.com/micro/go-micro/v2/transport"
+	tmemory "github.com/micro/go-micro/v2/transport/memory"
+)
+
+type DrainRequest struct{}
+
+type DrainResponse struct {
+	Done bool
+}
+
+type EchoMessage struct {
+	Value  string
+	Repeat int
+}
+
+type Echo struct{}
+
+func (e *Echo) Call(ctx context.Context, req *EchoMessage, rsp *EchoMessage) error {
+	rsp.Value = strings.Repeat(req.Value, req.Repeat)
+	return nil
+}
+
+type Greeter struct {
+	version string
+}
+
+func (g *Greeter) Hello(ctx context.Context, req *EchoMessage, rsp *EchoMessage) error {
+	rsp.Value = g.version + " " + req.Value
+	return nil
+}
+
+type Drain struct {
+	started chan bool
+	release chan bool
+}
+
+func (d *Drain) Wait(ctx context.Context, req *DrainRequest, rsp *DrainResponse) error {
+	d.started <- true
+	<-d.release
+	rsp.Done = true
+	return nil
+}
+
+func TestDrainTimeout(t *testing.T) {
+	testData := []struct {
+		name    string
+		timeout time.Duration
+		release bool
+	}{
+		// the requests in flight finish before the server stops
+		{"Drained", time.Minute, true},
+		// the server stops on timeout with requests in flight
+		{"Timeout", 100 * time.Millisecond, false},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			reg := rmemory.NewRegistry()
+			brk := bmemory.NewBroker(broker.Registry(reg))
+			tr := tmemory.NewTransport()
+
+			srv := server.NewServer(
+				server.Broker(brk),
+				server.Registry(reg),
+				server.Name("go.micro.service.drain"),
+				server.Address("127.0.0.1:0"),
+				server.Transport(tr),
+				server.DrainTimeout(d.timeout),
+			)
+			h := &Drain{started: make(chan bool), release: make(chan bool)}
+			if err := srv.Handle(srv.NewHandler(h)); err != nil {
+				t.Fatal(err)
+			}
+			if err := srv.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			cli := client.NewClient(
+				client.Router(router.NewRouter(router.Registry(reg))),
+				client.Broker(brk),
+				client.Transport(tr),
+				client.ContentType("application/json"),
+				client.RequestTimeout(time.Minute),
+			)
+
+			called := make(chan error, 1)
+			go func() {
+				req := cli.NewRequest("go.micro.service.drain", "Drain.Wait", &DrainRequest{})
+				rsp := &DrainResponse{}
+				err := cli.Call(context.TODO(), req, rsp, client.WithNetwork(registry.DefaultDomain))
+				if err == nil && !rsp.Done {
+					t.Error("Expected the request to be done")
+				}
+				called <- err
+			}()
+			<-h.started
+
+			stopped := make(chan error, 1)
+			go func() {
+				stopped <- srv.Stop()
+			}()
+
+			// the server is deregistered first
+			time.Sleep(50 * time.Millisecond)
+			if svcs, _ := reg.GetService("go.micro.service.drain"); len(svcs) > 0 {
+				t.Fatal("Expected the server to be deregistered while draining")
+			}
+
+			if d.release {
+				select {
+				case <-stopped:
+					t.Fatal("Expected the server to wait for the request in flight")
+				default:
+				}
+				close(h.release)
+
+				if err := <-called; err != nil {
+					t.Fatalf("Expected the request in flight to succeed, got %v", err)
+				}
+			}
+
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the server to stop")
+			}
+
+			if !d.release {
+				close(h.release)
+			}
+		})
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.limit"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.MaxConcurrentRequests(1),
+	)
+	h := &Drain{started: make(chan bool), release: make(chan bool)}
+	if err := srv.Handle(srv.NewHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+	call := func() error {
+		req := cli.NewRequest("go.micro.service.limit", "Drain.Wait", &DrainRequest{})
+		return cli.Call(context.TODO(), req, &DrainResponse{}, client.WithNetwork(registry.DefaultDomain))
+	}
+
+	called := make(chan error, 1)
+	go func() {
+		called <- call()
+	}()
+	<-h.started
+
+	// the request over the limit is rejected
+	if err := call(); errors.FromError(err).Code != 503 {
+		t.Fatalf("Expected an unavailable error, got %v", err)
+	}
+
+	h.release <- true
+	if err := <-called; err != nil {
+		t.Fatal(err)
+	}
+
+	// the request is admitted once the other is done
+	go func() {
+		<-h.started
+		h.release <- true
+	}()
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.timeout"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.HandlerTimeout(time.Minute),
+	)
+	h := &Drain{started: make(chan bool, 1), release: make(chan bool)}
+	defer close(h.release)
+
+	// the timeout of the handler overrides the one of the server
+	if err := srv.Handle(srv.NewHandler(h, server.Timeout(50*time.Millisecond))); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	req := cli.NewRequest("go.micro.service.timeout", "Drain.Wait", &DrainRequest{})
+	err := cli.Call(context.TODO(), req, &DrainResponse{}, client.WithNetwork(registry.DefaultDomain))
+	if errors.FromError(err).Code != 408 {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.size"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.MaxRequestSize(100),
+		server.MaxResponseSize(100),
+		server.MaxMessageSize(100),
+	)
+	if err := srv.Handle(srv.NewHandler(&Echo{})); err != nil {
+		t.Fatal(err)
+	}
+
+	var handled int
+	sub := srv.NewSubscriber("go.micro.topic.size", func(ctx context.Context, msg *EchoMessage) error {
+		handled++
+		return nil
+	})
+	if err := srv.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	call := func(value string, repeat int) error {
+		req := cli.NewRequest("go.micro.service.size", "Echo.Call", &EchoMessage{Value: value, Repeat: repeat})
+		return cli.Call(context.TODO(), req, &EchoMessage{}, client.WithNetwork(registry.DefaultDomain))
+	}
+
+	if err := call("a", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(strings.Repeat("a", 200), 0); errors.FromError(err).Code != 413 {
+		t.Fatalf("Expected a request too large error, got %v", err)
+	}
+	if err := call("a", 200); errors.FromError(err).Code != 413 {
+		t.Fatalf("Expected a response too large error, got %v", err)
+	}
+
+	publish := func(value string) error {
+		return brk.Publish("go.micro.topic.size", &broker.Message{
+			Header: map[string]string{
+				"Content-Type": "application/json",
+				"Micro-Topic":  "go.micro.topic.size",
+			},
+			Body: []byte(`{"Value":"` + value + `"}`),
+		})
+	}
+
+	if err := publish("a"); err != nil {
+		t.Fatal(err)
+	}
+	// the messages over the max size are dropped rather than redelivered
+	if err := publish(strings.Repeat("a", 200)); err != nil {
+		t.Fatalf("Expected the message too large to be dropped, got %v", err)
+	}
+	if handled != 1 {
+		t.Fatalf("Expected the message under the max size handled, got %d handled", handled)
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	srv := server.NewServer(
+		server.Name("go.micro.service.endpoints"),
+		server.Registry(rmemory.NewRegistry()),
+	)
+	if err := srv.Handle(srv.NewHandler(&Echo{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Handle(srv.NewHandler(&Drain{}, server.InternalHandler(true))); err != nil {
+		t.Fatal(err)
+	}
+	sub := srv.NewSubscriber("go.micro.topic.endpoints", func(ctx context.Context, msg *EchoMessage) error {
+		return nil
+	})
+	if err := srv.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints, ok := server.Endpoints(srv)
+	if !ok {
+		t.Fatal("Expected the server to describe its endpoints")
+	}
+
+	// the internal handlers aren't described
+	if len(endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(endpoints))
+	}
+	if e := endpoints[0]; e.Name != "Echo.Call" || e.Request == nil || e.Request.Type != "EchoMessage" || e.Response == nil {
+		t.Fatalf("Unexpected handler endpoint %+v", e)
+	}
+	if e := endpoints[1]; e.Metadata["subscriber"] != "true" || e.Metadata["topic"] != "go.micro.topic.endpoints" {
+		t.Fatalf("Unexpected subscriber endpoint %+v", e)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+	rep := mmemory.NewReporter()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.metrics"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.Metrics(rep),
+		server.RateLimit(0.001, 1),
+	)
+	if err := srv.Handle(srv.NewHandler(&Echo{})); err != nil {
+		t.Fatal(err)
+	}
+
+	handled := make(chan bool, 1)
+	sub := srv.NewSubscriber("go.micro.topic.metrics", func(ctx context.Context, msg *EchoMessage) error {
+		handled <- true
+		return nil
+	})
+	if err := srv.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+		// the publisher only timestamps the messages when reporting metrics
+		client.Metrics(mmemory.NewReporter()),
+	)
+
+	// the second request is rejected by the rate limit
+	for i := 0; i < 2; i++ {
+		req := cli.NewRequest("go.micro.service.metrics", "Echo.Call", &EchoMessage{Value: "a", Repeat: 1})
+		cli.Call(context.TODO(), req, &EchoMessage{}, client.WithNetwork(registry.DefaultDomain))
+	}
+
+	if err := cli.Publish(context.TODO(), cli.NewMessage("go.micro.topic.metrics", &EchoMessage{Value: "a"})); err != nil {
+		t.Fatal(err)
+	}
+	<-handled
+
+	list, err := rep.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]*metrics.Metric)
+	for _, m := range list {
+		found[m.Name+"/"+m.Tags["code"]] = m
+	}
+
+	if m := found[server.MetricRequests+"/200"]; m == nil || m.Value != 1 || m.Tags["endpoint"] != "Echo.Call" {
+		t.Fatalf("Expected a successful request, got %+v", m)
+	}
+	if m := found[server.MetricRequests+"/429"]; m == nil || m.Value != 1 {
+		t.Fatalf("Expected a rejected request, got %+v", m)
+	}
+	if m := found[server.MetricLatency+"/"]; m == nil || m.Count != 2 {
+		t.Fatalf("Expected the latency of 2 requests, got %+v", m)
+	}
+	if m := found[server.MetricInFlight+"/"]; m == nil || m.Value != 0 {
+		t.Fatalf("Expected no requests in flight, got %+v", m)
+	}
+	if m := found[server.MetricLag+"/"]; m == nil || m.Count != 1 || m.Tags["topic"] != "go.micro.topic.metrics" {
+		t.Fatalf("Expected the lag of the message, got %+v", m)
+	}
+
+	// the result of the message is reported once handled
+	deadline := time.Now().Add(time.Second)
+	for {
+		list, _ = rep.Read()
+		var messages *metrics.Metric
+		for _, m := range list {
+			if m.Name == server.MetricMessages && m.Tags["code"] == "200" {
+				messages = m
+			}
+		}
+		if messages != nil && messages.Value == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected a message handled, got %+v", messages)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestListeners(t *testing.T) {
+	dir, err := ioutil.TempDir("", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := transport.NewTransport()
+	sock := "unix://" + filepath.Join(dir, "service.sock")
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.listeners"),
+		server.Address("127.0.0.1:0"),
+		server.Listen(sock),
+		server.Transport(tr),
+	)
+	if err := srv.Handle(srv.NewHandler(&Echo{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	// the service is served on the address registered and the unix socket
+	for _, addr := range []string{srv.Options().Address, sock} {
+		req := cli.NewRequest("go.micro.service.listeners", "Echo.Call", &EchoMessage{Value: "a", Repeat: 2})
+		rsp := &EchoMessage{}
+		if err := cli.Call(context.TODO(), req, rsp, client.WithAddress(addr)); err != nil {
+			t.Fatalf("Expected the service to be served on %s, got %v", addr, err)
+		}
+		if rsp.Value != "aa" {
+			t.Fatalf("Expected aa, got %s", rsp.Value)
+		}
+	}
+}
+
+type Panicker struct{}
+
+func (p *Panicker) Call(ctx context.Context, req *EchoMessage, rsp *EchoMessage) error {
+	panic(req.Value)
+}
+
+func TestPanicHandler(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	panics := make(chan *server.Panic, 1)
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.panic"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.PanicHandler(func(ctx context.Context, p *server.Panic) {
+			panics <- p
+		}),
+	)
+	if err := srv.Handle(srv.NewHandler(&Panicker{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	// the caller gets an error with the id of the panic rather than a timeout
+	req := cli.NewRequest("go.micro.service.panic", "Panicker.Call", &EchoMessage{Value: "boom"})
+	err := cli.Call(context.TODO(), req, &EchoMessage{}, client.WithNetwork(registry.DefaultDomain), client.WithRequestTimeout(5*time.Second))
+	if errors.FromError(err).Code != 500 {
+		t.Fatalf("Expected an internal server error, got %v", err)
+	}
+
+	p := <-panics
+	if p.Value != "boom" || p.Endpoint != "Panicker.Call" {
+		t.Fatalf("Unexpected panic %+v", p)
+	}
+	if !strings.Contains(errors.FromError(err).Detail, p.Id) {
+		t.Fatalf("Expected the id %s of the panic in the error, got %v", p.Id, err)
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	var calls []string
+	intercept := func(name string) server.UnaryInterceptor {
+		return func(ctx context.Context, req server.Request, rsp interface{}, handler server.HandlerFunc) error {
+			calls = append(calls, name)
+			return handler(ctx, req, rsp)
+		}
+	}
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.intercept"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.WrapHandler(func(fn server.HandlerFunc) server.HandlerFunc {
+			return func(ctx context.Context, req server.Request, rsp interface{}) error {
+				calls = append(calls, "wrapper")
+				return fn(ctx, req, rsp)
+			}
+		}),
+		server.Intercept(intercept("first"), intercept("second")),
+		// the cached requests are short circuited
+		server.Intercept(func(ctx context.Context, req server.Request, rsp interface{}, handler server.HandlerFunc) error {
+			if req.Body().(*EchoMessage).Value == "cached" {
+				rsp.(*EchoMessage).Value = "from cache"
+				return nil
+			}
+			return handler(ctx, req, rsp)
+		}),
+	)
+	if err := srv.Handle(srv.NewHandler(&Echo{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	call := func(value string) string {
+		req := cli.NewRequest("go.micro.service.intercept", "Echo.Call", &EchoMessage{Value: value, Repeat: 2})
+		rsp := &EchoMessage{}
+		if err := cli.Call(context.TODO(), req, rsp, client.WithNetwork(registry.DefaultDomain)); err != nil {
+			t.Fatal(err)
+		}
+		return rsp.Value
+	}
+
+	// the interceptors are called in order within the wrappers
+	if v := call("a"); v != "aa" {
+		t.Fatalf("Expected aa, got %s", v)
+	}
+	if got := strings.Join(calls, ", "); got != "wrapper, first, second" {
+		t.Fatalf("Unexpected calls: %s", got)
+	}
+
+	if v := call("cached"); v != "from cache" {
+		t.Fatalf("Expected the request to be short circuited, got %s", v)
+	}
+}
+
+func TestHandlerVersions(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.versions"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+	)
+	if err := srv.Handle(srv.NewHandler(&Greeter{version: "v1"}, server.HandlerVersion("v1"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Handle(srv.NewHandler(&Greeter{version: "v2"}, server.HandlerVersion("2.0"))); err != nil {
+		t.Fatal(err)
+	}
+	// the same version can't be handled twice
+	if err := srv.Handle(srv.NewHandler(&Greeter{}, server.HandlerVersion("v1"))); err == nil {
+		t.Fatal("Expected an error handling a version twice")
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	endpoints, _ := server.Endpoints(srv)
+	if len(endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(endpoints))
+	}
+	if e := endpoints[0]; e.Name != "Greeter@2.0.Hello" || e.Metadata["version"] != "2.0" {
+		t.Fatalf("Unexpected endpoint %+v", e)
+	}
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	call := func(ctx context.Context, endpoint string) (string, error) {
+		req := cli.NewRequest("go.micro.service.versions", endpoint, &EchoMessage{Value: "hello"})
+		rsp := &EchoMessage{}
+		err := cli.Call(ctx, req, rsp, client.WithNetwork(registry.DefaultDomain))
+		return rsp.Value, err
+	}
+
+	testData := []struct {
+		ctx      context.Context
+		endpoint string
+		expect   string
+	}{
+		// the first version is the default
+		{context.TODO(), "Greeter.Hello", "v1 hello"},
+		{context.TODO(), "Greeter@2.0.Hello", "v2 hello"},
+		{metadata.Set(context.TODO(), server.VersionHeader, "2.0"), "Greeter.Hello", "v2 hello"},
+		// the endpoint takes precedence over the header
+		{metadata.Set(context.TODO(), server.VersionHeader, "2.0"), "Greeter@v1.Hello", "v1 hello"},
+	}
+
+	for _, d := range testData {
+		v, err := call(d.ctx, d.endpoint)
+		if err != nil {
+			t.Fatalf("Unexpected error calling %s: %v", d.endpoint, err)
+		}
+		if v != d.expect {
+			t.Fatalf("Expected %s calling %s, got %s", d.expect, d.endpoint, v)
+		}
+	}
+
+	if _, err := call(context.TODO(), "Greeter@v3.Hello"); err == nil {
+		t.Fatal("Expected an error calling an unknown version")
+	}
+}
+
+func TestWait(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.wait"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.DrainTimeout(100*time.Millisecond),
+	)
+	h := &Drain{started: make(chan bool), release: make(chan bool)}
+	if err := srv.Handle(srv.NewHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.RequestTimeout(time.Minute),
+		client.Retries(0),
+	)
+
+	go func() {
+		req := cli.NewRequest("go.micro.service.wait", "Drain.Wait", &DrainRequest{})
+		cli.Call(context.TODO(), req, &DrainResponse{}, client.WithNetwork(registry.DefaultDomain))
+	}()
+	<-h.started
+
+	if n, ok := server.Inflight(srv); !ok || n != 1 {
+		t.Fatalf("Expected 1 request in flight, got %d", n)
+	}
+
+	// the server stops on the drain timeout with the request in flight
+	if err := srv.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.WaitFor(ctx, srv); err != context.DeadlineExceeded {
+		t.Fatalf("Expected to wait for the request in flight, got %v", err)
+	}
+
+	close(h.release)
+
+	ctx, cancel = context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	if err := server.WaitFor(ctx, srv); err != nil {
+		t.Fatalf("Expected the server to be drained, got %v", err)
+	}
+	if n, _ := server.Inflight(srv); n != 0 {
+		t.Fatalf("Expected no requests in flight, got %d", n)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.validate"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.Validate(func(msg interface{}) error {
+			if m, ok := msg.(*EchoMessage); ok && m.Repeat <= 0 {
+				return server.ValidationError{server.NewFieldError("Repeat", "must be over 0, got %d", m.Repeat)}
+			}
+			return nil
+		}),
+	)
+	if err := srv.Handle(srv.NewHandler(&Echo{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	call := func(repeat int) error {
+		req := cli.NewRequest("go.micro.service.validate", "Echo.Call", &EchoMessage{Value: "a", Repeat: repeat})
+		return cli.Call(context.TODO(), req, &EchoMessage{}, client.WithNetwork(registry.DefaultDomain))
+	}
+
+	if err := call(1); err != nil {
+		t.Fatalf("Expected the request to be valid, got %v", err)
+	}
+
+	merr := errors.FromError(call(0))
+	if merr.Code != 400 || merr.Detail != "invalid Echo.Call request: Repeat: must be over 0, got 0" {
+		t.Fatalf("Expected a bad request, got %v", merr)
+	}
+}
+
+func TestMaxSizeTransport(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := transport.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.size"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.MaxRequestSize(100),
+	)
+	if err := srv.Handle(srv.NewHandler(&Echo{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+
+	call := func(value string) error {
+		req := cli.NewRequest("go.micro.service.size", "Echo.Call", &EchoMessage{Value: value, Repeat: 1})
+		return cli.Call(context.TODO(), req, &EchoMessage{}, client.WithNetwork(registry.DefaultDomain))
+	}
+
+	if err := call("a"); err != nil {
+		t.Fatal(err)
+	}
+	// the transport stops reading the request over the max size
+	if err := call(strings.Repeat("a", 200)); errors.FromError(err).Code != 413 {
+		t.Fatalf("Expected a request too large error, got %v", err)
+	}
+}
+
+// failingRegistry fails to deregister services
+type failingRegistry struct {
+	registry.Registry
+}
+
+func (r *failingRegistry) Deregister(*registry.Service, ...registry.DeregisterOption) error {
+	return errors.InternalServerError("go.micro.registry", "unavailable")
+}
+
+func TestDrainUnsubscribe(t *testing.T) {
+	reg := &failingRegistry{rmemory.NewRegistry()}
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.drain"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+		server.DrainTimeout(time.Minute),
+	)
+	h := &Drain{started: make(chan bool), release: make(chan bool)}
+	if err := srv.Handle(srv.NewHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+
+	handled := make(chan bool, 1)
+	sub := srv.NewSubscriber("go.micro.topic.drain", func(ctx context.Context, msg *EchoMessage) error {
+		handled <- true
+		return nil
+	})
+	if err := srv.Subscribe(sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(tr),
+		client.ContentType("application/json"),
+		client.RequestTimeout(time.Minute),
+	)
+
+	called := make(chan error, 1)
+	go func() {
+		req := cli.NewRequest("go.micro.service.drain", "Drain.Wait", &DrainRequest{})
+		called <- cli.Call(context.TODO(), req, &DrainResponse{}, client.WithNetwork(registry.DefaultDomain))
+	}()
+	<-h.started
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- srv.Stop()
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// the subscriptions are closed while draining, though deregister failed
+	if err := cli.Publish(context.TODO(), cli.NewMessage("go.micro.topic.drain", &EchoMessage{Value: "a"})); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-handled:
+		t.Fatal("Expected no message to be handled while draining")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(h.release)
+	if err := <-called; err != nil {
+		t.Fatalf("Expected the request in flight to succeed, got %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the server to stop")
+	}
+}
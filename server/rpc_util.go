@@ -2,6 +2,9 @@ package server
 
 import (
 	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/errors"
 )
 
 // waitgroup for global management of connections
@@ -30,3 +33,42 @@ func (w *waitGroup) Wait() {
 	// only wait on local group
 	w.lg.Wait()
 }
+
+// waitFor returns the wait group of the requests in flight the server waits
+// for on stop, set with the Wait option or created for the drain timeout
+func waitFor(opts Options) *sync.WaitGroup {
+	if wg := wait(opts.Context); wg != nil {
+		return wg
+	}
+	if opts.DrainTimeout > 0 {
+		return new(sync.WaitGroup)
+	}
+	return nil
+}
+
+// Drain waits for the wait group up to the timeout, or forever if zero,
+// returning false on timeout
+func Drain(wg *sync.WaitGroup, timeout time.Duration) bool {
+	if timeout <= 0 {
+		wg.Wait()
+		return true
+	}
+
+	done := make(chan bool)
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// tooLarge returns the error of a body of the size over the max
+func tooLarge(id, kind string, size, max int) error {
+	return errors.RequestEntityTooLarge(id, "%s of %d bytes exceeds the max size of %d bytes", kind, size, max)
+}
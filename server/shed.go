@@ -0,0 +1,98 @@
+package server
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Shedder decides to shed the load of an overloaded server by rejecting
+// requests e.g. on high CPU usage
+type Shedder interface {
+	// Shed returns true to reject a request given the requests in flight
+	Shed(inFlight int) bool
+}
+
+// ShedFunc is a func shedding load
+type ShedFunc func(inFlight int) bool
+
+func (f ShedFunc) Shed(inFlight int) bool {
+	return f(inFlight)
+}
+
+// DefaultCPUInterval is the interval of the samples of the CPU usage
+var DefaultCPUInterval = 250 * time.Millisecond
+
+type cpuShedder struct {
+	threshold float64
+
+	sync.Mutex
+	// the last sample of the cpu time of the process
+	sampled time.Time
+	cpu     time.Duration
+	usage   float64
+}
+
+func (c *cpuShedder) Shed(int) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if now := time.Now(); now.Sub(c.sampled) >= DefaultCPUInterval {
+		cpu, ok := cpuTime()
+		if !ok {
+			return false
+		}
+		if !c.sampled.IsZero() {
+			wall := now.Sub(c.sampled) * time.Duration(runtime.NumCPU())
+			c.usage = float64(cpu-c.cpu) / float64(wall)
+		}
+		c.sampled = now
+		c.cpu = cpu
+	}
+
+	return c.usage > c.threshold
+}
+
+// CPUShedder sheds load while the CPU usage of the process is over the
+// threshold, from 0 to 1 of all the CPUs. It never sheds on platforms
+// where the usage isn't available.
+func CPUShedder(threshold float64) Shedder {
+	return &cpuShedder{threshold: threshold}
+}
+
+// QueueShedder sheds load while the goroutines of the process, those of the
+// requests waiting on slow dependencies included, are more than the max
+func QueueShedder(max int) Shedder {
+	return ShedFunc(func(int) bool {
+		return runtime.NumGoroutine() > max
+	})
+}
+
+// Limiter limits the requests in flight of a server
+type Limiter struct {
+	inFlight int64
+}
+
+// Acquire admits a request unless the max requests are in flight or the
+// shedder sheds it, returning the func to call once the request is done.
+// The requests aren't limited if max is zero and shedder nil.
+func (l *Limiter) Acquire(max int, shedder Shedder) (func(), bool) {
+	n := atomic.AddInt64(&l.inFlight, 1)
+	release := func() {
+		atomic.AddInt64(&l.inFlight, -1)
+	}
+
+	if (max > 0 && n > int64(max)) || (shedder != nil && shedder.Shed(int(n-1))) {
+		release()
+		return nil, false
+	}
+
+	var once sync.Once
+	return func() { once.Do(release) }, true
+}
+
+// InFlight returns the number of requests in flight
+func (l *Limiter) InFlight() int {
+	return int(atomic.LoadInt64(&l.inFlight))
+}
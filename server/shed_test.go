@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestLimiter(t *testing.T) {
+	var l Limiter
+
+	// the requests aren't limited by default
+	for i := 0; i < 10; i++ {
+		if _, ok := l.Acquire(0, nil); !ok {
+			t.Fatal("Expected the request to be admitted")
+		}
+	}
+
+	l = Limiter{}
+	release, ok := l.Acquire(2, nil)
+	if !ok {
+		t.Fatal("Expected the request to be admitted")
+	}
+	if _, ok := l.Acquire(2, nil); !ok {
+		t.Fatal("Expected the request to be admitted")
+	}
+	if _, ok := l.Acquire(2, nil); ok {
+		t.Fatal("Expected the request over the max to be rejected")
+	}
+
+	// releasing twice frees one request
+	release()
+	release()
+	if l.InFlight() != 1 {
+		t.Fatalf("Expected 1 request in flight, got %d", l.InFlight())
+	}
+	if _, ok := l.Acquire(2, nil); !ok {
+		t.Fatal("Expected the request to be admitted once released")
+	}
+
+	// the shedder is given the requests in flight
+	var shed int
+	shedder := ShedFunc(func(inFlight int) bool {
+		shed = inFlight
+		return true
+	})
+	if _, ok := l.Acquire(0, shedder); ok || shed != 2 {
+		t.Fatalf("Expected the request to be shed with 2 in flight, got %d", shed)
+	}
+	if l.InFlight() != 2 {
+		t.Fatalf("Expected 2 requests in flight, got %d", l.InFlight())
+	}
+}
+
+func TestCPUShedder(t *testing.T) {
+	s := CPUShedder(1).(*cpuShedder)
+	if s.Shed(0) {
+		t.Fatal("Expected no shedding without a previous sample")
+	}
+
+	// force the usage over the threshold
+	s.usage = 2
+	if !s.Shed(0) {
+		t.Fatal("Expected shedding over the threshold")
+	}
+}
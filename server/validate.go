@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+// ValidateFunc validates a request message, returning the error of the fields
+// invalid, e.g. a ValidationError
+type ValidateFunc func(msg interface{}) error
+
+// FieldError is the error of a field of a message, as generated by
+// protoc-gen-validate
+type FieldError interface {
+	Field() string
+	Reason() string
+}
+
+// ValidationError lists the errors of the fields of a message
+type ValidationError []FieldError
+
+func (v ValidationError) Error() string {
+	fields := make([]string, 0, len(v))
+	for _, f := range v {
+		fields = append(fields, f.Field()+": "+f.Reason())
+	}
+	return strings.Join(fields, "; ")
+}
+
+type fieldError struct {
+	field, reason string
+}
+
+func (f *fieldError) Field() string  { return f.field }
+func (f *fieldError) Reason() string { return f.reason }
+
+// NewFieldError returns the error of the field for the reason, e.g. for
+// a ValidateFunc of struct tags
+func NewFieldError(field, reason string, a ...interface{}) FieldError {
+	return &fieldError{field: field, reason: fmt.Sprintf(reason, a...)}
+}
+
+// ValidateMessage validates the messages generated by protoc-gen-validate,
+// or any with a Validate method, reporting all the fields invalid if they
+// have a ValidateAll method. The messages without are valid.
+func ValidateMessage(msg interface{}) error {
+	var err error
+	switch v := msg.(type) {
+	case interface{ ValidateAll() error }:
+		err = v.ValidateAll()
+	case interface{ Validate() error }:
+		err = v.Validate()
+	}
+	if err == nil {
+		return nil
+	}
+
+	// the errors of all the fields
+	var errs []error
+	if m, ok := err.(interface{ AllErrors() []error }); ok {
+		errs = m.AllErrors()
+	} else {
+		errs = []error{err}
+	}
+
+	var verr ValidationError
+	for _, e := range errs {
+		f, ok := e.(FieldError)
+		if !ok {
+			return err
+		}
+		verr = append(verr, f)
+	}
+	return verr
+}
+
+// ValidateWrapper validates the requests before the handler, and the messages
+// received by the streams, none if fn is nil. The invalid requests get a bad
+// request error listing the fields.
+func ValidateWrapper(fn ValidateFunc) HandlerWrapper {
+	return func(h HandlerFunc) HandlerFunc {
+		if fn == nil {
+			return h
+		}
+		return func(ctx context.Context, req Request, rsp interface{}) error {
+			if s, ok := rsp.(Stream); ok {
+				return h(ctx, req, &validateStream{Stream: s, validate: fn})
+			}
+			if err := validate(fn, req.Service(), req.Endpoint(), req.Body()); err != nil {
+				return err
+			}
+			return h(ctx, req, rsp)
+		}
+	}
+}
+
+// validate returns the bad request error of the message if it's invalid
+func validate(fn ValidateFunc, service, endpoint string, msg interface{}) error {
+	if msg == nil {
+		return nil
+	}
+	if err := fn(msg); err != nil {
+		return errors.BadRequest(service, "invalid %s request: %v", endpoint, err)
+	}
+	return nil
+}
+
+// validateStream validates the messages received
+type validateStream struct {
+	Stream
+	validate ValidateFunc
+}
+
+func (v *validateStream) Recv(msg interface{}) error {
+	if err := v.Stream.Recv(msg); err != nil {
+		return err
+	}
+	req := v.Request()
+	return validate(v.validate, req.Service(), req.Endpoint(), msg)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/micro/go-micro/v2/errors"
+)
+
+// testMessage validates as the messages generated by protoc-gen-validate
+type testMessage struct {
+	Name  string
+	Email string
+}
+
+type testFieldError struct {
+	field, reason string
+}
+
+func (e testFieldError) Field() string  { return e.field }
+func (e testFieldError) Reason() string { return e.reason }
+func (e testFieldError) Error() string  { return e.field + ": " + e.reason }
+
+type testMultiError []error
+
+func (m testMultiError) Error() string      { return "invalid message" }
+func (m testMultiError) AllErrors() []error { return m }
+
+func (m *testMessage) ValidateAll() error {
+	var errs testMultiError
+	if len(m.Name) == 0 {
+		errs = append(errs, testFieldError{"Name", "value is required"})
+	}
+	if !strings.Contains(m.Email, "@") {
+		errs = append(errs, testFieldError{"Email", "value must be a valid email address"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type testStream struct {
+	Stream
+	req  Request
+	recv interface{}
+}
+
+func (s *testStream) Request() Request {
+	return s.req
+}
+
+func (s *testStream) Recv(msg interface{}) error {
+	*msg.(*testMessage) = *s.recv.(*testMessage)
+	return nil
+}
+
+func TestValidateMessage(t *testing.T) {
+	if err := ValidateMessage(&testMessage{Name: "foo", Email: "foo@bar.com"}); err != nil {
+		t.Fatalf("Expected the message to be valid, got %v", err)
+	}
+	// the messages without a validate method are valid
+	if err := ValidateMessage(&struct{}{}); err != nil {
+		t.Fatalf("Expected the message to be valid, got %v", err)
+	}
+
+	err := ValidateMessage(&testMessage{Email: "foo"})
+	verr, ok := err.(ValidationError)
+	if !ok || len(verr) != 2 {
+		t.Fatalf("Expected the errors of the 2 fields, got %v", err)
+	}
+	if verr[0].Field() != "Name" || verr[1].Field() != "Email" {
+		t.Fatalf("Unexpected fields %v", verr)
+	}
+}
+
+func TestValidateWrapper(t *testing.T) {
+	var called bool
+	h := ValidateWrapper(ValidateMessage)(func(ctx context.Context, req Request, rsp interface{}) error {
+		called = true
+		if s, ok := rsp.(Stream); ok {
+			return s.Recv(&testMessage{})
+		}
+		return nil
+	})
+
+	req := &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar", rawBody: &testMessage{Email: "foo"}}
+	err := h(context.TODO(), req, nil)
+	merr := errors.FromError(err)
+	if merr.Code != 400 || called {
+		t.Fatalf("Expected a bad request, got %v", err)
+	}
+	if merr.Detail != "invalid Foo.Bar request: Name: value is required; Email: value must be a valid email address" {
+		t.Fatalf("Unexpected detail %s", merr.Detail)
+	}
+
+	req.rawBody = &testMessage{Name: "foo", Email: "foo@bar.com"}
+	if err := h(context.TODO(), req, nil); err != nil || !called {
+		t.Fatalf("Expected the handler to be called, got %v", err)
+	}
+
+	// the messages received by the streams are validated
+	stream := &testStream{req: &rpcRequest{service: "go.micro.service.foo", endpoint: "Foo.Stream"}, recv: &testMessage{}}
+	if err := h(context.TODO(), stream.req, stream); errors.FromError(err).Code != 400 {
+		t.Fatalf("Expected a bad request, got %v", err)
+	}
+
+	// none are validated without a func
+	h = ValidateWrapper(nil)(func(ctx context.Context, req Request, rsp interface{}) error {
+		return nil
+	})
+	req.rawBody = &testMessage{}
+	if err := h(context.TODO(), req, nil); err != nil {
+		t.Fatalf("Expected no validation, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"strings"
+)
+
+// VersionHeader is the header of the requests selecting the version of the
+// handler, unless the endpoint names it e.g. Greeter@v2.Hello
+const VersionHeader = "Micro-Api-Version"
+
+// HandlerVersion serves the handler as the version, so a server can serve
+// several versions of a handler of the same name, e.g. during a migration to
+// new request types. The endpoints of the handler are advertised as
+// Greeter@v2.Hello, with the version in their metadata. The requests select
+// the version by the endpoint or the VersionHeader, the requests without get
+// the handler without a version, if any, otherwise the first version handled.
+func HandlerVersion(v string) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Version = v
+	}
+}
+
+// VersionedName returns the name of the handler at the version, the name
+// if the version is blank
+func VersionedName(name, version string) string {
+	if len(version) == 0 {
+		return name
+	}
+	return name + "@" + version
+}
+
+// SplitVersion splits the versioned name of a handler into its name and
+// version, the version is blank if the name has none
+func SplitVersion(name string) (string, string) {
+	if i := strings.Index(name, "@"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// splitEndpoint splits the endpoint of a request into its service, version
+// and method. The version may contain dots, e.g. Greeter@1.2.0.Hello.
+func splitEndpoint(endpoint string) (service, version, method string, ok bool) {
+	i := strings.LastIndex(endpoint, ".")
+	if i < 0 {
+		return "", "", "", false
+	}
+	service, version = SplitVersion(endpoint[:i])
+	method = endpoint[i+1:]
+	if len(service) == 0 || len(method) == 0 || strings.Contains(service, ".") {
+		return "", "", "", false
+	}
+	return service, version, method, true
+}
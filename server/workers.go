@@ -0,0 +1,165 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/logger"
+)
+
+// Workers handle the events of a subscriber with a fixed number of goroutines
+// reading a bounded queue, instead of one per message. The broker is blocked
+// once the queue is full.
+type Workers struct {
+	handler broker.Handler
+	queue   chan *job
+	// wait for the result of the events to ack them
+	wait bool
+	// tracks the events queued for the server to drain them
+	wg *sync.WaitGroup
+
+	sync.RWMutex
+	closed bool
+	once   sync.Once
+	exit   chan bool
+}
+
+type job struct {
+	event broker.Event
+	err   chan error
+}
+
+// NewWorkers starts n workers handling the events queued, with up to size
+// events waiting for them. When waiting, the broker gets the result of each
+// event e.g. to ack it, otherwise the events are acked once queued. The wait
+// group tracks the events queued, if not nil.
+func NewWorkers(h broker.Handler, n, size int, wait bool, wg *sync.WaitGroup) *Workers {
+	if n < 1 {
+		n = 1
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	w := &Workers{
+		handler: h,
+		queue:   make(chan *job, size),
+		wait:    wait,
+		wg:      wg,
+		exit:    make(chan bool),
+	}
+
+	for i := 0; i < n; i++ {
+		go w.run()
+	}
+
+	return w
+}
+
+// NewSubscriberWorkers returns the workers handling the events of the
+// subscriber with its concurrency, or the one of the server, nil if the events
+// are handled in the goroutines of the broker. The broker gets the result of
+// each event if the subscriber acks them itself or is delivered at least once.
+func NewSubscriberWorkers(h broker.Handler, sb Subscriber, opts Options, wg *sync.WaitGroup) *Workers {
+	so := sb.Options()
+
+	n := so.Concurrency
+	if n == 0 {
+		n = opts.SubscriberConcurrency
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	size := so.QueueSize
+	if size == 0 {
+		size = opts.SubscriberQueueSize
+	}
+
+	wait := !so.AutoAck || so.Delivery == broker.AtLeastOnce
+	return NewWorkers(h, n, size, wait, wg)
+}
+
+func (w *Workers) run() {
+	for j := range w.queue {
+		err := w.handler(j.event)
+		if w.wg != nil {
+			w.wg.Done()
+		}
+
+		if j.err != nil {
+			j.err <- err
+		} else if err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Failed to handle event of topic %s: %v", j.event.Topic(), err)
+		}
+	}
+}
+
+// Handle queues the event for the workers, blocking while the queue is full
+func (w *Workers) Handle(e broker.Event) error {
+	w.RLock()
+	if w.closed {
+		w.RUnlock()
+		return errors.ServiceUnavailable("go.micro.server", "subscriber of topic %s stopped", e.Topic())
+	}
+
+	j := &job{event: e}
+	if w.wait {
+		j.err = make(chan error, 1)
+	}
+
+	if w.wg != nil {
+		w.wg.Add(1)
+	}
+
+	// the queue is closed once no event is being queued
+	select {
+	case w.queue <- j:
+		w.RUnlock()
+	case <-w.exit:
+		w.RUnlock()
+		if w.wg != nil {
+			w.wg.Done()
+		}
+		return errors.ServiceUnavailable("go.micro.server", "subscriber of topic %s stopped", e.Topic())
+	}
+
+	if j.err == nil {
+		return nil
+	}
+	return <-j.err
+}
+
+// Stop queuing events, the workers exit once the events queued are handled
+func (w *Workers) Stop() {
+	// unblock the events waiting for the queue first
+	w.once.Do(func() {
+		close(w.exit)
+	})
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return
+	}
+	w.closed = true
+	close(w.queue)
+}
+
+// Subscriber returns the subscriber stopping the workers once unsubscribed
+func (w *Workers) Subscriber(sub broker.Subscriber) broker.Subscriber {
+	return &workersSubscriber{Subscriber: sub, workers: w}
+}
+
+type workersSubscriber struct {
+	broker.Subscriber
+	workers *Workers
+}
+
+func (w *workersSubscriber) Unsubscribe() error {
+	err := w.Subscriber.Unsubscribe()
+	w.workers.Stop()
+	return err
+}
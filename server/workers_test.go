@@ -0,0 +1,117 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/errors"
+)
+
+type testEvent struct {
+	topic string
+}
+
+func (e *testEvent) Topic() string            { return e.topic }
+func (e *testEvent) Message() *broker.Message { return &broker.Message{} }
+func (e *testEvent) Ack() error               { return nil }
+func (e *testEvent) Nack(bool) error          { return nil }
+func (e *testEvent) Error() error             { return nil }
+
+func TestWorkers(t *testing.T) {
+	var running, max, handled int32
+	release := make(chan bool)
+
+	h := func(e broker.Event) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&handled, 1)
+		return nil
+	}
+
+	wg := new(sync.WaitGroup)
+	w := NewWorkers(h, 2, 2, false, wg)
+
+	// two events are handled and two queued, the next one blocks the broker
+	for i := 0; i < 4; i++ {
+		if err := w.Handle(&testEvent{"foo"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocked := make(chan error)
+	go func() {
+		blocked <- w.Handle(&testEvent{"foo"})
+	}()
+	select {
+	case <-blocked:
+		t.Fatal("Expected the broker to be blocked once the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-blocked; err != nil {
+		t.Fatal(err)
+	}
+
+	// the events queued are handled once stopped, the next ones rejected
+	w.Stop()
+	wg.Wait()
+	if n := atomic.LoadInt32(&handled); n != 5 {
+		t.Fatalf("Expected 5 events handled, got %d", n)
+	}
+	if m := atomic.LoadInt32(&max); m != 2 {
+		t.Fatalf("Expected at most 2 events handled at once, got %d", m)
+	}
+	if err := w.Handle(&testEvent{"foo"}); errors.FromError(err).Code != 503 {
+		t.Fatalf("Expected the event to be rejected once stopped, got %v", err)
+	}
+}
+
+func TestWorkersWait(t *testing.T) {
+	failed := errors.InternalServerError("go.micro.server", "failed")
+	w := NewWorkers(func(e broker.Event) error {
+		return failed
+	}, 1, 0, true, nil)
+	defer w.Stop()
+
+	// the broker gets the result of the event to ack it
+	if err := w.Handle(&testEvent{"foo"}); err != failed {
+		t.Fatalf("Expected the error of the handler, got %v", err)
+	}
+}
+
+func TestNewSubscriberWorkers(t *testing.T) {
+	h := func(e broker.Event) error { return nil }
+	opts := newOptions()
+
+	sb := newSubscriber("foo", func(msg *testEvent) error { return nil })
+	if w := NewSubscriberWorkers(h, sb, opts, nil); w != nil {
+		t.Fatal("Expected no workers without concurrency")
+	}
+
+	// the concurrency of the subscriber overrides the one of the server
+	opts.SubscriberConcurrency = 4
+	sb = newSubscriber("foo", func(msg *testEvent) error { return nil }, SubscriberConcurrency(2))
+	w := NewSubscriberWorkers(h, sb, opts, nil)
+	if w == nil {
+		t.Fatal("Expected the workers of the subscriber")
+	}
+	w.Stop()
+
+	// the subscribers acking the messages wait for their result
+	sb = newSubscriber("foo", func(msg *testEvent) error { return nil }, DisableAutoAck())
+	w = NewSubscriberWorkers(h, sb, opts, nil)
+	if w == nil || !w.wait {
+		t.Fatal("Expected the workers to wait for the result of the events")
+	}
+	w.Stop()
+}
@@ -1,6 +1,7 @@
 package micro
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	rtime "runtime"
@@ -9,11 +10,14 @@ import (
 
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/cmd"
+	"github.com/micro/go-micro/v2/debug/manifest"
 	"github.com/micro/go-micro/v2/debug/service/handler"
 	"github.com/micro/go-micro/v2/debug/stats"
 	"github.com/micro/go-micro/v2/debug/trace"
 	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/plugin"
+	"github.com/micro/go-micro/v2/registry"
 	"github.com/micro/go-micro/v2/server"
 	"github.com/micro/go-micro/v2/store"
 	signalutil "github.com/micro/go-micro/v2/util/signal"
@@ -143,16 +147,39 @@ func (s *service) String() string {
 }
 
 func (s *service) Start() error {
+	// wait for the dependencies before starting
+	if len(s.opts.WaitFor) > 0 {
+		if err := registry.WaitForServices(s.opts.Context, s.opts.Registry, s.opts.WaitFor...); err != nil {
+			return err
+		}
+	}
+
 	for _, fn := range s.opts.BeforeStart {
 		if err := fn(); err != nil {
 			return err
 		}
 	}
 
+	// advertise the dependencies in the registry
+	deps := append(append([]string{}, s.opts.Dependencies...), s.opts.WaitFor...)
+	if len(deps) > 0 {
+		md := metadata.Copy(s.opts.Server.Options().Metadata)
+		md[manifest.DependenciesKey] = strings.Join(deps, ",")
+		s.opts.Server.Init(server.Metadata(md))
+	}
+
 	if err := s.opts.Server.Start(); err != nil {
 		return err
 	}
 
+	// describe the service now its endpoints are registered
+	m, err := manifest.Generate(s.opts.Server, deps, cmd.Flags())
+	if err != nil {
+		logger.Errorf("Error generating the service manifest: %v", err)
+	} else {
+		manifest.Set(m)
+	}
+
 	for _, fn := range s.opts.AfterStart {
 		if err := fn(); err != nil {
 			return err
@@ -175,6 +202,20 @@ func (s *service) Stop() error {
 		return err
 	}
 
+	// stop probing the static addresses
+	if p := s.opts.Client.Options().CallOptions.Prober; p != nil {
+		p.Stop()
+	}
+
+	// publish the messages still queued
+	if q := s.opts.Client.Options().PublishQueue; q != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), client.DefaultPublishDrainTimeout)
+		if err := q.Close(ctx); err != nil {
+			gerr = err
+		}
+		cancel()
+	}
+
 	for _, fn := range s.opts.AfterStop {
 		if err := fn(); err != nil {
 			gerr = err
@@ -188,7 +229,7 @@ func (s *service) Run() error {
 	// register the debug handler
 	s.opts.Server.Handle(
 		s.opts.Server.NewHandler(
-			handler.NewHandler(s.opts.Client),
+			handler.NewServerHandler(s.opts.Client, s.opts.Server),
 			server.InternalHandler(true),
 		),
 	)
@@ -206,6 +247,13 @@ func (s *service) Run() error {
 		defer s.opts.Profile.Stop()
 	}
 
+	// collect crash reports, the panics of Run are reported first
+	if s.opts.Crash != nil {
+		s.opts.Crash.Start()
+		defer s.opts.Crash.Stop()
+		defer s.opts.Crash.Recover()
+	}
+
 	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
 		logger.Infof("Starting [service] %s", s.Name())
 	}
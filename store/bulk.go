@@ -0,0 +1,374 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+var (
+	// DefaultBatchSize is the number of records processed between checkpoints
+	DefaultBatchSize uint = 100
+)
+
+// Checkpoint records the progress of a bulk export, import or migration. The
+// records are processed in key order so every key up to and including Key has
+// been processed.
+type Checkpoint struct {
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table,omitempty"`
+	Prefix   string `json:"prefix,omitempty"`
+	// Key is the last key processed
+	Key string `json:"key"`
+	// Count is the number of records processed
+	Count uint64 `json:"count"`
+	// Offset is the size in bytes of the output of an export up to Key.
+	// The output is truncated to it before resuming so the records written
+	// after the checkpoint aren't written twice.
+	Offset int64 `json:"offset,omitempty"`
+	// Done is set once every record has been processed
+	Done bool `json:"done"`
+}
+
+// Progress is reported after every batch of records
+type Progress struct {
+	// Key is the last key processed
+	Key string
+	// Count is the number of records processed, including previous sessions
+	Count uint64
+	// Total is the number of records, 0 if unknown e.g. when importing
+	Total uint64
+}
+
+// BulkOptions configures a bulk export, import or migration
+type BulkOptions struct {
+	Database, Table string
+	// Prefix limits the records to the keys with the prefix
+	Prefix string
+	// BatchSize is the number of records processed between checkpoints
+	BatchSize uint
+	// Checkpoint is the path of the file the progress is saved to. If the
+	// file exists the operation resumes from it.
+	Checkpoint string
+	// Progress is called after every batch of records
+	Progress func(Progress)
+}
+
+// BulkOption sets values in BulkOptions
+type BulkOption func(b *BulkOptions)
+
+// BulkFrom the database and table
+func BulkFrom(database, table string) BulkOption {
+	return func(b *BulkOptions) {
+		b.Database = database
+		b.Table = table
+	}
+}
+
+// BulkPrefix limits the records to the keys with the prefix
+func BulkPrefix(p string) BulkOption {
+	return func(b *BulkOptions) {
+		b.Prefix = p
+	}
+}
+
+// BulkBatchSize sets the number of records processed between checkpoints
+func BulkBatchSize(n uint) BulkOption {
+	return func(b *BulkOptions) {
+		b.BatchSize = n
+	}
+}
+
+// BulkCheckpoint saves the progress to the file at path and resumes from it if it exists
+func BulkCheckpoint(path string) BulkOption {
+	return func(b *BulkOptions) {
+		b.Checkpoint = path
+	}
+}
+
+// BulkProgress is called after every batch of records
+func BulkProgress(fn func(Progress)) BulkOption {
+	return func(b *BulkOptions) {
+		b.Progress = fn
+	}
+}
+
+// NewBulkOptions returns the bulk options with the defaults applied
+func NewBulkOptions(opts ...BulkOption) BulkOptions {
+	options := BulkOptions{
+		BatchSize: DefaultBatchSize,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.BatchSize == 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	return options
+}
+
+// LoadCheckpoint reads the checkpoint at path. A missing file returns a nil checkpoint.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %v", path, err)
+	}
+	return &cp, nil
+}
+
+// SaveCheckpoint atomically writes the checkpoint to path
+func SaveCheckpoint(path string, cp *Checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Export writes the records of the store to w as a stream of JSON records in
+// key order. With a checkpoint an interrupted export resumes after the last
+// exported key, so w should append to the output of the previous session
+// truncated to the Offset of the checkpoint, see ResumeExport.
+func Export(s Store, w io.Writer, opts ...BulkOption) (*Checkpoint, error) {
+	cw := &countWriter{w: w}
+	enc := json.NewEncoder(cw)
+	return bulkCopy(s, NewBulkOptions(opts...), func(cp *Checkpoint, rec *Record) error {
+		cw.n = 0
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+		cp.Offset += cw.n
+		return nil
+	})
+}
+
+// ResumeExport opens the file an export writes to. With a checkpoint of an
+// interrupted export the file is truncated to its offset, dropping the
+// records written after it, so the export appends the next records once.
+func ResumeExport(path string, cp *Checkpoint) (*os.File, error) {
+	if cp == nil || cp.Done {
+		return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(cp.Offset); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// countWriter counts the bytes written
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Import writes the records read from r, as produced by Export, to the store.
+// With a checkpoint an interrupted import skips the records up to the last
+// imported key.
+func Import(s Store, r io.Reader, opts ...BulkOption) (*Checkpoint, error) {
+	options := NewBulkOptions(opts...)
+
+	cp, err := bulkCheckpoint(options)
+	if err != nil || cp.Done {
+		return cp, err
+	}
+
+	var writeOpts []WriteOption
+	if len(options.Database) > 0 || len(options.Table) > 0 {
+		writeOpts = append(writeOpts, WriteTo(options.Database, options.Table))
+	}
+
+	dec := json.NewDecoder(r)
+	var batch uint
+
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return cp, fmt.Errorf("error decoding record after %q: %v", cp.Key, err)
+		}
+
+		// the records are in key order so anything up to the checkpoint
+		// was imported by a previous session
+		if cp.Count > 0 && rec.Key <= cp.Key {
+			continue
+		}
+
+		if err := s.Write(&rec, writeOpts...); err != nil {
+			return cp, fmt.Errorf("error writing record %s: %v", rec.Key, err)
+		}
+
+		cp.Key = rec.Key
+		cp.Count++
+
+		if batch++; batch == options.BatchSize {
+			if err := bulkSave(options, cp, 0); err != nil {
+				return cp, err
+			}
+			batch = 0
+		}
+	}
+
+	cp.Done = true
+	return cp, bulkSave(options, cp, 0)
+}
+
+// Migrate copies the records of the src store to the dst store in key order.
+// With a checkpoint an interrupted migration resumes after the last copied key.
+func Migrate(src, dst Store, opts ...BulkOption) (*Checkpoint, error) {
+	options := NewBulkOptions(opts...)
+
+	var writeOpts []WriteOption
+	if len(options.Database) > 0 || len(options.Table) > 0 {
+		writeOpts = append(writeOpts, WriteTo(options.Database, options.Table))
+	}
+
+	return bulkCopy(src, options, func(cp *Checkpoint, rec *Record) error {
+		if err := dst.Write(rec, writeOpts...); err != nil {
+			return fmt.Errorf("error writing record %s: %v", rec.Key, err)
+		}
+		return nil
+	})
+}
+
+// bulkCheckpoint loads the checkpoint to resume from, checking it was made
+// for the same records
+func bulkCheckpoint(options BulkOptions) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		Database: options.Database,
+		Table:    options.Table,
+		Prefix:   options.Prefix,
+	}
+	if len(options.Checkpoint) == 0 {
+		return cp, nil
+	}
+
+	saved, err := LoadCheckpoint(options.Checkpoint)
+	if err != nil || saved == nil {
+		return cp, err
+	}
+
+	if saved.Database != cp.Database || saved.Table != cp.Table || saved.Prefix != cp.Prefix {
+		return cp, fmt.Errorf("checkpoint %s is for database %q table %q prefix %q",
+			options.Checkpoint, saved.Database, saved.Table, saved.Prefix)
+	}
+
+	return saved, nil
+}
+
+// bulkSave saves the checkpoint and reports the progress
+func bulkSave(options BulkOptions, cp *Checkpoint, total uint64) error {
+	if len(options.Checkpoint) > 0 {
+		if err := SaveCheckpoint(options.Checkpoint, cp); err != nil {
+			return fmt.Errorf("error saving checkpoint: %v", err)
+		}
+	}
+	if options.Progress != nil {
+		options.Progress(Progress{Key: cp.Key, Count: cp.Count, Total: total})
+	}
+	return nil
+}
+
+// bulkCopy reads the records of the store in key order, skipping the ones
+// up to the checkpoint, and passes them to fn
+func bulkCopy(s Store, options BulkOptions, fn func(*Checkpoint, *Record) error) (*Checkpoint, error) {
+	cp, err := bulkCheckpoint(options)
+	if err != nil || cp.Done {
+		return cp, err
+	}
+
+	var listOpts []ListOption
+	var readOpts []ReadOption
+	if len(options.Database) > 0 || len(options.Table) > 0 {
+		listOpts = append(listOpts, ListFrom(options.Database, options.Table))
+		readOpts = append(readOpts, ReadFrom(options.Database, options.Table))
+	}
+	if len(options.Prefix) > 0 {
+		listOpts = append(listOpts, ListPrefix(options.Prefix))
+	}
+
+	keys, err := s.List(listOpts...)
+	if err != nil {
+		return cp, fmt.Errorf("error listing keys: %v", err)
+	}
+	sort.Strings(keys)
+
+	total := uint64(len(keys))
+
+	// skip the keys processed by previous sessions
+	if cp.Count > 0 {
+		keys = keys[sort.Search(len(keys), func(i int) bool { return keys[i] > cp.Key }):]
+	}
+
+	var batch uint
+
+	for _, key := range keys {
+		recs, err := s.Read(key, readOpts...)
+		if err == ErrNotFound {
+			// deleted since listed
+			continue
+		} else if err != nil {
+			return cp, fmt.Errorf("error reading record %s: %v", key, err)
+		}
+
+		for _, rec := range recs {
+			if err := fn(cp, rec); err != nil {
+				return cp, err
+			}
+		}
+
+		cp.Key = key
+		cp.Count++
+
+		if batch++; batch == options.BatchSize {
+			if err := bulkSave(options, cp, total); err != nil {
+				return cp, err
+			}
+			batch = 0
+		}
+	}
+
+	cp.Done = true
+	return cp, bulkSave(options, cp, total)
+}
@@ -0,0 +1,212 @@
+package store_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro/go-micro/v2/store"
+	"github.com/micro/go-micro/v2/store/memory"
+)
+
+// failingStore fails to write after a number of writes
+type failingStore struct {
+	store.Store
+	writes int
+}
+
+func (f *failingStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	if f.writes == 0 {
+		return errors.New("interrupted")
+	}
+	f.writes--
+	return f.Store.Write(r, opts...)
+}
+
+func TestExportImport(t *testing.T) {
+	src := memory.NewStore()
+	for i := 0; i < 25; i++ {
+		if err := src.Write(&store.Record{Key: fmt.Sprintf("key-%02d", i), Value: []byte{byte(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	cp, err := store.Export(src, &buf, store.BulkBatchSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cp.Done || cp.Count != 25 {
+		t.Fatalf("Expected 25 exported records, got %+v", cp)
+	}
+
+	dir, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint")
+
+	// interrupt the import after the first batch has been checkpointed
+	dst := &failingStore{Store: memory.NewStore(), writes: 12}
+	data := buf.Bytes()
+
+	if _, err := store.Import(dst, bytes.NewReader(data), store.BulkBatchSize(10), store.BulkCheckpoint(path)); err == nil {
+		t.Fatal("Expected the import to be interrupted")
+	}
+
+	saved, err := store.LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Count != 10 || saved.Key != "key-09" || saved.Done {
+		t.Fatalf("Expected a checkpoint after the first batch, got %+v", saved)
+	}
+
+	// resume the import
+	dst.writes = 100
+	var progress []store.Progress
+
+	cp, err = store.Import(dst, bytes.NewReader(data),
+		store.BulkBatchSize(10),
+		store.BulkCheckpoint(path),
+		store.BulkProgress(func(p store.Progress) { progress = append(progress, p) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cp.Done || cp.Count != 25 {
+		t.Fatalf("Expected 25 imported records, got %+v", cp)
+	}
+	if len(progress) != 2 || progress[1].Count != 25 {
+		t.Fatalf("Expected progress after every batch, got %+v", progress)
+	}
+	// the resumed session only writes the records after the checkpoint
+	if dst.writes != 85 {
+		t.Fatalf("Expected 15 writes, got %d", 100-dst.writes)
+	}
+
+	keys, err := dst.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 25 {
+		t.Fatalf("Expected 25 keys, got %d", len(keys))
+	}
+
+	// a finished checkpoint doesn't import again
+	cp, err = store.Import(dst, bytes.NewReader(data), store.BulkCheckpoint(path))
+	if err != nil || !cp.Done || dst.writes != 85 {
+		t.Fatalf("Expected the import to be done, got %+v %v", cp, err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	src := memory.NewStore()
+	for _, key := range []string{"foo/1", "foo/2", "bar/1"} {
+		if err := src.Write(&store.Record{Key: key, Value: []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := memory.NewStore()
+	cp, err := store.Migrate(src, dst, store.BulkPrefix("foo/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Count != 2 || cp.Key != "foo/2" {
+		t.Fatalf("Expected the prefixed records to be migrated, got %+v", cp)
+	}
+
+	recs, err := dst.Read("foo/1")
+	if err != nil || len(recs) != 1 || string(recs[0].Value) != "foo/1" {
+		t.Fatalf("Expected foo/1 to be migrated, got %v %v", recs, err)
+	}
+	if _, err := dst.Read("bar/1"); err != store.ErrNotFound {
+		t.Fatalf("Expected bar/1 not to be migrated, got %v", err)
+	}
+}
+
+// failingWriter fails to write after a number of writes
+type failingWriter struct {
+	w      io.Writer
+	writes int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.writes == 0 {
+		return 0, errors.New("interrupted")
+	}
+	f.writes--
+	return f.w.Write(p)
+}
+
+func TestExportResume(t *testing.T) {
+	src := memory.NewStore()
+	for i := 0; i < 25; i++ {
+		if err := src.Write(&store.Record{Key: fmt.Sprintf("key-%02d", i), Value: []byte{byte(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint")
+	out := filepath.Join(dir, "export")
+
+	export := func(writes int) (*store.Checkpoint, error) {
+		cp, err := store.LoadCheckpoint(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := store.ResumeExport(out, cp)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return store.Export(src, &failingWriter{f, writes}, store.BulkBatchSize(10), store.BulkCheckpoint(path))
+	}
+
+	// interrupt the export after the first batch has been checkpointed
+	if _, err := export(12); err == nil {
+		t.Fatal("Expected the export to be interrupted")
+	}
+	cp, err := export(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cp.Done || cp.Count != 25 {
+		t.Fatalf("Expected 25 exported records, got %+v", cp)
+	}
+
+	// the records written after the checkpoint aren't written twice
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	var keys []string
+	for {
+		var rec store.Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, rec.Key)
+	}
+	if len(keys) != 25 || keys[0] != "key-00" || keys[24] != "key-24" {
+		t.Fatalf("Expected every record exported once, got %v", keys)
+	}
+}
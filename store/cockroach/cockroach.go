@@ -146,10 +146,16 @@ func (s *sqlStore) configure() error {
 		}
 	}
 
-	// create source from first node
-	db, err := sql.Open("postgres", source)
-	if err != nil {
-		return err
+	// create source from first node, fetching the credentials for every
+	// connection if they're issued dynamically
+	var db *sql.DB
+	if s.options.Credentials != nil {
+		db = sql.OpenDB(&connector{source: source, creds: s.options.Credentials})
+	} else {
+		db, err = sql.Open("postgres", source)
+		if err != nil {
+			return err
+		}
 	}
 
 	if err := db.Ping(); err != nil {
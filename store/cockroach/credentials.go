@@ -0,0 +1,54 @@
+package cockroach
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/url"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/micro/go-micro/v2/auth/credentials"
+)
+
+// connector opens connections with the current credentials of the provider so
+// renewed credentials are used by new connections
+type connector struct {
+	source string
+	creds  credentials.Provider
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	creds, err := c.creds.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pc, err := pq.NewConnector(withCredentials(c.source, creds))
+	if err != nil {
+		return nil, err
+	}
+	return pc.Connect(ctx)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// withCredentials sets the user and password of the connection string, either
+// a URL or key value pairs
+func withCredentials(source string, creds *credentials.Credentials) string {
+	if strings.HasPrefix(source, "postgres://") || strings.HasPrefix(source, "postgresql://") {
+		u, err := url.Parse(source)
+		if err == nil {
+			u.User = url.UserPassword(creds.Username, creds.Password)
+			return u.String()
+		}
+	}
+
+	// later keys take precedence
+	quote := func(v string) string {
+		v = strings.Replace(v, `\`, `\\`, -1)
+		return "'" + strings.Replace(v, "'", `\'`, -1) + "'"
+	}
+	return source + " user=" + quote(creds.Username) + " password=" + quote(creds.Password)
+}
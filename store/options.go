@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/micro/go-micro/v2/auth/credentials"
 	"github.com/micro/go-micro/v2/client"
 )
 
@@ -21,6 +22,8 @@ type Options struct {
 	Context context.Context
 	// Client to use for RPC
 	Client client.Client
+	// Credentials used to connect to the backend
+	Credentials credentials.Provider
 }
 
 // Option sets values in Options
@@ -63,6 +66,13 @@ func WithClient(c client.Client) Option {
 	}
 }
 
+// WithCredentials sets the provider of the credentials used to connect to the backend
+func WithCredentials(p credentials.Provider) Option {
+	return func(o *Options) {
+		o.Credentials = p
+	}
+}
+
 // ReadOptions configures an individual Read operation
 type ReadOptions struct {
 	Database, Table string
@@ -0,0 +1,67 @@
+package quota
+
+import (
+	"time"
+)
+
+// Options for the quota store
+type Options struct {
+	// Soft limit applied to every database and table
+	Soft Quota
+	// Hard limit applied to every database and table
+	Hard Quota
+	// Tables holds limits for specific tables, keyed by database/table
+	Tables map[string]Limits
+	// OnSoftLimit is called when a write takes a table over its soft limit
+	OnSoftLimit func(database, table string, usage Usage)
+	// Reload is how often the usage of a table is recalculated from the store
+	Reload time.Duration
+}
+
+// Limits are the soft and hard limit of a table
+type Limits struct {
+	Soft Quota
+	Hard Quota
+}
+
+// Option sets values in Options
+type Option func(o *Options)
+
+// SoftLimit sets the soft limit of every database and table
+func SoftLimit(q Quota) Option {
+	return func(o *Options) {
+		o.Soft = q
+	}
+}
+
+// HardLimit sets the hard limit of every database and table
+func HardLimit(q Quota) Option {
+	return func(o *Options) {
+		o.Hard = q
+	}
+}
+
+// TableLimit sets the soft and hard limit of a table, overriding the defaults
+func TableLimit(database, table string, soft, hard Quota) Option {
+	return func(o *Options) {
+		if o.Tables == nil {
+			o.Tables = make(map[string]Limits)
+		}
+		o.Tables[key(database, table)] = Limits{Soft: soft, Hard: hard}
+	}
+}
+
+// OnSoftLimit sets the func called when a write takes a table over its soft limit
+func OnSoftLimit(fn func(database, table string, usage Usage)) Option {
+	return func(o *Options) {
+		o.OnSoftLimit = fn
+	}
+}
+
+// Reload sets how often the usage of a table is recalculated from the store. Records
+// removed by expiry or written by other instances are only accounted for on reload.
+func Reload(d time.Duration) Option {
+	return func(o *Options) {
+		o.Reload = d
+	}
+}
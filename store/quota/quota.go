@@ -0,0 +1,247 @@
+// Package quota provides a store which limits the records and bytes held per database and table
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+	"github.com/micro/go-micro/v2/store"
+)
+
+var (
+	// DefaultReload is how often the usage of a table is recalculated
+	DefaultReload = 5 * time.Minute
+
+	// ErrQuotaExceeded is matched by every QuotaExceededError using errors.Is
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// Quota limits the usage of a table. Zero values are unlimited.
+type Quota struct {
+	// Records is the max number of records
+	Records int64
+	// Bytes is the max total size of the keys and values
+	Bytes int64
+}
+
+// Usage of a table
+type Usage struct {
+	// Records is the number of records
+	Records int64
+	// Bytes is the total size of the keys and values
+	Bytes int64
+}
+
+// exceeds checks if the usage is over the quota
+func (u Usage) exceeds(q Quota) bool {
+	return (q.Records > 0 && u.Records > q.Records) || (q.Bytes > 0 && u.Bytes > q.Bytes)
+}
+
+// QuotaExceededError is returned when a write would take a table over its hard limit
+type QuotaExceededError struct {
+	Database string
+	Table    string
+	// Usage the table would have had the write been made
+	Usage Usage
+	// Limit is the hard limit of the table
+	Limit Quota
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded for %s/%s: %d records and %d bytes, limit is %d records and %d bytes",
+		e.Database, e.Table, e.Usage.Records, e.Usage.Bytes, e.Limit.Records, e.Limit.Bytes)
+}
+
+// Is reports whether the target is ErrQuotaExceeded
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// table is the usage of a database and table
+type table struct {
+	sync.Mutex
+	loaded time.Time
+	sizes  map[string]int64
+	usage  Usage
+}
+
+type quotaStore struct {
+	store.Store
+	opts Options
+
+	sync.Mutex
+	tables map[string]*table
+}
+
+// NewStore returns a store which tracks the usage of each database and table in the given
+// store and rejects writes which would take a table over its hard limit
+func NewStore(s store.Store, opts ...Option) store.Store {
+	options := Options{
+		Reload: DefaultReload,
+		OnSoftLimit: func(database, table string, u Usage) {
+			logger.Warnf("Store %s/%s is over its soft limit with %d records and %d bytes", database, table, u.Records, u.Bytes)
+		},
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &quotaStore{
+		Store:  s,
+		opts:   options,
+		tables: make(map[string]*table),
+	}
+}
+
+func key(database, table string) string {
+	return database + "/" + table
+}
+
+func size(r *store.Record) int64 {
+	return int64(len(r.Key) + len(r.Value))
+}
+
+// limits returns the limits of the table
+func (q *quotaStore) limits(database, table string) Limits {
+	if l, ok := q.opts.Tables[key(database, table)]; ok {
+		return l
+	}
+	return Limits{Soft: q.opts.Soft, Hard: q.opts.Hard}
+}
+
+// resolve falls back to the database and table of the store
+func (q *quotaStore) resolve(database, table string) (string, string) {
+	o := q.Store.Options()
+	if len(database) == 0 {
+		database = o.Database
+	}
+	if len(table) == 0 {
+		table = o.Table
+	}
+	return database, table
+}
+
+// table returns the usage of the table locked, loading it if it's due a reload
+func (q *quotaStore) table(database, tbl string) (*table, error) {
+	q.Lock()
+	t, ok := q.tables[key(database, tbl)]
+	if !ok {
+		t = &table{}
+		q.tables[key(database, tbl)] = t
+	}
+	q.Unlock()
+
+	t.Lock()
+	if time.Since(t.loaded) > q.opts.Reload {
+		if err := q.load(t, database, tbl); err != nil {
+			t.Unlock()
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// load calculates the usage of the table from the records in the store
+func (q *quotaStore) load(t *table, database, tbl string) error {
+	recs, err := q.Store.Read("", store.ReadFrom(database, tbl), store.ReadPrefix())
+	if err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	t.sizes = make(map[string]int64, len(recs))
+	t.usage = Usage{}
+	for _, r := range recs {
+		s := size(r)
+		t.sizes[r.Key] = s
+		t.usage.Records++
+		t.usage.Bytes += s
+	}
+	t.loaded = time.Now()
+
+	return nil
+}
+
+func (q *quotaStore) Init(opts ...store.Option) error {
+	q.Lock()
+	q.tables = make(map[string]*table)
+	q.Unlock()
+	return q.Store.Init(opts...)
+}
+
+// Write the record unless it would take the table over its hard limit. Writes which don't
+// increase the usage of a table are always allowed.
+func (q *quotaStore) Write(r *store.Record, opts ...store.WriteOption) error {
+	var options store.WriteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, tbl := q.resolve(options.Database, options.Table)
+
+	t, err := q.table(database, tbl)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock()
+
+	// work out the usage after the write
+	usage := t.usage
+	old, ok := t.sizes[r.Key]
+	if !ok {
+		usage.Records++
+	}
+	usage.Bytes += size(r) - old
+
+	limits := q.limits(database, tbl)
+	grows := usage.Records > t.usage.Records || usage.Bytes > t.usage.Bytes
+	if grows && usage.exceeds(limits.Hard) {
+		return &QuotaExceededError{Database: database, Table: tbl, Usage: usage, Limit: limits.Hard}
+	}
+
+	if err := q.Store.Write(r, opts...); err != nil {
+		return err
+	}
+
+	crossed := !t.usage.exceeds(limits.Soft) && usage.exceeds(limits.Soft)
+	t.sizes[r.Key] = size(r)
+	t.usage = usage
+
+	if crossed && q.opts.OnSoftLimit != nil {
+		q.opts.OnSoftLimit(database, tbl, usage)
+	}
+
+	return nil
+}
+
+func (q *quotaStore) Delete(key string, opts ...store.DeleteOption) error {
+	var options store.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	database, tbl := q.resolve(options.Database, options.Table)
+
+	t, err := q.table(database, tbl)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock()
+
+	if err := q.Store.Delete(key, opts...); err != nil {
+		return err
+	}
+
+	if s, ok := t.sizes[key]; ok {
+		delete(t.sizes, key)
+		t.usage.Records--
+		t.usage.Bytes -= s
+	}
+
+	return nil
+}
+
+func (q *quotaStore) String() string {
+	return "quota"
+}
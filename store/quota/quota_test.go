@@ -0,0 +1,79 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/micro/go-micro/v2/store"
+	"github.com/micro/go-micro/v2/store/memory"
+)
+
+func TestQuota(t *testing.T) {
+	var soft []Usage
+	s := NewStore(
+		memory.NewStore(store.Database("db"), store.Table("default")),
+		SoftLimit(Quota{Records: 1}),
+		HardLimit(Quota{Records: 2}),
+		TableLimit("db", "big", Quota{}, Quota{Bytes: 1024}),
+		OnSoftLimit(func(database, table string, u Usage) {
+			soft = append(soft, u)
+		}),
+	)
+
+	for _, k := range []string{"a", "b"} {
+		if err := s.Write(&store.Record{Key: k, Value: []byte("value")}); err != nil {
+			t.Fatalf("Write %s: %v", k, err)
+		}
+	}
+	if len(soft) != 1 || soft[0].Records != 2 {
+		t.Fatalf("Expected one soft limit call at 2 records, got %v", soft)
+	}
+
+	err := s.Write(&store.Record{Key: "c", Value: []byte("value")})
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected quota exceeded, got %v", err)
+	}
+	if qe, ok := err.(*QuotaExceededError); !ok || qe.Table != "default" || qe.Usage.Records != 3 {
+		t.Fatalf("Unexpected error %#v", err)
+	}
+
+	// overwriting an existing record doesn't add to the count
+	if err := s.Write(&store.Record{Key: "a", Value: []byte("new value")}); err != nil {
+		t.Fatalf("Overwrite: %v", err)
+	}
+
+	// deleting frees up space
+	if err := s.Delete("b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Write(&store.Record{Key: "c", Value: []byte("value")}); err != nil {
+		t.Fatalf("Write after delete: %v", err)
+	}
+
+	// tables with their own limits only use those
+	big := make([]byte, 500)
+	for _, k := range []string{"a", "b", "c"} {
+		err := s.Write(&store.Record{Key: k, Value: big}, store.WriteTo("db", "big"))
+		if k == "b" && err != nil {
+			t.Fatalf("Write big %s: %v", k, err)
+		}
+		if k == "c" && !errors.Is(err, ErrQuotaExceeded) {
+			t.Fatalf("Expected quota exceeded writing %s, got %v", k, err)
+		}
+	}
+}
+
+func TestQuotaLoad(t *testing.T) {
+	m := memory.NewStore(store.Database("db"), store.Table("table"))
+	for _, k := range []string{"a", "b"} {
+		if err := m.Write(&store.Record{Key: k, Value: []byte("value")}); err != nil {
+			t.Fatalf("Write %s: %v", k, err)
+		}
+	}
+
+	// existing records count towards the quota
+	s := NewStore(m, HardLimit(Quota{Records: 2}))
+	if err := s.Write(&store.Record{Key: "c", Value: []byte("value")}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected quota exceeded, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"net"
+	"sync"
 
 	"github.com/micro/go-micro/v2/transport"
 	maddr "github.com/micro/go-micro/v2/util/addr"
@@ -25,6 +26,10 @@ type grpcTransportListener struct {
 	listener net.Listener
 	secure   bool
 	tls      *tls.Config
+
+	// the listener is closed once by either drain or close
+	once sync.Once
+	err  error
 }
 
 func getTLSConfig(addr string) (*tls.Config, error) {
@@ -49,11 +54,19 @@ func getTLSConfig(addr string) (*tls.Config, error) {
 }
 
 func (t *grpcTransportListener) Addr() string {
-	return t.listener.Addr().String()
+	return mnet.Addr(t.listener.Addr())
+}
+
+// Drain closes the listener, streams already open are left to finish
+func (t *grpcTransportListener) Drain() error {
+	t.once.Do(func() {
+		t.err = t.listener.Close()
+	})
+	return t.err
 }
 
 func (t *grpcTransportListener) Close() error {
-	return t.listener.Close()
+	return t.Drain()
 }
 
 func (t *grpcTransportListener) Accept(fn func(transport.Socket)) error {
@@ -79,7 +92,7 @@ func (t *grpcTransportListener) Accept(fn func(transport.Socket)) error {
 	srv := grpc.NewServer(opts...)
 
 	// register service
-	pb.RegisterTransportServer(srv, &microTransport{addr: t.listener.Addr().String(), fn: fn})
+	pb.RegisterTransportServer(srv, &microTransport{addr: t.Addr(), fn: fn})
 
 	// start serving
 	return srv.Serve(t.listener)
@@ -96,6 +109,13 @@ func (t *grpcTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 
 	options := []grpc.DialOption{}
 
+	// dial unix domain sockets, and the sockets with options, directly
+	if network, _ := mnet.Network(addr); network == "unix" || t.opts.Socket != (mnet.SocketOptions{}) {
+		options = append(options, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return mnet.DialSocket(ctx, addr, t.opts.Socket)
+		}))
+	}
+
 	if t.opts.Secure || t.opts.TLSConfig != nil {
 		config := t.opts.TLSConfig
 		if config == nil {
@@ -139,7 +159,7 @@ func (t *grpcTransport) Listen(addr string, opts ...transport.ListenOption) (tra
 	}
 
 	ln, err := mnet.Listen(addr, func(addr string) (net.Listener, error) {
-		return net.Listen("tcp", addr)
+		return mnet.ListenSocket(addr, t.opts.Socket)
 	})
 	if err != nil {
 		return nil, err
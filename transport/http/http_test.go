@@ -1,10 +1,17 @@
 package http
 
 import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"sync"
 	"testing"
 
 	"github.com/micro/go-micro/v2/transport"
+	mnet "github.com/micro/go-micro/v2/util/net"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 func call(b *testing.B, c int) {
@@ -136,3 +143,97 @@ func BenchmarkTransport64(b *testing.B) {
 func BenchmarkTransport128(b *testing.B) {
 	call(b, 128)
 }
+
+func TestListenerMux(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := mnet.NewMux(l)
+	h2L := m.Match(mnet.HTTP2())
+	healthL := m.Match(mnet.HTTP1Path("/health"))
+	httpL := m.Match(mnet.HTTP1())
+	go m.Serve()
+	defer m.Close()
+
+	// h2c e.g. grpc
+	h2 := &http.Server{Handler: h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}), &http2.Server{})}
+	go h2.Serve(h2L)
+
+	// plain http health checks
+	health := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})}
+	go health.Serve(healthL)
+
+	// the mucp http transport
+	tr := NewTransport(Listener(httpL))
+	tl, err := tr.Listen("ignored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go tl.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		for {
+			var m transport.Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	})
+
+	addr := l.Addr().String()
+
+	rsp, err := http.Get("http://" + addr + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(rsp.Body)
+	rsp.Body.Close()
+	if string(b) != "ok" {
+		t.Fatalf("Expected the health check to be served, got %q", b)
+	}
+
+	h2Client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	rsp, err = h2Client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ = ioutil.ReadAll(rsp.Body)
+	rsp.Body.Close()
+	if string(b) != "HTTP/2.0" {
+		t.Fatalf("Expected the h2c request to be served, got %q", b)
+	}
+
+	c, err := tr.Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	msg := transport.Message{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   []byte(`{"message": "Hello World"}`),
+	}
+	if err := c.Send(&msg); err != nil {
+		t.Fatal(err)
+	}
+	var rmsg transport.Message
+	if err := c.Recv(&rmsg); err != nil {
+		t.Fatal(err)
+	}
+	if string(rmsg.Body) != string(msg.Body) {
+		t.Fatalf("Expected the transport message to be echoed, got %q", rmsg.Body)
+	}
+}
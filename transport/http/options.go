@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"net"
 	"net/http"
 
 	"github.com/micro/go-micro/v2/transport"
@@ -21,3 +22,15 @@ func Handle(pattern string, handler http.Handler) transport.Option {
 		o.Context = context.WithValue(o.Context, "http_handlers", handlers)
 	}
 }
+
+// Listener serves the transport on the given listener rather than binding the
+// listen address e.g. a listener of a util/net Mux sharing a port with other
+// protocols. The listener is used as is, so it must already handle TLS if needed.
+func Listener(l net.Listener) transport.Option {
+	return func(o *transport.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, "http_listener", l)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+
+	mnet "github.com/micro/go-micro/v2/util/net"
 )
 
 const (
@@ -79,6 +81,11 @@ func proxyDial(conn net.Conn, addr string, proxyURL *url.URL) (_ net.Conn, err e
 // Creates a new connection
 func newConn(dial func(string) (net.Conn, error)) func(string) (net.Conn, error) {
 	return func(addr string) (net.Conn, error) {
+		// unix domain sockets are local
+		if network, _ := mnet.Network(addr); network == "unix" {
+			return dial(addr)
+		}
+
 		// get the proxy url
 		proxyURL, err := getURL(addr)
 		if err != nil {
@@ -3,6 +3,7 @@ package transport
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"io"
@@ -65,11 +66,20 @@ type httpTransportSocket struct {
 	// local/remote ip
 	local  string
 	remote string
+
+	// max size of the bodies received, zero is unlimited
+	max int
 }
 
 type httpTransportListener struct {
 	ht       *httpTransport
 	listener net.Listener
+	// max size of the bodies received, zero is unlimited
+	max int
+
+	// the listener is closed once by either drain or close
+	once sync.Once
+	err  error
 }
 
 func (h *httpTransportClient) Local() string {
@@ -217,16 +227,6 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 			r = rr
 		}
 
-		// read body
-		b, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			return err
-		}
-
-		// set body
-		r.Body.Close()
-		m.Body = b
-
 		// set headers
 		for k, v := range r.Header {
 			if len(v) > 0 {
@@ -236,6 +236,28 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 			}
 		}
 
+		// don't read a body over the max size
+		if h.max > 0 && r.ContentLength > int64(h.max) {
+			return ErrMessageTooLarge
+		}
+
+		// read body, up to a byte over the max size
+		body := io.Reader(r.Body)
+		if h.max > 0 {
+			body = io.LimitReader(r.Body, int64(h.max)+1)
+		}
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		if h.max > 0 && len(b) > h.max {
+			return ErrMessageTooLarge
+		}
+
+		// set body
+		r.Body.Close()
+		m.Body = b
+
 		// return early early
 		return nil
 	}
@@ -262,11 +284,6 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 		return err
 	}
 
-	// check if we have data
-	if n > 0 {
-		m.Body = buf[:n]
-	}
-
 	// set headers
 	for k, v := range h.r.Header {
 		if len(v) > 0 {
@@ -279,6 +296,15 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 	// set path
 	m.Header[":path"] = h.r.URL.Path
 
+	if h.max > 0 && n > h.max {
+		return ErrMessageTooLarge
+	}
+
+	// check if we have data
+	if n > 0 {
+		m.Body = buf[:n]
+	}
+
 	return nil
 }
 
@@ -385,11 +411,19 @@ func (h *httpTransportSocket) Close() error {
 }
 
 func (h *httpTransportListener) Addr() string {
-	return h.listener.Addr().String()
+	return mnet.Addr(h.listener.Addr())
+}
+
+// Drain closes the listener, connections already hijacked are left to finish
+func (h *httpTransportListener) Drain() error {
+	h.once.Do(func() {
+		h.err = h.listener.Close()
+	})
+	return h.err
 }
 
 func (h *httpTransportListener) Close() error {
-	return h.listener.Close()
+	return h.Drain()
 }
 
 func (h *httpTransportListener) Accept(fn func(Socket)) error {
@@ -446,6 +480,7 @@ func (h *httpTransportListener) Accept(fn func(Socket)) error {
 			local:  h.Addr(),
 			remote: r.RemoteAddr,
 			closed: make(chan bool),
+			max:    h.max,
 		}
 
 		// execute the socket
@@ -498,11 +533,11 @@ func (h *httpTransport) Dial(addr string, opts ...DialOption) (Client, error) {
 		}
 		config.NextProtos = []string{"http/1.1"}
 		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, "tcp", addr, config)
+			return h.dial(addr, dopts.Timeout, config)
 		})(addr)
 	} else {
 		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return net.DialTimeout("tcp", addr, dopts.Timeout)
+			return h.dial(addr, dopts.Timeout, nil)
 		})(addr)
 	}
 
@@ -522,12 +557,62 @@ func (h *httpTransport) Dial(addr string, opts ...DialOption) (Client, error) {
 	}, nil
 }
 
+// dial connects to the addr with the options of the sockets, within the
+// timeout if any, and secures the connection with the tls config if not nil
+func (h *httpTransport) dial(addr string, timeout time.Duration, config *tls.Config) (net.Conn, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := mnet.DialSocket(ctx, addr, h.opts.Socket)
+	if err != nil || config == nil {
+		return conn, err
+	}
+
+	// verify the host dialled unless told otherwise, as tls.Dial does
+	if len(config.ServerName) == 0 && !config.InsecureSkipVerify {
+		_, address := mnet.Network(addr)
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	// the handshake is within the timeout too
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	tc := tls.Client(conn, config)
+	if err := tc.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
 func (h *httpTransport) Listen(addr string, opts ...ListenOption) (Listener, error) {
 	var options ListenOptions
 	for _, o := range opts {
 		o(&options)
 	}
 
+	// serve on the listener passed in rather than binding the address
+	if h.opts.Context != nil {
+		if l, ok := h.opts.Context.Value("http_listener").(net.Listener); ok && l != nil {
+			return &httpTransportListener{
+				ht:       h,
+				listener: l,
+			}, nil
+		}
+	}
+
 	var l net.Listener
 	var err error
 
@@ -555,13 +640,17 @@ func (h *httpTransport) Listen(addr string, opts ...ListenOption) (Listener, err
 				}
 				config = &tls.Config{Certificates: []tls.Certificate{cert}}
 			}
-			return tls.Listen("tcp", addr, config)
+			l, err := mnet.ListenSocket(addr, h.opts.Socket)
+			if err != nil {
+				return nil, err
+			}
+			return tls.NewListener(l, config), nil
 		}
 
 		l, err = mnet.Listen(addr, fn)
 	} else {
 		fn := func(addr string) (net.Listener, error) {
-			return net.Listen("tcp", addr)
+			return mnet.ListenSocket(addr, h.opts.Socket)
 		}
 
 		l, err = mnet.Listen(addr, fn)
@@ -574,6 +663,7 @@ func (h *httpTransport) Listen(addr string, opts ...ListenOption) (Listener, err
 	return &httpTransportListener{
 		ht:       h,
 		listener: l,
+		max:      options.MaxMessageSize,
 	}, nil
 }
 
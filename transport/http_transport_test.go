@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"fmt"
 	"io"
 	"net"
 	"testing"
@@ -244,3 +245,63 @@ func TestHTTPTransportTimeout(t *testing.T) {
 
 	<-done
 }
+
+func TestHTTPTransportMaxMessageSize(t *testing.T) {
+	tr := NewTransport()
+
+	l, err := tr.Listen("127.0.0.1:0", MaxMessageSize(10))
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	errs := make(chan error, 2)
+	fn := func(sock Socket) {
+		defer sock.Close()
+
+		for {
+			var m Message
+			err := sock.Recv(&m)
+			errs <- err
+			if err != nil {
+				if m.Header["Micro-Id"] != "2" {
+					t.Errorf("Expected the headers of the message too large, got %v", m.Header)
+				}
+				sock.Send(&Message{Header: m.Header})
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	}
+
+	go l.Accept(fn)
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer c.Close()
+
+	for i, body := range []string{"small", "over the max size"} {
+		m := Message{
+			Header: map[string]string{"Micro-Id": fmt.Sprintf("%d", i+1)},
+			Body:   []byte(body),
+		}
+		if err := c.Send(&m); err != nil {
+			t.Fatalf("Unexpected send err: %v", err)
+		}
+		var rm Message
+		if err := c.Recv(&rm); err != nil {
+			t.Fatalf("Unexpected recv err: %v", err)
+		}
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+	if err := <-errs; err != ErrMessageTooLarge {
+		t.Fatalf("Expected ErrMessageTooLarge, got %v", err)
+	}
+}
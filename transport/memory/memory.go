@@ -38,8 +38,10 @@ type memoryClient struct {
 }
 
 type memoryListener struct {
-	addr  string
-	exit  chan bool
+	addr string
+	exit chan bool
+	// closed when the listener stops accepting connections
+	drain chan bool
 	conn  chan *memorySocket
 	lopts transport.ListenOptions
 	topts transport.Options
@@ -124,6 +126,19 @@ func (m *memoryListener) Addr() string {
 	return m.addr
 }
 
+// Drain stops accepting connections, existing sockets stay open until the listener is closed
+func (m *memoryListener) Drain() error {
+	m.Lock()
+	defer m.Unlock()
+	select {
+	case <-m.drain:
+		return nil
+	default:
+		close(m.drain)
+	}
+	return nil
+}
+
 func (m *memoryListener) Close() error {
 	m.Lock()
 	defer m.Unlock()
@@ -141,6 +156,8 @@ func (m *memoryListener) Accept(fn func(transport.Socket)) error {
 		select {
 		case <-m.exit:
 			return nil
+		case <-m.drain:
+			return nil
 		case c := <-m.conn:
 			go fn(&memorySocket{
 				lexit:   c.lexit,
@@ -188,6 +205,8 @@ func (m *memoryTransport) Dial(addr string, opts ...transport.DialOption) (trans
 	select {
 	case <-listener.exit:
 		return nil, errors.New("connection error")
+	case <-listener.drain:
+		return nil, errors.New("connection error")
 	case listener.conn <- client.memorySocket:
 	}
 
@@ -232,6 +251,7 @@ func (m *memoryTransport) Listen(addr string, opts ...transport.ListenOption) (t
 		addr:  addr,
 		conn:  make(chan *memorySocket),
 		exit:  make(chan bool),
+		drain: make(chan bool),
 		ctx:   m.opts.Context,
 	}
 
@@ -92,3 +92,55 @@ func TestListener(t *testing.T) {
 		t.Fatal("Expected error binding to :8080 got nil")
 	}
 }
+
+func TestListenerDrain(t *testing.T) {
+	tr := NewTransport()
+
+	l, err := tr.Listen("127.0.0.1:8081")
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Accept(func(sock transport.Socket) {
+			for {
+				var m transport.Message
+				if err := sock.Recv(&m); err != nil {
+					return
+				}
+				if err := sock.Send(&m); err != nil {
+					return
+				}
+			}
+		})
+	}()
+
+	c, err := tr.Dial("127.0.0.1:8081")
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	if err := l.(*memoryListener).Drain(); err != nil {
+		t.Fatalf("Unexpected error draining %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error accepting %v", err)
+	}
+
+	// new connections are refused
+	if _, err := tr.Dial("127.0.0.1:8081"); err == nil {
+		t.Fatal("Expected dial to a drained listener to fail")
+	}
+
+	// existing connections are still served
+	if err := c.Send(&transport.Message{Body: []byte(`ping`)}); err != nil {
+		t.Fatalf("Unexpected error sending %v", err)
+	}
+	var m transport.Message
+	if err := c.Recv(&m); err != nil {
+		t.Fatalf("Unexpected error receiving %v", err)
+	}
+}
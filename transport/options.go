@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/micro/go-micro/v2/codec"
+	mnet "github.com/micro/go-micro/v2/util/net"
 )
 
 type Options struct {
@@ -23,6 +24,9 @@ type Options struct {
 	TLSConfig *tls.Config
 	// Timeout sets the timeout for Send/Recv
 	Timeout time.Duration
+	// Socket are the options of the tcp sockets of the listeners and
+	// the connections
+	Socket mnet.SocketOptions
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -47,6 +51,10 @@ type ListenOptions struct {
 	// TODO: add tls options when listening
 	// Currently set in global options
 
+	// MaxMessageSize is the max size in bytes of a message received by the
+	// sockets of the listener, zero is unlimited
+	MaxMessageSize int
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -89,6 +97,51 @@ func TLSConfig(t *tls.Config) Option {
 	}
 }
 
+// TLSCertificate sets the func returning the certificate of the listeners on
+// each handshake, e.g. the GetCertificate func of a tls.Reloader, so the
+// certificate can be rotated without restarting them
+func TLSCertificate(fn func(*tls.ClientHelloInfo) (*tls.Certificate, error)) Option {
+	return func(o *Options) {
+		config := new(tls.Config)
+		if o.TLSConfig != nil {
+			config = o.TLSConfig.Clone()
+		}
+		config.GetCertificate = fn
+		o.TLSConfig = config
+	}
+}
+
+// ReusePort lets several processes listen on the same port, so a new
+// instance can be started before the old one is stopped
+func ReusePort(b bool) Option {
+	return func(o *Options) {
+		o.Socket.ReusePort = b
+	}
+}
+
+// KeepAlive sets the period of the tcp keep alives of the connections,
+// negative to disable them
+func KeepAlive(d time.Duration) Option {
+	return func(o *Options) {
+		o.Socket.KeepAlive = d
+	}
+}
+
+// NoDelay sets TCP_NODELAY on the connections, the default, false to
+// coalesce their small writes
+func NoDelay(b bool) Option {
+	return func(o *Options) {
+		o.Socket.Delay = !b
+	}
+}
+
+// Backlog sets the size of the queue of the connections pending accept
+func Backlog(n int) Option {
+	return func(o *Options) {
+		o.Socket.Backlog = n
+	}
+}
+
 // Indicates whether this is a streaming connection
 func WithStream() DialOption {
 	return func(o *DialOptions) {
@@ -102,3 +155,12 @@ func WithTimeout(d time.Duration) DialOption {
 		o.Timeout = d
 	}
 }
+
+// MaxMessageSize sets the max size in bytes of a message received by the
+// sockets of a listener. Recv stops reading a body over the max size and
+// returns ErrMessageTooLarge with the headers of the message.
+func MaxMessageSize(n int) ListenOption {
+	return func(o *ListenOptions) {
+		o.MaxMessageSize = n
+	}
+}
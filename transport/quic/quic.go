@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/gob"
+	"sync"
 	"time"
 
 	quic "github.com/lucas-clemente/quic-go"
@@ -33,6 +34,10 @@ type quicListener struct {
 	l    quic.Listener
 	t    *quicTransport
 	opts transport.ListenOptions
+
+	// the listener is closed once by either drain or close
+	once sync.Once
+	err  error
 }
 
 func (q *quicClient) Close() error {
@@ -66,8 +71,16 @@ func (q *quicListener) Addr() string {
 	return q.l.Addr().String()
 }
 
+// Drain closes the listener, sessions already accepted are left to finish
+func (q *quicListener) Drain() error {
+	q.once.Do(func() {
+		q.err = q.l.Close()
+	})
+	return q.err
+}
+
 func (q *quicListener) Close() error {
-	return q.l.Close()
+	return q.Drain()
 }
 
 func (q *quicListener) Accept(fn func(transport.Socket)) error {
@@ -2,9 +2,16 @@
 package transport
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	// ErrMessageTooLarge is returned by Recv when the body of a message is
+	// over the max size of the listener
+	ErrMessageTooLarge = errors.New("message too large")
+)
+
 // Transport is an interface which is used for communication between
 // services. It uses connection based socket send/recv semantics and
 // has various implementations; http, grpc, quic.
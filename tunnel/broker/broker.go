@@ -163,6 +163,13 @@ func (t *tunEvent) Ack() error {
 	return nil
 }
 
+func (t *tunEvent) Nack(requeue bool) error {
+	if requeue {
+		return broker.UnsupportedDelivery("tunnel", broker.AtLeastOnce)
+	}
+	return nil
+}
+
 func (t *tunEvent) Error() error {
 	return nil
 }
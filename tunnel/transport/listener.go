@@ -1,20 +1,34 @@
 package transport
 
 import (
+	"sync"
+
 	"github.com/micro/go-micro/v2/transport"
 	"github.com/micro/go-micro/v2/tunnel"
 )
 
 type tunListener struct {
 	l tunnel.Listener
+
+	// the listener is closed once by either drain or close
+	once sync.Once
+	err  error
 }
 
 func (t *tunListener) Addr() string {
 	return t.l.Channel()
 }
 
+// Drain closes the tunnel listener
+func (t *tunListener) Drain() error {
+	t.once.Do(func() {
+		t.err = t.l.Close()
+	})
+	return t.err
+}
+
 func (t *tunListener) Close() error {
-	return t.l.Close()
+	return t.Drain()
 }
 
 func (t *tunListener) Accept(fn func(socket transport.Socket)) error {
@@ -69,7 +69,7 @@ func (t *tunTransport) Listen(addr string, opts ...transport.ListenOption) (tran
 		return nil, err
 	}
 
-	return &tunListener{l}, nil
+	return &tunListener{l: l}, nil
 }
 
 func (t *tunTransport) Options() transport.Options {
@@ -0,0 +1,140 @@
+// Package compress negotiates the compression of message bodies
+package compress
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/micro/go-micro/v2/transport"
+)
+
+var (
+	// EncodingHeader is the header of the encoding of a compressed body
+	EncodingHeader = "Content-Encoding"
+	// AcceptHeader is the header of the encodings of the bodies a peer
+	// accepts, in order of preference
+	AcceptHeader = "Accept-Encoding"
+	// MetadataKey is the node metadata key of the encodings a server accepts
+	MetadataKey = "compression"
+	// DefaultMinSize is the size in bytes of the smallest body compressed
+	DefaultMinSize = 1024
+	// DefaultMaxSize is the max size in bytes a body is decompressed to,
+	// unless Decode is given a max size
+	DefaultMaxSize = 64 << 20
+
+	// ErrTooLarge is returned when a body decompresses to more than the
+	// max size, so a small body can't exhaust the memory
+	ErrTooLarge = errors.New("decompressed body exceeds the max size")
+
+	mtx         sync.RWMutex
+	compressors = map[string]Compressor{}
+)
+
+// Compressor compresses message bodies e.g. gzip. Compressors other than
+// gzip, like zstd, are registered with Register.
+type Compressor interface {
+	Compress(b []byte) ([]byte, error)
+	// Decompress the body, failing with ErrTooLarge as soon as it
+	// decompresses to more than max bytes
+	Decompress(b []byte, max int) ([]byte, error)
+	String() string
+}
+
+// Register a compressor by the name of its encoding. The grpc client and
+// server compress with the compressors registered with grpc/encoding.
+func Register(c Compressor) {
+	mtx.Lock()
+	compressors[c.String()] = c
+	mtx.Unlock()
+}
+
+// Get the compressor of the encoding
+func Get(name string) (Compressor, bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	c, ok := compressors[name]
+	return c, ok
+}
+
+// Supported returns the encodings registered, as a comma separated list
+func Supported() string {
+	mtx.RLock()
+	names := make([]string, 0, len(compressors))
+	for name := range compressors {
+		names = append(names, name)
+	}
+	mtx.RUnlock()
+
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// Negotiate returns the compressor of the first encoding of the comma
+// separated list which is registered
+func Negotiate(accept string) (Compressor, bool) {
+	for _, name := range strings.Split(accept, ",") {
+		if c, ok := Get(strings.TrimSpace(name)); ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Accepts returns whether the comma separated list of encodings has the encoding
+func Accepts(accept, name string) bool {
+	for _, n := range strings.Split(accept, ",") {
+		if strings.TrimSpace(n) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode compresses the body of the message with the compressor, unless
+// it's smaller than DefaultMinSize, and sets the encoding header
+func Encode(msg *transport.Message, c Compressor) error {
+	delete(msg.Header, EncodingHeader)
+
+	if c == nil || len(msg.Body) < DefaultMinSize {
+		return nil
+	}
+
+	b, err := c.Compress(msg.Body)
+	if err != nil {
+		return err
+	}
+
+	msg.Body = b
+	msg.Header[EncodingHeader] = c.String()
+	return nil
+}
+
+// Decode decompresses the body of the message, if its encoding header is set,
+// up to max bytes, or DefaultMaxSize if max is zero or less
+func Decode(msg *transport.Message, max int) error {
+	name, ok := msg.Header[EncodingHeader]
+	if !ok {
+		return nil
+	}
+
+	c, ok := Get(name)
+	if !ok {
+		return fmt.Errorf("unsupported encoding %s", name)
+	}
+
+	if max <= 0 {
+		max = DefaultMaxSize
+	}
+
+	b, err := c.Decompress(msg.Body, max)
+	if err != nil {
+		return err
+	}
+
+	msg.Body = b
+	delete(msg.Header, EncodingHeader)
+	return nil
+}
@@ -0,0 +1,185 @@
+package compress_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/micro/go-micro/v2/broker"
+	bmemory "github.com/micro/go-micro/v2/broker/memory"
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/registry"
+	rmemory "github.com/micro/go-micro/v2/registry/memory"
+	"github.com/micro/go-micro/v2/router"
+	"github.com/micro/go-micro/v2/server"
+	"github.com/micro/go-micro/v2/transport"
+	tmemory "github.com/micro/go-micro/v2/transport/memory"
+	"github.com/micro/go-micro/v2/util/compress"
+)
+
+func TestEncode(t *testing.T) {
+	body := bytes.Repeat([]byte("foo"), compress.DefaultMinSize)
+	msg := &transport.Message{
+		Header: map[string]string{},
+		Body:   body,
+	}
+
+	if err := compress.Encode(msg, compress.Gzip()); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header[compress.EncodingHeader] != "gzip" || len(msg.Body) >= len(body) {
+		t.Fatalf("Expected the body to be gzipped, got %d bytes encoded %q", len(msg.Body), msg.Header[compress.EncodingHeader])
+	}
+
+	if err := compress.Decode(msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(msg.Body, body) {
+		t.Fatal("Expected the body to be decompressed")
+	}
+	if _, ok := msg.Header[compress.EncodingHeader]; ok {
+		t.Fatal("Expected the encoding header to be removed")
+	}
+
+	// small bodies aren't compressed
+	msg.Body = []byte("foo")
+	if err := compress.Encode(msg, compress.Gzip()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := msg.Header[compress.EncodingHeader]; ok || string(msg.Body) != "foo" {
+		t.Fatal("Expected a small body not to be compressed")
+	}
+
+	// a body decompressing to more than the max is rejected
+	bomb := &transport.Message{
+		Header: map[string]string{},
+		Body:   make([]byte, 1<<20),
+	}
+	if err := compress.Encode(bomb, compress.Gzip()); err != nil {
+		t.Fatal(err)
+	}
+	if err := compress.Decode(bomb, 1024); err != compress.ErrTooLarge {
+		t.Fatalf("Expected the body to be too large, got %v", err)
+	}
+
+	// unknown encodings can't be decoded
+	msg.Header[compress.EncodingHeader] = "unknown"
+	if err := compress.Decode(msg, 0); err == nil {
+		t.Fatal("Expected an error decoding an unknown encoding")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	if c, ok := compress.Negotiate("zstd, gzip"); !ok || c.String() != "gzip" {
+		t.Fatal("Expected gzip to be negotiated")
+	}
+	if _, ok := compress.Negotiate("zstd"); ok {
+		t.Fatal("Expected an unregistered encoding not to be negotiated")
+	}
+	if _, ok := compress.Negotiate(""); ok {
+		t.Fatal("Expected no encoding to be negotiated")
+	}
+	if !compress.Accepts("gzip,zstd", "zstd") || compress.Accepts("gzip", "zstd") {
+		t.Fatal("Unexpected accepted encodings")
+	}
+}
+
+type TestFoo struct{}
+
+type TestReq struct {
+	Data string
+}
+
+type TestRsp struct {
+	Data string
+}
+
+func (h *TestFoo) Echo(ctx context.Context, req *TestReq, rsp *TestRsp) error {
+	rsp.Data = req.Data
+	return nil
+}
+
+// recordTransport records the encodings of the messages sent by the client
+type recordTransport struct {
+	transport.Transport
+
+	sync.Mutex
+	encodings []string
+}
+
+type recordClient struct {
+	transport.Client
+	t *recordTransport
+}
+
+func (r *recordTransport) Dial(addr string, opts ...transport.DialOption) (transport.Client, error) {
+	c, err := r.Transport.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &recordClient{c, r}, nil
+}
+
+func (r *recordClient) Send(m *transport.Message) error {
+	r.t.Lock()
+	r.t.encodings = append(r.t.encodings, m.Header[compress.EncodingHeader])
+	r.t.Unlock()
+	return r.Client.Send(m)
+}
+
+func TestCompression(t *testing.T) {
+	reg := rmemory.NewRegistry()
+	brk := bmemory.NewBroker(broker.Registry(reg))
+	tr := tmemory.NewTransport()
+
+	srv := server.NewServer(
+		server.Broker(brk),
+		server.Registry(reg),
+		server.Name("go.micro.service.foo"),
+		server.Address("127.0.0.1:0"),
+		server.Transport(tr),
+	)
+	if err := srv.Handle(srv.NewHandler(&TestFoo{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	rt := &recordTransport{Transport: tr}
+	cli := client.NewClient(
+		client.Router(router.NewRouter(router.Registry(reg))),
+		client.Broker(brk),
+		client.Transport(rt),
+		client.ContentType("application/json"),
+		client.Compression("gzip"),
+	)
+
+	data := strings.Repeat("foo", compress.DefaultMinSize)
+	req := cli.NewRequest("go.micro.service.foo", "TestFoo.Echo", &TestReq{Data: data})
+
+	// the routes are in the network of the registry domain
+	network := client.WithNetwork(registry.DefaultDomain)
+
+	rsp := &TestRsp{}
+	if err := cli.Call(context.TODO(), req, rsp, network); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Data != data {
+		t.Fatal("Expected the data to be echoed")
+	}
+
+	// compression can be turned off per call
+	if err := cli.Call(context.TODO(), req, rsp, network, client.WithCompression("")); err != nil {
+		t.Fatal(err)
+	}
+
+	rt.Lock()
+	defer rt.Unlock()
+	if len(rt.encodings) != 2 || rt.encodings[0] != "gzip" || rt.encodings[1] != "" {
+		t.Fatalf("Expected the first request only to be gzipped, got encodings %q", rt.encodings)
+	}
+}
@@ -0,0 +1,67 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+func init() {
+	Register(Gzip())
+}
+
+type gzipCompressor struct {
+	writers sync.Pool
+}
+
+// Gzip returns the gzip compressor
+func Gzip() Compressor {
+	return &gzipCompressor{
+		writers: sync.Pool{
+			New: func() interface{} {
+				return gzip.NewWriter(nil)
+			},
+		},
+	}
+}
+
+func (g *gzipCompressor) Compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := g.writers.Get().(*gzip.Writer)
+	defer g.writers.Put(w)
+	w.Reset(&buf)
+
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress reads one byte past the max at most, so the bodies which
+// decompress to more are rejected before they're inflated
+func (g *gzipCompressor) Decompress(b []byte, max int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	d, err := ioutil.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(d) > max {
+		return nil, ErrTooLarge
+	}
+	return d, nil
+}
+
+func (g *gzipCompressor) String() string {
+	return "gzip"
+}
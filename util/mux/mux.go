@@ -43,7 +43,7 @@ func New(name string, p proxy.Proxy) *Server {
 		server.DefaultRouter.Handle(
 			// inject the debug handler
 			server.DefaultRouter.NewHandler(
-				handler.NewHandler(client.DefaultClient),
+				handler.NewServerHandler(client.DefaultClient, server.DefaultServer),
 				server.InternalHandler(true),
 			),
 		)
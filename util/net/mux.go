@@ -0,0 +1,303 @@
+package net
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMuxClosed is returned by the listeners of a closed mux
+	ErrMuxClosed = errors.New("mux closed")
+
+	// DefaultSniffTimeout is how long a connection has to send enough
+	// bytes for its protocol to be detected
+	DefaultSniffTimeout = 5 * time.Second
+
+	// http2Preface is sent first by HTTP/2 clients with prior knowledge e.g. grpc
+	http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+	// maxRequestLine is the max length of an HTTP/1 request line read when sniffing
+	maxRequestLine = 4096
+)
+
+// Matcher detects the protocol of a connection from the first bytes read from it
+type Matcher func(io.Reader) bool
+
+// HTTP2 matches HTTP/2 connections with prior knowledge, as used by grpc and h2c
+func HTTP2() Matcher {
+	return func(r io.Reader) bool {
+		return hasPrefix(r, http2Preface)
+	}
+}
+
+// HTTP1 matches HTTP/1 connections, as used by the mucp http transport
+func HTTP1() Matcher {
+	return HTTP1Path()
+}
+
+// HTTP1Path matches HTTP/1 connections whose first request path has one of the
+// prefixes e.g. HTTP1Path("/health") for health checks. Without prefixes it
+// matches any HTTP/1 connection.
+func HTTP1Path(prefixes ...string) Matcher {
+	return func(r io.Reader) bool {
+		line, err := bufio.NewReaderSize(io.LimitReader(r, int64(maxRequestLine)), maxRequestLine).ReadString('\n')
+		if err != nil {
+			return false
+		}
+
+		// method path proto
+		parts := strings.Fields(line)
+		if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/1.") {
+			return false
+		}
+
+		if len(prefixes) == 0 {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(parts[1], prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Any matches every connection, use it last as a fallback
+func Any() Matcher {
+	return func(io.Reader) bool {
+		return true
+	}
+}
+
+// hasPrefix reads from r until it has read the prefix, returning early on a mismatch
+// so connections sending fewer bytes than the prefix don't block
+func hasPrefix(r io.Reader, prefix []byte) bool {
+	buf := make([]byte, len(prefix))
+	var n int
+	for n < len(prefix) {
+		i, err := r.Read(buf[n:])
+		if !bytes.Equal(buf[n:n+i], prefix[n:n+i]) {
+			return false
+		}
+		n += i
+		if err != nil {
+			return n == len(prefix)
+		}
+	}
+	return true
+}
+
+// Mux serves several protocols on one listener by sniffing the first bytes
+// of every connection and passing it to the listener of the first matching
+// protocol e.g. grpc, the mucp http transport and health checks on one port:
+//
+//	m := NewMux(l)
+//	grpcL := m.Match(HTTP2())
+//	healthL := m.Match(HTTP1Path("/health"))
+//	httpL := m.Match(HTTP1())
+//	go m.Serve()
+//
+// Connections which match none of the protocols are closed.
+type Mux struct {
+	root    net.Listener
+	timeout time.Duration
+
+	sync.RWMutex
+	matches []*muxListener
+	closed  bool
+}
+
+// NewMux returns a mux serving the connections accepted by l
+func NewMux(l net.Listener) *Mux {
+	return &Mux{
+		root:    l,
+		timeout: DefaultSniffTimeout,
+	}
+}
+
+// SetTimeout sets how long a connection has to send enough bytes for its
+// protocol to be detected, zero for no timeout
+func (m *Mux) SetTimeout(d time.Duration) {
+	m.Lock()
+	m.timeout = d
+	m.Unlock()
+}
+
+// Match returns a listener accepting the connections matched by any of the
+// matchers. Matchers are tried in the order they were added.
+func (m *Mux) Match(matchers ...Matcher) net.Listener {
+	l := &muxListener{
+		mux:      m,
+		matchers: matchers,
+		conns:    make(chan net.Conn),
+		done:     make(chan struct{}),
+	}
+
+	m.Lock()
+	m.matches = append(m.matches, l)
+	m.Unlock()
+
+	return l
+}
+
+// Serve accepts connections until the root listener fails or the mux is closed
+func (m *Mux) Serve() error {
+	defer m.closeListeners()
+
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			m.RLock()
+			closed := m.closed
+			m.RUnlock()
+			if closed {
+				return ErrMuxClosed
+			}
+			return err
+		}
+
+		go m.serve(conn)
+	}
+}
+
+// Close closes the root listener and the listeners of the protocols
+func (m *Mux) Close() error {
+	m.Lock()
+	m.closed = true
+	m.Unlock()
+
+	m.closeListeners()
+	return m.root.Close()
+}
+
+func (m *Mux) closeListeners() {
+	m.RLock()
+	defer m.RUnlock()
+
+	for _, l := range m.matches {
+		l.Close()
+	}
+}
+
+// serve sniffs the protocol of the connection and hands it to the matching listener
+func (m *Mux) serve(conn net.Conn) {
+	m.RLock()
+	timeout := m.timeout
+	matches := m.matches
+	m.RUnlock()
+
+	if timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+
+	s := &sniffer{src: conn}
+
+	for _, l := range matches {
+		for _, match := range l.matchers {
+			s.reset()
+			if !match(s) {
+				continue
+			}
+
+			if timeout > 0 {
+				conn.SetReadDeadline(time.Time{})
+			}
+
+			// replay the sniffed bytes to the protocol
+			sc := &sniffedConn{
+				Conn: conn,
+				r:    io.MultiReader(bytes.NewReader(s.buf.Bytes()), conn),
+			}
+
+			select {
+			case l.conns <- sc:
+			case <-l.done:
+				conn.Close()
+			}
+			return
+		}
+	}
+
+	conn.Close()
+}
+
+// sniffer records the bytes read from the connection so they can be read
+// again by every matcher
+type sniffer struct {
+	src io.Reader
+	buf bytes.Buffer
+	off int
+	err error
+}
+
+func (s *sniffer) reset() {
+	s.off = 0
+}
+
+func (s *sniffer) Read(p []byte) (int, error) {
+	if s.off < s.buf.Len() {
+		n := copy(p, s.buf.Bytes()[s.off:])
+		s.off += n
+		return n, nil
+	}
+
+	// a failed read fails every matcher after it
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	n, err := s.src.Read(p)
+	s.buf.Write(p[:n])
+	s.off += n
+	s.err = err
+	return n, err
+}
+
+// sniffedConn is a connection whose sniffed bytes are read first
+type sniffedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *sniffedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// muxListener accepts the connections of a protocol
+type muxListener struct {
+	mux      *Mux
+	matchers []Matcher
+	conns    chan net.Conn
+
+	once sync.Once
+	done chan struct{}
+}
+
+func (l *muxListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, ErrMuxClosed
+	}
+}
+
+// Close stops accepting connections, the connections of the protocol are closed
+// from then on rather than passed to another protocol
+func (l *muxListener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *muxListener) Addr() net.Addr {
+	return l.mux.root.Addr()
+}
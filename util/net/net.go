@@ -1,16 +1,24 @@
 package net
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
 	"os"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
 // HostPort format addr and port suitable for dial
 func HostPort(addr string, port interface{}) string {
+	// unix domain sockets have no port
+	if strings.HasPrefix(addr, UnixScheme) {
+		return addr
+	}
+
 	host := addr
 	if strings.Count(addr, ":") > 0 {
 		host = fmt.Sprintf("[%s]", addr)
@@ -25,9 +33,58 @@ func HostPort(addr string, port interface{}) string {
 	return fmt.Sprintf("%s:%v", host, port)
 }
 
+// UnixScheme prefixes the addresses of unix domain sockets
+// Example: unix:///var/run/service.sock
+const UnixScheme = "unix://"
+
+// Network returns the network and address to listen on or dial for the addr,
+// unix for the addresses of unix domain sockets and tcp otherwise
+func Network(addr string) (string, string) {
+	if strings.HasPrefix(addr, UnixScheme) {
+		return "unix", strings.TrimPrefix(addr, UnixScheme)
+	}
+	return "tcp", addr
+}
+
+// Addr returns the address of a listener or connection, prefixed with the
+// unix scheme for unix domain sockets so it can be dialled
+func Addr(a net.Addr) string {
+	if a.Network() == "unix" {
+		return UnixScheme + a.String()
+	}
+	return a.String()
+}
+
+// Dial connects to the addr, over a unix domain socket for unix addresses
+func Dial(ctx context.Context, addr string) (net.Conn, error) {
+	return DialSocket(ctx, addr, SocketOptions{})
+}
+
+// stale checks if no process listens on the unix domain socket anymore, the
+// connections to it being refused
+func stale(path string) bool {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return false
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
 // Listen takes addr:portmin-portmax and binds to the first available port
 // Example: Listen("localhost:5000-6000", fn)
+//
+// The addresses of unix domain sockets are bound as is, once the socket left
+// by a previous process is removed. The socket of a process still listening
+// on it is left in place, so binding it fails.
+// Example: Listen("unix:///var/run/service.sock", fn)
 func Listen(addr string, fn func(string) (net.Listener, error)) (net.Listener, error) {
+	if network, path := Network(addr); network == "unix" {
+		if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 && stale(path) {
+			os.Remove(path)
+		}
+		return fn(addr)
+	}
 
 	if strings.Count(addr, ":") == 1 && strings.Count(addr, "-") == 0 {
 		return fn(addr)
@@ -1,9 +1,13 @@
 package net
 
 import (
+	"context"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestListen(t *testing.T) {
@@ -26,7 +30,113 @@ func TestListen(t *testing.T) {
 
 }
 
+func TestListenUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "net")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	addr := UnixScheme + filepath.Join(dir, "service.sock")
+	fn := func(addr string) (net.Listener, error) {
+		return net.Listen(Network(addr))
+	}
+
+	l, err := Listen(addr, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := Addr(l.Addr()); a != addr {
+		t.Fatalf("Expected address %s, got %s", addr, a)
+	}
+	if a := HostPort(addr, ""); a != addr {
+		t.Fatalf("Expected host port %s, got %s", addr, a)
+	}
+
+	go func(l net.Listener) {
+		if c, err := l.Accept(); err == nil {
+			c.Close()
+		}
+	}(l)
+	c, err := Dial(context.TODO(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	// the socket of a listener still listening is left in place
+	if _, err := Listen(addr, fn); err == nil {
+		t.Fatal("Expected the socket in use not to be bound again")
+	}
+	go func(l net.Listener) {
+		if c, err := l.Accept(); err == nil {
+			c.Close()
+		}
+	}(l)
+	c, err = Dial(context.TODO(), addr)
+	if err != nil {
+		t.Fatalf("Expected the socket in use to be kept, got %v", err)
+	}
+	c.Close()
+
+	// the socket left by a previous listener is removed
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	l, err = Listen(addr, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+}
+
 // TestProxyEnv checks whether we have proxy/network settings in env
+func TestListenSocket(t *testing.T) {
+	opts := SocketOptions{
+		ReusePort: true,
+		KeepAlive: time.Minute,
+		Delay:     true,
+		Backlog:   16,
+	}
+
+	l, err := ListenSocket("127.0.0.1:0", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// another instance listens on the same port
+	l2, err := ListenSocket(l.Addr().String(), opts)
+	if err != nil {
+		t.Fatalf("Expected to reuse the port, got %v", err)
+	}
+	l2.Close()
+
+	// unless the port isn't reused
+	if l3, err := ListenSocket(l.Addr().String(), SocketOptions{}); err == nil {
+		l3.Close()
+		t.Fatal("Expected the port to be in use")
+	}
+
+	accepted := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err == nil {
+			c.Close()
+		}
+		accepted <- err
+	}()
+
+	c, err := DialSocket(context.TODO(), l.Addr().String(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := <-accepted; err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestProxyEnv(t *testing.T) {
 	service := "foo"
 	address := []string{"bar"}
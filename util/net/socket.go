@@ -0,0 +1,85 @@
+package net
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// SocketOptions are the options of the tcp sockets of the listeners and the
+// connections. The zero value keeps the defaults of the platform.
+type SocketOptions struct {
+	// ReusePort sets SO_REUSEPORT on the listeners so several processes can
+	// bind the same port, e.g. to restart without downtime
+	ReusePort bool
+	// KeepAlive is the period of the tcp keep alives of the connections,
+	// the default if zero and disabled if negative
+	KeepAlive time.Duration
+	// Delay disables TCP_NODELAY, set by default, so the small writes of
+	// the connections are coalesced
+	Delay bool
+	// Backlog is the size of the queue of the connections pending accept,
+	// the default of the platform if zero
+	Backlog int
+}
+
+// ListenSocket listens on the addr with the options of the sockets. The
+// addresses of unix domain sockets are listened on as is, see Network.
+func ListenSocket(addr string, o SocketOptions) (net.Listener, error) {
+	network, address := Network(addr)
+
+	lc := net.ListenConfig{KeepAlive: o.KeepAlive}
+	if o.ReusePort && network == "tcp" {
+		lc.Control = reusePort
+	}
+
+	l, err := lc.Listen(context.Background(), network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Backlog > 0 {
+		if err := backlog(l, o.Backlog); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	if o.Delay {
+		return &delayListener{l}, nil
+	}
+	return l, nil
+}
+
+// DialSocket connects to the addr with the options of the sockets, over a
+// unix domain socket for unix addresses
+func DialSocket(ctx context.Context, addr string, o SocketOptions) (net.Conn, error) {
+	d := net.Dialer{KeepAlive: o.KeepAlive}
+	network, address := Network(addr)
+
+	c, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if tc, ok := c.(*net.TCPConn); ok && o.Delay {
+		tc.SetNoDelay(false)
+	}
+	return c, nil
+}
+
+// delayListener disables TCP_NODELAY on the connections accepted
+type delayListener struct {
+	net.Listener
+}
+
+func (d *delayListener) Accept() (net.Conn, error) {
+	c, err := d.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tc, ok := c.(*net.TCPConn); ok {
+		tc.SetNoDelay(false)
+	}
+	return c, nil
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package net
+
+import (
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reusePort sets SO_REUSEPORT on the socket before it's bound
+func reusePort(network, address string, c syscall.RawConn) error {
+	var serr error
+	err := c.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
+
+// backlog listens on the socket of the listener again with the size of the
+// backlog, which updates the size of its queue
+func backlog(l net.Listener, n int) error {
+	sc, ok := l.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+	c, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var lerr error
+	err = c.Control(func(fd uintptr) {
+		lerr = unix.Listen(int(fd), n)
+	})
+	if err != nil {
+		return err
+	}
+	return lerr
+}
@@ -0,0 +1,18 @@
+package net
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// reusePort isn't supported on windows, where SO_REUSEADDR lets processes
+// steal the port rather than share it
+func reusePort(network, address string, c syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on windows")
+}
+
+// backlog is left to the default on windows
+func backlog(l net.Listener, n int) error {
+	return nil
+}
@@ -9,30 +9,51 @@ import (
 )
 
 type pool struct {
-	size int
-	ttl  time.Duration
-	tr   transport.Transport
+	size  int
+	ttl   time.Duration
+	idle  time.Duration
+	check CheckFunc
+	tr    transport.Transport
+
+	exit chan bool
+	once sync.Once
 
 	sync.Mutex
 	conns map[string][]*poolConn
+	stats Stats
 }
 
 type poolConn struct {
 	transport.Client
 	id      string
 	created time.Time
+	// time it was released to the pool
+	used time.Time
 }
 
 func newPool(options Options) *pool {
-	return &pool{
+	p := &pool{
 		size:  options.Size,
 		tr:    options.Transport,
 		ttl:   options.TTL,
+		idle:  options.IdleTimeout,
+		check: options.Check,
+		exit:  make(chan bool),
 		conns: make(map[string][]*poolConn),
 	}
+
+	if options.CheckInterval > 0 {
+		go p.run(options.CheckInterval)
+	}
+
+	return p
 }
 
 func (p *pool) Close() error {
+	p.once.Do(func() {
+		close(p.exit)
+	})
+
 	p.Lock()
 	for k, c := range p.conns {
 		for _, conn := range c {
@@ -40,6 +61,7 @@ func (p *pool) Close() error {
 		}
 		delete(p.conns, k)
 	}
+	p.stats.Idle = 0
 	p.Unlock()
 	return nil
 }
@@ -57,6 +79,14 @@ func (p *poolConn) Created() time.Time {
 	return p.created
 }
 
+// expired returns true if the conn is too old or idle for too long
+func (p *pool) expired(conn *poolConn) bool {
+	if time.Since(conn.created) > p.ttl {
+		return true
+	}
+	return p.idle > 0 && time.Since(conn.used) > p.idle
+}
+
 func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 	p.Lock()
 	conns := p.conns[addr]
@@ -67,14 +97,18 @@ func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 		conn := conns[len(conns)-1]
 		conns = conns[:len(conns)-1]
 		p.conns[addr] = conns
+		p.stats.Idle--
 
 		// if conn is old kill it and move on
-		if d := time.Since(conn.Created()); d > p.ttl {
+		if p.expired(conn) {
 			conn.Client.Close()
+			p.stats.Evicted++
 			continue
 		}
 
 		// we got a good conn, lets unlock and return it
+		p.stats.InUse++
+		p.stats.Reused++
 		p.Unlock()
 
 		return conn, nil
@@ -87,6 +121,12 @@ func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	p.Lock()
+	p.stats.InUse++
+	p.stats.Dialed++
+	p.Unlock()
+
 	return &poolConn{
 		Client:  c,
 		id:      uuid.New().String(),
@@ -95,20 +135,93 @@ func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 }
 
 func (p *pool) Release(conn Conn, err error) error {
+	p.Lock()
+	p.stats.InUse--
+
 	// don't store the conn if it has errored
 	if err != nil {
+		p.stats.Failed++
+		p.Unlock()
 		return conn.(*poolConn).Client.Close()
 	}
 
 	// otherwise put it back for reuse
-	p.Lock()
 	conns := p.conns[conn.Remote()]
 	if len(conns) >= p.size {
 		p.Unlock()
 		return conn.(*poolConn).Client.Close()
 	}
-	p.conns[conn.Remote()] = append(conns, conn.(*poolConn))
+	pc := conn.(*poolConn)
+	pc.used = time.Now()
+	p.conns[conn.Remote()] = append(conns, pc)
+	p.stats.Idle++
 	p.Unlock()
 
 	return nil
 }
+
+func (p *pool) Stats() Stats {
+	p.Lock()
+	defer p.Unlock()
+	return p.stats
+}
+
+// evict the idle conns which expired or fail the health check
+func (p *pool) evict() {
+	p.Lock()
+	addrs := make([]string, 0, len(p.conns))
+	for addr := range p.conns {
+		addrs = append(addrs, addr)
+	}
+	p.Unlock()
+
+	for _, addr := range addrs {
+		// take the conns out of the pool while checking them
+		p.Lock()
+		conns := p.conns[addr]
+		delete(p.conns, addr)
+		p.stats.Idle -= len(conns)
+		p.Unlock()
+
+		healthy := conns[:0]
+		for _, conn := range conns {
+			if p.expired(conn) || (p.check != nil && p.check(conn) != nil) {
+				conn.Client.Close()
+				p.Lock()
+				p.stats.Evicted++
+				p.Unlock()
+				continue
+			}
+			healthy = append(healthy, conn)
+		}
+
+		// put the healthy conns back, along with the ones released since
+		p.Lock()
+		for _, conn := range healthy {
+			if len(p.conns[addr]) >= p.size {
+				conn.Client.Close()
+				continue
+			}
+			p.conns[addr] = append(p.conns[addr], conn)
+			p.stats.Idle++
+		}
+		if len(p.conns[addr]) == 0 {
+			delete(p.conns, addr)
+		}
+		p.Unlock()
+	}
+}
+
+func (p *pool) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.exit:
+			return
+		case <-t.C:
+			p.evict()
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package pool
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -87,3 +88,103 @@ func TestClientPool(t *testing.T) {
 	testPool(t, 0, time.Minute)
 	testPool(t, 2, time.Minute)
 }
+
+func TestPoolEviction(t *testing.T) {
+	tr := memory.NewTransport()
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		l.Accept(func(s transport.Socket) {
+			var msg transport.Message
+			for s.Recv(&msg) == nil {
+			}
+		})
+	}()
+
+	broken := make(chan string, 1)
+	p := newPool(Options{
+		TTL:           time.Minute,
+		Size:          2,
+		Transport:     tr,
+		IdleTimeout:   time.Minute,
+		CheckInterval: 10 * time.Millisecond,
+		Check: func(c Conn) error {
+			select {
+			case id := <-broken:
+				if id == c.Id() {
+					return errors.New("broken")
+				}
+				broken <- id
+			default:
+			}
+			return nil
+		},
+	})
+	defer p.Close()
+
+	c1, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := p.Stats(); s.InUse != 2 || s.Dialed != 2 {
+		t.Fatalf("Expected 2 conns dialed in use, got %+v", s)
+	}
+
+	p.Release(c1, nil)
+	p.Release(c2, nil)
+
+	// the broken conn is evicted by the health check
+	broken <- c1.Id()
+	time.Sleep(100 * time.Millisecond)
+
+	s := p.Stats()
+	if s.Idle != 1 || s.InUse != 0 || s.Evicted != 1 {
+		t.Fatalf("Expected the broken conn to be evicted, got %+v", s)
+	}
+
+	// the conn idle for too long is evicted
+	p.Lock()
+	for _, c := range p.conns[l.Addr()] {
+		c.used = time.Now().Add(-time.Hour)
+	}
+	p.Unlock()
+
+	c3, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3.Id() == c2.Id() {
+		t.Fatal("Expected the idle conn to be evicted")
+	}
+	if s := p.Stats(); s.Evicted != 2 || s.Reused != 0 || s.Dialed != 3 {
+		t.Fatalf("Expected the idle conn to be evicted, got %+v", s)
+	}
+
+	// the errored conn isn't pooled
+	p.Release(c3, errors.New("failed"))
+	if s := p.Stats(); s.Failed != 1 || s.Idle != 0 {
+		t.Fatalf("Expected the conn to be closed, got %+v", s)
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	p := NewPool(Transport(memory.NewTransport()))
+	defer p.Close()
+
+	if _, ok := ReadStats(p); !ok {
+		t.Fatal("Expected the stats of the default pool")
+	}
+	if _, ok := ReadStats(struct{ Pool }{p}); ok {
+		t.Fatal("Expected no stats of a pool not reporting them")
+	}
+}
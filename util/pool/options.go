@@ -10,6 +10,14 @@ type Options struct {
 	Transport transport.Transport
 	TTL       time.Duration
 	Size      int
+	// IdleTimeout is the time after which an idle connection is evicted
+	IdleTimeout time.Duration
+	// CheckInterval is the interval of the health checks of the idle
+	// connections, they aren't checked if zero
+	CheckInterval time.Duration
+	// Check is the health check of the idle connections, the connections
+	// are only checked for their age and idle time if nil
+	Check CheckFunc
 }
 
 type Option func(*Options)
@@ -31,3 +39,19 @@ func TTL(t time.Duration) Option {
 		o.TTL = t
 	}
 }
+
+// IdleTimeout sets the time after which an idle connection is evicted
+func IdleTimeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.IdleTimeout = t
+	}
+}
+
+// HealthCheck checks the idle connections at the interval, evicting the ones
+// too old, idle for too long or failing the check func if not nil
+func HealthCheck(interval time.Duration, fn CheckFunc) Option {
+	return func(o *Options) {
+		o.CheckInterval = interval
+		o.Check = fn
+	}
+}
@@ -17,6 +17,43 @@ type Pool interface {
 	Release(c Conn, status error) error
 }
 
+// Statser is implemented by the pools reporting the stats of their
+// connections
+type Statser interface {
+	Stats() Stats
+}
+
+// ReadStats returns the stats of the connections of the pool, false if the
+// pool doesn't report them
+func ReadStats(p Pool) (Stats, bool) {
+	s, ok := p.(Statser)
+	if !ok {
+		return Stats{}, false
+	}
+	return s.Stats(), true
+}
+
+// Stats of the connections of a pool
+type Stats struct {
+	// Idle is the number of connections in the pool
+	Idle int
+	// InUse is the number of connections out of the pool
+	InUse int
+	// Dialed is the number of connections dialed
+	Dialed uint64
+	// Reused is the number of connections got from the pool
+	Reused uint64
+	// Evicted is the number of idle connections closed for being too old,
+	// idle for too long or failing the health check
+	Evicted uint64
+	// Failed is the number of connections closed after an error
+	Failed uint64
+}
+
+// CheckFunc checks the health of an idle connection, the connections
+// failing it are evicted from the pool
+type CheckFunc func(c Conn) error
+
 type Conn interface {
 	// unique id of connection
 	Id() string
@@ -2,6 +2,8 @@
 package proto
 
 import (
+	"time"
+
 	"github.com/micro/go-micro/v2/router"
 	pbRtr "github.com/micro/go-micro/v2/router/service/proto"
 )
@@ -16,6 +18,8 @@ func RouteToProto(route router.Route) *pbRtr.Route {
 		Router:  route.Router,
 		Link:    route.Link,
 		Metric:  int64(route.Metric),
+		Origin:  route.Origin,
+		Hops:    route.Hops,
 	}
 }
 
@@ -29,5 +33,36 @@ func ProtoToRoute(route *pbRtr.Route) router.Route {
 		Router:  route.Router,
 		Link:    route.Link,
 		Metric:  route.Metric,
+		Origin:  route.Origin,
+		Hops:    route.Hops,
+	}
+}
+
+// SnapshotToProto encodes routing table snapshot into protobuf and returns it.
+// Unlike RouteToProto the route metadata is kept so the snapshot is a full copy.
+func SnapshotToProto(s *router.Snapshot) *pbRtr.Snapshot {
+	routes := make([]*pbRtr.Route, len(s.Routes))
+	for i, route := range s.Routes {
+		routes[i] = RouteToProto(route)
+		routes[i].Metadata = route.Metadata
+	}
+
+	return &pbRtr.Snapshot{
+		Timestamp: s.Timestamp.Unix(),
+		Routes:    routes,
+	}
+}
+
+// ProtoToSnapshot decodes protobuf snapshot into routing table snapshot and returns it
+func ProtoToSnapshot(s *pbRtr.Snapshot) *router.Snapshot {
+	routes := make([]router.Route, len(s.Routes))
+	for i, route := range s.Routes {
+		routes[i] = ProtoToRoute(route)
+		routes[i].Metadata = route.Metadata
+	}
+
+	return &router.Snapshot{
+		Timestamp: time.Unix(s.Timestamp, 0),
+		Routes:    routes,
 	}
 }
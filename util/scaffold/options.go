@@ -0,0 +1,81 @@
+package scaffold
+
+// Options are the options used to generate a service
+type Options struct {
+	// Alias is the short name of the service e.g. greeter
+	Alias string
+	// Namespace of the service e.g. go.micro
+	Namespace string
+	// Type of the service e.g. service, api or web
+	Type string
+	// Module is the go module path, the alias by default
+	Module string
+	// Dir to generate the service in, the alias by default
+	Dir string
+	// Plugins imported by the service e.g. registry/etcd
+	Plugins []string
+	// Files generated from templates
+	Files []File
+}
+
+// Option sets an option
+type Option func(o *Options)
+
+// Alias sets the short name of the service
+func Alias(a string) Option {
+	return func(o *Options) {
+		o.Alias = a
+	}
+}
+
+// Namespace sets the namespace of the service
+func Namespace(n string) Option {
+	return func(o *Options) {
+		o.Namespace = n
+	}
+}
+
+// Type sets the type of the service
+func Type(t string) Option {
+	return func(o *Options) {
+		o.Type = t
+	}
+}
+
+// Module sets the go module path
+func Module(m string) Option {
+	return func(o *Options) {
+		o.Module = m
+	}
+}
+
+// Dir sets the directory to generate the service in
+func Dir(d string) Option {
+	return func(o *Options) {
+		o.Dir = d
+	}
+}
+
+// Plugins imports the plugins in the service. Plugins are given as a path
+// in github.com/micro/go-plugins e.g. registry/etcd or a full import path.
+func Plugins(p ...string) Option {
+	return func(o *Options) {
+		o.Plugins = append(o.Plugins, p...)
+	}
+}
+
+// Files replaces the files generated, e.g. to standardise a team's layout
+func Files(f ...File) Option {
+	return func(o *Options) {
+		o.Files = f
+	}
+}
+
+// DefaultOptions returns the default options
+func DefaultOptions() Options {
+	return Options{
+		Namespace: DefaultNamespace,
+		Type:      DefaultType,
+		Files:     DefaultFiles,
+	}
+}
@@ -0,0 +1,220 @@
+// Package scaffold generates the skeleton of a new service from templates
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var (
+	// DefaultNamespace of generated services
+	DefaultNamespace = "go.micro"
+	// DefaultType of generated services
+	DefaultType = "service"
+	// PluginsPath is the import path of plugins given without a host
+	PluginsPath = "github.com/micro/go-plugins"
+
+	// ErrInvalidAlias is returned when the alias can't be used as a service name
+	ErrInvalidAlias = errors.New("invalid alias")
+	// ErrExists is returned when the directory to generate the service in exists
+	ErrExists = errors.New("directory already exists")
+)
+
+// File is a file generated from a template. The path is relative to the
+// service directory and, like the template, is executed with the Config.
+type File struct {
+	Path     string
+	Template string
+}
+
+// Config is the data templates are executed with
+type Config struct {
+	// Alias is the short name of the service e.g. greeter
+	Alias string
+	// Namespace of the service e.g. go.micro
+	Namespace string
+	// Type of the service e.g. service
+	Type string
+	// FQDN is the full name of the service e.g. go.micro.service.greeter
+	FQDN string
+	// Module is the go module path
+	Module string
+	// Dir the service is generated in
+	Dir string
+	// Plugins are the import paths of the plugins
+	Plugins []string
+}
+
+// NewConfig returns the config templates are executed with for the options
+func NewConfig(opts ...Option) (Config, error) {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if !validAlias(options.Alias) {
+		return Config{}, fmt.Errorf("%w: %q", ErrInvalidAlias, options.Alias)
+	}
+
+	c := Config{
+		Alias:     options.Alias,
+		Namespace: options.Namespace,
+		Type:      options.Type,
+		FQDN:      strings.Join([]string{options.Namespace, options.Type, options.Alias}, "."),
+		Module:    options.Module,
+		Dir:       options.Dir,
+	}
+
+	if len(c.Module) == 0 {
+		c.Module = c.Alias
+	}
+	if len(c.Dir) == 0 {
+		c.Dir = c.Alias
+	}
+
+	for _, p := range options.Plugins {
+		c.Plugins = append(c.Plugins, pluginPath(p))
+	}
+	sort.Strings(c.Plugins)
+
+	return c, nil
+}
+
+// Render executes the file templates and returns the contents by path
+func Render(opts ...Option) (map[string][]byte, error) {
+	options := DefaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+
+	c, err := NewConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(options.Files))
+	for _, f := range options.Files {
+		p, err := execute(f.Path, f.Path, c)
+		if err != nil {
+			return nil, err
+		}
+		path := string(p)
+		b, err := execute(path, f.Template, c)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = b
+	}
+
+	return files, nil
+}
+
+// Generate renders the files and writes them to the service directory which
+// must not exist. It returns the paths of the files written.
+func Generate(opts ...Option) ([]string, error) {
+	c, err := NewConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(c.Dir); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExists, c.Dir)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	files, err := Render(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	written := make([]string, 0, len(paths))
+	for _, path := range paths {
+		file := filepath.Join(c.Dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return written, err
+		}
+		if err := ioutil.WriteFile(file, files[path], 0644); err != nil {
+			return written, err
+		}
+		written = append(written, file)
+	}
+
+	return written, nil
+}
+
+// execute executes the template with the config
+func execute(name, text string, c Config) ([]byte, error) {
+	t, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %v", name, err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, c); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %v", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// funcs are the functions available to templates
+var funcs = template.FuncMap{
+	// title returns the alias as an exported go identifier e.g. foo-bar is FooBar
+	"title": func(s string) string {
+		var b strings.Builder
+		upper := true
+		for _, r := range s {
+			if r == '-' || r == '_' {
+				upper = true
+				continue
+			}
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	},
+	// dehyphen returns the alias as a go package name e.g. foo-bar is foobar
+	"dehyphen": func(s string) string {
+		return strings.Replace(s, "-", "", -1)
+	},
+}
+
+// validAlias checks the alias is a lowercase name usable as a package and service name
+func validAlias(alias string) bool {
+	if len(alias) == 0 || !unicode.IsLetter(rune(alias[0])) {
+		return false
+	}
+	for _, r := range alias {
+		if !(r >= 'a' && r <= 'z') && !unicode.IsDigit(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// pluginPath returns the import path of a plugin
+func pluginPath(p string) string {
+	// import paths start with a host e.g. github.com
+	if i := strings.Index(p, "/"); i > 0 && strings.Contains(p[:i], ".") {
+		return p
+	}
+	return PluginsPath + "/" + strings.Trim(p, "/") + "/v2"
+}
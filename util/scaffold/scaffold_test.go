@@ -0,0 +1,84 @@
+package scaffold
+
+import (
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	files, err := Render(
+		Alias("foo-bar"),
+		Module("github.com/acme/foo-bar"),
+		Plugins("registry/etcd", "github.com/acme/plugins/broker"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"main.go", "plugin.go", "handler/foo-bar.go", "subscriber/foo-bar.go", "proto/foo-bar/foo-bar.proto", "Dockerfile"} {
+		if _, ok := files[path]; !ok {
+			t.Errorf("expected %s to be generated", path)
+		}
+	}
+
+	// the go files must be valid and formatted
+	for path, b := range files {
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		f, err := format.Source(b)
+		if err != nil {
+			t.Errorf("invalid go in %s: %v\n%s", path, err, b)
+		} else if string(f) != string(b) {
+			t.Errorf("%s is not formatted:\n%s", path, b)
+		}
+	}
+
+	main := string(files["main.go"])
+	if !strings.Contains(main, `micro.Name("go.micro.service.foo-bar")`) {
+		t.Errorf("expected the service name in main.go:\n%s", main)
+	}
+	if !strings.Contains(main, "foobar.RegisterFooBarHandler") {
+		t.Errorf("expected the handler registered in main.go:\n%s", main)
+	}
+
+	plugin := string(files["plugin.go"])
+	for _, p := range []string{"github.com/micro/go-plugins/registry/etcd/v2", "github.com/acme/plugins/broker"} {
+		if !strings.Contains(plugin, `_ "`+p+`"`) {
+			t.Errorf("expected plugin %s imported:\n%s", p, plugin)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	svc := filepath.Join(dir, "greeter")
+	paths, err := Generate(Alias("greeter"), Dir(svc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != len(DefaultFiles) {
+		t.Errorf("expected %d files, got %v", len(DefaultFiles), paths)
+	}
+	if _, err := os.Stat(filepath.Join(svc, "handler", "greeter.go")); err != nil {
+		t.Errorf("expected the handler to be written: %v", err)
+	}
+
+	// the directory is never overwritten
+	if _, err := Generate(Alias("greeter"), Dir(svc)); err == nil {
+		t.Error("expected an error generating into an existing directory")
+	}
+
+	if _, err := Generate(Alias("Greeter!"), Dir(filepath.Join(dir, "invalid"))); err == nil {
+		t.Error("expected an error for an invalid alias")
+	}
+}
@@ -0,0 +1,149 @@
+package scaffold
+
+// DefaultFiles are the files generated for a service
+var DefaultFiles = []File{
+	{"main.go", mainTemplate},
+	{"plugin.go", pluginTemplate},
+	{"handler/{{.Alias}}.go", handlerTemplate},
+	{"subscriber/{{.Alias}}.go", subscriberTemplate},
+	{"proto/{{.Alias}}/{{.Alias}}.proto", protoTemplate},
+	{"Dockerfile", dockerTemplate},
+	{"Makefile", makefileTemplate},
+	{"go.mod", moduleTemplate},
+	{".gitignore", gitignoreTemplate},
+}
+
+var (
+	mainTemplate = `package main
+
+import (
+	"github.com/micro/go-micro/v2"
+	log "github.com/micro/go-micro/v2/logger"
+
+	"{{.Module}}/handler"
+	{{dehyphen .Alias}} "{{.Module}}/proto/{{.Alias}}"
+	"{{.Module}}/subscriber"
+)
+
+func main() {
+	// New Service
+	service := micro.NewService(
+		micro.Name("{{.FQDN}}"),
+		micro.Version("latest"),
+	)
+
+	// Initialise service
+	service.Init()
+
+	// Register Handler
+	{{dehyphen .Alias}}.Register{{title .Alias}}Handler(service.Server(), new(handler.{{title .Alias}}))
+
+	// Register Struct as Subscriber
+	micro.RegisterSubscriber("{{.FQDN}}", service.Server(), new(subscriber.{{title .Alias}}))
+
+	// Run service
+	if err := service.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+	pluginTemplate = `package main
+{{if .Plugins}}
+import ({{range .Plugins}}
+	_ "{{.}}"{{end}}
+)
+{{end}}`
+
+	handlerTemplate = `package handler
+
+import (
+	"context"
+
+	log "github.com/micro/go-micro/v2/logger"
+
+	{{dehyphen .Alias}} "{{.Module}}/proto/{{.Alias}}"
+)
+
+type {{title .Alias}} struct{}
+
+// Call is a single request handler called via client.Call or the generated client code
+func (e *{{title .Alias}}) Call(ctx context.Context, req *{{dehyphen .Alias}}.Request, rsp *{{dehyphen .Alias}}.Response) error {
+	log.Info("Received {{title .Alias}}.Call request")
+	rsp.Msg = "Hello " + req.Name
+	return nil
+}
+`
+
+	subscriberTemplate = `package subscriber
+
+import (
+	"context"
+
+	log "github.com/micro/go-micro/v2/logger"
+
+	{{dehyphen .Alias}} "{{.Module}}/proto/{{.Alias}}"
+)
+
+type {{title .Alias}} struct{}
+
+// Handle is called for each message published to the service topic
+func (e *{{title .Alias}}) Handle(ctx context.Context, msg *{{dehyphen .Alias}}.Message) error {
+	log.Info("Handler Received message: ", msg.Say)
+	return nil
+}
+`
+
+	protoTemplate = `syntax = "proto3";
+
+package {{.Namespace}}.{{.Type}}.{{dehyphen .Alias}};
+
+option go_package = "{{.Module}}/proto/{{.Alias}};{{dehyphen .Alias}}";
+
+service {{title .Alias}} {
+	rpc Call(Request) returns (Response) {}
+}
+
+message Message {
+	string say = 1;
+}
+
+message Request {
+	string name = 1;
+}
+
+message Response {
+	string msg = 1;
+}
+`
+
+	dockerTemplate = `FROM alpine
+ADD {{.Alias}} /{{.Alias}}
+ENTRYPOINT [ "/{{.Alias}}" ]
+`
+
+	makefileTemplate = `.PHONY: proto
+proto:
+	protoc --proto_path=. --micro_out=paths=source_relative:. --go_out=paths=source_relative:. proto/{{.Alias}}/{{.Alias}}.proto
+
+.PHONY: build
+build: proto
+	CGO_ENABLED=0 GOOS=linux go build -o {{.Alias}} .
+
+.PHONY: test
+test:
+	go test -v ./...
+
+.PHONY: docker
+docker: build
+	docker build . -t {{.Alias}}:latest
+`
+
+	moduleTemplate = `module {{.Module}}
+
+go 1.13
+`
+
+	gitignoreTemplate = `{{.Alias}}
+`
+)
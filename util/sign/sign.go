@@ -0,0 +1,208 @@
+// Package sign signs the bodies and metadata of requests and verifies their
+// signatures, protecting requests from tampering independently of the
+// transport security. The signatures are timestamped and rejected once they
+// are older than the max skew, which bounds how long a request captured can
+// be replayed for.
+package sign
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/micro/go-micro/v2/metadata"
+)
+
+var (
+	// SignatureHeader is the header of the signature of a request, the
+	// name of the signer followed by the base64 encoded signature
+	SignatureHeader = "Micro-Signature"
+	// HeadersHeader is the header of the comma separated names of the
+	// metadata signed
+	HeadersHeader = "Micro-Signature-Headers"
+	// TimestampHeader is the header of the unix time a request was signed at
+	TimestampHeader = "Micro-Signature-Timestamp"
+
+	// DefaultMaxSkew is how far the time a request was signed at may be
+	// from the time it's verified at
+	DefaultMaxSkew = 5 * time.Minute
+
+	// ErrInvalidSignature is returned when a signature doesn't match the
+	// request
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrMissingSignature is returned when a request isn't signed
+	ErrMissingSignature = errors.New("missing signature")
+	// ErrExpiredSignature is returned when a request was signed outside of
+	// the max skew
+	ErrExpiredSignature = errors.New("expired signature")
+)
+
+// Signer signs data and verifies signatures e.g. with a shared secret
+type Signer interface {
+	// Sign the data
+	Sign(data []byte) ([]byte, error)
+	// Verify the signature of the data
+	Verify(data, sig []byte) error
+	// String returns the name of the algorithm
+	String() string
+}
+
+type hmacSigner struct {
+	key []byte
+}
+
+func (h *hmacSigner) Sign(data []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (h *hmacSigner) Verify(data, sig []byte) error {
+	exp, _ := h.Sign(data)
+	if !hmac.Equal(exp, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (h *hmacSigner) String() string {
+	return "hmac-sha256"
+}
+
+// HMAC returns a signer of HMAC-SHA256 signatures with the key shared by the
+// clients and servers
+func HMAC(key []byte) Signer {
+	return &hmacSigner{key}
+}
+
+type ed25519Signer struct {
+	priv ed25519.PrivateKey
+	pub  ed25519.PublicKey
+}
+
+func (e *ed25519Signer) Sign(data []byte) ([]byte, error) {
+	if e.priv == nil {
+		return nil, errors.New("no private key to sign with")
+	}
+	return ed25519.Sign(e.priv, data), nil
+}
+
+func (e *ed25519Signer) Verify(data, sig []byte) error {
+	if !ed25519.Verify(e.pub, data, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (e *ed25519Signer) String() string {
+	return "ed25519"
+}
+
+// Ed25519 returns a signer of ed25519 signatures with the private key of the
+// client
+func Ed25519(priv ed25519.PrivateKey) Signer {
+	return &ed25519Signer{
+		priv: priv,
+		pub:  priv.Public().(ed25519.PublicKey),
+	}
+}
+
+// Ed25519Verifier returns a signer verifying ed25519 signatures with the
+// public key of the clients, it can't sign
+func Ed25519Verifier(pub ed25519.PublicKey) Signer {
+	return &ed25519Signer{pub: pub}
+}
+
+// Sign the body of the request to the endpoint of the service along with the
+// metadata of the headers and the time, returning the context with the
+// signature headers. The body is the bytes sent on the wire.
+func Sign(ctx context.Context, s Signer, service, endpoint string, body []byte, headers ...string) (context.Context, error) {
+	md, _ := metadata.FromContext(ctx)
+	names := make([]string, 0, len(headers))
+	for _, h := range headers {
+		names = append(names, strings.ToLower(h))
+	}
+	sort.Strings(names)
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	sig, err := s.Sign(payload(service, endpoint, ts, body, md, names))
+	if err != nil {
+		return ctx, err
+	}
+
+	return metadata.MergeContext(ctx, metadata.Metadata{
+		SignatureHeader: s.String() + " " + base64.StdEncoding.EncodeToString(sig),
+		HeadersHeader:   strings.Join(names, ","),
+		TimestampHeader: ts,
+	}, true), nil
+}
+
+// Verify the signature of the request in the metadata of the context against
+// the body received, rejecting signatures made more than DefaultMaxSkew away
+// from now
+func Verify(ctx context.Context, s Signer, service, endpoint string, body []byte) error {
+	md, _ := metadata.FromContext(ctx)
+
+	hdr, ok := lookup(md, SignatureHeader)
+	if !ok {
+		return ErrMissingSignature
+	}
+	parts := strings.SplitN(hdr, " ", 2)
+	if len(parts) != 2 || parts[0] != s.String() {
+		return ErrInvalidSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	ts, _ := lookup(md, TimestampHeader)
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if skew := time.Since(time.Unix(sec, 0)); skew > DefaultMaxSkew || skew < -DefaultMaxSkew {
+		return ErrExpiredSignature
+	}
+
+	var names []string
+	if v, _ := lookup(md, HeadersHeader); len(v) > 0 {
+		names = strings.Split(v, ",")
+	}
+
+	return s.Verify(payload(service, endpoint, ts, body, md, names), sig)
+}
+
+// payload returns the data signed, the service, endpoint, timestamp, names
+// and values of the headers and the body separated by new lines
+func payload(service, endpoint, ts string, body []byte, md metadata.Metadata, names []string) []byte {
+	var sb strings.Builder
+	sb.WriteString(service + "\n")
+	sb.WriteString(endpoint + "\n")
+	sb.WriteString(ts + "\n")
+	for _, n := range names {
+		v, _ := lookup(md, n)
+		sb.WriteString(n + ":" + v + "\n")
+	}
+	sb.Write(body)
+	return []byte(sb.String())
+}
+
+// lookup the header in the metadata regardless of its case, the transports
+// don't preserve it
+func lookup(md metadata.Metadata, name string) (string, bool) {
+	for k, v := range md {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
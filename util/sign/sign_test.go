@@ -0,0 +1,172 @@
+package sign
+
+import (
+	"context"
+	"crypto/ed25519"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/codec/bytes"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/metadata"
+	"github.com/micro/go-micro/v2/server"
+)
+
+func TestSign(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		name     string
+		signer   Signer
+		verifier Signer
+	}{
+		{"HMAC", HMAC([]byte("secret")), HMAC([]byte("secret"))},
+		{"Ed25519", Ed25519(priv), Ed25519Verifier(pub)},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			ctx := metadata.NewContext(context.TODO(), metadata.Metadata{"Micro-Tenant": "foo"})
+			ctx, err := Sign(ctx, d.signer, "go.micro.service.foo", "Foo.Bar", []byte(`{"Name":"john"}`), "Micro-Tenant")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// headers are lower cased by some transports
+			md, _ := metadata.FromContext(ctx)
+			lower := make(metadata.Metadata)
+			for k, v := range md {
+				lower[k] = v
+			}
+			vctx := metadata.NewContext(context.TODO(), lower)
+
+			if err := Verify(vctx, d.verifier, "go.micro.service.foo", "Foo.Bar", []byte(`{"Name":"john"}`)); err != nil {
+				t.Fatalf("Expected a valid signature, got %v", err)
+			}
+			if err := Verify(vctx, d.verifier, "go.micro.service.foo", "Foo.Bar", []byte(`{"Name":"jane"}`)); err != ErrInvalidSignature {
+				t.Fatalf("Expected a tampered body to be invalid, got %v", err)
+			}
+			if err := Verify(vctx, d.verifier, "go.micro.service.foo", "Foo.Delete", []byte(`{"Name":"john"}`)); err != ErrInvalidSignature {
+				t.Fatalf("Expected another endpoint to be invalid, got %v", err)
+			}
+
+			tctx := metadata.Set(vctx, "Micro-Tenant", "bar")
+			if err := Verify(tctx, d.verifier, "go.micro.service.foo", "Foo.Bar", []byte(`{"Name":"john"}`)); err != ErrInvalidSignature {
+				t.Fatalf("Expected tampered metadata to be invalid, got %v", err)
+			}
+
+			// a signature replayed after the max skew is rejected
+			old := strconv.FormatInt(time.Now().Add(-DefaultMaxSkew-time.Minute).Unix(), 10)
+			ectx := metadata.Set(vctx, TimestampHeader, old)
+			if err := Verify(ectx, d.verifier, "go.micro.service.foo", "Foo.Bar", []byte(`{"Name":"john"}`)); err != ErrExpiredSignature {
+				t.Fatalf("Expected an expired signature, got %v", err)
+			}
+
+			if err := Verify(context.TODO(), d.verifier, "go.micro.service.foo", "Foo.Bar", []byte(`{"Name":"john"}`)); err != ErrMissingSignature {
+				t.Fatalf("Expected a missing signature, got %v", err)
+			}
+		})
+	}
+
+	// a verifier can't sign
+	if _, err := Sign(context.TODO(), Ed25519Verifier(pub), "foo", "Foo.Bar", nil); err == nil {
+		t.Fatal("Expected an error signing without a private key")
+	}
+}
+
+type testBody struct {
+	Name string
+}
+
+type testRequest struct {
+	service  string
+	endpoint string
+	body     []byte
+
+	server.Request
+}
+
+type testClientRequest struct {
+	service  string
+	endpoint string
+	body     interface{}
+
+	client.Request
+}
+
+func (r *testClientRequest) Service() string {
+	return r.service
+}
+
+func (r *testClientRequest) Endpoint() string {
+	return r.endpoint
+}
+
+func (r *testClientRequest) ContentType() string {
+	return "application/json"
+}
+
+func (r *testClientRequest) Body() interface{} {
+	return r.body
+}
+
+func (r *testRequest) Service() string {
+	return r.service
+}
+
+func (r *testRequest) Endpoint() string {
+	return r.endpoint
+}
+
+func (r *testRequest) Read() ([]byte, error) {
+	return r.body, nil
+}
+
+func (r *testRequest) Stream() bool {
+	return false
+}
+
+func TestHandler(t *testing.T) {
+	s := HMAC([]byte("secret"))
+	creq := &testClientRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar", body: &testBody{"john"}}
+
+	var called bool
+	handler := Handler(s)(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		called = true
+		return nil
+	})
+
+	// the interceptor signs the bytes sent, which the handler verifies
+	var req *testRequest
+	invoker := func(ctx context.Context, r client.Request, rsp interface{}, opts ...client.CallOption) error {
+		f, ok := r.Body().(*bytes.Frame)
+		if !ok {
+			t.Fatalf("Expected the body sent as bytes, got %T", r.Body())
+		}
+		req = &testRequest{service: r.Service(), endpoint: r.Endpoint(), body: f.Data}
+		return handler(ctx, req, rsp)
+	}
+	if err := Interceptor(s)(context.TODO(), creq, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("Expected the handler to be called")
+	}
+
+	// unsigned requests are rejected
+	called = false
+	err := handler(context.TODO(), req, nil)
+	if verr := errors.FromError(err); verr.Code != 401 || called {
+		t.Fatalf("Expected unauthorized error but got %v", err)
+	}
+
+	// debug endpoints aren't verified
+	if err := handler(context.TODO(), &testRequest{service: "go.micro.service.foo", endpoint: "Debug.Health"}, nil); err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package sign
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/micro/go-micro/v2/client"
+	"github.com/micro/go-micro/v2/codec"
+	"github.com/micro/go-micro/v2/codec/bytes"
+	"github.com/micro/go-micro/v2/codec/json"
+	"github.com/micro/go-micro/v2/codec/proto"
+	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/server"
+)
+
+// marshalers of the content types whose codecs send the body as it's
+// marshalled, the bodies of the others are wrapped in envelopes
+var marshalers = map[string]codec.Marshaler{
+	"application/grpc":         proto.Marshaler{},
+	"application/grpc+proto":   proto.Marshaler{},
+	"application/grpc+json":    json.Marshaler{},
+	"application/protobuf":     proto.Marshaler{},
+	"application/json":         json.Marshaler{},
+	"application/octet-stream": bytes.Marshaler{},
+}
+
+// signedRequest sends the body signed as raw bytes so the bytes on the wire
+// are the ones signed
+type signedRequest struct {
+	client.Request
+	body *bytes.Frame
+}
+
+func (r *signedRequest) Body() interface{} {
+	return r.body
+}
+
+// encode the body of the request as the codec of its content type would
+func encode(req client.Request) ([]byte, error) {
+	switch b := req.Body().(type) {
+	case nil:
+		return nil, nil
+	case *bytes.Frame:
+		return b.Data, nil
+	}
+
+	m, ok := marshalers[req.ContentType()]
+	if !ok {
+		return nil, fmt.Errorf("can't sign requests of content type %s", req.ContentType())
+	}
+	b, err := m.Marshal(req.Body())
+	if err != nil {
+		return nil, err
+	}
+	// the marshalers reuse their buffers
+	return append([]byte(nil), b...), nil
+}
+
+// Interceptor signs the requests of the calls with the signer along with the
+// metadata of the headers, register it with client.Intercept. The body is
+// encoded once and sent as the bytes signed.
+func Interceptor(s Signer, headers ...string) client.UnaryInterceptor {
+	return func(ctx context.Context, req client.Request, rsp interface{}, invoker client.UnaryInvoker, opts ...client.CallOption) error {
+		b, err := encode(req)
+		if err != nil {
+			return errors.InternalServerError("go.micro.client", "Error signing request: %v", err)
+		}
+		ctx, err = Sign(ctx, s, req.Service(), req.Endpoint(), b, headers...)
+		if err != nil {
+			return errors.InternalServerError("go.micro.client", "Error signing request: %v", err)
+		}
+		if b != nil {
+			req = &signedRequest{Request: req, body: &bytes.Frame{Data: b}}
+		}
+		return invoker(ctx, req, rsp, opts...)
+	}
+}
+
+// StreamInterceptor signs the requests opening the streams with the signer
+// along with the metadata of the headers, register it with
+// client.InterceptStream. The messages sent on the streams aren't signed,
+// only the service, endpoint, headers and time the stream was opened at.
+func StreamInterceptor(s Signer, headers ...string) client.StreamInterceptor {
+	return func(ctx context.Context, req client.Request, streamer client.Streamer, opts ...client.CallOption) (client.Stream, error) {
+		ctx, err := Sign(ctx, s, req.Service(), req.Endpoint(), nil, headers...)
+		if err != nil {
+			return nil, errors.InternalServerError("go.micro.client", "Error signing request: %v", err)
+		}
+		return streamer(ctx, req, opts...)
+	}
+}
+
+// Handler wraps a server handler to verify the signatures of the requests
+// against the bodies received, rejecting unsigned, tampered or expired
+// requests. Debug endpoints aren't verified. The grpc server re-encodes the
+// bodies it received, so only requests encoded deterministically e.g.
+// without maps verify there.
+func Handler(s Signer) server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			if strings.HasPrefix(req.Endpoint(), "Debug.") {
+				return h(ctx, req, rsp)
+			}
+
+			// streams are signed without a body
+			var body []byte
+			if !req.Stream() {
+				b, err := req.Read()
+				if err != nil {
+					return errors.BadRequest(req.Service(), "Error reading request: %v", err)
+				}
+				body = b
+			}
+
+			if err := Verify(ctx, s, req.Service(), req.Endpoint(), body); err != nil {
+				return errors.Unauthorized(req.Service(), "Error verifying request signature: %v", err)
+			}
+
+			return h(ctx, req, rsp)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tls
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/micro/go-micro/v2/logger"
+)
+
+var (
+	// DefaultReloadInterval is the interval on which the certificate files
+	// are checked for changes
+	DefaultReloadInterval = time.Minute
+)
+
+// Reloader serves the certificate of a key pair loaded from disk, reloading
+// it once the files change, so short lived certificates rotate without
+// restarting the listeners using them
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	sync.RWMutex
+	cert *tls.Certificate
+	// modification times of the files loaded
+	certTime time.Time
+	keyTime  time.Time
+
+	once sync.Once
+	exit chan bool
+}
+
+// NewReloader loads the key pair of the files, and checks them for changes on
+// the interval, DefaultReloadInterval if zero, until stopped
+func NewReloader(certFile, keyFile string, interval time.Duration) (*Reloader, error) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		exit:     make(chan bool),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	go r.run(interval)
+	return r, nil
+}
+
+// changed returns true if the files were modified since they were loaded
+func (r *Reloader) changed() (bool, error) {
+	ci, err := os.Stat(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	ki, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+	return !ci.ModTime().Equal(r.certTime) || !ki.ModTime().Equal(r.keyTime), nil
+}
+
+func (r *Reloader) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			changed, err := r.changed()
+			if err == nil && changed {
+				err = r.Reload()
+			}
+			// keep serving the certificate loaded until the files are fixed
+			if err != nil && logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Failed to reload the certificate %s: %v", r.certFile, err)
+			}
+		case <-r.exit:
+			return
+		}
+	}
+}
+
+// Reload loads the key pair of the files now
+func (r *Reloader) Reload() error {
+	ci, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	ki, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	r.cert = &cert
+	r.certTime = ci.ModTime()
+	r.keyTime = ki.ModTime()
+	r.Unlock()
+
+	return nil
+}
+
+// GetCertificate returns the certificate loaded, to be set as the
+// GetCertificate func of the tls config of a server
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	return r.cert, nil
+}
+
+// GetClientCertificate returns the certificate loaded, to be set as the
+// GetClientCertificate func of the tls config of a client
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.RLock()
+	defer r.RUnlock()
+	return r.cert, nil
+}
+
+// Stop checking the files for changes
+func (r *Reloader) Stop() {
+	r.once.Do(func() {
+		close(r.exit)
+	})
+}
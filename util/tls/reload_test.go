@@ -0,0 +1,116 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKeyPair writes a new key pair to the files, returning its certificate
+func writeKeyPair(t *testing.T, certFile, keyFile string, mod time.Time) []byte {
+	cert, err := Certificate("localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key})
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return cert.Certificate[0]
+}
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	if _, err := NewReloader(certFile, keyFile, 0); err == nil {
+		t.Fatal("Expected an error loading missing files")
+	}
+
+	now := time.Now()
+	first := writeKeyPair(t, certFile, keyFile, now)
+
+	r, err := NewReloader(certFile, keyFile, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	// the listener serves the certificate loaded on each handshake
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{GetCertificate: r.GetCertificate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			c.(*tls.Conn).Handshake()
+			c.Close()
+		}
+	}()
+
+	served := func() []byte {
+		c, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		return c.ConnectionState().PeerCertificates[0].Raw
+	}
+
+	if string(served()) != string(first) {
+		t.Fatal("Expected the certificate loaded to be served")
+	}
+
+	// the new certificate is served once the files change
+	second := writeKeyPair(t, certFile, keyFile, now.Add(time.Second))
+	deadline := time.Now().Add(5 * time.Second)
+	for string(served()) != string(second) {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the new certificate to be served")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// an invalid key pair is not loaded, the last one is still served
+	if err := ioutil.WriteFile(keyFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("Expected an error loading an invalid key pair")
+	}
+	if string(served()) != string(second) {
+		t.Fatal("Expected the last certificate loaded to be served")
+	}
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// JWK is a JSON web key as defined in RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON web key set, the document served to external
+// systems so they can verify the tokens we issue
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// NewJWKS returns a key set for the base64 encoded PEM public keys
+func NewJWKS(keys ...string) (*JWKS, error) {
+	set := &JWKS{Keys: make([]*JWK, 0, len(keys))}
+
+	for _, k := range keys {
+		if len(k) == 0 {
+			continue
+		}
+
+		pub, err := parsePublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+
+		set.Keys = append(set.Keys, &JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: KeyID(pub),
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
+	}
+
+	return set, nil
+}
+
+// KeyID returns the RFC 7638 thumbprint of the key which is set
+// as the kid header of every token signed by it
+func KeyID(pub *rsa.PublicKey) string {
+	// the members must be in lexicographic order with no whitespace
+	b, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+	})
+
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// parsePublicKey decodes a base64 encoded PEM public key
+func parsePublicKey(key string) (*rsa.PublicKey, error) {
+	pub, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPublicKeyFromPEM(pub)
+}
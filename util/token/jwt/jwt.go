@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"crypto/rsa"
 	"encoding/base64"
 	"time"
 
@@ -56,6 +57,10 @@ func (j *JWT) Generate(acc *auth.Account, opts ...token.GenerateOption) (*token.
 			ExpiresAt: expiry.Unix(),
 		},
 	})
+
+	// identify the signing key so verifiers can select it from the key set
+	t.Header["kid"] = KeyID(&key.PublicKey)
+
 	tok, err := t.SignedString(key)
 	if err != nil {
 		return nil, err
@@ -72,15 +77,34 @@ func (j *JWT) Generate(acc *auth.Account, opts ...token.GenerateOption) (*token.
 // Inspect a JWT
 func (j *JWT) Inspect(t string) (*auth.Account, error) {
 	// decode the public key
-	pub, err := base64.StdEncoding.DecodeString(j.opts.PublicKey)
+	pub, err := parsePublicKey(j.opts.PublicKey)
 	if err != nil {
 		return nil, err
 	}
 
-	// parse the public key
-	res, err := jwt.ParseWithClaims(t, &authClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwt.ParseRSAPublicKeyFromPEM(pub)
-	})
+	keys := []*rsa.PublicKey{pub}
+	for _, k := range j.opts.VerificationKeys {
+		if key, err := parsePublicKey(k); err == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	// parse the token using the key it was signed with, tokens signed before
+	// a key rotation are verified using the previous keys. The tokens without
+	// a kid, e.g. issued by older versions, are tried with every key.
+	var res *jwt.Token
+	for _, key := range keys {
+		res, err = jwt.ParseWithClaims(t, &authClaims{}, func(tok *jwt.Token) (interface{}, error) {
+			kid, ok := tok.Header["kid"].(string)
+			if !ok || kid == KeyID(key) {
+				return key, nil
+			}
+			return nil, token.ErrInvalidToken
+		})
+		if err == nil {
+			break
+		}
+	}
 	if err != nil {
 		return nil, token.ErrInvalidToken
 	}
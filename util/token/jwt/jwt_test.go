@@ -1,10 +1,16 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"io/ioutil"
 	"testing"
 	"time"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/micro/go-micro/v2/auth"
 	"github.com/micro/go-micro/v2/util/token"
 )
@@ -85,3 +91,110 @@ func TestInspect(t *testing.T) {
 	})
 
 }
+
+func TestRotation(t *testing.T) {
+	pubKey, err := ioutil.ReadFile("test/sample_key.pub")
+	if err != nil {
+		t.Fatalf("Unable to read public key: %v", err)
+	}
+	privKey, err := ioutil.ReadFile("test/sample_key")
+	if err != nil {
+		t.Fatalf("Unable to read private key: %v", err)
+	}
+
+	// generate a new key to rotate to
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+	newPriv := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+	pubBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Unable to marshal public key: %v", err)
+	}
+	newPub := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pubBytes,
+	}))
+
+	old := NewTokenProvider(token.WithPrivateKey(string(privKey)))
+	tok, err := old.Generate(&auth.Account{ID: "test"})
+	if err != nil {
+		t.Fatalf("Generate returned %v error, expected nil", err)
+	}
+
+	t.Run("Without verification key", func(t *testing.T) {
+		j := NewTokenProvider(token.WithPublicKey(newPub), token.WithPrivateKey(newPriv))
+		if _, err := j.Inspect(tok.Token); err != token.ErrInvalidToken {
+			t.Fatalf("Inspect returned %v error, expected %v", err, token.ErrInvalidToken)
+		}
+	})
+
+	t.Run("With verification key", func(t *testing.T) {
+		j := NewTokenProvider(
+			token.WithPublicKey(newPub),
+			token.WithPrivateKey(newPriv),
+			token.WithVerificationKeys(string(pubKey)),
+		)
+		if _, err := j.Inspect(tok.Token); err != nil {
+			t.Fatalf("Inspect returned %v error, expected nil", err)
+		}
+
+		tok2, err := j.Generate(&auth.Account{ID: "test"})
+		if err != nil {
+			t.Fatalf("Generate returned %v error, expected nil", err)
+		}
+		if _, err := j.Inspect(tok2.Token); err != nil {
+			t.Fatalf("Inspect returned %v error, expected nil", err)
+		}
+	})
+
+	t.Run("Without kid", func(t *testing.T) {
+		oldKey, err := jwt.ParseRSAPrivateKeyFromPEM(mustDecode(t, string(privKey)))
+		if err != nil {
+			t.Fatalf("Unable to parse private key: %v", err)
+		}
+		claims := authClaims{StandardClaims: jwt.StandardClaims{Subject: "test", ExpiresAt: time.Now().Add(time.Minute).Unix()}}
+		unkeyed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(oldKey)
+		if err != nil {
+			t.Fatalf("Unable to sign token: %v", err)
+		}
+
+		// every key is tried for the tokens without a kid
+		j := NewTokenProvider(
+			token.WithPublicKey(newPub),
+			token.WithVerificationKeys(string(pubKey)),
+		)
+		acc, err := j.Inspect(unkeyed)
+		if err != nil {
+			t.Fatalf("Inspect returned %v error, expected nil", err)
+		}
+		if acc.ID != "test" {
+			t.Fatalf("Expected account test, got %v", acc.ID)
+		}
+	})
+
+	t.Run("Key set", func(t *testing.T) {
+		set, err := NewJWKS(newPub, string(pubKey))
+		if err != nil {
+			t.Fatalf("NewJWKS returned %v error, expected nil", err)
+		}
+		if len(set.Keys) != 2 {
+			t.Fatalf("Expected 2 keys, got %v", len(set.Keys))
+		}
+		if set.Keys[0].Kid != KeyID(&key.PublicKey) {
+			t.Fatalf("Expected kid %v, got %v", KeyID(&key.PublicKey), set.Keys[0].Kid)
+		}
+	})
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Unable to decode key: %v", err)
+	}
+	return b
+}
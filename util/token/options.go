@@ -13,6 +13,9 @@ type Options struct {
 	PublicKey string
 	// PrivateKey base64 encoded, used by JWT
 	PrivateKey string
+	// VerificationKeys are additional base64 encoded public keys
+	// accepted when inspecting a JWT, e.g. keys being rotated out
+	VerificationKeys []string
 }
 
 type Option func(o *Options)
@@ -38,6 +41,13 @@ func WithPrivateKey(key string) Option {
 	}
 }
 
+// WithVerificationKeys sets additional JWT public keys accepted during inspection
+func WithVerificationKeys(keys ...string) Option {
+	return func(o *Options) {
+		o.VerificationKeys = keys
+	}
+}
+
 func NewOptions(opts ...Option) Options {
 	var options Options
 	for _, o := range opts {
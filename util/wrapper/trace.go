@@ -0,0 +1,75 @@
+package wrapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// maxSummaryFields is the max number of request fields recorded on a span
+	maxSummaryFields = 16
+	// maxSummaryLength is the length string values are truncated to
+	maxSummaryLength = 32
+	// sensitiveFields are redacted when their name contains any of these words
+	sensitiveFields = []string{"password", "secret", "token", "key", "auth", "credential"}
+)
+
+// summarize returns a sanitized summary of the fields of a request body. Values
+// of fields which may contain credentials are redacted, strings are truncated and
+// collections are summarised by their length so no payload is copied into a span.
+func summarize(body interface{}) map[string]string {
+	v := reflect.ValueOf(body)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	summary := make(map[string]string)
+	t := v.Type()
+
+	for i := 0; i < t.NumField() && len(summary) < maxSummaryFields; i++ {
+		f := t.Field(i)
+		// skip unexported and generated protobuf fields
+		if len(f.PkgPath) > 0 || strings.HasPrefix(f.Name, "XXX_") {
+			continue
+		}
+		summary[f.Name] = summarizeValue(f.Name, v.Field(i))
+	}
+
+	return summary
+}
+
+func summarizeValue(name string, v reflect.Value) string {
+	lower := strings.ToLower(name)
+	for _, s := range sensitiveFields {
+		if strings.Contains(lower, s) {
+			return "[redacted]"
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if len(s) > maxSummaryLength {
+			return s[:maxSummaryLength] + "..."
+		}
+		return s
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("len=%d", v.Len())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return v.Elem().Type().String()
+	case reflect.Struct, reflect.Func, reflect.Chan:
+		return v.Type().String()
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
@@ -2,14 +2,19 @@ package wrapper
 
 import (
 	"context"
+	"net/http"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/broker"
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/debug/stats"
 	"github.com/micro/go-micro/v2/debug/trace"
 	"github.com/micro/go-micro/v2/errors"
+	"github.com/micro/go-micro/v2/logger"
 	"github.com/micro/go-micro/v2/metadata"
 	"github.com/micro/go-micro/v2/server"
 )
@@ -49,9 +54,31 @@ func (f *fromServiceWrapper) Publish(ctx context.Context, p client.Message, opts
 func FromService(name string, c client.Client) client.Client {
 	return &fromServiceWrapper{
 		c,
-		metadata.Metadata{
-			HeaderPrefix + "From-Service": name,
-		},
+		fromServiceHeaders(name),
+	}
+}
+
+func fromServiceHeaders(name string) metadata.Metadata {
+	return metadata.Metadata{
+		HeaderPrefix + "From-Service": name,
+	}
+}
+
+// FromServiceInterceptor injects the service metadata in the calls, register it
+// with client.Intercept. Publications aren't intercepted, use FromService for them.
+func FromServiceInterceptor(name string) client.UnaryInterceptor {
+	headers := fromServiceHeaders(name)
+	return func(ctx context.Context, req client.Request, rsp interface{}, invoker client.UnaryInvoker, opts ...client.CallOption) error {
+		return invoker(metadata.MergeContext(ctx, headers, false), req, rsp, opts...)
+	}
+}
+
+// FromServiceStreamInterceptor injects the service metadata in the streams,
+// register it with client.InterceptStream
+func FromServiceStreamInterceptor(name string) client.StreamInterceptor {
+	headers := fromServiceHeaders(name)
+	return func(ctx context.Context, req client.Request, streamer client.Streamer, opts ...client.CallOption) (client.Stream, error) {
+		return streamer(metadata.MergeContext(ctx, headers, false), req, opts...)
 	}
 }
 
@@ -79,18 +106,25 @@ type traceWrapper struct {
 }
 
 func (c *traceWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
-	newCtx, s := c.trace.Start(ctx, req.Service()+"."+req.Endpoint())
+	return TraceInterceptor(c.trace)(ctx, req, rsp, c.Client.Call, opts...)
+}
 
-	s.Type = trace.SpanTypeRequestOutbound
-	err := c.Client.Call(newCtx, req, rsp, opts...)
-	if err != nil {
-		s.Metadata["error"] = err.Error()
-	}
+// TraceInterceptor traces the calls, register it with client.Intercept
+func TraceInterceptor(t trace.Tracer) client.UnaryInterceptor {
+	return func(ctx context.Context, req client.Request, rsp interface{}, invoker client.UnaryInvoker, opts ...client.CallOption) error {
+		newCtx, s := t.Start(ctx, req.Service()+"."+req.Endpoint())
+
+		s.Type = trace.SpanTypeRequestOutbound
+		err := invoker(newCtx, req, rsp, opts...)
+		if err != nil {
+			s.Metadata["error"] = err.Error()
+		}
 
-	// finish the trace
-	c.trace.Finish(s)
+		// finish the trace
+		t.Finish(s)
 
-	return err
+		return err
+	}
 }
 
 // TraceCall is a call tracing wrapper
@@ -120,6 +154,16 @@ func TraceHandler(t trace.Tracer) server.HandlerWrapper {
 			err := h(newCtx, req, rsp)
 			if err != nil {
 				s.Metadata["error"] = err.Error()
+				s.Metadata["status"] = "error"
+			} else {
+				s.Metadata["status"] = "ok"
+			}
+
+			// capture a summary of the request when debugging
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				for k, v := range summarize(req.Body()) {
+					s.Metadata["request."+k] = v
+				}
 			}
 
 			// finish
@@ -133,19 +177,100 @@ func TraceHandler(t trace.Tracer) server.HandlerWrapper {
 type authWrapper struct {
 	client.Client
 	auth func() auth.Auth
+
+	// serialises the refreshes of the token
+	sync.Mutex
 }
 
 func (a *authWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
-	ctx = a.wrapContext(ctx, opts...)
-	return a.Client.Call(ctx, req, rsp, opts...)
+	wctx, ours := a.wrapContext(ctx, opts...)
+	err := a.Client.Call(wctx, req, rsp, opts...)
+	if !ours || !a.refresh(wctx, err) {
+		return err
+	}
+
+	// retry the call once with the refreshed token
+	wctx, _ = a.wrapContext(ctx, opts...)
+	return a.Client.Call(wctx, req, rsp, opts...)
 }
 
 func (a *authWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
-	ctx = a.wrapContext(ctx, opts...)
-	return a.Client.Stream(ctx, req, opts...)
+	wctx, ours := a.wrapContext(ctx, opts...)
+	stream, err := a.Client.Stream(wctx, req, opts...)
+	if !ours || !a.refresh(wctx, err) {
+		return stream, err
+	}
+
+	// reopen the stream once with the refreshed token
+	wctx, _ = a.wrapContext(ctx, opts...)
+	return a.Client.Stream(wctx, req, opts...)
 }
 
-func (a *authWrapper) wrapContext(ctx context.Context, opts ...client.CallOption) context.Context {
+// refresh the token of the service if the request made with the context
+// was rejected for an expired or invalid token, returning true if the
+// request can be retried with the new token
+func (a *authWrapper) refresh(ctx context.Context, err error) bool {
+	if !isInvalidToken(err) {
+		return false
+	}
+
+	aa := a.auth()
+	if aa == nil {
+		return false
+	}
+
+	// the token used by the request
+	used, _ := metadata.Get(ctx, "Authorization")
+	used = strings.TrimPrefix(used, auth.BearerScheme)
+
+	a.Lock()
+	defer a.Unlock()
+
+	// the token was refreshed by a concurrent request while waiting
+	aaOpts := aa.Options()
+	if tok := aaOpts.Token; tok != nil && !tok.Expired() && tok.AccessToken != used {
+		return true
+	}
+
+	var opts []auth.TokenOption
+	if aaOpts.Token != nil && len(aaOpts.Token.RefreshToken) > 0 {
+		opts = append(opts, auth.WithToken(aaOpts.Token.RefreshToken))
+	} else if len(aaOpts.ID) > 0 && len(aaOpts.Secret) > 0 {
+		opts = append(opts, auth.WithCredentials(aaOpts.ID, aaOpts.Secret))
+	} else {
+		return false
+	}
+
+	tok, terr := aa.Token(append(opts, auth.WithExpiry(time.Minute*10))...)
+	// the refresh token expired too, generate a token with the credentials
+	if terr != nil && len(aaOpts.ID) > 0 && len(aaOpts.Secret) > 0 && aaOpts.Token != nil {
+		tok, terr = aa.Token(auth.WithCredentials(aaOpts.ID, aaOpts.Secret), auth.WithExpiry(time.Minute*10))
+	}
+	if terr != nil {
+		logger.Warnf("[Auth] Error refreshing token: %v", terr)
+		return false
+	}
+
+	aa.Init(auth.ClientToken(tok))
+	return true
+}
+
+// isInvalidToken returns true if the error rejects an expired or invalid token
+func isInvalidToken(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == auth.ErrInvalidToken {
+		return true
+	}
+
+	verr := errors.FromError(err)
+	return verr.Code == http.StatusUnauthorized || strings.Contains(verr.Detail, auth.ErrInvalidToken.Error())
+}
+
+// wrapContext sets the auth headers of the request, returning false if the
+// authorization isn't the service's e.g. set by the caller
+func (a *authWrapper) wrapContext(ctx context.Context, opts ...client.CallOption) (context.Context, bool) {
 	// parse the options
 	var options client.CallOptions
 	for _, o := range opts {
@@ -156,14 +281,14 @@ func (a *authWrapper) wrapContext(ctx context.Context, opts ...client.CallOption
 	// We dont't override the header unless the ServiceToken option has
 	// been specified or the header wasn't provided
 	if _, ok := metadata.Get(ctx, "Authorization"); ok && !options.ServiceToken {
-		return ctx
+		return ctx, false
 	}
 
 	// if auth is nil we won't be able to get an access token, so we execute
 	// the request without one.
 	aa := a.auth()
 	if aa == nil {
-		return ctx
+		return ctx, false
 	}
 
 	// set the namespace header if it has not been set (e.g. on a service to service request)
@@ -175,16 +300,60 @@ func (a *authWrapper) wrapContext(ctx context.Context, opts ...client.CallOption
 	aaOpts := aa.Options()
 	if aaOpts.Token != nil && !aaOpts.Token.Expired() {
 		ctx = metadata.Set(ctx, "Authorization", auth.BearerScheme+aaOpts.Token.AccessToken)
-		return ctx
+		return ctx, true
 	}
 
 	// call without an auth token
-	return ctx
+	return ctx, true
+}
+
+type authBroker struct {
+	broker.Broker
+	auth func() auth.Auth
+}
+
+func (a *authBroker) verify(topic, endpoint string) error {
+	aa := a.auth()
+	if aa == nil {
+		return nil
+	}
+
+	err := auth.VerifyTopic(aa, topic, endpoint)
+	if err == auth.ErrForbidden {
+		return errors.Forbidden("go.micro.broker", "Forbidden %v to topic %v", endpoint, topic)
+	} else if err == auth.ErrInvalidToken {
+		return errors.Unauthorized("go.micro.broker", "Unauthorized %v to topic %v: %v", endpoint, topic, err)
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.broker", "Error authorizing %v to topic %v: %v", endpoint, topic, err)
+	}
+
+	return nil
 }
 
-// AuthClient wraps requests with the auth header
+func (a *authBroker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	if err := a.verify(topic, auth.TopicPublish); err != nil {
+		return err
+	}
+	return a.Broker.Publish(topic, m, opts...)
+}
+
+func (a *authBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	if err := a.verify(topic, auth.TopicSubscribe); err != nil {
+		return nil, err
+	}
+	return a.Broker.Subscribe(topic, h, opts...)
+}
+
+// AuthBroker wraps a broker to verify the service has access to a topic before
+// publishing or subscribing to it, using rules with the topic resource type
+func AuthBroker(auth func() auth.Auth, b broker.Broker) broker.Broker {
+	return &authBroker{b, auth}
+}
+
+// AuthClient wraps requests with the auth header. Requests rejected for an
+// expired or invalid token refresh the token once and are retried with it.
 func AuthClient(auth func() auth.Auth, c client.Client) client.Client {
-	return &authWrapper{c, auth}
+	return &authWrapper{Client: c, auth: auth}
 }
 
 func AuthHandlerNamespace(ns string) AuthHandlerOption {
@@ -290,43 +459,58 @@ type cacheWrapper struct {
 // Call executes the request. If the CacheExpiry option was set, the response will be cached using
 // a hash of the metadata and request as the key.
 func (c *cacheWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
-	// parse the options
-	var options client.CallOptions
-	for _, o := range opts {
-		o(&options)
-	}
+	return CacheInterceptor(c.cacheFn)(ctx, req, rsp, c.Client.Call, opts...)
+}
 
-	// if the client doesn't have a cacbe setup don't continue
-	cache := c.cacheFn()
-	if cache == nil {
-		return c.Client.Call(ctx, req, rsp, opts...)
-	}
+// CacheInterceptor caches the responses of the calls setting the CacheExpiry
+// option or to endpoints with a cache policy, register it with client.Intercept
+func CacheInterceptor(cacheFn func() *client.Cache) client.UnaryInterceptor {
+	return func(ctx context.Context, req client.Request, rsp interface{}, invoker client.UnaryInvoker, opts ...client.CallOption) error {
+		// parse the options
+		var options client.CallOptions
+		for _, o := range opts {
+			o(&options)
+		}
 
-	// if the cache expiry is not set, execute the call without the cache
-	if options.CacheExpiry == 0 {
-		return c.Client.Call(ctx, req, rsp, opts...)
-	}
+		// if the client doesn't have a cacbe setup don't continue
+		cache := cacheFn()
+		if cache == nil {
+			return invoker(ctx, req, rsp, opts...)
+		}
 
-	// if the response is nil don't call the cache since we can't assign the response
-	if rsp == nil {
-		return c.Client.Call(ctx, req, rsp, opts...)
-	}
+		// use the ttl of the cache policy of the endpoint if the expiry isn't set
+		if options.CacheExpiry == 0 {
+			if p, ok := cache.Policy(req.Service(), req.Endpoint()); ok {
+				options.CacheExpiry = p.TTL
+			}
+		}
 
-	// check to see if there is a response cached, if there is assign it
-	if r, ok := cache.Get(ctx, &req); ok {
-		val := reflect.ValueOf(rsp).Elem()
-		val.Set(reflect.ValueOf(r).Elem())
-		return nil
-	}
+		// if the cache expiry is not set, execute the call without the cache
+		if options.CacheExpiry == 0 {
+			return invoker(ctx, req, rsp, opts...)
+		}
 
-	// don't cache the result if there was an error
-	if err := c.Client.Call(ctx, req, rsp, opts...); err != nil {
-		return err
-	}
+		// if the response is nil don't call the cache since we can't assign the response
+		if rsp == nil {
+			return invoker(ctx, req, rsp, opts...)
+		}
 
-	// set the result in the cache
-	cache.Set(ctx, &req, rsp, options.CacheExpiry)
-	return nil
+		// check to see if there is a response cached, if there is assign it
+		if r, ok := cache.Get(ctx, &req); ok {
+			val := reflect.ValueOf(rsp).Elem()
+			val.Set(reflect.ValueOf(r).Elem())
+			return nil
+		}
+
+		// don't cache the result if there was an error
+		if err := invoker(ctx, req, rsp, opts...); err != nil {
+			return err
+		}
+
+		// set the result in the cache
+		cache.Set(ctx, &req, rsp, options.CacheExpiry)
+		return nil
+	}
 }
 
 // CacheClient wraps requests with the cache wrapper
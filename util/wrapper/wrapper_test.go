@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/micro/go-micro/v2/auth"
+	"github.com/micro/go-micro/v2/broker"
+	"github.com/micro/go-micro/v2/broker/memory"
 	"github.com/micro/go-micro/v2/client"
 	"github.com/micro/go-micro/v2/errors"
 	"github.com/micro/go-micro/v2/metadata"
@@ -349,6 +351,206 @@ func TestAuthHandler(t *testing.T) {
 	})
 }
 
+type refreshAuth struct {
+	token      *auth.Token
+	tokenCount int
+
+	auth.Auth
+}
+
+func (a *refreshAuth) Options() auth.Options {
+	return auth.Options{Token: a.token}
+}
+
+func (a *refreshAuth) Init(opts ...auth.Option) {
+	options := a.Options()
+	for _, o := range opts {
+		o(&options)
+	}
+	a.token = options.Token
+}
+
+func (a *refreshAuth) Token(opts ...auth.TokenOption) (*auth.Token, error) {
+	a.tokenCount++
+	if options := auth.NewTokenOptions(opts...); options.RefreshToken != "refresh" {
+		return nil, auth.ErrInvalidToken
+	}
+	return &auth.Token{AccessToken: "new", RefreshToken: "refresh", Expiry: time.Now().Add(time.Minute)}, nil
+}
+
+type authClient struct {
+	tokens []string
+	client.Client
+}
+
+func (c *authClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	tok, _ := metadata.Get(ctx, "Authorization")
+	c.tokens = append(c.tokens, tok)
+	if tok != auth.BearerScheme+"new" {
+		return errors.Unauthorized("go.micro.service.foo", "invalid token provided")
+	}
+	return nil
+}
+
+type auditRequest struct {
+	testRequest
+}
+
+func (r auditRequest) Header() map[string]string {
+	return map[string]string{}
+}
+
+func (r auditRequest) Body() interface{} {
+	return nil
+}
+
+type auditSink struct {
+	records []*server.AuditRecord
+}
+
+func (s *auditSink) Write(rec *server.AuditRecord) error {
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func TestAuthHandlerAudit(t *testing.T) {
+	req := auditRequest{testRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}}
+	ctx := metadata.Set(context.TODO(), "Authorization", auth.BearerScheme+"Token")
+
+	testData := []struct {
+		name      string
+		verifyErr error
+	}{
+		{"Allowed", nil},
+		{"Denied", auth.ErrForbidden},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			a := &testAuth{inspectAccount: &auth.Account{ID: "alice"}, verifyError: d.verifyErr}
+			authFn := func() auth.Auth { return a }
+
+			// the auditor wraps the auth handler setting the account
+			sink := &auditSink{}
+			audit := server.AuditWrapper(server.NewAuditor(sink, server.AuditAuth(authFn)))
+			h := audit(AuthHandler(authFn)(func(ctx context.Context, req server.Request, rsp interface{}) error {
+				return nil
+			}))
+
+			h(ctx, req, nil)
+			if len(sink.records) != 1 || sink.records[0].Account != "alice" {
+				t.Fatalf("Expected the record of the account, got %+v", sink.records)
+			}
+		})
+	}
+}
+
+type topicAuth struct {
+	inspectErr error
+	allowed    string
+
+	auth.Auth
+}
+
+func (a *topicAuth) Options() auth.Options {
+	return auth.Options{Token: &auth.Token{AccessToken: "token"}}
+}
+
+func (a *topicAuth) Inspect(token string) (*auth.Account, error) {
+	if a.inspectErr != nil {
+		return nil, a.inspectErr
+	}
+	return &auth.Account{ID: "svc"}, nil
+}
+
+func (a *topicAuth) Verify(acc *auth.Account, res *auth.Resource, opts ...auth.VerifyOption) error {
+	if res.Type == auth.TopicResourceType && res.Name == a.allowed {
+		return nil
+	}
+	return auth.ErrForbidden
+}
+
+func TestAuthBroker(t *testing.T) {
+	a := &topicAuth{allowed: "allowed"}
+	b := AuthBroker(func() auth.Auth { return a }, memory.NewBroker())
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	if err := b.Publish("allowed", &broker.Message{}); err != nil {
+		t.Fatalf("Expected publishing to be allowed, got %v", err)
+	}
+	sub, err := b.Subscribe("allowed", func(broker.Event) error { return nil })
+	if err != nil {
+		t.Fatalf("Expected subscribing to be allowed, got %v", err)
+	}
+	sub.Unsubscribe()
+
+	if err := b.Publish("denied", &broker.Message{}); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected publishing to be forbidden, got %v", err)
+	}
+	if _, err := b.Subscribe("denied", func(broker.Event) error { return nil }); errors.FromError(err).Code != 403 {
+		t.Fatalf("Expected subscribing to be forbidden, got %v", err)
+	}
+
+	// a token which can't be inspected is unauthorized
+	a.inspectErr = auth.ErrInvalidToken
+	if err := b.Publish("allowed", &broker.Message{}); errors.FromError(err).Code != 401 {
+		t.Fatalf("Expected publishing to be unauthorized, got %v", err)
+	}
+}
+
+func TestAuthClient(t *testing.T) {
+	var req client.Request
+
+	// a rejected token is refreshed and the call retried once
+	t.Run("RefreshToken", func(t *testing.T) {
+		a := &refreshAuth{token: &auth.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(time.Minute)}}
+		c := &authClient{}
+
+		err := AuthClient(func() auth.Auth { return a }, c).Call(context.TODO(), req, nil)
+		if err != nil {
+			t.Fatalf("Expected nil error but got %v", err)
+		}
+		if a.tokenCount != 1 {
+			t.Errorf("Expected the token to be refreshed once, got %d", a.tokenCount)
+		}
+		if !reflect.DeepEqual(c.tokens, []string{auth.BearerScheme + "old", auth.BearerScheme + "new"}) {
+			t.Errorf("Expected the call to be retried with the new token, got %v", c.tokens)
+		}
+	})
+
+	// a failed refresh returns the error of the call
+	t.Run("FailedRefresh", func(t *testing.T) {
+		a := &refreshAuth{token: &auth.Token{AccessToken: "old", RefreshToken: "revoked", Expiry: time.Now().Add(time.Minute)}}
+		c := &authClient{}
+
+		err := AuthClient(func() auth.Auth { return a }, c).Call(context.TODO(), req, nil)
+		if verr := errors.FromError(err); verr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected unauthorized error but got %v", err)
+		}
+		if len(c.tokens) != 1 {
+			t.Errorf("Expected the call not to be retried, got %d calls", len(c.tokens))
+		}
+	})
+
+	// the authorization set by the caller isn't refreshed
+	t.Run("CallerToken", func(t *testing.T) {
+		a := &refreshAuth{token: &auth.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(time.Minute)}}
+		c := &authClient{}
+
+		ctx := metadata.Set(context.TODO(), "Authorization", auth.BearerScheme+"caller")
+		err := AuthClient(func() auth.Auth { return a }, c).Call(ctx, req, nil)
+		if err == nil {
+			t.Errorf("Expected unauthorized error")
+		}
+		if a.tokenCount != 0 {
+			t.Errorf("Did not expect the token to be refreshed")
+		}
+	})
+}
+
 type testClient struct {
 	callCount int
 	callRsp   interface{}
@@ -371,7 +573,7 @@ type testRsp struct {
 }
 
 func TestCacheWrapper(t *testing.T) {
-	req := client.NewRequest("go.micro.service.foo", "Foo.Bar", nil)
+	var req client.Request
 
 	t.Run("NilCache", func(t *testing.T) {
 		cli := new(testClient)
@@ -444,3 +646,37 @@ func TestCacheWrapper(t *testing.T) {
 		}
 	})
 }
+
+func TestSummarize(t *testing.T) {
+	type testRequest struct {
+		Name     string
+		Password string
+		Ids      []string
+		Count    int
+		hidden   string
+	}
+
+	summary := summarize(&testRequest{
+		Name:     "a very long name which will be truncated in the summary",
+		Password: "secret",
+		Ids:      []string{"1", "2", "3"},
+		Count:    5,
+		hidden:   "hidden",
+	})
+
+	expected := map[string]string{
+		"Name":     "a very long name which will be t...",
+		"Password": "[redacted]",
+		"Ids":      "len=3",
+		"Count":    "5",
+	}
+
+	if len(summary) != len(expected) {
+		t.Fatalf("Expected %v fields, got %v", len(expected), summary)
+	}
+	for k, v := range expected {
+		if summary[k] != v {
+			t.Errorf("Expected %v to be %q, got %q", k, v, summary[k])
+		}
+	}
+}